@@ -6,9 +6,14 @@ extern "C" {
 #endif
 
 char* createKeyPair();
-char* getPbKey();
-char* getPvKey();
-char* createVp(char* did, char* iss, char* pvKeyBase58, char* credTyp, char* vcJwt);
+char* createKey(char* keyType);
+char* listKeys();
+int deleteKey(char* keyID);
+char* newMnemonic();
+int restoreWallet(char* mnemonic, char* passphrase);
+char* deriveKey(char* keyType, char* relyingParty, int rotation);
+char* getPbKey(char* keyID);
+char* createVp(char* did, char* iss, char* keyID, char* credTyp, char* vcJwt);
 long claimsGetExp(char* vpJwt);
 long claimsGetIat(char* vpJwt);
 long claimsGetInt64(char* vpJwt, char* claim);
@@ -14,22 +14,78 @@ Java_com_byd50_ssi_demo_NativeBridge_createKeyPairNative(JNIEnv *env, jobject /*
 }
 
 extern "C" JNIEXPORT jstring JNICALL
-Java_com_byd50_ssi_demo_NativeBridge_getPublicKeyBase58Native(JNIEnv *env, jobject /* this */) {
-    char* result = getPbKey();
+Java_com_byd50_ssi_demo_NativeBridge_createKeyNative(JNIEnv *env, jobject /* this */, jstring keyType) {
+    const char *c_type = env->GetStringUTFChars(keyType, nullptr);
+    char* result = createKey(const_cast<char*>(c_type));
     jstring out = env->NewStringUTF(result ? result : "");
     if (result) {
         free(result);
     }
+    env->ReleaseStringUTFChars(keyType, c_type);
     return out;
 }
 
 extern "C" JNIEXPORT jstring JNICALL
-Java_com_byd50_ssi_demo_NativeBridge_getPrivateKeyBase58Native(JNIEnv *env, jobject /* this */) {
-    char* result = getPvKey();
+Java_com_byd50_ssi_demo_NativeBridge_listKeysNative(JNIEnv *env, jobject /* this */) {
+    char* result = listKeys();
+    jstring out = env->NewStringUTF(result ? result : "[]");
+    if (result) {
+        free(result);
+    }
+    return out;
+}
+
+extern "C" JNIEXPORT jboolean JNICALL
+Java_com_byd50_ssi_demo_NativeBridge_deleteKeyNative(JNIEnv *env, jobject /* this */, jstring keyId) {
+    const char *c_key = env->GetStringUTFChars(keyId, nullptr);
+    int deleted = deleteKey(const_cast<char*>(c_key));
+    env->ReleaseStringUTFChars(keyId, c_key);
+    return deleted ? JNI_TRUE : JNI_FALSE;
+}
+
+extern "C" JNIEXPORT jstring JNICALL
+Java_com_byd50_ssi_demo_NativeBridge_newMnemonicNative(JNIEnv *env, jobject /* this */) {
+    char* result = newMnemonic();
+    jstring out = env->NewStringUTF(result ? result : "");
+    if (result) {
+        free(result);
+    }
+    return out;
+}
+
+extern "C" JNIEXPORT jboolean JNICALL
+Java_com_byd50_ssi_demo_NativeBridge_restoreWalletNative(JNIEnv *env, jobject /* this */, jstring mnemonic, jstring passphrase) {
+    const char *c_mnemonic = env->GetStringUTFChars(mnemonic, nullptr);
+    const char *c_passphrase = env->GetStringUTFChars(passphrase, nullptr);
+    int restored = restoreWallet(const_cast<char*>(c_mnemonic), const_cast<char*>(c_passphrase));
+    env->ReleaseStringUTFChars(mnemonic, c_mnemonic);
+    env->ReleaseStringUTFChars(passphrase, c_passphrase);
+    return restored ? JNI_TRUE : JNI_FALSE;
+}
+
+extern "C" JNIEXPORT jstring JNICALL
+Java_com_byd50_ssi_demo_NativeBridge_deriveKeyNative(JNIEnv *env, jobject /* this */, jstring keyType, jstring relyingParty, jint rotation) {
+    const char *c_type = env->GetStringUTFChars(keyType, nullptr);
+    const char *c_party = env->GetStringUTFChars(relyingParty, nullptr);
+    char* result = deriveKey(const_cast<char*>(c_type), const_cast<char*>(c_party), rotation);
+    jstring out = env->NewStringUTF(result ? result : "");
+    if (result) {
+        free(result);
+    }
+    env->ReleaseStringUTFChars(keyType, c_type);
+    env->ReleaseStringUTFChars(relyingParty, c_party);
+    return out;
+}
+
+extern "C" JNIEXPORT jstring JNICALL
+Java_com_byd50_ssi_demo_NativeBridge_getPublicKeyBase58Native(JNIEnv *env, jobject /* this */, jstring keyId) {
+    const char *c_key = env->GetStringUTFChars(keyId, nullptr);
+    char* result = getPbKey(const_cast<char*>(c_key));
     jstring out = env->NewStringUTF(result ? result : "");
     if (result) {
         free(result);
     }
+    env->ReleaseStringUTFChars(keyId, c_key);
     return out;
 }
 
@@ -39,16 +95,16 @@ Java_com_byd50_ssi_demo_NativeBridge_createVpNative(
         jobject /* this */,
         jstring did,
         jstring issuer,
-        jstring pvKeyBase58,
+        jstring keyId,
         jstring credType,
         jstring vcJwt) {
     const char *c_did = env->GetStringUTFChars(did, nullptr);
     const char *c_issuer = env->GetStringUTFChars(issuer, nullptr);
-    const char *c_pv = env->GetStringUTFChars(pvKeyBase58, nullptr);
+    const char *c_key = env->GetStringUTFChars(keyId, nullptr);
     const char *c_type = env->GetStringUTFChars(credType, nullptr);
     const char *c_vc = env->GetStringUTFChars(vcJwt, nullptr);
 
-    char* result = createVp(const_cast<char*>(c_did), const_cast<char*>(c_issuer), const_cast<char*>(c_pv), const_cast<char*>(c_type), const_cast<char*>(c_vc));
+    char* result = createVp(const_cast<char*>(c_did), const_cast<char*>(c_issuer), const_cast<char*>(c_key), const_cast<char*>(c_type), const_cast<char*>(c_vc));
     jstring out = env->NewStringUTF(result ? result : "");
 
     if (result) {
@@ -57,7 +113,7 @@ Java_com_byd50_ssi_demo_NativeBridge_createVpNative(
 
     env->ReleaseStringUTFChars(did, c_did);
     env->ReleaseStringUTFChars(issuer, c_issuer);
-    env->ReleaseStringUTFChars(pvKeyBase58, c_pv);
+    env->ReleaseStringUTFChars(keyId, c_key);
     env->ReleaseStringUTFChars(credType, c_type);
     env->ReleaseStringUTFChars(vcJwt, c_vc);
 
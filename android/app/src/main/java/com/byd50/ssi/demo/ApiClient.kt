@@ -12,6 +12,12 @@ class ApiClient(private val baseUrl: String) {
     private val tag = TAG
 
     data class Challenge(val aud: String, val nonce: String)
+    data class Enrollment(
+        val keyId: String,
+        val did: String,
+        val publicKeyBase58: String,
+        val accessToken: String
+    )
     data class LicenseIssueResult(
         val simplePresentationValid: Boolean,
         val vcJwt: String,
@@ -35,16 +41,31 @@ class ApiClient(private val baseUrl: String) {
         return resp.optString("did", "")
     }
 
+    /** Creates a key in the KMS of the service with a DID for it. The private key never leaves the service. */
+    fun enrollKey(keyType: String, method: String): Enrollment {
+        val body = JSONObject()
+        body.put("key_type", keyType)
+        body.put("method", method)
+        val resp = post(PATH_KEYS_ENROLL, body)
+        return Enrollment(
+            resp.optString("key_id", ""),
+            resp.optString("did", ""),
+            resp.optString("public_key_base58", ""),
+            resp.optString("access_token", "")
+        )
+    }
+
     fun createVc(
         kid: String,
-        pvKeyBase58: String,
+        keyId: String,
+        accessToken: String,
         credType: String,
         credentialSubject: JSONObject,
         expiresInMinutes: Int
     ): String {
         val body = JSONObject()
         body.put("kid", kid)
-        body.put("pv_key_base58", pvKeyBase58)
+        body.put("key_id", keyId)
         body.put("type", credType)
         body.put("issuer", DEFAULT_ISSUER)
         body.put("subject", kid)
@@ -52,7 +73,7 @@ class ApiClient(private val baseUrl: String) {
             body.put("expires_in_minutes", expiresInMinutes)
         }
         body.put("credential_subject", credentialSubject)
-        val resp = post(PATH_VC_CREATE, body)
+        val resp = post(PATH_VC_CREATE, body, accessToken)
         return resp.optString("vc_jwt", "")
     }
 
@@ -65,7 +86,8 @@ class ApiClient(private val baseUrl: String) {
 
     fun createVp(
         holderDid: String,
-        pvKeyBase58: String,
+        keyId: String,
+        accessToken: String,
         vcJwts: List<String>,
         aud: String,
         nonce: String,
@@ -73,7 +95,7 @@ class ApiClient(private val baseUrl: String) {
     ): String {
         val body = JSONObject()
         body.put("holder_did", holderDid)
-        body.put("pv_key_base58", pvKeyBase58)
+        body.put("key_id", keyId)
         body.put("type", VP_TYPE_DEFAULT)
         body.put("issuer", holderDid)
         body.put("subject", holderDid)
@@ -84,7 +106,7 @@ class ApiClient(private val baseUrl: String) {
         body.put("aud", aud)
         body.put("nonce", nonce)
         body.put("simple_presentation", simplePresentation)
-        val resp = post(PATH_VP_CREATE, body)
+        val resp = post(PATH_VP_CREATE, body, accessToken)
         return resp.optString("vp_jwt", "")
     }
 
@@ -159,11 +181,14 @@ class ApiClient(private val baseUrl: String) {
         )
     }
 
-    private fun post(path: String, body: JSONObject): JSONObject {
+    private fun post(path: String, body: JSONObject, accessToken: String = ""): JSONObject {
         val url = URL(baseUrl.trimEnd('/') + path)
         val conn = url.openConnection() as HttpURLConnection
         conn.requestMethod = "POST"
         conn.setRequestProperty("Content-Type", "application/json")
+        if (accessToken.isNotBlank()) {
+            conn.setRequestProperty("Authorization", "Bearer $accessToken")
+        }
         conn.doOutput = true
         Log.d(tag, "POST ${url} body=${body}")
 
@@ -196,6 +221,7 @@ class ApiClient(private val baseUrl: String) {
         private const val DEFAULT_VP_EXPIRES_MIN = 5
         private const val RESPONSE_LOG_MAX = 512
         private const val PATH_CREATE_DID = "/v2/testapi/create-did"
+        private const val PATH_KEYS_ENROLL = "/v2/testapi/keys/enroll"
         private const val PATH_VC_CREATE = "/v2/testapi/vc/create"
         private const val PATH_VC_VERIFY = "/v2/testapi/vc/verify"
         private const val PATH_VP_CREATE = "/v2/testapi/vp/create"
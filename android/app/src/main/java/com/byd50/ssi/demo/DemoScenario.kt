@@ -2,9 +2,14 @@ package com.byd50.ssi.demo
 
 import java.util.UUID
 
-class DemoScenario(private val api: ApiClient, private val native: NativeBridge) {
+class DemoScenario(private val api: ApiClient) {
     var did: String = ""
         private set
+    var keyId: String = ""
+        private set
+    var publicKeyBase58: String = ""
+        private set
+    private var accessToken: String = ""
     var dlVc: String = ""
         private set
     var rentalVc: String = ""
@@ -26,9 +31,11 @@ class DemoScenario(private val api: ApiClient, private val native: NativeBridge)
         private set
 
     fun createDid(): String {
-        native.createKeyPair()
-        val pb = native.getPublicKeyBase58()
-        did = api.createDid("byd50", pb)
+        val enrollment = api.enrollKey("ecdsa", "byd50")
+        keyId = enrollment.keyId
+        publicKeyBase58 = enrollment.publicKeyBase58
+        accessToken = enrollment.accessToken
+        did = enrollment.did
         return did
     }
 
@@ -38,7 +45,8 @@ class DemoScenario(private val api: ApiClient, private val native: NativeBridge)
         val challenge = api.requestLicenseChallenge()
         val vp = api.createVp(
             did,
-            native.getPrivateKeyBase58(),
+            keyId,
+            accessToken,
             emptyList(),
             challenge.aud,
             challenge.nonce,
@@ -74,7 +82,8 @@ class DemoScenario(private val api: ApiClient, private val native: NativeBridge)
         val challenge = api.requestRentalChallenge()
         val vp = api.createVp(
             did,
-            native.getPrivateKeyBase58(),
+            keyId,
+            accessToken,
             listOf(dlVc),
             challenge.aud,
             challenge.nonce,
@@ -109,7 +118,8 @@ class DemoScenario(private val api: ApiClient, private val native: NativeBridge)
         val nonce = UUID.randomUUID().toString().take(8)
         val vp = api.createVp(
             did,
-            native.getPrivateKeyBase58(),
+            keyId,
+            accessToken,
             listOf(rentalVc),
             "",
             nonce,
@@ -25,6 +25,7 @@ class MainActivity : AppCompatActivity() {
 
     private var scenario: DemoScenario? = null
     private var scenarioBaseUrl: String = ""
+    private var jniKeyId: String = ""
 
     override fun onCreate(savedInstanceState: Bundle?) {
         super.onCreate(savedInstanceState)
@@ -129,7 +130,10 @@ class MainActivity : AppCompatActivity() {
                 logLine(LOG_JNI_NOT_LOADED)
                 return@setOnClickListener
             }
-            val pb = NativeBridge.getPublicKeyBase58()
+            if (jniKeyId.isEmpty()) {
+                jniKeyId = NativeBridge.createKeyPair()
+            }
+            val pb = NativeBridge.getPublicKeyBase58(jniKeyId)
             logLine("$LOG_JNI_PUBKEY ${pb.take(48)}...")
         }
 
@@ -141,7 +145,7 @@ class MainActivity : AppCompatActivity() {
 
     private fun getScenario(baseUrl: String): DemoScenario {
         if (scenario == null || baseUrl != scenarioBaseUrl) {
-            scenario = DemoScenario(ApiClient(baseUrl), NativeBridge)
+            scenario = DemoScenario(ApiClient(baseUrl))
             scenarioBaseUrl = baseUrl
             logLine("$LOG_SCENARIO_RESET $baseUrl")
             resetAllChecks()
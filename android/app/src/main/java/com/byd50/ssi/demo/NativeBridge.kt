@@ -20,19 +20,47 @@ object NativeBridge {
     }
 
     external fun createKeyPairNative(): String
-    external fun getPublicKeyBase58Native(): String
-    external fun getPrivateKeyBase58Native(): String
-    external fun createVpNative(did: String, issuer: String, pvKeyBase58: String, credType: String, vcJwt: String): String
+    external fun createKeyNative(keyType: String): String
+    external fun listKeysNative(): String
+    external fun deleteKeyNative(keyId: String): Boolean
+    external fun newMnemonicNative(): String
+    external fun restoreWalletNative(mnemonic: String, passphrase: String): Boolean
+    external fun deriveKeyNative(keyType: String, relyingParty: String, rotation: Int): String
+    external fun getPublicKeyBase58Native(keyId: String): String
+    external fun createVpNative(did: String, issuer: String, keyId: String, credType: String, vcJwt: String): String
 
+    /** Creates an ECDSA key and returns its key ID. */
     fun createKeyPair(): String {
         return if (isLoaded) createKeyPairNative() else "not_loaded"
     }
 
-    fun getPublicKeyBase58(): String {
-        return if (isLoaded) getPublicKeyBase58Native() else ""
+    fun createKey(keyType: String): String {
+        return if (isLoaded) createKeyNative(keyType) else ""
     }
 
-    fun getPrivateKeyBase58(): String {
-        return if (isLoaded) getPrivateKeyBase58Native() else ""
+    fun listKeys(): String {
+        return if (isLoaded) listKeysNative() else "[]"
+    }
+
+    fun deleteKey(keyId: String): Boolean {
+        return if (isLoaded) deleteKeyNative(keyId) else false
+    }
+
+    /** Generates a 24-word mnemonic for the user to back up. */
+    fun newMnemonic(): String {
+        return if (isLoaded) newMnemonicNative() else ""
+    }
+
+    fun restoreWallet(mnemonic: String, passphrase: String): Boolean {
+        return if (isLoaded) restoreWalletNative(mnemonic, passphrase) else false
+    }
+
+    /** Derives a wallet key and returns its key ID; an empty relyingParty derives the identity key. */
+    fun deriveKey(keyType: String, relyingParty: String, rotation: Int): String {
+        return if (isLoaded) deriveKeyNative(keyType, relyingParty, rotation) else ""
+    }
+
+    fun getPublicKeyBase58(keyId: String): String {
+        return if (isLoaded) getPublicKeyBase58Native(keyId) else ""
     }
 }
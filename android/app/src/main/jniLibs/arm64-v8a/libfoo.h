@@ -75,8 +75,13 @@ extern "C" {
 #endif
 
 extern char* createKeyPair();
-extern char* getPbKey();
-extern char* getPvKey();
+extern char* createKey(char* keyType);
+extern char* listKeys();
+extern int deleteKey(char* keyID);
+extern char* newMnemonic();
+extern int restoreWallet(char* mnemonic, char* passphrase);
+extern char* deriveKey(char* keyType, char* relyingParty, int rotation);
+extern char* getPbKey(char* keyID);
 extern char* createVp(char* str1, char* str2, char* str3, char* str4, char* str5);
 extern long claimsGetExp(char* vpJwt);
 extern long claimsGetIat(char* vpJwt);
@@ -24,15 +24,22 @@ import (
 	"byd50-ssi/pkg/did/configs"
 	"byd50-ssi/pkg/did/core"
 	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	"byd50-ssi/pkg/did/core/didauth"
+	"byd50-ssi/pkg/did/core/pex"
 	"byd50-ssi/pkg/did/kms"
 	"byd50-ssi/pkg/did/pkg/controller"
 	pb "byd50-ssi/proto-files"
 	"context"
-	"crypto/ecdsa"
+	"crypto"
+	"crypto/rsa"
+	"encoding/base64"
 	"encoding/json"
 	"github.com/golang-jwt/jwt"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"log"
+	"net/http"
 	"sync"
 	"time"
 )
@@ -42,6 +49,12 @@ var (
 	relyingPartyClient pb.RelyingPartyClient
 )
 
+// relyingPartyAudience identifies demo-rp as the audience of DID auth tokens.
+const relyingPartyAudience = "byd50-ssi:demo-rp"
+
+// alumniDefinitionID is the presentation definition the relying party publishes for alumni credentials.
+const alumniDefinitionID = "alumni-credential"
+
 var (
 	onceIssRPC   sync.Once
 	issuerClient pb.IssuerClient
@@ -73,14 +86,35 @@ func logDidDocument(label, doc string) {
 	log.Printf("\n[%s]\n%s", label, pretty)
 }
 
-// mustPvKeyECDSA returns the ECDSA private key or exits on misconfiguration.
-// VC/VP signing uses ECDSA in this demo.
-func mustPvKeyECDSA(dkms kms.KMS) *ecdsa.PrivateKey {
-	pvKey, err := dkms.PvKeyECDSA()
+// keyManager holds the keys of the demo identities; the use cases sign and decrypt by key ID.
+var keyManager = kms.NewMemoryKeyManager()
+
+// identity is a DID of this client with the ID of its key in keyManager.
+type identity struct {
+	Did   string
+	KeyID string
+}
+
+// newIdentity creates a key of keyType and registers a DID for it.
+func newIdentity(keyType, method string) identity {
+	keyID, err := keyManager.Create(keyType)
+	if err != nil {
+		log.Fatalf("could not create %s key (%v)", keyType, err)
+	}
+	pbKeyBase58, err := kms.PublicKeyBase58(keyManager, keyID)
+	if err != nil {
+		log.Fatalf("could not export public key (%v)", err)
+	}
+	return identity{Did: controller.CreateDID(pbKeyBase58, method), KeyID: keyID}
+}
+
+// signer returns the signer of the identity key or exits on misconfiguration.
+func (id identity) signer() crypto.Signer {
+	signer, err := kms.KeySigner(keyManager, id.KeyID)
 	if err != nil {
-		log.Fatalf("invalid ECDSA private key: %v", err)
+		log.Fatalf("invalid key %s: %v", id.KeyID, err)
 	}
-	return pvKey
+	return signer
 }
 
 // GetRelyingPartyClient creates (once) the gRPC client used for auth and VP verify.
@@ -119,7 +153,9 @@ func GetIssuerClient(serviceHost string) pb.IssuerClient {
 
 // UseCase1DefaultAuthentication performs challenge/response DID authentication.
 // Flow: RP issues challenge -> holder decrypts -> holder responds -> RP verifies.
-func UseCase1DefaultAuthentication(dkms kms.KMS) {
+//
+// Deprecated: it needs an RSA key; UseCase1SignatureAuthentication works with any authentication key.
+func UseCase1DefaultAuthentication(holder identity) {
 	// Set up a connection to the server.
 	relyingPartyClient := GetRelyingPartyClient(configs.UseConfig.RelyingPartyAddress)
 
@@ -131,30 +167,153 @@ func UseCase1DefaultAuthentication(dkms kms.KMS) {
 	2. Recv 'Auth Challenge String'
 	3. decrypt AuthChallenge String
 	*/
-	challengeReply, err := relyingPartyClient.AuthChallenge(ctx, &pb.ChallengeRequest{Did: dkms.Did()})
+	challengeReply, err := relyingPartyClient.AuthChallenge(ctx, &pb.ChallengeRequest{Did: holder.Did})
 	if err != nil {
 		log.Fatalf("could not greet: %v", err)
 	}
 	challengeString := challengeReply.GetAuthChallenge()
-	log.Printf("Challenge received (len=%v)", len(challengeString))
+	log.Printf("Challenge received (len=%v) for session %v", len(challengeString), challengeReply.GetSessionId())
 
-	authResponseString := dkms.Decrypt(challengeString)
+	ciphertext, err := base64.StdEncoding.DecodeString(challengeString)
+	if err != nil {
+		log.Fatalf("invalid challenge: %v", err)
+	}
+	authResponseString, err := keyManager.Decrypt(holder.KeyID, ciphertext, &rsa.OAEPOptions{Hash: crypto.SHA512})
+	if err != nil {
+		log.Fatalf("could not decrypt challenge: %v", err)
+	}
 	log.Printf("Challenge decrypted")
 
 	/* Use Case 1. Default Authentication
 	4. Send 'Auth Response String'
 	5. Recv result
 	*/
-	responseReply, err2 := relyingPartyClient.AuthResponse(ctx, &pb.ResponseRequest{AuthResponse: string(authResponseString)})
+	responseReply, err2 := relyingPartyClient.AuthResponse(ctx, &pb.ResponseRequest{
+		AuthResponse: string(authResponseString),
+		SessionId:    challengeReply.GetSessionId(),
+		Did:          holder.Did,
+	})
 	if err2 != nil {
 		log.Fatalf("could not greet: %v", err2)
 	}
-	log.Printf("Auth response: %s", responseReply.GetMessage())
+	log.Printf("Auth response: %s (session token issued: %v)", responseReply.GetMessage(), responseReply.GetSessionToken() != "")
 }
 
-// UseCase2SimpleAuthentication sends a Simple Presentation (DID+timestamp+signature).
-// This demonstrates a lightweight holder-authentication pattern.
-func UseCase2SimpleAuthentication(dkms kms.KMS) {
+// UseCase1SignatureAuthentication performs signature-based DID Auth.
+// Flow: RP issues a structured challenge -> holder signs it with an authentication key -> RP verifies
+// the signature against the key in the resolved DID document and issues access and refresh tokens.
+// The tokens are DPoP-bound to a fresh key, which is returned with them.
+func UseCase1SignatureAuthentication(holder identity) (*didauth.TokenResponse, crypto.Signer) {
+	relyingPartyClient := GetRelyingPartyClient(configs.UseConfig.RelyingPartyAddress)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	challengeReply, err := relyingPartyClient.DidAuthChallenge(ctx, &pb.ChallengeRequest{Did: holder.Did})
+	if err != nil {
+		log.Fatalf("could not request challenge: %v", err)
+	}
+	// only answer challenges of the relying party we are talking to
+	if challengeReply.GetDomain() != configs.UseConfig.RelyingPartyAddress {
+		log.Fatalf("challenge is for another domain: %v", challengeReply.GetDomain())
+	}
+	log.Printf("Challenge received for session %v (aud=%v)", challengeReply.GetSessionId(), challengeReply.GetAud())
+
+	signedChallenge, err := didauth.SignChallenge(&didauth.SignedChallenge{
+		SessionID: challengeReply.GetSessionId(),
+		Nonce:     challengeReply.GetNonce(),
+		Aud:       challengeReply.GetAud(),
+		Domain:    challengeReply.GetDomain(),
+		Iat:       challengeReply.GetIat(),
+		Exp:       challengeReply.GetExp(),
+	}, holder.Did, holder.signer())
+	if err != nil {
+		log.Fatalf("could not sign challenge: %v", err)
+	}
+
+	dpopKeyID, err := keyManager.Create(kms.KeyTypeECDSA)
+	if err != nil {
+		log.Fatalf("could not create DPoP key: %v", err)
+	}
+	dpopKey := identity{KeyID: dpopKeyID}.signer()
+	dpopProof, err := didauth.CreateDPoPProof(http.MethodPost, pb.RelyingParty_DidAuthResponse_FullMethodName, "", dpopKey)
+	if err != nil {
+		log.Fatalf("could not create DPoP proof: %v", err)
+	}
+
+	responseReply, err := relyingPartyClient.DidAuthResponse(ctx, &pb.DidAuthResponseRequest{
+		SessionId:       challengeReply.GetSessionId(),
+		Did:             holder.Did,
+		SignedChallenge: signedChallenge,
+		DpopProof:       dpopProof,
+	})
+	if err != nil {
+		log.Fatalf("could not send response: %v", err)
+	}
+	log.Printf("Auth response: %s (token type: %v)", responseReply.GetMessage(), responseReply.GetTokenType())
+	return tokenResponse(responseReply), dpopKey
+}
+
+// UseCase1ProtectedCall calls the access-controlled RentalCarControl of the issuer with the tokens of
+// UseCase1SignatureAuthentication, then refreshes and revokes them.
+func UseCase1ProtectedCall(tokens *didauth.TokenResponse, dpopKey crypto.Signer) {
+	issuerClient := GetIssuerClient(configs.UseConfig.IssuerAddress)
+	relyingPartyClient := GetRelyingPartyClient(configs.UseConfig.RelyingPartyAddress)
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+
+	if _, err := issuerClient.RentalCarControl(ctx, &pb.RentalCarControlRequest{}); status.Code(err) == codes.Unauthenticated {
+		log.Printf("RentalCarControl without access token: rejected as expected")
+	}
+	callRentalCarControl := func(tokens *didauth.TokenResponse) error {
+		callCtx, err := didauth.OutgoingContext(ctx, pb.Issuer_RentalCarControl_FullMethodName, tokens, dpopKey)
+		if err != nil {
+			return err
+		}
+		// without a rental car agreement the call is authenticated but the agreement does not verify
+		reply, err := issuerClient.RentalCarControl(callCtx, &pb.RentalCarControlRequest{})
+		if err != nil {
+			return err
+		}
+		log.Printf("RentalCarControl with access token: valid=%v result=%v", reply.GetValid(), reply.GetResult())
+		return nil
+	}
+	if err := callRentalCarControl(tokens); err != nil {
+		log.Fatalf("could not call RentalCarControl: %v", err)
+	}
+
+	refreshProof, err := didauth.CreateDPoPProof(http.MethodPost, pb.RelyingParty_RefreshToken_FullMethodName, "", dpopKey)
+	if err != nil {
+		log.Fatalf("could not create DPoP proof: %v", err)
+	}
+	refreshReply, err := relyingPartyClient.RefreshToken(ctx, &pb.RefreshTokenRequest{RefreshToken: tokens.RefreshToken, DpopProof: refreshProof})
+	if err != nil {
+		log.Fatalf("could not refresh token: %v", err)
+	}
+	refreshed := tokenResponse(refreshReply)
+	log.Printf("Token refreshed (refresh token rotated: %v)", refreshed.RefreshToken != tokens.RefreshToken)
+
+	if _, err := relyingPartyClient.RevokeToken(ctx, &pb.RevokeTokenRequest{Token: refreshed.RefreshToken}); err != nil {
+		log.Fatalf("could not revoke token: %v", err)
+	}
+	if err := callRentalCarControl(refreshed); status.Code(err) == codes.Unauthenticated {
+		log.Printf("RentalCarControl after revocation: rejected as expected")
+	}
+}
+
+// tokenResponse converts the token fields of a DID auth or refresh reply.
+func tokenResponse(reply *pb.ResponseReply) *didauth.TokenResponse {
+	return &didauth.TokenResponse{
+		AccessToken:  reply.GetSessionToken(),
+		TokenType:    reply.GetTokenType(),
+		ExpiresIn:    reply.GetExpiresAt() - time.Now().Unix(),
+		RefreshToken: reply.GetRefreshToken(),
+	}
+}
+
+// UseCase2SimpleAuthentication sends a DID auth token (a JWS with aud, nonce, iat and exp signed by the DID).
+// This demonstrates a lightweight holder-authentication pattern without a challenge round trip.
+func UseCase2SimpleAuthentication(holder identity) {
 	// Set up a connection to the server.
 	relyingPartyClient := GetRelyingPartyClient(configs.UseConfig.RelyingPartyAddress)
 
@@ -162,23 +321,25 @@ func UseCase2SimpleAuthentication(dkms kms.KMS) {
 	defer cancel()
 
 	/* Use Case 2. Simple Authentication
-	1. SimplePresentDID
-	   didAndTime := "DID" + ";" + time.Now().UTC().String()
-	   signedStr := RsaSign(didAndTime)
-	   challengeString := didAndTime + ";" + signedStr
+	1. DID auth token for the relying party
+	   claims: iss=DID, aud=relying party, nonce, iat, exp
+	   token := JWS(typ didauth-token+jwt, kid=DID)
 	*/
-	simplePresentString := controller.GetSimplePresent(dkms.Did(), dkms.PvKeyBase58())
+	authToken, err := didauth.CreateToken(holder.Did, relyingPartyAudience, time.Minute, holder.signer())
+	if err != nil {
+		log.Fatalf("could not create DID auth token: %v", err)
+	}
 
-	simplePresentReply, err := relyingPartyClient.SimplePresent(ctx, &pb.SimplePresentRequest{SimplePresent: simplePresentString})
+	simplePresentReply, err := relyingPartyClient.SimplePresent(ctx, &pb.SimplePresentRequest{SimplePresent: authToken})
 	if err != nil {
 		log.Fatalf("could not greet: %v", err)
 	}
-	log.Printf("Simple present result: %s", simplePresentReply.GetResult())
+	log.Printf("Simple present result: %s %s", simplePresentReply.GetResult(), simplePresentReply.GetError())
 }
 
 // UseCase3RequestCredential requests a VC from the issuer and submits a VP to the RP.
 // The VP contains the VC JWT and is signed by the holder DID.
-func UseCase3RequestCredential(dkms kms.KMS) {
+func UseCase3RequestCredential(holder identity) {
 	// Set up a connection to the server.
 	issuerClient := GetIssuerClient(configs.UseConfig.IssuerAddress)
 	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
@@ -193,7 +354,7 @@ func UseCase3RequestCredential(dkms kms.KMS) {
 	1. JWT
 		credentialsubject
 	*/
-	log.Printf("Holder DID: %s", dkms.Did())
+	log.Printf("Holder DID: %s", holder.Did)
 
 	// ******************** Build VC Claims ******************** //
 	nonce := core.RandomString(12)
@@ -218,7 +379,7 @@ func UseCase3RequestCredential(dkms kms.KMS) {
 	standardClaims := jwt.StandardClaims{
 		Audience:  "",
 		ExpiresAt: time.Now().Add(time.Minute * 5).Unix(),
-		Id:        "089a411f-0d88-450f-8cc0-1a3acfebecd3",
+		Id:        core.NewCredentialID(),
 		IssuedAt:  time.Now().Unix(),
 		Issuer:    "http://google.com/issuer",
 		NotBefore: time.Now().Unix(),
@@ -227,15 +388,14 @@ func UseCase3RequestCredential(dkms kms.KMS) {
 
 	// Create the Claims
 	claims := byd50_jwt.VcClaims{
-		nonce,
-		myVcClaims,
-		standardClaims,
+		Nonce:          nonce,
+		Vc:             myVcClaims,
+		StandardClaims: standardClaims,
 	}
 
 	// ******************** Create VC with claims ******************** //
-	kid := dkms.Did()
-	pvKey := mustPvKeyECDSA(dkms)
-	vcRequestJwt := core.CreateVcWithClaims(kid, claims, pvKey)
+	kid := holder.Did
+	vcRequestJwt := core.CreateVcWithClaims(kid, claims, holder.signer())
 	log.Printf("\n[VC Request JWT]\n%v", vcRequestJwt)
 
 	// ******************** Request VC ******************** //
@@ -247,53 +407,46 @@ func UseCase3RequestCredential(dkms kms.KMS) {
 
 	myVc := credentialReply.GetVcJwt()
 
-	// ******************** Make VP ******************** //
-	// ******************** Build VP Claims ******************** //
-	nonce = core.RandomString(12)
-
-	typ = "CredentialManagerPresentation"
-	typArray = []string{"VerifiablePresentation"}
-	typArray = append(typArray, typ)
-
-	var vcJwtArray []string
-	vcJwtArray = append(vcJwtArray, myVc)
+	// ******************** Fetch Presentation Definition ******************** //
+	definitionReply, err := relyingPartyClient.GetPresentationDefinition(ctxRp, &pb.PresentationDefinitionRequest{DefinitionId: alumniDefinitionID})
+	if err != nil {
+		log.Fatalf("could not get presentation definition: %v", err)
+	}
+	definition, err := pex.ParseDefinition([]byte(definitionReply.GetPresentationDefinition()))
+	if err != nil {
+		log.Fatalf("invalid presentation definition: %v", err)
+	}
 
-	myVpClaims := map[string]interface{}{
-		"@context": []string{
-			"https://www.w3.org/2018/credentials/v1",
-			"https://www.w3.org/2018/credentials/examples/v1",
-		},
-		"type":                 typArray,
-		"verifiableCredential": vcJwtArray,
+	// ******************** Make VP ******************** //
+	vp, err := pex.BuildPresentation("CredentialManagerPresentation", definition, []string{myVc})
+	if err != nil {
+		log.Fatalf("could not satisfy presentation definition: %v", err)
 	}
 
 	standardClaims = jwt.StandardClaims{
 		Audience:  "",
 		ExpiresAt: time.Now().Add(time.Minute * 5).Unix(),
-		Id:        "089a411f-0d88-450f-8cc0-1a3acfebecd3",
+		Id:        core.NewCredentialID(),
 		IssuedAt:  time.Now().Unix(),
 		Issuer:    "client make this vp",
 		NotBefore: time.Now().Unix(),
 		Subject:   "",
 	}
 
-	// Create the Claims
-	vpClaims := byd50_jwt.VpClaims{
-		nonce,
-		myVpClaims,
-		standardClaims,
+	myVp, err := core.CreateVpWithPresentation(holder.Did, vp, standardClaims, holder.signer())
+	if err != nil {
+		log.Fatalf("could not sign vp: %v", err)
 	}
-
-	holderDid := dkms.Did()
-	holderPvKey := mustPvKeyECDSA(dkms)
-	myVp := core.CreateVpWithClaims(holderDid, vpClaims, holderPvKey)
 	log.Printf("\n[VP JWT]\n%v", myVp)
 
 	// ******************** Send VP ******************** //
-	VpReply, err := relyingPartyClient.VerifyVp(ctxRp, &pb.VerifyVpRequest{Vp: myVp})
+	VpReply, err := relyingPartyClient.VerifyVp(ctxRp, &pb.VerifyVpRequest{Vp: myVp, DefinitionId: definition.ID})
+	if err != nil {
+		log.Fatalf("could not verify vp: %v", err)
+	}
 
-	log.Printf("VP verify result: %v", VpReply.GetResult())
-	log.Printf("\n[VP Verify PublicKey PEM]\n%v", dkms.PbKeyPEM())
+	log.Printf("VP verify result: %v %v", VpReply.GetResult(), VpReply.GetError())
+	log.Printf("\n[VP Verify PublicKey PEM]\n%v", kms.ExportPublicKeyAsPEM(holder.signer().Public()))
 }
 
 // main executes the end-to-end demo sequence:
@@ -302,35 +455,32 @@ func UseCase3RequestCredential(dkms kms.KMS) {
 // 3) VC issuance + VP submission (ECDSA)
 func main() {
 	// Auth flows use RSA; VC/VP flows use ECDSA.
-	authKMS, err := kms.InitKMS(kms.KeyTypeRSA)
-	if err != nil {
-		log.Fatalf("could not Init KMS (%v)", err.Error())
-	}
-
 	method := "byd50"
-	authDid := controller.CreateDID(authKMS.PbKeyBase58(), method)
-	authKMS.SetDid(authDid)
-	log.Printf("\n[Auth DID]\n%s", authDid)
-	authDidDoc := controller.ResolveDID(authDid)
+	authHolder := newIdentity(kms.KeyTypeRSA, method)
+	log.Printf("\n[Auth DID]\n%s", authHolder.Did)
+	authDidDoc := controller.ResolveDID(authHolder.Did)
 	logDidDocument("DID Document (Auth)", authDidDoc)
 
 	logSectionStart("DID Auth Challenge & Response")
-	UseCase1DefaultAuthentication(authKMS)
+	UseCase1DefaultAuthentication(authHolder)
 	logSectionEnd("DID Auth Challenge & Response")
 
+	logSectionStart("DID Auth Signed Challenge")
+	tokens, dpopKey := UseCase1SignatureAuthentication(authHolder)
+	logSectionEnd("DID Auth Signed Challenge")
+
+	logSectionStart("Access Token Protected Call")
+	UseCase1ProtectedCall(tokens, dpopKey)
+	logSectionEnd("Access Token Protected Call")
+
 	logSectionStart("DID Simple Presentation")
-	UseCase2SimpleAuthentication(authKMS)
+	UseCase2SimpleAuthentication(authHolder)
 	logSectionEnd("DID Simple Presentation")
 
-	credKMS, err := kms.InitKMS(kms.KeyTypeECDSA)
-	if err != nil {
-		log.Fatalf("could not Init KMS (%v)", err.Error())
-	}
-	credDid := controller.CreateDID(credKMS.PbKeyBase58(), method)
-	credKMS.SetDid(credDid)
-	log.Printf("\n[Credential DID]\n%s", credDid)
+	credHolder := newIdentity(kms.KeyTypeECDSA, method)
+	log.Printf("\n[Credential DID]\n%s", credHolder.Did)
 
 	logSectionStart("VC Issue & VP Submit")
-	UseCase3RequestCredential(credKMS)
+	UseCase3RequestCredential(credHolder)
 	logSectionEnd("VC Issue & VP Submit")
 }
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"byd50-ssi/pkg/did/configs"
+	"byd50-ssi/pkg/did/core/didauth"
+	pb "byd50-ssi/proto-files"
+	"context"
+	"log"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// protectedMethods need an access token of the relying party.
+var protectedMethods = []string{pb.Issuer_RentalCarControl_FullMethodName}
+
+// relyingPartyIntrospection validates access tokens by introspecting them at the relying party that issued
+// them, which also knows about refreshes and revocations.
+type relyingPartyIntrospection struct {
+	once   sync.Once
+	client pb.RelyingPartyClient
+}
+
+func (r *relyingPartyIntrospection) ValidateAccess(accessToken, dpopProof, method, uri string) (*didauth.AccessClaims, error) {
+	r.once.Do(func() {
+		conn, err := grpc.Dial(configs.UseConfig.RelyingPartyAddress, grpc.WithInsecure())
+		if err != nil {
+			log.Fatalf("did not connect: %v", err)
+		}
+		r.client = pb.NewRelyingPartyClient(conn)
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	reply, err := r.client.IntrospectToken(ctx, &pb.IntrospectTokenRequest{
+		AccessToken: accessToken,
+		DpopProof:   dpopProof,
+		Htm:         method,
+		Htu:         uri,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if !reply.GetActive() {
+		return nil, &didauth.TokenError{Code: didauth.TokenErrorCode(reply.GetError()), Message: "access token is not active"}
+	}
+	return &didauth.AccessClaims{Did: reply.GetDid(), ExpiresAt: time.Unix(reply.GetExp(), 0), Jkt: reply.GetJkt()}, nil
+}
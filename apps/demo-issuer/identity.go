@@ -0,0 +1,63 @@
+package main
+
+import (
+	"byd50-ssi/pkg/did/kms"
+	"byd50-ssi/pkg/did/pkg/controller"
+	"log"
+	"os"
+)
+
+// issuerAlias and issuerKeyName locate the issuer identity in its keystore.
+const (
+	issuerAlias   = "issuer"
+	issuerKeyName = "signing"
+)
+
+// loadIssuerIdentity unlocks the keystore at ISSUER_KEYSTORE_PATH with KEYSTORE_PASSPHRASE and returns the
+// issuer DID and the ID of its key in keyManager, creating them on the first start. Without a passphrase the
+// identity is not persisted and the issuer gets a new DID on every start.
+func loadIssuerIdentity(keyManager kms.KeyManager) (string, string, error) {
+	createDid := func(pbKeyBase58 string) (string, error) {
+		return controller.CreateDIDWithErr(pbKeyBase58, "byd50")
+	}
+	passphrase := os.Getenv("KEYSTORE_PASSPHRASE")
+	if passphrase == "" {
+		log.Printf("KEYSTORE_PASSPHRASE is not set, the issuer DID will not survive a restart")
+		keyID, err := keyManager.Create(kms.KeyTypeECDSA)
+		if err != nil {
+			return "", "", err
+		}
+		pbKeyBase58, err := kms.PublicKeyBase58(keyManager, keyID)
+		if err != nil {
+			return "", "", err
+		}
+		did, err := createDid(pbKeyBase58)
+		if err != nil {
+			return "", "", err
+		}
+		return did, keyID, nil
+	}
+
+	keystorePath := os.Getenv("ISSUER_KEYSTORE_PATH")
+	if keystorePath == "" {
+		keystorePath = "/tmp/issuer-keystore.json"
+	}
+	keystore, err := kms.OpenKeystore(keystorePath, passphrase)
+	if err != nil {
+		return "", "", err
+	}
+	dkms, err := keystore.Identity(issuerAlias, issuerKeyName, kms.KeyTypeECDSA, createDid)
+	if err != nil {
+		return "", "", err
+	}
+	pvKey, err := dkms.Signer()
+	if err != nil {
+		return "", "", err
+	}
+	keyID, err := keyManager.Import(pvKey)
+	if err != nil {
+		return "", "", err
+	}
+	log.Printf("issuer identity loaded from %v", keystorePath)
+	return dkms.Did(), keyID, nil
+}
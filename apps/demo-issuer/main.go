@@ -23,79 +23,69 @@ import (
 	"byd50-ssi/pkg/did/configs"
 	"byd50-ssi/pkg/did/core"
 	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	"byd50-ssi/pkg/did/core/didauth"
+	derrors "byd50-ssi/pkg/did/errors"
 	"byd50-ssi/pkg/did/kms"
+	"byd50-ssi/pkg/did/ledger"
 	"byd50-ssi/pkg/did/pkg/controller"
+	"byd50-ssi/pkg/did/pkg/database"
+	"byd50-ssi/pkg/did/trust"
 	pb "byd50-ssi/proto-files"
 	"context"
-	"crypto/ecdsa"
-	"crypto/x509"
-	"fmt"
-	"github.com/btcsuite/btcutil/base58"
+	"crypto"
+	"errors"
 	"github.com/golang-jwt/jwt"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"log"
 	"net"
+	"os"
 	"time"
 )
 
 var sourceData = "randomStr;2021-06-08T14:04:43UTC"
 var issuerDid string
-var myDkms kms.KMS
+var issuerSigner crypto.Signer
+var issuanceLedger ledger.Store
 
 // server is used to implement proto-files.GreeterServer.
 type server struct {
 	pb.UnimplementedIssuerServer
 }
 
-func mustPvKeyECDSA(dkms kms.KMS) *ecdsa.PrivateKey {
-	pvKey, err := dkms.PvKeyECDSA()
-	if err != nil {
-		log.Fatalf("invalid ECDSA private key: %v", err)
-	}
-	return pvKey
-}
-
 // RequestCredential implements proto-files.GreeterServer
 func (s *server) RequestCredential(_ context.Context, in *pb.CredentialRequest) (*pb.CredentialReply, error) {
 	log.Printf("[RequestCredential][Request]")
-	subjectDid := ""
-	parseToken, err := jwt.Parse(in.VcClaimJwt, func(token *jwt.Token) (interface{}, error) {
-		// Don't forget to validate the alg is what you expect:
-		if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		subjectDid = token.Header["kid"].(string)
-		pbKeyBase58 := controller.GetPublicKey(subjectDid, "")
-		pbKey, _ := x509.ParsePKIXPublicKey(base58.Decode(pbKeyBase58))
-		return pbKey, nil
-	})
+	// the signature is verified with the key of the kid, whatever its type (ES256, ES256K, EdDSA, PS256, ...)
+	claims, err := byd50_jwt.ParseSigned(in.GetVcClaimJwt(), controller.GetPublicKey)
 	if err != nil {
-		log.Fatalf("error: Request Credential has an error: %v", err)
+		log.Printf("[RequestCredential] invalid claim jwt: %v", err)
+		return nil, status.Error(codes.InvalidArgument, "invalid vc claim jwt: "+err.Error())
+	}
+	subjectDid, err := core.GetSignerDid(in.GetVcClaimJwt())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	vc, _ := claims["vc"].(map[string]interface{})
+	credSub, ok := vc["credentialSubject"].(map[string]interface{})
+	if !ok {
+		return nil, status.Error(codes.InvalidArgument, "vc claim jwt has no credentialSubject")
 	}
 
-	vcJwt := ""
-	if parseToken.Valid {
-		kid := issuerDid
-		typ := "AlumniCredential"
-		pvKey := mustPvKeyECDSA(myDkms)
-		log.Printf("pvkey : %v", pvKey)
-		standardClaims := jwt.StandardClaims{
-			Audience:  "",
-			ExpiresAt: time.Now().Add(time.Minute * 5).Unix(),
-			Id:        "089a411f-0d88-450f-8cc0-1a3acfebecd3",
-			IssuedAt:  time.Now().Unix(),
-			Issuer:    "http://demo-issuer.com/issuer142857",
-			NotBefore: time.Now().Unix(),
-			Subject:   subjectDid,
-		}
-
-		if claims, ok := parseToken.Claims.(jwt.MapClaims); ok {
-			vc := claims["vc"].(map[string]interface{})
-			credSub := vc["credentialSubject"].(map[string]interface{})
-			vcJwt = core.CreateVc(kid, typ, credSub, standardClaims, pvKey)
-		}
-
+	kid := issuerDid
+	typ := "AlumniCredential"
+	standardClaims := jwt.StandardClaims{
+		Audience:  "",
+		ExpiresAt: time.Now().Add(time.Minute * 5).Unix(),
+		Id:        core.NewCredentialID(),
+		IssuedAt:  time.Now().Unix(),
+		Issuer:    "http://demo-issuer.com/issuer142857",
+		NotBefore: time.Now().Unix(),
+		Subject:   subjectDid,
 	}
+	vcJwt := core.CreateVc(kid, typ, credSub, standardClaims, issuerSigner)
+	recordIssued(vcJwt)
 	log.Printf("[RequestCredential][Reply] vcJwt: %v", vcJwt)
 
 	return &pb.CredentialReply{VcJwt: vcJwt}, nil
@@ -130,7 +120,7 @@ func (s *server) ReqCredIdCard(_ context.Context, in *pb.IdCardRequest) (*pb.IdC
 	standardClaims := jwt.StandardClaims{
 		Audience:  "",
 		ExpiresAt: time.Now().Add(time.Minute * 3).Unix(),
-		Id:        "089a411f-0d88-450f-8cc0-1a3acfebecd3",
+		Id:        core.NewCredentialID(),
 		IssuedAt:  time.Now().Unix(),
 		Issuer:    "http://www.gov.kr/residentregistration",
 		NotBefore: time.Now().Unix(),
@@ -139,13 +129,13 @@ func (s *server) ReqCredIdCard(_ context.Context, in *pb.IdCardRequest) (*pb.IdC
 
 	// Create the Claims
 	claims := byd50_jwt.VcClaims{
-		nonce,
-		myVc,
-		standardClaims,
+		Nonce:          nonce,
+		Vc:             myVc,
+		StandardClaims: standardClaims,
 	}
 	kid := issuerDid
-	pvKey := mustPvKeyECDSA(myDkms)
-	eIdVcJwt := core.CreateVcWithClaims(kid, claims, pvKey)
+	eIdVcJwt := core.CreateVcWithClaims(kid, claims, issuerSigner)
+	recordIssued(eIdVcJwt)
 
 	log.Printf("[ReqCredIdCard][Reply] vcJwt: %v", eIdVcJwt)
 	return &pb.IdCardReply{EidVcJwt: eIdVcJwt}, nil
@@ -156,7 +146,7 @@ func (s *server) ReqCredDlCard(_ context.Context, in *pb.DlCardRequest) (*pb.DlC
 	log.Printf("[ReqCredDlCard][Request]")
 
 	log.Printf("EIdVcJwt >>\n%v", in.GetEidVcJwt())
-	valid, did, err := core.VerifyVp(in.GetEidVcJwt(), controller.GetPublicKey)
+	valid, did, err := core.VerifyVpWithKeys(in.GetEidVcJwt(), controller.GetPublicKey, controller.GetAssertionMethodKey)
 	log.Printf("ReqCredDlCard ~~~   %v, %v, err:%v", valid, did, err)
 	result := ""
 	eDlVcJwt := ""
@@ -168,7 +158,7 @@ func (s *server) ReqCredDlCard(_ context.Context, in *pb.DlCardRequest) (*pb.DlC
 		subjectDid := did
 		nonce := core.RandomString(12)
 
-		typ := "eDriver'sLicenceCardCredential"
+		typ := licenceCredentialType
 		typArray := []string{"VerifiableCredential"}
 		typArray = append(typArray, typ)
 
@@ -196,7 +186,7 @@ func (s *server) ReqCredDlCard(_ context.Context, in *pb.DlCardRequest) (*pb.DlC
 		standardClaims := jwt.StandardClaims{
 			Audience:  "",
 			ExpiresAt: time.Now().Add(time.Minute * 1).Unix(),
-			Id:        "089a411f-0d88-450f-8cc0-1a3acfebecd3",
+			Id:        core.NewCredentialID(),
 			IssuedAt:  time.Now().Unix(),
 			Issuer:    "http://www.gov.kr/residentregistration",
 			NotBefore: time.Now().Unix(),
@@ -205,13 +195,13 @@ func (s *server) ReqCredDlCard(_ context.Context, in *pb.DlCardRequest) (*pb.DlC
 
 		// Create the Claims
 		claims := byd50_jwt.VcClaims{
-			nonce,
-			myVc,
-			standardClaims,
+			Nonce:          nonce,
+			Vc:             myVc,
+			StandardClaims: standardClaims,
 		}
 		kid := issuerDid
-		pvKey := mustPvKeyECDSA(myDkms)
-		eDlVcJwt = core.CreateVcWithClaims(kid, claims, pvKey)
+		eDlVcJwt = core.CreateVcWithClaims(kid, claims, issuerSigner)
+		recordIssued(eDlVcJwt)
 	}
 
 	log.Printf("[ReqCredDlCard][Reply] vcJwt: %v", eDlVcJwt)
@@ -220,7 +210,9 @@ func (s *server) ReqCredDlCard(_ context.Context, in *pb.DlCardRequest) (*pb.DlC
 
 // ReqCredRentalCarAgreement implements proto-files.GreeterServer
 func (s *server) ReqCredRentalCarAgreement(_ context.Context, in *pb.RentalCarAgreementRequest) (*pb.RentalCarAgreementReply, error) {
-	valid, did, err := core.VerifyVp(in.GetEdlVcJwt(), controller.GetPublicKey)
+	// only licences signed by an issuer the trust registry accepts for their type are honoured
+	valid, did, err := core.VerifyVpWithOptions(in.GetEdlVcJwt(), controller.GetPublicKey, controller.GetAssertionMethodKey,
+		core.VerifyOptions{Trust: trust.GetClient()})
 	result := ""
 	rentalCarAgreementVcJwt := ""
 	if err != nil {
@@ -262,7 +254,7 @@ func (s *server) ReqCredRentalCarAgreement(_ context.Context, in *pb.RentalCarAg
 		standardClaims := jwt.StandardClaims{
 			Audience:  "",
 			ExpiresAt: time.Now().Add(time.Second * 15).Unix(),
-			Id:        "089a411f-0d88-450f-8cc0-1a3acfebecd3",
+			Id:        core.NewCredentialID(),
 			IssuedAt:  time.Now().Unix(),
 			Issuer:    "http://www.gov.kr/residentregistration",
 			NotBefore: time.Now().Unix(),
@@ -271,13 +263,13 @@ func (s *server) ReqCredRentalCarAgreement(_ context.Context, in *pb.RentalCarAg
 
 		// Create the Claims
 		claims := byd50_jwt.VcClaims{
-			nonce,
-			myVc,
-			standardClaims,
+			Nonce:          nonce,
+			Vc:             myVc,
+			StandardClaims: standardClaims,
 		}
 		kid := issuerDid
-		pvKey := mustPvKeyECDSA(myDkms)
-		rentalCarAgreementVcJwt = core.CreateVcWithClaims(kid, claims, pvKey)
+		rentalCarAgreementVcJwt = core.CreateVcWithClaims(kid, claims, issuerSigner)
+		recordIssued(rentalCarAgreementVcJwt)
 	}
 	log.Printf("[ReqCredRentalCarAgreement][Reply] rentalCarAgreementVcJwt: %v", rentalCarAgreementVcJwt)
 
@@ -285,12 +277,21 @@ func (s *server) ReqCredRentalCarAgreement(_ context.Context, in *pb.RentalCarAg
 }
 
 // RentalCarControl implements proto-files.GreeterServer
-func (s *server) RentalCarControl(_ context.Context, in *pb.RentalCarControlRequest) (*pb.RentalCarControlReply, error) {
-	log.Printf("[RentalCarControl][Request]")
+//
+// Callers authenticate with an access token of the relying party (see protectedMethods); the agreement must be
+// presented by the authenticated DID.
+func (s *server) RentalCarControl(ctx context.Context, in *pb.RentalCarControlRequest) (*pb.RentalCarControlReply, error) {
+	claims, ok := didauth.ClaimsFromContext(ctx)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "access token required")
+	}
+	log.Printf("[RentalCarControl][Request] authenticated DID: %v", claims.Did)
 	log.Printf("GetRentalCarAgreementVpJwt >>\n%v", in.GetRentalCarAgreementVcJwt())
 	result := ""
-	valid, did, err := core.VerifyVp(in.GetRentalCarAgreementVcJwt(), controller.GetPublicKey)
-	if valid {
+	valid, did, err := core.VerifyVpWithKeys(in.GetRentalCarAgreementVcJwt(), controller.GetPublicKey, controller.GetAssertionMethodKey)
+	if valid && did != claims.Did {
+		valid, result = false, "agreement is not presented by the authenticated DID"
+	} else if valid {
 		result = "Welcome to our rental car system. " + did
 	}
 	if err != nil {
@@ -300,25 +301,124 @@ func (s *server) RentalCarControl(_ context.Context, in *pb.RentalCarControlRequ
 	return &pb.RentalCarControlReply{Valid: valid, Result: result}, nil
 }
 
+// GetIssuedCredential implements proto-files.IssuerServer
+func (s *server) GetIssuedCredential(ctx context.Context, in *pb.GetIssuedCredentialRequest) (*pb.IssuedCredential, error) {
+	log.Printf("[GetIssuedCredential][Request] jti: %v", in.GetJti())
+	record, err := issuanceLedger.Get(ctx, in.GetJti())
+	if err != nil {
+		return nil, ledgerError(err)
+	}
+	return toIssuedCredential(record), nil
+}
+
+// ListIssuedCredentials implements proto-files.IssuerServer
+func (s *server) ListIssuedCredentials(ctx context.Context, in *pb.ListIssuedCredentialsRequest) (*pb.ListIssuedCredentialsReply, error) {
+	log.Printf("[ListIssuedCredentials][Request] subject: %v, type: %v", in.GetSubjectDid(), in.GetType())
+	var records []ledger.Record
+	var err error
+	switch {
+	case in.GetSubjectDid() != "":
+		records, err = issuanceLedger.ListBySubject(ctx, in.GetSubjectDid())
+	case in.GetType() != "":
+		records, err = issuanceLedger.ListByType(ctx, in.GetType())
+	default:
+		records, err = issuanceLedger.List(ctx)
+	}
+	if err != nil {
+		return nil, ledgerError(err)
+	}
+
+	reply := &pb.ListIssuedCredentialsReply{}
+	for _, record := range records {
+		// a subject query is narrowed further by type
+		if in.GetType() != "" && record.Type != in.GetType() {
+			continue
+		}
+		reply.Credentials = append(reply.Credentials, toIssuedCredential(record))
+	}
+	return reply, nil
+}
+
+// ledgerError maps an issuance ledger error to a gRPC status.
+func ledgerError(err error) error {
+	var dErr *derrors.Error
+	if errors.As(err, &dErr) {
+		switch dErr.Code() {
+		case derrors.CodeNotFound:
+			return status.Error(codes.NotFound, err.Error())
+		case derrors.CodeInvalidInput:
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+// recordIssued stores the metadata of an issued VC in the issuance ledger.
+func recordIssued(vcJwt string) {
+	record, err := ledger.Issue(context.Background(), issuanceLedger, vcJwt)
+	if err != nil {
+		log.Printf("failed to record issued credential: %v", err)
+		return
+	}
+	log.Printf("issued credential recorded. jti: %v, statusIndex: %v", record.Jti, record.StatusIndex)
+}
+
+func toIssuedCredential(record ledger.Record) *pb.IssuedCredential {
+	return &pb.IssuedCredential{
+		Jti:         record.Jti,
+		Issuer:      record.Issuer,
+		SubjectDid:  record.SubjectDid,
+		Type:        record.Type,
+		Iat:         record.IssuedAt,
+		Exp:         record.ExpiresAt,
+		StatusIndex: record.StatusIndex,
+		Hash:        record.Hash,
+	}
+}
+
 func main() {
 	lis, err := net.Listen("tcp", configs.UseConfig.IssuerPort)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
-	// Initialize KMS
-	myDkms, err = kms.InitKMS(kms.KeyTypeECDSA)
-	log.Printf("pvkey : %v", mustPvKeyECDSA(myDkms))
+	// Load or create the issuer identity
+	keyManager := kms.NewMemoryKeyManager()
+	did, keyID, err := loadIssuerIdentity(keyManager)
+	if err != nil {
+		log.Fatalf("could not load issuer identity (%v)", err.Error())
+	}
+	if issuerSigner, err = kms.KeySigner(keyManager, keyID); err != nil {
+		log.Fatalf("invalid issuer key (%v)", err.Error())
+	}
+
+	// Initialize issuance ledger
+	ledgerPath := os.Getenv("LEDGER_LEVELDB_PATH")
+	if ledgerPath == "" {
+		ledgerPath = "/tmp/issuer-ledger.db"
+	}
+	db, err := database.InitializePath(ledgerPath)
+	if err != nil {
+		log.Fatalf("could not open issuance ledger (%v)", err)
+	}
+	ledgerStore, err := ledger.NewLevelDBStore(db)
 	if err != nil {
-		log.Fatalf("could not Init KMS (%v)", err.Error())
+		log.Fatalf("could not init issuance ledger (%v)", err)
+	}
+	defer ledgerStore.Close()
+	issuanceLedger = ledgerStore
+
+	// Register credential schemas
+	if err := registerCredentialSchemas(); err != nil {
+		log.Fatalf("could not register credential schemas (%v)", err)
 	}
 
-	// Create DID
-	method := "byd50"
-	did := controller.CreateDID(myDkms.PbKeyBase58(), method)
-	myDkms.SetDid(did)
 	issuerDid = did
+	log.Printf("issuer DID: %v", did)
+	if err := publishIssuerService(did); err != nil {
+		log.Printf("could not publish issuer service (%v)", err)
+	}
 
-	s := grpc.NewServer()
+	s := grpc.NewServer(grpc.UnaryInterceptor(didauth.UnaryServerInterceptor(&relyingPartyIntrospection{}, protectedMethods...)))
 	pb.RegisterIssuerServer(s, &server{})
 	log.Printf("server listening at %v", lis.Addr())
 	if err := s.Serve(lis); err != nil {
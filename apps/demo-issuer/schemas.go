@@ -0,0 +1,36 @@
+package main
+
+import (
+	"byd50-ssi/pkg/did/core"
+	"embed"
+	"path"
+)
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+// credentialSchemas maps the credential types issued by this server to their bundled JSON Schema.
+var credentialSchemas = []struct {
+	credType string
+	id       string
+	file     string
+}{
+	{"eIdCardCredential", "urn:byd50:schema:eIdCardCredential:1", "eid-card-credential.json"},
+	{"eDriver'sLicenceCardCredential", "urn:byd50:schema:eDriversLicenceCardCredential:1", "edriver-licence-card-credential.json"},
+	{"RentalCarAgreementCredential", "urn:byd50:schema:RentalCarAgreementCredential:1", "rental-car-agreement-credential.json"},
+}
+
+// registerCredentialSchemas registers the bundled schemas so that every issued
+// credentialSubject is validated before signing.
+func registerCredentialSchemas() error {
+	for _, s := range credentialSchemas {
+		schemaJSON, err := schemaFiles.ReadFile(path.Join("schemas", s.file))
+		if err != nil {
+			return err
+		}
+		if err := core.RegisterCredentialSchema(s.credType, s.id, schemaJSON); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,23 @@
+package main
+
+import (
+	"byd50-ssi/pkg/did/configs"
+	"byd50-ssi/pkg/did/core/dids"
+	"byd50-ssi/pkg/did/pkg/controller"
+	pb "byd50-ssi/proto-files"
+)
+
+// publishIssuerService publishes the gRPC endpoint of this issuer in its DID document,
+// so clients holding the issuer DID can discover where to request credentials. The service
+// published by an earlier start is replaced, so the document follows IssuerAddress.
+func publishIssuerService(did string) error {
+	service := dids.ServiceProperty{
+		ID:    did + "#issuer-grpc",
+		Types: dids.ServiceTypes{dids.ServiceTypeGrpcService},
+		ServiceEndpoint: dids.EndpointMap(map[string]interface{}{
+			"uri":     configs.UseConfig.IssuerAddress,
+			"service": pb.Issuer_ServiceDesc.ServiceName,
+		}),
+	}
+	return controller.ReplaceService(did, service, issuerSigner)
+}
@@ -0,0 +1,6 @@
+package main
+
+// licenceCredentialType is the credential a rental car agreement is issued against. Licences are only honoured
+// from issuers the trust registry accepts for it: the registry operator lists the licence authority, or a root
+// authority accredits it.
+const licenceCredentialType = "eDriver'sLicenceCardCredential"
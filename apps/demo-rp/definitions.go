@@ -0,0 +1,29 @@
+package main
+
+import (
+	"byd50-ssi/pkg/did/core/pex"
+	"embed"
+	"path"
+)
+
+//go:embed definitions/*.json
+var definitionFiles embed.FS
+
+// presentationDefinitions are the presentation definitions this relying party publishes, keyed by id.
+var presentationDefinitions = map[string]*pex.PresentationDefinition{}
+
+// loadPresentationDefinitions parses the bundled presentation definitions.
+func loadPresentationDefinitions() error {
+	for _, file := range []string{"alumni-credential.json"} {
+		data, err := definitionFiles.ReadFile(path.Join("definitions", file))
+		if err != nil {
+			return err
+		}
+		def, err := pex.ParseDefinition(data)
+		if err != nil {
+			return err
+		}
+		presentationDefinitions[def.ID] = def
+	}
+	return nil
+}
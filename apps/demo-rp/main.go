@@ -22,17 +22,42 @@ package main
 import (
 	"byd50-ssi/pkg/did/configs"
 	"byd50-ssi/pkg/did/core"
+	"byd50-ssi/pkg/did/core/didauth"
+	"byd50-ssi/pkg/did/core/pex"
+	"byd50-ssi/pkg/did/kms"
 	"byd50-ssi/pkg/did/pkg/controller"
 	pb "byd50-ssi/proto-files"
 	"context"
-	"github.com/btcsuite/btcutil/base58"
+	"encoding/json"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"log"
 	"net"
+	"net/http"
 	"time"
 )
 
-var sourceData = "randomStr;2021-06-08T14:04:43UTC"
+// challenges holds the pending challenge of every authentication session, bound to the requesting DID.
+var challenges = &didauth.ChallengeStore{}
+
+// tokens issues the access and refresh tokens of authenticated DIDs; its key is created at startup, so
+// sessions do not survive a restart.
+var tokens = &didauth.TokenService{Issuer: didAuthAudience, ClockSkew: configs.UseConfig.DidAuthClockSkew}
+
+// didAuth verifies challenges signed with an authentication key of the requesting DID.
+var didAuth = &didauth.Authenticator{GetPbKey: controller.GetPublicKey}
+
+// didAuthAudience identifies this relying party in signed challenges, DID auth tokens and as the issuer of
+// access tokens; the domain of challenges is its gRPC address.
+const didAuthAudience = "byd50-ssi:demo-rp"
+
+// authTokens verifies the DID auth tokens of SimplePresent and rejects replayed ones.
+var authTokens = &didauth.TokenVerifier{
+	Audience:  didAuthAudience,
+	GetPbKey:  controller.GetPublicKey,
+	ClockSkew: configs.UseConfig.DidAuthClockSkew,
+}
 
 // server is used to implement proto-files.GreeterServer.
 type server struct {
@@ -40,54 +65,216 @@ type server struct {
 }
 
 // AuthChallenge implements proto-files.GreeterServer
+//
+// Deprecated: the encrypted challenge only works for RSA keys; DidAuthChallenge replaces it.
 func (s *server) AuthChallenge(_ context.Context, in *pb.ChallengeRequest) (*pb.ChallengeReply, error) {
 	log.Printf("[AuthChallenge][Request] DID: %v", in.GetDid())
-	sourceData = time.Now().UTC().String()
-	sourceData = base58.Encode([]byte(sourceData)) + ";" + sourceData
-	authChallengeString := controller.GetAuthChallengeString(in.GetDid(), sourceData)
-	log.Printf("[AuthChallenge][Reply] authChallengeString(%v)", len(authChallengeString))
+	challenge, err := challenges.Issue(in.GetDid())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	authChallengeString := controller.GetAuthChallengeString(in.GetDid(), challenge.Value)
+	if authChallengeString == "" {
+		return nil, status.Error(codes.FailedPrecondition, "cannot encrypt a challenge for the did")
+	}
+	log.Printf("[AuthChallenge][Reply] session: %v authChallengeString(%v)", challenge.SessionID, len(authChallengeString))
 
-	return &pb.ChallengeReply{AuthChallenge: authChallengeString}, nil
+	return &pb.ChallengeReply{
+		AuthChallenge: authChallengeString,
+		SessionId:     challenge.SessionID,
+		ExpiresAt:     challenge.ExpiresAt.Unix(),
+	}, nil
 }
 
 // AuthResponse implements proto-files.GreeterServer
+//
+// Deprecated: DidAuthResponse replaces it.
 func (s *server) AuthResponse(_ context.Context, in *pb.ResponseRequest) (*pb.ResponseReply, error) {
-	log.Printf("[AuthResponse][Request] %v", in.AuthResponse)
-	message := "error"
+	log.Printf("[AuthResponse][Request] session: %v DID: %v", in.GetSessionId(), in.GetDid())
+
+	if err := challenges.Redeem(in.GetSessionId(), in.GetDid(), in.GetAuthResponse()); err != nil {
+		log.Printf("[AuthResponse][Reply] error: %v", err)
+		return &pb.ResponseReply{Message: "error"}, nil
+	}
+	response, err := tokens.Issue(in.GetDid(), "")
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	log.Printf("[AuthResponse][Reply] success")
+	return tokenReply(response), nil
+}
 
-	// compare Challenge and Response string
-	if in.GetAuthResponse() == sourceData {
-		log.Printf("[AuthResponse] Compare success")
-		message = "success"
+// DidAuthChallenge implements proto-files.RelyingPartyServer
+func (s *server) DidAuthChallenge(_ context.Context, in *pb.ChallengeRequest) (*pb.DidAuthChallengeReply, error) {
+	log.Printf("[DidAuthChallenge][Request] DID: %v", in.GetDid())
+	challenge, err := didAuth.Challenge(in.GetDid(), didAuthAudience, configs.UseConfig.RelyingPartyAddress)
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	log.Printf("[DidAuthChallenge][Reply] session: %v", challenge.SessionID)
+
+	return &pb.DidAuthChallengeReply{
+		SessionId: challenge.SessionID,
+		Nonce:     challenge.Nonce,
+		Aud:       challenge.Aud,
+		Domain:    challenge.Domain,
+		Iat:       challenge.Iat,
+		Exp:       challenge.Exp,
+	}, nil
+}
+
+// DidAuthResponse implements proto-files.RelyingPartyServer
+func (s *server) DidAuthResponse(_ context.Context, in *pb.DidAuthResponseRequest) (*pb.ResponseReply, error) {
+	log.Printf("[DidAuthResponse][Request] session: %v DID: %v", in.GetSessionId(), in.GetDid())
+
+	jkt := ""
+	if in.GetDpopProof() != "" {
+		var err error
+		if jkt, err = tokens.VerifyDPoPProof(in.GetDpopProof(), http.MethodPost, pb.RelyingParty_DidAuthResponse_FullMethodName); err != nil {
+			log.Printf("[DidAuthResponse][Reply] error: %v", err)
+			return &pb.ResponseReply{Message: "error"}, nil
+		}
+	}
+	if err := didAuth.Verify(in.GetSessionId(), in.GetDid(), in.GetSignedChallenge()); err != nil {
+		log.Printf("[DidAuthResponse][Reply] error: %v", err)
+		return &pb.ResponseReply{Message: "error"}, nil
 	}
+	response, err := tokens.Issue(in.GetDid(), jkt)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	log.Printf("[DidAuthResponse][Reply] success, token type: %v", response.TokenType)
+	return tokenReply(response), nil
+}
 
-	log.Printf("[AuthResponse][Reply] : " + message)
-	return &pb.ResponseReply{Message: message}, nil
+// RefreshToken implements proto-files.RelyingPartyServer
+func (s *server) RefreshToken(_ context.Context, in *pb.RefreshTokenRequest) (*pb.ResponseReply, error) {
+	log.Printf("[RefreshToken][Request]")
+	jkt := ""
+	if in.GetDpopProof() != "" {
+		var err error
+		if jkt, err = tokens.VerifyDPoPProof(in.GetDpopProof(), http.MethodPost, pb.RelyingParty_RefreshToken_FullMethodName); err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+	}
+	response, err := tokens.Refresh(in.GetRefreshToken(), jkt)
+	if err != nil {
+		log.Printf("[RefreshToken][Reply] error: %v", err)
+		return nil, status.Error(codes.Unauthenticated, err.Error())
+	}
+	log.Printf("[RefreshToken][Reply] success")
+	return tokenReply(response), nil
+}
+
+// RevokeToken implements proto-files.RelyingPartyServer
+func (s *server) RevokeToken(_ context.Context, in *pb.RevokeTokenRequest) (*pb.RevokeTokenReply, error) {
+	log.Printf("[RevokeToken][Request]")
+	if err := tokens.Revoke(in.GetToken()); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.RevokeTokenReply{}, nil
+}
+
+// IntrospectToken implements proto-files.RelyingPartyServer
+func (s *server) IntrospectToken(_ context.Context, in *pb.IntrospectTokenRequest) (*pb.IntrospectTokenReply, error) {
+	claims, err := tokens.ValidateAccess(in.GetAccessToken(), in.GetDpopProof(), in.GetHtm(), in.GetHtu())
+	if err != nil {
+		log.Printf("[IntrospectToken][Reply] inactive: %v", err)
+		return &pb.IntrospectTokenReply{Active: false, Error: string(didauth.TokenErrorCodeOf(err))}, nil
+	}
+	log.Printf("[IntrospectToken][Reply] active: %v", claims.Did)
+	return &pb.IntrospectTokenReply{Active: true, Did: claims.Did, Exp: claims.ExpiresAt.Unix(), Jkt: claims.Jkt}, nil
+}
+
+// tokenReply is the successful reply of an authentication or refresh.
+func tokenReply(response *didauth.TokenResponse) *pb.ResponseReply {
+	return &pb.ResponseReply{
+		Message:      "success",
+		SessionToken: response.AccessToken,
+		ExpiresAt:    time.Now().Add(time.Duration(response.ExpiresIn) * time.Second).Unix(),
+		RefreshToken: response.RefreshToken,
+		TokenType:    response.TokenType,
+	}
 }
 
 // SimplePresent implements proto-files.GreeterServer
 func (s *server) SimplePresent(_ context.Context, in *pb.SimplePresentRequest) (*pb.SimplePresentReply, error) {
 	log.Printf("[SimplePresent][Request]")
-	result := controller.VerifySimplePresent(in.GetSimplePresent())
-	log.Printf("[SimplePresent][Reply] result: %v", result)
+	did, err := authTokens.Verify(in.GetSimplePresent())
+	if didauth.TokenErrorCodeOf(err) == didauth.TokenMalformed {
+		if legacyDid, legacyErr := authTokens.VerifyLegacy(in.GetSimplePresent()); didauth.TokenErrorCodeOf(legacyErr) != didauth.TokenMalformed {
+			log.Printf("[SimplePresent] legacy simple presentation, deprecated")
+			did, err = legacyDid, legacyErr
+		}
+	}
+	if err != nil {
+		log.Printf("[SimplePresent][Reply] rejected: %v", err)
+		return &pb.SimplePresentReply{Result: "fail", Error: string(didauth.TokenErrorCodeOf(err))}, nil
+	}
+	log.Printf("[SimplePresent][Reply] success: %v", did)
 
-	return &pb.SimplePresentReply{Result: result}, nil
+	return &pb.SimplePresentReply{Result: "success", Did: did}, nil
 }
 
-// SimplePresent implements proto-files.GreeterServer
+// VerifyVp implements proto-files.RelyingPartyServer
 func (s *server) VerifyVp(_ context.Context, in *pb.VerifyVpRequest) (*pb.VerifyVpReply, error) {
-	log.Printf("[VerifyVp][Request]")
-	valid, _, err := core.VerifyVp(in.GetVp(), controller.GetPublicKey)
+	log.Printf("[VerifyVp][Request] definition: %v", in.GetDefinitionId())
+	if in.GetDefinitionId() != "" {
+		def, ok := presentationDefinitions[in.GetDefinitionId()]
+		if !ok {
+			return nil, status.Error(codes.NotFound, "presentation definition not found")
+		}
+		evaluation, holderDid, err := pex.Verify(def, in.GetVp(), controller.GetPublicKey, controller.GetAssertionMethodKey)
+		if err != nil {
+			log.Printf("[VerifyVp][Reply] presentation submission rejected: %v", err)
+			return &pb.VerifyVpReply{Error: err.Error()}, nil
+		}
+		log.Printf("[VerifyVp][Reply] holder %v satisfied %v descriptors", holderDid, len(evaluation.Credentials))
+		return &pb.VerifyVpReply{Result: "verified"}, nil
+	}
+
+	valid, _, err := core.VerifyVpWithKeys(in.GetVp(), controller.GetPublicKey, controller.GetAssertionMethodKey)
 	log.Printf("[VerifyVp][Reply] valid: %v err: %v", valid, err)
 
 	result := ""
 	if valid {
 		result = "verified"
 	}
-	return &pb.VerifyVpReply{Result: result}, nil
+	reply := &pb.VerifyVpReply{Result: result}
+	if err != nil {
+		reply.Error = err.Error()
+	}
+	return reply, nil
+}
+
+// GetPresentationDefinition implements proto-files.RelyingPartyServer
+func (s *server) GetPresentationDefinition(_ context.Context, in *pb.PresentationDefinitionRequest) (*pb.PresentationDefinitionReply, error) {
+	log.Printf("[GetPresentationDefinition][Request] %v", in.GetDefinitionId())
+	def, ok := presentationDefinitions[in.GetDefinitionId()]
+	if !ok {
+		return nil, status.Error(codes.NotFound, "presentation definition not found")
+	}
+	data, err := json.Marshal(def)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	return &pb.PresentationDefinitionReply{PresentationDefinition: string(data)}, nil
 }
 
 func main() {
+	if err := loadPresentationDefinitions(); err != nil {
+		log.Fatalf("could not load presentation definitions: %v", err)
+	}
+	keyManager := kms.NewMemoryKeyManager()
+	tokenKeyID, err := keyManager.Create(kms.KeyTypeECDSA)
+	if err != nil {
+		log.Fatalf("could not create token signing key (%v)", err.Error())
+	}
+	if tokens.PvKey, err = kms.KeySigner(keyManager, tokenKeyID); err != nil {
+		log.Fatalf("invalid token signing key: %v", err)
+	}
 	lis, err := net.Listen("tcp", configs.UseConfig.RelyingPartyPort)
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
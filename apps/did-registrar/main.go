@@ -22,8 +22,12 @@ package main
 import (
 	"byd50-ssi/pkg/did/configs"
 	"byd50-ssi/pkg/did/core"
+	"byd50-ssi/pkg/did/core/dids"
 	"byd50-ssi/pkg/did/core/driver"
+	"byd50-ssi/pkg/did/pkg/database"
+	"byd50-ssi/pkg/did/trust"
 	"context"
+	"encoding/json"
 	"log"
 	"net"
 	"os"
@@ -32,6 +36,8 @@ import (
 
 	pb "byd50-ssi/proto-files"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 )
 
 // server is used to implement proto-files.RegistrarServer.
@@ -98,10 +104,67 @@ func (s *server) ResolveDid(ctx context.Context, in *pb.ResolveDidRequest) (*pb.
 }
 
 // UpdateDID implements proto-files.RegistrarServer
+// The new document is only stored when the proof is signed by a capabilityInvocation key of the current one.
 func (s *server) UpdateDid(ctx context.Context, in *pb.UpdateDidRequest) (*pb.UpdateDidResponse, error) {
 	log.Printf("[UpdateDid] Received DID: %v", in.GetDid())
 
-	return &pb.UpdateDidResponse{Result: ""}, nil
+	slice := strings.Split(in.GetDid(), ":")
+	if len(slice) < 3 || slice[0] != "did" {
+		return nil, status.Error(codes.InvalidArgument, "invalid did")
+	}
+	method := driver.GetDidMethod(slice[1])
+	updater, ok := method.(driver.DidMethodUpdater)
+	if !ok {
+		return nil, status.Errorf(codes.Unimplemented, "did method %s does not support update", slice[1])
+	}
+
+	currentDocument, _, _, err := method.ResolveDid(in.GetDid())
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "resolve did failed: %v", err)
+	}
+	if currentDocument == "" {
+		return nil, status.Error(codes.NotFound, "did not found")
+	}
+	if err := core.VerifyDidUpdate(in.GetProof(), in.GetDid(), currentDocument, in.GetDocument()); err != nil {
+		log.Printf("[UpdateDid] rejected: %v", err)
+		return nil, status.Errorf(codes.PermissionDenied, "did update rejected: %v", err)
+	}
+
+	result, err := updater.UpdateDid(in.GetDid(), in.GetDocument())
+	if err != nil {
+		return nil, status.Errorf(codes.Unavailable, "update did failed: %v", err)
+	}
+	log.Printf("[UpdateDid] reply <~ %v", result)
+
+	return &pb.UpdateDidResponse{Result: result}, nil
+}
+
+// assertionMethodKey resolves an assertionMethod key with the drivers of the registrar itself, so that the
+// trust registry verifies accreditations without calling back into a DID service.
+func assertionMethodKey(did, keyId string) string {
+	slice := strings.Split(did, ":")
+	if len(slice) < 3 || slice[0] != "did" {
+		return ""
+	}
+	method := driver.GetDidMethod(slice[1])
+	if method == nil {
+		return ""
+	}
+	document, _, _, err := method.ResolveDid(did)
+	if err != nil || document == "" {
+		log.Printf("[assertionMethodKey] resolve %v failed: %v", did, err)
+		return ""
+	}
+	var ifDoc dids.DocumentInterface
+	if err := json.Unmarshal([]byte(document), &ifDoc); err != nil {
+		return ""
+	}
+	vm, err := ifDoc.FindVerificationMethod(dids.AssertionMethod, keyId)
+	if err != nil {
+		return ""
+	}
+	pbKeyBase58, _ := vm.PublicKeyAsBase58()
+	return pbKeyBase58
 }
 
 func main() {
@@ -109,8 +172,30 @@ func main() {
 	if err != nil {
 		log.Fatalf("failed to listen: %v", err)
 	}
-	s := grpc.NewServer()
+
+	// The trust registry of accepted issuers; accreditation VCs are verified against assertionMethod keys.
+	trustPath := os.Getenv("TRUST_LEVELDB_PATH")
+	if trustPath == "" {
+		trustPath = "/tmp/trust-registry.db"
+	}
+	db, err := database.InitializePath(trustPath)
+	if err != nil {
+		log.Fatalf("failed to open trust registry: %v", err)
+	}
+	trustStore, err := trust.NewLevelDBStore(db)
+	if err != nil {
+		log.Fatalf("failed to init trust registry store: %v", err)
+	}
+	defer trustStore.Close()
+
+	// direct registry writes are reserved to the operator; without a token issuers are only added through Accredit
+	operatorToken := os.Getenv(trust.OperatorTokenEnv)
+	if operatorToken == "" {
+		log.Printf("%s is not set: trust registry operator writes are disabled", trust.OperatorTokenEnv)
+	}
+	s := grpc.NewServer(grpc.UnaryInterceptor(trust.OperatorInterceptor(operatorToken)))
 	pb.RegisterRegistrarServer(s, &server{})
+	pb.RegisterTrustRegistryServer(s, trust.NewServer(trust.NewRegistry(trustStore, assertionMethodKey)))
 	log.Printf("server listening at %v", lis.Addr())
 	if err := s.Serve(lis); err != nil {
 		log.Fatalf("failed to serve: %v", err)
@@ -86,10 +86,12 @@ func (s *server) UpdateDid(ctx context.Context, in *pb.RegistryUpdateDidRequest)
 	ret, err := registryStore.Has(ctx, in.GetDid())
 	if ret {
 		if err := registryStore.Put(ctx, in.GetDid(), []byte(in.GetDocument())); err != nil {
+			result = "fail"
 			log.Printf("error caused by.. err[%v], ret[%v]", err, ret)
 		}
 		log.Printf("UpdateDid(%v) - [%v] %v", result, in.GetDid(), in.GetDocument())
 	} else {
+		result = "not found"
 		log.Printf("error caused by.. err[%v], ret[%v]", err, ret)
 	}
 
@@ -0,0 +1,63 @@
+package api
+
+import (
+	"byd50-ssi/pkg/did/core/didauth"
+	"github.com/gin-gonic/gin"
+	"net/http"
+)
+
+// accessClaimsKey is the gin context key of the claims RequireAccessToken validated.
+const accessClaimsKey = "didauth.accessClaims"
+
+// RequireAccessToken protects the routes it is installed on with the access tokens of DID Auth (see
+// VerifyDidAuthResponse). Tokens are sent as "Authorization: Bearer <token>", or "Authorization: DPoP <token>" with
+// a DPoP header proving possession of the key DPoP-bound tokens are bound to.
+func RequireAccessToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := authenticate(c); !ok {
+			return
+		}
+		c.Next()
+	}
+}
+
+// authenticate validates the access token of the request and keeps its claims for AccessClaimsFrom. It aborts
+// the request with 401 and returns false when the token is missing or invalid.
+func authenticate(c *gin.Context) (*didauth.AccessClaims, bool) {
+	if claims, ok := AccessClaimsFrom(c); ok {
+		return claims, true
+	}
+	accessToken, ok := didauth.AccessTokenFromHeader(c.GetHeader("Authorization"))
+	if !ok {
+		unauthorized(c, "access token required")
+		return nil, false
+	}
+	claims, err := ensureDidAuthTokens().ValidateAccess(accessToken, c.GetHeader("DPoP"), c.Request.Method, requestURI(c))
+	if err != nil {
+		logReq(c, "RequireAccessToken.Rejected", map[string]string{"error": err.Error()})
+		unauthorized(c, err.Error())
+		return nil, false
+	}
+	c.Set(accessClaimsKey, claims)
+	return claims, true
+}
+
+// AccessClaimsFrom returns the claims of the access token RequireAccessToken validated for the request.
+func AccessClaimsFrom(c *gin.Context) (*didauth.AccessClaims, bool) {
+	value, ok := c.Get(accessClaimsKey)
+	if !ok {
+		return nil, false
+	}
+	claims, ok := value.(*didauth.AccessClaims)
+	return claims, ok
+}
+
+// requestURI is the htu of DPoP proofs for the request: its URL without query and fragment.
+func requestURI(c *gin.Context) string {
+	return baseURL(c) + c.Request.URL.Path
+}
+
+func unauthorized(c *gin.Context, message string) {
+	c.Header("WWW-Authenticate", `Bearer error="invalid_token", DPoP error="invalid_token"`)
+	c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Code: "UNAUTHORIZED", Message: message})
+}
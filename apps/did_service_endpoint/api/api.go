@@ -3,11 +3,11 @@ package api
 import (
 	"byd50-ssi/pkg/did/core"
 	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	"byd50-ssi/pkg/did/core/vcdm"
 	"byd50-ssi/pkg/did/pkg/controller"
-	"crypto/ecdsa"
-	"crypto/x509"
+	"byd50-ssi/pkg/keys"
+	"crypto"
 	"errors"
-	"github.com/btcsuite/btcutil/base58"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
 	"log"
@@ -40,7 +40,8 @@ type ErrorResponse struct {
 
 type CreateVcRequestBody struct {
 	Kid               string                 `json:"kid" example:"did:byd50:1234567890abcdef"`
-	PvKeyBase58       string                 `json:"pv_key_base58" example:"3VZ6oJdR8i1qKX7kH3Yv9d7w7wzgn..."`
+	KeyID             string                 `json:"key_id,omitempty" example:"0b7c3f4e-3d0c-4c36-a5a0-6c3b8a2f7e51"`
+	PvKeyBase58       string                 `json:"pv_key_base58,omitempty" example:"3VZ6oJdR8i1qKX7kH3Yv9d7w7wzgn..."`
 	Type              string                 `json:"type" example:"AlumniCredential"`
 	CredentialSubject map[string]interface{} `json:"credential_subject"`
 	Issuer            string                 `json:"issuer" example:"http://demo-issuer.example"`
@@ -53,12 +54,14 @@ type CreateVcResponse struct {
 }
 
 type VerifyVcRequestBody struct {
-	VcJwt string `json:"vc_jwt" example:"eyJhbGciOiJFUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	VcJwt          string `json:"vc_jwt" example:"eyJhbGciOiJFUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ValidateSchema bool   `json:"validate_schema,omitempty" example:"true"`
 }
 
 type CreateVpRequestBody struct {
 	HolderDid        string   `json:"holder_did" example:"did:byd50:holder123"`
-	PvKeyBase58      string   `json:"pv_key_base58" example:"3VZ6oJdR8i1qKX7kH3Yv9d7w7wzgn..."`
+	KeyID            string   `json:"key_id,omitempty" example:"0b7c3f4e-3d0c-4c36-a5a0-6c3b8a2f7e51"`
+	PvKeyBase58      string   `json:"pv_key_base58,omitempty" example:"3VZ6oJdR8i1qKX7kH3Yv9d7w7wzgn..."`
 	Type             string   `json:"type" example:"CredentialManagerPresentation"`
 	VcJwts           []string `json:"vc_jwts"`
 	Issuer           string   `json:"issuer" example:"client make this vp"`
@@ -92,7 +95,7 @@ func standardClaims(issuer, subject string, expiresInMinutes int) jwt.StandardCl
 	return jwt.StandardClaims{
 		Audience:  "",
 		ExpiresAt: now.Add(time.Duration(expiresInMinutes) * time.Minute).Unix(),
-		Id:        core.RandomString(12),
+		Id:        core.NewCredentialID(),
 		IssuedAt:  now.Unix(),
 		Issuer:    issuer,
 		NotBefore: now.Unix(),
@@ -100,9 +103,10 @@ func standardClaims(issuer, subject string, expiresInMinutes int) jwt.StandardCl
 	}
 }
 
-func parseEcPrivateKeyBase58(pvKeyBase58 string) (*ecdsa.PrivateKey, error) {
-	keyBytes := base58.Decode(pvKeyBase58)
-	return x509.ParseECPrivateKey(keyBytes)
+// parsePrivateKeyBase58 accepts ECDSA (P-256, P-384, secp256k1), Ed25519 and RSA private keys.
+// The JWS alg of the issued JWT follows from the key type.
+func parsePrivateKeyBase58(pvKeyBase58 string) (crypto.PrivateKey, error) {
+	return keys.ParsePrivateKeyBase58(pvKeyBase58)
 }
 
 func logReq(c *gin.Context, action string, fields map[string]string) {
@@ -232,13 +236,15 @@ func GetDidPublicKey(c *gin.Context) {
 
 // CreateVc
 // @Summary Create VC
-// @Description Create a Verifiable Credential (JWT) signed by the issuer's private key.
+// @Description Create a Verifiable Credential (JWT) signed with the issuer key key_id held by this endpoint.
+// @Description credential_subject is validated against the JSON Schema registered for type; violations are returned as 400 with a violations list.
 // @ID createVc
 // @Accept  json
 // @Produce  json
 // @Param   CreateVcRequestBody  body    CreateVcRequestBody  true  "Create VC request"
+// @Param   Authorization  header  string  false  "Bearer <access_token> or DPoP <access_token> of the owner of key_id"
 // @Success 200 {object} CreateVcResponse "ok" example({"vc_jwt":"eyJhbGciOi..."} )
-// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"kid, pv_key_base58, and credential_subject are required"})
+// @Failure 400 {object} SchemaErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"kid and credential_subject are required"})
 // @Failure 500 {object} ErrorResponse "internal error" example({"code":"INTERNAL_ERROR","message":"failed to create vc"})
 // @Security ApiKeyAuth
 // @Router /testapi/vc/create [post]
@@ -257,48 +263,32 @@ func CreateVc(c *gin.Context) {
 		"type":    requestBody.Type,
 		"subject": requestBody.Subject,
 	})
-	if requestBody.Kid == "" || requestBody.PvKeyBase58 == "" || requestBody.CredentialSubject == nil {
+	if requestBody.Kid == "" || requestBody.CredentialSubject == nil {
 		logReq(c, "CreateVc.BadRequest", map[string]string{"error": "missing required fields"})
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Code:    "INVALID_PARAM",
-			Message: "kid, pv_key_base58, and credential_subject are required",
+			Message: "kid and credential_subject are required",
 		})
 		return
 	}
-	pvKey, err := parseEcPrivateKeyBase58(requestBody.PvKeyBase58)
-	if err != nil {
-		logReq(c, "CreateVc.BadRequest", map[string]string{"error": "invalid pv_key_base58"})
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Code:    "INVALID_PARAM",
-			Message: "invalid pv_key_base58",
-		})
+	pvKey, ok := requestSigner(c, requestBody.KeyID, requestBody.PvKeyBase58)
+	if !ok {
 		return
 	}
-	typArray := []string{"VerifiableCredential"}
-	if requestBody.Type != "" {
-		typArray = append(typArray, requestBody.Type)
-	}
-	claims := map[string]interface{}{
-		"@context": []string{
-			"https://www.w3.org/2018/credentials/v1",
-			"https://www.w3.org/2018/credentials/examples/v1",
-		},
-		"type":              typArray,
-		"credentialSubject": requestBody.CredentialSubject,
-	}
 	issuer := requestBody.Issuer
 	if issuer == "" {
 		issuer = requestBody.Kid
 	}
 	stdClaims := standardClaims(issuer, requestBody.Subject, requestBody.ExpiresInMinutes)
-	vcClaims := byd50_jwt.VcClaims{
-		core.RandomString(12),
-		claims,
-		stdClaims,
+	vc := vcdm.NewCredentialV1(requestBody.Type, vcdm.CredentialSubject{Claims: requestBody.CredentialSubject})
+	vcJwt, err := core.CreateVcWithCredential(requestBody.Kid, vc, stdClaims, pvKey)
+	if resp, ok := schemaError(err); ok {
+		logReq(c, "CreateVc.BadRequest", map[string]string{"error": "schema violation", "schema": resp.SchemaID})
+		c.JSON(http.StatusBadRequest, resp)
+		return
 	}
-	vcJwt := core.CreateVcWithClaims(requestBody.Kid, vcClaims, pvKey)
-	if vcJwt == "" {
-		logReq(c, "CreateVc.Error", map[string]string{"error": "create vc failed"})
+	if err != nil {
+		logReq(c, "CreateVc.Error", map[string]string{"error": err.Error()})
 		c.JSON(http.StatusInternalServerError, ErrorResponse{
 			Code:    "INTERNAL_ERROR",
 			Message: "failed to create vc",
@@ -311,7 +301,7 @@ func CreateVc(c *gin.Context) {
 
 // VerifyVc
 // @Summary Verify VC
-// @Description Verify a VC (JWT) using DID resolver for public key lookup.
+// @Description Verify a VC (JWT) using DID resolver for public key lookup. With validate_schema, the credentialSubject is also checked against its credential schemas.
 // @ID verifyVc
 // @Accept  json
 // @Produce  json
@@ -339,7 +329,9 @@ func VerifyVc(c *gin.Context) {
 		return
 	}
 	logReq(c, "VerifyVc.Request", map[string]string{"vc_len": strconv.Itoa(len(requestBody.VcJwt))})
-	ok, err := core.VerifyVc(requestBody.VcJwt, controller.GetPublicKey)
+	ok, err := core.VerifyVcWithOptions(requestBody.VcJwt, controller.GetAssertionMethodKey, core.VerifyOptions{
+		ValidateSchema: requestBody.ValidateSchema,
+	})
 	if err != nil {
 		logReq(c, "VerifyVc.Result", map[string]string{"valid": "false", "error": err.Error()})
 		c.JSON(http.StatusOK, VerifyResponse{Valid: false, Error: err.Error()})
@@ -356,8 +348,9 @@ func VerifyVc(c *gin.Context) {
 // @Accept  json
 // @Produce  json
 // @Param   CreateVpRequestBody  body    CreateVpRequestBody  true  "Create VP request"
+// @Param   Authorization  header  string  false  "Bearer <access_token> or DPoP <access_token> of the owner of key_id"
 // @Success 200 {object} CreateVpResponse "ok" example({"vp_jwt":"eyJhbGciOi..."} )
-// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"holder_did, pv_key_base58, and vc_jwts are required"})
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"holder_did is required"})
 // @Failure 500 {object} ErrorResponse "internal error" example({"code":"INTERNAL_ERROR","message":"failed to create vp"})
 // @Security ApiKeyAuth
 // @Router /testapi/vp/create [post]
@@ -371,11 +364,11 @@ func CreateVp(c *gin.Context) {
 		})
 		return
 	}
-	if requestBody.HolderDid == "" || requestBody.PvKeyBase58 == "" {
+	if requestBody.HolderDid == "" {
 		logReq(c, "CreateVp.BadRequest", map[string]string{"error": "missing required fields"})
 		c.JSON(http.StatusBadRequest, ErrorResponse{
 			Code:    "INVALID_PARAM",
-			Message: "holder_did and pv_key_base58 are required",
+			Message: "holder_did is required",
 		})
 		return
 	}
@@ -387,13 +380,8 @@ func CreateVp(c *gin.Context) {
 		})
 		return
 	}
-	pvKey, err := parseEcPrivateKeyBase58(requestBody.PvKeyBase58)
-	if err != nil {
-		logReq(c, "CreateVp.BadRequest", map[string]string{"error": "invalid pv_key_base58"})
-		c.JSON(http.StatusBadRequest, ErrorResponse{
-			Code:    "INVALID_PARAM",
-			Message: "invalid pv_key_base58",
-		})
+	pvKey, ok := requestSigner(c, requestBody.KeyID, requestBody.PvKeyBase58)
+	if !ok {
 		return
 	}
 	issuer := requestBody.Issuer
@@ -467,7 +455,7 @@ func VerifyVp(c *gin.Context) {
 		return
 	}
 	logReq(c, "VerifyVp.Request", map[string]string{"vp_len": strconv.Itoa(len(requestBody.VpJwt))})
-	ok, did, err := core.VerifyVp(requestBody.VpJwt, controller.GetPublicKey)
+	ok, did, err := core.VerifyVpWithKeys(requestBody.VpJwt, controller.GetPublicKey, controller.GetAssertionMethodKey)
 	if err != nil || !ok {
 		if err == nil {
 			err = errors.New("vp signature invalid")
@@ -491,7 +479,7 @@ func VerifyVp(c *gin.Context) {
 		}
 		vcJwts, _ := extractVcJwtsFromVp(claims)
 		if len(vcJwts) > 0 {
-			vcValid, _ := core.VerifyVc(vcJwts[0], controller.GetPublicKey)
+			vcValid, _ := core.VerifyVc(vcJwts[0], controller.GetAssertionMethodKey)
 			if !vcValid {
 				c.JSON(http.StatusOK, VerifyResponse{Valid: false, Error: "vc signature invalid"})
 				return
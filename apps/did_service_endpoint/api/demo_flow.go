@@ -2,19 +2,16 @@ package api
 
 import (
 	"byd50-ssi/pkg/did/core"
-	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	"byd50-ssi/pkg/did/core/vcdm"
 	"byd50-ssi/pkg/did/kms"
 	"byd50-ssi/pkg/did/pkg/controller"
-	"crypto/ecdsa"
-	"crypto/elliptic"
-	"crypto/rand"
-	"crypto/x509"
+	"crypto"
 	"errors"
-	"github.com/btcsuite/btcutil/base58"
 	"github.com/gin-gonic/gin"
 	"github.com/golang-jwt/jwt"
 	"log"
 	"net/http"
+	"os"
 	"sync"
 	"time"
 )
@@ -64,8 +61,8 @@ type IssueRentalResponse struct {
 
 type demoActor struct {
 	Did         string
-	PvKey       *ecdsa.PrivateKey
-	PvKeyBase58 string
+	KeyID       string
+	PvKey       crypto.Signer
 	PbKeyBase58 string
 }
 
@@ -84,21 +81,91 @@ func ensureDemoActors() {
 	})
 }
 
+// demoKeystore keeps the identities of the demo actors across restarts when KEYSTORE_PASSPHRASE is set.
+var demoKeystore struct {
+	once     sync.Once
+	keystore *kms.Keystore
+}
+
+func openDemoKeystore() *kms.Keystore {
+	demoKeystore.once.Do(func() {
+		passphrase := os.Getenv("KEYSTORE_PASSPHRASE")
+		if passphrase == "" {
+			log.Printf("[did_service_endpoint][demo] KEYSTORE_PASSPHRASE is not set, demo actor DIDs will not survive a restart")
+			return
+		}
+		keystorePath := os.Getenv("SERVICE_KEYSTORE_PATH")
+		if keystorePath == "" {
+			keystorePath = "/tmp/did-service-endpoint-keystore.json"
+		}
+		keystore, err := kms.OpenKeystore(keystorePath, passphrase)
+		if err != nil {
+			log.Printf("[did_service_endpoint][demo] could not open keystore %s: %v", keystorePath, err)
+			return
+		}
+		demoKeystore.keystore = keystore
+	})
+	return demoKeystore.keystore
+}
+
+// createDemoActor returns the demo actor tag, loaded from the keystore when it was created before. Its key is kept in
+// serviceKeys without an owner, so clients cannot sign with it.
 func createDemoActor(tag string) demoActor {
-	pvKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
-	pbKey := &pvKey.PublicKey
-	pvKeyBase58 := kms.ExportPrivateKeyAsBase58(pvKey)
-	pbKeyBase58 := kms.ExportPublicKeyAsBase58(pbKey)
-	did := controller.CreateDID(pbKeyBase58, "byd50")
-	if did == "" {
+	if keystore := openDemoKeystore(); keystore != nil {
+		dkms, err := keystore.Identity(tag, "signing", kms.KeyTypeECDSA, func(pbKeyBase58 string) (string, error) {
+			return controller.CreateDIDWithErr(pbKeyBase58, "byd50")
+		})
+		var actor demoActor
+		if err == nil {
+			actor, err = importDemoActor(dkms)
+		}
+		if err == nil {
+			return actor
+		}
+		log.Printf("[did_service_endpoint][demo] failed to load identity of %s: %v", tag, err)
+	}
+	keyID, err := serviceKeys.manager.Create(kms.KeyTypeECDSA)
+	if err != nil {
+		log.Printf("[did_service_endpoint][demo] failed to create key for %s: %v", tag, err)
+		return demoActor{}
+	}
+	actor, err := demoActorOf(keyID)
+	if err != nil {
+		log.Printf("[did_service_endpoint][demo] failed to load key for %s: %v", tag, err)
+		return demoActor{}
+	}
+	actor.Did = controller.CreateDID(actor.PbKeyBase58, "byd50")
+	if actor.Did == "" {
 		log.Printf("[did_service_endpoint][demo] failed to create did for %s", tag)
 	}
-	return demoActor{
-		Did:         did,
-		PvKey:       pvKey,
-		PvKeyBase58: pvKeyBase58,
-		PbKeyBase58: pbKeyBase58,
+	return actor
+}
+
+// importDemoActor moves the key of an identity loaded from the keystore into serviceKeys.
+func importDemoActor(dkms kms.KMS) (demoActor, error) {
+	pvKey, err := dkms.Signer()
+	if err != nil {
+		return demoActor{}, err
+	}
+	keyID, err := serviceKeys.manager.Import(pvKey)
+	if err != nil {
+		return demoActor{}, err
 	}
+	actor, err := demoActorOf(keyID)
+	actor.Did = dkms.Did()
+	return actor, err
+}
+
+func demoActorOf(keyID string) (demoActor, error) {
+	signer, err := kms.KeySigner(serviceKeys.manager, keyID)
+	if err != nil {
+		return demoActor{}, err
+	}
+	pbKeyBase58, err := kms.PublicKeyBase58(serviceKeys.manager, keyID)
+	if err != nil {
+		return demoActor{}, err
+	}
+	return demoActor{KeyID: keyID, PvKey: signer, PbKeyBase58: pbKeyBase58}, nil
 }
 
 // GetDemoActors
@@ -196,9 +263,9 @@ func IssueLicense(c *gin.Context) {
 	}
 
 	subject := map[string]interface{}{
-		"holderDid":  requestBody.HolderDid,
+		"holderDid":   requestBody.HolderDid,
 		"licenseType": "Type-1",
-		"country":    "KR",
+		"country":     "KR",
 	}
 	stdClaims := standardClaimsWithSeconds(
 		demoActors.license.Did,
@@ -206,19 +273,13 @@ func IssueLicense(c *gin.Context) {
 		requestBody.ExpiresInSeconds,
 		requestBody.ExpiresInMinutes,
 	)
-	claims := byd50_jwt.VcClaims{
-		core.RandomString(12),
-		map[string]interface{}{
-			"@context": []string{
-				"https://www.w3.org/2018/credentials/v1",
-				"https://www.w3.org/2018/credentials/examples/v1",
-			},
-			"type":              []string{"VerifiableCredential", "DriverLicenseCredential"},
-			"credentialSubject": subject,
-		},
-		stdClaims,
-	}
-	vcJwt := core.CreateVcWithClaims(demoActors.license.Did, claims, demoActors.license.PvKey)
+	vc := vcdm.NewCredentialV1("DriverLicenseCredential", vcdm.CredentialSubject{Claims: subject})
+	vcJwt, err := core.CreateVcWithCredential(demoActors.license.Did, vc, stdClaims, demoActors.license.PvKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Code: "INTERNAL_ERROR", Message: err.Error()})
+		return
+	}
+	recordIssued(vcJwt)
 	c.JSON(http.StatusOK, IssueLicenseResponse{
 		SimplePresentationValid: true,
 		VcJwt:                   vcJwt,
@@ -261,7 +322,7 @@ func IssueRental(c *gin.Context) {
 	vcNotExpired := false
 	holderMatch := false
 	if sigValid && len(vcJwts) > 0 {
-		vcValid, _ = core.VerifyVc(vcJwts[0], controller.GetPublicKey)
+		vcValid, _ = core.VerifyVc(vcJwts[0], controller.GetAssertionMethodKey)
 		vcNotExpired = !vcExpired(vcJwts[0])
 		holderDid := vcHolderDid(vcJwts[0])
 		holderMatch = holderDid != "" && holderDid == vpDid
@@ -299,19 +360,13 @@ func IssueRental(c *gin.Context) {
 		requestBody.ExpiresInSeconds,
 		requestBody.ExpiresInMinutes,
 	)
-	claims := byd50_jwt.VcClaims{
-		core.RandomString(12),
-		map[string]interface{}{
-			"@context": []string{
-				"https://www.w3.org/2018/credentials/v1",
-				"https://www.w3.org/2018/credentials/examples/v1",
-			},
-			"type":              []string{"VerifiableCredential", "RentalCarAgreementCredential"},
-			"credentialSubject": subject,
-		},
-		stdClaims,
-	}
-	vcJwt := core.CreateVcWithClaims(demoActors.rental.Did, claims, demoActors.rental.PvKey)
+	vc := vcdm.NewCredentialV1("RentalCarAgreementCredential", vcdm.CredentialSubject{Claims: subject})
+	vcJwt, err := core.CreateVcWithCredential(demoActors.rental.Did, vc, stdClaims, demoActors.rental.PvKey)
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Code: "INTERNAL_ERROR", Message: err.Error()})
+		return
+	}
+	recordIssued(vcJwt)
 	c.JSON(http.StatusOK, IssueRentalResponse{
 		VpSignatureValid: true,
 		AudNonceValid:    true,
@@ -323,7 +378,7 @@ func IssueRental(c *gin.Context) {
 }
 
 func verifyVpExpectations(vpJwt, expectedAud, expectedNonce string) (bool, string, bool, bool, []string, error) {
-	ok, did, err := core.VerifyVp(vpJwt, controller.GetPublicKey)
+	ok, did, err := core.VerifyVpWithKeys(vpJwt, controller.GetPublicKey, controller.GetAssertionMethodKey)
 	if err != nil || !ok {
 		if err == nil {
 			err = errors.New("vp signature invalid")
@@ -362,27 +417,14 @@ func matchAudience(audClaim interface{}, expected string) bool {
 }
 
 func extractVcJwtsFromVp(claims jwt.MapClaims) ([]string, error) {
-	vp, ok := claims["vp"].(map[string]interface{})
-	if !ok {
+	if _, ok := claims["vp"].(map[string]interface{}); !ok {
 		return nil, errors.New("vp claim missing")
 	}
-	raw := vp["verifiableCredential"]
-	switch v := raw.(type) {
-	case string:
-		return []string{v}, nil
-	case []interface{}:
-		var arr []string
-		for _, a := range v {
-			if s, ok := a.(string); ok {
-				arr = append(arr, s)
-			}
-		}
-		return arr, nil
-	case []string:
-		return v, nil
-	default:
-		return nil, errors.New("unsupported vc list")
+	vp, err := vcdm.PresentationFromMapClaims(claims)
+	if err != nil {
+		return nil, err
 	}
+	return vp.CredentialJwts(), nil
 }
 
 func vcExpired(vcJwt string) bool {
@@ -398,32 +440,11 @@ func vcExpired(vcJwt string) bool {
 }
 
 func parseVcClaims(vcJwt string) (jwt.MapClaims, error) {
-	parseToken, err := jwt.Parse(vcJwt, func(token *jwt.Token) (interface{}, error) {
-		if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
-			return nil, errors.New("unexpected signing method")
-		}
-		did, ok := token.Header["kid"].(string)
-		if !ok || did == "" {
-			return nil, errors.New("missing kid header")
-		}
-		pbKeyBase58 := controller.GetPublicKey(did, "")
-		pbKeyBytes := base58.Decode(pbKeyBase58)
-		if len(pbKeyBytes) == 0 {
-			return nil, errors.New("invalid public key base58")
-		}
-		pbKey, err := x509.ParsePKIXPublicKey(pbKeyBytes)
-		if err != nil {
-			return nil, err
-		}
-		return pbKey, nil
-	})
+	_, claims, err := core.GetVcMapClaims(vcJwt, controller.GetAssertionMethodKey)
 	if err != nil {
 		return nil, err
 	}
-	if claims, ok := parseToken.Claims.(jwt.MapClaims); ok && parseToken.Valid {
-		return claims, nil
-	}
-	return nil, errors.New("invalid vc claims")
+	return claims, nil
 }
 
 func standardClaimsWithSeconds(issuer, subject string, expiresInSeconds, expiresInMinutes int) jwt.StandardClaims {
@@ -432,7 +453,7 @@ func standardClaimsWithSeconds(issuer, subject string, expiresInSeconds, expires
 		return jwt.StandardClaims{
 			Audience:  "",
 			ExpiresAt: now.Add(time.Duration(expiresInSeconds) * time.Second).Unix(),
-			Id:        core.RandomString(12),
+			Id:        core.NewCredentialID(),
 			IssuedAt:  now.Unix(),
 			Issuer:    issuer,
 			NotBefore: now.Unix(),
@@ -447,21 +468,21 @@ func vcHolderDid(vcJwt string) string {
 	if err != nil || claims == nil {
 		return ""
 	}
-	vc, ok := claims["vc"].(map[string]interface{})
-	if !ok || vc == nil {
+	if _, ok := claims["vc"].(map[string]interface{}); !ok {
 		return ""
 	}
-	subject, ok := vc["credentialSubject"].(map[string]interface{})
-	if !ok || subject == nil {
+	vc, err := vcdm.CredentialFromMapClaims(claims)
+	if err != nil || len(vc.CredentialSubject) != 1 {
 		return ""
 	}
-	if v, ok := subject["holderDid"].(string); ok {
+	subject := vc.CredentialSubject[0]
+	if v, ok := subject.Claims["holderDid"].(string); ok {
 		return v
 	}
-	if v, ok := subject["id"].(string); ok {
-		return v
+	if subject.ID != "" {
+		return subject.ID
 	}
-	if v, ok := subject["did"].(string); ok {
+	if v, ok := subject.Claims["did"].(string); ok {
 		return v
 	}
 	return ""
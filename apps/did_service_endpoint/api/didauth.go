@@ -0,0 +1,323 @@
+package api
+
+import (
+	"byd50-ssi/pkg/did/configs"
+	"byd50-ssi/pkg/did/core/didauth"
+	"byd50-ssi/pkg/did/pkg/controller"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// didAuthPath is the path of the DID Auth endpoints, which is also the audience of their challenges.
+const didAuthPath = "/v2/testapi/didauth"
+
+// didAuthenticator verifies challenges signed with an authentication key of the DID, resolved through the controller.
+var didAuthenticator = &didauth.Authenticator{GetPbKey: controller.GetPublicKey}
+
+// didAuthTokens issues the access and refresh tokens of DIDs authenticated by this endpoint, signed by a
+// demo relying party DID created on first use.
+var didAuthTokens struct {
+	once    sync.Once
+	service *didauth.TokenService
+}
+
+func ensureDidAuthTokens() *didauth.TokenService {
+	didAuthTokens.once.Do(func() {
+		rp := createDemoActor("didauth-rp")
+		didAuthTokens.service = &didauth.TokenService{
+			Issuer:    rp.Did,
+			PvKey:     rp.PvKey,
+			ClockSkew: configs.UseConfig.DidAuthClockSkew,
+		}
+		log.Printf("[did_service_endpoint][didauth] token issuer=%s", rp.Did)
+	})
+	return didAuthTokens.service
+}
+
+type DidAuthChallengeRequestBody struct {
+	Did string `json:"did" example:"did:byd50:holder123"`
+}
+
+type DidAuthSignRequestBody struct {
+	Challenge *didauth.SignedChallenge `json:"challenge"`
+	// Kid is the DID, or the DID URL of the authentication key the signing key belongs to.
+	Kid         string `json:"kid" example:"did:byd50:holder123#key-1"`
+	KeyID       string `json:"key_id,omitempty" example:"0b7c3f4e-3d0c-4c36-a5a0-6c3b8a2f7e51"`
+	PvKeyBase58 string `json:"pv_key_base58,omitempty" example:"3VZ6oJdR8i1qKX7kH3Yv9d7w7wzgn..."`
+}
+
+type DidAuthSignResponse struct {
+	SignedChallenge string `json:"signed_challenge"`
+}
+
+type DidAuthVerifyRequestBody struct {
+	SessionID       string `json:"session_id" example:"7b0f0f8e-3c5e-4f5a-9d0e-2a4c1c3f9a10"`
+	Did             string `json:"did" example:"did:byd50:holder123"`
+	SignedChallenge string `json:"signed_challenge" example:"eyJhbGciOiJFUzI1NiIsImtpZCI6ImRpZDpieWQ1MDpob2xkZXIxMjMiLCJ0eXAiOiJkaWRhdXRoK2p3dCJ9..."`
+}
+
+type DidAuthVerifyResponse struct {
+	Did string `json:"did"`
+	*didauth.TokenResponse
+}
+
+type DidAuthTokenRequestBody struct {
+	RefreshToken string `json:"refresh_token" example:"8Wf3nVYk2Q9uXc..."`
+}
+
+type DidAuthRevokeRequestBody struct {
+	// Token is a refresh token, which ends the session, or an access token.
+	Token string `json:"token" example:"8Wf3nVYk2Q9uXc..."`
+}
+
+type DidAuthDPoPProofRequestBody struct {
+	Method string `json:"method" example:"GET"`
+	URI    string `json:"uri" example:"http://localhost:8080/v2/testapi/didauth/session"`
+	// AccessToken is the token the request carries, if any.
+	AccessToken string `json:"access_token,omitempty"`
+	KeyID       string `json:"key_id,omitempty" example:"0b7c3f4e-3d0c-4c36-a5a0-6c3b8a2f7e51"`
+	PvKeyBase58 string `json:"pv_key_base58,omitempty" example:"3VZ6oJdR8i1qKX7kH3Yv9d7w7wzgn..."`
+}
+
+type DidAuthDPoPProofResponse struct {
+	DPoPProof string `json:"dpop_proof"`
+}
+
+// CreateDidAuthChallenge
+// @Summary Create DID Auth challenge
+// @Description Relying party side: issue a single-use challenge (nonce, aud, domain, iat, exp) bound to the DID, to be
+// @Description signed by the holder with any authentication key of the DID.
+// @ID createDidAuthChallenge
+// @Accept  json
+// @Produce  json
+// @Param   DidAuthChallengeRequestBody  body    DidAuthChallengeRequestBody  true  "Challenge request"
+// @Success 200 {object} didauth.SignedChallenge "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"did is required"})
+// @Security ApiKeyAuth
+// @Router /testapi/didauth/challenges [post]
+func CreateDidAuthChallenge(c *gin.Context) {
+	var requestBody DidAuthChallengeRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "CreateDidAuthChallenge.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "CreateDidAuthChallenge.Request", map[string]string{"did": requestBody.Did})
+	challenge, err := didAuthenticator.Challenge(requestBody.Did, baseURL(c)+didAuthPath, c.Request.Host)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, challenge)
+}
+
+// SignDidAuthChallenge
+// @Summary Sign DID Auth challenge
+// @Description Holder side: sign a challenge as a compact JWS (typ didauth+jwt) with the key key_id, which should belong
+// @Description to the authentication key kid of the DID.
+// @ID signDidAuthChallenge
+// @Accept  json
+// @Produce  json
+// @Param   DidAuthSignRequestBody  body    DidAuthSignRequestBody  true  "Sign request"
+// @Param   Authorization  header  string  false  "Bearer <access_token> or DPoP <access_token> of the owner of key_id"
+// @Success 200 {object} DidAuthSignResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"key_id is required"})
+// @Security ApiKeyAuth
+// @Router /testapi/didauth/sign [post]
+func SignDidAuthChallenge(c *gin.Context) {
+	var requestBody DidAuthSignRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "SignDidAuthChallenge.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "SignDidAuthChallenge.Request", map[string]string{"kid": requestBody.Kid})
+	pvKey, ok := requestSigner(c, requestBody.KeyID, requestBody.PvKeyBase58)
+	if !ok {
+		return
+	}
+	signed, err := didauth.SignChallenge(requestBody.Challenge, requestBody.Kid, pvKey)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, DidAuthSignResponse{SignedChallenge: signed})
+}
+
+// VerifyDidAuthResponse
+// @Summary Verify DID Auth response
+// @Description Relying party side: verify the signed challenge of a session against the authentication keys of the
+// @Description resolved DID document and return an access token (JWT, typ at+jwt) and a refresh token. With a DPoP
+// @Description header (a proof for this request) the tokens are bound to the key of the proof. A session accepts a
+// @Description single attempt.
+// @ID verifyDidAuthResponse
+// @Accept  json
+// @Produce  json
+// @Param   DidAuthVerifyRequestBody  body    DidAuthVerifyRequestBody  true  "Verify request"
+// @Param   DPoP  header  string  false  "DPoP proof"
+// @Success 200 {object} DidAuthVerifyResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"signed challenge aud mismatch"})
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"authentication session not found"})
+// @Security ApiKeyAuth
+// @Router /testapi/didauth/verify [post]
+func VerifyDidAuthResponse(c *gin.Context) {
+	var requestBody DidAuthVerifyRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "VerifyDidAuthResponse.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "VerifyDidAuthResponse.Request", map[string]string{"did": requestBody.Did, "session": requestBody.SessionID})
+	tokens := ensureDidAuthTokens()
+	jkt, ok := dpopThumbprint(c, tokens)
+	if !ok {
+		return
+	}
+	if err := didAuthenticator.Verify(requestBody.SessionID, requestBody.Did, requestBody.SignedChallenge); err != nil {
+		logReq(c, "VerifyDidAuthResponse.Rejected", map[string]string{"did": requestBody.Did, "error": err.Error()})
+		serviceError(c, err)
+		return
+	}
+	response, err := tokens.Issue(requestBody.Did, jkt)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	logReq(c, "VerifyDidAuthResponse.Success", map[string]string{"did": requestBody.Did, "tokenType": response.TokenType})
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, DidAuthVerifyResponse{Did: requestBody.Did, TokenResponse: response})
+}
+
+// RefreshDidAuthToken
+// @Summary Refresh DID Auth tokens
+// @Description Exchange a refresh token for a new access and refresh token. Refresh tokens are single-use; DPoP-bound
+// @Description tokens need a DPoP header with a proof of the same key.
+// @ID refreshDidAuthToken
+// @Accept  json
+// @Produce  json
+// @Param   DidAuthTokenRequestBody  body    DidAuthTokenRequestBody  true  "Refresh request"
+// @Param   DPoP  header  string  false  "DPoP proof"
+// @Success 200 {object} didauth.TokenResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"refresh token is unknown, used or revoked"})
+// @Security ApiKeyAuth
+// @Router /testapi/didauth/token [post]
+func RefreshDidAuthToken(c *gin.Context) {
+	var requestBody DidAuthTokenRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "RefreshDidAuthToken.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "RefreshDidAuthToken.Request", map[string]string{})
+	tokens := ensureDidAuthTokens()
+	jkt, ok := dpopThumbprint(c, tokens)
+	if !ok {
+		return
+	}
+	response, err := tokens.Refresh(requestBody.RefreshToken, jkt)
+	if err != nil {
+		logReq(c, "RefreshDidAuthToken.Rejected", map[string]string{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: err.Error()})
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, response)
+}
+
+// RevokeDidAuthToken
+// @Summary Revoke DID Auth token
+// @Description Revoke a refresh token, ending its session and the access token issued with it, or an access token.
+// @Description Unknown tokens are ignored.
+// @ID revokeDidAuthToken
+// @Accept  json
+// @Produce  json
+// @Param   DidAuthRevokeRequestBody  body    DidAuthRevokeRequestBody  true  "Revoke request"
+// @Success 200 {object} map[string]string "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"invalid json body"})
+// @Security ApiKeyAuth
+// @Router /testapi/didauth/revoke [post]
+func RevokeDidAuthToken(c *gin.Context) {
+	var requestBody DidAuthRevokeRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "RevokeDidAuthToken.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "RevokeDidAuthToken.Request", map[string]string{})
+	if err := ensureDidAuthTokens().Revoke(requestBody.Token); err != nil {
+		serviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// CreateDidAuthDPoPProof
+// @Summary Create DPoP proof
+// @Description Holder side: prove possession of the key key_id for a request of method to uri (the full URL without
+// @Description query), covering access_token when the request carries one. Send the proof in the DPoP header.
+// @ID createDidAuthDPoPProof
+// @Accept  json
+// @Produce  json
+// @Param   DidAuthDPoPProofRequestBody  body    DidAuthDPoPProofRequestBody  true  "DPoP proof request"
+// @Param   Authorization  header  string  false  "Bearer <access_token> or DPoP <access_token> of the owner of key_id"
+// @Success 200 {object} DidAuthDPoPProofResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"key_id is required"})
+// @Security ApiKeyAuth
+// @Router /testapi/didauth/dpop-proof [post]
+func CreateDidAuthDPoPProof(c *gin.Context) {
+	var requestBody DidAuthDPoPProofRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "CreateDidAuthDPoPProof.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "CreateDidAuthDPoPProof.Request", map[string]string{"method": requestBody.Method, "uri": requestBody.URI})
+	if requestBody.Method == "" || requestBody.URI == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "method and uri are required"})
+		return
+	}
+	signer, ok := requestSigner(c, requestBody.KeyID, requestBody.PvKeyBase58)
+	if !ok {
+		return
+	}
+	proof, err := didauth.CreateDPoPProof(requestBody.Method, requestBody.URI, requestBody.AccessToken, signer)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, DidAuthDPoPProofResponse{DPoPProof: proof})
+}
+
+// GetDidAuthSession
+// @Summary Get DID Auth session
+// @Description Return the claims of the access token of the request (Authorization: Bearer or DPoP, with a DPoP
+// @Description header for DPoP-bound tokens). An example of an endpoint protected by RequireAccessToken.
+// @ID getDidAuthSession
+// @Produce  json
+// @Param   Authorization  header  string  true  "Bearer <access_token> or DPoP <access_token>"
+// @Param   DPoP  header  string  false  "DPoP proof"
+// @Success 200 {object} didauth.AccessClaims "ok"
+// @Failure 401 {object} ErrorResponse "unauthorized" example({"code":"UNAUTHORIZED","message":"access token revoked"})
+// @Router /testapi/didauth/session [get]
+func GetDidAuthSession(c *gin.Context) {
+	claims, _ := AccessClaimsFrom(c)
+	c.JSON(http.StatusOK, claims)
+}
+
+// dpopThumbprint verifies the optional DPoP header of a token request and returns the thumbprint of its key.
+// It writes the error response and returns false when the proof is invalid.
+func dpopThumbprint(c *gin.Context, tokens *didauth.TokenService) (string, bool) {
+	proof := c.GetHeader("DPoP")
+	if proof == "" {
+		return "", true
+	}
+	jkt, err := tokens.VerifyDPoPProof(proof, c.Request.Method, requestURI(c))
+	if err != nil {
+		logReq(c, "DPoP.Rejected", map[string]string{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: err.Error()})
+		return "", false
+	}
+	return jkt, true
+}
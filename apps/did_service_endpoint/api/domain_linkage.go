@@ -0,0 +1,232 @@
+package api
+
+import (
+	"byd50-ssi/pkg/did/core/dids"
+	"byd50-ssi/pkg/did/core/domainlinkage"
+	"byd50-ssi/pkg/did/pkg/controller"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Environment variables of the DIDs the operator allows to link to this endpoint (comma separated) and of the
+// origin of this endpoint. Without DIDs nothing can be published; without an origin the origin requests are
+// made to is used.
+const (
+	linkedDidsEnv   = "DOMAIN_LINKAGE_DIDS"
+	linkedOriginEnv = "DOMAIN_LINKAGE_ORIGIN"
+)
+
+// linkedDids holds the Domain Linkage Credentials published at the well-known DID configuration of this endpoint,
+// one per DID: publishing again replaces the credential of the DID.
+var linkedDids = struct {
+	sync.Mutex
+	dids []string
+	jwts []string
+}{}
+
+// linkableDid reports whether the operator allows did to be linked to this endpoint.
+func linkableDid(did string) bool {
+	for _, allowed := range strings.Split(os.Getenv(linkedDidsEnv), ",") {
+		if allowed = strings.TrimSpace(allowed); allowed != "" && allowed == did {
+			return true
+		}
+	}
+	return false
+}
+
+// ownOrigin is the origin of this endpoint, the only origin its well-known DID configuration can link.
+func ownOrigin(c *gin.Context) (string, error) {
+	if origin := os.Getenv(linkedOriginEnv); origin != "" {
+		return domainlinkage.NormalizeOrigin(origin)
+	}
+	return domainlinkage.NormalizeOrigin(baseURL(c))
+}
+
+type IssueDomainLinkageRequestBody struct {
+	Did              string `json:"did" example:"did:byd50:1234567890abcdef"`
+	KeyID            string `json:"key_id,omitempty" example:"0b7c3f4e-3d0c-4c36-a5a0-6c3b8a2f7e51"`
+	PvKeyBase58      string `json:"pv_key_base58,omitempty" example:"3VZ6oJdR8i1qKX7kH3Yv9d7w7wzgn..."`
+	Origin           string `json:"origin" example:"https://issuer.example.com"`
+	ExpiresInMinutes int    `json:"expires_in_minutes" example:"525600"`
+}
+
+type IssueDomainLinkageResponse struct {
+	DomainLinkageCredential string `json:"domain_linkage_credential"`
+}
+
+type VerifyDomainLinkageRequestBody struct {
+	Did    string `json:"did" example:"did:byd50:1234567890abcdef"`
+	Origin string `json:"origin,omitempty" example:"https://issuer.example.com"`
+}
+
+type VerifyDomainLinkageResponse struct {
+	Did     string                       `json:"did"`
+	Results []domainlinkage.OriginResult `json:"results"`
+}
+
+// GetDidConfiguration
+// @Summary Well-known DID configuration
+// @Description Serve the DIF Well-Known DID Configuration with the Domain Linkage Credentials issued by this endpoint.
+// @ID getDidConfiguration
+// @Produce  json
+// @Success 200 {object} domainlinkage.Configuration "ok"
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"no linked dids"})
+// @Router /.well-known/did-configuration.json [get]
+func GetDidConfiguration(c *gin.Context) {
+	linkedDids.Lock()
+	jwts := append([]string(nil), linkedDids.jwts...)
+	linkedDids.Unlock()
+	if len(jwts) == 0 {
+		c.JSON(http.StatusNotFound, ErrorResponse{Code: "NOT_FOUND", Message: "no linked dids"})
+		return
+	}
+	c.JSON(http.StatusOK, domainlinkage.NewConfiguration(jwts...))
+}
+
+// IssueDomainLinkage
+// @Summary Issue Domain Linkage Credential
+// @Description Sign a Domain Linkage Credential linking did to origin and publish it in the well-known DID configuration,
+// @Description replacing the previous credential of did. Only the DIDs listed in DOMAIN_LINKAGE_DIDS can be linked, and
+// @Description origin must be the origin of this endpoint (DOMAIN_LINKAGE_ORIGIN). The key key_id should belong to an
+// @Description assertionMethod key of the DID.
+// @ID issueDomainLinkage
+// @Accept  json
+// @Produce  json
+// @Param   IssueDomainLinkageRequestBody  body    IssueDomainLinkageRequestBody  true  "Issue domain linkage request"
+// @Param   Authorization  header  string  false  "Bearer <access_token> or DPoP <access_token> of the owner of key_id"
+// @Success 200 {object} IssueDomainLinkageResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"origin must be an https url"})
+// @Failure 403 {object} ErrorResponse "forbidden" example({"code":"FORBIDDEN","message":"did is not allowed to link to this endpoint"})
+// @Security ApiKeyAuth
+// @Router /testapi/domain-linkage/issue [post]
+func IssueDomainLinkage(c *gin.Context) {
+	var requestBody IssueDomainLinkageRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "IssueDomainLinkage.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "IssueDomainLinkage.Request", map[string]string{
+		"did":     requestBody.Did,
+		"origin":  requestBody.Origin,
+		"expires": strconv.Itoa(requestBody.ExpiresInMinutes),
+	})
+	if requestBody.Did == "" || requestBody.Origin == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "did and origin are required"})
+		return
+	}
+	if !linkableDid(requestBody.Did) {
+		logReq(c, "IssueDomainLinkage.Forbidden", map[string]string{"did": requestBody.Did})
+		c.JSON(http.StatusForbidden, ErrorResponse{Code: "FORBIDDEN", Message: "did is not allowed to link to this endpoint"})
+		return
+	}
+	origin, err := domainlinkage.NormalizeOrigin(requestBody.Origin)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	endpointOrigin, err := ownOrigin(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "origin of this endpoint is unknown, set " + linkedOriginEnv + ": " + err.Error()})
+		return
+	}
+	if origin != endpointOrigin {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "origin must be the origin of this endpoint: " + endpointOrigin})
+		return
+	}
+	if requestBody.ExpiresInMinutes <= 0 {
+		requestBody.ExpiresInMinutes = 60 * 24 * 365
+	}
+	pvKey, ok := requestSigner(c, requestBody.KeyID, requestBody.PvKeyBase58)
+	if !ok {
+		return
+	}
+	vcJwt, err := domainlinkage.Issue(requestBody.Did, origin, time.Duration(requestBody.ExpiresInMinutes)*time.Minute, pvKey)
+	if err != nil {
+		logReq(c, "IssueDomainLinkage.Error", map[string]string{"error": err.Error()})
+		serviceError(c, err)
+		return
+	}
+
+	linkedDids.Lock()
+	replaced := false
+	for i, did := range linkedDids.dids {
+		if did == requestBody.Did {
+			linkedDids.jwts[i], replaced = vcJwt, true
+		}
+	}
+	if !replaced {
+		linkedDids.dids = append(linkedDids.dids, requestBody.Did)
+		linkedDids.jwts = append(linkedDids.jwts, vcJwt)
+	}
+	linkedDids.Unlock()
+	logReq(c, "IssueDomainLinkage.Success", map[string]string{"did": requestBody.Did})
+	c.JSON(http.StatusOK, IssueDomainLinkageResponse{DomainLinkageCredential: vcJwt})
+}
+
+// VerifyDomainLinkage
+// @Summary Verify linked domains
+// @Description Resolve the DID and verify the origins of its LinkedDomains services against their well-known DID configurations.
+// @Description When origin is given, only that origin is verified.
+// @ID verifyDomainLinkage
+// @Accept  json
+// @Produce  json
+// @Param   VerifyDomainLinkageRequestBody  body    VerifyDomainLinkageRequestBody  true  "Verify domain linkage request"
+// @Success 200 {object} VerifyDomainLinkageResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"did is required"})
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"did document has no LinkedDomains service"})
+// @Security ApiKeyAuth
+// @Router /testapi/domain-linkage/verify [post]
+func VerifyDomainLinkage(c *gin.Context) {
+	var requestBody VerifyDomainLinkageRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "VerifyDomainLinkage.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "VerifyDomainLinkage.Request", map[string]string{"did": requestBody.Did, "origin": requestBody.Origin})
+	if requestBody.Did == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "did is required"})
+		return
+	}
+
+	verifier := domainlinkage.Verifier{GetPbKey: controller.GetAssertionMethodKey}
+	if requestBody.Origin != "" {
+		origin, err := domainlinkage.NormalizeOrigin(requestBody.Origin)
+		if err != nil {
+			serviceError(c, err)
+			return
+		}
+		result := domainlinkage.OriginResult{Origin: origin, Verified: true}
+		if err := verifier.VerifyOrigin(c.Request.Context(), requestBody.Did, origin); err != nil {
+			result.Verified = false
+			result.Error = err.Error()
+		}
+		c.JSON(http.StatusOK, VerifyDomainLinkageResponse{Did: requestBody.Did, Results: []domainlinkage.OriginResult{result}})
+		return
+	}
+
+	document, err := controller.ResolveDIDWithErr(requestBody.Did)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	var doc dids.DocumentInterface
+	if err := json.Unmarshal([]byte(document), &doc); err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Code: "INTERNAL_ERROR", Message: "failed to parse did document"})
+		return
+	}
+	results, err := verifier.VerifyLinkedDomains(c.Request.Context(), doc)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	logReq(c, "VerifyDomainLinkage.Success", map[string]string{"did": requestBody.Did, "origins": strconv.Itoa(len(results))})
+	c.JSON(http.StatusOK, VerifyDomainLinkageResponse{Did: requestBody.Did, Results: results})
+}
@@ -0,0 +1,220 @@
+package api
+
+import (
+	"byd50-ssi/pkg/did/core/jwe"
+	"byd50-ssi/pkg/did/kms"
+	"byd50-ssi/pkg/did/pkg/controller"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strings"
+)
+
+type AddKeyAgreementKeyRequestBody struct {
+	Did             string `json:"did" example:"did:byd50:1234567890abcdef"`
+	KeyID           string `json:"key_id,omitempty" example:"0b7c3f4e-3d0c-4c36-a5a0-6c3b8a2f7e51"`
+	PvKeyBase58     string `json:"pv_key_base58,omitempty" example:"3VZ6oJdR8i1qKX7kH3Yv9d7w7wzgn..."`
+	MethodID        string `json:"method_id" example:"#key-agreement-1"`
+	PublicKeyBase58 string `json:"public_key_base58" example:"MCowBQYDK2VuAyEA..."`
+}
+
+type RemoveVerificationMethodRequestBody struct {
+	Did         string `json:"did" example:"did:byd50:1234567890abcdef"`
+	KeyID       string `json:"key_id,omitempty" example:"0b7c3f4e-3d0c-4c36-a5a0-6c3b8a2f7e51"`
+	PvKeyBase58 string `json:"pv_key_base58,omitempty" example:"3VZ6oJdR8i1qKX7kH3Yv9d7w7wzgn..."`
+	MethodID    string `json:"method_id" example:"#key-agreement-1"`
+}
+
+type EncryptJWERequestBody struct {
+	Dids        []string `json:"dids" example:"did:byd50:holder123"`
+	Plaintext   string   `json:"plaintext" example:"eyJhbGciOiJFUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ContentType string   `json:"content_type,omitempty" example:"JWT"`
+}
+
+type DecryptJWERequestBody struct {
+	KeyID string          `json:"key_id" example:"0b7c3f4e-3d0c-4c36-a5a0-6c3b8a2f7e51"`
+	JWE   json.RawMessage `json:"jwe" swaggertype:"object"`
+}
+
+type DecryptJWEResponse struct {
+	Plaintext   string `json:"plaintext" example:"eyJhbGciOiJFUzI1NiIsInR5cCI6IkpXVCJ9..."`
+	ContentType string `json:"content_type,omitempty" example:"JWT"`
+}
+
+// AddKeyAgreementKey
+// @Summary Add DID key agreement key
+// @Description Publish public_key_base58 (an x25519 or EC key, e.g. a key created with key_type x25519) as the
+// @Description keyAgreement verification method method_id of a DID document, so that JWEs can be encrypted to the DID.
+// @Description The update is signed with the key key_id, which must belong to a capabilityInvocation key of the DID.
+// @ID addDidKeyAgreementKey
+// @Accept  json
+// @Produce  json
+// @Param   AddKeyAgreementKeyRequestBody  body    AddKeyAgreementKeyRequestBody  true  "Add key agreement key request"
+// @Param   Authorization  header  string  false  "Bearer <access_token> or DPoP <access_token> of the owner of key_id"
+// @Success 200 {object} map[string]string "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"key agreement keys must be X25519 or EC keys"})
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"did document not found"})
+// @Failure 500 {object} ErrorResponse "internal error"
+// @Security ApiKeyAuth
+// @Router /testapi/did/key-agreement/add [post]
+func AddKeyAgreementKey(c *gin.Context) {
+	var requestBody AddKeyAgreementKeyRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "AddKeyAgreementKey.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "AddKeyAgreementKey.Request", map[string]string{
+		"did":      requestBody.Did,
+		"methodId": requestBody.MethodID,
+		"keyId":    requestBody.KeyID,
+	})
+	if requestBody.Did == "" || requestBody.MethodID == "" || requestBody.PublicKeyBase58 == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "did, method_id and public_key_base58 are required"})
+		return
+	}
+	pvKey, ok := requestSigner(c, requestBody.KeyID, requestBody.PvKeyBase58)
+	if !ok {
+		return
+	}
+	if err := controller.AddKeyAgreementKey(requestBody.Did, requestBody.MethodID, requestBody.PublicKeyBase58, pvKey); err != nil {
+		logReq(c, "AddKeyAgreementKey.Error", map[string]string{"error": err.Error()})
+		serviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// RemoveVerificationMethod
+// @Summary Remove DID verification method
+// @Description Remove the verification method method_id, e.g. a rotated key agreement key, from a DID document and
+// @Description all of its relationships. The update is signed with the key key_id, which must belong to a
+// @Description capabilityInvocation key of the DID.
+// @ID removeDidVerificationMethod
+// @Accept  json
+// @Produce  json
+// @Param   RemoveVerificationMethodRequestBody  body    RemoveVerificationMethodRequestBody  true  "Remove verification method request"
+// @Param   Authorization  header  string  false  "Bearer <access_token> or DPoP <access_token> of the owner of key_id"
+// @Success 200 {object} map[string]string "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"did and method_id are required"})
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"verification method not found"})
+// @Failure 500 {object} ErrorResponse "internal error"
+// @Security ApiKeyAuth
+// @Router /testapi/did/verification-method/remove [post]
+func RemoveVerificationMethod(c *gin.Context) {
+	var requestBody RemoveVerificationMethodRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "RemoveVerificationMethod.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "RemoveVerificationMethod.Request", map[string]string{"did": requestBody.Did, "methodId": requestBody.MethodID})
+	if requestBody.Did == "" || requestBody.MethodID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "did and method_id are required"})
+		return
+	}
+	pvKey, ok := requestSigner(c, requestBody.KeyID, requestBody.PvKeyBase58)
+	if !ok {
+		return
+	}
+	if err := controller.RemoveVerificationMethod(requestBody.Did, requestBody.MethodID, pvKey); err != nil {
+		logReq(c, "RemoveVerificationMethod.Error", map[string]string{"error": err.Error()})
+		serviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// EncryptJWE
+// @Summary Encrypt to DIDs
+// @Description Encrypt plaintext (e.g. a VC JWT) to every keyAgreement key of the given DIDs with ECDH-ES+A256KW and
+// @Description A256GCM. Returns the JWE in the general JSON serialization; each holder decrypts it with its own key.
+// @ID encryptJwe
+// @Accept  json
+// @Produce  json
+// @Param   EncryptJWERequestBody  body    EncryptJWERequestBody  true  "Encrypt request"
+// @Success 200 {object} jwe.JWE "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"dids and plaintext are required"})
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"no keyAgreement key in document"})
+// @Failure 500 {object} ErrorResponse "internal error"
+// @Security ApiKeyAuth
+// @Router /testapi/jwe/encrypt [post]
+func EncryptJWE(c *gin.Context) {
+	var requestBody EncryptJWERequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "EncryptJWE.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "EncryptJWE.Request", map[string]string{"dids": strings.Join(requestBody.Dids, ","), "contentType": requestBody.ContentType})
+	if len(requestBody.Dids) == 0 || requestBody.Plaintext == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "dids and plaintext are required"})
+		return
+	}
+	encrypted, err := controller.EncryptForDIDs([]byte(requestBody.Plaintext), requestBody.ContentType, requestBody.Dids...)
+	if err != nil {
+		logReq(c, "EncryptJWE.Error", map[string]string{"error": err.Error()})
+		serviceError(c, err)
+		return
+	}
+	c.Header("Content-Type", jwe.MediaType)
+	c.JSON(http.StatusOK, encrypted)
+}
+
+// DecryptJWE
+// @Summary Decrypt a JWE by key ID
+// @Description Decrypt a JWE (JSON serialization, or compact as a string) with the key agreement key key_id in custody,
+// @Description which must belong to the DID of the access token.
+// @ID decryptJwe
+// @Accept  json
+// @Produce  json
+// @Param   DecryptJWERequestBody  body    DecryptJWERequestBody  true  "Decrypt request"
+// @Param   Authorization  header  string  true  "Bearer <access_token> or DPoP <access_token>"
+// @Param   DPoP  header  string  false  "DPoP proof"
+// @Success 200 {object} DecryptJWEResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"no recipient of the jwe matches the key"})
+// @Failure 401 {object} ErrorResponse "unauthorized" example({"code":"UNAUTHORIZED","message":"access token required"})
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"key not found"})
+// @Router /testapi/jwe/decrypt [post]
+func DecryptJWE(c *gin.Context) {
+	claims, _ := AccessClaimsFrom(c)
+	var requestBody DecryptJWERequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "DecryptJWE.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "DecryptJWE.Request", map[string]string{"did": claims.Did, "keyId": requestBody.KeyID})
+	if requestBody.KeyID == "" || len(requestBody.JWE) == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "key_id and jwe are required"})
+		return
+	}
+	if keyOwner(requestBody.KeyID) != claims.Did {
+		c.JSON(http.StatusNotFound, ErrorResponse{Code: "NOT_FOUND", Message: "key not found"})
+		return
+	}
+	data := []byte(requestBody.JWE)
+	var compact string
+	if err := json.Unmarshal(data, &compact); err == nil {
+		data = []byte(compact)
+	}
+	encrypted, err := jwe.Parse(data)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	agreer, err := kms.KeyAgreer(serviceKeys.manager, requestBody.KeyID)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	plaintext, err := encrypted.Decrypt(agreer)
+	if err != nil {
+		logReq(c, "DecryptJWE.Error", map[string]string{"error": err.Error()})
+		serviceError(c, err)
+		return
+	}
+	header, _ := encrypted.Header()
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, DecryptJWEResponse{Plaintext: string(plaintext), ContentType: header.Cty})
+}
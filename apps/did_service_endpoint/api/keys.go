@@ -0,0 +1,257 @@
+package api
+
+import (
+	"byd50-ssi/pkg/did/configs"
+	"byd50-ssi/pkg/did/core/didauth"
+	"byd50-ssi/pkg/did/kms"
+	"byd50-ssi/pkg/did/pkg/controller"
+	"crypto"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"sync"
+)
+
+// serviceKeys holds the keys in custody of this endpoint: the keys of its demo actors and the keys clients create.
+// Clients refer to their keys by key ID; a key can only be used by the DID that owns it.
+var serviceKeys = struct {
+	manager kms.KeyManager
+	mu      sync.RWMutex
+	owners  map[string]string
+}{manager: kms.NewMemoryKeyManager(), owners: map[string]string{}}
+
+func setKeyOwner(keyID, did string) {
+	serviceKeys.mu.Lock()
+	defer serviceKeys.mu.Unlock()
+	serviceKeys.owners[keyID] = did
+}
+
+func keyOwner(keyID string) string {
+	serviceKeys.mu.RLock()
+	defer serviceKeys.mu.RUnlock()
+	return serviceKeys.owners[keyID]
+}
+
+type KeyEnrollRequestBody struct {
+	KeyType string `json:"key_type" example:"ecdsa"`
+	Method  string `json:"method" example:"byd50"`
+}
+
+type KeyEnrollResponse struct {
+	KeyHandle
+	*didauth.TokenResponse
+}
+
+type CreateKeyRequestBody struct {
+	KeyType string `json:"key_type" example:"ed25519"`
+}
+
+// KeyHandle is a key in custody of this endpoint.
+type KeyHandle struct {
+	KeyID           string `json:"key_id" example:"0b7c3f4e-3d0c-4c36-a5a0-6c3b8a2f7e51"`
+	Type            string `json:"type" example:"ecdsa"`
+	PublicKeyBase58 string `json:"public_key_base58" example:"3VZ6oJdR8i1qKX7kH3Yv9d7w7wzgn..."`
+	Did             string `json:"did" example:"did:byd50:holder123"`
+}
+
+// EnrollKey
+// @Summary Enroll a key in custody
+// @Description Create a key of key_type (ecdsa, secp256k1, ed25519 or rsa; default ecdsa) in the KMS of this endpoint,
+// @Description register a DID of method (default byd50) for it and return the key ID with DID Auth access and refresh
+// @Description tokens of the DID. The private key never leaves the KMS: signing endpoints take the key_id with the access
+// @Description token. With a DPoP header (a proof for this request) the tokens are bound to the key of the proof.
+// @ID enrollKey
+// @Accept  json
+// @Produce  json
+// @Param   KeyEnrollRequestBody  body    KeyEnrollRequestBody  true  "Enroll request"
+// @Param   DPoP  header  string  false  "DPoP proof"
+// @Success 200 {object} KeyEnrollResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"unknown keyType: dsa"})
+// @Security ApiKeyAuth
+// @Router /testapi/keys/enroll [post]
+func EnrollKey(c *gin.Context) {
+	var requestBody KeyEnrollRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "EnrollKey.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	if requestBody.KeyType == "" {
+		requestBody.KeyType = kms.KeyTypeECDSA
+	}
+	if requestBody.Method == "" {
+		requestBody.Method = "byd50"
+	}
+	if requestBody.KeyType == kms.KeyTypeX25519 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "x25519 keys cannot sign, enroll a signing key"})
+		return
+	}
+	logReq(c, "EnrollKey.Request", map[string]string{"keyType": requestBody.KeyType, "method": requestBody.Method})
+	tokens := ensureDidAuthTokens()
+	jkt, ok := dpopThumbprint(c, tokens)
+	if !ok {
+		return
+	}
+	keyID, err := serviceKeys.manager.Create(requestBody.KeyType)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	pbKeyBase58, err := kms.PublicKeyBase58(serviceKeys.manager, keyID)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	did, err := controller.CreateDIDWithErr(pbKeyBase58, requestBody.Method)
+	if err != nil {
+		serviceKeys.manager.Delete(keyID)
+		serviceError(c, err)
+		return
+	}
+	setKeyOwner(keyID, did)
+	// the DID was just created for a key only this endpoint holds, so it is authenticated without a challenge
+	response, err := tokens.Issue(did, jkt)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	logReq(c, "EnrollKey.Success", map[string]string{"did": did, "keyId": keyID})
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, KeyEnrollResponse{
+		KeyHandle:     KeyHandle{KeyID: keyID, Type: requestBody.KeyType, PublicKeyBase58: pbKeyBase58, Did: did},
+		TokenResponse: response,
+	})
+}
+
+// CreateKey
+// @Summary Create a key in custody
+// @Description Create another key of key_type (default ecdsa) for the DID of the access token. Add its public key to
+// @Description the DID document to sign with it on behalf of the DID. x25519 keys only decrypt JWEs: publish them with
+// @Description /testapi/did/key-agreement/add.
+// @ID createKey
+// @Accept  json
+// @Produce  json
+// @Param   CreateKeyRequestBody  body    CreateKeyRequestBody  true  "Create key request"
+// @Param   Authorization  header  string  true  "Bearer <access_token> or DPoP <access_token>"
+// @Param   DPoP  header  string  false  "DPoP proof"
+// @Success 200 {object} KeyHandle "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"unknown keyType: dsa"})
+// @Failure 401 {object} ErrorResponse "unauthorized" example({"code":"UNAUTHORIZED","message":"access token required"})
+// @Router /testapi/keys [post]
+func CreateKey(c *gin.Context) {
+	claims, _ := AccessClaimsFrom(c)
+	var requestBody CreateKeyRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "CreateKey.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	if requestBody.KeyType == "" {
+		requestBody.KeyType = kms.KeyTypeECDSA
+	}
+	logReq(c, "CreateKey.Request", map[string]string{"did": claims.Did, "keyType": requestBody.KeyType})
+	keyID, err := serviceKeys.manager.Create(requestBody.KeyType)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	pbKeyBase58, err := kms.PublicKeyBase58(serviceKeys.manager, keyID)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	setKeyOwner(keyID, claims.Did)
+	c.JSON(http.StatusOK, KeyHandle{KeyID: keyID, Type: requestBody.KeyType, PublicKeyBase58: pbKeyBase58, Did: claims.Did})
+}
+
+// ListKeys
+// @Summary List keys in custody
+// @Description List the keys of the DID of the access token.
+// @ID listKeys
+// @Produce  json
+// @Param   Authorization  header  string  true  "Bearer <access_token> or DPoP <access_token>"
+// @Param   DPoP  header  string  false  "DPoP proof"
+// @Success 200 {array} KeyHandle "ok"
+// @Failure 401 {object} ErrorResponse "unauthorized" example({"code":"UNAUTHORIZED","message":"access token required"})
+// @Router /testapi/keys [get]
+func ListKeys(c *gin.Context) {
+	claims, _ := AccessClaimsFrom(c)
+	handles, err := serviceKeys.manager.List()
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	owned := []KeyHandle{}
+	for _, handle := range handles {
+		if keyOwner(handle.ID) == claims.Did {
+			owned = append(owned, KeyHandle{KeyID: handle.ID, Type: handle.Type, PublicKeyBase58: handle.PublicKeyBase58, Did: claims.Did})
+		}
+	}
+	c.JSON(http.StatusOK, owned)
+}
+
+// DeleteKey
+// @Summary Delete a key in custody
+// @Description Delete a key of the DID of the access token. The key is not removed from the DID document.
+// @ID deleteKey
+// @Produce  json
+// @Param   key_id  path  string  true  "Key ID"
+// @Param   Authorization  header  string  true  "Bearer <access_token> or DPoP <access_token>"
+// @Param   DPoP  header  string  false  "DPoP proof"
+// @Success 200 {object} map[string]string "ok"
+// @Failure 401 {object} ErrorResponse "unauthorized" example({"code":"UNAUTHORIZED","message":"access token required"})
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"key not found"})
+// @Router /testapi/keys/{key_id} [delete]
+func DeleteKey(c *gin.Context) {
+	claims, _ := AccessClaimsFrom(c)
+	keyID := c.Param("key_id")
+	logReq(c, "DeleteKey.Request", map[string]string{"did": claims.Did, "keyId": keyID})
+	if keyOwner(keyID) != claims.Did {
+		c.JSON(http.StatusNotFound, ErrorResponse{Code: "NOT_FOUND", Message: "key not found"})
+		return
+	}
+	if err := serviceKeys.manager.Delete(keyID); err != nil {
+		serviceError(c, err)
+		return
+	}
+	serviceKeys.mu.Lock()
+	delete(serviceKeys.owners, keyID)
+	serviceKeys.mu.Unlock()
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// requestSigner returns the signing key of a request: the key keyID in custody, which must belong to the DID of
+// the access token of the request, or, when insecure_demo_keys is enabled, the raw private key pvKeyBase58.
+// It writes the error response and returns false when there is no usable key.
+func requestSigner(c *gin.Context, keyID, pvKeyBase58 string) (crypto.Signer, bool) {
+	if keyID != "" {
+		claims, ok := authenticate(c)
+		if !ok {
+			return nil, false
+		}
+		if keyOwner(keyID) != claims.Did {
+			c.JSON(http.StatusNotFound, ErrorResponse{Code: "NOT_FOUND", Message: "key not found"})
+			return nil, false
+		}
+		signer, err := kms.KeySigner(serviceKeys.manager, keyID)
+		if err != nil {
+			serviceError(c, err)
+			return nil, false
+		}
+		return signer, true
+	}
+	if pvKeyBase58 == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "key_id is required"})
+		return nil, false
+	}
+	if !configs.UseConfig.InsecureDemoKeys {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "pv_key_base58 is disabled, sign with a key_id (insecure_demo_keys)"})
+		return nil, false
+	}
+	pvKey, err := parsePrivateKeyBase58(pvKeyBase58)
+	signer, ok := pvKey.(crypto.Signer)
+	if err != nil || !ok {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid pv_key_base58"})
+		return nil, false
+	}
+	return signer, true
+}
@@ -0,0 +1,96 @@
+package api
+
+import (
+	derrors "byd50-ssi/pkg/did/errors"
+	"byd50-ssi/pkg/did/ledger"
+	"context"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+)
+
+type IssuedCredentialsResponse struct {
+	Credentials []ledger.Record `json:"credentials"`
+}
+
+// issuanceLedger keeps the metadata of every VC issued by the demo actors.
+var issuanceLedger ledger.Store = ledger.NewMemoryStore()
+
+func recordIssued(vcJwt string) {
+	if vcJwt == "" {
+		return
+	}
+	record, err := ledger.Issue(context.Background(), issuanceLedger, vcJwt)
+	if err != nil {
+		log.Printf("[did_service_endpoint][ledger] failed to record issued vc: %v", err)
+		return
+	}
+	log.Printf("[did_service_endpoint][ledger] recorded jti=%s status_index=%d", record.Jti, record.StatusIndex)
+}
+
+// ListIssuedCredentials
+// @Summary List issued credentials
+// @Description Query the issuance ledger of the demo issuers, optionally filtered by subject DID and credential type.
+// @ID listIssuedCredentials
+// @Accept  json
+// @Produce  json
+// @Param   subject  query  string  false  "Subject DID"
+// @Param   type     query  string  false  "Credential type"
+// @Success 200 {object} IssuedCredentialsResponse "ok"
+// @Security ApiKeyAuth
+// @Router /testapi/ledger/credentials [get]
+func ListIssuedCredentials(c *gin.Context) {
+	subject := c.Query("subject")
+	typ := c.Query("type")
+	logReq(c, "ListIssuedCredentials", map[string]string{"subject": subject, "type": typ})
+
+	var records []ledger.Record
+	var err error
+	if subject != "" {
+		records, err = issuanceLedger.ListBySubject(c.Request.Context(), subject)
+	} else {
+		records, err = issuanceLedger.List(c.Request.Context())
+	}
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Code: "INTERNAL_ERROR", Message: err.Error()})
+		return
+	}
+
+	matched := []ledger.Record{}
+	for _, record := range records {
+		if typ != "" && record.Type != typ {
+			continue
+		}
+		matched = append(matched, record)
+	}
+	c.JSON(http.StatusOK, IssuedCredentialsResponse{Credentials: matched})
+}
+
+// GetIssuedCredential
+// @Summary Get issued credential
+// @Description Look up a single issuance ledger record by jti.
+// @ID getIssuedCredential
+// @Accept  json
+// @Produce  json
+// @Param   jti  path  string  true  "Credential ID (jti)"
+// @Success 200 {object} ledger.Record "ok"
+// @Failure 404 {object} ErrorResponse "not found"
+// @Security ApiKeyAuth
+// @Router /testapi/ledger/credentials/{jti} [get]
+func GetIssuedCredential(c *gin.Context) {
+	jti := c.Param("jti")
+	logReq(c, "GetIssuedCredential", map[string]string{"jti": jti})
+
+	record, err := issuanceLedger.Get(c.Request.Context(), jti)
+	if err != nil {
+		var dErr *derrors.Error
+		if errors.As(err, &dErr) && dErr.Code() == derrors.CodeNotFound {
+			c.JSON(http.StatusNotFound, ErrorResponse{Code: "NOT_FOUND", Message: err.Error()})
+			return
+		}
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Code: "INTERNAL_ERROR", Message: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, record)
+}
@@ -0,0 +1,208 @@
+package api
+
+import (
+	"byd50-ssi/pkg/did/core/oid4vci"
+	"byd50-ssi/pkg/did/pkg/controller"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// oid4vciPath is the path of the credential issuer URL below the host of this endpoint.
+const oid4vciPath = "/v2/testapi/oid4vci"
+
+// oid4vciIssuer issues the driver licence of the demo licence issuer through OID4VCI.
+var oid4vciIssuer struct {
+	once   sync.Once
+	issuer *oid4vci.Issuer
+}
+
+func ensureOid4vciIssuer() *oid4vci.Issuer {
+	oid4vciIssuer.once.Do(func() {
+		ensureDemoActors()
+		algs := []string{"ES256", "ES256K", "ES384", "EdDSA", "PS256"}
+		oid4vciIssuer.issuer = &oid4vci.Issuer{
+			Did:   demoActors.license.Did,
+			PvKey: demoActors.license.PvKey,
+			Configurations: map[string]oid4vci.CredentialConfiguration{
+				"DriverLicenseCredential_jwt_vc_json": {
+					Format:                               oid4vci.FormatJwtVcJson,
+					CryptographicBindingMethodsSupported: []string{oid4vci.BindingMethodDid},
+					CredentialSigningAlgValuesSupported:  []string{"ES256"},
+					ProofTypesSupported: map[string]oid4vci.ProofTypeMetadata{
+						oid4vci.ProofTypeJwt: {ProofSigningAlgValuesSupported: algs},
+					},
+					CredentialDefinition: oid4vci.CredentialDefinition{Type: []string{"VerifiableCredential", "DriverLicenseCredential"}},
+					Display:              []map[string]interface{}{{"name": "Driver License", "locale": "en-US"}},
+				},
+			},
+			CredentialPath: "/credential",
+			TokenPath:      "/token",
+			GetPbKey:       controller.GetPublicKey,
+			OnIssued:       recordIssued,
+		}
+		log.Printf("[did_service_endpoint][oid4vci] issuer=%s", demoActors.license.Did)
+	})
+	return oid4vciIssuer.issuer
+}
+
+type Oid4vciOfferRequestBody struct {
+	CredentialConfigurationID string                 `json:"credential_configuration_id" example:"DriverLicenseCredential_jwt_vc_json"`
+	CredentialSubject         map[string]interface{} `json:"credential_subject"`
+	// TxCode protects the pre-authorized code with a transaction code returned here, to be sent to the holder out of band.
+	TxCode bool `json:"tx_code" example:"true"`
+}
+
+type Oid4vciOfferResponse struct {
+	CredentialOffer    *oid4vci.CredentialOffer `json:"credential_offer"`
+	CredentialOfferURI string                   `json:"credential_offer_uri" example:"openid-credential-offer://?credential_offer=..."`
+	TxCode             string                   `json:"tx_code,omitempty" example:"493536"`
+}
+
+// GetOid4vciIssuerMetadata
+// @Summary OID4VCI credential issuer metadata
+// @Description Serve the metadata of the credential issuer of this endpoint (credential_issuer <host>/v2/testapi/oid4vci).
+// @ID getOid4vciIssuerMetadata
+// @Produce  json
+// @Success 200 {object} oid4vci.IssuerMetadata "ok"
+// @Router /.well-known/openid-credential-issuer/v2/testapi/oid4vci [get]
+func GetOid4vciIssuerMetadata(c *gin.Context) {
+	c.JSON(http.StatusOK, ensureOid4vciIssuer().Metadata(baseURL(c)+oid4vciPath))
+}
+
+// GetOid4vciAuthorizationServerMetadata
+// @Summary OID4VCI authorization server metadata
+// @Description Serve the OAuth 2.0 authorization server metadata of the credential issuer, which issues its own access tokens.
+// @ID getOid4vciAuthorizationServerMetadata
+// @Produce  json
+// @Success 200 {object} oid4vci.AuthorizationServerMetadata "ok"
+// @Router /.well-known/oauth-authorization-server/v2/testapi/oid4vci [get]
+func GetOid4vciAuthorizationServerMetadata(c *gin.Context) {
+	c.JSON(http.StatusOK, ensureOid4vciIssuer().AuthorizationServerMetadata(baseURL(c)+oid4vciPath))
+}
+
+// CreateOid4vciOffer
+// @Summary Create OID4VCI credential offer
+// @Description Issuer side: offer a credential with the given credential_subject through the pre-authorized code flow,
+// @Description optionally protected by a transaction code. The credential_subject is checked against the credential schema.
+// @ID createOid4vciOffer
+// @Accept  json
+// @Produce  json
+// @Param   Oid4vciOfferRequestBody  body    Oid4vciOfferRequestBody  true  "Create offer request"
+// @Success 200 {object} Oid4vciOfferResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"invalid json body"})
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"credential configuration not found"})
+// @Security ApiKeyAuth
+// @Router /testapi/oid4vci/offers [post]
+func CreateOid4vciOffer(c *gin.Context) {
+	var requestBody Oid4vciOfferRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "CreateOid4vciOffer.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "CreateOid4vciOffer.Request", map[string]string{"configurationId": requestBody.CredentialConfigurationID})
+	issuer := ensureOid4vciIssuer()
+	if issuer.Did == "" {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Code: "INTERNAL_ERROR", Message: "issuer did is not available"})
+		return
+	}
+	offer, txCode, err := issuer.CreateOffer(baseURL(c)+oid4vciPath, requestBody.CredentialConfigurationID, requestBody.CredentialSubject, requestBody.TxCode)
+	if resp, ok := schemaError(err); ok {
+		c.JSON(http.StatusBadRequest, resp)
+		return
+	}
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	uri, err := offer.URI()
+	if err != nil {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Code: "INTERNAL_ERROR", Message: err.Error()})
+		return
+	}
+	logReq(c, "CreateOid4vciOffer.Success", map[string]string{"configurationId": requestBody.CredentialConfigurationID})
+	c.JSON(http.StatusOK, Oid4vciOfferResponse{CredentialOffer: offer, CredentialOfferURI: uri, TxCode: txCode})
+}
+
+// Oid4vciToken
+// @Summary OID4VCI token endpoint
+// @Description Wallet side: redeem a pre-authorized code (and its tx_code, when the offer requires one) for an access token and c_nonce.
+// @ID oid4vciToken
+// @Accept  x-www-form-urlencoded
+// @Produce  json
+// @Param   grant_type  formData  string  true  "urn:ietf:params:oauth:grant-type:pre-authorized_code"
+// @Param   pre-authorized_code  formData  string  true  "Pre-authorized code of the offer"
+// @Param   tx_code  formData  string  false  "Transaction code"
+// @Success 200 {object} oid4vci.TokenResponse "ok"
+// @Failure 400 {object} oid4vci.Error "bad request" example({"error":"invalid_grant","error_description":"tx_code mismatch"})
+// @Router /testapi/oid4vci/token [post]
+func Oid4vciToken(c *gin.Context) {
+	var request oid4vci.TokenRequest
+	if err := c.ShouldBind(&request); err != nil {
+		c.JSON(http.StatusBadRequest, oid4vci.Error{Code: oid4vci.ErrInvalidRequest, Description: "invalid token request"})
+		return
+	}
+	logReq(c, "Oid4vciToken.Request", map[string]string{"grantType": request.GrantType})
+	response, err := ensureOid4vciIssuer().Token(request)
+	if err != nil {
+		oid4vciError(c, err)
+		return
+	}
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, response)
+}
+
+// Oid4vciCredential
+// @Summary OID4VCI credential endpoint
+// @Description Wallet side: request the offered credential with the access token (Authorization: Bearer) and a proof JWT
+// @Description (typ openid4vci-proof+jwt) signed by the holder DID, with the credential issuer as aud and the current c_nonce.
+// @Description The credential is issued as jwt_vc_json and bound to the holder DID.
+// @ID oid4vciCredential
+// @Accept  json
+// @Produce  json
+// @Param   CredentialRequest  body    oid4vci.CredentialRequest  true  "Credential request"
+// @Success 200 {object} oid4vci.CredentialResponse "ok"
+// @Failure 400 {object} oid4vci.Error "bad request" example({"error":"invalid_proof","error_description":"proof nonce is not the current c_nonce","c_nonce":"..."})
+// @Failure 401 {object} oid4vci.Error "unauthorized" example({"error":"invalid_token"})
+// @Router /testapi/oid4vci/credential [post]
+func Oid4vciCredential(c *gin.Context) {
+	accessToken := strings.TrimPrefix(c.GetHeader("Authorization"), "Bearer ")
+	var request oid4vci.CredentialRequest
+	if err := c.ShouldBindJSON(&request); err != nil {
+		c.JSON(http.StatusBadRequest, oid4vci.Error{Code: oid4vci.ErrInvalidCredentialRequest, Description: "invalid json body"})
+		return
+	}
+	logReq(c, "Oid4vciCredential.Request", map[string]string{"format": request.Format})
+	response, err := ensureOid4vciIssuer().Credential(accessToken, request)
+	if err != nil {
+		logReq(c, "Oid4vciCredential.Rejected", map[string]string{"error": err.Error()})
+		oid4vciError(c, err)
+		return
+	}
+	logReq(c, "Oid4vciCredential.Success", map[string]string{"vc_len": strconv.Itoa(len(response.Credential))})
+	c.Header("Cache-Control", "no-store")
+	c.JSON(http.StatusOK, response)
+}
+
+// oid4vciError writes an OAuth 2.0 error of the token or credential endpoint.
+func oid4vciError(c *gin.Context, err error) {
+	var oauthErr *oid4vci.Error
+	if !errors.As(err, &oauthErr) {
+		c.JSON(http.StatusInternalServerError, oid4vci.Error{Code: oid4vci.ErrServerError, Description: err.Error()})
+		return
+	}
+	switch oauthErr.Code {
+	case oid4vci.ErrInvalidToken:
+		c.Header("WWW-Authenticate", `Bearer error="invalid_token"`)
+		c.JSON(http.StatusUnauthorized, oauthErr)
+	case oid4vci.ErrServerError:
+		c.JSON(http.StatusInternalServerError, oauthErr)
+	default:
+		c.JSON(http.StatusBadRequest, oauthErr)
+	}
+}
@@ -0,0 +1,194 @@
+package api
+
+import (
+	"byd50-ssi/pkg/did/core/oid4vp"
+	"byd50-ssi/pkg/did/core/pex"
+	"byd50-ssi/pkg/did/pkg/controller"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"log"
+	"net/http"
+	"sync"
+)
+
+// oid4vpVerifier is the OID4VP verifier of this endpoint. Its DID is created on first use.
+var oid4vpVerifier struct {
+	once     sync.Once
+	actor    demoActor
+	verifier *oid4vp.Verifier
+}
+
+func ensureOid4vpVerifier() *oid4vp.Verifier {
+	oid4vpVerifier.once.Do(func() {
+		oid4vpVerifier.actor = createDemoActor("oid4vp-verifier")
+		oid4vpVerifier.verifier = &oid4vp.Verifier{
+			ClientID:        oid4vpVerifier.actor.Did,
+			PvKey:           oid4vpVerifier.actor.PvKey,
+			GetAuthKey:      controller.GetPublicKey,
+			GetAssertionKey: controller.GetAssertionMethodKey,
+		}
+		log.Printf("[did_service_endpoint][oid4vp] verifier=%s", oid4vpVerifier.actor.Did)
+	})
+	return oid4vpVerifier.verifier
+}
+
+type Oid4vpCreateRequestBody struct {
+	DefinitionID           string          `json:"definition_id,omitempty" example:"driver-license"`
+	PresentationDefinition json.RawMessage `json:"presentation_definition,omitempty" swaggertype:"object"`
+	// ByReference passes the request as a signed request object fetched from request_uri.
+	ByReference bool `json:"by_reference" example:"true"`
+}
+
+type Oid4vpCreateResponse struct {
+	State                   string                       `json:"state"`
+	Nonce                   string                       `json:"nonce"`
+	ClientID                string                       `json:"client_id"`
+	AuthorizationRequestURI string                       `json:"authorization_request_uri" example:"openid4vp://?client_id=did%3Abyd50%3A...&request_uri=..."`
+	RequestURI              string                       `json:"request_uri,omitempty"`
+	AuthorizationRequest    *oid4vp.AuthorizationRequest `json:"authorization_request"`
+}
+
+// Oid4vpErrorResponse is the OAuth 2.0 error returned to wallets by the response endpoint.
+type Oid4vpErrorResponse struct {
+	Error            string `json:"error" example:"invalid_request"`
+	ErrorDescription string `json:"error_description,omitempty" example:"vp nonce mismatch"`
+}
+
+// CreateOid4vpRequest
+// @Summary Create OID4VP authorization request
+// @Description Verifier side: start a verification session for a presentation_definition (inline or by definition_id)
+// @Description and return the openid4vp:// authorization request for the wallet, by value or by reference
+// @Description (request_uri serving a request object signed by the verifier DID). The response mode is direct_post.
+// @ID createOid4vpRequest
+// @Accept  json
+// @Produce  json
+// @Param   Oid4vpCreateRequestBody  body    Oid4vpCreateRequestBody  true  "Create authorization request"
+// @Success 200 {object} Oid4vpCreateResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"invalid json body"})
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"presentation definition not found"})
+// @Security ApiKeyAuth
+// @Router /testapi/oid4vp/requests [post]
+func CreateOid4vpRequest(c *gin.Context) {
+	var requestBody Oid4vpCreateRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "CreateOid4vpRequest.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "CreateOid4vpRequest.Request", map[string]string{"definitionId": requestBody.DefinitionID})
+	def, ok := resolvePresentationDefinition(c, requestBody.DefinitionID, requestBody.PresentationDefinition)
+	if !ok {
+		return
+	}
+	verifier := ensureOid4vpVerifier()
+	if verifier.ClientID == "" {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Code: "INTERNAL_ERROR", Message: "verifier did is not available"})
+		return
+	}
+	session, err := verifier.CreateSession(def, baseURL(c)+"/v2/testapi/oid4vp/response")
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+
+	response := Oid4vpCreateResponse{
+		State:                session.ID,
+		Nonce:                session.Request.Nonce,
+		ClientID:             session.Request.ClientID,
+		AuthorizationRequest: &session.Request,
+	}
+	if requestBody.ByReference {
+		response.RequestURI = baseURL(c) + "/v2/testapi/oid4vp/request/" + session.ID
+		response.AuthorizationRequestURI = oid4vp.RequestURI(session.Request.ClientID, response.RequestURI)
+	} else if response.AuthorizationRequestURI, err = session.Request.URI(); err != nil {
+		serviceError(c, err)
+		return
+	}
+	logReq(c, "CreateOid4vpRequest.Success", map[string]string{"state": session.ID})
+	c.JSON(http.StatusOK, response)
+}
+
+// GetOid4vpRequestObject
+// @Summary Get OID4VP request object
+// @Description Wallet side: fetch the signed request object (typ oauth-authz-req+jwt) of a session passed by request_uri.
+// @ID getOid4vpRequestObject
+// @Produce  application/oauth-authz-req+jwt
+// @Param   state  path  string  true  "Session state"
+// @Success 200 {string} string "signed request object"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"session is verified"})
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"session not found"})
+// @Router /testapi/oid4vp/request/{state} [get]
+func GetOid4vpRequestObject(c *gin.Context) {
+	state := c.Param("state")
+	logReq(c, "GetOid4vpRequestObject.Request", map[string]string{"state": state})
+	requestObject, err := ensureOid4vpVerifier().RequestObject(state)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	c.Data(http.StatusOK, "application/oauth-authz-req+jwt", []byte(requestObject))
+}
+
+// PostOid4vpResponse
+// @Summary OID4VP direct_post response endpoint
+// @Description Wallet side: post the authorization response (form encoded vp_token, presentation_submission and state).
+// @Description The VP must be signed by the holder with the nonce of the session and the verifier DID as aud, and its
+// @Description presentation_submission must satisfy the requested definition. A session accepts a single response.
+// @ID postOid4vpResponse
+// @Accept  x-www-form-urlencoded
+// @Produce  json
+// @Param   vp_token  formData  string  true  "VP JWT"
+// @Param   presentation_submission  formData  string  true  "presentation_submission JSON"
+// @Param   state  formData  string  true  "Session state"
+// @Success 200 {object} map[string]string "ok"
+// @Failure 400 {object} Oid4vpErrorResponse "bad request" example({"error":"invalid_request","error_description":"vp nonce mismatch"})
+// @Router /testapi/oid4vp/response [post]
+func PostOid4vpResponse(c *gin.Context) {
+	response := oid4vp.Response{VpToken: c.PostForm("vp_token"), State: c.PostForm("state")}
+	logReq(c, "PostOid4vpResponse.Request", map[string]string{"state": response.State})
+	if submission := c.PostForm("presentation_submission"); submission != "" {
+		response.PresentationSubmission = new(pex.PresentationSubmission)
+		if err := json.Unmarshal([]byte(submission), response.PresentationSubmission); err != nil {
+			c.JSON(http.StatusBadRequest, Oid4vpErrorResponse{Error: "invalid_request", ErrorDescription: "invalid presentation_submission"})
+			return
+		}
+	}
+	session, err := ensureOid4vpVerifier().HandleResponse(response)
+	if err != nil {
+		logReq(c, "PostOid4vpResponse.Rejected", map[string]string{"state": response.State, "error": err.Error()})
+		c.JSON(http.StatusBadRequest, Oid4vpErrorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+		return
+	}
+	logReq(c, "PostOid4vpResponse.Success", map[string]string{"state": session.ID, "holder": session.HolderDid})
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// GetOid4vpSession
+// @Summary Get OID4VP session status
+// @Description Verifier side: poll a verification session (created, request_retrieved, verified or expired).
+// @Description Verified sessions carry the holder DID and the credential submitted for each input descriptor.
+// @ID getOid4vpSession
+// @Produce  json
+// @Param   state  path  string  true  "Session state"
+// @Success 200 {object} oid4vp.Session "ok"
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"session not found"})
+// @Security ApiKeyAuth
+// @Router /testapi/oid4vp/sessions/{state} [get]
+func GetOid4vpSession(c *gin.Context) {
+	state := c.Param("state")
+	session, err := ensureOid4vpVerifier().Session(state)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
+
+// baseURL is the scheme and host the request reached this endpoint with.
+func baseURL(c *gin.Context) string {
+	scheme := "http"
+	if c.Request.TLS != nil || c.GetHeader("X-Forwarded-Proto") == "https" {
+		scheme = "https"
+	}
+	return scheme + "://" + c.Request.Host
+}
@@ -0,0 +1,275 @@
+package api
+
+import (
+	"byd50-ssi/pkg/did/core"
+	"byd50-ssi/pkg/did/core/pex"
+	"byd50-ssi/pkg/did/pkg/controller"
+	"embed"
+	"encoding/json"
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt"
+	"net/http"
+	"path"
+	"strconv"
+	"time"
+)
+
+//go:embed definitions/*.json
+var definitionFiles embed.FS
+
+// presentationDefinitions are the presentation definitions published by this endpoint, keyed by id.
+var presentationDefinitions = map[string]*pex.PresentationDefinition{}
+
+func init() {
+	for _, file := range []string{"driver-license.json"} {
+		data, err := definitionFiles.ReadFile(path.Join("definitions", file))
+		if err != nil {
+			panic(err)
+		}
+		def, err := pex.ParseDefinition(data)
+		if err != nil {
+			panic(err)
+		}
+		presentationDefinitions[def.ID] = def
+	}
+}
+
+type PexMatchRequestBody struct {
+	DefinitionID           string          `json:"definition_id,omitempty" example:"driver-license"`
+	PresentationDefinition json.RawMessage `json:"presentation_definition,omitempty" swaggertype:"object"`
+	VcJwts                 []string        `json:"vc_jwts"`
+}
+
+type PexMatchResponse struct {
+	// Matches maps every input descriptor id to the matching credentials.
+	Matches                map[string][]string         `json:"matches"`
+	Selected               []string                    `json:"selected,omitempty"`
+	PresentationSubmission *pex.PresentationSubmission `json:"presentation_submission,omitempty"`
+	Error                  string                      `json:"error,omitempty"`
+}
+
+type PexPresentRequestBody struct {
+	HolderDid              string          `json:"holder_did" example:"did:byd50:1234567890abcdef"`
+	KeyID                  string          `json:"key_id,omitempty" example:"0b7c3f4e-3d0c-4c36-a5a0-6c3b8a2f7e51"`
+	PvKeyBase58            string          `json:"pv_key_base58,omitempty" example:"3VZ6oJdR8i1qKX7kH3Yv9d7w7wzgn..."`
+	DefinitionID           string          `json:"definition_id,omitempty" example:"driver-license"`
+	PresentationDefinition json.RawMessage `json:"presentation_definition,omitempty" swaggertype:"object"`
+	VcJwts                 []string        `json:"vc_jwts"`
+	Aud                    string          `json:"aud,omitempty" example:"did:byd50:verifier"`
+	Nonce                  string          `json:"nonce,omitempty" example:"n-0S6_WzA2Mj"`
+	ExpiresInMinutes       int             `json:"expires_in_minutes,omitempty" example:"5"`
+}
+
+type PexPresentResponse struct {
+	VpJwt                  string                      `json:"vp_jwt"`
+	PresentationSubmission *pex.PresentationSubmission `json:"presentation_submission"`
+}
+
+type PexEvaluateRequestBody struct {
+	DefinitionID           string                      `json:"definition_id,omitempty" example:"driver-license"`
+	PresentationDefinition json.RawMessage             `json:"presentation_definition,omitempty" swaggertype:"object"`
+	VpJwt                  string                      `json:"vp_jwt"`
+	PresentationSubmission *pex.PresentationSubmission `json:"presentation_submission,omitempty"`
+}
+
+type PexEvaluateResponse struct {
+	Valid       bool              `json:"valid"`
+	HolderDid   string            `json:"holder_did,omitempty"`
+	Credentials map[string]string `json:"credentials,omitempty"`
+	Error       string            `json:"error,omitempty"`
+}
+
+// GetPresentationDefinition
+// @Summary Get presentation definition
+// @Description Return a DIF Presentation Exchange v2 presentation_definition published by this verifier.
+// @ID getPresentationDefinition
+// @Produce  json
+// @Param   id  path  string  true  "Definition id"
+// @Success 200 {object} pex.PresentationDefinition "ok"
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"presentation definition not found"})
+// @Router /testapi/pex/definitions/{id} [get]
+func GetPresentationDefinition(c *gin.Context) {
+	id := c.Params.ByName("id")
+	logReq(c, "GetPresentationDefinition.Request", map[string]string{"id": id})
+	def, ok := presentationDefinitions[id]
+	if !ok {
+		c.JSON(http.StatusNotFound, ErrorResponse{Code: "NOT_FOUND", Message: "presentation definition not found"})
+		return
+	}
+	c.JSON(http.StatusOK, def)
+}
+
+// MatchPresentationDefinition
+// @Summary Match credentials to a presentation definition
+// @Description Holder side: find the credentials satisfying each input descriptor of a presentation_definition
+// @Description (inline or by definition_id) and the presentation_submission for the selected ones.
+// @ID matchPresentationDefinition
+// @Accept  json
+// @Produce  json
+// @Param   PexMatchRequestBody  body    PexMatchRequestBody  true  "Match request"
+// @Success 200 {object} PexMatchResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"presentation definition id is empty"})
+// @Security ApiKeyAuth
+// @Router /testapi/pex/match [post]
+func MatchPresentationDefinition(c *gin.Context) {
+	var requestBody PexMatchRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "MatchPresentationDefinition.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "MatchPresentationDefinition.Request", map[string]string{
+		"definitionId": requestBody.DefinitionID,
+		"vcCount":      strconv.Itoa(len(requestBody.VcJwts)),
+	})
+	def, ok := resolvePresentationDefinition(c, requestBody.DefinitionID, requestBody.PresentationDefinition)
+	if !ok {
+		return
+	}
+	matches, err := pex.Match(def, requestBody.VcJwts)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	response := PexMatchResponse{Matches: matches}
+	if selected, submission, err := pex.Select(def, requestBody.VcJwts); err != nil {
+		response.Error = err.Error()
+	} else {
+		response.Selected, response.PresentationSubmission = selected, submission
+	}
+	c.JSON(http.StatusOK, response)
+}
+
+// PresentPresentationDefinition
+// @Summary Create VP for a presentation definition
+// @Description Holder side: select the credentials for a presentation_definition and sign a VP that carries them
+// @Description with its presentation_submission.
+// @ID presentPresentationDefinition
+// @Accept  json
+// @Produce  json
+// @Param   PexPresentRequestBody  body    PexPresentRequestBody  true  "Present request"
+// @Param   Authorization  header  string  false  "Bearer <access_token> or DPoP <access_token> of the owner of key_id"
+// @Success 200 {object} PexPresentResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"holder_did is required"})
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"no credential matches input descriptors: driver_license"})
+// @Security ApiKeyAuth
+// @Router /testapi/pex/present [post]
+func PresentPresentationDefinition(c *gin.Context) {
+	var requestBody PexPresentRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "PresentPresentationDefinition.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "PresentPresentationDefinition.Request", map[string]string{
+		"holderDid":    requestBody.HolderDid,
+		"definitionId": requestBody.DefinitionID,
+		"vcCount":      strconv.Itoa(len(requestBody.VcJwts)),
+	})
+	if requestBody.HolderDid == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "holder_did is required"})
+		return
+	}
+	def, ok := resolvePresentationDefinition(c, requestBody.DefinitionID, requestBody.PresentationDefinition)
+	if !ok {
+		return
+	}
+	pvKey, ok := requestSigner(c, requestBody.KeyID, requestBody.PvKeyBase58)
+	if !ok {
+		return
+	}
+	vp, err := pex.BuildPresentation("PresentationSubmission", def, requestBody.VcJwts)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	if requestBody.ExpiresInMinutes <= 0 {
+		requestBody.ExpiresInMinutes = 5
+	}
+	nonce := requestBody.Nonce
+	if nonce == "" {
+		nonce = core.RandomString(12)
+	}
+	claims, err := vp.ToVpClaims(jwt.StandardClaims{
+		Audience:  requestBody.Aud,
+		ExpiresAt: time.Now().Add(time.Duration(requestBody.ExpiresInMinutes) * time.Minute).Unix(),
+		Id:        core.NewCredentialID(),
+		IssuedAt:  time.Now().Unix(),
+		Issuer:    requestBody.HolderDid,
+	}, nonce)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	vpJwt := core.CreateVpWithClaims(requestBody.HolderDid, claims, pvKey)
+	if vpJwt == "" {
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Code: "INTERNAL_ERROR", Message: "failed to sign vp"})
+		return
+	}
+	submission, _ := vp.Extra[pex.SubmissionClaim].(*pex.PresentationSubmission)
+	c.JSON(http.StatusOK, PexPresentResponse{VpJwt: vpJwt, PresentationSubmission: submission})
+}
+
+// EvaluatePresentationSubmission
+// @Summary Evaluate presentation submission
+// @Description Verifier side: verify the VP and its credentials, then evaluate its presentation_submission (embedded,
+// @Description or given in the body) against the presentation_definition.
+// @ID evaluatePresentationSubmission
+// @Accept  json
+// @Produce  json
+// @Param   PexEvaluateRequestBody  body    PexEvaluateRequestBody  true  "Evaluate request"
+// @Success 200 {object} PexEvaluateResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"vp_jwt is required"})
+// @Security ApiKeyAuth
+// @Router /testapi/pex/evaluate [post]
+func EvaluatePresentationSubmission(c *gin.Context) {
+	var requestBody PexEvaluateRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "EvaluatePresentationSubmission.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "EvaluatePresentationSubmission.Request", map[string]string{"definitionId": requestBody.DefinitionID})
+	if requestBody.VpJwt == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "vp_jwt is required"})
+		return
+	}
+	def, ok := resolvePresentationDefinition(c, requestBody.DefinitionID, requestBody.PresentationDefinition)
+	if !ok {
+		return
+	}
+	valid, holderDid, err := core.VerifyVpWithKeys(requestBody.VpJwt, controller.GetPublicKey, controller.GetAssertionMethodKey)
+	if !valid {
+		message := "vp signature invalid"
+		if err != nil {
+			message = err.Error()
+		}
+		c.JSON(http.StatusOK, PexEvaluateResponse{Valid: false, HolderDid: holderDid, Error: message})
+		return
+	}
+	evaluation, err := pex.Evaluate(def, requestBody.VpJwt, requestBody.PresentationSubmission)
+	if err != nil {
+		logReq(c, "EvaluatePresentationSubmission.Rejected", map[string]string{"error": err.Error()})
+		c.JSON(http.StatusOK, PexEvaluateResponse{Valid: false, HolderDid: holderDid, Error: err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, PexEvaluateResponse{Valid: true, HolderDid: holderDid, Credentials: evaluation.Credentials})
+}
+
+// resolvePresentationDefinition returns the inline definition, or the published one with id.
+func resolvePresentationDefinition(c *gin.Context, id string, inline json.RawMessage) (*pex.PresentationDefinition, bool) {
+	if len(inline) > 0 {
+		def, err := pex.ParseDefinition(inline)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: err.Error()})
+			return nil, false
+		}
+		return def, true
+	}
+	def, ok := presentationDefinitions[id]
+	if !ok {
+		c.JSON(http.StatusNotFound, ErrorResponse{Code: "NOT_FOUND", Message: "presentation definition not found"})
+		return nil, false
+	}
+	return def, true
+}
@@ -0,0 +1,56 @@
+package api
+
+import (
+	"byd50-ssi/pkg/did/core"
+	"byd50-ssi/pkg/did/core/credschema"
+	"embed"
+	"errors"
+	"path"
+)
+
+//go:embed schemas/*.json
+var schemaFiles embed.FS
+
+type SchemaErrorResponse struct {
+	Code       string                 `json:"code" example:"INVALID_PARAM"`
+	Message    string                 `json:"message" example:"credentialSubject does not match schema urn:byd50:schema:DriverLicenseCredential:1"`
+	SchemaID   string                 `json:"schema_id" example:"urn:byd50:schema:DriverLicenseCredential:1"`
+	Violations []credschema.Violation `json:"violations"`
+}
+
+// credentialSchemas maps the credential types issued by the demo actors to their bundled JSON Schema.
+// Schemas that differ from the demo-issuer schema of the same type have their own id in the service namespace.
+var credentialSchemas = []struct {
+	credType string
+	id       string
+	file     string
+}{
+	{"DriverLicenseCredential", "urn:byd50:schema:DriverLicenseCredential:1", "driver-license-credential.json"},
+	{"RentalCarAgreementCredential", "urn:byd50:schema:service:RentalCarAgreementCredential:1", "rental-car-agreement-credential.json"},
+}
+
+func init() {
+	for _, s := range credentialSchemas {
+		schemaJSON, err := schemaFiles.ReadFile(path.Join("schemas", s.file))
+		if err != nil {
+			panic(err)
+		}
+		if err := core.RegisterCredentialSchema(s.credType, s.id, schemaJSON); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// schemaError returns the schema violations carried by err, if any.
+func schemaError(err error) (SchemaErrorResponse, bool) {
+	var verr *credschema.ValidationError
+	if !errors.As(err, &verr) {
+		return SchemaErrorResponse{}, false
+	}
+	return SchemaErrorResponse{
+		Code:       "INVALID_PARAM",
+		Message:    verr.Error(),
+		SchemaID:   verr.SchemaID,
+		Violations: verr.Violations,
+	}, true
+}
@@ -0,0 +1,206 @@
+package api
+
+import (
+	"byd50-ssi/pkg/did/core"
+	"byd50-ssi/pkg/did/pkg/controller"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type CreateSdJwtRequestBody struct {
+	Kid               string                 `json:"kid" example:"did:byd50:1234567890abcdef"`
+	KeyID             string                 `json:"key_id,omitempty" example:"0b7c3f4e-3d0c-4c36-a5a0-6c3b8a2f7e51"`
+	PvKeyBase58       string                 `json:"pv_key_base58,omitempty" example:"3VZ6oJdR8i1qKX7kH3Yv9d7w7wzgn..."`
+	Vct               string                 `json:"vct" example:"DriverLicenseCredential"`
+	CredentialSubject map[string]interface{} `json:"credential_subject"`
+	Disclosable       []string               `json:"disclosable" example:"name,birth_date,license.number"`
+	HolderDid         string                 `json:"holder_did" example:"did:byd50:holder123"`
+	Issuer            string                 `json:"issuer" example:"did:byd50:1234567890abcdef"`
+	ExpiresInMinutes  int                    `json:"expires_in_minutes" example:"5"`
+}
+
+type CreateSdJwtResponse struct {
+	SdJwt string `json:"sd_jwt" example:"eyJhbGciOiJFUzI1NiIsInR5cCI6InZjK3NkLWp3dCJ9...~WyJz...~"`
+}
+
+type PresentSdJwtRequestBody struct {
+	SdJwt       string   `json:"sd_jwt" example:"eyJhbGciOiJFUzI1NiIsInR5cCI6InZjK3NkLWp3dCJ9...~WyJz...~"`
+	Reveal      []string `json:"reveal" example:"license.class"`
+	HolderDid   string   `json:"holder_did" example:"did:byd50:holder123"`
+	KeyID       string   `json:"key_id,omitempty" example:"0b7c3f4e-3d0c-4c36-a5a0-6c3b8a2f7e51"`
+	PvKeyBase58 string   `json:"pv_key_base58,omitempty" example:"3VZ6oJdR8i1qKX7kH3Yv9d7w7wzgn..."`
+	Audience    string   `json:"aud" example:"did:byd50:rental456"`
+	Nonce       string   `json:"nonce" example:"n-123456"`
+}
+
+type PresentSdJwtResponse struct {
+	Presentation string `json:"presentation" example:"eyJhbGciOiJFUzI1NiIsInR5cCI6InZjK3NkLWp3dCJ9...~WyJz...~eyJhbGciOi..."`
+}
+
+type VerifySdJwtRequestBody struct {
+	Presentation  string `json:"presentation" example:"eyJhbGciOiJFUzI1NiIsInR5cCI6InZjK3NkLWp3dCJ9...~WyJz...~eyJhbGciOi..."`
+	ExpectedAud   string `json:"expected_aud" example:"did:byd50:rental456"`
+	ExpectedNonce string `json:"expected_nonce" example:"n-123456"`
+}
+
+type VerifySdJwtResponse struct {
+	Valid  bool                   `json:"valid" example:"true"`
+	Claims map[string]interface{} `json:"claims,omitempty"`
+	Error  string                 `json:"error,omitempty" example:"disclosure digest not found"`
+}
+
+// CreateSdJwt
+// @Summary Create SD-JWT VC
+// @Description Issue an SD-JWT VC. Claims listed in disclosable (dotted paths into credential_subject) are selectively disclosable.
+// @ID createSdJwt
+// @Accept  json
+// @Produce  json
+// @Param   CreateSdJwtRequestBody  body    CreateSdJwtRequestBody  true  "Create SD-JWT VC request"
+// @Param   Authorization  header  string  false  "Bearer <access_token> or DPoP <access_token> of the owner of key_id"
+// @Success 200 {object} CreateSdJwtResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"kid, vct, holder_did, and credential_subject are required"})
+// @Security ApiKeyAuth
+// @Router /testapi/sd-jwt/create [post]
+func CreateSdJwt(c *gin.Context) {
+	var requestBody CreateSdJwtRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "CreateSdJwt.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_PARAM",
+			Message: "invalid json body",
+		})
+		return
+	}
+	logReq(c, "CreateSdJwt.Request", map[string]string{
+		"kid":         requestBody.Kid,
+		"vct":         requestBody.Vct,
+		"holder":      requestBody.HolderDid,
+		"disclosable": strconv.Itoa(len(requestBody.Disclosable)),
+	})
+	if requestBody.Kid == "" || requestBody.Vct == "" ||
+		requestBody.HolderDid == "" || requestBody.CredentialSubject == nil {
+		logReq(c, "CreateSdJwt.BadRequest", map[string]string{"error": "missing required fields"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_PARAM",
+			Message: "kid, vct, holder_did, and credential_subject are required",
+		})
+		return
+	}
+	pvKey, ok := requestSigner(c, requestBody.KeyID, requestBody.PvKeyBase58)
+	if !ok {
+		return
+	}
+	issuer := requestBody.Issuer
+	if issuer == "" {
+		issuer = requestBody.Kid
+	}
+	stdClaims := standardClaims(issuer, requestBody.HolderDid, requestBody.ExpiresInMinutes)
+	sdJwt, err := core.CreateSdJwtVc(requestBody.Kid, requestBody.Vct, requestBody.CredentialSubject,
+		requestBody.Disclosable, requestBody.HolderDid, stdClaims, pvKey)
+	if err != nil {
+		logReq(c, "CreateSdJwt.BadRequest", map[string]string{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_PARAM",
+			Message: err.Error(),
+		})
+		return
+	}
+	logReq(c, "CreateSdJwt.Success", map[string]string{"sd_jwt_len": strconv.Itoa(len(sdJwt))})
+	c.JSON(http.StatusOK, CreateSdJwtResponse{SdJwt: sdJwt})
+}
+
+// PresentSdJwt
+// @Summary Present SD-JWT VC
+// @Description Build a holder presentation revealing only the listed claims, bound to aud/nonce by a key binding JWT.
+// @ID presentSdJwt
+// @Accept  json
+// @Produce  json
+// @Param   PresentSdJwtRequestBody  body    PresentSdJwtRequestBody  true  "Present SD-JWT request"
+// @Param   Authorization  header  string  false  "Bearer <access_token> or DPoP <access_token> of the owner of key_id"
+// @Success 200 {object} PresentSdJwtResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"sd_jwt, holder_did, aud, and nonce are required"})
+// @Security ApiKeyAuth
+// @Router /testapi/sd-jwt/present [post]
+func PresentSdJwt(c *gin.Context) {
+	var requestBody PresentSdJwtRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "PresentSdJwt.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_PARAM",
+			Message: "invalid json body",
+		})
+		return
+	}
+	logReq(c, "PresentSdJwt.Request", map[string]string{
+		"holder": requestBody.HolderDid,
+		"aud":    requestBody.Audience,
+		"reveal": strconv.Itoa(len(requestBody.Reveal)),
+	})
+	if requestBody.SdJwt == "" || requestBody.HolderDid == "" ||
+		requestBody.Audience == "" || requestBody.Nonce == "" {
+		logReq(c, "PresentSdJwt.BadRequest", map[string]string{"error": "missing required fields"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_PARAM",
+			Message: "sd_jwt, holder_did, aud, and nonce are required",
+		})
+		return
+	}
+	pvKey, ok := requestSigner(c, requestBody.KeyID, requestBody.PvKeyBase58)
+	if !ok {
+		return
+	}
+	presentation, err := core.PresentSdJwt(requestBody.SdJwt, requestBody.Reveal, requestBody.Audience,
+		requestBody.Nonce, requestBody.HolderDid, pvKey)
+	if err != nil {
+		logReq(c, "PresentSdJwt.BadRequest", map[string]string{"error": err.Error()})
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_PARAM",
+			Message: err.Error(),
+		})
+		return
+	}
+	logReq(c, "PresentSdJwt.Success", map[string]string{"presentation_len": strconv.Itoa(len(presentation))})
+	c.JSON(http.StatusOK, PresentSdJwtResponse{Presentation: presentation})
+}
+
+// VerifySdJwt
+// @Summary Verify SD-JWT presentation
+// @Description Verify the issuer signature, disclosure digests and key binding JWT, and return the disclosed claims.
+// @ID verifySdJwt
+// @Accept  json
+// @Produce  json
+// @Param   VerifySdJwtRequestBody  body    VerifySdJwtRequestBody  true  "Verify SD-JWT request"
+// @Success 200 {object} VerifySdJwtResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"presentation, expected_aud, and expected_nonce are required"})
+// @Security ApiKeyAuth
+// @Router /testapi/sd-jwt/verify [post]
+func VerifySdJwt(c *gin.Context) {
+	var requestBody VerifySdJwtRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "VerifySdJwt.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_PARAM",
+			Message: "invalid json body",
+		})
+		return
+	}
+	if requestBody.Presentation == "" || requestBody.ExpectedAud == "" || requestBody.ExpectedNonce == "" {
+		logReq(c, "VerifySdJwt.BadRequest", map[string]string{"error": "missing required fields"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{
+			Code:    "INVALID_PARAM",
+			Message: "presentation, expected_aud, and expected_nonce are required",
+		})
+		return
+	}
+	logReq(c, "VerifySdJwt.Request", map[string]string{"presentation_len": strconv.Itoa(len(requestBody.Presentation))})
+	claims, err := core.VerifySdJwtWithKeys(requestBody.Presentation, requestBody.ExpectedAud, requestBody.ExpectedNonce,
+		controller.GetAssertionMethodKey, controller.GetPublicKey)
+	if err != nil {
+		logReq(c, "VerifySdJwt.Result", map[string]string{"valid": "false", "error": err.Error()})
+		c.JSON(http.StatusOK, VerifySdJwtResponse{Valid: false, Error: err.Error()})
+		return
+	}
+	logReq(c, "VerifySdJwt.Result", map[string]string{"valid": "true"})
+	c.JSON(http.StatusOK, VerifySdJwtResponse{Valid: true, Claims: claims})
+}
@@ -0,0 +1,166 @@
+package api
+
+import (
+	"byd50-ssi/pkg/did/core/dids"
+	derrors "byd50-ssi/pkg/did/errors"
+	"byd50-ssi/pkg/did/pkg/controller"
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"strconv"
+)
+
+type AddServiceRequestBody struct {
+	Did         string               `json:"did" example:"did:byd50:1234567890abcdef"`
+	KeyID       string               `json:"key_id,omitempty" example:"0b7c3f4e-3d0c-4c36-a5a0-6c3b8a2f7e51"`
+	PvKeyBase58 string               `json:"pv_key_base58,omitempty" example:"3VZ6oJdR8i1qKX7kH3Yv9d7w7wzgn..."`
+	Service     dids.ServiceProperty `json:"service"`
+}
+
+type RemoveServiceRequestBody struct {
+	Did         string `json:"did" example:"did:byd50:1234567890abcdef"`
+	KeyID       string `json:"key_id,omitempty" example:"0b7c3f4e-3d0c-4c36-a5a0-6c3b8a2f7e51"`
+	PvKeyBase58 string `json:"pv_key_base58,omitempty" example:"3VZ6oJdR8i1qKX7kH3Yv9d7w7wzgn..."`
+	ServiceID   string `json:"service_id" example:"did:byd50:1234567890abcdef#didcomm"`
+}
+
+type ServicesResponse struct {
+	Services []dids.ServiceProperty `json:"services"`
+}
+
+// AddService
+// @Summary Add DID service
+// @Description Publish a service (e.g. DIDCommMessaging, LinkedDomains, CredentialRegistry) in a DID document.
+// @Description serviceEndpoint may be a URI, a map or a set of them. The update is signed with the key key_id,
+// @Description which must belong to a capabilityInvocation key of the DID.
+// @ID addDidService
+// @Accept  json
+// @Produce  json
+// @Param   AddServiceRequestBody  body    AddServiceRequestBody  true  "Add service request"
+// @Param   Authorization  header  string  false  "Bearer <access_token> or DPoP <access_token> of the owner of key_id"
+// @Success 200 {object} ServicesResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"service requires id, type and serviceEndpoint"})
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"did document not found"})
+// @Failure 500 {object} ErrorResponse "internal error"
+// @Security ApiKeyAuth
+// @Router /testapi/did/service/add [post]
+func AddService(c *gin.Context) {
+	var requestBody AddServiceRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "AddService.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "AddService.Request", map[string]string{
+		"did":       requestBody.Did,
+		"serviceId": requestBody.Service.ID,
+		"keyId":     requestBody.KeyID,
+	})
+	if requestBody.Did == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "did is required"})
+		return
+	}
+	pvKey, ok := requestSigner(c, requestBody.KeyID, requestBody.PvKeyBase58)
+	if !ok {
+		return
+	}
+	if err := controller.AddService(requestBody.Did, requestBody.Service, pvKey); err != nil {
+		logReq(c, "AddService.Error", map[string]string{"error": err.Error()})
+		serviceError(c, err)
+		return
+	}
+	listServices(c, requestBody.Did)
+}
+
+// RemoveService
+// @Summary Remove DID service
+// @Description Remove a service from a DID document. The update is signed with the key key_id,
+// @Description which must belong to a capabilityInvocation key of the DID.
+// @ID removeDidService
+// @Accept  json
+// @Produce  json
+// @Param   RemoveServiceRequestBody  body    RemoveServiceRequestBody  true  "Remove service request"
+// @Param   Authorization  header  string  false  "Bearer <access_token> or DPoP <access_token> of the owner of key_id"
+// @Success 200 {object} ServicesResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"did and service_id are required"})
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"service not found"})
+// @Failure 500 {object} ErrorResponse "internal error"
+// @Security ApiKeyAuth
+// @Router /testapi/did/service/remove [post]
+func RemoveService(c *gin.Context) {
+	var requestBody RemoveServiceRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "RemoveService.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "RemoveService.Request", map[string]string{"did": requestBody.Did, "serviceId": requestBody.ServiceID})
+	if requestBody.Did == "" || requestBody.ServiceID == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "did and service_id are required"})
+		return
+	}
+	pvKey, ok := requestSigner(c, requestBody.KeyID, requestBody.PvKeyBase58)
+	if !ok {
+		return
+	}
+	if err := controller.RemoveService(requestBody.Did, requestBody.ServiceID, pvKey); err != nil {
+		logReq(c, "RemoveService.Error", map[string]string{"error": err.Error()})
+		serviceError(c, err)
+		return
+	}
+	listServices(c, requestBody.Did)
+}
+
+// GetServices
+// @Summary Get DID services
+// @Description List the services of a DID document, optionally filtered by type.
+// @ID getDidServices
+// @Accept  json
+// @Produce  json
+// @Param   some_id  path   string  true   "DID"
+// @Param   type     query  string  false  "Service type"
+// @Success 200 {object} ServicesResponse "ok"
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"did document not found"})
+// @Security ApiKeyAuth
+// @Router /testapi/did/services/{some_id} [get]
+func GetServices(c *gin.Context) {
+	did := c.Params.ByName("some_id")
+	logReq(c, "GetServices.Request", map[string]string{"did": did, "type": c.Query("type")})
+	services, err := controller.GetServices(did, c.Query("type"))
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	if services == nil {
+		services = []dids.ServiceProperty{}
+	}
+	c.JSON(http.StatusOK, ServicesResponse{Services: services})
+}
+
+func listServices(c *gin.Context, did string) {
+	services, err := controller.GetServices(did, "")
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	if services == nil {
+		services = []dids.ServiceProperty{}
+	}
+	logReq(c, "Services.Success", map[string]string{"did": did, "count": strconv.Itoa(len(services))})
+	c.JSON(http.StatusOK, ServicesResponse{Services: services})
+}
+
+func serviceError(c *gin.Context, err error) {
+	var dErr *derrors.Error
+	if errors.As(err, &dErr) {
+		switch dErr.Code() {
+		case derrors.CodeNotFound:
+			c.JSON(http.StatusNotFound, ErrorResponse{Code: "NOT_FOUND", Message: err.Error()})
+			return
+		case derrors.CodeInvalidInput, derrors.CodeEmptyKey, derrors.CodeInvalidKey:
+			c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: err.Error()})
+			return
+		}
+	}
+	c.JSON(http.StatusInternalServerError, ErrorResponse{Code: "INTERNAL_ERROR", Message: err.Error()})
+}
@@ -0,0 +1,153 @@
+package api
+
+import (
+	"byd50-ssi/pkg/did/core/siop"
+	"byd50-ssi/pkg/did/pkg/controller"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"time"
+)
+
+// siopRelyingParty signs users in with SIOPv2 self-issued ID tokens, resolving their DID through the controller.
+var siopRelyingParty = &siop.RelyingParty{GetPbKey: controller.GetPublicKey}
+
+type SiopRequestResponse struct {
+	State                   string                     `json:"state"`
+	Nonce                   string                     `json:"nonce"`
+	AuthorizationRequestURI string                     `json:"authorization_request_uri" example:"siopv2://?response_type=id_token&scope=openid&..."`
+	AuthorizationRequest    *siop.AuthorizationRequest `json:"authorization_request"`
+}
+
+type SiopIDTokenRequestBody struct {
+	AuthorizationRequestURI string `json:"authorization_request_uri" example:"siopv2://?response_type=id_token&scope=openid&..."`
+	Did                     string `json:"did" example:"did:byd50:holder123"`
+	KeyID                   string `json:"key_id,omitempty" example:"0b7c3f4e-3d0c-4c36-a5a0-6c3b8a2f7e51"`
+	PvKeyBase58             string `json:"pv_key_base58,omitempty" example:"3VZ6oJdR8i1qKX7kH3Yv9d7w7wzgn..."`
+	ExpiresInMinutes        int    `json:"expires_in_minutes,omitempty" example:"5"`
+}
+
+type SiopIDTokenResponse struct {
+	IDToken     string `json:"id_token"`
+	State       string `json:"state"`
+	ResponseURI string `json:"response_uri"`
+}
+
+// CreateSiopRequest
+// @Summary Create SIOPv2 sign-in request
+// @Description Relying party side: start a "sign in with your DID" session and return the siopv2:// request for the wallet.
+// @Description The wallet posts a self-issued ID token signed by its DID to /testapi/siop/response (direct_post).
+// @ID createSiopRequest
+// @Produce  json
+// @Success 200 {object} SiopRequestResponse "ok"
+// @Security ApiKeyAuth
+// @Router /testapi/siop/requests [post]
+func CreateSiopRequest(c *gin.Context) {
+	logReq(c, "CreateSiopRequest.Request", map[string]string{})
+	session, err := siopRelyingParty.CreateSession(baseURL(c) + "/v2/testapi/siop/response")
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	uri, err := session.Request.URI()
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, SiopRequestResponse{
+		State:                   session.ID,
+		Nonce:                   session.Request.Nonce,
+		AuthorizationRequestURI: uri,
+		AuthorizationRequest:    &session.Request,
+	})
+}
+
+// CreateSiopIDToken
+// @Summary Create SIOPv2 ID token
+// @Description Wallet side: answer a siopv2:// request with a self-issued ID token (iss and sub are the DID) signed by
+// @Description the key key_id, which should belong to an authentication key of the DID.
+// @ID createSiopIDToken
+// @Accept  json
+// @Produce  json
+// @Param   SiopIDTokenRequestBody  body    SiopIDTokenRequestBody  true  "ID token request"
+// @Param   Authorization  header  string  false  "Bearer <access_token> or DPoP <access_token> of the owner of key_id"
+// @Success 200 {object} SiopIDTokenResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"scope must contain openid"})
+// @Security ApiKeyAuth
+// @Router /testapi/siop/id-token [post]
+func CreateSiopIDToken(c *gin.Context) {
+	var requestBody SiopIDTokenRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "CreateSiopIDToken.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "CreateSiopIDToken.Request", map[string]string{"did": requestBody.Did})
+	if requestBody.Did == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "did is required"})
+		return
+	}
+	request, err := siop.ParseRequestURI(requestBody.AuthorizationRequestURI)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	pvKey, ok := requestSigner(c, requestBody.KeyID, requestBody.PvKeyBase58)
+	if !ok {
+		return
+	}
+	if requestBody.ExpiresInMinutes <= 0 {
+		requestBody.ExpiresInMinutes = 5
+	}
+	idToken, err := siop.CreateIDToken(request, requestBody.Did, time.Duration(requestBody.ExpiresInMinutes)*time.Minute, pvKey)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, SiopIDTokenResponse{IDToken: idToken, State: request.State, ResponseURI: request.ResponseURI})
+}
+
+// PostSiopResponse
+// @Summary SIOPv2 direct_post response endpoint
+// @Description Wallet side: post the self-issued ID token (form encoded id_token and state). The token must be signed by a
+// @Description key of its sub DID, resolved through the DID registry, with this endpoint as aud and the session nonce.
+// @Description A rejected ID token leaves the session waiting; the session error tells why it was rejected.
+// @ID postSiopResponse
+// @Accept  x-www-form-urlencoded
+// @Produce  json
+// @Param   id_token  formData  string  true  "Self-issued ID token"
+// @Param   state  formData  string  true  "Session state"
+// @Success 200 {object} map[string]string "ok"
+// @Failure 400 {object} Oid4vpErrorResponse "bad request" example({"error":"invalid_request","error_description":"id token nonce mismatch"})
+// @Router /testapi/siop/response [post]
+func PostSiopResponse(c *gin.Context) {
+	state := c.PostForm("state")
+	logReq(c, "PostSiopResponse.Request", map[string]string{"state": state})
+	session, err := siopRelyingParty.HandleResponse(state, c.PostForm("id_token"))
+	if err != nil {
+		logReq(c, "PostSiopResponse.Rejected", map[string]string{"state": state, "error": err.Error()})
+		c.JSON(http.StatusBadRequest, Oid4vpErrorResponse{Error: "invalid_request", ErrorDescription: err.Error()})
+		return
+	}
+	logReq(c, "PostSiopResponse.Success", map[string]string{"state": state, "subject": session.Subject})
+	c.JSON(http.StatusOK, gin.H{})
+}
+
+// GetSiopSession
+// @Summary Get SIOPv2 session status
+// @Description Relying party side: poll a sign-in session (created, authenticated or expired). Authenticated
+// @Description sessions carry the DID that signed in as subject.
+// @ID getSiopSession
+// @Produce  json
+// @Param   state  path  string  true  "Session state"
+// @Success 200 {object} siop.Session "ok"
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"session not found"})
+// @Security ApiKeyAuth
+// @Router /testapi/siop/sessions/{state} [get]
+func GetSiopSession(c *gin.Context) {
+	session, err := siopRelyingParty.Session(c.Param("state"))
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, session)
+}
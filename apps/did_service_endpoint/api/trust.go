@@ -0,0 +1,422 @@
+package api
+
+import (
+	"byd50-ssi/pkg/did/trust"
+	pb "byd50-ssi/proto-files"
+	"context"
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type TrustedIssuerBody struct {
+	IssuerDid      string `json:"issuer_did" example:"did:byd50:1234567890abcdef"`
+	CredentialType string `json:"credential_type" example:"eDriver'sLicenceCardCredential"`
+	ValidFrom      int64  `json:"valid_from,omitempty" example:"0"`
+	ValidUntil     int64  `json:"valid_until,omitempty" example:"0"`
+	AccreditedBy   string `json:"accredited_by,omitempty"`
+	Accreditation  string `json:"accreditation,omitempty"`
+}
+
+type RootAuthorityBody struct {
+	Did             string   `json:"did" example:"did:byd50:1234567890abcdef"`
+	CredentialTypes []string `json:"credential_types,omitempty"`
+	ValidFrom       int64    `json:"valid_from,omitempty" example:"0"`
+	ValidUntil      int64    `json:"valid_until,omitempty" example:"0"`
+}
+
+type RemoveTrustedIssuerRequestBody struct {
+	IssuerDid      string `json:"issuer_did" example:"did:byd50:1234567890abcdef"`
+	CredentialType string `json:"credential_type" example:"eDriver'sLicenceCardCredential"`
+}
+
+type RemoveRootAuthorityRequestBody struct {
+	Did string `json:"did" example:"did:byd50:1234567890abcdef"`
+}
+
+type CreateAccreditationRequestBody struct {
+	RootDid          string   `json:"root_did" example:"did:byd50:1234567890abcdef"`
+	KeyID            string   `json:"key_id,omitempty" example:"0b7c3f4e-3d0c-4c36-a5a0-6c3b8a2f7e51"`
+	PvKeyBase58      string   `json:"pv_key_base58,omitempty" example:"3VZ6oJdR8i1qKX7kH3Yv9d7w7wzgn..."`
+	IssuerDid        string   `json:"issuer_did" example:"did:byd50:fedcba0987654321"`
+	CredentialTypes  []string `json:"credential_types"`
+	ExpiresInMinutes int      `json:"expires_in_minutes" example:"525600"`
+}
+
+type AccreditationResponse struct {
+	AccreditationVcJwt string `json:"accreditation_vc_jwt"`
+}
+
+type AccreditRequestBody struct {
+	AccreditationVcJwt string `json:"accreditation_vc_jwt"`
+}
+
+type TrustedIssuersResponse struct {
+	Issuers []TrustedIssuerBody `json:"issuers"`
+}
+
+type RootAuthoritiesResponse struct {
+	Roots []RootAuthorityBody `json:"roots"`
+}
+
+type CheckIssuerRequestBody struct {
+	IssuerDid       string   `json:"issuer_did" example:"did:byd50:1234567890abcdef"`
+	CredentialTypes []string `json:"credential_types"`
+}
+
+type CheckIssuerResponse struct {
+	Trusted bool   `json:"trusted"`
+	Reason  string `json:"reason,omitempty"`
+}
+
+type TrustResultResponse struct {
+	Result string `json:"result"`
+}
+
+// ListTrustedIssuers
+// @Summary List trusted issuers
+// @Description List the issuers the trust registry accepts, optionally only those of a credential type.
+// @ID listTrustedIssuers
+// @Produce  json
+// @Param   type  query  string  false  "Credential type"
+// @Success 200 {object} TrustedIssuersResponse "ok"
+// @Failure 500 {object} ErrorResponse "internal error"
+// @Security ApiKeyAuth
+// @Router /testapi/trust/issuers [get]
+func ListTrustedIssuers(c *gin.Context) {
+	logReq(c, "ListTrustedIssuers.Request", map[string]string{"type": c.Query("type")})
+	reply, err := trust.GetClient().ListTrustedIssuers(c.Request.Context(), &pb.ListTrustedIssuersRequest{CredentialType: c.Query("type")})
+	if err != nil {
+		trustError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, TrustedIssuersResponse{Issuers: trustedIssuerBodies(reply.GetIssuers())})
+}
+
+// AddTrustedIssuer
+// @Summary Add trusted issuer
+// @Description Accept issuer_did as an issuer of credential_type, optionally within [valid_from, valid_until) (unix seconds).
+// @Description Reserved to the registry operator: send its token as "Authorization: Bearer <token>".
+// @ID addTrustedIssuer
+// @Accept  json
+// @Produce  json
+// @Param   TrustedIssuerBody  body    TrustedIssuerBody  true  "Trusted issuer"
+// @Success 200 {object} TrustResultResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"issuer_did and credential_type are required"})
+// @Failure 500 {object} ErrorResponse "internal error"
+// @Failure 401 {object} ErrorResponse "operator token required"
+// @Failure 403 {object} ErrorResponse "forbidden" example({"code":"FORBIDDEN","message":"invalid operator token"})
+// @Security ApiKeyAuth
+// @Router /testapi/trust/issuers/add [post]
+func AddTrustedIssuer(c *gin.Context) {
+	var requestBody TrustedIssuerBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "AddTrustedIssuer.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "AddTrustedIssuer.Request", map[string]string{"issuerDid": requestBody.IssuerDid, "type": requestBody.CredentialType})
+	if requestBody.IssuerDid == "" || requestBody.CredentialType == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "issuer_did and credential_type are required"})
+		return
+	}
+	reply, err := trust.GetClient().AddTrustedIssuer(operatorContext(c), &pb.AddTrustedIssuerRequest{Issuer: &pb.TrustedIssuer{
+		IssuerDid:      requestBody.IssuerDid,
+		CredentialType: requestBody.CredentialType,
+		ValidFrom:      requestBody.ValidFrom,
+		ValidUntil:     requestBody.ValidUntil,
+	}})
+	if err != nil {
+		trustError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, TrustResultResponse{Result: reply.GetResult()})
+}
+
+// RemoveTrustedIssuer
+// @Summary Remove trusted issuer
+// @Description Stop accepting issuer_did as an issuer of credential_type. Reserved to the registry operator.
+// @ID removeTrustedIssuer
+// @Accept  json
+// @Produce  json
+// @Param   RemoveTrustedIssuerRequestBody  body    RemoveTrustedIssuerRequestBody  true  "Trusted issuer"
+// @Success 200 {object} TrustResultResponse "ok"
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"trusted issuer not found"})
+// @Failure 401 {object} ErrorResponse "operator token required"
+// @Failure 403 {object} ErrorResponse "forbidden" example({"code":"FORBIDDEN","message":"invalid operator token"})
+// @Security ApiKeyAuth
+// @Router /testapi/trust/issuers/remove [post]
+func RemoveTrustedIssuer(c *gin.Context) {
+	var requestBody RemoveTrustedIssuerRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "RemoveTrustedIssuer.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "RemoveTrustedIssuer.Request", map[string]string{"issuerDid": requestBody.IssuerDid, "type": requestBody.CredentialType})
+	reply, err := trust.GetClient().RemoveTrustedIssuer(operatorContext(c), &pb.RemoveTrustedIssuerRequest{
+		IssuerDid:      requestBody.IssuerDid,
+		CredentialType: requestBody.CredentialType,
+	})
+	if err != nil {
+		trustError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, TrustResultResponse{Result: reply.GetResult()})
+}
+
+// ListRootAuthorities
+// @Summary List root authorities
+// @Description List the root authorities that may accredit issuers.
+// @ID listRootAuthorities
+// @Produce  json
+// @Success 200 {object} RootAuthoritiesResponse "ok"
+// @Failure 500 {object} ErrorResponse "internal error"
+// @Security ApiKeyAuth
+// @Router /testapi/trust/roots [get]
+func ListRootAuthorities(c *gin.Context) {
+	logReq(c, "ListRootAuthorities.Request", nil)
+	reply, err := trust.GetClient().ListRootAuthorities(c.Request.Context(), &pb.ListRootAuthoritiesRequest{})
+	if err != nil {
+		trustError(c, err)
+		return
+	}
+	roots := make([]RootAuthorityBody, 0, len(reply.GetRoots()))
+	for _, root := range reply.GetRoots() {
+		roots = append(roots, RootAuthorityBody{
+			Did:             root.GetDid(),
+			CredentialTypes: root.GetCredentialTypes(),
+			ValidFrom:       root.GetValidFrom(),
+			ValidUntil:      root.GetValidUntil(),
+		})
+	}
+	c.JSON(http.StatusOK, RootAuthoritiesResponse{Roots: roots})
+}
+
+// AddRootAuthority
+// @Summary Add root authority
+// @Description Register a root authority for credential_types (any type when empty). A root is trusted
+// @Description for those types and may accredit issuers of them. Reserved to the registry operator.
+// @ID addRootAuthority
+// @Accept  json
+// @Produce  json
+// @Param   RootAuthorityBody  body    RootAuthorityBody  true  "Root authority"
+// @Success 200 {object} TrustResultResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"did is required"})
+// @Failure 401 {object} ErrorResponse "operator token required"
+// @Failure 403 {object} ErrorResponse "forbidden" example({"code":"FORBIDDEN","message":"invalid operator token"})
+// @Security ApiKeyAuth
+// @Router /testapi/trust/roots/add [post]
+func AddRootAuthority(c *gin.Context) {
+	var requestBody RootAuthorityBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "AddRootAuthority.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "AddRootAuthority.Request", map[string]string{"did": requestBody.Did, "types": strings.Join(requestBody.CredentialTypes, ",")})
+	if requestBody.Did == "" {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "did is required"})
+		return
+	}
+	reply, err := trust.GetClient().AddRootAuthority(operatorContext(c), &pb.AddRootAuthorityRequest{Root: &pb.RootAuthority{
+		Did:             requestBody.Did,
+		CredentialTypes: requestBody.CredentialTypes,
+		ValidFrom:       requestBody.ValidFrom,
+		ValidUntil:      requestBody.ValidUntil,
+	}})
+	if err != nil {
+		trustError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, TrustResultResponse{Result: reply.GetResult()})
+}
+
+// RemoveRootAuthority
+// @Summary Remove root authority
+// @Description Remove a root authority. The issuers it accredited are no longer trusted. Reserved to the registry operator.
+// @ID removeRootAuthority
+// @Accept  json
+// @Produce  json
+// @Param   RemoveRootAuthorityRequestBody  body    RemoveRootAuthorityRequestBody  true  "Root authority"
+// @Success 200 {object} TrustResultResponse "ok"
+// @Failure 404 {object} ErrorResponse "not found" example({"code":"NOT_FOUND","message":"root authority not found"})
+// @Failure 401 {object} ErrorResponse "operator token required"
+// @Failure 403 {object} ErrorResponse "forbidden" example({"code":"FORBIDDEN","message":"invalid operator token"})
+// @Security ApiKeyAuth
+// @Router /testapi/trust/roots/remove [post]
+func RemoveRootAuthority(c *gin.Context) {
+	var requestBody RemoveRootAuthorityRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "RemoveRootAuthority.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "RemoveRootAuthority.Request", map[string]string{"did": requestBody.Did})
+	reply, err := trust.GetClient().RemoveRootAuthority(operatorContext(c), &pb.RemoveRootAuthorityRequest{Did: requestBody.Did})
+	if err != nil {
+		trustError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, TrustResultResponse{Result: reply.GetResult()})
+}
+
+// CreateAccreditation
+// @Summary Create accreditation VC
+// @Description Sign an AccreditationCredential in which root_did accredits issuer_did for credential_types.
+// @Description The key key_id should belong to an assertionMethod key of root_did. Submit it with /testapi/trust/accredit.
+// @ID createAccreditation
+// @Accept  json
+// @Produce  json
+// @Param   CreateAccreditationRequestBody  body    CreateAccreditationRequestBody  true  "Accreditation"
+// @Param   Authorization  header  string  false  "Bearer <access_token> or DPoP <access_token> of the owner of key_id"
+// @Success 200 {object} AccreditationResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"root_did, issuer_did and credential_types are required"})
+// @Security ApiKeyAuth
+// @Router /testapi/trust/accreditation/create [post]
+func CreateAccreditation(c *gin.Context) {
+	var requestBody CreateAccreditationRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "CreateAccreditation.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "CreateAccreditation.Request", map[string]string{
+		"rootDid":   requestBody.RootDid,
+		"issuerDid": requestBody.IssuerDid,
+		"types":     strings.Join(requestBody.CredentialTypes, ","),
+		"expires":   strconv.Itoa(requestBody.ExpiresInMinutes),
+	})
+	if requestBody.RootDid == "" || requestBody.IssuerDid == "" || len(requestBody.CredentialTypes) == 0 {
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "root_did, issuer_did and credential_types are required"})
+		return
+	}
+	if requestBody.ExpiresInMinutes <= 0 {
+		requestBody.ExpiresInMinutes = 60 * 24 * 365
+	}
+	pvKey, ok := requestSigner(c, requestBody.KeyID, requestBody.PvKeyBase58)
+	if !ok {
+		return
+	}
+	vcJwt, err := trust.IssueAccreditation(requestBody.RootDid, requestBody.IssuerDid, requestBody.CredentialTypes,
+		time.Duration(requestBody.ExpiresInMinutes)*time.Minute, pvKey)
+	if err != nil {
+		serviceError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, AccreditationResponse{AccreditationVcJwt: vcJwt})
+}
+
+// Accredit
+// @Summary Submit accreditation VC
+// @Description Verify an AccreditationCredential signed by a registered root authority and trust its subject
+// @Description for the accredited credential types until the accreditation expires.
+// @ID accredit
+// @Accept  json
+// @Produce  json
+// @Param   AccreditRequestBody  body    AccreditRequestBody  true  "Accreditation"
+// @Success 200 {object} TrustedIssuersResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"not a root authority"})
+// @Security ApiKeyAuth
+// @Router /testapi/trust/accredit [post]
+func Accredit(c *gin.Context) {
+	var requestBody AccreditRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil || requestBody.AccreditationVcJwt == "" {
+		logReq(c, "Accredit.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "accreditation_vc_jwt is required"})
+		return
+	}
+	logReq(c, "Accredit.Request", nil)
+	reply, err := trust.GetClient().Accredit(c.Request.Context(), &pb.AccreditRequest{AccreditationVcJwt: requestBody.AccreditationVcJwt})
+	if err != nil {
+		trustError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, TrustedIssuersResponse{Issuers: trustedIssuerBodies(reply.GetIssuers())})
+}
+
+// CheckTrustedIssuer
+// @Summary Check issuer trust
+// @Description Ask the trust registry whether issuer_did is trusted now for every one of credential_types.
+// @ID checkTrustedIssuer
+// @Accept  json
+// @Produce  json
+// @Param   CheckIssuerRequestBody  body    CheckIssuerRequestBody  true  "Issuer"
+// @Success 200 {object} CheckIssuerResponse "ok"
+// @Failure 400 {object} ErrorResponse "bad request" example({"code":"INVALID_PARAM","message":"issuer_did and credential_types are required"})
+// @Security ApiKeyAuth
+// @Router /testapi/trust/check [post]
+func CheckTrustedIssuer(c *gin.Context) {
+	var requestBody CheckIssuerRequestBody
+	if err := c.ShouldBindJSON(&requestBody); err != nil {
+		logReq(c, "CheckTrustedIssuer.BadRequest", map[string]string{"error": "invalid json"})
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: "invalid json body"})
+		return
+	}
+	logReq(c, "CheckTrustedIssuer.Request", map[string]string{"issuerDid": requestBody.IssuerDid, "types": strings.Join(requestBody.CredentialTypes, ",")})
+	reply, err := trust.GetClient().TrustRegistryClient.CheckIssuer(c.Request.Context(), &pb.CheckIssuerRequest{
+		IssuerDid:       requestBody.IssuerDid,
+		CredentialTypes: requestBody.CredentialTypes,
+	})
+	if err != nil {
+		trustError(c, err)
+		return
+	}
+	c.JSON(http.StatusOK, CheckIssuerResponse{Trusted: reply.GetTrusted(), Reason: reply.GetReason()})
+}
+
+func trustedIssuerBodies(issuers []*pb.TrustedIssuer) []TrustedIssuerBody {
+	bodies := make([]TrustedIssuerBody, 0, len(issuers))
+	for _, issuer := range issuers {
+		bodies = append(bodies, TrustedIssuerBody{
+			IssuerDid:      issuer.GetIssuerDid(),
+			CredentialType: issuer.GetCredentialType(),
+			ValidFrom:      issuer.GetValidFrom(),
+			ValidUntil:     issuer.GetValidUntil(),
+			AccreditedBy:   issuer.GetAccreditedBy(),
+			Accreditation:  issuer.GetAccreditation(),
+		})
+	}
+	return bodies
+}
+
+// RequireOperatorToken protects the trust registry writes reserved to its operator. The token, sent as
+// "Authorization: Bearer <token>", is checked by the registry itself (see trust.OperatorInterceptor).
+func RequireOperatorToken() gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if _, ok := trust.OperatorTokenFromHeader(c.GetHeader("Authorization")); !ok {
+			c.Header("WWW-Authenticate", "Bearer")
+			c.AbortWithStatusJSON(http.StatusUnauthorized, ErrorResponse{Code: "UNAUTHORIZED", Message: "operator token required"})
+			return
+		}
+		c.Next()
+	}
+}
+
+// operatorContext forwards the operator token of the request to the trust registry.
+func operatorContext(c *gin.Context) context.Context {
+	token, _ := trust.OperatorTokenFromHeader(c.GetHeader("Authorization"))
+	return trust.OperatorContext(c.Request.Context(), token)
+}
+
+// trustError maps a gRPC error of the trust registry to an HTTP response.
+func trustError(c *gin.Context, err error) {
+	st, _ := status.FromError(err)
+	logReq(c, "Trust.Error", map[string]string{"error": st.Message()})
+	switch st.Code() {
+	case codes.InvalidArgument:
+		c.JSON(http.StatusBadRequest, ErrorResponse{Code: "INVALID_PARAM", Message: st.Message()})
+	case codes.NotFound:
+		c.JSON(http.StatusNotFound, ErrorResponse{Code: "NOT_FOUND", Message: st.Message()})
+	case codes.Unauthenticated:
+		c.JSON(http.StatusUnauthorized, ErrorResponse{Code: "UNAUTHORIZED", Message: st.Message()})
+	case codes.PermissionDenied:
+		c.JSON(http.StatusForbidden, ErrorResponse{Code: "FORBIDDEN", Message: st.Message()})
+	default:
+		c.JSON(http.StatusInternalServerError, ErrorResponse{Code: "INTERNAL_ERROR", Message: st.Message()})
+	}
+}
@@ -31,15 +31,67 @@ func main() {
 	r.POST("/v2/testapi/create-did", api.CreateDid)
 	r.GET("/v2/testapi/get-did/:some_id", api.GetDid)
 	r.GET("/v2/testapi/get-did-public-key/:some_id", api.GetDidPublicKey)
+	r.POST("/v2/testapi/did/service/add", api.AddService)
+	r.POST("/v2/testapi/did/service/remove", api.RemoveService)
+	r.GET("/v2/testapi/did/services/:some_id", api.GetServices)
+	r.POST("/v2/testapi/did/key-agreement/add", api.AddKeyAgreementKey)
+	r.POST("/v2/testapi/did/verification-method/remove", api.RemoveVerificationMethod)
+	r.POST("/v2/testapi/jwe/encrypt", api.EncryptJWE)
+	r.POST("/v2/testapi/jwe/decrypt", api.RequireAccessToken(), api.DecryptJWE)
+	r.GET("/.well-known/did-configuration.json", api.GetDidConfiguration)
+	r.POST("/v2/testapi/domain-linkage/issue", api.IssueDomainLinkage)
+	r.POST("/v2/testapi/domain-linkage/verify", api.VerifyDomainLinkage)
+	r.GET("/v2/testapi/trust/issuers", api.ListTrustedIssuers)
+	r.POST("/v2/testapi/trust/issuers/add", api.RequireOperatorToken(), api.AddTrustedIssuer)
+	r.POST("/v2/testapi/trust/issuers/remove", api.RequireOperatorToken(), api.RemoveTrustedIssuer)
+	r.GET("/v2/testapi/trust/roots", api.ListRootAuthorities)
+	r.POST("/v2/testapi/trust/roots/add", api.RequireOperatorToken(), api.AddRootAuthority)
+	r.POST("/v2/testapi/trust/roots/remove", api.RequireOperatorToken(), api.RemoveRootAuthority)
+	r.POST("/v2/testapi/trust/accreditation/create", api.CreateAccreditation)
+	r.POST("/v2/testapi/trust/accredit", api.Accredit)
+	r.POST("/v2/testapi/trust/check", api.CheckTrustedIssuer)
 	r.POST("/v2/testapi/vc/create", api.CreateVc)
 	r.POST("/v2/testapi/vc/verify", api.VerifyVc)
 	r.POST("/v2/testapi/vp/create", api.CreateVp)
 	r.POST("/v2/testapi/vp/verify", api.VerifyVp)
+	r.POST("/v2/testapi/sd-jwt/create", api.CreateSdJwt)
+	r.POST("/v2/testapi/sd-jwt/present", api.PresentSdJwt)
+	r.POST("/v2/testapi/sd-jwt/verify", api.VerifySdJwt)
+	r.GET("/v2/testapi/pex/definitions/:id", api.GetPresentationDefinition)
+	r.POST("/v2/testapi/pex/match", api.MatchPresentationDefinition)
+	r.POST("/v2/testapi/pex/present", api.PresentPresentationDefinition)
+	r.POST("/v2/testapi/pex/evaluate", api.EvaluatePresentationSubmission)
+	r.POST("/v2/testapi/oid4vp/requests", api.CreateOid4vpRequest)
+	r.GET("/v2/testapi/oid4vp/request/:state", api.GetOid4vpRequestObject)
+	r.POST("/v2/testapi/oid4vp/response", api.PostOid4vpResponse)
+	r.GET("/v2/testapi/oid4vp/sessions/:state", api.GetOid4vpSession)
+	r.POST("/v2/testapi/siop/requests", api.CreateSiopRequest)
+	r.POST("/v2/testapi/siop/id-token", api.CreateSiopIDToken)
+	r.POST("/v2/testapi/siop/response", api.PostSiopResponse)
+	r.GET("/v2/testapi/siop/sessions/:state", api.GetSiopSession)
+	r.POST("/v2/testapi/didauth/challenges", api.CreateDidAuthChallenge)
+	r.POST("/v2/testapi/didauth/sign", api.SignDidAuthChallenge)
+	r.POST("/v2/testapi/didauth/verify", api.VerifyDidAuthResponse)
+	r.POST("/v2/testapi/didauth/token", api.RefreshDidAuthToken)
+	r.POST("/v2/testapi/didauth/revoke", api.RevokeDidAuthToken)
+	r.POST("/v2/testapi/didauth/dpop-proof", api.CreateDidAuthDPoPProof)
+	r.GET("/v2/testapi/didauth/session", api.RequireAccessToken(), api.GetDidAuthSession)
+	r.POST("/v2/testapi/keys/enroll", api.EnrollKey)
+	r.POST("/v2/testapi/keys", api.RequireAccessToken(), api.CreateKey)
+	r.GET("/v2/testapi/keys", api.RequireAccessToken(), api.ListKeys)
+	r.DELETE("/v2/testapi/keys/:key_id", api.RequireAccessToken(), api.DeleteKey)
+	r.GET("/.well-known/openid-credential-issuer/v2/testapi/oid4vci", api.GetOid4vciIssuerMetadata)
+	r.GET("/.well-known/oauth-authorization-server/v2/testapi/oid4vci", api.GetOid4vciAuthorizationServerMetadata)
+	r.POST("/v2/testapi/oid4vci/offers", api.CreateOid4vciOffer)
+	r.POST("/v2/testapi/oid4vci/token", api.Oid4vciToken)
+	r.POST("/v2/testapi/oid4vci/credential", api.Oid4vciCredential)
 	r.GET("/v2/testapi/demo/actors", api.GetDemoActors)
 	r.POST("/v2/testapi/license/challenge", api.LicenseChallenge)
 	r.POST("/v2/testapi/license/issue", api.IssueLicense)
 	r.POST("/v2/testapi/rental/challenge", api.RentalChallenge)
 	r.POST("/v2/testapi/rental/issue", api.IssueRental)
+	r.GET("/v2/testapi/ledger/credentials", api.ListIssuedCredentials)
+	r.GET("/v2/testapi/ledger/credentials/:jti", api.GetIssuedCredential)
 
 	url := ginSwagger.URL("http://localhost:8080/swagger/doc.json") // The url pointing to API definition
 	r.GET("/swagger/*any", ginSwagger.WrapHandler(swaggerFiles.Handler, url))
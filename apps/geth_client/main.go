@@ -79,11 +79,16 @@ func main() {
 		gasPrice = big.NewInt(0)
 	}
 
-	localKMS, err := kms.InitKMS(kms.KeyTypeECDSA)
+	keyManager := kms.NewMemoryKeyManager()
+	keyID, err := keyManager.Create(kms.KeyTypeECDSA)
 	if err != nil {
-		log.Fatalf("failed to init KMS: %v", err)
+		log.Fatalf("failed to create DID key: %v", err)
 	}
-	createdDid, createdDoc := dids.CreateDID("eth", localKMS.PbKeyBase58())
+	pbKeyBase58, err := kms.PublicKeyBase58(keyManager, keyID)
+	if err != nil {
+		log.Fatalf("failed to export DID key: %v", err)
+	}
+	createdDid, createdDoc := dids.CreateDID("eth", pbKeyBase58)
 	log.Printf("CreateDid input => %s", createdDid)
 
 	tx, err := instance.CreateDid(&bind.TransactOpts{
@@ -4,39 +4,68 @@ import (
 	"byd50-ssi/pkg/did/core"
 	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
 	"byd50-ssi/pkg/did/kms"
-	"crypto/x509"
 	"encoding/json"
 	"errors"
 	"fmt"
-	"github.com/btcsuite/btcutil/base58"
 	"github.com/golang-jwt/jwt"
 	"time"
 )
 
-func CreateKeyPairForAndr() {
-	_, err := kms.InitKMS(kms.KeyTypeECDSA)
+// keyManager holds the keys of the app. The app refers to them by the key IDs returned on creation.
+var keyManager = kms.NewMemoryKeyManager()
+
+// CreateKeyForAndr creates a key of keyType and returns its key ID, or an empty string on failure.
+func CreateKeyForAndr(keyType string) string {
+	keyID, err := keyManager.Create(keyType)
 	if err != nil {
-		panic(err)
+		return ""
 	}
+	return keyID
 }
 
-func GetPrivateKeyBase58() string {
-	dkms := kms.GetKMS()
-	return dkms.PvKeyBase58()
+// CreateKeyPairForAndr creates an ECDSA key and returns its key ID, or an empty string on failure.
+func CreateKeyPairForAndr() string {
+	return CreateKeyForAndr(kms.KeyTypeECDSA)
 }
 
-func GetPublicKeyBase58() string {
-	dkms := kms.GetKMS()
-	return dkms.PbKeyBase58()
+// GetPublicKeyBase58 returns the public key of keyID, or an empty string when there is no such key.
+func GetPublicKeyBase58(keyID string) string {
+	pbKeyBase58, err := kms.PublicKeyBase58(keyManager, keyID)
+	if err != nil {
+		return ""
+	}
+	return pbKeyBase58
 }
 
-func CreateVpForAndr(did, iss, pvKeyBase58, credTyp, vcJwt string) string {
+// ListKeysForAndr returns the keys as a JSON array of key handles.
+func ListKeysForAndr() string {
+	handles, err := keyManager.List()
+	if err != nil {
+		return "[]"
+	}
+	data, err := json.Marshal(handles)
+	if err != nil {
+		return "[]"
+	}
+	return string(data)
+}
+
+// DeleteKeyForAndr deletes keyID and reports whether it existed.
+func DeleteKeyForAndr(keyID string) bool {
+	return keyManager.Delete(keyID) == nil
+}
+
+// CreateVpForAndr creates a VP of vcJwt for did, signed with keyID. It returns an empty string on failure.
+func CreateVpForAndr(did, iss, keyID, credTyp, vcJwt string) string {
 	holderDid := did
 	issuer := did
 	if len(iss) > 0 {
 		issuer = iss
 	}
-	holderPvKey, _ := x509.ParseECPrivateKey(base58.Decode(pvKeyBase58))
+	holderPvKey, err := kms.KeySigner(keyManager, keyID)
+	if err != nil {
+		return ""
+	}
 
 	// ******************** Build VP Claims ******************** //
 	nonce := core.RandomString(12)
@@ -60,7 +89,7 @@ func CreateVpForAndr(did, iss, pvKeyBase58, credTyp, vcJwt string) string {
 	standardClaims := jwt.StandardClaims{
 		Audience:  "",
 		ExpiresAt: time.Now().Add(time.Minute * 5).Unix(),
-		Id:        "089a411f-0d88-450f-8cc0-1a3acfebecd3",
+		Id:        core.NewCredentialID(),
 		IssuedAt:  time.Now().Unix(),
 		Issuer:    issuer,
 		NotBefore: time.Now().Unix(),
@@ -0,0 +1,90 @@
+package foo
+
+import (
+	"byd50-ssi/pkg/did/kms"
+	"byd50-ssi/pkg/keys"
+	"sync"
+)
+
+// wallet is the seed of the restored mnemonic and the key IDs of the keys derived from it, by path.
+var wallet struct {
+	mu      sync.Mutex
+	seed    []byte
+	derived map[string]string
+}
+
+// NewMnemonicForAndr generates a 24-word BIP-39 mnemonic for a new wallet, or returns an empty string on failure.
+// Show it to the user for backup and pass it to RestoreWalletForAndr.
+func NewMnemonicForAndr() string {
+	mnemonic, err := keys.NewMnemonic(256)
+	if err != nil {
+		return ""
+	}
+	return mnemonic
+}
+
+// RestoreWalletForAndr opens the wallet of mnemonic and passphrase, so that DeriveKeyForAndr derives its keys.
+// It reports whether the mnemonic is valid.
+func RestoreWalletForAndr(mnemonic, passphrase string) bool {
+	seed, err := keys.MnemonicToSeed(mnemonic, passphrase)
+	if err != nil {
+		return false
+	}
+	wallet.mu.Lock()
+	defer wallet.mu.Unlock()
+	wallet.seed = seed
+	wallet.derived = map[string]string{}
+	return true
+}
+
+// DeriveKeyForAndr derives the wallet key of keyType (ecdsa, secp256k1 or ed25519) and returns its key ID, or an
+// empty string on failure. An empty relyingParty derives the identity key of the wallet's DID, otherwise the
+// pairwise key for the relying party's DID or origin; rotation counts key rotations from 0. The same mnemonic
+// always yields the same keys.
+func DeriveKeyForAndr(keyType, relyingParty string, rotation int) string {
+	curve := map[string]string{
+		kms.KeyTypeECDSA:     keys.CurveP256,
+		kms.KeyTypeSecp256k1: keys.CurveSecp256k1,
+		kms.KeyTypeEd25519:   keys.CurveEd25519,
+	}[keyType]
+	if curve == "" || rotation < 0 {
+		return ""
+	}
+	var (
+		path string
+		err  error
+	)
+	if relyingParty == "" {
+		path, err = keys.IdentityKeyPath(curve, uint32(rotation))
+	} else {
+		path, err = keys.RelyingPartyKeyPath(curve, relyingParty, uint32(rotation))
+	}
+	if err != nil {
+		return ""
+	}
+
+	wallet.mu.Lock()
+	defer wallet.mu.Unlock()
+	if wallet.seed == nil {
+		return ""
+	}
+	if keyID, ok := wallet.derived[path]; ok {
+		if _, err := keyManager.PublicKey(keyID); err == nil {
+			return keyID
+		}
+	}
+	master, err := keys.NewMasterKey(wallet.seed, curve)
+	if err != nil {
+		return ""
+	}
+	hdKey, err := master.Derive(path)
+	if err != nil {
+		return ""
+	}
+	keyID, err := keyManager.Import(hdKey.PrivateKey())
+	if err != nil {
+		return ""
+	}
+	wallet.derived[path] = keyID
+	return keyID
+}
@@ -7,18 +7,48 @@ import (
 
 //export createKeyPair
 func createKeyPair() *C.char {
-	foo.CreateKeyPairForAndr()
-	return C.CString("success")
+	return C.CString(foo.CreateKeyPairForAndr())
 }
 
-//export getPbKey
-func getPbKey() *C.char {
-	return C.CString(foo.GetPublicKeyBase58())
+//export createKey
+func createKey(keyType *C.char) *C.char {
+	return C.CString(foo.CreateKeyForAndr(C.GoString(keyType)))
+}
+
+//export listKeys
+func listKeys() *C.char {
+	return C.CString(foo.ListKeysForAndr())
+}
+
+//export deleteKey
+func deleteKey(keyID *C.char) C.int {
+	if foo.DeleteKeyForAndr(C.GoString(keyID)) {
+		return 1
+	}
+	return 0
+}
+
+//export newMnemonic
+func newMnemonic() *C.char {
+	return C.CString(foo.NewMnemonicForAndr())
+}
+
+//export restoreWallet
+func restoreWallet(mnemonic *C.char, passphrase *C.char) C.int {
+	if foo.RestoreWalletForAndr(C.GoString(mnemonic), C.GoString(passphrase)) {
+		return 1
+	}
+	return 0
 }
 
-//export getPvKey
-func getPvKey() *C.char {
-	return C.CString(foo.GetPrivateKeyBase58())
+//export deriveKey
+func deriveKey(keyType *C.char, relyingParty *C.char, rotation C.int) *C.char {
+	return C.CString(foo.DeriveKeyForAndr(C.GoString(keyType), C.GoString(relyingParty), int(rotation)))
+}
+
+//export getPbKey
+func getPbKey(keyID *C.char) *C.char {
+	return C.CString(foo.GetPublicKeyBase58(C.GoString(keyID)))
 }
 
 //export createVp
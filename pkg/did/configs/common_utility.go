@@ -7,6 +7,7 @@ import (
 	"path"
 	"path/filepath"
 	"runtime"
+	"time"
 )
 
 var UseConfig SysUseConfig
@@ -26,6 +27,7 @@ func GetConfig() SysUseConfig {
 		SystemLogMode:      "N/A",
 		SystemLogPrintMode: "N/A",
 		GenerationRule:     "hexdigit",
+		DidAuthClockSkew:   "30s",
 		RelService: struct {
 			DidRegistry struct {
 				Address string `yaml:"address"`
@@ -278,6 +280,15 @@ func GetConfig() SysUseConfig {
 	useConfig.SystemLogMode = config.SystemLogMode
 	useConfig.SystemLogPrintMode = config.SystemLogPrintMode
 	useConfig.GenerationRule = config.GenerationRule
+	useConfig.InsecureDemoKeys = config.InsecureDemoKeys
+	useConfig.DidAuthClockSkew = 30 * time.Second
+	if config.DidAuthClockSkew != "" {
+		if skew, err := time.ParseDuration(config.DidAuthClockSkew); err == nil {
+			useConfig.DidAuthClockSkew = skew
+		} else {
+			log.Printf("Invalid did_auth_clock_skew %q: %s. Using %v.", config.DidAuthClockSkew, err, useConfig.DidAuthClockSkew)
+		}
+	}
 
 	// 시스템 모드 별 설정
 	switch config.SystemMode {
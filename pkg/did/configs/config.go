@@ -1,5 +1,7 @@
 package configs
 
+import "time"
+
 // DidConfig : API 서버 환경 설정
 type DidConfig struct {
 	SystemMode         string `yaml:"system_mode"`
@@ -8,6 +10,10 @@ type DidConfig struct {
 	SystemLogMode      string `yaml:"system_log_mode"`
 	SystemLogPrintMode string `yaml:"system_log_print_mode"`
 	GenerationRule     string `yaml:"generation_rule"`
+	// DidAuthClockSkew is the clock skew tolerated when verifying DID auth tokens, as a Go duration.
+	DidAuthClockSkew string `yaml:"did_auth_clock_skew"`
+	// InsecureDemoKeys lets REST clients send raw private keys (pv_key_base58) instead of key IDs of the service KMS.
+	InsecureDemoKeys bool `yaml:"insecure_demo_keys"`
 
 	RelService struct {
 		DidRegistry struct {
@@ -112,4 +118,6 @@ type SysUseConfig struct {
 	GenerationRule         string
 	EthClientUrl           string
 	EthClientScAddress     string
+	DidAuthClockSkew       time.Duration
+	InsecureDemoKeys       bool
 }
@@ -6,6 +6,7 @@ import (
 	"path/filepath"
 	"strings"
 	"testing"
+	"time"
 )
 
 func TestGetConfigFromFile(t *testing.T) {
@@ -20,6 +21,12 @@ func TestGetConfigFromFile(t *testing.T) {
 	if !strings.Contains(cfg.EthClientUrl, "http") {
 		t.Fatalf("unexpected eth client url: %v", cfg.EthClientUrl)
 	}
+	if cfg.DidAuthClockSkew != 30*time.Second {
+		t.Fatalf("unexpected did auth clock skew: %v", cfg.DidAuthClockSkew)
+	}
+	if cfg.InsecureDemoKeys {
+		t.Fatal("expected raw private keys to be disabled by default")
+	}
 }
 
 func TestRootDir(t *testing.T) {
@@ -54,6 +54,8 @@ func Contains(arr []string, str string) bool {
 //	return false, -1
 //}
 
+// PbKeyEncrypt encrypts plainText with RSA-OAEP to a base58 PKCS#1 RSA key and returns the base64 ciphertext.
+// It only works for RSA keys; content for a DID is encrypted to its keyAgreement keys with controller.EncryptForDIDs.
 func PbKeyEncrypt(pbKeyBase58, plainText string) string {
 	pbKey, _ := x509.ParsePKCS1PublicKey(base58.Decode(pbKeyBase58))
 
@@ -86,7 +88,10 @@ func PvKeySign(pvKeyBase58 string, pCipherMessage string, pLabel string) (bool,
 }
 
 func PbKeyVerify(pbKeyBase58 string, pCipherMessage string, pSign string) bool {
-	pbKey, _ := x509.ParsePKCS1PublicKey(base58.Decode(pbKeyBase58))
+	pbKey, err := x509.ParsePKCS1PublicKey(base58.Decode(pbKeyBase58))
+	if err != nil {
+		return false
+	}
 
 	result := rsaVerify(pCipherMessage, pSign, pbKey)
 
@@ -0,0 +1,452 @@
+package byd50_jwt
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"github.com/golang-jwt/jwt"
+	"sort"
+	"strings"
+	"time"
+)
+
+// SD-JWT VC (IETF SD-JWT / SD-JWT VC) support.
+//
+// Issuance:     <issuer-jwt>~<disclosure>~...~<disclosure>~
+// Presentation: <issuer-jwt>~<disclosure>~...~<kb-jwt>
+//
+// credentialSubject claims are placed at the top level of the issuer JWT. Claims listed as disclosable
+// (dotted paths for nested objects, e.g. "driverLicense.documentnumber") are replaced by digests in "_sd".
+const (
+	SdJwtVcType    = "vc+sd-jwt"
+	KbJwtType      = "kb+jwt"
+	SdAlgSha256    = "sha-256"
+	sdJwtSeparator = "~"
+
+	// KbJwtMaxAge bounds the age of a key binding JWT accepted by the verifier.
+	KbJwtMaxAge = 5 * time.Minute
+)
+
+// Disclosure is a decoded SD-JWT disclosure ([salt, name, value]).
+type Disclosure struct {
+	Salt    string
+	Name    string
+	Value   interface{}
+	Encoded string
+}
+
+// Digest returns the base64url encoded SHA-256 digest of the disclosure as referenced from "_sd".
+func (d Disclosure) Digest() string {
+	return sdDigest(d.Encoded)
+}
+
+// SdJwt is a parsed SD-JWT in issuance or presentation form.
+type SdJwt struct {
+	IssuerJwt   string
+	Disclosures []Disclosure
+	KbJwt       string
+}
+
+// CreateSdJwtVc issues an SD-JWT VC of type vct signed with the issuer's key.
+// holderDid is bound through the cnf claim and must sign the key binding JWT at presentation time.
+func CreateSdJwtVc(kid, vct string, credSub map[string]interface{}, disclosable []string, holderDid string,
+	standardClaims jwt.StandardClaims, pvKey crypto.PrivateKey) (string, error) {
+	if pvKey == nil {
+		return "", errors.New("private key is nil")
+	}
+	// Round trip through JSON to get a private, generic copy of the subject claims.
+	payload := map[string]interface{}{}
+	if data, err := json.Marshal(credSub); err != nil {
+		return "", err
+	} else if err := json.Unmarshal(data, &payload); err != nil || payload == nil {
+		return "", errors.New("credential subject must be a json object")
+	}
+
+	paths := append([]string(nil), disclosable...)
+	// Disclose the deepest claims first so that parent objects embed the digests of their children.
+	sort.Slice(paths, func(i, j int) bool {
+		return strings.Count(paths[i], ".") > strings.Count(paths[j], ".")
+	})
+	var disclosures []Disclosure
+	for _, path := range paths {
+		disclosure, err := makeDisclosable(payload, strings.Split(path, "."))
+		if err != nil {
+			return "", err
+		}
+		disclosures = append(disclosures, disclosure)
+	}
+
+	var std map[string]interface{}
+	data, err := json.Marshal(standardClaims)
+	if err != nil {
+		return "", err
+	}
+	if err := json.Unmarshal(data, &std); err != nil {
+		return "", err
+	}
+	for k, v := range std {
+		payload[k] = v
+	}
+	payload["vct"] = vct
+	payload["_sd_alg"] = SdAlgSha256
+	if holderDid != "" {
+		payload["cnf"] = map[string]interface{}{"kid": holderDid}
+	}
+
+	issuerJwt, err := signWithHeader(kid, "", map[string]interface{}{"typ": SdJwtVcType}, jwt.MapClaims(payload), pvKey)
+	if err != nil {
+		return "", err
+	}
+
+	sdJwt := SdJwt{IssuerJwt: issuerJwt, Disclosures: disclosures}
+	return sdJwt.String(), nil
+}
+
+// ParseSdJwt splits an SD-JWT into the issuer JWT, its disclosures and the optional key binding JWT.
+func ParseSdJwt(sdJwt string) (SdJwt, error) {
+	var out SdJwt
+	parts := strings.Split(sdJwt, sdJwtSeparator)
+	if len(parts) < 2 || parts[0] == "" {
+		return out, errors.New("invalid sd-jwt format")
+	}
+	out.IssuerJwt = parts[0]
+	out.KbJwt = parts[len(parts)-1]
+	for _, encoded := range parts[1 : len(parts)-1] {
+		disclosure, err := decodeDisclosure(encoded)
+		if err != nil {
+			return out, err
+		}
+		out.Disclosures = append(out.Disclosures, disclosure)
+	}
+	return out, nil
+}
+
+// String serializes the SD-JWT. Without a key binding JWT the result ends with "~".
+func (s SdJwt) String() string {
+	var b strings.Builder
+	b.WriteString(s.IssuerJwt)
+	b.WriteString(sdJwtSeparator)
+	for _, d := range s.Disclosures {
+		b.WriteString(d.Encoded)
+		b.WriteString(sdJwtSeparator)
+	}
+	b.WriteString(s.KbJwt)
+	return b.String()
+}
+
+// PresentSdJwt keeps only the disclosures needed for the reveal paths and appends a key binding JWT
+// signed by the holder for the given audience and nonce.
+func PresentSdJwt(sdJwt string, reveal []string, aud, nonce, holderDid string, pvKey crypto.PrivateKey) (string, error) {
+	if pvKey == nil {
+		return "", errors.New("private key is nil")
+	}
+	parsed, err := ParseSdJwt(sdJwt)
+	if err != nil {
+		return "", err
+	}
+	payload, err := unverifiedPayload(parsed.IssuerJwt)
+	if err != nil {
+		return "", err
+	}
+	paths := disclosurePaths(payload, parsed.Disclosures)
+
+	var selected []Disclosure
+	for _, d := range parsed.Disclosures {
+		path := paths[d.Digest()]
+		for _, r := range reveal {
+			// Revealing a nested claim also requires the disclosures of its parents.
+			if path != "" && (r == path || strings.HasPrefix(r, path+".")) {
+				selected = append(selected, d)
+				break
+			}
+		}
+	}
+
+	presentation := SdJwt{IssuerJwt: parsed.IssuerJwt, Disclosures: selected}
+	kbClaims := jwt.MapClaims{
+		"iat":     time.Now().Unix(),
+		"aud":     aud,
+		"nonce":   nonce,
+		"sd_hash": sdDigest(presentation.String()),
+	}
+	presentation.KbJwt, err = signWithHeader(holderDid, "", map[string]interface{}{"typ": KbJwtType}, kbClaims, pvKey)
+	if err != nil {
+		return "", err
+	}
+	return presentation.String(), nil
+}
+
+// VerifySdJwt verifies an SD-JWT presentation: the issuer signature, every disclosure digest and the
+// key binding JWT (holder signature, aud, nonce, freshness and sd_hash). aud and nonce are the values the
+// verifier expects and are required.
+// It returns the issuer JWT payload with the disclosed claims restored and the SD fields removed.
+func VerifySdJwt(presentation, aud, nonce string, getPbKey func(string, string) string) (map[string]interface{}, error) {
+	return VerifySdJwtWithKeys(presentation, aud, nonce, getPbKey, getPbKey)
+}
+
+// VerifySdJwtWithKeys is VerifySdJwt with the issuer key resolved by getIssuerKey and the
+// key binding JWT verified with the holder key resolved by getHolderKey.
+func VerifySdJwtWithKeys(presentation, aud, nonce string, getIssuerKey, getHolderKey func(string, string) string) (map[string]interface{}, error) {
+	parsed, err := ParseSdJwt(presentation)
+	if err != nil {
+		return nil, err
+	}
+	token, err := jwt.Parse(parsed.IssuerJwt, verificationKeyFunc(getIssuerKey))
+	if err != nil {
+		return nil, err
+	}
+	if typ, _ := token.Header["typ"].(string); typ != SdJwtVcType {
+		return nil, fmt.Errorf("unexpected sd-jwt typ: %v", token.Header["typ"])
+	}
+	payload := map[string]interface{}(token.Claims.(jwt.MapClaims))
+	if alg, _ := payload["_sd_alg"].(string); alg != SdAlgSha256 {
+		return nil, fmt.Errorf("unsupported _sd_alg: %v", payload["_sd_alg"])
+	}
+
+	disclosed := map[string]Disclosure{}
+	for _, d := range parsed.Disclosures {
+		if _, ok := disclosed[d.Digest()]; ok {
+			return nil, errors.New("duplicate disclosure")
+		}
+		disclosed[d.Digest()] = d
+	}
+	used := map[string]bool{}
+	claims, err := restoreClaims(payload, disclosed, used)
+	if err != nil {
+		return nil, err
+	}
+	if len(used) != len(disclosed) {
+		return nil, errors.New("disclosure digest not found in sd-jwt")
+	}
+
+	if parsed.KbJwt == "" {
+		return nil, errors.New("key binding jwt is missing")
+	}
+	cnf, _ := payload["cnf"].(map[string]interface{})
+	holderDid, _ := cnf["kid"].(string)
+	if holderDid == "" {
+		return nil, errors.New("sd-jwt has no holder binding (cnf.kid)")
+	}
+	if err := verifyKbJwt(parsed, holderDid, aud, nonce, getHolderKey); err != nil {
+		return nil, err
+	}
+
+	delete(claims, "_sd_alg")
+	return claims, nil
+}
+
+func verifyKbJwt(parsed SdJwt, holderDid, aud, nonce string, getPbKey func(string, string) string) error {
+	kbToken, err := jwt.Parse(parsed.KbJwt, verificationKeyFunc(getPbKey))
+	if err != nil {
+		return fmt.Errorf("key binding jwt invalid: %w", err)
+	}
+	if typ, _ := kbToken.Header["typ"].(string); typ != KbJwtType {
+		return fmt.Errorf("unexpected kb-jwt typ: %v", kbToken.Header["typ"])
+	}
+	if kid, _ := kbToken.Header["kid"].(string); kid != holderDid {
+		return errors.New("key binding jwt is not signed by the holder")
+	}
+	kbClaims := MapClaims(kbToken.Claims.(jwt.MapClaims))
+	// an unchecked aud or nonce would let a key binding jwt be replayed to any verifier
+	if aud == "" || nonce == "" {
+		return errors.New("expected aud and nonce of the key binding jwt are required")
+	}
+	audiences, _ := kbClaims.GetAudience()
+	if !contains(audiences, aud) {
+		return errors.New("key binding jwt aud mismatch")
+	}
+	if got, _ := kbClaims["nonce"].(string); got != nonce {
+		return errors.New("key binding jwt nonce mismatch")
+	}
+	iat, err := kbClaims.GetIssuedAt()
+	if err != nil {
+		return errors.New("key binding jwt iat is missing")
+	}
+	if time.Since(time.Unix(iat, 0)) > KbJwtMaxAge {
+		return errors.New("key binding jwt is too old")
+	}
+	presented := parsed
+	presented.KbJwt = ""
+	if got, _ := kbClaims["sd_hash"].(string); got != sdDigest(presented.String()) {
+		return errors.New("key binding jwt sd_hash mismatch")
+	}
+	return nil
+}
+
+// makeDisclosable replaces the claim at path with a digest in the "_sd" array of its parent object.
+func makeDisclosable(payload map[string]interface{}, path []string) (Disclosure, error) {
+	parent := payload
+	for _, name := range path[:len(path)-1] {
+		child, ok := parent[name].(map[string]interface{})
+		if !ok {
+			return Disclosure{}, fmt.Errorf("disclosable path %q is not an object", strings.Join(path, "."))
+		}
+		parent = child
+	}
+	name := path[len(path)-1]
+	value, ok := parent[name]
+	if !ok {
+		return Disclosure{}, fmt.Errorf("claim %q not found", strings.Join(path, "."))
+	}
+	disclosure, err := newDisclosure(name, value)
+	if err != nil {
+		return Disclosure{}, err
+	}
+	delete(parent, name)
+
+	digests, _ := parent["_sd"].([]interface{})
+	digests = append(digests, disclosure.Digest())
+	// Sorting hides the original claim order.
+	sort.Slice(digests, func(i, j int) bool { return digests[i].(string) < digests[j].(string) })
+	parent["_sd"] = digests
+	return disclosure, nil
+}
+
+func newDisclosure(name string, value interface{}) (Disclosure, error) {
+	salt := make([]byte, 16)
+	if _, err := rand.Read(salt); err != nil {
+		return Disclosure{}, err
+	}
+	d := Disclosure{Salt: base64.RawURLEncoding.EncodeToString(salt), Name: name, Value: value}
+	data, err := json.Marshal([]interface{}{d.Salt, d.Name, d.Value})
+	if err != nil {
+		return Disclosure{}, err
+	}
+	d.Encoded = base64.RawURLEncoding.EncodeToString(data)
+	return d, nil
+}
+
+func decodeDisclosure(encoded string) (Disclosure, error) {
+	data, err := base64.RawURLEncoding.DecodeString(encoded)
+	if err != nil {
+		return Disclosure{}, fmt.Errorf("invalid disclosure encoding: %w", err)
+	}
+	var arr []interface{}
+	if err := json.Unmarshal(data, &arr); err != nil || len(arr) != 3 {
+		return Disclosure{}, errors.New("disclosure must be a [salt, name, value] array")
+	}
+	salt, ok1 := arr[0].(string)
+	name, ok2 := arr[1].(string)
+	if !ok1 || !ok2 {
+		return Disclosure{}, errors.New("disclosure salt and name must be strings")
+	}
+	if name == "_sd" || name == "..." {
+		return Disclosure{}, errors.New("disclosure uses a reserved claim name")
+	}
+	return Disclosure{Salt: salt, Name: name, Value: arr[2], Encoded: encoded}, nil
+}
+
+// restoreClaims rebuilds an object, replacing "_sd" digests with the disclosed claims.
+func restoreClaims(obj map[string]interface{}, disclosed map[string]Disclosure, used map[string]bool) (map[string]interface{}, error) {
+	out := map[string]interface{}{}
+	for k, v := range obj {
+		if k == "_sd" {
+			continue
+		}
+		restored, err := restoreValue(v, disclosed, used)
+		if err != nil {
+			return nil, err
+		}
+		out[k] = restored
+	}
+	digests, _ := obj["_sd"].([]interface{})
+	for _, raw := range digests {
+		digest, ok := raw.(string)
+		if !ok {
+			return nil, errors.New("_sd entries must be strings")
+		}
+		d, ok := disclosed[digest]
+		if !ok {
+			continue
+		}
+		if used[digest] {
+			return nil, errors.New("disclosure digest referenced twice")
+		}
+		used[digest] = true
+		if _, exists := out[d.Name]; exists {
+			return nil, fmt.Errorf("disclosed claim %q overwrites an existing claim", d.Name)
+		}
+		restored, err := restoreValue(d.Value, disclosed, used)
+		if err != nil {
+			return nil, err
+		}
+		out[d.Name] = restored
+	}
+	return out, nil
+}
+
+func restoreValue(v interface{}, disclosed map[string]Disclosure, used map[string]bool) (interface{}, error) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		return restoreClaims(value, disclosed, used)
+	case []interface{}:
+		out := make([]interface{}, 0, len(value))
+		for _, item := range value {
+			restored, err := restoreValue(item, disclosed, used)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, restored)
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
+
+// disclosurePaths maps each disclosure digest to the dotted claim path it discloses.
+func disclosurePaths(payload map[string]interface{}, disclosures []Disclosure) map[string]string {
+	byDigest := map[string]Disclosure{}
+	for _, d := range disclosures {
+		byDigest[d.Digest()] = d
+	}
+	paths := map[string]string{}
+	var walk func(obj map[string]interface{}, prefix string)
+	walk = func(obj map[string]interface{}, prefix string) {
+		digests, _ := obj["_sd"].([]interface{})
+		for _, raw := range digests {
+			digest, _ := raw.(string)
+			d, ok := byDigest[digest]
+			if !ok {
+				continue
+			}
+			paths[digest] = prefix + d.Name
+			if child, ok := d.Value.(map[string]interface{}); ok {
+				walk(child, prefix+d.Name+".")
+			}
+		}
+		for k, v := range obj {
+			if child, ok := v.(map[string]interface{}); ok {
+				walk(child, prefix+k+".")
+			}
+		}
+	}
+	walk(payload, "")
+	return paths
+}
+
+func unverifiedPayload(issuerJwt string) (map[string]interface{}, error) {
+	token, _, err := new(jwt.Parser).ParseUnverified(issuerJwt, jwt.MapClaims{})
+	if err != nil {
+		return nil, err
+	}
+	return token.Claims.(jwt.MapClaims), nil
+}
+
+func sdDigest(s string) string {
+	digest := sha256.Sum256([]byte(s))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+func contains(arr []string, s string) bool {
+	for _, a := range arr {
+		if a == s {
+			return true
+		}
+	}
+	return false
+}
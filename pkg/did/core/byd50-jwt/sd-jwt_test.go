@@ -0,0 +1,166 @@
+package byd50_jwt
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/golang-jwt/jwt"
+)
+
+func sdJwtFixture(t *testing.T) (string, *ecdsa.PrivateKey, func(string, string) string) {
+	t.Helper()
+	issuerKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	holderKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[string]*ecdsa.PublicKey{
+		"did:byd50:issuer": &issuerKey.PublicKey,
+		"did:byd50:holder": &holderKey.PublicKey,
+	}
+	getPbKey := func(did string, _ string) string {
+		pbKey, ok := keys[did]
+		if !ok {
+			return ""
+		}
+		pbBytes, _ := x509.MarshalPKIXPublicKey(pbKey)
+		return base58.Encode(pbBytes)
+	}
+
+	credSub := map[string]interface{}{
+		"identityinfo": map[string]interface{}{
+			"name":  "Hong Gil-Dong",
+			"birth": "2000-11-08",
+		},
+		"driverLicense": map[string]interface{}{
+			"documentnumber":        "15-03-142857-74",
+			"certificateprivileges": "1-normal",
+		},
+	}
+	sdJwt, err := CreateSdJwtVc("did:byd50:issuer", "DriverLicenseCredential", credSub,
+		[]string{"identityinfo", "identityinfo.name", "identityinfo.birth", "driverLicense.documentnumber"},
+		"did:byd50:holder",
+		jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(time.Minute).Unix(),
+			IssuedAt:  time.Now().Unix(),
+			Issuer:    "did:byd50:issuer",
+		}, issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return sdJwt, holderKey, getPbKey
+}
+
+func TestSdJwtSelectiveDisclosure(t *testing.T) {
+	sdJwt, holderKey, getPbKey := sdJwtFixture(t)
+
+	parsed, err := ParseSdJwt(sdJwt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(parsed.Disclosures) != 4 || parsed.KbJwt != "" || !strings.HasSuffix(sdJwt, "~") {
+		t.Fatalf("unexpected issuance form: %+v", parsed)
+	}
+	payload, err := unverifiedPayload(parsed.IssuerJwt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := payload["identityinfo"]; ok {
+		t.Fatal("disclosable claim must not be in clear text")
+	}
+	if payload["driverLicense"].(map[string]interface{})["certificateprivileges"] != "1-normal" {
+		t.Fatal("non disclosable claim must stay in clear text")
+	}
+
+	presentation, err := PresentSdJwt(sdJwt, []string{"identityinfo.name"}, "did:byd50:rental", "n-1",
+		"did:byd50:holder", holderKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	claims, err := VerifySdJwt(presentation, "did:byd50:rental", "n-1", getPbKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	identity := claims["identityinfo"].(map[string]interface{})
+	if identity["name"] != "Hong Gil-Dong" {
+		t.Fatalf("name not disclosed: %v", claims)
+	}
+	if _, ok := identity["birth"]; ok {
+		t.Fatal("birth must not be disclosed")
+	}
+	license := claims["driverLicense"].(map[string]interface{})
+	if _, ok := license["documentnumber"]; ok {
+		t.Fatal("documentnumber must not be disclosed")
+	}
+	if _, ok := claims["_sd_alg"]; ok {
+		t.Fatal("_sd_alg must be removed")
+	}
+	if claims["vct"] != "DriverLicenseCredential" {
+		t.Fatalf("unexpected vct: %v", claims["vct"])
+	}
+}
+
+func TestSdJwtVerifyRejects(t *testing.T) {
+	sdJwt, holderKey, getPbKey := sdJwtFixture(t)
+	presentation, err := PresentSdJwt(sdJwt, []string{"driverLicense.documentnumber"}, "did:byd50:rental", "n-1",
+		"did:byd50:holder", holderKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := VerifySdJwt(presentation, "did:byd50:other", "n-1", getPbKey); err == nil {
+		t.Fatal("expected aud mismatch")
+	}
+	if _, err := VerifySdJwt(presentation, "did:byd50:rental", "n-2", getPbKey); err == nil {
+		t.Fatal("expected nonce mismatch")
+	}
+	// the expected aud and nonce can not be skipped
+	if _, err := VerifySdJwt(presentation, "", "n-1", getPbKey); err == nil {
+		t.Fatal("expected an empty expected aud to be rejected")
+	}
+	if _, err := VerifySdJwt(presentation, "did:byd50:rental", "", getPbKey); err == nil {
+		t.Fatal("expected an empty expected nonce to be rejected")
+	}
+
+	// A disclosure that was not issued can not be injected.
+	parsed, _ := ParseSdJwt(presentation)
+	forged, err := newDisclosure("documentnumber", "00-00-000000-00")
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed.Disclosures = append(parsed.Disclosures, forged)
+	if _, err := VerifySdJwt(parsed.String(), "did:byd50:rental", "n-1", getPbKey); err == nil {
+		t.Fatal("expected unknown disclosure to fail")
+	}
+
+	// Dropping a disclosure after signing breaks the key binding sd_hash.
+	parsed, _ = ParseSdJwt(presentation)
+	parsed.Disclosures = nil
+	if _, err := VerifySdJwt(parsed.String(), "did:byd50:rental", "n-1", getPbKey); err == nil {
+		t.Fatal("expected sd_hash mismatch")
+	}
+
+	// Without key binding the presentation is rejected.
+	if _, err := VerifySdJwt(sdJwt, "did:byd50:rental", "n-1", getPbKey); err == nil {
+		t.Fatal("expected missing key binding jwt to fail")
+	}
+
+	// The key binding JWT must be signed by the holder bound in cnf.
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	stolen, err := PresentSdJwt(sdJwt, nil, "did:byd50:rental", "n-1", "did:byd50:holder", otherKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := VerifySdJwt(stolen, "did:byd50:rental", "n-1", getPbKey); err == nil {
+		t.Fatal("expected key binding signature to fail")
+	}
+}
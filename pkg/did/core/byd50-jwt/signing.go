@@ -0,0 +1,306 @@
+package byd50_jwt
+
+import (
+	"byd50-ssi/pkg/keys"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+	"github.com/golang-jwt/jwt"
+	"math/big"
+	"strings"
+)
+
+// JWS algorithms supported for VC/VP signing. The algorithm is never chosen by the caller:
+// it follows from the signing key type (and the verification method type when one is given).
+const (
+	AlgES256  = "ES256"  // ECDSA P-256
+	AlgES384  = "ES384"  // ECDSA P-384
+	AlgES256K = "ES256K" // ECDSA secp256k1 (RFC 8812), e.g. keys of eth DIDs
+	AlgEdDSA  = "EdDSA"  // Ed25519
+	AlgPS256  = "PS256"  // RSASSA-PSS with SHA-256
+)
+
+var allAlgs = []string{AlgES256, AlgES384, AlgES256K, AlgEdDSA, AlgPS256}
+
+// algsByVerificationMethodType lists the algorithms a DID document verification method type may be used with.
+// JsonWebKey2020 and Multikey carry the key type in the key material itself.
+var algsByVerificationMethodType = map[string][]string{
+	"EcdsaSecp256r1VerificationKey2019": {AlgES256},
+	"EcdsaSecp256k1VerificationKey2019": {AlgES256K},
+	"Ed25519VerificationKey2018":        {AlgEdDSA},
+	"Ed25519VerificationKey2020":        {AlgEdDSA},
+	"RsaVerificationKey2018":            {AlgPS256},
+	"JsonWebKey2020":                    allAlgs,
+	"Multikey":                          allAlgs,
+}
+
+// SigningMethodES256K implements ES256K with the secp256k1 implementation of go-ethereum.
+// Signatures are the 64 byte R || S form without the recovery id.
+var SigningMethodES256K = &signingMethodES256K{}
+
+type signingMethodES256K struct{}
+
+func init() {
+	jwt.RegisterSigningMethod(AlgES256K, func() jwt.SigningMethod {
+		return SigningMethodES256K
+	})
+}
+
+func (m *signingMethodES256K) Alg() string {
+	return AlgES256K
+}
+
+func (m *signingMethodES256K) Sign(signingString string, key interface{}) (string, error) {
+	pvKey, ok := key.(*ecdsa.PrivateKey)
+	if !ok || !keys.IsSecp256k1(pvKey.Curve) {
+		return "", jwt.ErrInvalidKeyType
+	}
+	digest := sha256.Sum256([]byte(signingString))
+	sig, err := ethcrypto.Sign(digest[:], pvKey)
+	if err != nil {
+		return "", err
+	}
+	return jwt.EncodeSegment(sig[:64]), nil
+}
+
+func (m *signingMethodES256K) Verify(signingString, signature string, key interface{}) error {
+	pbKey, ok := key.(*ecdsa.PublicKey)
+	if !ok || !keys.IsSecp256k1(pbKey.Curve) {
+		return jwt.ErrInvalidKeyType
+	}
+	sig, err := jwt.DecodeSegment(signature)
+	if err != nil {
+		return err
+	}
+	digest := sha256.Sum256([]byte(signingString))
+	if len(sig) != 64 || !ethcrypto.VerifySignature(ethcrypto.CompressPubkey(pbKey), digest[:], sig) {
+		return jwt.ErrECDSAVerification
+	}
+	return nil
+}
+
+// AlgForPublicKey returns the JWS algorithm used with keys of the given type.
+func AlgForPublicKey(pbKey crypto.PublicKey) (string, error) {
+	switch key := pbKey.(type) {
+	case *ecdsa.PublicKey:
+		switch {
+		case keys.IsSecp256k1(key.Curve):
+			return AlgES256K, nil
+		case key.Curve == elliptic.P256():
+			return AlgES256, nil
+		case key.Curve == elliptic.P384():
+			return AlgES384, nil
+		}
+		return "", errors.New("unsupported ecdsa curve")
+	case ed25519.PublicKey:
+		return AlgEdDSA, nil
+	case *rsa.PublicKey:
+		return AlgPS256, nil
+	}
+	return "", fmt.Errorf("unsupported public key type %T", pbKey)
+}
+
+// SigningMethodFor picks the signing method for pvKey. When vmType (the type of the
+// verification method the key is published under) is not empty, the algorithm must be allowed for it.
+func SigningMethodFor(vmType string, pvKey crypto.PrivateKey) (jwt.SigningMethod, error) {
+	signer, ok := pvKey.(crypto.Signer)
+	if !ok || signer == nil {
+		return nil, errors.New("private key is not a signer")
+	}
+	alg, err := AlgForPublicKey(signer.Public())
+	if err != nil {
+		return nil, err
+	}
+	if err := checkVerificationMethodAlg(vmType, alg); err != nil {
+		return nil, err
+	}
+	return jwt.GetSigningMethod(alg), nil
+}
+
+// CheckVerificationMethodKey reports whether pbKey may be published under a verification method of vmType.
+func CheckVerificationMethodKey(vmType string, pbKey crypto.PublicKey) error {
+	alg, err := AlgForPublicKey(pbKey)
+	if err != nil {
+		return err
+	}
+	return checkVerificationMethodAlg(vmType, alg)
+}
+
+func checkVerificationMethodAlg(vmType, alg string) error {
+	if vmType == "" {
+		return nil
+	}
+	algs, ok := algsByVerificationMethodType[vmType]
+	if !ok {
+		return fmt.Errorf("unsupported verification method type: %s", vmType)
+	}
+	if !contains(algs, alg) {
+		return fmt.Errorf("%s key cannot be used with verification method type %s", alg, vmType)
+	}
+	return nil
+}
+
+// Sign signs claims as a JWS whose alg follows from pvKey and vmType, with kid in the header.
+func Sign(kid, vmType string, claims jwt.Claims, pvKey crypto.PrivateKey) (string, error) {
+	return signWithHeader(kid, vmType, nil, claims, pvKey)
+}
+
+func signWithHeader(kid, vmType string, header map[string]interface{}, claims jwt.Claims, pvKey crypto.PrivateKey) (string, error) {
+	method, err := SigningMethodFor(vmType, pvKey)
+	if err != nil {
+		return "", err
+	}
+	token := jwt.NewWithClaims(method, claims)
+	token.Header["kid"] = kid
+	for k, v := range header {
+		token.Header[k] = v
+	}
+	return SignToken(token, pvKey)
+}
+
+// SignToken signs token with pvKey. Besides the private keys of the crypto packages, pvKey may be any
+// crypto.Signer, such as a key held by a KMS or an HSM that never exposes the private key.
+func SignToken(token *jwt.Token, pvKey crypto.PrivateKey) (string, error) {
+	switch pvKey.(type) {
+	case *ecdsa.PrivateKey, ed25519.PrivateKey, *rsa.PrivateKey:
+		return token.SignedString(pvKey)
+	}
+	signer, ok := pvKey.(crypto.Signer)
+	if !ok || signer == nil {
+		return "", jwt.ErrInvalidKeyType
+	}
+	signingString, err := token.SigningString()
+	if err != nil {
+		return "", err
+	}
+	signature, err := signWithSigner(token.Method.Alg(), signingString, signer)
+	if err != nil {
+		return "", err
+	}
+	return signingString + "." + jwt.EncodeSegment(signature), nil
+}
+
+// signWithSigner creates the JWS signature of alg over signingString with signer. ECDSA signers return
+// ASN.1 signatures, which JWS encodes as the fixed size R || S.
+func signWithSigner(alg, signingString string, signer crypto.Signer) ([]byte, error) {
+	switch alg {
+	case AlgEdDSA:
+		return signer.Sign(rand.Reader, []byte(signingString), crypto.Hash(0))
+	case AlgPS256:
+		digest := sha256.Sum256([]byte(signingString))
+		return signer.Sign(rand.Reader, digest[:], &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256})
+	case AlgES256, AlgES256K, AlgES384:
+		hash, size := crypto.SHA256, 32
+		if alg == AlgES384 {
+			hash, size = crypto.SHA384, 48
+		}
+		h := hash.New()
+		h.Write([]byte(signingString))
+		der, err := signer.Sign(rand.Reader, h.Sum(nil), hash)
+		if err != nil {
+			return nil, err
+		}
+		var sig struct{ R, S *big.Int }
+		if rest, err := asn1.Unmarshal(der, &sig); err != nil || len(rest) != 0 {
+			return nil, errors.New("signer returned an invalid ecdsa signature")
+		}
+		if alg == AlgES256K {
+			// secp256k1 verifiers only accept the low-S form.
+			if n := ethcrypto.S256().Params().N; sig.S.Cmp(new(big.Int).Rsh(n, 1)) > 0 {
+				sig.S.Sub(n, sig.S)
+			}
+		}
+		out := make([]byte, 2*size)
+		sig.R.FillBytes(out[:size])
+		sig.S.FillBytes(out[size:])
+		return out, nil
+	}
+	return nil, fmt.Errorf("unsupported signing method %s", alg)
+}
+
+// verificationKeyFunc resolves the key of the kid header with getPbKey. The token is only accepted when
+// its alg is the one that belongs to the resolved key type, so a verifier never trusts the header alg alone.
+func verificationKeyFunc(getPbKey func(string, string) string) jwt.Keyfunc {
+	return func(token *jwt.Token) (interface{}, error) {
+		alg, _ := token.Header["alg"].(string)
+		if !contains(allAlgs, alg) {
+			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
+		}
+		kid, ok := token.Header["kid"].(string)
+		if !ok || kid == "" {
+			return nil, errors.New("missing kid header")
+		}
+		// a kid that is a DID URL selects that verification method of the DID, a bare DID its first key
+		did, keyId := kid, ""
+		if i := strings.Index(kid, "#"); i >= 0 {
+			did, keyId = kid[:i], kid
+		}
+		pbKey, err := keys.ParsePublicKeyBase58(getPbKey(did, keyId))
+		if err != nil {
+			return nil, err
+		}
+		keyAlg, err := AlgForPublicKey(pbKey)
+		if err != nil {
+			return nil, err
+		}
+		if keyAlg != alg {
+			return nil, fmt.Errorf("signing method %s does not match %s key of %s", alg, keyAlg, did)
+		}
+		return pbKey, nil
+	}
+}
+
+// ParseSigned verifies a JWS created with Sign, resolving the key of its kid with getPbKey,
+// and returns its claims. exp, iat and nbf are validated when present.
+func ParseSigned(tokenString string, getPbKey func(string, string) string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, verificationKeyFunc(getPbKey))
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
+
+// SignTyped is Sign with a typ header, for JWTs of an explicit type such as request objects or proofs.
+func SignTyped(kid, typ string, claims jwt.Claims, pvKey crypto.PrivateKey) (string, error) {
+	return signWithHeader(kid, "", map[string]interface{}{"typ": typ}, claims, pvKey)
+}
+
+// ParseTyped is ParseSigned for a JWS whose typ header must be typ.
+func ParseTyped(tokenString, typ string, getPbKey func(string, string) string) (jwt.MapClaims, error) {
+	return parseTyped(new(jwt.Parser), tokenString, typ, getPbKey)
+}
+
+// VerifyTypedSignature is ParseTyped without the exp, iat and nbf checks, for callers that validate them
+// with their own clock skew tolerance.
+func VerifyTypedSignature(tokenString, typ string, getPbKey func(string, string) string) (jwt.MapClaims, error) {
+	return parseTyped(&jwt.Parser{SkipClaimsValidation: true}, tokenString, typ, getPbKey)
+}
+
+func parseTyped(parser *jwt.Parser, tokenString, typ string, getPbKey func(string, string) string) (jwt.MapClaims, error) {
+	token, err := parser.Parse(tokenString, func(token *jwt.Token) (interface{}, error) {
+		if got, _ := token.Header["typ"].(string); got != typ {
+			return nil, fmt.Errorf("unexpected typ: %v", token.Header["typ"])
+		}
+		return verificationKeyFunc(getPbKey)(token)
+	})
+	if err != nil {
+		return nil, err
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok || !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	return claims, nil
+}
@@ -0,0 +1,192 @@
+package byd50_jwt
+
+import (
+	"byd50-ssi/pkg/keys"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+type testKey struct {
+	name   string
+	pvKey  crypto.Signer
+	alg    string
+	vmType string
+}
+
+func testKeys(t *testing.T) []testKey {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k1, _, err := keys.GenerateSecp256k1KeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed, _, err := keys.GenerateEd25519KeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return []testKey{
+		{"p256", p256, AlgES256, "EcdsaSecp256r1VerificationKey2019"},
+		{"p384", p384, AlgES384, "JsonWebKey2020"},
+		{"secp256k1", k1, AlgES256K, "EcdsaSecp256k1VerificationKey2019"},
+		{"ed25519", ed, AlgEdDSA, "Ed25519VerificationKey2018"},
+		{"rsa", rsaKey, AlgPS256, "RsaVerificationKey2018"},
+	}
+}
+
+func testVcClaims() VcClaims {
+	return VcClaims{
+		Vc: map[string]interface{}{"type": []string{"VerifiableCredential"}},
+		StandardClaims: jwt.StandardClaims{
+			ExpiresAt: time.Now().Add(time.Minute).Unix(),
+			IssuedAt:  time.Now().Unix(),
+			Issuer:    "did:byd50:issuer",
+		},
+	}
+}
+
+func TestMultiAlgSigning(t *testing.T) {
+	for _, tc := range testKeys(t) {
+		t.Run(tc.name, func(t *testing.T) {
+			pbKeyBase58 := keys.ExportPublicKeyAsBase58(tc.pvKey.Public())
+			getPbKey := func(_ string, _ string) string {
+				return pbKeyBase58
+			}
+
+			vcJwt := CreateVc("did:byd50:issuer", testVcClaims(), tc.pvKey)
+			if vcJwt == "" {
+				t.Fatal("vc jwt empty")
+			}
+			token, _, err := new(jwt.Parser).ParseUnverified(vcJwt, jwt.MapClaims{})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if token.Header["alg"] != tc.alg {
+				t.Fatalf("unexpected alg %v, want %s", token.Header["alg"], tc.alg)
+			}
+			if ok, err := VerifyVc(vcJwt, getPbKey); !ok || err != nil {
+				t.Fatalf("vc verify failed: %v", err)
+			}
+
+			vpJwt := CreateVp("did:byd50:holder", VpClaims{
+				Vp:             map[string]interface{}{"verifiableCredential": []string{vcJwt}},
+				StandardClaims: testVcClaims().StandardClaims,
+			}, tc.pvKey)
+			if ok, did, err := VerifyVp(vpJwt, getPbKey); !ok || err != nil || did != "did:byd50:holder" {
+				t.Fatalf("vp verify failed: %v", err)
+			}
+
+			if _, err := SigningMethodFor(tc.vmType, tc.pvKey); err != nil {
+				t.Fatalf("key rejected for %s: %v", tc.vmType, err)
+			}
+			if err := CheckVerificationMethodKey(tc.vmType, tc.pvKey.Public()); err != nil {
+				t.Fatal(err)
+			}
+
+			// The signature must not verify against a tampered payload.
+			parts := strings.Split(vcJwt, ".")
+			tampered := parts[0] + "." + jwt.EncodeSegment([]byte(`{"iss":"did:byd50:other"}`)) + "." + parts[2]
+			if ok, err := VerifyVc(tampered, getPbKey); ok || err == nil {
+				t.Fatal("expected tampered vc to fail")
+			}
+		})
+	}
+}
+
+func TestSigningAlgorithmMismatch(t *testing.T) {
+	all := testKeys(t)
+	p256, k1, ed := all[0], all[2], all[3]
+
+	// A verifier resolving an Ed25519 key rejects an ES256 token even before checking the signature.
+	vcJwt := CreateVc("did:byd50:issuer", testVcClaims(), p256.pvKey)
+	edKeyBase58 := keys.ExportPublicKeyAsBase58(ed.pvKey.Public())
+	if ok, err := VerifyVc(vcJwt, func(string, string) string { return edKeyBase58 }); ok || err == nil {
+		t.Fatal("expected alg/key type mismatch")
+	}
+
+	// ES256 and ES256K keys are both ECDSA; the curve decides the algorithm.
+	k1KeyBase58 := keys.ExportPublicKeyAsBase58(k1.pvKey.Public())
+	if ok, err := VerifyVc(vcJwt, func(string, string) string { return k1KeyBase58 }); ok || err == nil {
+		t.Fatal("expected ES256 token to be rejected for a secp256k1 key")
+	}
+
+	if _, err := SigningMethodFor("Ed25519VerificationKey2018", p256.pvKey); err == nil {
+		t.Fatal("expected verification method type mismatch")
+	}
+	if _, err := SigningMethodFor("UnknownKey2099", p256.pvKey); err == nil {
+		t.Fatal("expected unsupported verification method type")
+	}
+	if _, err := SigningMethodFor("", "not a key"); err == nil {
+		t.Fatal("expected invalid private key error")
+	}
+}
+
+func TestVerifyVpWithKeys(t *testing.T) {
+	all := testKeys(t)
+	issuer, holder := all[0], all[3]
+	issuerKey := keys.ExportPublicKeyAsBase58(issuer.pvKey.Public())
+	holderKey := keys.ExportPublicKeyAsBase58(holder.pvKey.Public())
+	getKey := func(pbKeyBase58 string) func(string, string) string {
+		return func(string, string) string { return pbKeyBase58 }
+	}
+
+	vcJwt := CreateVc("did:byd50:issuer", testVcClaims(), issuer.pvKey)
+	vpJwt := CreateVp("did:byd50:holder", VpClaims{
+		Vp:             map[string]interface{}{"verifiableCredential": []string{vcJwt}},
+		StandardClaims: testVcClaims().StandardClaims,
+	}, holder.pvKey)
+
+	if ok, _, err := VerifyVpWithKeys(vpJwt, getKey(holderKey), getKey(issuerKey)); !ok || err != nil {
+		t.Fatalf("vp verify failed: %v", err)
+	}
+	// The embedded VC must be checked with the assertion key resolver, not the holder's.
+	if ok, _, err := VerifyVpWithKeys(vpJwt, getKey(holderKey), getKey(holderKey)); ok && err == nil {
+		t.Fatal("expected vc verification with the authentication key to fail")
+	}
+}
+
+// opaqueSigner hides the private key behind crypto.Signer, as a KMS or HSM key handle does.
+type opaqueSigner struct{ signer crypto.Signer }
+
+func (s opaqueSigner) Public() crypto.PublicKey { return s.signer.Public() }
+
+func (s opaqueSigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return s.signer.Sign(rand, digest, opts)
+}
+
+func TestOpaqueSignerSigning(t *testing.T) {
+	for _, tc := range testKeys(t) {
+		t.Run(tc.name, func(t *testing.T) {
+			pbKeyBase58 := keys.ExportPublicKeyAsBase58(tc.pvKey.Public())
+			signed, err := Sign("did:byd50:issuer", tc.vmType, jwt.MapClaims{"iss": "did:byd50:issuer"}, opaqueSigner{tc.pvKey})
+			if err != nil {
+				t.Fatal(err)
+			}
+			claims, err := ParseSigned(signed, func(string, string) string { return pbKeyBase58 })
+			if err != nil {
+				t.Fatalf("signature of opaque signer does not verify: %v", err)
+			}
+			if claims["iss"] != "did:byd50:issuer" {
+				t.Fatalf("unexpected claims %v", claims)
+			}
+		})
+	}
+}
@@ -1,11 +1,7 @@
 package byd50_jwt
 
 import (
-	"crypto/ecdsa"
-	"crypto/x509"
-	"errors"
-	"fmt"
-	"github.com/btcsuite/btcutil/base58"
+	"crypto"
 	"github.com/golang-jwt/jwt"
 	"log"
 	"time"
@@ -34,7 +30,7 @@ type VcClaims struct {
 	jwt.StandardClaims
 }
 
-func MakeVcSample(kid string, pvKey *ecdsa.PrivateKey) string {
+func MakeVcSample(kid string, pvKey crypto.PrivateKey) string {
 	typ := []string{"VerifiableCredential", "AlumniCredential"}
 	credSub := map[string]interface{}{
 		"degree": "BachelorDegree",
@@ -78,11 +74,9 @@ func MakeVcSample(kid string, pvKey *ecdsa.PrivateKey) string {
 	return vcSampleJwt
 }
 
-func CreateVc(kid string, claims VcClaims, pvKey *ecdsa.PrivateKey) string {
-	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
-	token.Header["kid"] = kid
-
-	ss, err := token.SignedString(pvKey)
+// CreateVc signs the claims with pvKey. The JWS alg follows from the key type (see SigningMethodFor).
+func CreateVc(kid string, claims VcClaims, pvKey crypto.PrivateKey) string {
+	ss, err := Sign(kid, "", claims, pvKey)
 	if err != nil {
 		log.Printf(err.Error())
 	}
@@ -90,25 +84,15 @@ func CreateVc(kid string, claims VcClaims, pvKey *ecdsa.PrivateKey) string {
 }
 
 func VerifyVc(vcJwt string, getPbKey func(string, string) string) (bool, error) {
-	parseToken, err := jwt.Parse(vcJwt, func(token *jwt.Token) (interface{}, error) {
-		// Don't forget to validate the alg is what you expect:
-		if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		did, ok := token.Header["kid"].(string)
-		if !ok || did == "" {
-			return nil, errors.New("missing kid header")
-		}
-		pbKeyBase58 := getPbKey(did, "")
-		pbKeyBytes := base58.Decode(pbKeyBase58)
-		if len(pbKeyBytes) == 0 {
-			return nil, errors.New("invalid public key base58")
-		}
-		pbKey, err := x509.ParsePKIXPublicKey(pbKeyBytes)
-		if err != nil {
-			return nil, err
-		}
-		return pbKey, nil
-	})
+	parseToken, err := jwt.Parse(vcJwt, verificationKeyFunc(getPbKey))
 	return parseToken.Valid, err
 }
+
+// ParseVc verifies the VC JWT and returns its claims.
+func ParseVc(vcJwt string, getPbKey func(string, string) string) (bool, jwt.MapClaims, error) {
+	parseToken, err := jwt.Parse(vcJwt, verificationKeyFunc(getPbKey))
+	if claims, ok := parseToken.Claims.(jwt.MapClaims); err == nil && ok && parseToken.Valid {
+		return parseToken.Valid, claims, err
+	}
+	return false, nil, err
+}
@@ -1,12 +1,9 @@
 package byd50_jwt
 
 import (
-	"crypto/ecdsa"
-	"crypto/x509"
+	"crypto"
 	"encoding/json"
 	"errors"
-	"fmt"
-	"github.com/btcsuite/btcutil/base58"
 	"github.com/golang-jwt/jwt"
 	"log"
 	"time"
@@ -28,7 +25,7 @@ type VpClaims struct {
 	jwt.StandardClaims
 }
 
-func MakeVpSample(issuerDid string, vcJwtArray []string, pvKey *ecdsa.PrivateKey) string {
+func MakeVpSample(issuerDid string, vcJwtArray []string, pvKey crypto.PrivateKey) string {
 	typ := []string{"VerifiableCredential", "AlumniCredential"}
 	myVp := map[string]interface{}{
 		"@context": []string{
@@ -68,11 +65,9 @@ func MakeVpSample(issuerDid string, vcJwtArray []string, pvKey *ecdsa.PrivateKey
 	return vpSampleJwt
 }
 
-func CreateVp(kid string, claims VpClaims, pvKey *ecdsa.PrivateKey) string {
-	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
-	token.Header["kid"] = kid
-
-	ss, err := token.SignedString(pvKey)
+// CreateVp signs the claims with pvKey. The JWS alg follows from the key type (see SigningMethodFor).
+func CreateVp(kid string, claims VpClaims, pvKey crypto.PrivateKey) string {
+	ss, err := Sign(kid, "", claims, pvKey)
 	if err != nil {
 		log.Printf(err.Error())
 	}
@@ -80,28 +75,18 @@ func CreateVp(kid string, claims VpClaims, pvKey *ecdsa.PrivateKey) string {
 }
 
 func VerifyVp(vpJwt string, getPbKey func(string, string) string) (bool, string, error) {
+	return VerifyVpWithKeys(vpJwt, getPbKey, getPbKey)
+}
+
+// VerifyVpWithKeys verifies the holder signature with getAuthKey and every embedded VC with getAssertionKey,
+// so that each signature is checked against the verification relationship it was made for.
+func VerifyVpWithKeys(vpJwt string, getAuthKey, getAssertionKey func(string, string) string) (bool, string, error) {
 	valid := false
 	did := ""
+	keyFunc := verificationKeyFunc(getAuthKey)
 	parseToken, err := jwt.Parse(vpJwt, func(token *jwt.Token) (interface{}, error) {
-		// Don't forget to validate the alg is what you expect:
-		if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		kid, ok := token.Header["kid"].(string)
-		if !ok || kid == "" {
-			return nil, errors.New("missing kid header")
-		}
-		did = kid
-		pbKeyBase58 := getPbKey(did, "")
-		pbKeyBytes := base58.Decode(pbKeyBase58)
-		if len(pbKeyBytes) == 0 {
-			return nil, errors.New("invalid public key base58")
-		}
-		pbKey, err := x509.ParsePKIXPublicKey(pbKeyBytes)
-		if err != nil {
-			return nil, err
-		}
-		return pbKey, nil
+		did, _ = token.Header["kid"].(string)
+		return keyFunc(token)
 	})
 	if err != nil {
 		return valid, did, err
@@ -129,7 +114,7 @@ func VerifyVp(vpJwt string, getPbKey func(string, string) string) (bool, string,
 
 			log.Printf("Verify each elements.")
 			for index, element := range vcJwtArray {
-				ok, err = VerifyVc(element, getPbKey)
+				ok, err = VerifyVc(element, getAssertionKey)
 				if err != nil {
 					valid = false
 					log.Printf(err.Error())
@@ -148,26 +133,7 @@ func VerifyVp(vpJwt string, getPbKey func(string, string) string) (bool, string,
 }
 
 func ParseVp(vpJwt string, getPbKey func(string, string) string) (bool, jwt.MapClaims, error) {
-	parseToken, err := jwt.Parse(vpJwt, func(token *jwt.Token) (interface{}, error) {
-		// Don't forget to validate the alg is what you expect:
-		if _, ok := token.Method.(*jwt.SigningMethodECDSA); !ok {
-			return nil, fmt.Errorf("unexpected signing method: %v", token.Header["alg"])
-		}
-		did, ok := token.Header["kid"].(string)
-		if !ok || did == "" {
-			return nil, errors.New("missing kid header")
-		}
-		pbKeyBase58 := getPbKey(did, "")
-		pbKeyBytes := base58.Decode(pbKeyBase58)
-		if len(pbKeyBytes) == 0 {
-			return nil, errors.New("invalid public key base58")
-		}
-		pbKey, err := x509.ParsePKIXPublicKey(pbKeyBytes)
-		if err != nil {
-			return nil, err
-		}
-		return pbKey, nil
-	})
+	parseToken, err := jwt.Parse(vpJwt, verificationKeyFunc(getPbKey))
 	if claims, ok := parseToken.Claims.(jwt.MapClaims); err == nil && ok && parseToken.Valid {
 		return parseToken.Valid, claims, err
 	}
@@ -6,6 +6,7 @@ import (
 	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
 	"byd50-ssi/pkg/did/core/dids"
 	"byd50-ssi/pkg/did/kms"
+	"encoding/json"
 	"encoding/pem"
 	"errors"
 	"github.com/golang-jwt/jwt"
@@ -351,3 +352,59 @@ func TestKMSExportsECDSA(t *testing.T) {
 		t.Fatal(errors.New("unexpected base58 for invalid public key"))
 	}
 }
+
+func TestDidUpdateProof(t *testing.T) {
+	ownerKMS, err := kms.InitKMS(kms.KeyTypeECDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKMS, err := kms.InitKMS(kms.KeyTypeECDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	did, current := dids.CreateDID("byd50", ownerKMS.PbKeyBase58())
+
+	var doc dids.DocumentInterface
+	if err := json.Unmarshal(current, &doc); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.AddService(dids.ServiceProperty{ID: "#linked-domain", Types: dids.ServiceTypes{dids.ServiceTypeLinkedDomains},
+		ServiceEndpoint: dids.EndpointURI("https://issuer.example")}); err != nil {
+		t.Fatal(err)
+	}
+	updated, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proof, err := core.SignDidUpdate(did, string(current), string(updated), ownerKMS.PvKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := core.VerifyDidUpdate(proof, did, string(current), string(updated)); err != nil {
+		t.Fatalf("update proof rejected: %v", err)
+	}
+
+	// The proof is bound to both document versions.
+	if err := core.VerifyDidUpdate(proof, did, string(updated), string(updated)); err == nil {
+		t.Fatal("expected proof for another document version to be rejected")
+	}
+	if err := core.VerifyDidUpdate(proof, did, string(current), string(current)); err == nil {
+		t.Fatal("expected proof for another new document to be rejected")
+	}
+
+	otherProof, err := core.SignDidUpdate(did, string(current), string(updated), otherKMS.PvKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := core.VerifyDidUpdate(otherProof, did, string(current), string(updated)); err == nil {
+		t.Fatal("expected proof by a key without capabilityInvocation to be rejected")
+	}
+
+	doc.ID = "did:byd50:other"
+	moved, _ := json.Marshal(doc)
+	movedProof, _ := core.SignDidUpdate(did, string(current), string(moved), ownerKMS.PvKey())
+	if err := core.VerifyDidUpdate(movedProof, did, string(current), string(moved)); err == nil {
+		t.Fatal("expected document id change to be rejected")
+	}
+}
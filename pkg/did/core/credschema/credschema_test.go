@@ -0,0 +1,128 @@
+package credschema
+
+import (
+	"byd50-ssi/pkg/did/core/vcdm"
+	"encoding/json"
+	"errors"
+	"testing"
+)
+
+const licenceSchemaID = "urn:byd50:schema:DriverLicenseCredential:1"
+
+const licenceSchema = `{
+	"$schema": "https://json-schema.org/draft/2020-12/schema",
+	"type": "object",
+	"required": ["name", "birth", "driverLicense"],
+	"properties": {
+		"name": {"type": "string", "minLength": 1},
+		"birth": {"type": "string", "format": "date"},
+		"driverLicense": {
+			"type": "object",
+			"required": ["documentnumber"],
+			"properties": {
+				"documentnumber": {"type": "string", "pattern": "^[0-9]{2}-[0-9]{2}-[0-9]{6}-[0-9]{2}$"},
+				"class": {"type": "integer", "minimum": 1}
+			}
+		}
+	}
+}`
+
+func licenceSubject() map[string]interface{} {
+	return map[string]interface{}{
+		"name":  "Hong Gil-Dong",
+		"birth": "2000-11-08",
+		"driverLicense": map[string]interface{}{
+			"documentnumber": "15-03-142857-74",
+			"class":          1,
+		},
+	}
+}
+
+func TestRegistryValidate(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("DriverLicenseCredential", licenceSchemaID, []byte(licenceSchema)); err != nil {
+		t.Fatal(err)
+	}
+
+	vc := vcdm.NewCredentialV1("DriverLicenseCredential", vcdm.CredentialSubject{ID: "did:byd50:holder", Claims: licenceSubject()})
+	if err := r.Validate(vc); err != nil {
+		t.Fatalf("valid subject rejected: %v", err)
+	}
+	refs := r.SchemasFor(vc.Type)
+	if len(refs) != 1 || refs[0].ID != licenceSchemaID || refs[0].Type != vcdm.CredentialSchemaTypeJsonSchema {
+		t.Fatalf("unexpected schema refs: %+v", refs)
+	}
+
+	subject := licenceSubject()
+	delete(subject, "name")
+	subject["birth"] = "08/11/2000"
+	subject["driverLicense"].(map[string]interface{})["documentnumber"] = "x"
+	vc = vcdm.NewCredentialV1("DriverLicenseCredential", vcdm.CredentialSubject{Claims: subject})
+	err := r.Validate(vc)
+	var verr *ValidationError
+	if !errors.As(err, &verr) {
+		t.Fatalf("expected ValidationError, got %v", err)
+	}
+	paths := map[string]bool{}
+	for _, v := range verr.Violations {
+		paths[v.Path] = true
+	}
+	for _, want := range []string{"/credentialSubject", "/credentialSubject/birth", "/credentialSubject/driverLicense/documentnumber"} {
+		if !paths[want] {
+			t.Fatalf("missing violation at %s: %+v", want, verr.Violations)
+		}
+	}
+	if verr.CredentialType != "DriverLicenseCredential" || verr.SchemaID != licenceSchemaID {
+		t.Fatalf("unexpected error metadata: %+v", verr)
+	}
+
+	// Subjects of types without a schema are not validated.
+	other := vcdm.NewCredentialV1("AlumniCredential", vcdm.CredentialSubject{Claims: map[string]interface{}{"any": 1}})
+	if err := r.Validate(other); err != nil {
+		t.Fatalf("unexpected error for unregistered type: %v", err)
+	}
+}
+
+func TestRegistryCredentialSchemaReference(t *testing.T) {
+	r := NewRegistry()
+	r.MustRegister("DriverLicenseCredential", licenceSchemaID, []byte(licenceSchema))
+
+	// credentialSchema is honored even when the type is not the registered one.
+	vc := vcdm.NewCredential("OtherCredential", vcdm.CredentialSubject{Claims: map[string]interface{}{"name": ""}})
+	vc.CredentialSchema = []vcdm.CredentialSchema{{ID: licenceSchemaID, Type: vcdm.CredentialSchemaTypeJsonSchema}}
+	if err := r.Validate(vc); err == nil {
+		t.Fatal("expected violation for referenced schema")
+	}
+
+	vc.CredentialSchema = []vcdm.CredentialSchema{{ID: "urn:byd50:schema:unknown", Type: vcdm.CredentialSchemaTypeJsonSchema}}
+	if err := r.Validate(vc); err == nil {
+		t.Fatal("expected error for unknown schema")
+	}
+
+	// credentialSchema survives a JSON round trip.
+	vc.CredentialSchema = []vcdm.CredentialSchema{{ID: licenceSchemaID, Type: vcdm.CredentialSchemaTypeJsonSchema}}
+	data, err := json.Marshal(vc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded vcdm.VerifiableCredential
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if len(decoded.CredentialSchema) != 1 || decoded.CredentialSchema[0].ID != licenceSchemaID || decoded.Extra != nil {
+		t.Fatalf("credentialSchema not preserved: %s", data)
+	}
+}
+
+func TestRegisterRejectsInvalidSchema(t *testing.T) {
+	r := NewRegistry()
+	if err := r.Register("Broken", "urn:byd50:schema:broken", []byte(`{"type": 1}`)); err == nil {
+		t.Fatal("expected invalid schema error")
+	}
+	if err := r.Register("Remote", "urn:byd50:schema:remote", []byte(`{"$ref": "https://example.com/schema.json"}`)); err == nil {
+		t.Fatal("expected remote reference to be rejected")
+	}
+	if err := r.Register("", "urn:byd50:schema:empty", []byte(`{}`)); err == nil {
+		t.Fatal("expected missing credential type error")
+	}
+}
@@ -0,0 +1,26 @@
+package credschema
+
+import "strings"
+
+// Violation is a single schema violation. Path is a JSON pointer into the credential
+// (e.g. /credentialSubject/driverLicense/documentnumber); Keyword points into the schema.
+type Violation struct {
+	Path    string `json:"path"`
+	Keyword string `json:"keyword"`
+	Message string `json:"message"`
+}
+
+// ValidationError reports every violation of a credentialSubject against one schema.
+type ValidationError struct {
+	CredentialType string
+	SchemaID       string
+	Violations     []Violation
+}
+
+func (e *ValidationError) Error() string {
+	messages := make([]string, 0, len(e.Violations))
+	for _, v := range e.Violations {
+		messages = append(messages, v.Path+": "+v.Message)
+	}
+	return "credentialSubject does not match schema " + e.SchemaID + ": " + strings.Join(messages, "; ")
+}
@@ -0,0 +1,216 @@
+// Package credschema validates credentialSubject claims against JSON Schemas (draft 2020-12)
+// registered per credential type, and links them to credentials through the credentialSchema property.
+package credschema
+
+import (
+	"byd50-ssi/pkg/did/core/vcdm"
+	derrors "byd50-ssi/pkg/did/errors"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sync"
+
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+// Default is the process wide registry used by credential issuance and verification in core.
+var Default = NewRegistry()
+
+type entry struct {
+	credType string
+	id       string
+	schema   *jsonschema.Schema
+}
+
+// Registry holds compiled JSON Schemas keyed by credential type and by schema ID.
+type Registry struct {
+	mu     sync.RWMutex
+	byType map[string]*entry
+	byID   map[string]*entry
+}
+
+// NewRegistry returns an empty registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		byType: map[string]*entry{},
+		byID:   map[string]*entry{},
+	}
+}
+
+// Register compiles schemaJSON and registers it for credType under schemaID.
+// Registering a type again replaces its previous schema.
+func (r *Registry) Register(credType, schemaID string, schemaJSON []byte) error {
+	if credType == "" || schemaID == "" {
+		return derrors.New(derrors.CodeInvalidInput, "credential type and schema id are required")
+	}
+	compiler := jsonschema.NewCompiler()
+	compiler.Draft = jsonschema.Draft2020
+	compiler.AssertFormat = true
+	compiler.LoadURL = func(url string) (io.ReadCloser, error) {
+		return nil, fmt.Errorf("remote schema %s is not allowed", url)
+	}
+	if err := compiler.AddResource(schemaID, bytes.NewReader(schemaJSON)); err != nil {
+		return derrors.Wrap(derrors.CodeInvalidInput, "invalid schema "+schemaID, err)
+	}
+	schema, err := compiler.Compile(schemaID)
+	if err != nil {
+		return derrors.Wrap(derrors.CodeInvalidInput, "failed to compile schema "+schemaID, err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if old, ok := r.byType[credType]; ok {
+		delete(r.byID, old.id)
+	}
+	e := &entry{credType: credType, id: schemaID, schema: schema}
+	r.byType[credType] = e
+	r.byID[schemaID] = e
+	return nil
+}
+
+// MustRegister is like Register but panics on error. It is meant for bundled schemas.
+func (r *Registry) MustRegister(credType, schemaID string, schemaJSON []byte) {
+	if err := r.Register(credType, schemaID, schemaJSON); err != nil {
+		panic(err)
+	}
+}
+
+// SchemaFor returns the credentialSchema reference registered for credType.
+func (r *Registry) SchemaFor(credType string) (vcdm.CredentialSchema, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	e, ok := r.byType[credType]
+	if !ok {
+		return vcdm.CredentialSchema{}, false
+	}
+	return vcdm.CredentialSchema{ID: e.id, Type: vcdm.CredentialSchemaTypeJsonSchema}, true
+}
+
+// SchemasFor returns the credentialSchema references registered for any of the given types.
+func (r *Registry) SchemasFor(types []string) []vcdm.CredentialSchema {
+	var schemas []vcdm.CredentialSchema
+	for _, typ := range types {
+		if schema, ok := r.SchemaFor(typ); ok {
+			schemas = append(schemas, schema)
+		}
+	}
+	return schemas
+}
+
+// Validate checks every credentialSubject of vc against the schemas referenced by its
+// credentialSchema property and the schemas registered for its types.
+// A credential without any applicable schema is valid.
+func (r *Registry) Validate(vc *vcdm.VerifiableCredential) error {
+	if vc == nil {
+		return derrors.New(derrors.CodeInvalidInput, "credential is nil")
+	}
+	entries, err := r.lookup(vc)
+	if err != nil {
+		return err
+	}
+	for i, subject := range vc.CredentialSubject {
+		instance, err := toInstance(subject)
+		if err != nil {
+			return err
+		}
+		for _, e := range entries {
+			if err := validate(e, instance, subjectPointer(i, len(vc.CredentialSubject))); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// ValidateSubject checks a single credentialSubject against the schema registered for credType.
+func (r *Registry) ValidateSubject(credType string, subject map[string]interface{}) error {
+	r.mu.RLock()
+	e, ok := r.byType[credType]
+	r.mu.RUnlock()
+	if !ok {
+		return nil
+	}
+	instance, err := toInstance(subject)
+	if err != nil {
+		return err
+	}
+	return validate(e, instance, "/credentialSubject")
+}
+
+func (r *Registry) lookup(vc *vcdm.VerifiableCredential) ([]*entry, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	var entries []*entry
+	seen := map[string]bool{}
+	for _, ref := range vc.CredentialSchema {
+		if ref.Type != vcdm.CredentialSchemaTypeJsonSchema {
+			return nil, derrors.New(derrors.CodeInvalidInput, "unsupported credentialSchema type "+ref.Type)
+		}
+		e, ok := r.byID[ref.ID]
+		if !ok {
+			return nil, derrors.New(derrors.CodeNotFound, "unknown credentialSchema "+ref.ID)
+		}
+		if !seen[e.id] {
+			seen[e.id] = true
+			entries = append(entries, e)
+		}
+	}
+	for _, typ := range vc.Type {
+		if e, ok := r.byType[typ]; ok && !seen[e.id] {
+			seen[e.id] = true
+			entries = append(entries, e)
+		}
+	}
+	return entries, nil
+}
+
+func validate(e *entry, instance interface{}, pointer string) error {
+	err := e.schema.Validate(instance)
+	if err == nil {
+		return nil
+	}
+	verr, ok := err.(*jsonschema.ValidationError)
+	if !ok {
+		return derrors.Wrap(derrors.CodeInvalidInput, "schema validation failed", err)
+	}
+	out := &ValidationError{CredentialType: e.credType, SchemaID: e.id}
+	collectViolations(verr, pointer, &out.Violations)
+	return out
+}
+
+func collectViolations(verr *jsonschema.ValidationError, pointer string, violations *[]Violation) {
+	if len(verr.Causes) == 0 {
+		*violations = append(*violations, Violation{
+			Path:    pointer + verr.InstanceLocation,
+			Keyword: verr.KeywordLocation,
+			Message: verr.Message,
+		})
+		return
+	}
+	for _, cause := range verr.Causes {
+		collectViolations(cause, pointer, violations)
+	}
+}
+
+// toInstance converts a Go value into the generic JSON representation the validator expects.
+func toInstance(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "failed to encode credentialSubject", err)
+	}
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+	var instance interface{}
+	if err := decoder.Decode(&instance); err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "failed to decode credentialSubject", err)
+	}
+	return instance, nil
+}
+
+func subjectPointer(index, count int) string {
+	if count == 1 {
+		return "/credentialSubject"
+	}
+	return fmt.Sprintf("/credentialSubject/%d", index)
+}
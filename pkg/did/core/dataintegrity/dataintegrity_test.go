@@ -0,0 +1,181 @@
+package dataintegrity
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcutil/base58"
+)
+
+func TestCanonicalize(t *testing.T) {
+	// Samples from RFC 8785 section 3.2.2 and 3.2.3.
+	input := `{
+		"numbers": [333333333.33333329, 1E30, 4.50, 2e-3, 0.000000000000000000000000001, -0, 1e21, 1e20],
+		"string": "€$\u000F\u000aA'\u0042\u0022\u005c\\\"\/",
+		"literals": [null, true, false]
+	}`
+	want := `{"literals":[null,true,false],"numbers":[333333333.3333333,1e+30,4.5,0.002,1e-27,0,1e+21,100000000000000000000],"string":"€$\u000f\nA'B\"\\\\\"/"}`
+	got, err := Canonicalize([]byte(input))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(got) != want {
+		t.Fatalf("unexpected canonical form:\n got: %s\nwant: %s", got, want)
+	}
+
+	sorting := `{"€":"Euro Sign","\r":"Carriage Return","דּ":"Hebrew Letter Dalet With Dagesh","1":"One","😀":"Emoji: Grinning Face","\u0080":"Control","ö":"Latin Small Letter O With Diaeresis"}`
+	got, err = Canonicalize([]byte(sorting))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var order []string
+	for _, part := range strings.Split(string(got), ",") {
+		order = append(order, strings.SplitN(part, ":", 2)[1])
+	}
+	wantOrder := []string{`"Carriage Return"`, `"One"`, `"Control"`, `"Latin Small Letter O With Diaeresis"`,
+		`"Euro Sign"`, `"Emoji: Grinning Face"`, `"Hebrew Letter Dalet With Dagesh"}`}
+	if strings.Join(order, ",") != strings.Join(wantOrder, ",") {
+		t.Fatalf("unexpected property order: %s", got)
+	}
+}
+
+func testDocument() map[string]interface{} {
+	return map[string]interface{}{
+		"@context":          []interface{}{"https://www.w3.org/ns/credentials/v2"},
+		"type":              []interface{}{"VerifiableCredential"},
+		"issuer":            "did:byd50:issuer",
+		"credentialSubject": map[string]interface{}{"id": "did:byd50:holder", "age": 20},
+	}
+}
+
+func TestProofRoundTrip(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p384Key, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	edPub, edKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for _, tc := range []struct {
+		name        string
+		signer      crypto.Signer
+		pbKey       crypto.PublicKey
+		cryptosuite string
+	}{
+		{"p256", ecKey, &ecKey.PublicKey, CryptosuiteEcdsaJcs2019},
+		{"p384", p384Key, &p384Key.PublicKey, CryptosuiteEcdsaJcs2019},
+		{"ed25519", edKey, edPub, CryptosuiteEddsaJcs2022},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			doc := testDocument()
+			proof, err := CreateProof(doc, ProofOptions{VerificationMethod: "did:byd50:issuer#keys-1"}, tc.signer)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if proof.Cryptosuite != tc.cryptosuite || proof.ProofPurpose != ProofPurposeAssertionMethod {
+				t.Fatalf("unexpected proof: %+v", proof)
+			}
+			if !strings.HasPrefix(proof.ProofValue, "z") {
+				t.Fatalf("proofValue must be multibase base58btc: %s", proof.ProofValue)
+			}
+
+			// The proof is verified against the secured document as well (proof is ignored).
+			doc["proof"] = proof
+			if err := VerifyProof(doc, proof, "did:byd50:issuer", ProofPurposeAssertionMethod, tc.pbKey); err != nil {
+				t.Fatalf("verify failed: %v", err)
+			}
+
+			doc["credentialSubject"].(map[string]interface{})["age"] = 21
+			if err := VerifyProof(doc, proof, "did:byd50:issuer", ProofPurposeAssertionMethod, tc.pbKey); err == nil {
+				t.Fatal("expected tampered document to fail")
+			}
+		})
+	}
+}
+
+func TestVerifyProofRejects(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := testDocument()
+	proof, err := CreateProof(doc, ProofOptions{VerificationMethod: "did:byd50:issuer#keys-1", Challenge: "c-1"}, ecKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tampered := proof
+	tampered.Challenge = "c-2"
+	if err := VerifyProof(doc, tampered, "did:byd50:issuer", ProofPurposeAssertionMethod, &ecKey.PublicKey); err == nil {
+		t.Fatal("expected modified proof options to fail")
+	}
+
+	rdfc := proof
+	rdfc.Cryptosuite = CryptosuiteEcdsaRdfc2019
+	if err := VerifyProof(doc, rdfc, "did:byd50:issuer", ProofPurposeAssertionMethod, &ecKey.PublicKey); err == nil {
+		t.Fatal("expected rdfc cryptosuite to be rejected")
+	}
+
+	if err := VerifyProof(doc, proof, "did:byd50:issuer", ProofPurposeAuthentication, &ecKey.PublicKey); err == nil {
+		t.Fatal("expected an assertionMethod proof to be rejected for authentication")
+	}
+
+	if err := VerifyProof(doc, proof, "did:byd50:other", ProofPurposeAssertionMethod, &ecKey.PublicKey); err == nil {
+		t.Fatal("expected a key of another DID than the controller to be rejected")
+	}
+
+	edPub, _, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := VerifyProof(doc, proof, "did:byd50:issuer", ProofPurposeAssertionMethod, edPub); err == nil {
+		t.Fatal("expected key type mismatch to fail")
+	}
+
+	if _, err := CreateProof(doc, ProofOptions{}, ecKey); err == nil {
+		t.Fatal("expected missing verificationMethod to fail")
+	}
+}
+
+func TestResolveVerificationMethod(t *testing.T) {
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pbBytes, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var gotDid, gotKeyId string
+	getPbKey := func(did, keyId string) string {
+		gotDid, gotKeyId = did, keyId
+		return base58.Encode(pbBytes)
+	}
+
+	pbKey, err := ResolveVerificationMethod("did:byd50:issuer#keys-1", getPbKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if gotDid != "did:byd50:issuer" || gotKeyId != "did:byd50:issuer#keys-1" {
+		t.Fatalf("unexpected lookup: %s %s", gotDid, gotKeyId)
+	}
+	if !ecKey.PublicKey.Equal(pbKey) {
+		t.Fatal("resolved key mismatch")
+	}
+
+	if _, err := ResolveVerificationMethod("did:byd50:issuer#keys-1", func(string, string) string { return "" }); err == nil {
+		t.Fatal("expected error for missing key")
+	}
+}
@@ -0,0 +1,299 @@
+// Package dataintegrity implements W3C Data Integrity proofs with the JCS cryptosuites
+// ecdsa-jcs-2019 (P-256/P-384) and eddsa-jcs-2022 (Ed25519).
+package dataintegrity
+
+import (
+	derrors "byd50-ssi/pkg/did/errors"
+	"byd50-ssi/pkg/keys"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"encoding/asn1"
+	"encoding/json"
+	"math/big"
+	"strings"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+)
+
+const (
+	ProofTypeDataIntegrity = "DataIntegrityProof"
+
+	CryptosuiteEcdsaJcs2019  = "ecdsa-jcs-2019"
+	CryptosuiteEddsaJcs2022  = "eddsa-jcs-2022"
+	CryptosuiteEcdsaRdfc2019 = "ecdsa-rdfc-2019"
+	CryptosuiteEddsaRdfc2022 = "eddsa-rdfc-2022"
+
+	ProofPurposeAssertionMethod = "assertionMethod"
+	ProofPurposeAuthentication  = "authentication"
+
+	// ContextDataIntegrityV1 is the context VCDM 1.1 documents need for DataIntegrityProof.
+	ContextDataIntegrityV1 = "https://w3id.org/security/data-integrity/v1"
+
+	// multibaseBase58Btc is the multibase prefix of base58-btc encoded values.
+	multibaseBase58Btc = "z"
+)
+
+// Proof is an embedded Data Integrity proof.
+type Proof struct {
+	ID                 string `json:"id,omitempty"`
+	Type               string `json:"type"`
+	Cryptosuite        string `json:"cryptosuite"`
+	Created            string `json:"created,omitempty"`
+	Expires            string `json:"expires,omitempty"`
+	VerificationMethod string `json:"verificationMethod"`
+	ProofPurpose       string `json:"proofPurpose"`
+	Challenge          string `json:"challenge,omitempty"`
+	Domain             string `json:"domain,omitempty"`
+	ProofValue         string `json:"proofValue,omitempty"`
+}
+
+// ProofOptions are the options of a proof to be created. Created defaults to now.
+type ProofOptions struct {
+	VerificationMethod string
+	ProofPurpose       string
+	Challenge          string
+	Domain             string
+	Created            time.Time
+	Expires            time.Time
+}
+
+// CreateProof signs the unsecured document and returns the proof to be attached to it.
+// The cryptosuite is selected from the signer's key type.
+func CreateProof(document map[string]interface{}, options ProofOptions, signer crypto.Signer) (Proof, error) {
+	if signer == nil {
+		return Proof{}, derrors.New(derrors.CodeEmptyKey, "signer is nil")
+	}
+	if options.VerificationMethod == "" {
+		return Proof{}, derrors.New(derrors.CodeInvalidInput, "verificationMethod is empty")
+	}
+	if options.ProofPurpose == "" {
+		options.ProofPurpose = ProofPurposeAssertionMethod
+	}
+	if options.Created.IsZero() {
+		options.Created = time.Now()
+	}
+
+	cryptosuite, err := cryptosuiteFor(signer.Public())
+	if err != nil {
+		return Proof{}, err
+	}
+	proof := Proof{
+		Type:               ProofTypeDataIntegrity,
+		Cryptosuite:        cryptosuite,
+		Created:            options.Created.UTC().Format(time.RFC3339),
+		VerificationMethod: options.VerificationMethod,
+		ProofPurpose:       options.ProofPurpose,
+		Challenge:          options.Challenge,
+		Domain:             options.Domain,
+	}
+	if !options.Expires.IsZero() {
+		proof.Expires = options.Expires.UTC().Format(time.RFC3339)
+	}
+
+	data, err := hashData(document, proof, hashFor(signer.Public()))
+	if err != nil {
+		return Proof{}, err
+	}
+	signature, err := sign(signer, data)
+	if err != nil {
+		return Proof{}, err
+	}
+	proof.ProofValue = multibaseBase58Btc + base58.Encode(signature)
+	return proof, nil
+}
+
+// VerifyProof verifies proof against the unsecured document (the document without its proof property).
+// The verificationMethod must belong to controller (the issuer of a credential, the holder of a presentation)
+// and the proof must be made for proofPurpose, the verification relationship its key was resolved from.
+func VerifyProof(document map[string]interface{}, proof Proof, controller, proofPurpose string, pbKey crypto.PublicKey) error {
+	if proof.Type != ProofTypeDataIntegrity {
+		return derrors.New(derrors.CodeInvalidInput, "unsupported proof type: "+proof.Type)
+	}
+	if controller == "" {
+		return derrors.New(derrors.CodeInvalidInput, "proof controller is empty")
+	}
+	if did, _, _ := strings.Cut(proof.VerificationMethod, "#"); did != controller {
+		return derrors.New(derrors.CodeInvalidInput, "verificationMethod is not a key of "+controller)
+	}
+	if proof.ProofPurpose != proofPurpose {
+		return derrors.New(derrors.CodeInvalidInput, "proofPurpose must be "+proofPurpose)
+	}
+	switch proof.Cryptosuite {
+	case CryptosuiteEcdsaJcs2019, CryptosuiteEddsaJcs2022:
+	case CryptosuiteEcdsaRdfc2019, CryptosuiteEddsaRdfc2022:
+		return derrors.New(derrors.CodeInvalidInput, "rdfc canonicalization is not supported: "+proof.Cryptosuite)
+	default:
+		return derrors.New(derrors.CodeInvalidInput, "unsupported cryptosuite: "+proof.Cryptosuite)
+	}
+	expected, err := cryptosuiteFor(pbKey)
+	if err != nil {
+		return err
+	}
+	if expected != proof.Cryptosuite {
+		return derrors.New(derrors.CodeInvalidKey, "key type does not match cryptosuite "+proof.Cryptosuite)
+	}
+	if proof.Expires != "" {
+		expires, err := time.Parse(time.RFC3339, proof.Expires)
+		if err != nil {
+			return derrors.Wrap(derrors.CodeInvalidInput, "invalid proof expires", err)
+		}
+		if time.Now().After(expires) {
+			return derrors.New(derrors.CodeInvalidInput, "proof expired")
+		}
+	}
+	if !strings.HasPrefix(proof.ProofValue, multibaseBase58Btc) {
+		return derrors.New(derrors.CodeInvalidInput, "proofValue must be base58-btc multibase")
+	}
+	signature := base58.Decode(proof.ProofValue[len(multibaseBase58Btc):])
+	if len(signature) == 0 {
+		return derrors.New(derrors.CodeInvalidInput, "proofValue is empty")
+	}
+
+	data, err := hashData(document, proof, hashFor(pbKey))
+	if err != nil {
+		return err
+	}
+	if !verify(pbKey, data, signature) {
+		return derrors.New(derrors.CodeInvalidInput, "proof signature invalid")
+	}
+	return nil
+}
+
+// ResolveVerificationMethod resolves the public key of a verificationMethod URL (did#fragment)
+// using the same resolver that is used for JWT verification.
+func ResolveVerificationMethod(verificationMethod string, getPbKey func(string, string) string) (crypto.PublicKey, error) {
+	// a DID URL selects that verification method of the DID, a bare DID its first key
+	did, keyId := verificationMethod, ""
+	if i := strings.Index(verificationMethod, "#"); i >= 0 {
+		did, keyId = verificationMethod[:i], verificationMethod
+	}
+	if did == "" {
+		return nil, derrors.New(derrors.CodeInvalidInput, "verificationMethod is empty")
+	}
+	pbKeyBase58 := getPbKey(did, keyId)
+	if pbKeyBase58 == "" {
+		return nil, derrors.New(derrors.CodeNotFound, "public key not found: "+verificationMethod)
+	}
+	pbKey, err := keys.ParsePublicKeyBase58(pbKeyBase58)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidKey, "invalid public key", err)
+	}
+	return pbKey, nil
+}
+
+// hashData computes hash(canonical proof config) || hash(canonical unsecured document).
+// The proof config is the proof without proofValue, carrying the document's @context.
+func hashData(document map[string]interface{}, proof Proof, hash crypto.Hash) ([]byte, error) {
+	unsecured := make(map[string]interface{}, len(document))
+	for k, v := range document {
+		if k != "proof" {
+			unsecured[k] = v
+		}
+	}
+	canonicalDocument, err := Canonicalize(unsecured)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "failed to canonicalize document", err)
+	}
+
+	proof.ProofValue = ""
+	var proofConfig map[string]interface{}
+	data, err := json.Marshal(proof)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "failed to encode proof", err)
+	}
+	if err := json.Unmarshal(data, &proofConfig); err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "failed to decode proof", err)
+	}
+	if ctx, ok := document["@context"]; ok {
+		proofConfig["@context"] = ctx
+	}
+	canonicalProofConfig, err := Canonicalize(proofConfig)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "failed to canonicalize proof config", err)
+	}
+
+	h := hash.New()
+	h.Write(canonicalProofConfig)
+	out := h.Sum(nil)
+	h.Reset()
+	h.Write(canonicalDocument)
+	return h.Sum(out), nil
+}
+
+func cryptosuiteFor(pbKey crypto.PublicKey) (string, error) {
+	switch key := pbKey.(type) {
+	case *ecdsa.PublicKey:
+		if key.Curve != elliptic.P256() && key.Curve != elliptic.P384() {
+			return "", derrors.New(derrors.CodeInvalidKey, "ecdsa-jcs-2019 requires a P-256 or P-384 key")
+		}
+		return CryptosuiteEcdsaJcs2019, nil
+	case ed25519.PublicKey:
+		return CryptosuiteEddsaJcs2022, nil
+	default:
+		return "", derrors.New(derrors.CodeInvalidKey, "unsupported key type for data integrity proof")
+	}
+}
+
+func hashFor(pbKey crypto.PublicKey) crypto.Hash {
+	if key, ok := pbKey.(*ecdsa.PublicKey); ok && key.Curve == elliptic.P384() {
+		return crypto.SHA384
+	}
+	return crypto.SHA256
+}
+
+func sign(signer crypto.Signer, data []byte) ([]byte, error) {
+	switch key := signer.Public().(type) {
+	case ed25519.PublicKey:
+		signature, err := signer.Sign(rand.Reader, data, crypto.Hash(0))
+		if err != nil {
+			return nil, derrors.Wrap(derrors.CodeInternal, "failed to sign proof", err)
+		}
+		return signature, nil
+	case *ecdsa.PublicKey:
+		hash := hashFor(key)
+		h := hash.New()
+		h.Write(data)
+		der, err := signer.Sign(rand.Reader, h.Sum(nil), hash)
+		if err != nil {
+			return nil, derrors.Wrap(derrors.CodeInternal, "failed to sign proof", err)
+		}
+		var sig struct{ R, S *big.Int }
+		if _, err := asn1.Unmarshal(der, &sig); err != nil {
+			return nil, derrors.Wrap(derrors.CodeInternal, "invalid ecdsa signature", err)
+		}
+		// Data Integrity uses the fixed-size r||s (IEEE P1363) encoding.
+		size := (key.Curve.Params().BitSize + 7) / 8
+		signature := make([]byte, 2*size)
+		sig.R.FillBytes(signature[:size])
+		sig.S.FillBytes(signature[size:])
+		return signature, nil
+	default:
+		return nil, derrors.New(derrors.CodeInvalidKey, "unsupported key type for data integrity proof")
+	}
+}
+
+func verify(pbKey crypto.PublicKey, data, signature []byte) bool {
+	switch key := pbKey.(type) {
+	case ed25519.PublicKey:
+		return ed25519.Verify(key, data, signature)
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return false
+		}
+		hash := hashFor(key)
+		h := hash.New()
+		h.Write(data)
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		return ecdsa.Verify(key, h.Sum(nil), r, s)
+	default:
+		return false
+	}
+}
@@ -0,0 +1,90 @@
+package core
+
+import (
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	"byd50-ssi/pkg/did/core/dids"
+	derrors "byd50-ssi/pkg/did/errors"
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"github.com/golang-jwt/jwt"
+	"time"
+)
+
+// DidUpdateProofLifetime is how long a DID document update proof is accepted after it was signed.
+const DidUpdateProofLifetime = 5 * time.Minute
+
+// SignDidUpdate signs the replacement of currentDocument with newDocument by the DID controller.
+// pvKey must belong to a capabilityInvocation key of the DID. Binding the current document makes
+// the proof unusable once the document has changed, so it can't be replayed to revert a later update.
+func SignDidUpdate(did, currentDocument, newDocument string, pvKey crypto.PrivateKey) (string, error) {
+	if pvKey == nil {
+		return "", derrors.New(derrors.CodeEmptyKey, "private key is nil")
+	}
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":  did,
+		"iat":  now.Unix(),
+		"exp":  now.Add(DidUpdateProofLifetime).Unix(),
+		"prev": documentHash(currentDocument),
+		"doc":  documentHash(newDocument),
+	}
+	proof, err := byd50_jwt.Sign(did, "", claims, pvKey)
+	if err != nil {
+		return "", derrors.Wrap(derrors.CodeInvalidKey, "failed to sign did update", err)
+	}
+	return proof, nil
+}
+
+// VerifyDidUpdate checks that proof authorizes replacing currentDocument with newDocument: it must be signed
+// by a capabilityInvocation key of currentDocument, and newDocument must be a document of the same DID.
+func VerifyDidUpdate(proof, did, currentDocument, newDocument string) error {
+	var current, updated dids.DocumentInterface
+	if err := json.Unmarshal([]byte(currentDocument), &current); err != nil {
+		return derrors.Wrap(derrors.CodeInternal, "failed to parse current did document", err)
+	}
+	if err := json.Unmarshal([]byte(newDocument), &updated); err != nil {
+		return derrors.Wrap(derrors.CodeInvalidInput, "failed to parse new did document", err)
+	}
+	if current.ID != did || updated.ID != did {
+		return derrors.New(derrors.CodeInvalidInput, "did document id mismatch")
+	}
+
+	getPbKey := func(kid, keyId string) string {
+		if kid != did {
+			return ""
+		}
+		vm, err := current.FindVerificationMethod(dids.CapabilityInvocation, keyId)
+		if err != nil {
+			return ""
+		}
+		pbKeyBase58, err := vm.PublicKeyAsBase58()
+		if err != nil || (vm.Types != "" && CheckVerificationMethodKey(vm.Types, pbKeyBase58) != nil) {
+			return ""
+		}
+		return pbKeyBase58
+	}
+	claims, err := byd50_jwt.ParseSigned(proof, getPbKey)
+	if err != nil {
+		return derrors.Wrap(derrors.CodeInvalidKey, "did update proof invalid", err)
+	}
+	if _, ok := claims["exp"]; !ok {
+		return derrors.New(derrors.CodeInvalidInput, "did update proof has no exp")
+	}
+	if iss, _ := claims["iss"].(string); iss != did {
+		return derrors.New(derrors.CodeInvalidInput, "did update proof issuer mismatch")
+	}
+	if prev, _ := claims["prev"].(string); prev != documentHash(currentDocument) {
+		return derrors.New(derrors.CodeInvalidInput, "did update proof is for another document version")
+	}
+	if doc, _ := claims["doc"].(string); doc != documentHash(newDocument) {
+		return derrors.New(derrors.CodeInvalidInput, "did update proof does not match the new document")
+	}
+	return nil
+}
+
+func documentHash(document string) string {
+	digest := sha256.Sum256([]byte(document))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
@@ -0,0 +1,299 @@
+package didauth
+
+import (
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	derrors "byd50-ssi/pkg/did/errors"
+	"byd50-ssi/pkg/keys"
+	"crypto"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	// AccessTokenType is the typ header of access tokens (RFC 9068).
+	AccessTokenType = "at+jwt"
+	// TokenTypeBearer and TokenTypeDPoP are the token_type of unbound and DPoP-bound access tokens.
+	TokenTypeBearer = "Bearer"
+	TokenTypeDPoP   = "DPoP"
+	// DefaultAccessTokenTTL is the lifetime of access tokens when TokenService.AccessTokenTTL is zero.
+	DefaultAccessTokenTTL = 5 * time.Minute
+	// DefaultRefreshTokenTTL is how long a session can be refreshed when TokenService.RefreshTokenTTL is zero.
+	DefaultRefreshTokenTTL = 24 * time.Hour
+)
+
+// TokenResponse carries the tokens of an authenticated session.
+type TokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// AccessClaims are the claims of a validated access token.
+type AccessClaims struct {
+	// Did is the authenticated DID, the sub of the token.
+	Did       string    `json:"did"`
+	Issuer    string    `json:"iss"`
+	ID        string    `json:"jti"`
+	ExpiresAt time.Time `json:"expires_at"`
+	// Jkt is the JWK thumbprint of the holder key a DPoP-bound token is bound to.
+	Jkt string `json:"jkt,omitempty"`
+}
+
+// AccessValidator validates the access token of a request and, for DPoP-bound tokens, the DPoP proof of the
+// request of method to uri.
+type AccessValidator interface {
+	ValidateAccess(accessToken, dpopProof, method, uri string) (*AccessClaims, error)
+}
+
+// TokenService issues the access and refresh tokens of DIDs authenticated by the relying party Issuer. Access
+// tokens are JWTs signed with PvKey; refresh tokens are opaque, single-use and rotated on every refresh.
+// Refresh tokens, revocations and seen DPoP proofs are kept in memory.
+type TokenService struct {
+	// Issuer identifies the relying party, normally its DID; it is the iss and kid of access tokens.
+	Issuer          string
+	PvKey           crypto.Signer
+	AccessTokenTTL  time.Duration
+	RefreshTokenTTL time.Duration
+	ClockSkew       time.Duration
+
+	mu            sync.Mutex
+	refreshTokens map[string]refreshRecord
+	revoked       map[string]time.Time
+	proofs        map[string]time.Time
+}
+
+type refreshRecord struct {
+	did       string
+	jkt       string
+	accessJti string
+	accessExp time.Time
+	expiresAt time.Time
+}
+
+// Issue starts a session of did, which the caller authenticated. When jkt is set the tokens are bound to the
+// holder key with that JWK thumbprint (see VerifyDPoPProof) and must be presented with DPoP proofs.
+func (s *TokenService) Issue(did, jkt string) (*TokenResponse, error) {
+	if did == "" {
+		return nil, derrors.New(derrors.CodeInvalidInput, "did is required")
+	}
+	ttl := s.RefreshTokenTTL
+	if ttl <= 0 {
+		ttl = DefaultRefreshTokenTTL
+	}
+	return s.issue(did, jkt, time.Now().Add(ttl))
+}
+
+// Refresh exchanges a refresh token for new tokens. The refresh token is consumed, and tokens bound to a
+// holder key can only be refreshed with a proof of that key (jkt of the verified DPoP proof).
+func (s *TokenService) Refresh(refreshToken, jkt string) (*TokenResponse, error) {
+	s.mu.Lock()
+	record, ok := s.refreshTokens[refreshToken]
+	delete(s.refreshTokens, refreshToken)
+	s.mu.Unlock()
+
+	switch {
+	case !ok:
+		return nil, tokenError(TokenRevoked, "refresh token is unknown, used or revoked")
+	case time.Now().After(record.expiresAt):
+		return nil, tokenError(TokenExpired, "refresh token expired")
+	case record.jkt != "" && record.jkt != jkt:
+		return nil, tokenError(TokenInvalidProof, "refresh token is bound to another key")
+	}
+	// the session does not outlive the original refresh token
+	return s.issue(record.did, record.jkt, record.expiresAt)
+}
+
+// Revoke revokes a refresh token, together with the last access token issued with it, or an access token.
+// Unknown tokens are ignored, as in RFC 7009.
+func (s *TokenService) Revoke(token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if record, ok := s.refreshTokens[token]; ok {
+		delete(s.refreshTokens, token)
+		s.revokeLocked(record.accessJti, record.accessExp)
+		return nil
+	}
+	claims, err := s.parse(token)
+	if err != nil {
+		return nil
+	}
+	s.revokeLocked(claims.ID, claims.ExpiresAt)
+	return nil
+}
+
+// ValidateAccess implements AccessValidator. Failures are *TokenError.
+func (s *TokenService) ValidateAccess(accessToken, dpopProof, method, uri string) (*AccessClaims, error) {
+	claims, err := s.parse(accessToken)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	if now.After(claims.ExpiresAt.Add(s.clockSkew())) {
+		return nil, tokenError(TokenExpired, "access token expired")
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, revoked := s.revoked[claims.ID]; revoked {
+		return nil, tokenError(TokenRevoked, "access token revoked")
+	}
+	if claims.Jkt == "" {
+		return claims, nil
+	}
+	if dpopProof == "" {
+		return nil, tokenError(TokenInvalidProof, "access token is DPoP-bound and needs a DPoP proof")
+	}
+	proof, err := VerifyDPoPProof(dpopProof, method, uri, accessToken, s.clockSkew())
+	if err != nil {
+		return nil, err
+	}
+	if proof.Jkt != claims.Jkt {
+		return nil, tokenError(TokenInvalidProof, "dpop proof is signed by another key")
+	}
+	if err := s.markProofLocked(proof, now); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// VerifyDPoPProof verifies the DPoP proof of a token request and returns the thumbprint of its key. Each proof
+// is accepted once.
+func (s *TokenService) VerifyDPoPProof(dpopProof, method, uri string) (string, error) {
+	proof, err := VerifyDPoPProof(dpopProof, method, uri, "", s.clockSkew())
+	if err != nil {
+		return "", err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if err := s.markProofLocked(proof, time.Now()); err != nil {
+		return "", err
+	}
+	return proof.Jkt, nil
+}
+
+func (s *TokenService) issue(did, jkt string, refreshExpiresAt time.Time) (*TokenResponse, error) {
+	if s.PvKey == nil || s.Issuer == "" {
+		return nil, derrors.New(derrors.CodeInternal, "token service has no issuer key")
+	}
+	refreshToken, err := randomToken()
+	if err != nil {
+		return nil, err
+	}
+	ttl := s.AccessTokenTTL
+	if ttl <= 0 {
+		ttl = DefaultAccessTokenTTL
+	}
+	now := time.Now()
+	jti := uuid.NewV4().String()
+	claims := jwt.MapClaims{
+		"iss": s.Issuer,
+		"sub": did,
+		"aud": s.Issuer,
+		"jti": jti,
+		"iat": now.Unix(),
+		"exp": now.Add(ttl).Unix(),
+	}
+	tokenType := TokenTypeBearer
+	if jkt != "" {
+		claims["cnf"] = map[string]string{"jkt": jkt}
+		tokenType = TokenTypeDPoP
+	}
+	accessToken, err := byd50_jwt.SignTyped(s.Issuer, AccessTokenType, claims, s.PvKey)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInternal, "failed to sign access token", err)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.refreshTokens == nil {
+		s.refreshTokens = map[string]refreshRecord{}
+	}
+	for token, record := range s.refreshTokens {
+		if now.After(record.expiresAt) {
+			delete(s.refreshTokens, token)
+		}
+	}
+	s.refreshTokens[refreshToken] = refreshRecord{
+		did:       did,
+		jkt:       jkt,
+		accessJti: jti,
+		accessExp: now.Add(ttl),
+		expiresAt: refreshExpiresAt,
+	}
+	return &TokenResponse{
+		AccessToken:  accessToken,
+		TokenType:    tokenType,
+		ExpiresIn:    int64(ttl / time.Second),
+		RefreshToken: refreshToken,
+	}, nil
+}
+
+// parse verifies the signature of an access token of this service and returns its claims, without checking exp.
+func (s *TokenService) parse(accessToken string) (*AccessClaims, error) {
+	if s.PvKey == nil {
+		return nil, tokenError(TokenInvalidSignature, "token service has no issuer key")
+	}
+	pbKeyBase58 := keys.ExportPublicKeyAsBase58(s.PvKey.Public())
+	claims, err := byd50_jwt.VerifyTypedSignature(accessToken, AccessTokenType, func(kid, _ string) string {
+		if kid != s.Issuer {
+			return ""
+		}
+		return pbKeyBase58
+	})
+	if err != nil {
+		return nil, tokenError(TokenInvalidSignature, "access token invalid: "+err.Error())
+	}
+	mapClaims := byd50_jwt.MapClaims(claims)
+	sub, _ := mapClaims["sub"].(string)
+	jti, _ := mapClaims["jti"].(string)
+	exp, err := mapClaims.GetExpiresAt()
+	if iss, _ := mapClaims["iss"].(string); iss != s.Issuer || sub == "" || jti == "" || err != nil {
+		return nil, tokenError(TokenMalformed, "access token claims are incomplete")
+	}
+	accessClaims := &AccessClaims{Did: sub, Issuer: s.Issuer, ID: jti, ExpiresAt: time.Unix(exp, 0)}
+	if cnf, ok := mapClaims["cnf"].(map[string]interface{}); ok {
+		accessClaims.Jkt, _ = cnf["jkt"].(string)
+	}
+	return accessClaims, nil
+}
+
+func (s *TokenService) revokeLocked(jti string, until time.Time) {
+	now := time.Now()
+	if s.revoked == nil {
+		s.revoked = map[string]time.Time{}
+	}
+	for id, exp := range s.revoked {
+		if now.After(exp.Add(s.clockSkew())) {
+			delete(s.revoked, id)
+		}
+	}
+	s.revoked[jti] = until
+}
+
+// markProofLocked records a DPoP proof until it is too old to be accepted, failing when it was seen before.
+func (s *TokenService) markProofLocked(proof *DPoPProof, now time.Time) error {
+	if s.proofs == nil {
+		s.proofs = map[string]time.Time{}
+	}
+	for id, until := range s.proofs {
+		if now.After(until) {
+			delete(s.proofs, id)
+		}
+	}
+	if _, ok := s.proofs[proof.ID]; ok {
+		return tokenError(TokenReplayed, "dpop proof was already used")
+	}
+	s.proofs[proof.ID] = proof.IssuedAt.Add(DPoPProofMaxAge + s.clockSkew())
+	return nil
+}
+
+func (s *TokenService) clockSkew() time.Duration {
+	if s.ClockSkew <= 0 {
+		return DefaultClockSkew
+	}
+	return s.ClockSkew
+}
@@ -0,0 +1,120 @@
+// Package didauth implements DID authentication at a relying party: challenges issued to a DID for one
+// authentication session, the signed challenge responses that prove control of an authentication key of
+// the DID, and the session tokens handed out once a challenge is answered.
+package didauth
+
+import (
+	derrors "byd50-ssi/pkg/did/errors"
+	"crypto/rand"
+	"crypto/subtle"
+	"sync"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+	uuid "github.com/satori/go.uuid"
+)
+
+// DefaultChallengeTTL is how long a challenge can be answered when ChallengeStore.TTL is zero.
+const DefaultChallengeTTL = 2 * time.Minute
+
+// Challenge is the challenge of one authentication session, bound to the DID it was issued to.
+type Challenge struct {
+	SessionID string `json:"session_id"`
+	Did       string `json:"did"`
+	Value     string `json:"value"`
+	// Audience and Domain identify the relying party in signed challenges.
+	Audience  string    `json:"aud,omitempty"`
+	Domain    string    `json:"domain,omitempty"`
+	IssuedAt  time.Time `json:"iat"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
+
+// ChallengeStore issues single-use challenges keyed by session id. Challenges are kept in memory.
+type ChallengeStore struct {
+	TTL time.Duration
+
+	mu         sync.Mutex
+	challenges map[string]Challenge
+}
+
+// Issue starts an authentication session for did and returns its challenge.
+func (s *ChallengeStore) Issue(did string) (Challenge, error) {
+	return s.issue(did, "", "")
+}
+
+func (s *ChallengeStore) issue(did, audience, domain string) (Challenge, error) {
+	if did == "" {
+		return Challenge{}, derrors.New(derrors.CodeInvalidInput, "did is required")
+	}
+	value, err := randomToken()
+	if err != nil {
+		return Challenge{}, err
+	}
+	ttl := s.TTL
+	if ttl <= 0 {
+		ttl = DefaultChallengeTTL
+	}
+	now := time.Now()
+	challenge := Challenge{
+		SessionID: uuid.NewV4().String(),
+		Did:       did,
+		Value:     value,
+		Audience:  audience,
+		Domain:    domain,
+		IssuedAt:  now,
+		ExpiresAt: now.Add(ttl),
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.challenges == nil {
+		s.challenges = map[string]Challenge{}
+	}
+	for id, c := range s.challenges {
+		if now.After(c.ExpiresAt) {
+			delete(s.challenges, id)
+		}
+	}
+	s.challenges[challenge.SessionID] = challenge
+	return challenge, nil
+}
+
+// Redeem checks the response of did to the challenge of sessionID. The challenge is consumed by the first
+// attempt, successful or not, so a session can be answered only once.
+func (s *ChallengeStore) Redeem(sessionID, did, response string) error {
+	challenge, err := s.take(sessionID, did)
+	if err != nil {
+		return err
+	}
+	if subtle.ConstantTimeCompare([]byte(challenge.Value), []byte(response)) != 1 {
+		return derrors.New(derrors.CodeInvalidInput, "challenge response mismatch")
+	}
+	return nil
+}
+
+// take removes the challenge of sessionID and returns it while it is valid for did.
+func (s *ChallengeStore) take(sessionID, did string) (Challenge, error) {
+	s.mu.Lock()
+	challenge, ok := s.challenges[sessionID]
+	delete(s.challenges, sessionID)
+	s.mu.Unlock()
+
+	switch {
+	case !ok:
+		return Challenge{}, derrors.New(derrors.CodeNotFound, "authentication session not found")
+	case time.Now().After(challenge.ExpiresAt):
+		return Challenge{}, derrors.New(derrors.CodeInvalidInput, "challenge expired")
+	case challenge.Did != did:
+		return Challenge{}, derrors.New(derrors.CodeInvalidInput, "challenge was issued to another did")
+	}
+	return challenge, nil
+}
+
+// randomToken returns 256 bits from crypto/rand, base58 encoded.
+func randomToken() (string, error) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		return "", derrors.Wrap(derrors.CodeInternal, "failed to read random bytes", err)
+	}
+	return base58.Encode(b), nil
+}
@@ -0,0 +1,565 @@
+package didauth
+
+import (
+	"byd50-ssi/pkg/did/core"
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	"byd50-ssi/pkg/keys"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"fmt"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	holderDid = "did:byd50:holder"
+	audience  = "https://rp.example.com"
+	domain    = "rp.example.com"
+)
+
+// testDocument maps the key ids of the authentication keys of holderDid to their private keys;
+// the first entry is the default key.
+type testDocument struct {
+	ids   []string
+	pvKey map[string]crypto.Signer
+}
+
+func newTestDocument(t *testing.T) *testDocument {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k1, _, err := keys.GenerateSecp256k1KeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &testDocument{
+		ids: []string{holderDid + "#key-1", holderDid + "#key-2", holderDid + "#key-3"},
+		pvKey: map[string]crypto.Signer{
+			holderDid + "#key-1": p256,
+			holderDid + "#key-2": rsaKey,
+			holderDid + "#key-3": k1,
+		},
+	}
+}
+
+func (d *testDocument) getPbKey(did, keyId string) string {
+	if did != holderDid {
+		return ""
+	}
+	if keyId == "" {
+		keyId = d.ids[0]
+	}
+	pvKey, ok := d.pvKey[keyId]
+	if !ok {
+		return ""
+	}
+	return keys.ExportPublicKeyAsBase58(pvKey.Public())
+}
+
+func TestChallengeRedeem(t *testing.T) {
+	store := &ChallengeStore{}
+	challenge, err := store.Issue(holderDid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if challenge.SessionID == "" || challenge.Value == "" || challenge.Did != holderDid {
+		t.Fatalf("unexpected challenge %+v", challenge)
+	}
+	if err := store.Redeem(challenge.SessionID, holderDid, challenge.Value); err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Redeem(challenge.SessionID, holderDid, challenge.Value); err == nil {
+		t.Fatal("expected a redeemed challenge to be rejected")
+	}
+	if _, err := store.Issue(""); err == nil {
+		t.Fatal("expected an empty did to be rejected")
+	}
+}
+
+func TestChallengeRejections(t *testing.T) {
+	store := &ChallengeStore{}
+	challenge, _ := store.Issue(holderDid)
+	if err := store.Redeem(challenge.SessionID, "did:byd50:other", challenge.Value); err == nil {
+		t.Fatal("expected a response for another did to be rejected")
+	}
+	// the failed attempt consumed the session
+	if err := store.Redeem(challenge.SessionID, holderDid, challenge.Value); err == nil {
+		t.Fatal("expected the session to be single-use")
+	}
+
+	challenge, _ = store.Issue(holderDid)
+	if err := store.Redeem(challenge.SessionID, holderDid, "wrong"); err == nil {
+		t.Fatal("expected a wrong response to be rejected")
+	}
+
+	expiring := &ChallengeStore{TTL: time.Millisecond}
+	challenge, _ = expiring.Issue(holderDid)
+	time.Sleep(5 * time.Millisecond)
+	if err := expiring.Redeem(challenge.SessionID, holderDid, challenge.Value); err == nil {
+		t.Fatal("expected an expired challenge to be rejected")
+	}
+}
+
+func TestConcurrentSessionsAreIndependent(t *testing.T) {
+	store := &ChallengeStore{}
+	const n = 50
+	challenges := make([]Challenge, n)
+	var wg sync.WaitGroup
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			challenge, err := store.Issue(fmt.Sprintf("did:byd50:holder%d", i))
+			if err != nil {
+				t.Error(err)
+			}
+			challenges[i] = challenge
+		}(i)
+	}
+	wg.Wait()
+
+	// every holder can answer its own challenge, whatever was issued after it
+	for i := 0; i < n; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			c := challenges[i]
+			if err := store.Redeem(c.SessionID, c.Did, c.Value); err != nil {
+				t.Errorf("session %d: %v", i, err)
+			}
+		}(i)
+	}
+	wg.Wait()
+}
+
+func TestConcurrentRedeemSucceedsOnce(t *testing.T) {
+	store := &ChallengeStore{}
+	challenge, _ := store.Issue(holderDid)
+	var succeeded int32
+	var wg sync.WaitGroup
+	for i := 0; i < 20; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if store.Redeem(challenge.SessionID, holderDid, challenge.Value) == nil {
+				atomic.AddInt32(&succeeded, 1)
+			}
+		}()
+	}
+	wg.Wait()
+	if succeeded != 1 {
+		t.Fatalf("expected exactly one successful redeem, got %d", succeeded)
+	}
+}
+
+func TestSignedChallengeWithAnyAuthenticationKey(t *testing.T) {
+	doc := newTestDocument(t)
+	auth := &Authenticator{GetPbKey: doc.getPbKey}
+	kids := append([]string{holderDid}, doc.ids...)
+	for _, kid := range kids {
+		pvKey := doc.pvKey[kid]
+		if kid == holderDid {
+			pvKey = doc.pvKey[doc.ids[0]]
+		}
+		challenge, err := auth.Challenge(holderDid, audience, domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		response, err := SignChallenge(challenge, kid, pvKey)
+		if err != nil {
+			t.Fatalf("%s: %v", kid, err)
+		}
+		if err := auth.Verify(challenge.SessionID, holderDid, response); err != nil {
+			t.Fatalf("%s: %v", kid, err)
+		}
+		if err := auth.Verify(challenge.SessionID, holderDid, response); err == nil {
+			t.Fatalf("%s: expected a replayed response to be rejected", kid)
+		}
+	}
+}
+
+func TestSignedChallengeRejections(t *testing.T) {
+	doc := newTestDocument(t)
+	auth := &Authenticator{GetPbKey: doc.getPbKey}
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	cases := map[string]func(*SignedChallenge) (string, error){
+		"foreign key": func(c *SignedChallenge) (string, error) {
+			return SignChallenge(c, holderDid, other)
+		},
+		"key of another verification method": func(c *SignedChallenge) (string, error) {
+			return SignChallenge(c, doc.ids[1], doc.pvKey[doc.ids[0]])
+		},
+		"another relying party": func(c *SignedChallenge) (string, error) {
+			c.Aud = "https://other.example.com"
+			return SignChallenge(c, holderDid, doc.pvKey[doc.ids[0]])
+		},
+		"another domain": func(c *SignedChallenge) (string, error) {
+			c.Domain = "other.example.com"
+			return SignChallenge(c, holderDid, doc.pvKey[doc.ids[0]])
+		},
+		"another nonce": func(c *SignedChallenge) (string, error) {
+			c.Nonce = "other"
+			return SignChallenge(c, holderDid, doc.pvKey[doc.ids[0]])
+		},
+		"another did": func(c *SignedChallenge) (string, error) {
+			return SignChallenge(c, "did:byd50:other", doc.pvKey[doc.ids[0]])
+		},
+	}
+	for name, sign := range cases {
+		challenge, err := auth.Challenge(holderDid, audience, domain)
+		if err != nil {
+			t.Fatal(err)
+		}
+		session := challenge.SessionID
+		response, err := sign(challenge)
+		if err != nil {
+			t.Fatalf("%s: %v", name, err)
+		}
+		if err := auth.Verify(session, holderDid, response); err == nil {
+			t.Fatalf("%s: expected the response to be rejected", name)
+		}
+	}
+
+	if _, err := auth.Challenge(holderDid, "", domain); err == nil {
+		t.Fatal("expected a challenge without audience to be rejected")
+	}
+}
+
+func TestAuthToken(t *testing.T) {
+	doc := newTestDocument(t)
+	verifier := &TokenVerifier{Audience: audience, GetPbKey: doc.getPbKey}
+	for _, kid := range doc.ids {
+		token, err := CreateToken(kid, audience, time.Minute, doc.pvKey[kid])
+		if err != nil {
+			t.Fatal(err)
+		}
+		did, err := verifier.Verify(token)
+		if err != nil || did != holderDid {
+			t.Fatalf("%s: unexpected result %q: %v", kid, did, err)
+		}
+		if _, err := verifier.Verify(token); TokenErrorCodeOf(err) != TokenReplayed {
+			t.Fatalf("%s: expected a replayed token to be rejected, got %v", kid, err)
+		}
+	}
+}
+
+func TestAuthTokenRejections(t *testing.T) {
+	doc := newTestDocument(t)
+	pvKey := doc.pvKey[doc.ids[0]]
+	other, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	sign := func(claims jwt.MapClaims) string {
+		token, err := byd50_jwt.SignTyped(holderDid, AuthTokenType, claims, pvKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	create := func(kid, aud string, validity time.Duration, key crypto.PrivateKey) string {
+		token, err := CreateToken(kid, aud, validity, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return token
+	}
+	now := time.Now()
+	cases := []struct {
+		name  string
+		token string
+		code  TokenErrorCode
+	}{
+		{"legacy string", "did:byd50:holder;2021-06-08T14:04:43Z;c2ln", TokenMalformed},
+		{"garbage", "a.b.c", TokenMalformed},
+		{"foreign key", create(holderDid, audience, time.Minute, other), TokenInvalidSignature},
+		{"another relying party", create(holderDid, "https://other.example.com", time.Minute, pvKey), TokenInvalidAudience},
+		{"long lived", create(holderDid, audience, time.Hour, pvKey), TokenInvalidLifetime},
+		{"exp before iat", create(holderDid, audience, -2*time.Minute, pvKey), TokenInvalidLifetime},
+		{"expired beyond skew", sign(jwt.MapClaims{"iss": holderDid, "aud": audience, "nonce": "n1",
+			"iat": now.Add(-3 * time.Minute).Unix(), "exp": now.Add(-time.Minute).Unix()}), TokenExpired},
+		{"issued in the future", sign(jwt.MapClaims{"iss": holderDid, "aud": audience, "nonce": "n2",
+			"iat": now.Add(time.Minute).Unix(), "exp": now.Add(2 * time.Minute).Unix()}), TokenNotYetValid},
+		{"issued for another did", sign(jwt.MapClaims{"iss": "did:byd50:other", "aud": audience, "nonce": "n3",
+			"iat": now.Unix(), "exp": now.Add(time.Minute).Unix()}), TokenInvalidIssuer},
+		{"no nonce", sign(jwt.MapClaims{"iss": holderDid, "aud": audience,
+			"iat": now.Unix(), "exp": now.Add(time.Minute).Unix()}), TokenMalformed},
+	}
+	verifier := &TokenVerifier{Audience: audience, GetPbKey: doc.getPbKey}
+	for _, c := range cases {
+		if _, err := verifier.Verify(c.token); TokenErrorCodeOf(err) != c.code {
+			t.Fatalf("%s: expected %s, got %v", c.name, c.code, err)
+		}
+	}
+}
+
+func TestAuthTokenClockSkew(t *testing.T) {
+	doc := newTestDocument(t)
+	pvKey := doc.pvKey[doc.ids[0]]
+	now := time.Now()
+	// a token that expired 10 seconds ago, as seen by a relying party whose clock runs ahead
+	token, err := byd50_jwt.SignTyped(holderDid, AuthTokenType, jwt.MapClaims{
+		"iss": holderDid, "aud": audience, "nonce": "skewed",
+		"iat": now.Add(-time.Minute).Unix(), "exp": now.Add(-10 * time.Second).Unix(),
+	}, pvKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	strict := &TokenVerifier{Audience: audience, GetPbKey: doc.getPbKey, ClockSkew: time.Second}
+	if _, err := strict.Verify(token); TokenErrorCodeOf(err) != TokenExpired {
+		t.Fatalf("expected the token to be expired with 1s skew, got %v", err)
+	}
+	tolerant := &TokenVerifier{Audience: audience, GetPbKey: doc.getPbKey, ClockSkew: time.Minute}
+	if _, err := tolerant.Verify(token); err != nil {
+		t.Fatalf("expected the token to be accepted with 1m skew, got %v", err)
+	}
+}
+
+func TestLegacySimplePresent(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+	getPbKey := func(did, _ string) string {
+		if did != holderDid {
+			return ""
+		}
+		return keys.ExportRSAPublicKeyAsBase58(&rsaKey.PublicKey)
+	}
+	present := func(did string, at time.Time) string {
+		message := did + ";" + at.UTC().Format(time.RFC3339)
+		_, signature := core.PvKeySign(keys.ExportRSAPrivateKeyAsBase58(rsaKey), message, "")
+		return message + ";" + signature
+	}
+	verifier := &TokenVerifier{Audience: audience, GetPbKey: getPbKey}
+
+	simplePresent := present(holderDid, time.Now())
+	did, err := verifier.VerifyLegacy(simplePresent)
+	if err != nil || did != holderDid {
+		t.Fatalf("unexpected result %q: %v", did, err)
+	}
+	if _, err := verifier.VerifyLegacy(simplePresent); TokenErrorCodeOf(err) != TokenReplayed {
+		t.Fatalf("expected a replayed presentation to be rejected, got %v", err)
+	}
+
+	cases := []struct {
+		name          string
+		simplePresent string
+		code          TokenErrorCode
+	}{
+		{"empty", "", TokenMalformed},
+		{"missing parts", holderDid, TokenMalformed},
+		{"extra parts", simplePresent + ";x", TokenMalformed},
+		{"bad time", holderDid + ";yesterday;c2ln", TokenMalformed},
+		{"old", present(holderDid, time.Now().Add(-time.Hour)), TokenExpired},
+		{"future", present(holderDid, time.Now().Add(time.Hour)), TokenNotYetValid},
+		{"unknown did", present("did:byd50:other", time.Now()), TokenInvalidSignature},
+		{"tampered", strings.Replace(present(holderDid, time.Now()), holderDid, holderDid+"x", 1), TokenInvalidSignature},
+	}
+	for _, c := range cases {
+		if _, err := verifier.VerifyLegacy(c.simplePresent); TokenErrorCodeOf(err) != c.code {
+			t.Fatalf("%s: expected %s, got %v", c.name, c.code, err)
+		}
+	}
+}
+
+func newTestTokenService(t *testing.T) *TokenService {
+	pvKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return &TokenService{Issuer: "did:byd50:rp", PvKey: pvKey}
+}
+
+func TestAccessTokenRefreshAndRevoke(t *testing.T) {
+	service := newTestTokenService(t)
+	tokens, err := service.Issue(holderDid, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if tokens.TokenType != TokenTypeBearer || tokens.RefreshToken == "" || tokens.ExpiresIn != int64(DefaultAccessTokenTTL/time.Second) {
+		t.Fatalf("unexpected token response %+v", tokens)
+	}
+	claims, err := service.ValidateAccess(tokens.AccessToken, "", "GET", "https://rp.example.com/resource")
+	if err != nil || claims.Did != holderDid || claims.Issuer != service.Issuer || claims.Jkt != "" {
+		t.Fatalf("unexpected claims %+v: %v", claims, err)
+	}
+
+	refreshed, err := service.Refresh(tokens.RefreshToken, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if refreshed.RefreshToken == tokens.RefreshToken || refreshed.AccessToken == tokens.AccessToken {
+		t.Fatal("expected refresh to rotate both tokens")
+	}
+	if _, err := service.Refresh(tokens.RefreshToken, ""); TokenErrorCodeOf(err) != TokenRevoked {
+		t.Fatalf("expected a used refresh token to be rejected, got %v", err)
+	}
+
+	if err := service.Revoke(refreshed.RefreshToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ValidateAccess(refreshed.AccessToken, "", "GET", "/"); TokenErrorCodeOf(err) != TokenRevoked {
+		t.Fatalf("expected the access token of a revoked refresh token to be rejected, got %v", err)
+	}
+	if _, err := service.Refresh(refreshed.RefreshToken, ""); TokenErrorCodeOf(err) != TokenRevoked {
+		t.Fatalf("expected a revoked refresh token to be rejected, got %v", err)
+	}
+	if err := service.Revoke(tokens.AccessToken); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.ValidateAccess(tokens.AccessToken, "", "GET", "/"); TokenErrorCodeOf(err) != TokenRevoked {
+		t.Fatalf("expected a revoked access token to be rejected, got %v", err)
+	}
+	if err := service.Revoke("unknown"); err != nil {
+		t.Fatalf("expected unknown tokens to be ignored, got %v", err)
+	}
+}
+
+func TestAccessTokenRejections(t *testing.T) {
+	service := newTestTokenService(t)
+	tokens, _ := service.Issue(holderDid, "")
+
+	other := newTestTokenService(t)
+	forged, _ := other.Issue(holderDid, "")
+	if _, err := service.ValidateAccess(forged.AccessToken, "", "GET", "/"); TokenErrorCodeOf(err) != TokenInvalidSignature {
+		t.Fatalf("expected a token of another key to be rejected, got %v", err)
+	}
+	if _, err := service.ValidateAccess(tokens.AccessToken+"x", "", "GET", "/"); TokenErrorCodeOf(err) != TokenInvalidSignature {
+		t.Fatalf("expected a tampered token to be rejected, got %v", err)
+	}
+	authToken, _ := CreateToken(service.Issuer, audience, time.Minute, service.PvKey)
+	if _, err := service.ValidateAccess(authToken, "", "GET", "/"); TokenErrorCodeOf(err) != TokenInvalidSignature {
+		t.Fatalf("expected a token of another typ to be rejected, got %v", err)
+	}
+
+	expiring := newTestTokenService(t)
+	expiring.AccessTokenTTL = time.Second
+	expiring.ClockSkew = time.Millisecond
+	expired, _ := expiring.Issue(holderDid, "")
+	time.Sleep(2100 * time.Millisecond)
+	if _, err := expiring.ValidateAccess(expired.AccessToken, "", "GET", "/"); TokenErrorCodeOf(err) != TokenExpired {
+		t.Fatalf("expected an expired token to be rejected, got %v", err)
+	}
+	if _, err := service.Issue("", ""); err == nil {
+		t.Fatal("expected an empty did to be rejected")
+	}
+}
+
+func TestDPoPBoundAccessToken(t *testing.T) {
+	const uri = "https://rp.example.com/resource"
+	service := newTestTokenService(t)
+	holderKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	proof, err := CreateDPoPProof("POST", "https://rp.example.com/token", "", holderKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	jkt, err := service.VerifyDPoPProof(proof, "POST", "https://rp.example.com/token")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := service.VerifyDPoPProof(proof, "POST", "https://rp.example.com/token"); TokenErrorCodeOf(err) != TokenReplayed {
+		t.Fatalf("expected a replayed proof to be rejected, got %v", err)
+	}
+	tokens, err := service.Issue(holderDid, jkt)
+	if err != nil || tokens.TokenType != TokenTypeDPoP {
+		t.Fatalf("unexpected token response %+v: %v", tokens, err)
+	}
+
+	proof, _ = CreateDPoPProof("GET", uri, tokens.AccessToken, holderKey)
+	claims, err := service.ValidateAccess(tokens.AccessToken, proof, "GET", uri)
+	if err != nil || claims.Jkt != jkt || claims.Did != holderDid {
+		t.Fatalf("unexpected claims %+v: %v", claims, err)
+	}
+	if _, err := service.ValidateAccess(tokens.AccessToken, proof, "GET", uri); TokenErrorCodeOf(err) != TokenReplayed {
+		t.Fatalf("expected a replayed proof to be rejected, got %v", err)
+	}
+
+	otherToken, _ := service.Issue(holderDid, jkt)
+	stolen, _ := CreateDPoPProof("GET", uri, tokens.AccessToken, otherKey)
+	wrongToken, _ := CreateDPoPProof("GET", uri, otherToken.AccessToken, holderKey)
+	wrongURI, _ := CreateDPoPProof("GET", uri+"/other", tokens.AccessToken, holderKey)
+	wrongMethod, _ := CreateDPoPProof("DELETE", uri, tokens.AccessToken, holderKey)
+	cases := []struct {
+		name  string
+		proof string
+	}{
+		{"missing", ""},
+		{"other key", stolen},
+		{"other token", wrongToken},
+		{"other uri", wrongURI},
+		{"other method", wrongMethod},
+		{"malformed", "x.y.z"},
+	}
+	for _, c := range cases {
+		if _, err := service.ValidateAccess(tokens.AccessToken, c.proof, "GET", uri); TokenErrorCodeOf(err) != TokenInvalidProof {
+			t.Fatalf("%s: expected %s, got %v", c.name, TokenInvalidProof, err)
+		}
+	}
+
+	if _, err := service.Refresh(tokens.RefreshToken, "other"); TokenErrorCodeOf(err) != TokenInvalidProof {
+		t.Fatalf("expected a refresh with another key to be rejected, got %v", err)
+	}
+	refreshed, err := service.Refresh(otherToken.RefreshToken, jkt)
+	if err != nil || refreshed.TokenType != TokenTypeDPoP {
+		t.Fatalf("unexpected refresh %+v: %v", refreshed, err)
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	const protectedMethod = "/issuer.Issuer/RentalCarControl"
+	service := newTestTokenService(t)
+	holderKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	pbJwk, _ := keys.ExportPublicKeyAsJWK(holderKey.Public())
+	jkt, _ := pbJwk.Thumbprint()
+	tokens, _ := service.Issue(holderDid, jkt)
+
+	interceptor := UnaryServerInterceptor(service, protectedMethod)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		claims, ok := ClaimsFromContext(ctx)
+		if !ok {
+			return "public", nil
+		}
+		return claims.Did, nil
+	}
+	call := func(ctx context.Context, method string) (interface{}, error) {
+		md, _ := metadata.FromOutgoingContext(ctx)
+		return interceptor(metadata.NewIncomingContext(context.Background(), md), nil, &grpc.UnaryServerInfo{FullMethod: method}, handler)
+	}
+
+	if got, err := call(context.Background(), "/issuer.Issuer/IssueCredential"); err != nil || got != "public" {
+		t.Fatalf("expected unprotected methods to pass, got %v: %v", got, err)
+	}
+	if _, err := call(context.Background(), protectedMethod); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected a call without token to be rejected, got %v", err)
+	}
+	bearer := metadata.AppendToOutgoingContext(context.Background(), AuthorizationMetadataKey, "Bearer "+tokens.AccessToken)
+	if _, err := call(bearer, protectedMethod); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected a DPoP-bound token without proof to be rejected, got %v", err)
+	}
+	ctx, err := OutgoingContext(context.Background(), protectedMethod, tokens, holderKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := call(ctx, protectedMethod); err != nil || got != holderDid {
+		t.Fatalf("expected the call to be authenticated as %s, got %v: %v", holderDid, got, err)
+	}
+}
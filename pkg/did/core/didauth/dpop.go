@@ -0,0 +1,128 @@
+package didauth
+
+import (
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	derrors "byd50-ssi/pkg/did/errors"
+	"byd50-ssi/pkg/keys"
+	"crypto"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	// DPoPProofType is the typ header of DPoP proofs (RFC 9449).
+	DPoPProofType = "dpop+jwt"
+	// DPoPProofMaxAge is how old the iat of a DPoP proof may be.
+	DPoPProofMaxAge = time.Minute
+)
+
+// DPoPProof is a verified DPoP proof.
+type DPoPProof struct {
+	// Jkt is the JWK SHA-256 thumbprint of the key that signed the proof.
+	Jkt      string
+	ID       string
+	IssuedAt time.Time
+}
+
+// CreateDPoPProof proves possession of pvKey for an HTTP request (or gRPC call) of method to uri. accessToken
+// is the token the request carries, if any; the proof then covers its hash.
+func CreateDPoPProof(method, uri, accessToken string, pvKey crypto.Signer) (string, error) {
+	jwk, err := keys.ExportPublicKeyAsJWK(pvKey.Public())
+	if err != nil {
+		return "", derrors.Wrap(derrors.CodeInvalidKey, "unsupported dpop key", err)
+	}
+	signingMethod, err := byd50_jwt.SigningMethodFor("", pvKey)
+	if err != nil {
+		return "", derrors.Wrap(derrors.CodeInvalidKey, "unsupported dpop key", err)
+	}
+	claims := jwt.MapClaims{
+		"jti": uuid.NewV4().String(),
+		"htm": method,
+		"htu": uri,
+		"iat": time.Now().Unix(),
+	}
+	if accessToken != "" {
+		claims["ath"] = accessTokenHash(accessToken)
+	}
+	token := jwt.NewWithClaims(signingMethod, claims)
+	token.Header["typ"] = DPoPProofType
+	token.Header["jwk"] = jwk
+	proof, err := byd50_jwt.SignToken(token, pvKey)
+	if err != nil {
+		return "", derrors.Wrap(derrors.CodeInternal, "failed to sign dpop proof", err)
+	}
+	return proof, nil
+}
+
+// VerifyDPoPProof checks that proof was made for a request of method to uri carrying accessToken (empty when the
+// request carries none) and signed by the key in its jwk header. Replays must be detected by the caller with the
+// proof ID. Failures are *TokenError.
+func VerifyDPoPProof(proof, method, uri, accessToken string, clockSkew time.Duration) (*DPoPProof, error) {
+	var jkt string
+	token, err := (&jwt.Parser{SkipClaimsValidation: true}).Parse(proof, func(token *jwt.Token) (interface{}, error) {
+		if typ, _ := token.Header["typ"].(string); typ != DPoPProofType {
+			return nil, derrors.New(derrors.CodeInvalidInput, "unexpected typ")
+		}
+		raw, err := json.Marshal(token.Header["jwk"])
+		if err != nil {
+			return nil, err
+		}
+		var jwk keys.JWK
+		if err := json.Unmarshal(raw, &jwk); err != nil {
+			return nil, err
+		}
+		pbKey, err := jwk.PublicKey()
+		if err != nil {
+			return nil, err
+		}
+		alg, err := byd50_jwt.AlgForPublicKey(pbKey)
+		if err != nil || alg != token.Method.Alg() {
+			return nil, derrors.New(derrors.CodeInvalidInput, "dpop alg does not match its jwk")
+		}
+		if jkt, err = jwk.Thumbprint(); err != nil {
+			return nil, err
+		}
+		return pbKey, nil
+	})
+	if err != nil || !token.Valid {
+		return nil, tokenError(TokenInvalidProof, "dpop proof invalid: "+errString(err))
+	}
+	claims, _ := token.Claims.(jwt.MapClaims)
+	mapClaims := byd50_jwt.MapClaims(claims)
+	jti, _ := mapClaims["jti"].(string)
+	htm, _ := mapClaims["htm"].(string)
+	htu, _ := mapClaims["htu"].(string)
+	ath, _ := mapClaims["ath"].(string)
+	switch {
+	case jti == "":
+		return nil, tokenError(TokenInvalidProof, "dpop proof jti is missing")
+	case htm != method || htu != uri:
+		return nil, tokenError(TokenInvalidProof, "dpop proof is for another request")
+	case accessToken != "" && ath != accessTokenHash(accessToken):
+		return nil, tokenError(TokenInvalidProof, "dpop proof is for another access token")
+	}
+	iat, err := mapClaims.GetIssuedAt()
+	issuedAt := time.Unix(iat, 0)
+	if err != nil || time.Since(issuedAt) > DPoPProofMaxAge+clockSkew || time.Until(issuedAt) > clockSkew {
+		return nil, tokenError(TokenInvalidProof, "dpop proof iat is missing or not recent")
+	}
+	return &DPoPProof{Jkt: jkt, ID: jti, IssuedAt: issuedAt}, nil
+}
+
+// accessTokenHash is the ath of DPoP proofs: the base64url SHA-256 hash of the access token.
+func accessTokenHash(accessToken string) string {
+	digest := sha256.Sum256([]byte(accessToken))
+	return base64.RawURLEncoding.EncodeToString(digest[:])
+}
+
+func errString(err error) string {
+	if err == nil {
+		return "invalid token"
+	}
+	return err.Error()
+}
@@ -0,0 +1,92 @@
+package didauth
+
+import (
+	derrors "byd50-ssi/pkg/did/errors"
+	"context"
+	"crypto"
+	"net/http"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// Metadata keys of the access token and DPoP proof of gRPC calls, as the HTTP headers of the same name.
+const (
+	AuthorizationMetadataKey = "authorization"
+	DPoPMetadataKey          = "dpop"
+)
+
+type claimsContextKey struct{}
+
+// UnaryServerInterceptor rejects calls of protectedMethods (full method names, e.g. "/issuer.Issuer/RentalCarControl")
+// without a valid access token in the authorization metadata ("Bearer <token>" or "DPoP <token>"). DPoP proofs of
+// gRPC calls are made for method POST and the full method name as uri. Handlers get the claims with ClaimsFromContext.
+func UnaryServerInterceptor(validator AccessValidator, protectedMethods ...string) grpc.UnaryServerInterceptor {
+	protected := map[string]bool{}
+	for _, method := range protectedMethods {
+		protected[method] = true
+	}
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !protected[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		accessToken, ok := AccessTokenFromHeader(firstValue(md, AuthorizationMetadataKey))
+		if !ok {
+			return nil, status.Error(codes.Unauthenticated, "access token required")
+		}
+		claims, err := validator.ValidateAccess(accessToken, firstValue(md, DPoPMetadataKey), http.MethodPost, info.FullMethod)
+		if err != nil {
+			return nil, status.Error(codes.Unauthenticated, err.Error())
+		}
+		return handler(ContextWithClaims(ctx, claims), req)
+	}
+}
+
+// OutgoingContext adds an access token to the metadata of a client call of fullMethod. Tokens of type
+// TokenTypeDPoP are sent with a fresh DPoP proof of dpopKey, the holder key they are bound to.
+func OutgoingContext(ctx context.Context, fullMethod string, token *TokenResponse, dpopKey crypto.Signer) (context.Context, error) {
+	if token.TokenType != TokenTypeDPoP {
+		return metadata.AppendToOutgoingContext(ctx, AuthorizationMetadataKey, TokenTypeBearer+" "+token.AccessToken), nil
+	}
+	if dpopKey == nil {
+		return nil, derrors.New(derrors.CodeEmptyKey, "dpop-bound access token needs the holder key")
+	}
+	proof, err := CreateDPoPProof(http.MethodPost, fullMethod, token.AccessToken, dpopKey)
+	if err != nil {
+		return nil, err
+	}
+	return metadata.AppendToOutgoingContext(ctx,
+		AuthorizationMetadataKey, TokenTypeDPoP+" "+token.AccessToken,
+		DPoPMetadataKey, proof), nil
+}
+
+// AccessTokenFromHeader returns the token of an Authorization header of scheme Bearer or DPoP.
+func AccessTokenFromHeader(header string) (string, bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || token == "" || (!strings.EqualFold(scheme, TokenTypeBearer) && !strings.EqualFold(scheme, TokenTypeDPoP)) {
+		return "", false
+	}
+	return token, true
+}
+
+// ContextWithClaims returns a copy of ctx carrying the claims of a validated access token.
+func ContextWithClaims(ctx context.Context, claims *AccessClaims) context.Context {
+	return context.WithValue(ctx, claimsContextKey{}, claims)
+}
+
+// ClaimsFromContext returns the access token claims UnaryServerInterceptor validated for the call.
+func ClaimsFromContext(ctx context.Context) (*AccessClaims, bool) {
+	claims, ok := ctx.Value(claimsContextKey{}).(*AccessClaims)
+	return claims, ok
+}
+
+func firstValue(md metadata.MD, key string) string {
+	if values := md.Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
@@ -0,0 +1,135 @@
+package didauth
+
+import (
+	"byd50-ssi/pkg/did/core"
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	derrors "byd50-ssi/pkg/did/errors"
+	"crypto"
+	"crypto/subtle"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	// TokenType is the typ header of signed challenge responses.
+	TokenType = "didauth+jwt"
+	// DefaultClockSkew is the tolerance for differences between holder and relying party clocks when
+	// the ClockSkew of an Authenticator or TokenVerifier is zero.
+	DefaultClockSkew = 30 * time.Second
+)
+
+// SignedChallenge is the structured challenge a holder signs to prove control of an authentication key
+// of its DID. aud and domain identify the relying party, so a response cannot be replayed at another one.
+type SignedChallenge struct {
+	SessionID string `json:"session_id"`
+	Nonce     string `json:"nonce"`
+	Aud       string `json:"aud"`
+	Domain    string `json:"domain"`
+	Iat       int64  `json:"iat"`
+	Exp       int64  `json:"exp"`
+}
+
+// Authenticator runs signature-based DID Auth: the relying party issues a SignedChallenge for a DID, the
+// holder signs it with any authentication key of the DID and the relying party verifies the signature
+// against the key resolved from the DID document.
+type Authenticator struct {
+	// GetPbKey resolves the authentication key of a DID; the key id is the kid of the response when it
+	// is a DID URL and empty otherwise.
+	GetPbKey   func(string, string) string
+	ClockSkew  time.Duration
+	Challenges ChallengeStore
+}
+
+// Challenge starts an authentication session of did at the relying party audience (its DID or URL)
+// serving domain.
+func (a *Authenticator) Challenge(did, audience, domain string) (*SignedChallenge, error) {
+	if audience == "" {
+		return nil, derrors.New(derrors.CodeInvalidInput, "audience is required")
+	}
+	challenge, err := a.Challenges.issue(did, audience, domain)
+	if err != nil {
+		return nil, err
+	}
+	return &SignedChallenge{
+		SessionID: challenge.SessionID,
+		Nonce:     challenge.Value,
+		Aud:       challenge.Audience,
+		Domain:    challenge.Domain,
+		Iat:       challenge.IssuedAt.Unix(),
+		Exp:       challenge.ExpiresAt.Unix(),
+	}, nil
+}
+
+// SignChallenge answers c as the holder with a compact JWS of type TokenType. kid is the DID, or the DID URL
+// of the authentication key pvKey belongs to when the DID has several.
+func SignChallenge(c *SignedChallenge, kid string, pvKey crypto.PrivateKey) (string, error) {
+	if c == nil || c.Nonce == "" || c.Aud == "" {
+		return "", derrors.New(derrors.CodeInvalidInput, "challenge nonce and aud are required")
+	}
+	did := didOf(kid)
+	if !strings.HasPrefix(did, "did:") {
+		return "", derrors.New(derrors.CodeInvalidInput, "kid must be a DID or DID URL")
+	}
+	claims := jwt.MapClaims{
+		"iss":    did,
+		"aud":    c.Aud,
+		"nonce":  c.Nonce,
+		"iat":    time.Now().Unix(),
+		"exp":    c.Exp,
+		"domain": c.Domain,
+	}
+	response, err := byd50_jwt.SignTyped(kid, TokenType, claims, pvKey)
+	if err != nil {
+		return "", derrors.Wrap(derrors.CodeInternal, "failed to sign challenge", err)
+	}
+	return response, nil
+}
+
+// Verify checks the signed response of did to the challenge of sessionID. The challenge is consumed by the
+// first attempt, successful or not.
+func (a *Authenticator) Verify(sessionID, did, response string) error {
+	challenge, err := a.Challenges.take(sessionID, did)
+	if err != nil {
+		return err
+	}
+	claims, err := byd50_jwt.ParseTyped(response, TokenType, a.GetPbKey)
+	if err != nil {
+		return derrors.Wrap(derrors.CodeInvalidInput, "signed challenge invalid", err)
+	}
+	if kid, err := core.GetSigner(response); err != nil || didOf(kid) != did {
+		return derrors.New(derrors.CodeInvalidInput, "signed challenge is not signed by a key of the did")
+	}
+	mapClaims := byd50_jwt.MapClaims(claims)
+	if iss, _ := mapClaims["iss"].(string); iss != did {
+		return derrors.New(derrors.CodeInvalidInput, "signed challenge iss mismatch")
+	}
+	if audiences, _ := mapClaims.GetAudience(); !core.Contains(audiences, challenge.Audience) {
+		return derrors.New(derrors.CodeInvalidInput, "signed challenge aud mismatch")
+	}
+	if domain, _ := mapClaims["domain"].(string); domain != challenge.Domain {
+		return derrors.New(derrors.CodeInvalidInput, "signed challenge domain mismatch")
+	}
+	nonce, _ := mapClaims["nonce"].(string)
+	if subtle.ConstantTimeCompare([]byte(nonce), []byte(challenge.Value)) != 1 {
+		return derrors.New(derrors.CodeInvalidInput, "signed challenge nonce mismatch")
+	}
+	skew := a.ClockSkew
+	if skew <= 0 {
+		skew = DefaultClockSkew
+	}
+	iat, err := mapClaims.GetIssuedAt()
+	if err != nil || time.Unix(iat, 0).Before(challenge.IssuedAt.Add(-skew)) {
+		return derrors.New(derrors.CodeInvalidInput, "signed challenge iat is missing or predates the challenge")
+	}
+	return nil
+}
+
+// didOf returns the DID of a DID URL.
+func didOf(kid string) string {
+	if i := strings.Index(kid, "#"); i >= 0 {
+		return kid[:i]
+	}
+	return kid
+}
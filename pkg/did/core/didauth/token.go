@@ -0,0 +1,246 @@
+package didauth
+
+import (
+	"byd50-ssi/pkg/did/core"
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	derrors "byd50-ssi/pkg/did/errors"
+	"crypto"
+	"errors"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	// AuthTokenType is the typ header of DID auth tokens.
+	AuthTokenType = "didauth-token+jwt"
+	// DefaultMaxTokenLifetime bounds exp - iat of DID auth tokens when TokenVerifier.MaxLifetime is zero.
+	DefaultMaxTokenLifetime = 5 * time.Minute
+	// DefaultLegacyWindow is how old a legacy simple presentation may be when TokenVerifier.LegacyWindow is zero.
+	DefaultLegacyWindow = 10 * time.Second
+)
+
+// TokenErrorCode classifies why a DID auth token was rejected.
+type TokenErrorCode string
+
+const (
+	TokenMalformed        TokenErrorCode = "malformed"
+	TokenInvalidSignature TokenErrorCode = "invalid_signature"
+	TokenInvalidIssuer    TokenErrorCode = "invalid_issuer"
+	TokenInvalidAudience  TokenErrorCode = "invalid_audience"
+	TokenInvalidLifetime  TokenErrorCode = "invalid_lifetime"
+	TokenExpired          TokenErrorCode = "expired"
+	TokenNotYetValid      TokenErrorCode = "not_yet_valid"
+	TokenReplayed         TokenErrorCode = "replayed"
+	TokenRevoked          TokenErrorCode = "revoked"
+	TokenInvalidProof     TokenErrorCode = "invalid_dpop_proof"
+)
+
+// TokenError is the typed result of a rejected DID auth token, legacy simple presentation, access token or
+// DPoP proof.
+type TokenError struct {
+	Code    TokenErrorCode
+	Message string
+}
+
+func (e *TokenError) Error() string {
+	return string(e.Code) + ": " + e.Message
+}
+
+func tokenError(code TokenErrorCode, message string) *TokenError {
+	return &TokenError{Code: code, Message: message}
+}
+
+// TokenErrorCodeOf returns the code of a TokenError in err's chain, or "" when there is none.
+func TokenErrorCodeOf(err error) TokenErrorCode {
+	var tokenErr *TokenError
+	if errors.As(err, &tokenErr) {
+		return tokenErr.Code
+	}
+	return ""
+}
+
+// CreateToken returns a DID auth token: a compact JWS of type AuthTokenType in which the DID of kid
+// authenticates to the relying party aud, with a fresh nonce and valid for validity. kid is the DID, or the
+// DID URL of the authentication key pvKey belongs to.
+func CreateToken(kid, aud string, validity time.Duration, pvKey crypto.PrivateKey) (string, error) {
+	did := didOf(kid)
+	if !strings.HasPrefix(did, "did:") || aud == "" {
+		return "", derrors.New(derrors.CodeInvalidInput, "a DID kid and aud are required")
+	}
+	nonce, err := randomToken()
+	if err != nil {
+		return "", err
+	}
+	now := time.Now()
+	token, err := byd50_jwt.SignTyped(kid, AuthTokenType, jwt.MapClaims{
+		"iss":   did,
+		"aud":   aud,
+		"nonce": nonce,
+		"iat":   now.Unix(),
+		"exp":   now.Add(validity).Unix(),
+	}, pvKey)
+	if err != nil {
+		return "", derrors.Wrap(derrors.CodeInternal, "failed to sign token", err)
+	}
+	return token, nil
+}
+
+// TokenVerifier verifies DID auth tokens presented to the relying party Audience. Each token is accepted
+// once: the nonces of accepted tokens are remembered in memory until the tokens expire.
+type TokenVerifier struct {
+	Audience string
+	// GetPbKey resolves the authentication key of a DID; the key id is the kid of the token when it is a
+	// DID URL and empty otherwise.
+	GetPbKey func(string, string) string
+	// ClockSkew is the tolerance for iat, nbf and exp between holder and relying party clocks.
+	ClockSkew    time.Duration
+	MaxLifetime  time.Duration
+	LegacyWindow time.Duration
+
+	mu   sync.Mutex
+	seen map[string]time.Time
+}
+
+// Verify checks a DID auth token and returns the DID it authenticates. Failures are *TokenError.
+func (v *TokenVerifier) Verify(token string) (string, error) {
+	if strings.Count(token, ".") != 2 {
+		return "", tokenError(TokenMalformed, "not a compact JWS")
+	}
+	claims, err := byd50_jwt.VerifyTypedSignature(token, AuthTokenType, v.GetPbKey)
+	if err != nil {
+		var validationErr *jwt.ValidationError
+		if errors.As(err, &validationErr) && validationErr.Errors&jwt.ValidationErrorMalformed != 0 {
+			return "", tokenError(TokenMalformed, err.Error())
+		}
+		return "", tokenError(TokenInvalidSignature, err.Error())
+	}
+	mapClaims := byd50_jwt.MapClaims(claims)
+	kid, _ := core.GetSigner(token)
+	did := didOf(kid)
+	if iss, _ := mapClaims["iss"].(string); !strings.HasPrefix(did, "did:") || iss != did {
+		return "", tokenError(TokenInvalidIssuer, "token is not issued by the DID of its signing key")
+	}
+	if audiences, _ := mapClaims.GetAudience(); v.Audience == "" || !core.Contains(audiences, v.Audience) {
+		return "", tokenError(TokenInvalidAudience, "token is not for this relying party")
+	}
+	nonce, _ := mapClaims["nonce"].(string)
+	if nonce == "" {
+		return "", tokenError(TokenMalformed, "nonce is missing")
+	}
+	exp, errExp := mapClaims.GetExpiresAt()
+	iat, errIat := mapClaims.GetIssuedAt()
+	if errExp != nil || errIat != nil {
+		return "", tokenError(TokenMalformed, "iat and exp are required")
+	}
+	maxLifetime := v.MaxLifetime
+	if maxLifetime <= 0 {
+		maxLifetime = DefaultMaxTokenLifetime
+	}
+	if exp < iat || time.Duration(exp-iat)*time.Second > maxLifetime {
+		return "", tokenError(TokenInvalidLifetime, "token lifetime exceeds "+maxLifetime.String())
+	}
+
+	now := time.Now()
+	skew := v.clockSkew()
+	if now.After(time.Unix(exp, 0).Add(skew)) {
+		return "", tokenError(TokenExpired, "token expired")
+	}
+	notBefore := iat
+	if nbf, err := mapClaims.GetNotBefore(); err == nil && nbf > notBefore {
+		notBefore = nbf
+	}
+	if time.Unix(notBefore, 0).After(now.Add(skew)) {
+		return "", tokenError(TokenNotYetValid, "token is not valid yet")
+	}
+	if err := v.markUsed(did+"|"+nonce, time.Unix(exp, 0).Add(skew)); err != nil {
+		return "", err
+	}
+	return did, nil
+}
+
+// LegacySimplePresent is the legacy "did;time;signature" simple presentation: an RSA PKCS#1 v1.5 signature
+// over "did;time" with time in RFC 3339.
+type LegacySimplePresent struct {
+	Did       string
+	Time      time.Time
+	Signature string
+	// Message is the signed "did;time".
+	Message string
+}
+
+// ParseLegacySimplePresent splits a legacy simple presentation without verifying it.
+func ParseLegacySimplePresent(simplePresent string) (*LegacySimplePresent, error) {
+	parts := strings.Split(simplePresent, ";")
+	if len(parts) != 3 || !strings.HasPrefix(parts[0], "did:") || parts[2] == "" {
+		return nil, tokenError(TokenMalformed, "expected did;time;signature")
+	}
+	presentTime, err := time.Parse(time.RFC3339, parts[1])
+	if err != nil {
+		return nil, tokenError(TokenMalformed, "invalid time: "+err.Error())
+	}
+	return &LegacySimplePresent{
+		Did:       parts[0],
+		Time:      presentTime,
+		Signature: parts[2],
+		Message:   parts[0] + ";" + parts[1],
+	}, nil
+}
+
+// VerifyLegacy checks a legacy simple presentation and returns its DID. The presentation has no audience or
+// nonce, so it is only accepted within LegacyWindow of its time and once; ClockSkew only applies to times
+// in the future. Failures are *TokenError.
+func (v *TokenVerifier) VerifyLegacy(simplePresent string) (string, error) {
+	legacy, err := ParseLegacySimplePresent(simplePresent)
+	if err != nil {
+		return "", err
+	}
+	window := v.LegacyWindow
+	if window <= 0 {
+		window = DefaultLegacyWindow
+	}
+	age := time.Since(legacy.Time)
+	if age > window {
+		return "", tokenError(TokenExpired, "simple presentation is older than "+window.String())
+	}
+	if age < -v.clockSkew() {
+		return "", tokenError(TokenNotYetValid, "simple presentation is dated in the future")
+	}
+	pbKeyBase58 := v.GetPbKey(legacy.Did, "")
+	if pbKeyBase58 == "" || !core.PbKeyVerify(pbKeyBase58, legacy.Message, legacy.Signature) {
+		return "", tokenError(TokenInvalidSignature, "simple presentation signature invalid")
+	}
+	if err := v.markUsed("legacy|"+legacy.Signature, legacy.Time.Add(window)); err != nil {
+		return "", err
+	}
+	return legacy.Did, nil
+}
+
+func (v *TokenVerifier) clockSkew() time.Duration {
+	if v.ClockSkew <= 0 {
+		return DefaultClockSkew
+	}
+	return v.ClockSkew
+}
+
+// markUsed records key until expiresAt, failing when it was recorded before.
+func (v *TokenVerifier) markUsed(key string, expiresAt time.Time) error {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	now := time.Now()
+	if v.seen == nil {
+		v.seen = map[string]time.Time{}
+	}
+	for k, until := range v.seen {
+		if now.After(until) {
+			delete(v.seen, k)
+		}
+	}
+	if _, ok := v.seen[key]; ok {
+		return tokenError(TokenReplayed, "token was already used")
+	}
+	v.seen[key] = expiresAt
+	return nil
+}
@@ -2,6 +2,7 @@ package dids
 
 import (
 	"byd50-ssi/pkg/did/configs"
+	"byd50-ssi/pkg/keys"
 	"encoding/json"
 	"strings"
 	"testing"
@@ -59,3 +60,313 @@ func TestResolutionErrorCodeString(t *testing.T) {
 		t.Fatal("invalid resolution error string")
 	}
 }
+
+func TestVerificationMethodTypeDeclared(t *testing.T) {
+	_, edPub, err := keys.GenerateEd25519KeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	_, doc := CreateDID("byd50", keys.ExportPublicKeyAsBase58(edPub))
+	var parsed DocumentInterface
+	if err := json.Unmarshal(doc, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	vm, err := parsed.FindVerificationMethod(Authentication, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vm.Types != Ed25519VerificationKey2020 || vm.PublicKeyMultibase == "" {
+		t.Fatalf("authentication key type not declared: %+v", vm)
+	}
+}
+
+func TestVerificationRelationships(t *testing.T) {
+	_, edPub, err := keys.GenerateEd25519KeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	did, doc := CreateDID("byd50", keys.ExportPublicKeyAsBase58(edPub))
+
+	// Relationships reference the initial verification method by DID URL.
+	var raw map[string]interface{}
+	if err := json.Unmarshal(doc, &raw); err != nil {
+		t.Fatal(err)
+	}
+	for _, relationship := range []string{Authentication, AssertionMethod, CapabilityInvocation, CapabilityDelegation} {
+		entries, _ := raw[relationship].([]interface{})
+		if len(entries) != 1 || entries[0] != did+"#keys-1" {
+			t.Fatalf("%s is not a reference to keys-1: %v", relationship, raw[relationship])
+		}
+	}
+	if _, ok := raw[KeyAgreement]; ok {
+		t.Fatal("signing key must not be published for keyAgreement")
+	}
+
+	var parsed DocumentInterface
+	if err := json.Unmarshal(doc, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	for _, keyId := range []string{"", "keys-1", "#keys-1", did + "#keys-1"} {
+		vm, err := parsed.FindVerificationMethod(AssertionMethod, keyId)
+		if err != nil || vm.ID != did+"#keys-1" {
+			t.Fatalf("lookup %q failed: %v", keyId, err)
+		}
+	}
+	if _, err := parsed.FindVerificationMethod(AssertionMethod, "keys-2"); err != ErrVerificationMethodNotFound {
+		t.Fatalf("expected ErrVerificationMethodNotFound, got %v", err)
+	}
+	if _, err := parsed.FindVerificationMethod(KeyAgreement, ""); err != ErrVerificationMethodNotFound {
+		t.Fatalf("expected ErrVerificationMethodNotFound, got %v", err)
+	}
+	if _, err := parsed.FindVerificationMethod("unknown", ""); err != ErrUnknownRelationship {
+		t.Fatalf("expected ErrUnknownRelationship, got %v", err)
+	}
+
+	// Embedded methods and relative references are resolved as well.
+	embedded, err := NewVerificationMethod(did+"#keys-2", did, Multikey, keys.ExportPublicKeyAsBase58(edPub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed.KeyAgreement = []VerificationRelationship{VerificationRelationship(embedded)}
+	parsed.CapabilityInvocation = []VerificationRelationship{ReferenceTo("#keys-1")}
+	data, err := json.Marshal(parsed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var reparsed DocumentInterface
+	if err := json.Unmarshal(data, &reparsed); err != nil {
+		t.Fatal(err)
+	}
+	if vm, err := reparsed.FindVerificationMethod(KeyAgreement, "keys-2"); err != nil || vm.Types != Multikey {
+		t.Fatalf("embedded lookup failed: %+v %v", vm, err)
+	}
+	if vm, err := reparsed.FindVerificationMethod(CapabilityInvocation, ""); err != nil || vm.ID != did+"#keys-1" {
+		t.Fatalf("relative reference lookup failed: %+v %v", vm, err)
+	}
+}
+
+func TestTypedVerificationMethods(t *testing.T) {
+	ecKey, _, err := keys.GenerateECDSAKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	k1Key, _, err := keys.GenerateSecp256k1KeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, edPub, err := keys.GenerateEd25519KeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, xPub, err := keys.GenerateX25519KeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ecPb := keys.ExportPublicKeyAsBase58(&ecKey.PublicKey)
+	k1Pb := keys.ExportPublicKeyAsBase58(&k1Key.PublicKey)
+	edPb := keys.ExportPublicKeyAsBase58(edPub)
+	xPb := keys.ExportPublicKeyAsBase58(xPub)
+
+	for _, tc := range []struct {
+		vmType      string
+		pbKeyBase58 string
+		wantType    string
+		wantJwk     bool
+	}{
+		{"", ecPb, JsonWebKey2020, true},
+		{"", k1Pb, EcdsaSecp256k1VerificationKey2019, true},
+		{"", edPb, Ed25519VerificationKey2020, false},
+		{Multikey, ecPb, Multikey, false},
+		{Multikey, k1Pb, Multikey, false},
+		{JsonWebKey2020, edPb, JsonWebKey2020, true},
+		{"", xPb, X25519KeyAgreementKey2020, false},
+		{JsonWebKey2020, xPb, JsonWebKey2020, true},
+	} {
+		vm, err := NewVerificationMethod("did:byd50:test#keys-1", "did:byd50:test", tc.vmType, tc.pbKeyBase58)
+		if err != nil {
+			t.Fatalf("%s: %v", tc.wantType, err)
+		}
+		if vm.Types != tc.wantType || (vm.PublicKeyJwk != nil) != tc.wantJwk || (vm.PublicKeyMultibase != "") == tc.wantJwk {
+			t.Fatalf("unexpected verification method: %+v", vm)
+		}
+
+		// The document must survive a json round trip and resolve to the same base58 key.
+		data, err := json.Marshal(vm)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if strings.Contains(string(data), "publicKeyBase58") {
+			t.Fatalf("typed verification method must not publish base58: %s", data)
+		}
+		var parsed VerificationMethodProperty
+		if err := json.Unmarshal(data, &parsed); err != nil {
+			t.Fatal(err)
+		}
+		got, err := parsed.PublicKeyAsBase58()
+		if err != nil || got != tc.pbKeyBase58 {
+			t.Fatalf("%s: key mismatch: %v", tc.wantType, err)
+		}
+	}
+
+	if _, err := NewVerificationMethod("id", "did", Ed25519VerificationKey2020, ecPb); err == nil {
+		t.Fatal("expected Ed25519VerificationKey2020 to reject a P-256 key")
+	}
+	if _, err := NewVerificationMethod("id", "did", EcdsaSecp256k1VerificationKey2019, ecPb); err == nil {
+		t.Fatal("expected EcdsaSecp256k1VerificationKey2019 to reject a P-256 key")
+	}
+	if _, err := NewVerificationMethod("id", "did", X25519KeyAgreementKey2020, edPb); err == nil {
+		t.Fatal("expected X25519KeyAgreementKey2020 to reject an ed25519 key")
+	}
+	if _, err := NewVerificationMethod("id", "did", "UnknownKey2099", ecPb); err == nil {
+		t.Fatal("expected unsupported type error")
+	}
+}
+
+func TestLegacyBase58Document(t *testing.T) {
+	ecKey, _, err := keys.GenerateECDSAKeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	pbKeyBase58 := keys.ExportPublicKeyAsBase58(&ecKey.PublicKey)
+	legacy := `{"@context":["https://www.w3.org/ns/dids/v1"],"id":"did:byd50:legacy","authentication":[` +
+		`{"id":"did:byd50:legacy#keys-1","type":"","controller":"did:byd50:legacy","publicKeyBase58":"` + pbKeyBase58 + `"}]}`
+
+	var doc DocumentInterface
+	if err := json.Unmarshal([]byte(legacy), &doc); err != nil {
+		t.Fatal(err)
+	}
+	got, err := doc.Authentication[0].PublicKeyAsBase58()
+	if err != nil || got != pbKeyBase58 {
+		t.Fatalf("legacy key not read: %v", err)
+	}
+	pbKey, err := doc.Authentication[0].PublicKey()
+	if err != nil || !ecKey.PublicKey.Equal(pbKey) {
+		t.Fatalf("legacy key mismatch: %v", err)
+	}
+
+	if _, err := (AuthenticationProperty{}).PublicKeyAsBase58(); err != ErrNoPublicKey {
+		t.Fatalf("expected ErrNoPublicKey, got %v", err)
+	}
+
+	// Adding a key agreement key keeps the authentication key in use for the other relationships.
+	_, xPub, err := keys.GenerateX25519KeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm, err := NewVerificationMethod("#key-agreement-1", "did:byd50:legacy", "", keys.ExportPublicKeyAsBase58(xPub))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.AddVerificationMethod(vm, KeyAgreement); err != nil {
+		t.Fatal(err)
+	}
+	if found, err := doc.FindVerificationMethod(KeyAgreement, ""); err != nil || found.ID != "did:byd50:legacy#key-agreement-1" {
+		t.Fatalf("key agreement key not found: %+v %v", found, err)
+	}
+	if found, err := doc.FindVerificationMethod(CapabilityInvocation, ""); err != nil || found.ID != "did:byd50:legacy#keys-1" {
+		t.Fatalf("legacy key lost for capabilityInvocation: %+v %v", found, err)
+	}
+	if err := doc.AddVerificationMethod(vm, KeyAgreement); err != ErrVerificationMethodExists {
+		t.Fatalf("expected ErrVerificationMethodExists, got %v", err)
+	}
+	if err := doc.AddVerificationMethod(VerificationMethodProperty{ID: "#key-2"}, KeyAgreement); err != ErrNoPublicKey {
+		t.Fatalf("expected ErrNoPublicKey, got %v", err)
+	}
+	if err := doc.RemoveVerificationMethod("key-agreement-1"); err != nil {
+		t.Fatal(err)
+	}
+	if err := doc.RemoveVerificationMethod("#key-agreement-1"); err != ErrVerificationMethodNotFound {
+		t.Fatalf("expected ErrVerificationMethodNotFound, got %v", err)
+	}
+	if _, err := doc.FindVerificationMethod(KeyAgreement, ""); err != ErrVerificationMethodNotFound {
+		t.Fatalf("expected the key agreement key to be removed, got %v", err)
+	}
+}
+
+func TestServiceEndpoints(t *testing.T) {
+	doc := DocumentInterface{ID: "did:byd50:svc"}
+	services := []ServiceProperty{
+		{ID: "#linked-domain", Types: ServiceTypes{ServiceTypeLinkedDomains}, ServiceEndpoint: EndpointURI("https://issuer.example")},
+		{ID: "#registry", Types: ServiceTypes{ServiceTypeCredentialRegistry, "LinkedDomains"},
+			ServiceEndpoint: EndpointURIs("https://a.example/registry", "https://b.example/registry")},
+		{ID: "#didcomm", Types: ServiceTypes{ServiceTypeDIDCommMessaging}, ServiceEndpoint: EndpointMap(map[string]interface{}{
+			"uri":    "https://issuer.example/didcomm",
+			"accept": []interface{}{"didcomm/v2"},
+		})},
+	}
+	for _, service := range services {
+		if err := doc.AddService(service); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := doc.AddService(services[0]); err != ErrServiceExists {
+		t.Fatalf("expected ErrServiceExists, got %v", err)
+	}
+	if err := doc.AddService(ServiceProperty{ID: "#empty", Types: ServiceTypes{ServiceTypeLinkedDomains}}); err != ErrInvalidService {
+		t.Fatalf("expected ErrInvalidService, got %v", err)
+	}
+	moved := ServiceProperty{ID: "#moved", Types: ServiceTypes{ServiceTypeLinkedDomains}}
+	for _, uri := range []string{"https://old.example", "https://new.example"} {
+		moved.ServiceEndpoint = EndpointURI(uri)
+		if err := doc.ReplaceService(moved); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if replaced, err := doc.FindService("#moved"); err != nil || replaced.ServiceEndpoint.URI != "https://new.example" || len(doc.Service) != 4 {
+		t.Fatalf("expected the service to be replaced, got %+v %v", replaced, err)
+	}
+	if err := doc.RemoveService("#moved"); err != nil {
+		t.Fatal(err)
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw struct {
+		Service []map[string]interface{} `json:"service"`
+	}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw.Service[0]["id"] != "did:byd50:svc#linked-domain" || raw.Service[0]["type"] != ServiceTypeLinkedDomains ||
+		raw.Service[0]["serviceEndpoint"] != "https://issuer.example" {
+		t.Fatalf("unexpected string endpoint: %v", raw.Service[0])
+	}
+	if _, ok := raw.Service[1]["type"].([]interface{}); !ok {
+		t.Fatalf("expected type array: %v", raw.Service[1])
+	}
+	if _, ok := raw.Service[1]["serviceEndpoint"].([]interface{}); !ok {
+		t.Fatalf("expected endpoint set: %v", raw.Service[1])
+	}
+	if _, ok := raw.Service[2]["serviceEndpoint"].(map[string]interface{}); !ok {
+		t.Fatalf("expected endpoint map: %v", raw.Service[2])
+	}
+
+	var parsed DocumentInterface
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		t.Fatal(err)
+	}
+	if got := parsed.ServicesByType(ServiceTypeLinkedDomains); len(got) != 2 {
+		t.Fatalf("unexpected LinkedDomains services: %v", got)
+	}
+	registry, err := parsed.FindService("#registry")
+	if err != nil || strings.Join(registry.ServiceEndpoint.URIs(), ",") != "https://a.example/registry,https://b.example/registry" {
+		t.Fatalf("unexpected registry service: %+v %v", registry, err)
+	}
+	didcomm, err := parsed.FindService("did:byd50:svc#didcomm")
+	if err != nil || didcomm.ServiceEndpoint.URIs()[0] != "https://issuer.example/didcomm" {
+		t.Fatalf("unexpected didcomm service: %+v %v", didcomm, err)
+	}
+
+	if err := parsed.RemoveService("#registry"); err != nil {
+		t.Fatal(err)
+	}
+	if err := parsed.RemoveService("#registry"); err != ErrServiceNotFound {
+		t.Fatalf("expected ErrServiceNotFound, got %v", err)
+	}
+	if len(parsed.Service) != 2 {
+		t.Fatalf("unexpected services after remove: %v", parsed.Service)
+	}
+}
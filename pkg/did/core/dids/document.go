@@ -3,6 +3,7 @@ package dids
 import (
 	"byd50-ssi/pkg/did/configs"
 	"byd50-ssi/pkg/did/pkg/logger"
+	"byd50-ssi/pkg/keys"
 	"crypto/rand"
 	"crypto/sha256"
 	"encoding/hex"
@@ -39,27 +40,48 @@ type DocumentInterface struct {
 	// The verificationMethod property is OPTIONAL.
 	// If present, the value MUST be a set of verification methods, where each verification method is expressed using a map.
 	VerificationMethod []VerificationMethodProperty `json:"verificationMethod"`
+
+	// The assertionMethod property is OPTIONAL. Keys used to issue verifiable credentials.
+	AssertionMethod []VerificationRelationship `json:"assertionMethod,omitempty"`
+
+	// The keyAgreement property is OPTIONAL. Keys used to encrypt information to the DID subject.
+	KeyAgreement []VerificationRelationship `json:"keyAgreement,omitempty"`
+
+	// The capabilityInvocation property is OPTIONAL. Keys used to invoke capabilities, e.g. to update the DID document.
+	CapabilityInvocation []VerificationRelationship `json:"capabilityInvocation,omitempty"`
+
+	// The capabilityDelegation property is OPTIONAL. Keys used to delegate capabilities to another party.
+	CapabilityDelegation []VerificationRelationship `json:"capabilityDelegation,omitempty"`
 }
 
 /**
  * This corresponds to the authentications property of the DIDs specification.
  * www.w3.org/TR/dids-core/#authentication
  */
-type AuthenticationProperty struct {
-	ID              string `json:"id"`
-	Types           string `json:"type"`
-	Controller      string `json:"controller"`
-	PublicKeyBase58 string `json:"publicKeyBase58"`
+type AuthenticationProperty = VerificationRelationship
+
+/**
+ * An entry of a verification relationship (authentication, assertionMethod, keyAgreement, ...).
+ * It embeds a verification method, or references one by DID URL when only ID is set.
+ * www.w3.org/TR/dids-core/#verification-relationships
+ */
+type VerificationRelationship struct {
+	ID                 string    `json:"id"`
+	Types              string    `json:"type"`
+	Controller         string    `json:"controller"`
+	PublicKeyBase58    string    `json:"publicKeyBase58,omitempty"`
+	PublicKeyJwk       *keys.JWK `json:"publicKeyJwk,omitempty"`
+	PublicKeyMultibase string    `json:"publicKeyMultibase,omitempty"`
 }
 
 /**
  * A network address, such as an HTTP URL, at which services operate on behalf of a DID subject.
- * www.w3.org/TR/dids-core/
+ * www.w3.org/TR/dids-core/#services
  */
 type ServiceProperty struct {
-	ID              string `json:"id"`
-	Types           string `json:"type"`
-	ServiceEndpoint string `json:"serviceEndpoint"`
+	ID              string          `json:"id"`
+	Types           ServiceTypes    `json:"type"`
+	ServiceEndpoint ServiceEndpoint `json:"serviceEndpoint"`
 }
 
 /**
@@ -67,10 +89,12 @@ type ServiceProperty struct {
  * www.w3.org/TR/dids-core/#verification-methods
  */
 type VerificationMethodProperty struct {
-	ID              string `json:"id"`
-	Types           string `json:"type"`
-	Controller      string `json:"controller"`
-	PublicKeyBase58 string `json:"publicKeyBase58"`
+	ID                 string    `json:"id"`
+	Types              string    `json:"type"`
+	Controller         string    `json:"controller"`
+	PublicKeyBase58    string    `json:"publicKeyBase58,omitempty"`
+	PublicKeyJwk       *keys.JWK `json:"publicKeyJwk,omitempty"`
+	PublicKeyMultibase string    `json:"publicKeyMultibase,omitempty"`
 }
 
 func CreateDID(method, pbKey string) (string, []byte) {
@@ -88,13 +112,18 @@ func initDocument(did, pbKey string) ([]byte, error) {
 	ifDoc.Context = []string{"https://www.w3.org/ns/dids/v1"}
 	ifDoc.ID = did
 	initialAuthKeyId := did + "#keys-1"
-	ifDoc.Authentication = []AuthenticationProperty{
-		{
-			ID:              initialAuthKeyId,
-			Controller:      did,
-			PublicKeyBase58: pbKey,
-		},
+	vm, err := NewVerificationMethod(initialAuthKeyId, did, "", pbKey)
+	if err != nil {
+		// Keys this package can't parse are published as given, without a type.
+		log.Printf("[initDocument] - untyped key: %v\n", err)
+		vm = VerificationMethodProperty{ID: initialAuthKeyId, Controller: did, PublicKeyBase58: pbKey}
 	}
+	// The initial key is the signing key of every relationship except keyAgreement.
+	ifDoc.VerificationMethod = []VerificationMethodProperty{vm}
+	ifDoc.Authentication = []AuthenticationProperty{ReferenceTo(vm.ID)}
+	ifDoc.AssertionMethod = []VerificationRelationship{ReferenceTo(vm.ID)}
+	ifDoc.CapabilityInvocation = []VerificationRelationship{ReferenceTo(vm.ID)}
+	ifDoc.CapabilityDelegation = []VerificationRelationship{ReferenceTo(vm.ID)}
 	bytes, err := json.MarshalIndent(ifDoc, "", " ")
 	log.Printf("[initDocument] - document.ID(%v)\n", ifDoc.ID)
 	return bytes, err
@@ -0,0 +1,231 @@
+package dids
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Verification relationships of a DID document.
+// www.w3.org/TR/dids-core/#verification-relationships
+const (
+	Authentication       = "authentication"
+	AssertionMethod      = "assertionMethod"
+	KeyAgreement         = "keyAgreement"
+	CapabilityInvocation = "capabilityInvocation"
+	CapabilityDelegation = "capabilityDelegation"
+)
+
+// relationships lists all verification relationships.
+var relationships = []string{Authentication, AssertionMethod, KeyAgreement, CapabilityInvocation, CapabilityDelegation}
+
+var (
+	// ErrUnknownRelationship is returned for a relationship name not listed above.
+	ErrUnknownRelationship = errors.New("unknown verification relationship")
+	// ErrVerificationMethodNotFound is returned when no verification method of the relationship matches.
+	ErrVerificationMethodNotFound = errors.New("verification method not found in relationship")
+	// ErrVerificationMethodExists is returned when adding a verification method whose id is already used.
+	ErrVerificationMethodExists = errors.New("verification method already exists")
+)
+
+// ReferenceTo returns a relationship entry referencing the verification method with the given DID URL.
+func ReferenceTo(id string) VerificationRelationship {
+	return VerificationRelationship{ID: id}
+}
+
+// IsReference reports whether the entry references a verification method instead of embedding one.
+func (r VerificationRelationship) IsReference() bool {
+	return r.Types == "" && r.Controller == "" &&
+		r.PublicKeyBase58 == "" && r.PublicKeyJwk == nil && r.PublicKeyMultibase == ""
+}
+
+// MarshalJSON writes a reference as a DID URL string and an embedded method as a map.
+func (r VerificationRelationship) MarshalJSON() ([]byte, error) {
+	if r.IsReference() && r.ID != "" {
+		return json.Marshal(r.ID)
+	}
+	type embedded VerificationRelationship
+	return json.Marshal(embedded(r))
+}
+
+// UnmarshalJSON reads either form of a relationship entry.
+func (r *VerificationRelationship) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err == nil {
+		*r = ReferenceTo(id)
+		return nil
+	}
+	type embedded VerificationRelationship
+	var vm embedded
+	if err := json.Unmarshal(data, &vm); err != nil {
+		return err
+	}
+	*r = VerificationRelationship(vm)
+	return nil
+}
+
+// Relationship returns the entries of the named verification relationship.
+func (doc DocumentInterface) Relationship(relationship string) ([]VerificationRelationship, error) {
+	switch relationship {
+	case Authentication:
+		return doc.Authentication, nil
+	case AssertionMethod:
+		return doc.AssertionMethod, nil
+	case KeyAgreement:
+		return doc.KeyAgreement, nil
+	case CapabilityInvocation:
+		return doc.CapabilityInvocation, nil
+	case CapabilityDelegation:
+		return doc.CapabilityDelegation, nil
+	}
+	return nil, ErrUnknownRelationship
+}
+
+// FindVerificationMethod returns the verification method of the relationship identified by keyId,
+// resolving references against verificationMethod. keyId may be a DID URL, a fragment ("keys-1" or
+// "#keys-1") or empty for the first method of the relationship.
+//
+// Documents created before verification relationships were published only embed a key in authentication,
+// which was used for every purpose; for them that key is also returned for assertionMethod and the
+// capability relationships.
+func (doc DocumentInterface) FindVerificationMethod(relationship, keyId string) (VerificationMethodProperty, error) {
+	entries, err := doc.Relationship(relationship)
+	if err != nil {
+		return VerificationMethodProperty{}, err
+	}
+	if doc.isLegacy() && relationship != KeyAgreement {
+		entries = doc.Authentication
+	}
+	for _, entry := range entries {
+		if keyId != "" && !doc.matchesKeyId(entry.ID, keyId) {
+			continue
+		}
+		if !entry.IsReference() {
+			return VerificationMethodProperty(entry), nil
+		}
+		for _, vm := range doc.VerificationMethod {
+			if doc.absoluteDidUrl(vm.ID) == doc.absoluteDidUrl(entry.ID) {
+				return vm, nil
+			}
+		}
+	}
+	return VerificationMethodProperty{}, ErrVerificationMethodNotFound
+}
+
+// AddVerificationMethod adds vm to verificationMethod and references it from the given relationships, e.g. a
+// key agreement key from keyAgreement. A relative id ("#name") is expanded with the document id.
+//
+// The authentication key of a legacy document (see FindVerificationMethod) is first listed in assertionMethod
+// and the capability relationships, so that it keeps being used for them.
+func (doc *DocumentInterface) AddVerificationMethod(vm VerificationMethodProperty, relationshipNames ...string) error {
+	if vm.ID == "" || (vm.PublicKeyJwk == nil && vm.PublicKeyMultibase == "" && vm.PublicKeyBase58 == "") {
+		return ErrNoPublicKey
+	}
+	vm.ID = doc.absoluteDidUrl(vm.ID)
+	if doc.hasVerificationMethod(vm.ID) {
+		return ErrVerificationMethodExists
+	}
+	for _, relationship := range relationshipNames {
+		if _, err := doc.Relationship(relationship); err != nil {
+			return err
+		}
+	}
+	if doc.isLegacy() {
+		doc.AssertionMethod = append([]VerificationRelationship(nil), doc.Authentication...)
+		doc.CapabilityInvocation = append([]VerificationRelationship(nil), doc.Authentication...)
+		doc.CapabilityDelegation = append([]VerificationRelationship(nil), doc.Authentication...)
+	}
+	doc.VerificationMethod = append(doc.VerificationMethod, vm)
+	for _, relationship := range relationshipNames {
+		entries := doc.relationshipEntries(relationship)
+		*entries = append(*entries, ReferenceTo(vm.ID))
+	}
+	return nil
+}
+
+// RemoveVerificationMethod removes the verification method keyId (a DID URL or a fragment, as for
+// FindVerificationMethod) from verificationMethod and from every relationship.
+func (doc *DocumentInterface) RemoveVerificationMethod(keyId string) error {
+	if !strings.Contains(keyId, "#") {
+		keyId = "#" + keyId
+	}
+	id := doc.absoluteDidUrl(keyId)
+	removed := false
+	methods := doc.VerificationMethod[:0]
+	for _, vm := range doc.VerificationMethod {
+		if doc.absoluteDidUrl(vm.ID) == id {
+			removed = true
+			continue
+		}
+		methods = append(methods, vm)
+	}
+	doc.VerificationMethod = methods
+	for _, relationship := range relationships {
+		entries := doc.relationshipEntries(relationship)
+		kept := (*entries)[:0]
+		for _, entry := range *entries {
+			if doc.absoluteDidUrl(entry.ID) == id {
+				removed = true
+				continue
+			}
+			kept = append(kept, entry)
+		}
+		*entries = kept
+	}
+	if !removed {
+		return ErrVerificationMethodNotFound
+	}
+	return nil
+}
+
+func (doc DocumentInterface) hasVerificationMethod(id string) bool {
+	for _, vm := range doc.VerificationMethod {
+		if doc.absoluteDidUrl(vm.ID) == id {
+			return true
+		}
+	}
+	for _, relationship := range relationships {
+		entries, _ := doc.Relationship(relationship)
+		for _, entry := range entries {
+			if doc.absoluteDidUrl(entry.ID) == id {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// relationshipEntries returns the field of a known relationship.
+func (doc *DocumentInterface) relationshipEntries(relationship string) *[]VerificationRelationship {
+	switch relationship {
+	case Authentication:
+		return &doc.Authentication
+	case AssertionMethod:
+		return &doc.AssertionMethod
+	case KeyAgreement:
+		return &doc.KeyAgreement
+	case CapabilityInvocation:
+		return &doc.CapabilityInvocation
+	}
+	return &doc.CapabilityDelegation
+}
+
+func (doc DocumentInterface) isLegacy() bool {
+	return len(doc.VerificationMethod) == 0 && len(doc.AssertionMethod) == 0 && len(doc.KeyAgreement) == 0 &&
+		len(doc.CapabilityInvocation) == 0 && len(doc.CapabilityDelegation) == 0
+}
+
+func (doc DocumentInterface) matchesKeyId(id, keyId string) bool {
+	if !strings.Contains(keyId, "#") {
+		keyId = "#" + keyId
+	}
+	return doc.absoluteDidUrl(id) == doc.absoluteDidUrl(keyId)
+}
+
+// absoluteDidUrl expands a relative DID URL ("#keys-1") against the document id.
+func (doc DocumentInterface) absoluteDidUrl(id string) string {
+	if strings.HasPrefix(id, "#") {
+		return doc.ID + id
+	}
+	return id
+}
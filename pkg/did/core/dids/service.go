@@ -0,0 +1,193 @@
+package dids
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+)
+
+// Service types published in DID documents.
+// www.w3.org/TR/did-spec-registries/#service-types
+const (
+	ServiceTypeDIDCommMessaging   = "DIDCommMessaging"
+	ServiceTypeLinkedDomains      = "LinkedDomains"
+	ServiceTypeCredentialRegistry = "CredentialRegistry"
+	// ServiceTypeGrpcService is a byd50 gRPC service; the endpoint map carries "uri" (host:port) and "service".
+	ServiceTypeGrpcService = "GrpcService"
+)
+
+var (
+	// ErrInvalidService is returned for a service without id, type or endpoint.
+	ErrInvalidService = errors.New("service requires id, type and serviceEndpoint")
+	// ErrServiceExists is returned when a service with the same id is already published.
+	ErrServiceExists = errors.New("service already exists")
+	// ErrServiceNotFound is returned when no service has the given id.
+	ErrServiceNotFound = errors.New("service not found")
+)
+
+// ServiceTypes is the type of a service: a single string or a set of strings.
+type ServiceTypes []string
+
+// MarshalJSON writes a single type as a string.
+func (t ServiceTypes) MarshalJSON() ([]byte, error) {
+	if len(t) == 1 {
+		return json.Marshal(t[0])
+	}
+	return json.Marshal([]string(t))
+}
+
+// UnmarshalJSON reads a string or a set of strings.
+func (t *ServiceTypes) UnmarshalJSON(data []byte) error {
+	var single string
+	if err := json.Unmarshal(data, &single); err == nil {
+		*t = ServiceTypes{single}
+		return nil
+	}
+	var set []string
+	if err := json.Unmarshal(data, &set); err != nil {
+		return err
+	}
+	*t = set
+	return nil
+}
+
+// Has reports whether typ is one of the types.
+func (t ServiceTypes) Has(typ string) bool {
+	for _, v := range t {
+		if v == typ {
+			return true
+		}
+	}
+	return false
+}
+
+// ServiceEndpoint is a serviceEndpoint value: a URI, a map (e.g. DIDComm {"uri", "accept", "routingKeys"})
+// or a set of URIs and maps. Exactly one of URI, Map and Set is used.
+type ServiceEndpoint struct {
+	URI string
+	Map map[string]interface{}
+	Set []ServiceEndpoint
+}
+
+// EndpointURI returns an endpoint with a single URI.
+func EndpointURI(uri string) ServiceEndpoint {
+	return ServiceEndpoint{URI: uri}
+}
+
+// EndpointURIs returns an endpoint with a set of URIs.
+func EndpointURIs(uris ...string) ServiceEndpoint {
+	set := make([]ServiceEndpoint, 0, len(uris))
+	for _, uri := range uris {
+		set = append(set, EndpointURI(uri))
+	}
+	return ServiceEndpoint{Set: set}
+}
+
+// EndpointMap returns an endpoint described by a map.
+func EndpointMap(m map[string]interface{}) ServiceEndpoint {
+	return ServiceEndpoint{Map: m}
+}
+
+// IsEmpty reports whether the endpoint has no value.
+func (e ServiceEndpoint) IsEmpty() bool {
+	return e.URI == "" && len(e.Map) == 0 && len(e.Set) == 0
+}
+
+// URIs returns every URI of the endpoint, including the "uri" entries of maps.
+func (e ServiceEndpoint) URIs() []string {
+	var uris []string
+	switch {
+	case e.URI != "":
+		uris = append(uris, e.URI)
+	case e.Map != nil:
+		if uri, ok := e.Map["uri"].(string); ok && uri != "" {
+			uris = append(uris, uri)
+		}
+	}
+	for _, item := range e.Set {
+		uris = append(uris, item.URIs()...)
+	}
+	return uris
+}
+
+// MarshalJSON writes the endpoint in its DID Core form.
+func (e ServiceEndpoint) MarshalJSON() ([]byte, error) {
+	switch {
+	case e.Map != nil:
+		return json.Marshal(e.Map)
+	case e.Set != nil:
+		return json.Marshal(e.Set)
+	}
+	return json.Marshal(e.URI)
+}
+
+// UnmarshalJSON reads a string, a map or a set of them.
+func (e *ServiceEndpoint) UnmarshalJSON(data []byte) error {
+	*e = ServiceEndpoint{}
+	trimmed := strings.TrimSpace(string(data))
+	switch {
+	case strings.HasPrefix(trimmed, "{"):
+		return json.Unmarshal(data, &e.Map)
+	case strings.HasPrefix(trimmed, "["):
+		return json.Unmarshal(data, &e.Set)
+	}
+	return json.Unmarshal(data, &e.URI)
+}
+
+// FindService returns the service with the given id ("#name" ids are relative to the document).
+func (doc DocumentInterface) FindService(id string) (ServiceProperty, error) {
+	for _, service := range doc.Service {
+		if doc.absoluteDidUrl(service.ID) == doc.absoluteDidUrl(id) {
+			return service, nil
+		}
+	}
+	return ServiceProperty{}, ErrServiceNotFound
+}
+
+// ServicesByType returns the services that have typ among their types.
+func (doc DocumentInterface) ServicesByType(typ string) []ServiceProperty {
+	var services []ServiceProperty
+	for _, service := range doc.Service {
+		if service.Types.Has(typ) {
+			services = append(services, service)
+		}
+	}
+	return services
+}
+
+// AddService appends a service to the document. A relative id ("#name") is expanded with the document id.
+func (doc *DocumentInterface) AddService(service ServiceProperty) error {
+	if service.ID == "" || len(service.Types) == 0 || service.ServiceEndpoint.IsEmpty() {
+		return ErrInvalidService
+	}
+	for _, typ := range service.Types {
+		if typ == "" {
+			return ErrInvalidService
+		}
+	}
+	service.ID = doc.absoluteDidUrl(service.ID)
+	if _, err := doc.FindService(service.ID); err == nil {
+		return ErrServiceExists
+	}
+	doc.Service = append(doc.Service, service)
+	return nil
+}
+
+// ReplaceService adds a service to the document, replacing the service that has the same id, if any.
+func (doc *DocumentInterface) ReplaceService(service ServiceProperty) error {
+	if err := doc.RemoveService(service.ID); err != nil && !errors.Is(err, ErrServiceNotFound) {
+		return err
+	}
+	return doc.AddService(service)
+}
+
+// RemoveService removes the service with the given id from the document.
+func (doc *DocumentInterface) RemoveService(id string) error {
+	for i, service := range doc.Service {
+		if doc.absoluteDidUrl(service.ID) == doc.absoluteDidUrl(id) {
+			doc.Service = append(doc.Service[:i], doc.Service[i+1:]...)
+			return nil
+		}
+	}
+	return ErrServiceNotFound
+}
@@ -0,0 +1,130 @@
+package dids
+
+import (
+	"byd50-ssi/pkg/keys"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"errors"
+)
+
+// Verification method types declared in DID documents.
+// www.w3.org/TR/did-spec-registries/#verification-method-types
+const (
+	EcdsaSecp256r1VerificationKey2019 = "EcdsaSecp256r1VerificationKey2019"
+	EcdsaSecp256k1VerificationKey2019 = "EcdsaSecp256k1VerificationKey2019"
+	Ed25519VerificationKey2018        = "Ed25519VerificationKey2018"
+	Ed25519VerificationKey2020        = "Ed25519VerificationKey2020"
+	RsaVerificationKey2018            = "RsaVerificationKey2018"
+	X25519KeyAgreementKey2020         = "X25519KeyAgreementKey2020"
+	JsonWebKey2020                    = "JsonWebKey2020"
+	Multikey                          = "Multikey"
+)
+
+// NewVerificationMethod builds a verification method for a base58 public key (see keys.ExportPublicKeyAsBase58).
+// An empty vmType picks the type by key: Ed25519VerificationKey2020 (publicKeyMultibase) for Ed25519,
+// X25519KeyAgreementKey2020 (publicKeyMultibase) for X25519, EcdsaSecp256k1VerificationKey2019 (publicKeyJwk)
+// for secp256k1 and JsonWebKey2020 (publicKeyJwk) otherwise.
+// Multikey publishes any supported key as publicKeyMultibase.
+func NewVerificationMethod(id, controller, vmType, pbKeyBase58 string) (VerificationMethodProperty, error) {
+	vm := VerificationMethodProperty{ID: id, Controller: controller}
+	pbKey, err := keys.ParsePublicKeyBase58(pbKeyBase58)
+	if err != nil {
+		return vm, err
+	}
+	if vmType == "" {
+		vmType = defaultVerificationMethodType(pbKey)
+	}
+	vm.Types = vmType
+
+	switch vmType {
+	case Multikey, Ed25519VerificationKey2020:
+		if _, ok := pbKey.(ed25519.PublicKey); !ok && vmType == Ed25519VerificationKey2020 {
+			return vm, errors.New("Ed25519VerificationKey2020 requires an ed25519 key")
+		}
+		vm.PublicKeyMultibase, err = keys.ExportPublicKeyAsMultibase(pbKey)
+	case X25519KeyAgreementKey2020:
+		if _, ok := pbKey.(*ecdh.PublicKey); !ok {
+			return vm, errors.New("X25519KeyAgreementKey2020 requires an x25519 key")
+		}
+		vm.PublicKeyMultibase, err = keys.ExportPublicKeyAsMultibase(pbKey)
+	case JsonWebKey2020, EcdsaSecp256k1VerificationKey2019:
+		if key, ok := pbKey.(*ecdsa.PublicKey); vmType == EcdsaSecp256k1VerificationKey2019 && (!ok || !keys.IsSecp256k1(key.Curve)) {
+			return vm, errors.New("EcdsaSecp256k1VerificationKey2019 requires a secp256k1 key")
+		}
+		vm.PublicKeyJwk, err = keys.ExportPublicKeyAsJWK(pbKey)
+	default:
+		return vm, errors.New("unsupported verification method type: " + vmType)
+	}
+	return vm, err
+}
+
+func defaultVerificationMethodType(pbKey crypto.PublicKey) string {
+	switch key := pbKey.(type) {
+	case ed25519.PublicKey:
+		return Ed25519VerificationKey2020
+	case *ecdh.PublicKey:
+		return X25519KeyAgreementKey2020
+	case *ecdsa.PublicKey:
+		if keys.IsSecp256k1(key.Curve) {
+			return EcdsaSecp256k1VerificationKey2019
+		}
+	}
+	return JsonWebKey2020
+}
+
+// PublicKey returns the key of the verification method from publicKeyJwk, publicKeyMultibase or,
+// for documents created before typed verification methods, publicKeyBase58.
+func (vm VerificationMethodProperty) PublicKey() (crypto.PublicKey, error) {
+	return decodePublicKey(vm.PublicKeyJwk, vm.PublicKeyMultibase, vm.PublicKeyBase58)
+}
+
+// PublicKeyAsBase58 returns the key of the verification method in the base58 form used by getPbKey.
+func (vm VerificationMethodProperty) PublicKeyAsBase58() (string, error) {
+	return publicKeyAsBase58(vm.PublicKeyJwk, vm.PublicKeyMultibase, vm.PublicKeyBase58)
+}
+
+// PublicKey returns the key of an embedded verification method, see VerificationMethodProperty.PublicKey.
+func (r VerificationRelationship) PublicKey() (crypto.PublicKey, error) {
+	return decodePublicKey(r.PublicKeyJwk, r.PublicKeyMultibase, r.PublicKeyBase58)
+}
+
+// PublicKeyAsBase58 returns the key of an embedded verification method in the base58 form used by getPbKey.
+func (r VerificationRelationship) PublicKeyAsBase58() (string, error) {
+	return publicKeyAsBase58(r.PublicKeyJwk, r.PublicKeyMultibase, r.PublicKeyBase58)
+}
+
+// ErrNoPublicKey is returned when a verification method carries no key material.
+var ErrNoPublicKey = errors.New("verification method has no public key")
+
+func decodePublicKey(jwk *keys.JWK, multibase, base58 string) (crypto.PublicKey, error) {
+	switch {
+	case jwk != nil:
+		return jwk.PublicKey()
+	case multibase != "":
+		return keys.ParsePublicKeyMultibase(multibase)
+	case base58 != "":
+		return keys.ParsePublicKeyBase58(base58)
+	}
+	return nil, ErrNoPublicKey
+}
+
+func publicKeyAsBase58(jwk *keys.JWK, multibase, base58 string) (string, error) {
+	// Legacy documents are passed through unchanged.
+	if jwk == nil && multibase == "" {
+		if base58 == "" {
+			return "", ErrNoPublicKey
+		}
+		return base58, nil
+	}
+	pbKey, err := decodePublicKey(jwk, multibase, base58)
+	if err != nil {
+		return "", err
+	}
+	pbKeyBase58 := keys.ExportPublicKeyAsBase58(pbKey)
+	if pbKeyBase58 == "" {
+		return "", errors.New("failed to encode public key")
+	}
+	return pbKeyBase58, nil
+}
@@ -0,0 +1,139 @@
+// Package domainlinkage implements the DIF Well-Known DID Configuration: Domain Linkage Credentials
+// that bind a DID to a web origin, the /.well-known/did-configuration.json resource that publishes them,
+// and a verifier for the LinkedDomains services of DID documents.
+// identity.foundation/.well-known/resources/did-configuration/
+package domainlinkage
+
+import (
+	"byd50-ssi/pkg/did/core"
+	"byd50-ssi/pkg/did/core/vcdm"
+	derrors "byd50-ssi/pkg/did/errors"
+	"crypto"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	// ContextV1 is the @context of DID configuration resources and Domain Linkage Credentials.
+	ContextV1 = "https://identity.foundation/.well-known/did-configuration/v1"
+	// WellKnownPath is where an origin publishes its DID configuration.
+	WellKnownPath = "/.well-known/did-configuration.json"
+	// CredentialType is the type of a Domain Linkage Credential.
+	CredentialType = "DomainLinkageCredential"
+)
+
+// Configuration is the DID configuration resource. linked_dids holds Domain Linkage Credentials in JWT format.
+type Configuration struct {
+	Context    string   `json:"@context"`
+	LinkedDids []string `json:"linked_dids"`
+}
+
+// NewConfiguration returns a DID configuration publishing the given Domain Linkage Credentials.
+func NewConfiguration(linkedDids ...string) Configuration {
+	if linkedDids == nil {
+		linkedDids = []string{}
+	}
+	return Configuration{Context: ContextV1, LinkedDids: linkedDids}
+}
+
+// NormalizeOrigin returns origin as scheme://host[:port]. Only https origins without path, query or fragment are accepted.
+func NormalizeOrigin(origin string) (string, error) {
+	u, err := url.Parse(strings.TrimSpace(origin))
+	if err != nil {
+		return "", derrors.Wrap(derrors.CodeInvalidInput, "invalid origin", err)
+	}
+	if !strings.EqualFold(u.Scheme, "https") || u.Host == "" {
+		return "", derrors.New(derrors.CodeInvalidInput, "origin must be an https url")
+	}
+	if (u.Path != "" && u.Path != "/") || u.RawQuery != "" || u.Fragment != "" || u.User != nil {
+		return "", derrors.New(derrors.CodeInvalidInput, "origin must not have a path, query, fragment or userinfo")
+	}
+	return "https://" + strings.ToLower(u.Host), nil
+}
+
+// Issue signs a Domain Linkage Credential (JWT) in which did asserts control of origin.
+// pvKey should be an assertionMethod key of did, since verifiers resolve the key from that relationship.
+func Issue(did, origin string, validity time.Duration, pvKey crypto.PrivateKey) (string, error) {
+	if did == "" {
+		return "", derrors.New(derrors.CodeInvalidInput, "did is empty")
+	}
+	if validity <= 0 {
+		return "", derrors.New(derrors.CodeInvalidInput, "validity must be positive")
+	}
+	if pvKey == nil {
+		return "", derrors.New(derrors.CodeEmptyKey, "private key is nil")
+	}
+	origin, err := NormalizeOrigin(origin)
+	if err != nil {
+		return "", err
+	}
+
+	now := time.Now()
+	vc := vcdm.NewCredentialV1(CredentialType, vcdm.CredentialSubject{
+		ID:     did,
+		Claims: map[string]interface{}{"origin": origin},
+	})
+	vc.Context = []string{vcdm.ContextV1, ContextV1}
+	vc.Issuer = vcdm.Issuer{ID: did}
+	vc.ValidFrom = now
+	vc.ValidUntil = now.Add(validity)
+
+	// Domain Linkage Credentials carry no credential id (jti), and need no nonce.
+	claims, err := vc.ToVcClaims(jwt.StandardClaims{IssuedAt: now.Unix()}, "")
+	if err != nil {
+		return "", err
+	}
+	vcJwt := core.CreateVcWithClaims(did, claims, pvKey)
+	if vcJwt == "" {
+		return "", derrors.New(derrors.CodeInternal, "failed to sign domain linkage credential")
+	}
+	return vcJwt, nil
+}
+
+// VerifyCredential checks that vcJwt is a valid Domain Linkage Credential of did for origin.
+// The signature is verified with the key resolved by getPbKey (assertionMethod keys).
+func VerifyCredential(vcJwt, did, origin string, getPbKey func(string, string) string) error {
+	origin, err := NormalizeOrigin(origin)
+	if err != nil {
+		return err
+	}
+	ok, mapClaims, err := core.GetVcMapClaims(vcJwt, getPbKey)
+	if err != nil || !ok {
+		return derrors.Wrap(derrors.CodeInvalidInput, "domain linkage credential signature invalid", err)
+	}
+	// the key was resolved from the kid, so the signer must be the linked DID itself
+	if signer, err := core.GetSignerDid(vcJwt); err != nil || signer != did {
+		return derrors.New(derrors.CodeInvalidInput, "domain linkage credential not signed by "+did)
+	}
+	if _, ok := mapClaims["exp"]; !ok {
+		return derrors.New(derrors.CodeInvalidInput, "domain linkage credential has no exp")
+	}
+	if iss, _ := mapClaims["iss"].(string); iss != did {
+		return derrors.New(derrors.CodeInvalidInput, "domain linkage credential issuer mismatch")
+	}
+	if sub, _ := mapClaims["sub"].(string); sub != did {
+		return derrors.New(derrors.CodeInvalidInput, "domain linkage credential subject mismatch")
+	}
+
+	vc, err := vcdm.CredentialFromMapClaims(mapClaims)
+	if err != nil {
+		return err
+	}
+	if !core.Contains(vc.Type, CredentialType) {
+		return derrors.New(derrors.CodeInvalidInput, "credential is not a "+CredentialType)
+	}
+	if !core.Contains(vc.Context, ContextV1) {
+		return derrors.New(derrors.CodeInvalidInput, "domain linkage credential context missing")
+	}
+	if len(vc.CredentialSubject) != 1 || vc.CredentialSubject[0].ID != did {
+		return derrors.New(derrors.CodeInvalidInput, "credentialSubject.id must be the did")
+	}
+	claimed, _ := vc.CredentialSubject[0].Claims["origin"].(string)
+	if claimed, err = NormalizeOrigin(claimed); err != nil || claimed != origin {
+		return derrors.New(derrors.CodeInvalidInput, "domain linkage credential origin mismatch")
+	}
+	return nil
+}
@@ -0,0 +1,199 @@
+package domainlinkage_test
+
+import (
+	"byd50-ssi/pkg/did/core"
+	"byd50-ssi/pkg/did/core/dids"
+	"byd50-ssi/pkg/did/core/domainlinkage"
+	"byd50-ssi/pkg/did/core/vcdm"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/golang-jwt/jwt"
+)
+
+const testDid = "did:byd50:domainlinkage"
+
+func newTestKey(t *testing.T) (*ecdsa.PrivateKey, func(string, string) string) {
+	t.Helper()
+	pvKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pbBytes, err := x509.MarshalPKIXPublicKey(&pvKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pbKeyBase58 := base58.Encode(pbBytes)
+	return pvKey, func(did, _ string) string {
+		if did != testDid {
+			return ""
+		}
+		return pbKeyBase58
+	}
+}
+
+func TestIssueAndVerifyCredential(t *testing.T) {
+	pvKey, getPbKey := newTestKey(t)
+	origin := "https://Identity.Example.com/"
+
+	vcJwt, err := domainlinkage.Issue(testDid, origin, time.Hour, pvKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := domainlinkage.VerifyCredential(vcJwt, testDid, "https://identity.example.com", getPbKey); err != nil {
+		t.Fatalf("verify failed: %v", err)
+	}
+
+	if err := domainlinkage.VerifyCredential(vcJwt, testDid, "https://evil.example.com", getPbKey); err == nil {
+		t.Fatal("expected origin mismatch")
+	}
+	if err := domainlinkage.VerifyCredential(vcJwt, "did:byd50:other", origin, getPbKey); err == nil {
+		t.Fatal("expected did mismatch")
+	}
+
+	parts := strings.Split(vcJwt, ".")
+	tampered := parts[0] + "." + parts[1] + "." + strings.Repeat("A", len(parts[2]))
+	if err := domainlinkage.VerifyCredential(tampered, testDid, origin, getPbKey); err == nil {
+		t.Fatal("expected tampered signature to fail")
+	}
+
+	_, otherKey := newTestKey(t)
+	if err := domainlinkage.VerifyCredential(vcJwt, testDid, origin, otherKey); err == nil {
+		t.Fatal("expected verification with another key to fail")
+	}
+}
+
+func TestCredentialSignedByAnotherDid(t *testing.T) {
+	pvKey, getPbKey := newTestKey(t)
+	attackerKey, getAttackerKey := newTestKey(t)
+	const attackerDid = "did:byd50:attacker"
+	origin := "https://identity.example.com"
+
+	// claims naming testDid as issuer and subject, but signed with the key of another DID
+	vc := vcdm.NewCredentialV1(domainlinkage.CredentialType, vcdm.CredentialSubject{
+		ID:     testDid,
+		Claims: map[string]interface{}{"origin": origin},
+	})
+	vc.Context = []string{vcdm.ContextV1, domainlinkage.ContextV1}
+	vc.Issuer = vcdm.Issuer{ID: testDid}
+	vc.ValidFrom = time.Now()
+	vc.ValidUntil = time.Now().Add(time.Hour)
+	claims, err := vc.ToVcClaims(jwt.StandardClaims{IssuedAt: time.Now().Unix()}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	forged := core.CreateVcWithClaims(attackerDid, claims, attackerKey)
+
+	resolve := func(did, keyId string) string {
+		if did == attackerDid {
+			return getAttackerKey(testDid, keyId)
+		}
+		return getPbKey(did, keyId)
+	}
+	if err := domainlinkage.VerifyCredential(forged, testDid, origin, resolve); err == nil {
+		t.Fatal("expected credential signed by another did to fail")
+	}
+	forged = core.CreateVcWithClaims(attackerDid+"#keys-1", claims, attackerKey)
+	if err := domainlinkage.VerifyCredential(forged, testDid, origin, resolve); err == nil {
+		t.Fatal("expected credential signed by a key of another did to fail")
+	}
+
+	// a kid that is a DID URL of the linked DID is accepted
+	signed := core.CreateVcWithClaims(testDid+"#keys-1", claims, pvKey)
+	if err := domainlinkage.VerifyCredential(signed, testDid, origin, getPbKey); err != nil {
+		t.Fatalf("verify with did url kid failed: %v", err)
+	}
+}
+
+func TestIssueRejectsInvalidOrigin(t *testing.T) {
+	pvKey, _ := newTestKey(t)
+	for _, origin := range []string{"http://example.com", "https://example.com/path", "https://example.com?q=1", "example.com"} {
+		if _, err := domainlinkage.Issue(testDid, origin, time.Hour, pvKey); err == nil {
+			t.Fatalf("expected %q to be rejected", origin)
+		}
+	}
+	if _, err := domainlinkage.Issue(testDid, "https://example.com", 0, pvKey); err == nil {
+		t.Fatal("expected non-positive validity to be rejected")
+	}
+}
+
+func TestVerifyLinkedDomains(t *testing.T) {
+	pvKey, getPbKey := newTestKey(t)
+
+	var config domainlinkage.Configuration
+	server := httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != domainlinkage.WellKnownPath {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(config)
+	}))
+	defer server.Close()
+
+	vcJwt, err := domainlinkage.Issue(testDid, server.URL, time.Hour, pvKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherJwt, err := domainlinkage.Issue(testDid, "https://other.example.com", time.Hour, pvKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	doc := dids.DocumentInterface{ID: testDid}
+	if err := doc.AddService(dids.ServiceProperty{
+		ID:              "#domains",
+		Types:           dids.ServiceTypes{dids.ServiceTypeLinkedDomains},
+		ServiceEndpoint: dids.EndpointMap(map[string]interface{}{"origins": []interface{}{server.URL}}),
+	}); err != nil {
+		t.Fatal(err)
+	}
+
+	verifier := domainlinkage.Verifier{Client: server.Client(), GetPbKey: getPbKey}
+	ctx := context.Background()
+
+	// a configuration holding only a credential for another origin does not link the domain
+	config = domainlinkage.NewConfiguration(otherJwt)
+	results, err := verifier.VerifyLinkedDomains(ctx, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || results[0].Verified {
+		t.Fatalf("expected unverified origin, got %+v", results)
+	}
+
+	config = domainlinkage.NewConfiguration(otherJwt, vcJwt)
+	results, err = verifier.VerifyLinkedDomains(ctx, doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(results) != 1 || !results[0].Verified || results[0].Origin != server.URL {
+		t.Fatalf("expected verified origin, got %+v", results)
+	}
+
+	if _, err := verifier.VerifyLinkedDomains(ctx, dids.DocumentInterface{ID: testDid}); err == nil {
+		t.Fatal("expected error without LinkedDomains service")
+	}
+}
+
+func TestExpiredCredentialRejected(t *testing.T) {
+	pvKey, getPbKey := newTestKey(t)
+	vcJwt, err := domainlinkage.Issue(testDid, "https://example.com", time.Second, pvKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(2 * time.Second)
+	if err := domainlinkage.VerifyCredential(vcJwt, testDid, "https://example.com", getPbKey); err == nil {
+		t.Fatal("expected expired credential to fail")
+	}
+}
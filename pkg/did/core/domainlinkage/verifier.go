@@ -0,0 +1,125 @@
+package domainlinkage
+
+import (
+	"byd50-ssi/pkg/did/core"
+	"byd50-ssi/pkg/did/core/dids"
+	derrors "byd50-ssi/pkg/did/errors"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+)
+
+// maxConfigurationSize bounds the DID configuration resource read from an origin.
+const maxConfigurationSize = 256 * 1024
+
+var defaultClient = &http.Client{Timeout: 10 * time.Second}
+
+// Fetch downloads and decodes the DID configuration of origin. A nil client uses a client with a 10s timeout.
+func Fetch(ctx context.Context, client *http.Client, origin string) (*Configuration, error) {
+	origin, err := NormalizeOrigin(origin)
+	if err != nil {
+		return nil, err
+	}
+	if client == nil {
+		client = defaultClient
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, origin+WellKnownPath, nil)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "invalid did configuration url", err)
+	}
+	req.Header.Set("Accept", "application/json")
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeUpstream, "failed to fetch did configuration", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, derrors.New(derrors.CodeUpstream, fmt.Sprintf("did configuration returned status %d", resp.StatusCode))
+	}
+
+	var config Configuration
+	if err := json.NewDecoder(io.LimitReader(resp.Body, maxConfigurationSize)).Decode(&config); err != nil {
+		return nil, derrors.Wrap(derrors.CodeUpstream, "invalid did configuration", err)
+	}
+	if config.Context != ContextV1 {
+		return nil, derrors.New(derrors.CodeUpstream, "unexpected did configuration @context")
+	}
+	return &config, nil
+}
+
+// OriginResult is the outcome of verifying one origin of a LinkedDomains service.
+type OriginResult struct {
+	Origin   string `json:"origin"`
+	Verified bool   `json:"verified"`
+	Error    string `json:"error,omitempty"`
+}
+
+// Verifier checks the LinkedDomains services of DID documents against the DID configurations of their origins.
+type Verifier struct {
+	// Client fetches DID configurations; nil uses a client with a 10s timeout.
+	Client *http.Client
+	// GetPbKey resolves the assertionMethod key that signed a Domain Linkage Credential.
+	GetPbKey func(string, string) string
+}
+
+// VerifyOrigin checks that the DID configuration of origin holds a valid Domain Linkage Credential of did.
+func (v *Verifier) VerifyOrigin(ctx context.Context, did, origin string) error {
+	config, err := Fetch(ctx, v.Client, origin)
+	if err != nil {
+		return err
+	}
+	var lastErr error
+	for _, vcJwt := range config.LinkedDids {
+		if lastErr = VerifyCredential(vcJwt, did, origin, v.GetPbKey); lastErr == nil {
+			return nil
+		}
+	}
+	if lastErr == nil {
+		lastErr = derrors.New(derrors.CodeNotFound, "did configuration has no linked dids")
+	}
+	return derrors.Wrap(derrors.CodeNotFound, "no valid domain linkage credential for "+did, lastErr)
+}
+
+// VerifyLinkedDomains verifies every origin of the LinkedDomains services of doc. It fails only when the
+// document has no such service; the outcome per origin is reported in the results.
+func (v *Verifier) VerifyLinkedDomains(ctx context.Context, doc dids.DocumentInterface) ([]OriginResult, error) {
+	origins := LinkedOrigins(doc)
+	if len(origins) == 0 {
+		return nil, derrors.New(derrors.CodeNotFound, "did document has no LinkedDomains service")
+	}
+	results := make([]OriginResult, 0, len(origins))
+	for _, origin := range origins {
+		result := OriginResult{Origin: origin, Verified: true}
+		if err := v.VerifyOrigin(ctx, doc.ID, origin); err != nil {
+			result.Verified = false
+			result.Error = err.Error()
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// LinkedOrigins returns the origins of the LinkedDomains services of doc. Endpoints may be an origin,
+// a set of origins or a map with an "origins" array.
+func LinkedOrigins(doc dids.DocumentInterface) []string {
+	var origins []string
+	for _, service := range doc.ServicesByType(dids.ServiceTypeLinkedDomains) {
+		candidates := service.ServiceEndpoint.URIs()
+		if list, ok := service.ServiceEndpoint.Map["origins"].([]interface{}); ok {
+			for _, item := range list {
+				if origin, ok := item.(string); ok {
+					candidates = append(candidates, origin)
+				}
+			}
+		}
+		for _, candidate := range candidates {
+			if origin, err := NormalizeOrigin(candidate); err == nil && !core.Contains(origins, origin) {
+				origins = append(origins, origin)
+			}
+		}
+	}
+	return origins
+}
@@ -59,6 +59,21 @@ func (m *DidMethodBYD50) ResolveDid(did string) (string, string, string, error)
 	return didDocument, DidDocumentMetadata, ResolutionError, err
 }
 
+// UpdateDid - Implements the UpdateDid method from DidMethodUpdater
+// The caller is responsible for authorizing the update.
+func (m *DidMethodBYD50) UpdateDid(did, document string) (string, error) {
+	registryClient := GetRegistryClient(configs.UseConfig.DidRegistryAddress)
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+
+	r, err := registryClient.UpdateDid(ctx, &pb.RegistryUpdateDidRequest{Did: did, Document: document})
+	if err != nil {
+		return "", err
+	}
+	return r.GetResult(), nil
+}
+
 // CreateDid - Implements the RegisterDid method from DidMethod
 // For this register did method, pbKeyBase58 must be an base58 encoded string
 func (m *DidMethodBYD50) CreateDid(pbKeyBase58 string) (string, error) {
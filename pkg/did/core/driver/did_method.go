@@ -14,6 +14,11 @@ type DidMethod interface {
 	Method() string                                        // returns the method identifier for this method (example: 'byd50')
 }
 
+// DidMethodUpdater is implemented by methods whose DID documents can be replaced after creation.
+type DidMethodUpdater interface {
+	UpdateDid(did, document string) (string, error) // Returns the registry result or error
+}
+
 // RegisterDidMethod Register the "method" name and a factory function for signing method.
 // This is typically done during init() in the method's implementation
 func RegisterDidMethod(method string, f func() DidMethod) {
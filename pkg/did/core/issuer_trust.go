@@ -0,0 +1,61 @@
+package core
+
+import (
+	"byd50-ssi/pkg/did/core/vcdm"
+	derrors "byd50-ssi/pkg/did/errors"
+	"github.com/golang-jwt/jwt"
+)
+
+// IssuerTrust decides whether a DID may issue credentials of the given types, e.g. a trust registry.
+type IssuerTrust interface {
+	// CheckIssuer returns an error unless issuerDid is trusted for every one of credentialTypes.
+	CheckIssuer(issuerDid string, credentialTypes []string) error
+}
+
+// IssuerTrustFunc adapts a function to IssuerTrust.
+type IssuerTrustFunc func(issuerDid string, credentialTypes []string) error
+
+func (f IssuerTrustFunc) CheckIssuer(issuerDid string, credentialTypes []string) error {
+	return f(issuerDid, credentialTypes)
+}
+
+// VerifyVpWithOptions is VerifyVpWithKeys followed by the checks of options on every embedded VC.
+func VerifyVpWithOptions(vp string, getAuthKey, getAssertionKey func(string, string) string, options VerifyOptions) (bool, string, error) {
+	ok, did, err := VerifyVpWithKeys(vp, getAuthKey, getAssertionKey)
+	if !ok {
+		return false, did, err
+	}
+	token, _, err := new(jwt.Parser).ParseUnverified(vp, jwt.MapClaims{})
+	if err != nil {
+		return false, did, derrors.Wrap(derrors.CodeInvalidInput, "failed to parse vp", err)
+	}
+	presentation, err := vcdm.PresentationFromMapClaims(token.Claims.(jwt.MapClaims))
+	if err != nil {
+		return false, did, err
+	}
+	for _, vcJwt := range presentation.CredentialJwts() {
+		if err := options.check(vcJwt); err != nil {
+			return false, did, err
+		}
+	}
+	return true, did, nil
+}
+
+// checkIssuerTrust asks trust about the DID that signed vcJwt (the DID of its kid), since that is the issuer
+// whose key was verified, and the credential types other than VerifiableCredential.
+func checkIssuerTrust(trust IssuerTrust, vcJwt string, vc *vcdm.VerifiableCredential) error {
+	signer, err := GetSignerDid(vcJwt)
+	if err != nil {
+		return err
+	}
+	var credentialTypes []string
+	for _, typ := range vc.Type {
+		if typ != "VerifiableCredential" {
+			credentialTypes = append(credentialTypes, typ)
+		}
+	}
+	if len(credentialTypes) == 0 {
+		return derrors.New(derrors.CodeInvalidInput, "vc has no credential type")
+	}
+	return trust.CheckIssuer(signer, credentialTypes)
+}
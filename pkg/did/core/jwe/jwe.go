@@ -0,0 +1,364 @@
+// Package jwe implements JSON Web Encryption (RFC 7516) between DIDs. Content is encrypted with A256GCM under a
+// random content encryption key, which is wrapped for every recipient with ECDH-ES+A256KW (RFC 7518 §4.6) to a
+// keyAgreement key of its DID document, so that one message can be addressed to several holders.
+// Messages are produced in the general JSON serialization; the flattened and compact serializations are
+// accepted as well when parsing.
+package jwe
+
+import (
+	derrors "byd50-ssi/pkg/did/errors"
+	"byd50-ssi/pkg/keys"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+)
+
+const (
+	// AlgECDHESA256KW is the key management algorithm: ECDH-ES with an ephemeral key per recipient, whose
+	// Concat KDF output wraps the content encryption key with AES-256 key wrap.
+	AlgECDHESA256KW = "ECDH-ES+A256KW"
+	// EncA256GCM is the content encryption algorithm.
+	EncA256GCM = "A256GCM"
+	// MediaType is the media type of JWEs in the JSON serialization.
+	MediaType = "application/jose+json"
+
+	cekSize = 32
+)
+
+// Header is a JOSE header of a JWE. Members may appear in the protected header, the shared unprotected header
+// or the per-recipient header.
+type Header struct {
+	Alg string    `json:"alg,omitempty"`
+	Enc string    `json:"enc,omitempty"`
+	Typ string    `json:"typ,omitempty"`
+	Cty string    `json:"cty,omitempty"`
+	Kid string    `json:"kid,omitempty"`
+	Epk *keys.JWK `json:"epk,omitempty"`
+	Apu string    `json:"apu,omitempty"`
+	Apv string    `json:"apv,omitempty"`
+}
+
+// merge returns h completed with the members of other that h does not set.
+func (h Header) merge(other *Header) Header {
+	if other == nil {
+		return h
+	}
+	fill := func(dst *string, src string) {
+		if *dst == "" {
+			*dst = src
+		}
+	}
+	fill(&h.Alg, other.Alg)
+	fill(&h.Enc, other.Enc)
+	fill(&h.Typ, other.Typ)
+	fill(&h.Cty, other.Cty)
+	fill(&h.Kid, other.Kid)
+	fill(&h.Apu, other.Apu)
+	fill(&h.Apv, other.Apv)
+	if h.Epk == nil {
+		h.Epk = other.Epk
+	}
+	return h
+}
+
+// Recipient is a key a JWE is encrypted to: usually a keyAgreement verification method of the recipient's DID
+// document. PublicKey is a P-256, P-384 or secp256k1 *ecdsa.PublicKey or an X25519 *ecdh.PublicKey.
+type Recipient struct {
+	// KeyID is published as kid of the recipient, e.g. the DID URL of the verification method.
+	KeyID     string
+	PublicKey crypto.PublicKey
+}
+
+// RecipientKey is the per-recipient part of a JWE: its header with the ephemeral key and the wrapped content
+// encryption key.
+type RecipientKey struct {
+	Header       *Header `json:"header,omitempty"`
+	EncryptedKey string  `json:"encrypted_key"`
+}
+
+// JWE is a JSON Web Encryption in the general JSON serialization. Binary members are base64url encoded.
+type JWE struct {
+	Protected   string         `json:"protected"`
+	Unprotected *Header        `json:"unprotected,omitempty"`
+	Recipients  []RecipientKey `json:"recipients"`
+	AAD         string         `json:"aad,omitempty"`
+	IV          string         `json:"iv"`
+	Ciphertext  string         `json:"ciphertext"`
+	Tag         string         `json:"tag"`
+}
+
+// Encrypt encrypts plaintext to every recipient. contentType is published as cty of the protected header,
+// e.g. "JWT" for a credential in JWT format, and may be empty.
+func Encrypt(plaintext []byte, contentType string, recipients ...Recipient) (*JWE, error) {
+	if len(recipients) == 0 {
+		return nil, derrors.New(derrors.CodeInvalidInput, "at least one recipient is required")
+	}
+	protected, err := json.Marshal(Header{Enc: EncA256GCM, Cty: contentType})
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInternal, "failed to encode protected header", err)
+	}
+	jwe := &JWE{Protected: base64.RawURLEncoding.EncodeToString(protected)}
+
+	cek := make([]byte, cekSize)
+	if _, err := rand.Read(cek); err != nil {
+		return nil, derrors.Wrap(derrors.CodeInternal, "failed to generate content encryption key", err)
+	}
+	for _, recipient := range recipients {
+		recipientKey, err := wrapFor(recipient, cek)
+		if err != nil {
+			return nil, err
+		}
+		jwe.Recipients = append(jwe.Recipients, recipientKey)
+	}
+
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return nil, err
+	}
+	iv := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(iv); err != nil {
+		return nil, derrors.Wrap(derrors.CodeInternal, "failed to generate iv", err)
+	}
+	sealed := gcm.Seal(nil, iv, plaintext, jwe.additionalData())
+	split := len(sealed) - gcm.Overhead()
+	jwe.IV = base64.RawURLEncoding.EncodeToString(iv)
+	jwe.Ciphertext = base64.RawURLEncoding.EncodeToString(sealed[:split])
+	jwe.Tag = base64.RawURLEncoding.EncodeToString(sealed[split:])
+	return jwe, nil
+}
+
+// wrapFor wraps cek for recipient with a fresh ephemeral key.
+func wrapFor(recipient Recipient, cek []byte) (RecipientKey, error) {
+	if recipient.PublicKey == nil {
+		return RecipientKey{}, derrors.New(derrors.CodeEmptyKey, "recipient public key is nil")
+	}
+	ephemeral, ephemeralPublic, err := keys.GenerateKeyAgreementKey(recipient.PublicKey)
+	if err != nil {
+		return RecipientKey{}, derrors.Wrap(derrors.CodeInvalidKey, "unsupported recipient key", err)
+	}
+	z, err := keys.SharedSecret(ephemeral, recipient.PublicKey)
+	if err != nil {
+		return RecipientKey{}, derrors.Wrap(derrors.CodeInvalidKey, "key agreement failed", err)
+	}
+	epk, err := keys.ExportPublicKeyAsJWK(ephemeralPublic)
+	if err != nil {
+		return RecipientKey{}, derrors.Wrap(derrors.CodeInternal, "failed to encode ephemeral key", err)
+	}
+	encryptedKey, err := wrapKey(concatKDF(z, AlgECDHESA256KW, nil, nil, cekSize), cek)
+	if err != nil {
+		return RecipientKey{}, derrors.Wrap(derrors.CodeInternal, "failed to wrap content encryption key", err)
+	}
+	return RecipientKey{
+		Header:       &Header{Alg: AlgECDHESA256KW, Kid: recipient.KeyID, Epk: epk},
+		EncryptedKey: base64.RawURLEncoding.EncodeToString(encryptedKey),
+	}, nil
+}
+
+// Parse parses a JWE in the general JSON, flattened JSON or compact serialization.
+func Parse(data []byte) (*JWE, error) {
+	text := strings.TrimSpace(string(data))
+	jwe := &JWE{}
+	if strings.HasPrefix(text, "{") {
+		var serialized struct {
+			JWE
+			Header       *Header `json:"header"`
+			EncryptedKey string  `json:"encrypted_key"`
+		}
+		if err := json.Unmarshal([]byte(text), &serialized); err != nil {
+			return nil, derrors.Wrap(derrors.CodeInvalidInput, "invalid jwe json", err)
+		}
+		*jwe = serialized.JWE
+		if len(jwe.Recipients) == 0 && (serialized.Header != nil || serialized.EncryptedKey != "") {
+			jwe.Recipients = []RecipientKey{{Header: serialized.Header, EncryptedKey: serialized.EncryptedKey}}
+		}
+	} else {
+		parts := strings.Split(text, ".")
+		if len(parts) != 5 {
+			return nil, derrors.New(derrors.CodeInvalidInput, "compact jwe must have 5 parts")
+		}
+		jwe.Protected, jwe.IV, jwe.Ciphertext, jwe.Tag = parts[0], parts[2], parts[3], parts[4]
+		jwe.Recipients = []RecipientKey{{EncryptedKey: parts[1]}}
+	}
+	if jwe.Protected == "" || len(jwe.Recipients) == 0 || jwe.IV == "" || jwe.Tag == "" {
+		return nil, derrors.New(derrors.CodeInvalidInput, "jwe is missing protected, recipients, iv or tag")
+	}
+	if _, err := jwe.Header(); err != nil {
+		return nil, err
+	}
+	return jwe, nil
+}
+
+// Header returns the protected header of the JWE completed with its shared unprotected header.
+func (jwe *JWE) Header() (Header, error) {
+	var header Header
+	protected, err := base64.RawURLEncoding.DecodeString(jwe.Protected)
+	if err != nil {
+		return header, derrors.Wrap(derrors.CodeInvalidInput, "invalid protected header encoding", err)
+	}
+	if err := json.Unmarshal(protected, &header); err != nil {
+		return header, derrors.Wrap(derrors.CodeInvalidInput, "invalid protected header", err)
+	}
+	return header.merge(jwe.Unprotected), nil
+}
+
+// KeyIDs returns the kid of every recipient, so that a holder can tell which of its keys decrypts the JWE.
+func (jwe *JWE) KeyIDs() []string {
+	shared, _ := jwe.Header()
+	var kids []string
+	for _, recipient := range jwe.Recipients {
+		if kid := shared.merge(recipient.Header).Kid; kid != "" {
+			kids = append(kids, kid)
+		}
+	}
+	return kids
+}
+
+// Decrypt decrypts the JWE with the key of agreer. Every recipient on the curve of the key is tried until one
+// of them unwraps the content encryption key.
+func (jwe *JWE) Decrypt(agreer KeyAgreer) ([]byte, error) {
+	if agreer == nil {
+		return nil, derrors.New(derrors.CodeEmptyKey, "key agreer is nil")
+	}
+	shared, err := jwe.Header()
+	if err != nil {
+		return nil, err
+	}
+	if shared.Enc != EncA256GCM {
+		return nil, derrors.New(derrors.CodeInvalidInput, "unsupported jwe enc: "+shared.Enc)
+	}
+	own, err := keys.ExportPublicKeyAsJWK(agreer.Public())
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidKey, "unsupported key agreement key", err)
+	}
+
+	var lastErr error
+	for _, recipient := range jwe.Recipients {
+		header := shared.merge(recipient.Header)
+		if header.Alg != AlgECDHESA256KW || header.Epk == nil || header.Epk.Crv != own.Crv {
+			continue
+		}
+		cek, err := unwrapFor(header, recipient.EncryptedKey, agreer)
+		if err != nil {
+			lastErr = err
+			continue
+		}
+		return jwe.decryptContent(cek)
+	}
+	if lastErr != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidKey, "no recipient of the jwe matches the key", lastErr)
+	}
+	return nil, derrors.New(derrors.CodeInvalidKey, "no recipient of the jwe matches the key")
+}
+
+// unwrapFor recovers the content encryption key of one recipient.
+func unwrapFor(header Header, encryptedKey string, agreer KeyAgreer) ([]byte, error) {
+	epk, err := header.Epk.PublicKey()
+	if err != nil {
+		return nil, err
+	}
+	z, err := agreer.SharedSecret(epk)
+	if err != nil {
+		return nil, err
+	}
+	apu, err := base64.RawURLEncoding.DecodeString(header.Apu)
+	if err != nil {
+		return nil, err
+	}
+	apv, err := base64.RawURLEncoding.DecodeString(header.Apv)
+	if err != nil {
+		return nil, err
+	}
+	wrapped, err := base64.RawURLEncoding.DecodeString(encryptedKey)
+	if err != nil {
+		return nil, err
+	}
+	return unwrapKey(concatKDF(z, AlgECDHESA256KW, apu, apv, cekSize), wrapped)
+}
+
+func (jwe *JWE) decryptContent(cek []byte) ([]byte, error) {
+	iv, err := base64.RawURLEncoding.DecodeString(jwe.IV)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "invalid iv encoding", err)
+	}
+	ciphertext, err := base64.RawURLEncoding.DecodeString(jwe.Ciphertext)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "invalid ciphertext encoding", err)
+	}
+	tag, err := base64.RawURLEncoding.DecodeString(jwe.Tag)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "invalid tag encoding", err)
+	}
+	gcm, err := newGCM(cek)
+	if err != nil {
+		return nil, err
+	}
+	if len(iv) != gcm.NonceSize() || len(tag) != gcm.Overhead() {
+		return nil, derrors.New(derrors.CodeInvalidInput, "invalid iv or tag length")
+	}
+	plaintext, err := gcm.Open(nil, iv, append(ciphertext, tag...), jwe.additionalData())
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "content decryption failed", err)
+	}
+	return plaintext, nil
+}
+
+// additionalData is the AAD of the content encryption: the encoded protected header, followed by the aad
+// member if present.
+func (jwe *JWE) additionalData() []byte {
+	if jwe.AAD != "" {
+		return []byte(jwe.Protected + "." + jwe.AAD)
+	}
+	return []byte(jwe.Protected)
+}
+
+func newGCM(cek []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(cek)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "invalid content encryption key", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInternal, "failed to initialize A256GCM", err)
+	}
+	return gcm, nil
+}
+
+// KeyAgreer is a key agreement key whose private part may be held elsewhere, e.g. by a key manager.
+type KeyAgreer interface {
+	// Public returns the public key.
+	Public() crypto.PublicKey
+	// SharedSecret computes the ECDH shared secret Z with a peer public key, see keys.SharedSecret.
+	SharedSecret(peer crypto.PublicKey) ([]byte, error)
+}
+
+type privateKeyAgreer struct {
+	pvKey crypto.PrivateKey
+	pbKey crypto.PublicKey
+}
+
+// NewKeyAgreer returns a KeyAgreer of a P-256, P-384 or secp256k1 *ecdsa.PrivateKey or an X25519 *ecdh.PrivateKey.
+func NewKeyAgreer(pvKey crypto.PrivateKey) (KeyAgreer, error) {
+	switch v := pvKey.(type) {
+	case *ecdsa.PrivateKey:
+		return privateKeyAgreer{pvKey: v, pbKey: &v.PublicKey}, nil
+	case *ecdh.PrivateKey:
+		return privateKeyAgreer{pvKey: v, pbKey: v.PublicKey()}, nil
+	case nil:
+		return nil, derrors.New(derrors.CodeEmptyKey, "private key is nil")
+	}
+	return nil, derrors.New(derrors.CodeInvalidKey, "unsupported key type for key agreement")
+}
+
+func (a privateKeyAgreer) Public() crypto.PublicKey {
+	return a.pbKey
+}
+
+func (a privateKeyAgreer) SharedSecret(peer crypto.PublicKey) ([]byte, error) {
+	return keys.SharedSecret(a.pvKey, peer)
+}
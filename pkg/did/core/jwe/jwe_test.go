@@ -0,0 +1,175 @@
+package jwe
+
+import (
+	"byd50-ssi/pkg/keys"
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+)
+
+func TestKeyWrapVectors(t *testing.T) {
+	// RFC 3394 §4.1 and §4.6
+	vectors := []struct{ kek, key, wrapped string }{
+		{
+			"000102030405060708090a0b0c0d0e0f",
+			"00112233445566778899aabbccddeeff",
+			"1fa68b0a8112b447aef34bd8fb5a7b829d3e862371d2cfe5",
+		},
+		{
+			"000102030405060708090a0b0c0d0e0f101112131415161718191a1b1c1d1e1f",
+			"00112233445566778899aabbccddeeff000102030405060708090a0b0c0d0e0f",
+			"28c9f404c4b810f4cbccb35cfb87f8263f5786e2d80ed326cbc7f0e71a99f43bfb988b9b7a02dd21",
+		},
+	}
+	for _, v := range vectors {
+		kek, _ := hex.DecodeString(v.kek)
+		key, _ := hex.DecodeString(v.key)
+		wrapped, err := wrapKey(kek, key)
+		if err != nil || hex.EncodeToString(wrapped) != v.wrapped {
+			t.Fatalf("unexpected wrapped key %x: %v", wrapped, err)
+		}
+		unwrapped, err := unwrapKey(kek, wrapped)
+		if err != nil || !bytes.Equal(unwrapped, key) {
+			t.Fatalf("unexpected unwrapped key %x: %v", unwrapped, err)
+		}
+		wrapped[0] ^= 1
+		if _, err := unwrapKey(kek, wrapped); err == nil {
+			t.Fatal("expected a tampered key to fail the integrity check")
+		}
+	}
+}
+
+func TestConcatKDFVector(t *testing.T) {
+	// RFC 7518 Appendix C
+	z, _ := hex.DecodeString("9e56d91d817135d372834283bf84269cfb316ea3da806a48f6daa7798cfe90c4")
+	key := concatKDF(z, "A128GCM", []byte("Alice"), []byte("Bob"), 16)
+	if got := base64.RawURLEncoding.EncodeToString(key); got != "VqqN6vgjbSBcIijNcacQGg" {
+		t.Fatalf("unexpected derived key %s", got)
+	}
+}
+
+func TestEncryptDecrypt(t *testing.T) {
+	p384, err := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p256, _, _ := keys.GenerateECDSAKeyPair()
+	k1, _, _ := keys.GenerateSecp256k1KeyPair()
+	x25519, _, _ := keys.GenerateX25519KeyPair()
+	edKey, edPub, _ := keys.GenerateEd25519KeyPair()
+
+	pvKeys := []crypto.PrivateKey{p256, p384, k1, x25519}
+	recipients := []Recipient{
+		{KeyID: "did:byd50:holder#key-agreement-1", PublicKey: &p256.PublicKey},
+		{KeyID: "did:byd50:holder#key-agreement-2", PublicKey: &p384.PublicKey},
+		{KeyID: "did:byd50:holder#key-agreement-3", PublicKey: &k1.PublicKey},
+		{KeyID: "did:byd50:verifier#key-agreement-1", PublicKey: x25519.PublicKey()},
+	}
+	plaintext := []byte("eyJhbGciOiJFUzI1NiJ9.credential.signature")
+	jwe, err := Encrypt(plaintext, "JWT", recipients...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kids := jwe.KeyIDs(); len(kids) != 4 || kids[3] != "did:byd50:verifier#key-agreement-1" {
+		t.Fatalf("unexpected key ids %v", kids)
+	}
+
+	// Every recipient decrypts the serialized message.
+	data, err := json.Marshal(jwe)
+	if err != nil {
+		t.Fatal(err)
+	}
+	parsed, err := Parse(data)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if header, err := parsed.Header(); err != nil || header.Enc != EncA256GCM || header.Cty != "JWT" {
+		t.Fatalf("unexpected header %+v: %v", header, err)
+	}
+	for _, pvKey := range pvKeys {
+		agreer, err := NewKeyAgreer(pvKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, err := parsed.Decrypt(agreer)
+		if err != nil || !bytes.Equal(got, plaintext) {
+			t.Fatalf("%T: unexpected plaintext %q: %v", pvKey, got, err)
+		}
+	}
+
+	// Other keys, tampering and unsupported keys are rejected.
+	other, _, _ := keys.GenerateECDSAKeyPair()
+	agreer, _ := NewKeyAgreer(other)
+	if _, err := parsed.Decrypt(agreer); err == nil {
+		t.Fatal("expected a key that is not a recipient to be rejected")
+	}
+	tampered := *parsed
+	tampered.Protected = base64.RawURLEncoding.EncodeToString([]byte(`{"enc":"A256GCM","cty":"vc+sd-jwt"}`))
+	agreer, _ = NewKeyAgreer(p256)
+	if _, err := tampered.Decrypt(agreer); err == nil {
+		t.Fatal("expected a modified protected header to fail authentication")
+	}
+	if _, err := Encrypt(plaintext, "", Recipient{PublicKey: edPub}); err == nil {
+		t.Fatal("expected an ed25519 recipient to be rejected")
+	}
+	if _, err := NewKeyAgreer(edKey); err == nil {
+		t.Fatal("expected an ed25519 key agreer to be rejected")
+	}
+	if _, err := Encrypt(plaintext, ""); err == nil {
+		t.Fatal("expected a jwe without recipients to be rejected")
+	}
+}
+
+func TestParseSerializations(t *testing.T) {
+	pvKey, _, _ := keys.GenerateX25519KeyPair()
+	jwe, err := Encrypt([]byte("hello"), "", Recipient{PublicKey: pvKey.PublicKey()})
+	if err != nil {
+		t.Fatal(err)
+	}
+	recipient := jwe.Recipients[0]
+	agreer, _ := NewKeyAgreer(pvKey)
+
+	flattened, _ := json.Marshal(map[string]interface{}{
+		"protected":     jwe.Protected,
+		"header":        recipient.Header,
+		"encrypted_key": recipient.EncryptedKey,
+		"iv":            jwe.IV,
+		"ciphertext":    jwe.Ciphertext,
+		"tag":           jwe.Tag,
+	})
+	parsed, err := Parse(flattened)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, err := parsed.Decrypt(agreer); err != nil || string(got) != "hello" {
+		t.Fatalf("flattened: unexpected plaintext %q: %v", got, err)
+	}
+
+	// A compact JWE produced by another JOSE implementation (go-jose), with all members in the protected header.
+	p256, err := keys.ParsePrivateKeyBase58("WejGrq3SkVGYRd7vh7aoFAJcWLy9wUvM9LD6L4wUW86LJv4wBfbHbikPPdr8bFqfknaRpVNYN42ztf3PXsSPgSYLo3nUrDBZWYW5iQVAWcBoWNMsayAcd3cHA8rEFSa1FnGJZ688TKasgJvte14XWk4gkAFowxGq5pEJH")
+	if err != nil {
+		t.Fatal(err)
+	}
+	compact := "eyJhbGciOiJFQ0RILUVTK0EyNTZLVyIsImN0eSI6IkpXVCIsImVuYyI6IkEyNTZHQ00iLCJlcGsiOnsia3R5IjoiRUMiLCJjcnYiOiJQLTI1NiIsIngiOiJUWkFiUnVKUlpZdVZ0dlZlVllaUEhiUGYtaTlwcXhRcEdyRS1iazFtV21NIiwieSI6ImRMaXRabU9LN2ZSSW01NWNBUUppQmtsdDNqYUVpaXd5bXpwUmdhaWNmZ2cifX0." +
+		"tK0E9dBh4jQ-6FAgPiuEgwlRt_ncldFnt3kHS9xcnpGFcT0-oXVI9A.utN_dAr6pbAivjs6._oyXXQs5s-VPiP6oIA.SFdDOukDOfllAg3eVezykw"
+	parsed, err = Parse([]byte(compact))
+	if err != nil {
+		t.Fatal(err)
+	}
+	agreer, _ = NewKeyAgreer(p256)
+	if got, err := parsed.Decrypt(agreer); err != nil || string(got) != "interoperable" {
+		t.Fatalf("compact: unexpected plaintext %q: %v", got, err)
+	}
+	if _, err := Parse([]byte("a.b.c")); err == nil {
+		t.Fatal("expected a malformed compact jwe to be rejected")
+	}
+	if _, err := Parse([]byte(`{"protected":"e30"}`)); err == nil {
+		t.Fatal("expected a jwe without recipients to be rejected")
+	}
+}
@@ -0,0 +1,93 @@
+package jwe
+
+import (
+	"crypto/aes"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/binary"
+	"errors"
+)
+
+// concatKDF derives keyLen bytes from the shared secret z with the single-step KDF of NIST SP 800-56A as profiled
+// by RFC 7518 §4.6.2: AlgorithmID, PartyUInfo and PartyVInfo are length prefixed and SuppPubInfo is the key length
+// in bits.
+func concatKDF(z []byte, alg string, apu, apv []byte, keyLen int) []byte {
+	otherInfo := lengthPrefixed(nil, []byte(alg))
+	otherInfo = lengthPrefixed(otherInfo, apu)
+	otherInfo = lengthPrefixed(otherInfo, apv)
+	otherInfo = binary.BigEndian.AppendUint32(otherInfo, uint32(keyLen*8))
+
+	key := make([]byte, 0, keyLen+sha256.Size)
+	for counter := uint32(1); len(key) < keyLen; counter++ {
+		h := sha256.New()
+		h.Write(binary.BigEndian.AppendUint32(nil, counter))
+		h.Write(z)
+		h.Write(otherInfo)
+		key = h.Sum(key)
+	}
+	return key[:keyLen]
+}
+
+func lengthPrefixed(dst, data []byte) []byte {
+	return append(binary.BigEndian.AppendUint32(dst, uint32(len(data))), data...)
+}
+
+// keyWrapIV is the default initial value of the AES key wrap.
+var keyWrapIV = []byte{0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6, 0xa6}
+
+// wrapKey wraps cek with kek using the AES key wrap of RFC 3394.
+func wrapKey(kek, cek []byte) ([]byte, error) {
+	if len(cek) < 16 || len(cek)%8 != 0 {
+		return nil, errors.New("key to wrap must be a multiple of 64 bits")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(cek) / 8
+	out := make([]byte, 8+len(cek))
+	copy(out, keyWrapIV)
+	copy(out[8:], cek)
+
+	b := make([]byte, 16)
+	for j := 0; j < 6; j++ {
+		for i := 1; i <= n; i++ {
+			copy(b, out[:8])
+			copy(b[8:], out[8*i:8*i+8])
+			block.Encrypt(b, b)
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(out[:8], binary.BigEndian.Uint64(b[:8])^t)
+			copy(out[8*i:], b[8:])
+		}
+	}
+	return out, nil
+}
+
+// unwrapKey reverses wrapKey and verifies the integrity of the wrapped key.
+func unwrapKey(kek, wrapped []byte) ([]byte, error) {
+	if len(wrapped) < 24 || len(wrapped)%8 != 0 {
+		return nil, errors.New("invalid wrapped key length")
+	}
+	block, err := aes.NewCipher(kek)
+	if err != nil {
+		return nil, err
+	}
+	n := len(wrapped)/8 - 1
+	out := append([]byte(nil), wrapped...)
+
+	b := make([]byte, 16)
+	for j := 5; j >= 0; j-- {
+		for i := n; i >= 1; i-- {
+			t := uint64(n*j + i)
+			binary.BigEndian.PutUint64(b[:8], binary.BigEndian.Uint64(out[:8])^t)
+			copy(b[8:], out[8*i:8*i+8])
+			block.Decrypt(b, b)
+			copy(out[:8], b[:8])
+			copy(out[8*i:], b[8:])
+		}
+	}
+	if subtle.ConstantTimeCompare(out[:8], keyWrapIV) != 1 {
+		return nil, errors.New("key unwrap integrity check failed")
+	}
+	return out[8:], nil
+}
@@ -0,0 +1,24 @@
+package oid4vci
+
+import (
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	derrors "byd50-ssi/pkg/did/errors"
+	"crypto"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// CreateProof is the wallet side proof of possession: a JWT of holderDid for the credential issuer and its
+// current c_nonce. pvKey should belong to an authentication key of holderDid.
+func CreateProof(credentialIssuer, cNonce, holderDid string, pvKey crypto.PrivateKey) (string, error) {
+	proofJwt, err := byd50_jwt.SignTyped(holderDid, ProofJwtType, jwt.MapClaims{
+		"aud":   credentialIssuer,
+		"iat":   time.Now().Unix(),
+		"nonce": cNonce,
+	}, pvKey)
+	if err != nil {
+		return "", derrors.Wrap(derrors.CodeInternal, "failed to sign proof", err)
+	}
+	return proofJwt, nil
+}
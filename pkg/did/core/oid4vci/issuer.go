@@ -0,0 +1,343 @@
+package oid4vci
+
+import (
+	"byd50-ssi/pkg/did/core"
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	"byd50-ssi/pkg/did/core/vcdm"
+	derrors "byd50-ssi/pkg/did/errors"
+	"crypto"
+	"crypto/rand"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+	uuid "github.com/satori/go.uuid"
+)
+
+const (
+	// DefaultOfferTTL is how long a pre-authorized code can be redeemed when Issuer.OfferTTL is zero.
+	DefaultOfferTTL = 10 * time.Minute
+	// DefaultCredentialValidity is the lifetime of issued credentials when Issuer.CredentialValidity is zero.
+	DefaultCredentialValidity = 365 * 24 * time.Hour
+	// AccessTokenTTL is the lifetime of access tokens.
+	AccessTokenTTL = 5 * time.Minute
+	// CNonceTTL is the lifetime of a c_nonce.
+	CNonceTTL = 5 * time.Minute
+	// ProofMaxAge is how old the iat of a proof JWT may be.
+	ProofMaxAge = 5 * time.Minute
+	// TxCodeLength is the number of digits of generated transaction codes.
+	TxCodeLength = 6
+	// MaxTxCodeAttempts is how many wrong transaction codes invalidate an offer.
+	MaxTxCodeAttempts = 3
+)
+
+// Issuer is an OID4VCI credential issuer acting as its own authorization server. Offers and access tokens
+// are kept in memory.
+type Issuer struct {
+	// Did signs the credentials with PvKey, which should belong to an assertionMethod key of it.
+	Did   string
+	PvKey crypto.PrivateKey
+	// Configurations are the credentials offered, by credential configuration id.
+	Configurations map[string]CredentialConfiguration
+	// CredentialPath and TokenPath locate the endpoints below the credential issuer URL.
+	CredentialPath string
+	TokenPath      string
+	// GetPbKey resolves the holder keys proofs are verified with, normally authentication keys.
+	GetPbKey           func(string, string) string
+	OfferTTL           time.Duration
+	CredentialValidity time.Duration
+	// OnIssued is called with every issued credential, e.g. to record it in a ledger.
+	OnIssued func(vcJwt string)
+
+	mu     sync.Mutex
+	offers map[string]*offer
+	tokens map[string]*grant
+}
+
+// offer is a credential offer waiting for its pre-authorized code to be redeemed.
+type offer struct {
+	credentialIssuer  string
+	configurationID   string
+	credentialSubject map[string]interface{}
+	txCode            string
+	txCodeAttempts    int
+	expiresAt         time.Time
+}
+
+// grant is an access token and what it may be used for.
+type grant struct {
+	offer
+	tokenExpiresAt  time.Time
+	cNonce          string
+	cNonceExpiresAt time.Time
+	issued          bool
+}
+
+// Metadata returns the credential issuer metadata for the issuer URL credentialIssuer.
+func (i *Issuer) Metadata(credentialIssuer string) IssuerMetadata {
+	return IssuerMetadata{
+		CredentialIssuer:                  credentialIssuer,
+		CredentialEndpoint:                credentialIssuer + i.CredentialPath,
+		CredentialConfigurationsSupported: i.Configurations,
+	}
+}
+
+// AuthorizationServerMetadata returns the metadata of the token endpoint for the issuer URL credentialIssuer.
+func (i *Issuer) AuthorizationServerMetadata(credentialIssuer string) AuthorizationServerMetadata {
+	return AuthorizationServerMetadata{
+		Issuer:              credentialIssuer,
+		TokenEndpoint:       credentialIssuer + i.TokenPath,
+		GrantTypesSupported: []string{GrantTypePreAuthorizedCode},
+		PreAuthorizedGrantAnonymousAccessSupported: true,
+	}
+}
+
+// CreateOffer offers the credential configurationID with credentialSubject to the holder that redeems it.
+// With withTxCode, the returned transaction code must be passed to the holder out of band and entered in the
+// wallet; it is not part of the offer.
+func (i *Issuer) CreateOffer(credentialIssuer, configurationID string, credentialSubject map[string]interface{}, withTxCode bool) (*CredentialOffer, string, error) {
+	cfg, ok := i.Configurations[configurationID]
+	if !ok {
+		return nil, "", derrors.New(derrors.CodeNotFound, "credential configuration not found: "+configurationID)
+	}
+	// fail at offer time rather than at issuance when the subject does not match the credential schema
+	vc := newCredential(cfg, "did:example:holder", credentialSubject)
+	if err := core.ValidateCredentialSchema(vc); err != nil {
+		return nil, "", err
+	}
+
+	pending := &offer{
+		credentialIssuer:  credentialIssuer,
+		configurationID:   configurationID,
+		credentialSubject: credentialSubject,
+		expiresAt:         time.Now().Add(i.offerTTL()),
+	}
+	grant := &PreAuthorizedCodeGrant{PreAuthorizedCode: uuid.NewV4().String()}
+	if withTxCode {
+		txCode, err := randomDigits(TxCodeLength)
+		if err != nil {
+			return nil, "", derrors.Wrap(derrors.CodeInternal, "failed to generate tx_code", err)
+		}
+		pending.txCode = txCode
+		grant.TxCode = &TxCode{InputMode: "numeric", Length: TxCodeLength, Description: "Enter the code sent to you by the issuer"}
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	i.pruneLocked(time.Now())
+	if i.offers == nil {
+		i.offers = map[string]*offer{}
+	}
+	i.offers[grant.PreAuthorizedCode] = pending
+	return &CredentialOffer{
+		CredentialIssuer:           credentialIssuer,
+		CredentialConfigurationIDs: []string{configurationID},
+		Grants:                     Grants{PreAuthorizedCode: grant},
+	}, pending.txCode, nil
+}
+
+// Token redeems a pre-authorized code for an access token. A code is redeemed once, and wrong transaction codes
+// invalidate it after MaxTxCodeAttempts.
+func (i *Issuer) Token(req TokenRequest) (*TokenResponse, error) {
+	if req.GrantType != GrantTypePreAuthorizedCode {
+		return nil, newError(ErrUnsupportedGrantType, "only the pre-authorized code grant is supported")
+	}
+	if req.PreAuthorizedCode == "" {
+		return nil, newError(ErrInvalidRequest, "pre-authorized_code is required")
+	}
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	now := time.Now()
+	pending, ok := i.offers[req.PreAuthorizedCode]
+	if !ok || now.After(pending.expiresAt) {
+		delete(i.offers, req.PreAuthorizedCode)
+		return nil, newError(ErrInvalidGrant, "pre-authorized_code is invalid or expired")
+	}
+	if pending.txCode != "" && req.TxCode != pending.txCode {
+		pending.txCodeAttempts++
+		if pending.txCodeAttempts >= MaxTxCodeAttempts {
+			delete(i.offers, req.PreAuthorizedCode)
+		}
+		return nil, newError(ErrInvalidGrant, "tx_code mismatch")
+	}
+	delete(i.offers, req.PreAuthorizedCode)
+
+	accessToken := uuid.NewV4().String()
+	issued := &grant{offer: *pending, tokenExpiresAt: now.Add(AccessTokenTTL)}
+	issued.renewNonce(now)
+	if i.tokens == nil {
+		i.tokens = map[string]*grant{}
+	}
+	i.tokens[accessToken] = issued
+	return &TokenResponse{
+		AccessToken:     accessToken,
+		TokenType:       "Bearer",
+		ExpiresIn:       int64(AccessTokenTTL / time.Second),
+		CNonce:          issued.cNonce,
+		CNonceExpiresIn: int64(CNonceTTL / time.Second),
+	}, nil
+}
+
+// Credential issues the credential granted to accessToken. The proof JWT must be signed by the holder DID the
+// credential is bound to, name the credential issuer as aud and carry the current c_nonce, which is renewed
+// by every request.
+func (i *Issuer) Credential(accessToken string, req CredentialRequest) (*CredentialResponse, error) {
+	i.mu.Lock()
+	defer i.mu.Unlock()
+	now := time.Now()
+	granted, ok := i.tokens[accessToken]
+	if !ok || now.After(granted.tokenExpiresAt) {
+		return nil, newError(ErrInvalidToken, "access token is invalid or expired")
+	}
+	if granted.issued {
+		return nil, newError(ErrInvalidCredentialRequest, "credential already issued")
+	}
+	if req.Format != FormatJwtVcJson {
+		return nil, newError(ErrUnsupportedCredentialFormat, "unsupported format: "+req.Format)
+	}
+	cfg := i.Configurations[granted.configurationID]
+	if req.CredentialDefinition == nil || !sameTypes(req.CredentialDefinition.Type, cfg.CredentialDefinition.Type) {
+		return nil, newError(ErrUnsupportedCredentialType, "credential_definition does not match the offered credential")
+	}
+	if req.Proof == nil || req.Proof.ProofType != ProofTypeJwt {
+		return nil, i.proofError(granted, now, "a jwt proof is required")
+	}
+	holderDid, err := i.verifyProof(req.Proof.Jwt, granted, now)
+	if err != nil {
+		return nil, i.proofError(granted, now, err.Error())
+	}
+
+	vc := newCredential(cfg, holderDid, granted.credentialSubject)
+	claims, err := vc.ToVcClaims(jwt.StandardClaims{
+		ExpiresAt: now.Add(i.credentialValidity()).Unix(),
+		Id:        core.NewCredentialID(),
+		IssuedAt:  now.Unix(),
+		Issuer:    i.Did,
+		NotBefore: now.Unix(),
+		Subject:   holderDid,
+	}, core.RandomString(12))
+	if err != nil {
+		return nil, newError(ErrServerError, err.Error())
+	}
+	vcJwt := core.CreateVcWithClaims(i.Did, claims, i.PvKey)
+	if vcJwt == "" {
+		return nil, newError(ErrServerError, "failed to sign credential")
+	}
+	granted.issued = true
+	granted.renewNonce(now)
+	if i.OnIssued != nil {
+		i.OnIssued(vcJwt)
+	}
+	return &CredentialResponse{
+		Credential:      vcJwt,
+		CNonce:          granted.cNonce,
+		CNonceExpiresIn: int64(CNonceTTL / time.Second),
+	}, nil
+}
+
+// verifyProof returns the holder DID that signed proofJwt for the current c_nonce of granted.
+func (i *Issuer) verifyProof(proofJwt string, granted *grant, now time.Time) (string, error) {
+	claims, err := byd50_jwt.ParseTyped(proofJwt, ProofJwtType, i.GetPbKey)
+	if err != nil {
+		return "", err
+	}
+	mapClaims := byd50_jwt.MapClaims(claims)
+	if audiences, _ := mapClaims.GetAudience(); !core.Contains(audiences, granted.credentialIssuer) {
+		return "", derrors.New(derrors.CodeInvalidInput, "proof aud is not the credential issuer")
+	}
+	if nonce, _ := mapClaims["nonce"].(string); nonce != granted.cNonce || now.After(granted.cNonceExpiresAt) {
+		return "", derrors.New(derrors.CodeInvalidInput, "proof nonce is not the current c_nonce")
+	}
+	iat, err := mapClaims.GetIssuedAt()
+	if err != nil || now.Sub(time.Unix(iat, 0)) > ProofMaxAge {
+		return "", derrors.New(derrors.CodeInvalidInput, "proof iat is missing or too old")
+	}
+	// the kid may be a DID URL selecting a key of the holder; the credential is bound to the DID itself
+	holderDid, err := core.GetSignerDid(proofJwt)
+	if err != nil {
+		return "", err
+	}
+	if iss, ok := mapClaims["iss"].(string); ok && iss != holderDid {
+		return "", derrors.New(derrors.CodeInvalidInput, "proof iss is not the holder DID")
+	}
+	return holderDid, nil
+}
+
+// proofError rejects a proof with a fresh c_nonce for the retry.
+func (i *Issuer) proofError(granted *grant, now time.Time, description string) *Error {
+	granted.renewNonce(now)
+	return &Error{
+		Code:            ErrInvalidProof,
+		Description:     description,
+		CNonce:          granted.cNonce,
+		CNonceExpiresIn: int64(CNonceTTL / time.Second),
+	}
+}
+
+func (g *grant) renewNonce(now time.Time) {
+	g.cNonce = uuid.NewV4().String()
+	g.cNonceExpiresAt = now.Add(CNonceTTL)
+}
+
+// pruneLocked drops expired offers and access tokens.
+func (i *Issuer) pruneLocked(now time.Time) {
+	for code, pending := range i.offers {
+		if now.After(pending.expiresAt) {
+			delete(i.offers, code)
+		}
+	}
+	for token, granted := range i.tokens {
+		if now.After(granted.tokenExpiresAt) {
+			delete(i.tokens, token)
+		}
+	}
+}
+
+func (i *Issuer) offerTTL() time.Duration {
+	if i.OfferTTL > 0 {
+		return i.OfferTTL
+	}
+	return DefaultOfferTTL
+}
+
+func (i *Issuer) credentialValidity() time.Duration {
+	if i.CredentialValidity > 0 {
+		return i.CredentialValidity
+	}
+	return DefaultCredentialValidity
+}
+
+// newCredential builds the credential of cfg for holderDid.
+func newCredential(cfg CredentialConfiguration, holderDid string, credentialSubject map[string]interface{}) *vcdm.VerifiableCredential {
+	vc := vcdm.NewCredentialV1("", vcdm.CredentialSubject{ID: holderDid, Claims: credentialSubject})
+	vc.Type = append([]string(nil), cfg.CredentialDefinition.Type...)
+	return vc
+}
+
+// sameTypes reports whether a and b hold the same types in any order.
+func sameTypes(a, b []string) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	a = append([]string(nil), a...)
+	b = append([]string(nil), b...)
+	sort.Strings(a)
+	sort.Strings(b)
+	return strings.Join(a, "\x00") == strings.Join(b, "\x00")
+}
+
+func randomDigits(n int) (string, error) {
+	digits := make([]byte, n)
+	for k := range digits {
+		d, err := rand.Int(rand.Reader, big.NewInt(10))
+		if err != nil {
+			return "", err
+		}
+		digits[k] = byte('0' + d.Int64())
+	}
+	return string(digits), nil
+}
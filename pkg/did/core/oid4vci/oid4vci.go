@@ -0,0 +1,167 @@
+// Package oid4vci implements the issuer side of OpenID for Verifiable Credential Issuance (draft 13) with the
+// pre-authorized code flow: the issuer publishes its metadata, creates credential offers carrying a
+// pre-authorized code (optionally protected by a transaction code sent out of band), exchanges the code for an
+// access token at its token endpoint and issues jwt_vc_json credentials at its credential endpoint against a
+// proof of possession JWT signed by the holder DID.
+package oid4vci
+
+import (
+	"encoding/json"
+	"net/url"
+)
+
+const (
+	// FormatJwtVcJson is the credential format of JWT VCs following the VC data model.
+	FormatJwtVcJson = "jwt_vc_json"
+	// GrantTypePreAuthorizedCode is the grant type of the pre-authorized code flow.
+	GrantTypePreAuthorizedCode = "urn:ietf:params:oauth:grant-type:pre-authorized_code"
+	// ProofTypeJwt is the proof of possession type of DID signed JWTs.
+	ProofTypeJwt = "jwt"
+	// ProofJwtType is the typ header of proof JWTs.
+	ProofJwtType = "openid4vci-proof+jwt"
+	// OfferScheme is the custom URL scheme wallets register for credential offers.
+	OfferScheme = "openid-credential-offer://"
+	// BindingMethodDid binds credentials to the DID that signs the proof.
+	BindingMethodDid = "did"
+)
+
+// IssuerMetadata is served at /.well-known/openid-credential-issuer.
+type IssuerMetadata struct {
+	CredentialIssuer                  string                             `json:"credential_issuer"`
+	AuthorizationServers              []string                           `json:"authorization_servers,omitempty"`
+	CredentialEndpoint                string                             `json:"credential_endpoint"`
+	CredentialConfigurationsSupported map[string]CredentialConfiguration `json:"credential_configurations_supported"`
+}
+
+// AuthorizationServerMetadata is served at /.well-known/oauth-authorization-server when the credential issuer
+// is its own authorization server.
+type AuthorizationServerMetadata struct {
+	Issuer                                     string   `json:"issuer"`
+	TokenEndpoint                              string   `json:"token_endpoint"`
+	GrantTypesSupported                        []string `json:"grant_types_supported"`
+	PreAuthorizedGrantAnonymousAccessSupported bool     `json:"pre-authorized_grant_anonymous_access_supported"`
+}
+
+// CredentialConfiguration describes a credential the issuer offers.
+type CredentialConfiguration struct {
+	Format                               string                       `json:"format"`
+	CryptographicBindingMethodsSupported []string                     `json:"cryptographic_binding_methods_supported,omitempty"`
+	CredentialSigningAlgValuesSupported  []string                     `json:"credential_signing_alg_values_supported,omitempty"`
+	ProofTypesSupported                  map[string]ProofTypeMetadata `json:"proof_types_supported,omitempty"`
+	CredentialDefinition                 CredentialDefinition         `json:"credential_definition"`
+	Display                              []map[string]interface{}     `json:"display,omitempty"`
+}
+
+// ProofTypeMetadata lists the algorithms accepted for a proof type.
+type ProofTypeMetadata struct {
+	ProofSigningAlgValuesSupported []string `json:"proof_signing_alg_values_supported"`
+}
+
+// CredentialDefinition identifies a jwt_vc_json credential by its types.
+type CredentialDefinition struct {
+	Type []string `json:"type"`
+}
+
+// CredentialOffer is passed to the wallet, by value in a credential_offer parameter.
+type CredentialOffer struct {
+	CredentialIssuer           string   `json:"credential_issuer"`
+	CredentialConfigurationIDs []string `json:"credential_configuration_ids"`
+	Grants                     Grants   `json:"grants"`
+}
+
+// Grants holds the grants a credential offer may be redeemed with.
+type Grants struct {
+	PreAuthorizedCode *PreAuthorizedCodeGrant `json:"urn:ietf:params:oauth:grant-type:pre-authorized_code,omitempty"`
+}
+
+// PreAuthorizedCodeGrant carries the pre-authorized code of an offer. When TxCode is set, the token request
+// must also carry the transaction code the holder received out of band.
+type PreAuthorizedCodeGrant struct {
+	PreAuthorizedCode string  `json:"pre-authorized_code"`
+	TxCode            *TxCode `json:"tx_code,omitempty"`
+}
+
+// TxCode describes the transaction code a wallet asks the holder for.
+type TxCode struct {
+	InputMode   string `json:"input_mode,omitempty"`
+	Length      int    `json:"length,omitempty"`
+	Description string `json:"description,omitempty"`
+}
+
+// TokenRequest is the form posted to the token endpoint.
+type TokenRequest struct {
+	GrantType         string `form:"grant_type" json:"grant_type"`
+	PreAuthorizedCode string `form:"pre-authorized_code" json:"pre-authorized_code"`
+	TxCode            string `form:"tx_code" json:"tx_code,omitempty"`
+}
+
+// TokenResponse is the access token and the first c_nonce for proofs.
+type TokenResponse struct {
+	AccessToken     string `json:"access_token"`
+	TokenType       string `json:"token_type"`
+	ExpiresIn       int64  `json:"expires_in"`
+	CNonce          string `json:"c_nonce"`
+	CNonceExpiresIn int64  `json:"c_nonce_expires_in"`
+}
+
+// CredentialRequest is the body posted to the credential endpoint.
+type CredentialRequest struct {
+	Format               string                `json:"format"`
+	CredentialDefinition *CredentialDefinition `json:"credential_definition,omitempty"`
+	Proof                *Proof                `json:"proof,omitempty"`
+}
+
+// Proof is the proof of possession of the key the credential is bound to.
+type Proof struct {
+	ProofType string `json:"proof_type"`
+	Jwt       string `json:"jwt"`
+}
+
+// CredentialResponse is the issued credential and a fresh c_nonce for the next request.
+type CredentialResponse struct {
+	Credential      string `json:"credential"`
+	CNonce          string `json:"c_nonce"`
+	CNonceExpiresIn int64  `json:"c_nonce_expires_in"`
+}
+
+// Error is an OAuth 2.0 error of the token or credential endpoint.
+type Error struct {
+	Code        string `json:"error"`
+	Description string `json:"error_description,omitempty"`
+	// CNonce is a fresh nonce returned with invalid_proof, so the wallet can retry.
+	CNonce          string `json:"c_nonce,omitempty"`
+	CNonceExpiresIn int64  `json:"c_nonce_expires_in,omitempty"`
+}
+
+// Error codes of the token and credential endpoints.
+const (
+	ErrInvalidRequest              = "invalid_request"
+	ErrInvalidGrant                = "invalid_grant"
+	ErrUnsupportedGrantType        = "unsupported_grant_type"
+	ErrInvalidToken                = "invalid_token"
+	ErrInvalidProof                = "invalid_proof"
+	ErrUnsupportedCredentialFormat = "unsupported_credential_format"
+	ErrUnsupportedCredentialType   = "unsupported_credential_type"
+	ErrInvalidCredentialRequest    = "invalid_credential_request"
+	ErrServerError                 = "server_error"
+)
+
+func (e *Error) Error() string {
+	if e.Description == "" {
+		return e.Code
+	}
+	return e.Code + ": " + e.Description
+}
+
+func newError(code, description string) *Error {
+	return &Error{Code: code, Description: description}
+}
+
+// URI encodes the offer by value as an OfferScheme URL.
+func (o *CredentialOffer) URI() (string, error) {
+	data, err := json.Marshal(o)
+	if err != nil {
+		return "", err
+	}
+	return OfferScheme + "?" + url.Values{"credential_offer": {string(data)}}.Encode(), nil
+}
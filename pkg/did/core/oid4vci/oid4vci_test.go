@@ -0,0 +1,226 @@
+package oid4vci
+
+import (
+	"byd50-ssi/pkg/did/core"
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	credentialIssuer = "https://issuer.example.com"
+	issuerDid        = "did:byd50:issuer"
+	holderDid        = "did:byd50:holder"
+	configurationID  = "UniversityDegree_jwt_vc_json"
+)
+
+type testKeys map[string]*ecdsa.PrivateKey
+
+func (k testKeys) getPbKey(did, _ string) string {
+	pvKey, ok := k[did]
+	if !ok {
+		return ""
+	}
+	pbBytes, _ := x509.MarshalPKIXPublicKey(&pvKey.PublicKey)
+	return base58.Encode(pbBytes)
+}
+
+func newTestIssuer(t *testing.T) (*Issuer, testKeys, *[]string) {
+	k := testKeys{}
+	for _, did := range []string{issuerDid, holderDid, "did:byd50:other"} {
+		pvKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k[did] = pvKey
+	}
+	var issued []string
+	issuer := &Issuer{
+		Did:   issuerDid,
+		PvKey: k[issuerDid],
+		Configurations: map[string]CredentialConfiguration{
+			configurationID: {
+				Format:               FormatJwtVcJson,
+				CredentialDefinition: CredentialDefinition{Type: []string{"VerifiableCredential", "UniversityDegreeCredential"}},
+			},
+		},
+		CredentialPath: "/credential",
+		TokenPath:      "/token",
+		GetPbKey:       k.getPbKey,
+		OnIssued:       func(vcJwt string) { issued = append(issued, vcJwt) },
+	}
+	return issuer, k, &issued
+}
+
+func credentialRequest(proofJwt string) CredentialRequest {
+	return CredentialRequest{
+		Format:               FormatJwtVcJson,
+		CredentialDefinition: &CredentialDefinition{Type: []string{"UniversityDegreeCredential", "VerifiableCredential"}},
+		Proof:                &Proof{ProofType: ProofTypeJwt, Jwt: proofJwt},
+	}
+}
+
+func errorCode(err error) string {
+	var oauthErr *Error
+	if errors.As(err, &oauthErr) {
+		return oauthErr.Code
+	}
+	return ""
+}
+
+func TestPreAuthorizedCodeFlow(t *testing.T) {
+	issuer, k, issued := newTestIssuer(t)
+	if md := issuer.Metadata(credentialIssuer); md.CredentialEndpoint != credentialIssuer+"/credential" {
+		t.Fatalf("unexpected metadata %+v", md)
+	}
+
+	offer, txCode, err := issuer.CreateOffer(credentialIssuer, configurationID, map[string]interface{}{"degree": "BachelorDegree"}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txCode) != TxCodeLength || offer.Grants.PreAuthorizedCode.TxCode == nil {
+		t.Fatalf("expected a tx_code, got %q", txCode)
+	}
+	uri, err := offer.URI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(uri, txCode) {
+		t.Fatal("tx_code must not be part of the offer")
+	}
+	query, _ := url.ParseQuery(strings.TrimPrefix(uri, OfferScheme+"?"))
+	var decoded CredentialOffer
+	if err := json.Unmarshal([]byte(query.Get("credential_offer")), &decoded); err != nil {
+		t.Fatal(err)
+	}
+	code := decoded.Grants.PreAuthorizedCode.PreAuthorizedCode
+
+	if _, err := issuer.Token(TokenRequest{GrantType: GrantTypePreAuthorizedCode, PreAuthorizedCode: code, TxCode: "000000x"}); errorCode(err) != ErrInvalidGrant {
+		t.Fatalf("expected invalid_grant for a wrong tx_code, got %v", err)
+	}
+	token, err := issuer.Token(TokenRequest{GrantType: GrantTypePreAuthorizedCode, PreAuthorizedCode: code, TxCode: txCode})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := issuer.Token(TokenRequest{GrantType: GrantTypePreAuthorizedCode, PreAuthorizedCode: code, TxCode: txCode}); errorCode(err) != ErrInvalidGrant {
+		t.Fatalf("expected a redeemed code to be rejected, got %v", err)
+	}
+
+	// a proof for a stale nonce is rejected with a fresh c_nonce to retry with
+	stale, _ := CreateProof(credentialIssuer, "stale", holderDid, k[holderDid])
+	_, err = issuer.Credential(token.AccessToken, credentialRequest(stale))
+	var proofErr *Error
+	if !errors.As(err, &proofErr) || proofErr.Code != ErrInvalidProof || proofErr.CNonce == "" || proofErr.CNonce == token.CNonce {
+		t.Fatalf("expected invalid_proof with a new c_nonce, got %v", err)
+	}
+	// the kid of a proof may be a DID URL; the credential is issued to the DID
+	proof, _ := CreateProof(credentialIssuer, proofErr.CNonce, holderDid+"#keys-1", k[holderDid])
+	response, err := issuer.Credential(token.AccessToken, credentialRequest(proof))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	ok, claims, err := core.GetVcMapClaims(response.Credential, k.getPbKey)
+	if !ok || err != nil {
+		t.Fatalf("issued credential does not verify: %v", err)
+	}
+	if claims["sub"] != holderDid || claims["iss"] != issuerDid {
+		t.Fatalf("unexpected credential claims %v", claims)
+	}
+	subject := claims["vc"].(map[string]interface{})["credentialSubject"].(map[string]interface{})
+	if subject["id"] != holderDid || subject["degree"] != "BachelorDegree" {
+		t.Fatalf("unexpected credentialSubject %v", subject)
+	}
+	if len(*issued) != 1 || (*issued)[0] != response.Credential {
+		t.Fatal("expected OnIssued to record the credential")
+	}
+	proof, _ = CreateProof(credentialIssuer, response.CNonce, holderDid, k[holderDid])
+	if _, err := issuer.Credential(token.AccessToken, credentialRequest(proof)); errorCode(err) != ErrInvalidCredentialRequest {
+		t.Fatalf("expected a second credential to be refused, got %v", err)
+	}
+}
+
+func TestTxCodeAttempts(t *testing.T) {
+	issuer, _, _ := newTestIssuer(t)
+	offer, txCode, err := issuer.CreateOffer(credentialIssuer, configurationID, map[string]interface{}{}, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	code := offer.Grants.PreAuthorizedCode.PreAuthorizedCode
+	for n := 0; n < MaxTxCodeAttempts; n++ {
+		if _, err := issuer.Token(TokenRequest{GrantType: GrantTypePreAuthorizedCode, PreAuthorizedCode: code}); errorCode(err) != ErrInvalidGrant {
+			t.Fatalf("expected invalid_grant, got %v", err)
+		}
+	}
+	if _, err := issuer.Token(TokenRequest{GrantType: GrantTypePreAuthorizedCode, PreAuthorizedCode: code, TxCode: txCode}); errorCode(err) != ErrInvalidGrant {
+		t.Fatalf("expected the offer to be invalidated, got %v", err)
+	}
+	if _, _, err := issuer.CreateOffer(credentialIssuer, "unknown", nil, false); err == nil {
+		t.Fatal("expected unknown configuration to be rejected")
+	}
+	if _, err := issuer.Token(TokenRequest{GrantType: "authorization_code", PreAuthorizedCode: code}); errorCode(err) != ErrUnsupportedGrantType {
+		t.Fatalf("expected unsupported_grant_type, got %v", err)
+	}
+}
+
+func TestCredentialRequestChecks(t *testing.T) {
+	issuer, k, _ := newTestIssuer(t)
+	offer, _, err := issuer.CreateOffer(credentialIssuer, configurationID, map[string]interface{}{"degree": "BachelorDegree"}, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, err := issuer.Token(TokenRequest{GrantType: GrantTypePreAuthorizedCode, PreAuthorizedCode: offer.Grants.PreAuthorizedCode.PreAuthorizedCode})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := issuer.Credential("unknown", credentialRequest("")); errorCode(err) != ErrInvalidToken {
+		t.Fatalf("expected invalid_token, got %v", err)
+	}
+
+	request := credentialRequest("")
+	request.Format = "ldp_vc"
+	if _, err := issuer.Credential(token.AccessToken, request); errorCode(err) != ErrUnsupportedCredentialFormat {
+		t.Fatalf("expected unsupported_credential_format, got %v", err)
+	}
+	request = credentialRequest("")
+	request.CredentialDefinition.Type = []string{"VerifiableCredential", "OtherCredential"}
+	if _, err := issuer.Credential(token.AccessToken, request); errorCode(err) != ErrUnsupportedCredentialType {
+		t.Fatalf("expected unsupported_credential_type, got %v", err)
+	}
+
+	// proofs for another issuer, or with a key that is not the one of their kid, are rejected
+	nonce := token.CNonce
+	wrongAud, _ := CreateProof("https://other.example.com", nonce, holderDid, k[holderDid])
+	_, err = issuer.Credential(token.AccessToken, credentialRequest(wrongAud))
+	var proofErr *Error
+	if !errors.As(err, &proofErr) || proofErr.Code != ErrInvalidProof {
+		t.Fatalf("expected invalid_proof for a wrong aud, got %v", err)
+	}
+	forged, _ := CreateProof(credentialIssuer, proofErr.CNonce, holderDid, k["did:byd50:other"])
+	_, err = issuer.Credential(token.AccessToken, credentialRequest(forged))
+	if !errors.As(err, &proofErr) || proofErr.Code != ErrInvalidProof {
+		t.Fatalf("expected invalid_proof for a forged signature, got %v", err)
+	}
+
+	// an iss other than the DID of the kid is rejected
+	otherIss, _ := byd50_jwt.SignTyped(holderDid+"#keys-1", ProofJwtType, jwt.MapClaims{
+		"iss":   "did:byd50:other",
+		"aud":   credentialIssuer,
+		"iat":   time.Now().Unix(),
+		"nonce": proofErr.CNonce,
+	}, k[holderDid])
+	if _, err = issuer.Credential(token.AccessToken, credentialRequest(otherIss)); errorCode(err) != ErrInvalidProof {
+		t.Fatalf("expected invalid_proof for an iss of another DID, got %v", err)
+	}
+}
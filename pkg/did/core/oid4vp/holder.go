@@ -0,0 +1,41 @@
+package oid4vp
+
+import (
+	"byd50-ssi/pkg/did/core"
+	"byd50-ssi/pkg/did/core/pex"
+	derrors "byd50-ssi/pkg/did/errors"
+	"crypto"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// CreateResponse answers r as a wallet: the credentials of vcJwts that satisfy its presentation definition are
+// presented in a VP of holderDid whose aud is the verifier and whose nonce is the one of the request.
+// pvKey should belong to an authentication key of holderDid.
+func CreateResponse(r *AuthorizationRequest, holderDid string, vcJwts []string, validity time.Duration, pvKey crypto.PrivateKey) (*Response, error) {
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	vp, err := pex.BuildPresentation("", r.PresentationDefinition, vcJwts)
+	if err != nil {
+		return nil, err
+	}
+	now := time.Now()
+	claims, err := vp.ToVpClaims(jwt.StandardClaims{
+		Audience:  r.ClientID,
+		ExpiresAt: now.Add(validity).Unix(),
+		Id:        core.NewCredentialID(),
+		IssuedAt:  now.Unix(),
+		Issuer:    holderDid,
+	}, r.Nonce)
+	if err != nil {
+		return nil, err
+	}
+	vpToken := core.CreateVpWithClaims(holderDid, claims, pvKey)
+	if vpToken == "" {
+		return nil, derrors.New(derrors.CodeInternal, "failed to sign vp")
+	}
+	submission, _ := vp.Extra[pex.SubmissionClaim].(*pex.PresentationSubmission)
+	return &Response{VpToken: vpToken, PresentationSubmission: submission, State: r.State}, nil
+}
@@ -0,0 +1,167 @@
+// Package oid4vp implements OpenID for Verifiable Presentations (draft 20) on top of the DIF Presentation
+// Exchange support of package pex. The verifier creates authorization requests, passed to the wallet by value
+// or by reference through a request_uri serving a signed request object, and accepts direct_post responses
+// carrying a vp_token and its presentation_submission.
+//
+// The verifier is identified by its DID (client_id_scheme "did") and signs request objects with a key of it.
+// Only JWT presentations (jwt_vp) with JWT credentials (jwt_vc) are supported.
+package oid4vp
+
+import (
+	"byd50-ssi/pkg/did/core"
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	"byd50-ssi/pkg/did/core/pex"
+	derrors "byd50-ssi/pkg/did/errors"
+	"crypto"
+	"encoding/json"
+	"net/url"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	// Scheme is the custom URL scheme wallets register for authorization requests.
+	Scheme = "openid4vp://"
+	// ResponseTypeVpToken requests a vp_token.
+	ResponseTypeVpToken = "vp_token"
+	// ResponseModeDirectPost makes the wallet POST the response to response_uri.
+	ResponseModeDirectPost = "direct_post"
+	// ClientIDSchemeDid identifies the verifier by a DID whose keys sign the request object.
+	ClientIDSchemeDid = "did"
+	// RequestObjectType is the typ header of signed request objects.
+	RequestObjectType = "oauth-authz-req+jwt"
+	// SelfIssuedAudience is the aud of request objects for wallets without a known issuer identifier.
+	SelfIssuedAudience = "https://self-issued.me/v2"
+)
+
+// AuthorizationRequest is an OID4VP authorization request with response_mode direct_post.
+type AuthorizationRequest struct {
+	ResponseType           string                      `json:"response_type"`
+	ClientID               string                      `json:"client_id"`
+	ClientIDScheme         string                      `json:"client_id_scheme,omitempty"`
+	ResponseMode           string                      `json:"response_mode"`
+	ResponseURI            string                      `json:"response_uri"`
+	Nonce                  string                      `json:"nonce"`
+	State                  string                      `json:"state"`
+	PresentationDefinition *pex.PresentationDefinition `json:"presentation_definition"`
+	ClientMetadata         *ClientMetadata             `json:"client_metadata,omitempty"`
+}
+
+// ClientMetadata describes the verifier to the wallet.
+type ClientMetadata struct {
+	VpFormats pex.Formats `json:"vp_formats,omitempty"`
+}
+
+// Response is the authorization response a wallet posts to response_uri.
+type Response struct {
+	VpToken                string                      `json:"vp_token"`
+	PresentationSubmission *pex.PresentationSubmission `json:"presentation_submission"`
+	State                  string                      `json:"state"`
+}
+
+// requestObjectClaims are the claims of a signed request object: the request parameters and iss/aud/iat/exp.
+type requestObjectClaims struct {
+	AuthorizationRequest
+	jwt.StandardClaims
+}
+
+// Validate checks the parameters this package relies on.
+func (r *AuthorizationRequest) Validate() error {
+	switch {
+	case r.ResponseType != ResponseTypeVpToken:
+		return derrors.New(derrors.CodeInvalidInput, "unsupported response_type: "+r.ResponseType)
+	case r.ResponseMode != ResponseModeDirectPost:
+		return derrors.New(derrors.CodeInvalidInput, "unsupported response_mode: "+r.ResponseMode)
+	case r.ClientID == "" || r.ResponseURI == "":
+		return derrors.New(derrors.CodeInvalidInput, "client_id and response_uri are required")
+	case r.Nonce == "" || r.State == "":
+		return derrors.New(derrors.CodeInvalidInput, "nonce and state are required")
+	case r.PresentationDefinition == nil:
+		return derrors.New(derrors.CodeInvalidInput, "presentation_definition is required")
+	}
+	return r.PresentationDefinition.Validate()
+}
+
+// URI encodes the request by value as a Scheme URL. JSON valued parameters are JSON encoded.
+func (r *AuthorizationRequest) URI() (string, error) {
+	definition, err := json.Marshal(r.PresentationDefinition)
+	if err != nil {
+		return "", derrors.Wrap(derrors.CodeInternal, "failed to encode presentation_definition", err)
+	}
+	params := url.Values{}
+	params.Set("response_type", r.ResponseType)
+	params.Set("client_id", r.ClientID)
+	if r.ClientIDScheme != "" {
+		params.Set("client_id_scheme", r.ClientIDScheme)
+	}
+	params.Set("response_mode", r.ResponseMode)
+	params.Set("response_uri", r.ResponseURI)
+	params.Set("nonce", r.Nonce)
+	params.Set("state", r.State)
+	params.Set("presentation_definition", string(definition))
+	if r.ClientMetadata != nil {
+		metadata, err := json.Marshal(r.ClientMetadata)
+		if err != nil {
+			return "", derrors.Wrap(derrors.CodeInternal, "failed to encode client_metadata", err)
+		}
+		params.Set("client_metadata", string(metadata))
+	}
+	return Scheme + "?" + params.Encode(), nil
+}
+
+// RequestURI encodes a request passed by reference: the wallet fetches the signed request object at requestURI.
+func RequestURI(clientID, requestURI string) string {
+	params := url.Values{}
+	params.Set("client_id", clientID)
+	params.Set("request_uri", requestURI)
+	return Scheme + "?" + params.Encode()
+}
+
+// SignRequestObject signs r as a request object of the verifier r.ClientID, valid for validity.
+// pvKey should belong to an authentication key of the verifier DID.
+func SignRequestObject(r *AuthorizationRequest, validity time.Duration, pvKey crypto.PrivateKey) (string, error) {
+	if err := r.Validate(); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	claims := requestObjectClaims{
+		AuthorizationRequest: *r,
+		StandardClaims: jwt.StandardClaims{
+			Audience:  SelfIssuedAudience,
+			ExpiresAt: now.Add(validity).Unix(),
+			IssuedAt:  now.Unix(),
+			Issuer:    r.ClientID,
+		},
+	}
+	requestObject, err := byd50_jwt.SignTyped(r.ClientID, RequestObjectType, claims, pvKey)
+	if err != nil {
+		return "", derrors.Wrap(derrors.CodeInternal, "failed to sign request object", err)
+	}
+	return requestObject, nil
+}
+
+// ParseRequestObject verifies a signed request object as a wallet does: the signature must resolve to a key
+// of the client_id DID, which is also its issuer.
+func ParseRequestObject(requestObject string, getPbKey func(string, string) string) (*AuthorizationRequest, error) {
+	mapClaims, err := byd50_jwt.ParseTyped(requestObject, RequestObjectType, getPbKey)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "request object invalid", err)
+	}
+	data, err := json.Marshal(mapClaims)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "request object invalid", err)
+	}
+	claims := new(requestObjectClaims)
+	if err := json.Unmarshal(data, claims); err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "request object invalid", err)
+	}
+	signer, err := core.GetSignerDid(requestObject)
+	if err != nil || signer != claims.ClientID || claims.Issuer != claims.ClientID {
+		return nil, derrors.New(derrors.CodeInvalidInput, "request object is not signed by its client_id")
+	}
+	if err := claims.AuthorizationRequest.Validate(); err != nil {
+		return nil, err
+	}
+	return &claims.AuthorizationRequest, nil
+}
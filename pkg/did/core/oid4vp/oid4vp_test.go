@@ -0,0 +1,244 @@
+package oid4vp
+
+import (
+	"byd50-ssi/pkg/did/core"
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	"byd50-ssi/pkg/did/core/pex"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	verifierDid = "did:byd50:verifier"
+	holderDid   = "did:byd50:holder"
+	issuerDid   = "did:byd50:issuer"
+)
+
+const testDefinition = `{
+  "id": "alumni-verification",
+  "input_descriptors": [{
+    "id": "alumni",
+    "constraints": {
+      "fields": [{"path": ["$.vc.type"], "filter": {"type": "array", "contains": {"const": "AlumniCredential"}}}]
+    }
+  }]
+}`
+
+type testKeys map[string]*ecdsa.PrivateKey
+
+func newTestKeys(t *testing.T, dids ...string) testKeys {
+	k := testKeys{}
+	for _, did := range dids {
+		pvKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k[did] = pvKey
+	}
+	return k
+}
+
+func (k testKeys) getPbKey(did, _ string) string {
+	pvKey, ok := k[did]
+	if !ok {
+		return ""
+	}
+	pbBytes, _ := x509.MarshalPKIXPublicKey(&pvKey.PublicKey)
+	return base58.Encode(pbBytes)
+}
+
+func newTestVerifier(t *testing.T) (*Verifier, testKeys, *pex.PresentationDefinition, string) {
+	k := newTestKeys(t, verifierDid, holderDid, issuerDid)
+	def, err := pex.ParseDefinition([]byte(testDefinition))
+	if err != nil {
+		t.Fatal(err)
+	}
+	vcJwt := core.CreateVc(issuerDid, "AlumniCredential", map[string]interface{}{"degree": "BachelorDegree"}, jwt.StandardClaims{
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		IssuedAt:  time.Now().Unix(),
+		Issuer:    issuerDid,
+		Subject:   holderDid,
+	}, k[issuerDid])
+	v := &Verifier{
+		ClientID:        verifierDid,
+		ResponseURI:     "https://verifier.example.com/oid4vp/response",
+		PvKey:           k[verifierDid],
+		GetAuthKey:      k.getPbKey,
+		GetAssertionKey: k.getPbKey,
+	}
+	return v, k, def, vcJwt
+}
+
+func TestRequestByValueAndReference(t *testing.T) {
+	v, k, def, _ := newTestVerifier(t)
+	session, err := v.CreateSession(def, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	uri, err := session.Request.URI()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(uri, Scheme) {
+		t.Fatalf("unexpected uri %s", uri)
+	}
+	query, err := url.ParseQuery(strings.TrimPrefix(uri, Scheme+"?"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if query.Get("state") != session.ID || query.Get("nonce") != session.Request.Nonce || query.Get("response_mode") != ResponseModeDirectPost {
+		t.Fatalf("unexpected request parameters %v", query)
+	}
+	if _, err := pex.ParseDefinition([]byte(query.Get("presentation_definition"))); err != nil {
+		t.Fatalf("presentation_definition not encoded by value: %v", err)
+	}
+
+	requestObject, err := v.RequestObject(session.ID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	request, err := ParseRequestObject(requestObject, k.getPbKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.State != session.ID || request.Nonce != session.Request.Nonce || request.PresentationDefinition.ID != def.ID {
+		t.Fatalf("unexpected request object %+v", request)
+	}
+	if got, _ := v.Session(session.ID); got.Status != StatusRequestRetrieved {
+		t.Fatalf("expected request_retrieved, got %s", got.Status)
+	}
+
+	// a request object naming the verifier as client_id but signed with another key is rejected
+	forgedObject, err := SignRequestObject(&session.Request, time.Minute, k[holderDid])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseRequestObject(forgedObject, k.getPbKey); err == nil {
+		t.Fatal("expected request object signed with a key of another DID to be rejected")
+	}
+
+	// a kid that is a DID URL of client_id is accepted, one of another DID is not
+	token, _, err := new(jwt.Parser).ParseUnverified(requestObject, jwt.MapClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	urlObject, err := byd50_jwt.SignTyped(verifierDid+"#keys-1", RequestObjectType, token.Claims, k[verifierDid])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseRequestObject(urlObject, k.getPbKey); err != nil {
+		t.Fatalf("expected request object with a did url kid to verify: %v", err)
+	}
+	urlObject, err = byd50_jwt.SignTyped(holderDid+"#keys-1", RequestObjectType, token.Claims, k[holderDid])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ParseRequestObject(urlObject, k.getPbKey); err == nil {
+		t.Fatal("expected request object signed with a key of another DID to be rejected")
+	}
+}
+
+func TestDirectPostResponse(t *testing.T) {
+	v, k, def, vcJwt := newTestVerifier(t)
+	session, err := v.CreateSession(def, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := CreateResponse(&session.Request, holderDid, []string{vcJwt}, time.Minute, k[holderDid])
+	if err != nil {
+		t.Fatal(err)
+	}
+	verified, err := v.HandleResponse(*response)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verified.Status != StatusVerified || verified.HolderDid != holderDid || verified.Credentials["alumni"] != vcJwt {
+		t.Fatalf("unexpected session %+v", verified)
+	}
+	if _, err := v.HandleResponse(*response); err == nil {
+		t.Fatal("expected a second response to the same session to be rejected")
+	}
+	if _, err := v.HandleResponse(Response{State: "unknown"}); err == nil {
+		t.Fatal("expected unknown state to be rejected")
+	}
+}
+
+func TestDirectPostResponseBinding(t *testing.T) {
+	v, k, def, vcJwt := newTestVerifier(t)
+	first, _ := v.CreateSession(def, "")
+	second, _ := v.CreateSession(def, "")
+
+	// a presentation made for another request (nonce) is rejected by the session it is replayed to
+	response, err := CreateResponse(&first.Request, holderDid, []string{vcJwt}, time.Minute, k[holderDid])
+	if err != nil {
+		t.Fatal(err)
+	}
+	response.State = second.ID
+	failed, err := v.HandleResponse(*response)
+	if err == nil || !failed.pending() || !strings.Contains(failed.Error, "nonce") {
+		t.Fatalf("expected nonce mismatch, got %v %+v", err, failed)
+	}
+
+	// a rejected response leaves the session open for the holder's own response
+	response, err = CreateResponse(&second.Request, holderDid, []string{vcJwt}, time.Minute, k[holderDid])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if verified, err := v.HandleResponse(*response); err != nil || verified.Status != StatusVerified {
+		t.Fatalf("expected the session to still verify, got %v", err)
+	}
+
+	// a presentation made for another verifier (aud) is rejected
+	other := first.Request
+	other.ClientID = "did:byd50:other"
+	response, err = CreateResponse(&other, holderDid, []string{vcJwt}, time.Minute, k[holderDid])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if failed, err = v.HandleResponse(*response); err == nil || !strings.Contains(failed.Error, "aud") {
+		t.Fatalf("expected aud mismatch, got %v", err)
+	}
+
+	// an unsatisfied definition matches no credential of the holder
+	third, _ := v.CreateSession(def, "")
+	otherVc := core.CreateVc(issuerDid, "OtherCredential", map[string]interface{}{"degree": "BachelorDegree"}, jwt.StandardClaims{
+		ExpiresAt: time.Now().Add(time.Hour).Unix(),
+		Issuer:    issuerDid,
+	}, k[issuerDid])
+	if _, err := CreateResponse(&third.Request, holderDid, []string{otherVc}, time.Minute, k[holderDid]); err == nil {
+		t.Fatal("expected holder to find no matching credential")
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	v, k, def, vcJwt := newTestVerifier(t)
+	v.SessionTTL = time.Millisecond
+	session, err := v.CreateSession(def, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	response, err := CreateResponse(&session.Request, holderDid, []string{vcJwt}, time.Minute, k[holderDid])
+	if err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(5 * time.Millisecond)
+	if _, err := v.HandleResponse(*response); err == nil {
+		t.Fatal("expected expired session to reject the response")
+	}
+	if got, _ := v.Session(session.ID); got.Status != StatusExpired {
+		t.Fatalf("expected expired, got %s", got.Status)
+	}
+	if _, err := v.RequestObject(session.ID); err == nil {
+		t.Fatal("expected no request object for an expired session")
+	}
+}
@@ -0,0 +1,215 @@
+package oid4vp
+
+import (
+	"byd50-ssi/pkg/did/core"
+	"byd50-ssi/pkg/did/core/pex"
+	derrors "byd50-ssi/pkg/did/errors"
+	"crypto"
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// DefaultSessionTTL is how long a session accepts a response when Verifier.SessionTTL is zero.
+const DefaultSessionTTL = 5 * time.Minute
+
+// Status is the state of a verification session.
+type Status string
+
+const (
+	// StatusCreated is a session whose request was not fetched by a wallet yet.
+	StatusCreated Status = "created"
+	// StatusRequestRetrieved is a session whose request object was fetched through its request_uri.
+	StatusRequestRetrieved Status = "request_retrieved"
+	// StatusVerified is a session whose response was verified.
+	StatusVerified Status = "verified"
+	// StatusExpired is a session that timed out before a response arrived.
+	StatusExpired Status = "expired"
+)
+
+// Session tracks one authorization request. Its id is the state of the request.
+type Session struct {
+	ID        string               `json:"id"`
+	Status    Status               `json:"status"`
+	Request   AuthorizationRequest `json:"request"`
+	CreatedAt time.Time            `json:"created_at"`
+	ExpiresAt time.Time            `json:"expires_at"`
+	// HolderDid and Credentials (input descriptor id to VC JWT) are set once the response is verified.
+	HolderDid   string            `json:"holder_did,omitempty"`
+	Credentials map[string]string `json:"credentials,omitempty"`
+	// Error is why the last response was rejected.
+	Error string `json:"error,omitempty"`
+}
+
+// pending reports whether the session still waits for a response.
+func (s *Session) pending() bool {
+	return s.Status == StatusCreated || s.Status == StatusRequestRetrieved
+}
+
+// Verifier is an OID4VP verifier identified by the DID ClientID. Sessions are kept in memory.
+type Verifier struct {
+	ClientID string
+	// ResponseURI is where wallets post their responses, unless a session is created with its own.
+	ResponseURI string
+	// PvKey signs request objects and should belong to an authentication key of ClientID.
+	PvKey crypto.PrivateKey
+	// GetAuthKey resolves the holder keys for the VP signature, GetAssertionKey the issuer keys for the VCs.
+	GetAuthKey      func(string, string) string
+	GetAssertionKey func(string, string) string
+	SessionTTL      time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// CreateSession starts a session requesting a presentation for def, with a fresh state and nonce.
+// An empty responseURI defaults to v.ResponseURI.
+func (v *Verifier) CreateSession(def *pex.PresentationDefinition, responseURI string) (*Session, error) {
+	if def == nil {
+		return nil, derrors.New(derrors.CodeInvalidInput, "presentation definition is nil")
+	}
+	if responseURI == "" {
+		responseURI = v.ResponseURI
+	}
+	ttl := v.SessionTTL
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	now := time.Now()
+	session := &Session{
+		ID:     uuid.NewV4().String(),
+		Status: StatusCreated,
+		Request: AuthorizationRequest{
+			ResponseType:           ResponseTypeVpToken,
+			ClientID:               v.ClientID,
+			ClientIDScheme:         ClientIDSchemeDid,
+			ResponseMode:           ResponseModeDirectPost,
+			ResponseURI:            responseURI,
+			Nonce:                  uuid.NewV4().String(),
+			PresentationDefinition: def,
+			ClientMetadata: &ClientMetadata{VpFormats: pex.Formats{
+				pex.FormatJwtVp: {},
+				pex.FormatJwtVc: {},
+			}},
+		},
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	session.Request.State = session.ID
+	if err := session.Request.Validate(); err != nil {
+		return nil, err
+	}
+
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	if v.sessions == nil {
+		v.sessions = map[string]*Session{}
+	}
+	v.pruneLocked(now)
+	v.sessions[session.ID] = session
+	copied := *session
+	return &copied, nil
+}
+
+// Session returns a snapshot of the session id.
+func (v *Verifier) Session(id string) (*Session, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	session, err := v.sessionLocked(id, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	copied := *session
+	return &copied, nil
+}
+
+// RequestObject signs the request of the session id for its request_uri. It can only be fetched while the
+// session waits for a response.
+func (v *Verifier) RequestObject(id string) (string, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	now := time.Now()
+	session, err := v.sessionLocked(id, now)
+	if err != nil {
+		return "", err
+	}
+	if !session.pending() {
+		return "", derrors.New(derrors.CodeInvalidInput, "session is "+string(session.Status))
+	}
+	requestObject, err := SignRequestObject(&session.Request, session.ExpiresAt.Sub(now), v.PvKey)
+	if err != nil {
+		return "", err
+	}
+	session.Status = StatusRequestRetrieved
+	return requestObject, nil
+}
+
+// HandleResponse verifies a direct_post response: the VP must be signed by the holder, carry the nonce of the
+// session and the verifier as aud, and its presentation submission must satisfy the requested definition.
+// A session accepts a single verified response. The state is public in the request, so a rejected response
+// leaves the session pending for the holder's own response; err explains the rejection.
+func (v *Verifier) HandleResponse(response Response) (*Session, error) {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	session, err := v.sessionLocked(response.State, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	if !session.pending() {
+		return nil, derrors.New(derrors.CodeInvalidInput, "session is "+string(session.Status))
+	}
+
+	evaluation, holderDid, err := v.verify(&session.Request, response)
+	if err != nil {
+		session.Error = err.Error()
+	} else {
+		session.Status = StatusVerified
+		session.HolderDid = holderDid
+		session.Credentials = evaluation.Credentials
+	}
+	copied := *session
+	return &copied, err
+}
+
+func (v *Verifier) verify(request *AuthorizationRequest, response Response) (*pex.Evaluation, string, error) {
+	if response.VpToken == "" {
+		return nil, "", derrors.New(derrors.CodeInvalidInput, "vp_token is required")
+	}
+	if response.PresentationSubmission == nil {
+		return nil, "", derrors.New(derrors.CodeInvalidInput, "presentation_submission is required")
+	}
+	ok, holderDid, err := core.VerifyVpWithBinding(response.VpToken, request.ClientID, request.Nonce, v.GetAuthKey, v.GetAssertionKey)
+	if !ok {
+		if err == nil {
+			err = derrors.New(derrors.CodeInvalidInput, "vp invalid")
+		}
+		return nil, holderDid, err
+	}
+	evaluation, err := pex.Evaluate(request.PresentationDefinition, response.VpToken, response.PresentationSubmission)
+	if err != nil {
+		return nil, holderDid, err
+	}
+	return evaluation, holderDid, nil
+}
+
+// sessionLocked returns the session id, marking it expired when it timed out while pending.
+func (v *Verifier) sessionLocked(id string, now time.Time) (*Session, error) {
+	session, ok := v.sessions[id]
+	if !ok {
+		return nil, derrors.New(derrors.CodeNotFound, "session not found")
+	}
+	if session.pending() && now.After(session.ExpiresAt) {
+		session.Status = StatusExpired
+	}
+	return session, nil
+}
+
+// pruneLocked drops the sessions that expired more than a TTL ago, so finished sessions stay pollable for a while.
+func (v *Verifier) pruneLocked(now time.Time) {
+	for id, session := range v.sessions {
+		if now.After(session.ExpiresAt.Add(session.ExpiresAt.Sub(session.CreatedAt))) {
+			delete(v.sessions, id)
+		}
+	}
+}
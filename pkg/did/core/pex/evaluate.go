@@ -0,0 +1,183 @@
+package pex
+
+import (
+	derrors "byd50-ssi/pkg/did/errors"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/golang-jwt/jwt"
+	"github.com/santhosh-tekuri/jsonschema/v5"
+)
+
+type compiledField struct {
+	Field
+	paths  [][]pathStep
+	filter *jsonschema.Schema
+}
+
+type compiledDescriptor struct {
+	InputDescriptor
+	fields []compiledField
+}
+
+type compiledDefinition struct {
+	def         *PresentationDefinition
+	descriptors []compiledDescriptor
+}
+
+func compileDefinition(def *PresentationDefinition) (*compiledDefinition, error) {
+	if def == nil || def.ID == "" {
+		return nil, derrors.New(derrors.CodeInvalidInput, "presentation definition id is empty")
+	}
+	if len(def.InputDescriptors) == 0 {
+		return nil, derrors.New(derrors.CodeInvalidInput, "presentation definition has no input descriptors")
+	}
+	compiled := &compiledDefinition{def: def}
+	seen := map[string]bool{}
+	for _, descriptor := range def.InputDescriptors {
+		if descriptor.ID == "" || seen[descriptor.ID] {
+			return nil, derrors.New(derrors.CodeInvalidInput, "input descriptor ids must be unique and not empty")
+		}
+		seen[descriptor.ID] = true
+		switch descriptor.Constraints.LimitDisclosure {
+		case "", LimitDisclosurePreferred, LimitDisclosureRequired:
+		default:
+			return nil, derrors.New(derrors.CodeInvalidInput, "invalid limit_disclosure of "+descriptor.ID)
+		}
+		cd := compiledDescriptor{InputDescriptor: descriptor}
+		for i, field := range descriptor.Constraints.Fields {
+			cf, err := compileField(field, fmt.Sprintf("%s/fields/%d", descriptor.ID, i))
+			if err != nil {
+				return nil, err
+			}
+			cd.fields = append(cd.fields, cf)
+		}
+		compiled.descriptors = append(compiled.descriptors, cd)
+	}
+	return compiled, nil
+}
+
+func compileField(field Field, location string) (compiledField, error) {
+	cf := compiledField{Field: field}
+	if len(field.Path) == 0 {
+		return cf, derrors.New(derrors.CodeInvalidInput, location+" has no path")
+	}
+	for _, path := range field.Path {
+		steps, err := compilePath(path)
+		if err != nil {
+			return cf, invalid(location, err)
+		}
+		cf.paths = append(cf.paths, steps)
+	}
+	if len(field.Filter) > 0 {
+		compiler := jsonschema.NewCompiler()
+		compiler.Draft = jsonschema.Draft7
+		compiler.LoadURL = func(url string) (io.ReadCloser, error) {
+			return nil, fmt.Errorf("remote schema %s is not allowed", url)
+		}
+		url := "pex:///" + location
+		if err := compiler.AddResource(url, bytes.NewReader(field.Filter)); err != nil {
+			return cf, invalid(location+" has an invalid filter", err)
+		}
+		schema, err := compiler.Compile(url)
+		if err != nil {
+			return cf, invalid(location+" has an invalid filter", err)
+		}
+		cf.filter = schema
+	}
+	return cf, nil
+}
+
+// match reports whether a credential with the given JWT header and claims satisfies the descriptor.
+func (cd compiledDescriptor) match(defFormats Formats, format string, header map[string]interface{}, claims interface{}) error {
+	if !isVcFormat(format) {
+		return fmt.Errorf("format %s is not a credential format", format)
+	}
+	if err := checkFormat(cd.Format, format, header); err != nil {
+		return err
+	}
+	if cd.Format == nil {
+		if err := checkFormat(defFormats, format, header); err != nil {
+			return err
+		}
+	}
+	if cd.Constraints.LimitDisclosure == LimitDisclosureRequired {
+		return fmt.Errorf("limit_disclosure required can not be met by %s", format)
+	}
+	for _, field := range cd.fields {
+		if !field.match(claims) && !field.Optional {
+			name := field.ID
+			if name == "" {
+				name = strings.Join(field.Path, " | ")
+			}
+			return fmt.Errorf("field %s not satisfied", name)
+		}
+	}
+	return nil
+}
+
+// match is true when one of the paths selects a value that passes the filter.
+func (cf compiledField) match(claims interface{}) bool {
+	for _, steps := range cf.paths {
+		for _, value := range evaluatePath(steps, claims) {
+			if cf.filter == nil || cf.filter.Validate(value) == nil {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// checkFormat accepts the credential when formats is empty, or lists the format with its alg.
+func checkFormat(formats Formats, format string, header map[string]interface{}) error {
+	if len(formats) == 0 {
+		return nil
+	}
+	accepted, ok := formats[format]
+	if !ok {
+		// jwt_vc and jwt_vc_json name the same format
+		for name, f := range formats {
+			if isVcFormat(name) && isVcFormat(format) {
+				accepted, ok = f, true
+			}
+		}
+	}
+	if !ok {
+		return fmt.Errorf("format %s is not accepted", format)
+	}
+	if len(accepted.Alg) == 0 {
+		return nil
+	}
+	alg, _ := header["alg"].(string)
+	for _, a := range accepted.Alg {
+		if a == alg {
+			return nil
+		}
+	}
+	return fmt.Errorf("alg %s is not accepted for %s", alg, format)
+}
+
+// decodeJwt returns the header and claims of a JWT without verifying it. Claims are decoded as
+// generic JSON so that JSONPath and filters see the same values as in the token.
+func decodeJwt(token string) (map[string]interface{}, interface{}, error) {
+	parsed, _, err := new(jwt.Parser).ParseUnverified(token, jwt.MapClaims{})
+	if err != nil {
+		return nil, nil, err
+	}
+	raw, err := json.Marshal(parsed.Claims)
+	if err != nil {
+		return nil, nil, err
+	}
+	var claims interface{}
+	if err := json.Unmarshal(raw, &claims); err != nil {
+		return nil, nil, err
+	}
+	return parsed.Header, claims, nil
+}
+
+func invalid(message string, err error) error {
+	return derrors.Wrap(derrors.CodeInvalidInput, message, err)
+}
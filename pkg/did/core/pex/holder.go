@@ -0,0 +1,87 @@
+package pex
+
+import (
+	"byd50-ssi/pkg/did/core"
+	"byd50-ssi/pkg/did/core/vcdm"
+	derrors "byd50-ssi/pkg/did/errors"
+	"fmt"
+	"strings"
+)
+
+// Match returns, for every input descriptor id, the credentials of vcJwts that satisfy it, in the order given.
+// Credentials that can not be decoded are skipped.
+func Match(def *PresentationDefinition, vcJwts []string) (map[string][]string, error) {
+	compiled, err := compileDefinition(def)
+	if err != nil {
+		return nil, err
+	}
+	matches := make(map[string][]string, len(compiled.descriptors))
+	for _, cd := range compiled.descriptors {
+		matches[cd.ID] = []string{}
+	}
+	for _, vcJwt := range vcJwts {
+		header, claims, err := decodeJwt(vcJwt)
+		if err != nil {
+			continue
+		}
+		for _, cd := range compiled.descriptors {
+			if cd.match(def.Format, FormatJwtVc, header, claims) == nil {
+				matches[cd.ID] = append(matches[cd.ID], vcJwt)
+			}
+		}
+	}
+	return matches, nil
+}
+
+// Select picks the first matching credential for every input descriptor and returns the credentials to
+// present together with the presentation submission describing them within a JWT-VP.
+func Select(def *PresentationDefinition, vcJwts []string) ([]string, *PresentationSubmission, error) {
+	matches, err := Match(def, vcJwts)
+	if err != nil {
+		return nil, nil, err
+	}
+	var missing []string
+	var selected []string
+	index := map[string]int{}
+	submission := &PresentationSubmission{ID: core.NewCredentialID(), DefinitionID: def.ID}
+	for _, descriptor := range def.InputDescriptors {
+		candidates := matches[descriptor.ID]
+		if len(candidates) == 0 {
+			missing = append(missing, descriptor.ID)
+			continue
+		}
+		vcJwt := candidates[0]
+		i, ok := index[vcJwt]
+		if !ok {
+			i = len(selected)
+			index[vcJwt] = i
+			selected = append(selected, vcJwt)
+		}
+		submission.DescriptorMap = append(submission.DescriptorMap, Descriptor{
+			ID:     descriptor.ID,
+			Format: FormatJwtVp,
+			Path:   "$",
+			PathNested: &Descriptor{
+				ID:     descriptor.ID,
+				Format: FormatJwtVc,
+				Path:   fmt.Sprintf("$.vp.verifiableCredential[%d]", i),
+			},
+		})
+	}
+	if len(missing) > 0 {
+		return nil, nil, derrors.New(derrors.CodeNotFound, "no credential matches input descriptors: "+strings.Join(missing, ", "))
+	}
+	return selected, submission, nil
+}
+
+// BuildPresentation selects the credentials for def and returns a presentation of type typ that carries
+// them with its presentation submission. The holder signs it with core.CreateVpWithPresentation.
+func BuildPresentation(typ string, def *PresentationDefinition, vcJwts []string) (*vcdm.VerifiablePresentation, error) {
+	selected, submission, err := Select(def, vcJwts)
+	if err != nil {
+		return nil, err
+	}
+	vp := vcdm.NewPresentationV1(typ, vcdm.JwtCredentials(selected)...)
+	vp.Extra = map[string]interface{}{SubmissionClaim: submission}
+	return vp, nil
+}
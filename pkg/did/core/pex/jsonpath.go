@@ -0,0 +1,151 @@
+package pex
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// pathStep is a single step of a JSONPath: a member name, an array index or a wildcard,
+// optionally applied recursively (..).
+type pathStep struct {
+	recursive bool
+	wildcard  bool
+	name      string
+	index     int
+	isIndex   bool
+}
+
+// compilePath parses the JSONPath subset used by Presentation Exchange definitions:
+// $, .name, ['name'], [n], [*], .* and recursive descent (..name, ..*).
+// Filter expressions, slices and unions are not supported.
+func compilePath(path string) ([]pathStep, error) {
+	if !strings.HasPrefix(path, "$") {
+		return nil, fmt.Errorf("json path %q must start with $", path)
+	}
+	var steps []pathStep
+	rest := path[1:]
+	for rest != "" {
+		var step pathStep
+		switch {
+		case strings.HasPrefix(rest, ".."):
+			step.recursive = true
+			rest = rest[2:]
+			if strings.HasPrefix(rest, "[") {
+				break
+			}
+			fallthrough
+		case strings.HasPrefix(rest, "."):
+			rest = strings.TrimPrefix(rest, ".")
+			end := strings.IndexAny(rest, ".[")
+			if end < 0 {
+				end = len(rest)
+			}
+			name := rest[:end]
+			if name == "" {
+				return nil, fmt.Errorf("json path %q has an empty member name", path)
+			}
+			if name == "*" {
+				step.wildcard = true
+			} else {
+				step.name = name
+			}
+			rest = rest[end:]
+			steps = append(steps, step)
+			continue
+		case !strings.HasPrefix(rest, "["):
+			return nil, fmt.Errorf("json path %q is invalid at %q", path, rest)
+		}
+
+		end := strings.Index(rest, "]")
+		if end < 0 {
+			return nil, fmt.Errorf("json path %q has an unterminated [", path)
+		}
+		selector := strings.TrimSpace(rest[1:end])
+		rest = rest[end+1:]
+		switch {
+		case selector == "*":
+			step.wildcard = true
+		case len(selector) >= 2 && (selector[0] == '\'' || selector[0] == '"') && selector[len(selector)-1] == selector[0]:
+			step.name = selector[1 : len(selector)-1]
+		default:
+			index, err := strconv.Atoi(selector)
+			if err != nil || index < 0 {
+				return nil, fmt.Errorf("json path %q has an unsupported selector [%s]", path, selector)
+			}
+			step.index, step.isIndex = index, true
+		}
+		steps = append(steps, step)
+	}
+	return steps, nil
+}
+
+// evaluatePath returns the values selected by steps in document order.
+func evaluatePath(steps []pathStep, root interface{}) []interface{} {
+	nodes := []interface{}{root}
+	for _, step := range steps {
+		var next []interface{}
+		for _, node := range nodes {
+			if step.recursive {
+				for _, descendant := range descendants(node) {
+					next = append(next, step.apply(descendant)...)
+				}
+			} else {
+				next = append(next, step.apply(node)...)
+			}
+		}
+		nodes = next
+	}
+	return nodes
+}
+
+func (step pathStep) apply(node interface{}) []interface{} {
+	switch v := node.(type) {
+	case map[string]interface{}:
+		if step.wildcard {
+			return mapValues(v)
+		}
+		if value, ok := v[step.name]; ok && !step.isIndex {
+			return []interface{}{value}
+		}
+	case []interface{}:
+		if step.wildcard {
+			return v
+		}
+		if step.isIndex && step.index < len(v) {
+			return []interface{}{v[step.index]}
+		}
+	}
+	return nil
+}
+
+// descendants returns node and all values nested in it.
+func descendants(node interface{}) []interface{} {
+	out := []interface{}{node}
+	switch v := node.(type) {
+	case map[string]interface{}:
+		for _, child := range mapValues(v) {
+			out = append(out, descendants(child)...)
+		}
+	case []interface{}:
+		for _, child := range v {
+			out = append(out, descendants(child)...)
+		}
+	}
+	return out
+}
+
+// mapValues returns the values of m ordered by key, so evaluation is deterministic.
+func mapValues(m map[string]interface{}) []interface{} {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	values := make([]interface{}, 0, len(keys))
+	for _, k := range keys {
+		values = append(values, m[k])
+	}
+	return values
+}
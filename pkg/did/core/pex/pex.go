@@ -0,0 +1,114 @@
+// Package pex implements DIF Presentation Exchange v2 (identity.foundation/presentation-exchange/spec/v2.0.0/)
+// for JWT credentials: verifiers publish a presentation definition whose input descriptors constrain
+// credential fields with JSONPath expressions and JSON Schema filters, holders select matching credentials
+// and present them with a presentation submission, and verifiers evaluate the submission against the definition.
+//
+// Submission requirements and feature extensions (predicates, relational constraints) are not supported.
+package pex
+
+import (
+	"encoding/json"
+)
+
+// Claim formats of the JWT credentials and presentations handled by this package.
+// The _json variants are the names used by OpenID for Verifiable Presentations.
+const (
+	FormatJwtVc     = "jwt_vc"
+	FormatJwtVp     = "jwt_vp"
+	FormatJwtVcJson = "jwt_vc_json"
+	FormatJwtVpJson = "jwt_vp_json"
+)
+
+// Limit disclosure values of Constraints.
+const (
+	LimitDisclosureRequired  = "required"
+	LimitDisclosurePreferred = "preferred"
+)
+
+// SubmissionClaim is the property of a presentation that carries its presentation submission.
+const SubmissionClaim = "presentation_submission"
+
+// PresentationDefinition states the credentials a verifier requires.
+type PresentationDefinition struct {
+	ID               string            `json:"id"`
+	Name             string            `json:"name,omitempty"`
+	Purpose          string            `json:"purpose,omitempty"`
+	Format           Formats           `json:"format,omitempty"`
+	InputDescriptors []InputDescriptor `json:"input_descriptors"`
+}
+
+// InputDescriptor describes one required credential.
+type InputDescriptor struct {
+	ID          string      `json:"id"`
+	Name        string      `json:"name,omitempty"`
+	Purpose     string      `json:"purpose,omitempty"`
+	Format      Formats     `json:"format,omitempty"`
+	Constraints Constraints `json:"constraints"`
+}
+
+// Constraints are the conditions a credential must satisfy for an input descriptor.
+type Constraints struct {
+	LimitDisclosure string  `json:"limit_disclosure,omitempty"`
+	Fields          []Field `json:"fields,omitempty"`
+}
+
+// Field selects a value of the credential with the first matching Path and checks it with Filter,
+// a JSON Schema. A field that is not Optional must be satisfied.
+type Field struct {
+	ID       string          `json:"id,omitempty"`
+	Path     []string        `json:"path"`
+	Purpose  string          `json:"purpose,omitempty"`
+	Name     string          `json:"name,omitempty"`
+	Filter   json.RawMessage `json:"filter,omitempty"`
+	Optional bool            `json:"optional,omitempty"`
+}
+
+// Formats maps claim formats to the algorithms accepted for them.
+type Formats map[string]Format
+
+// Format restricts the signing algorithms of a claim format. An empty Alg accepts any algorithm.
+type Format struct {
+	Alg []string `json:"alg,omitempty"`
+}
+
+// PresentationSubmission maps the input descriptors of a definition to the credentials of a presentation.
+type PresentationSubmission struct {
+	ID            string       `json:"id"`
+	DefinitionID  string       `json:"definition_id"`
+	DescriptorMap []Descriptor `json:"descriptor_map"`
+}
+
+// Descriptor locates the credential submitted for the input descriptor ID. Path is evaluated against the
+// presentation; PathNested, when present, is evaluated against the object Path selected.
+type Descriptor struct {
+	ID         string      `json:"id"`
+	Format     string      `json:"format"`
+	Path       string      `json:"path"`
+	PathNested *Descriptor `json:"path_nested,omitempty"`
+}
+
+// ParseDefinition decodes and validates a presentation definition.
+func ParseDefinition(data []byte) (*PresentationDefinition, error) {
+	def := new(PresentationDefinition)
+	if err := json.Unmarshal(data, def); err != nil {
+		return nil, invalid("invalid presentation definition", err)
+	}
+	if _, err := compileDefinition(def); err != nil {
+		return nil, err
+	}
+	return def, nil
+}
+
+// Validate checks the definition: ids, JSONPath expressions and filters.
+func (def *PresentationDefinition) Validate() error {
+	_, err := compileDefinition(def)
+	return err
+}
+
+func isVcFormat(format string) bool {
+	return format == FormatJwtVc || format == FormatJwtVcJson
+}
+
+func isVpFormat(format string) bool {
+	return format == FormatJwtVp || format == FormatJwtVpJson
+}
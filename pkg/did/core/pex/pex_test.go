@@ -0,0 +1,227 @@
+package pex
+
+import (
+	"byd50-ssi/pkg/did/core"
+	"byd50-ssi/pkg/did/core/vcdm"
+	"byd50-ssi/pkg/keys"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/golang-jwt/jwt"
+)
+
+const testDefinition = `{
+  "id": "alumni-verification",
+  "purpose": "Prove that you graduated",
+  "format": {"jwt_vc": {"alg": ["ES256"]}, "jwt_vp": {"alg": ["ES256"]}},
+  "input_descriptors": [{
+    "id": "alumni",
+    "constraints": {
+      "fields": [
+        {"path": ["$.vc.type", "$.type"], "filter": {"type": "array", "contains": {"const": "AlumniCredential"}}},
+        {"id": "degree", "path": ["$.vc.credentialSubject.degree", "$.credentialSubject.degree"], "filter": {"type": "string", "pattern": "^Bachelor"}},
+        {"path": ["$.vc.credentialSubject.gpa"], "optional": true}
+      ]
+    }
+  }]
+}`
+
+func TestJsonPath(t *testing.T) {
+	var doc interface{}
+	if err := json.Unmarshal([]byte(`{"a": {"b": [1, {"c": "x"}], "d c": 2}, "e": [{"c": "y"}]}`), &doc); err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		path string
+		want []interface{}
+	}{
+		{"$.a.b[0]", []interface{}{1.0}},
+		{"$.a.b[1].c", []interface{}{"x"}},
+		{"$['a']['d c']", []interface{}{2.0}},
+		{"$.e[*].c", []interface{}{"y"}},
+		{"$..c", []interface{}{"x", "y"}},
+		{"$.a.missing", nil},
+		{"$.a.b[5]", nil},
+	}
+	for _, tt := range tests {
+		steps, err := compilePath(tt.path)
+		if err != nil {
+			t.Fatalf("%s: %v", tt.path, err)
+		}
+		if got := evaluatePath(steps, doc); !reflect.DeepEqual(got, tt.want) {
+			t.Fatalf("%s: got %v, want %v", tt.path, got, tt.want)
+		}
+	}
+	for _, path := range []string{"a.b", "$.a[?(@.b)]", "$.a[", "$.a[1:2]", "$.."} {
+		if _, err := compilePath(path); err == nil {
+			t.Fatalf("expected %q to be rejected", path)
+		}
+	}
+}
+
+func TestParseDefinition(t *testing.T) {
+	if _, err := ParseDefinition([]byte(testDefinition)); err != nil {
+		t.Fatal(err)
+	}
+	invalid := []string{
+		`{"input_descriptors": [{"id": "a", "constraints": {}}]}`,
+		`{"id": "d", "input_descriptors": []}`,
+		`{"id": "d", "input_descriptors": [{"id": "a", "constraints": {}}, {"id": "a", "constraints": {}}]}`,
+		`{"id": "d", "input_descriptors": [{"id": "a", "constraints": {"fields": [{"path": ["vc.type"]}]}}]}`,
+		`{"id": "d", "input_descriptors": [{"id": "a", "constraints": {"fields": [{"path": ["$.type"], "filter": {"type": 5}}]}}]}`,
+	}
+	for _, data := range invalid {
+		if _, err := ParseDefinition([]byte(data)); err == nil {
+			t.Fatalf("expected definition to be rejected: %s", data)
+		}
+	}
+}
+
+type testParty struct {
+	did      string
+	pvKey    *ecdsa.PrivateKey
+	pbKeyB58 string
+}
+
+func newTestParty(t *testing.T, did string) testParty {
+	t.Helper()
+	pvKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pbBytes, err := x509.MarshalPKIXPublicKey(&pvKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return testParty{did: did, pvKey: pvKey, pbKeyB58: base58.Encode(pbBytes)}
+}
+
+func standardClaims(issuer string) jwt.StandardClaims {
+	return jwt.StandardClaims{
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		IssuedAt:  time.Now().Unix(),
+		Issuer:    issuer,
+	}
+}
+
+func TestSelectPresentAndEvaluate(t *testing.T) {
+	def, err := ParseDefinition([]byte(testDefinition))
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := newTestParty(t, "did:byd50:university")
+	holder := newTestParty(t, "did:byd50:holder")
+	parties := map[string]testParty{issuer.did: issuer, holder.did: holder}
+	getPbKey := func(did, _ string) string { return parties[did].pbKeyB58 }
+
+	licence := core.CreateVc(issuer.did, "DriverLicenceCredential", map[string]interface{}{"degree": "BachelorDegree"}, standardClaims(issuer.did), issuer.pvKey)
+	master := core.CreateVc(issuer.did, "AlumniCredential", map[string]interface{}{"degree": "MasterDegree"}, standardClaims(issuer.did), issuer.pvKey)
+	alumni := core.CreateVc(issuer.did, "AlumniCredential", map[string]interface{}{"degree": "BachelorDegree"}, standardClaims(issuer.did), issuer.pvKey)
+
+	matches, err := Match(def, []string{licence, master, alumni, "not-a-jwt"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(matches["alumni"], []string{alumni}) {
+		t.Fatalf("unexpected matches: %v", matches)
+	}
+
+	vp, err := BuildPresentation("AlumniPresentation", def, []string{licence, master, alumni})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if creds := vp.CredentialJwts(); len(creds) != 1 || creds[0] != alumni {
+		t.Fatalf("only the selected credential must be presented: %v", creds)
+	}
+	vpJwt, err := core.CreateVpWithPresentation(holder.did, vp, standardClaims(holder.did), holder.pvKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	evaluation, holderDid, err := Verify(def, vpJwt, getPbKey, getPbKey)
+	if err != nil {
+		t.Fatalf("evaluation failed: %v", err)
+	}
+	if holderDid != holder.did || evaluation.Credentials["alumni"] != alumni {
+		t.Fatalf("unexpected evaluation: %v %+v", holderDid, evaluation)
+	}
+
+	if _, _, err := Select(def, []string{licence, master}); err == nil {
+		t.Fatal("expected select to fail without a matching credential")
+	}
+}
+
+func TestEvaluateRejectsBadSubmissions(t *testing.T) {
+	def, err := ParseDefinition([]byte(testDefinition))
+	if err != nil {
+		t.Fatal(err)
+	}
+	issuer := newTestParty(t, "did:byd50:university")
+	holder := newTestParty(t, "did:byd50:holder")
+	alumni := core.CreateVc(issuer.did, "AlumniCredential", map[string]interface{}{"degree": "BachelorDegree"}, standardClaims(issuer.did), issuer.pvKey)
+	master := core.CreateVc(issuer.did, "AlumniCredential", map[string]interface{}{"degree": "MasterDegree"}, standardClaims(issuer.did), issuer.pvKey)
+
+	present := func(vcJwts []string, extra map[string]interface{}) string {
+		vp := vcdm.NewPresentationV1("AlumniPresentation", vcdm.JwtCredentials(vcJwts)...)
+		vp.Extra = extra
+		vpJwt, err := core.CreateVpWithPresentation(holder.did, vp, standardClaims(holder.did), holder.pvKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return vpJwt
+	}
+	nested := func(path string) *PresentationSubmission {
+		return &PresentationSubmission{ID: "s", DefinitionID: def.ID, DescriptorMap: []Descriptor{{
+			ID: "alumni", Format: FormatJwtVp, Path: "$",
+			PathNested: &Descriptor{ID: "alumni", Format: FormatJwtVc, Path: path},
+		}}}
+	}
+
+	vpJwt := present([]string{master, alumni}, nil)
+	if _, err := Evaluate(def, vpJwt, nested("$.vp.verifiableCredential[1]")); err != nil {
+		t.Fatalf("expected submission to satisfy the definition: %v", err)
+	}
+	if _, err := Evaluate(def, vpJwt, nil); err == nil {
+		t.Fatal("expected error without presentation_submission")
+	}
+
+	// the submission points to a credential that does not satisfy the filters
+	if _, err := Evaluate(def, vpJwt, nested("$.vp.verifiableCredential[0]")); err == nil {
+		t.Fatal("expected non matching credential to fail")
+	}
+	// submission for another definition
+	other := nested("$.vp.verifiableCredential[1]")
+	other.DefinitionID = "other"
+	if _, err := Evaluate(def, vpJwt, other); err == nil {
+		t.Fatal("expected definition id mismatch")
+	}
+	// descriptor missing from the map
+	if _, err := Evaluate(def, vpJwt, &PresentationSubmission{ID: "s", DefinitionID: def.ID}); err == nil {
+		t.Fatal("expected missing descriptor to fail")
+	}
+
+	// a credential outside vp.verifiableCredential is never verified, so it can not be submitted
+	forgerKey := newTestParty(t, issuer.did)
+	forged := core.CreateVc(issuer.did, "AlumniCredential", map[string]interface{}{"degree": "BachelorDegree"}, standardClaims(issuer.did), forgerKey.pvKey)
+	smuggled := present([]string{master}, map[string]interface{}{"attachment": forged})
+	if _, err := Evaluate(def, smuggled, nested("$.vp.attachment")); err == nil {
+		t.Fatal("expected credential outside verifiableCredential to be rejected")
+	}
+
+	// the definition only accepts ES256 credentials
+	edPriv, _, err := keys.GenerateEd25519KeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	edVc := core.CreateVc(issuer.did, "AlumniCredential", map[string]interface{}{"degree": "BachelorDegree"}, standardClaims(issuer.did), edPriv)
+	if matches, _ := Match(def, []string{edVc}); len(matches["alumni"]) != 0 {
+		t.Fatal("expected EdDSA credential to be excluded by the format")
+	}
+}
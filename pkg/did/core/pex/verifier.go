@@ -0,0 +1,172 @@
+package pex
+
+import (
+	"byd50-ssi/pkg/did/core"
+	derrors "byd50-ssi/pkg/did/errors"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Evaluation is the outcome of a successful submission evaluation.
+type Evaluation struct {
+	// Credentials maps every input descriptor id to the VC JWT submitted for it.
+	Credentials map[string]string `json:"credentials"`
+}
+
+// SubmissionFromVp returns the presentation submission embedded in a JWT-VP, in its vp claim or at the top level.
+func SubmissionFromVp(vpJwt string) (*PresentationSubmission, error) {
+	_, claims, err := decodeJwt(vpJwt)
+	if err != nil {
+		return nil, invalid("failed to parse vp", err)
+	}
+	payload, _ := claims.(map[string]interface{})
+	raw, ok := payload[SubmissionClaim]
+	if vp, isMap := payload["vp"].(map[string]interface{}); !ok && isMap {
+		raw, ok = vp[SubmissionClaim]
+	}
+	if !ok {
+		return nil, derrors.New(derrors.CodeInvalidInput, "vp has no presentation_submission")
+	}
+	data, err := json.Marshal(raw)
+	if err != nil {
+		return nil, invalid("invalid presentation_submission", err)
+	}
+	submission := new(PresentationSubmission)
+	if err := json.Unmarshal(data, submission); err != nil {
+		return nil, invalid("invalid presentation_submission", err)
+	}
+	return submission, nil
+}
+
+// Evaluate checks that submission maps every input descriptor of def to a credential of vpJwt that satisfies it.
+// When submission is nil, the one embedded in the presentation is used. Signatures are not verified; use
+// Verify, or verify the presentation and its credentials before.
+func Evaluate(def *PresentationDefinition, vpJwt string, submission *PresentationSubmission) (*Evaluation, error) {
+	compiled, err := compileDefinition(def)
+	if err != nil {
+		return nil, err
+	}
+	if submission == nil {
+		if submission, err = SubmissionFromVp(vpJwt); err != nil {
+			return nil, err
+		}
+	}
+	if submission.DefinitionID != def.ID {
+		return nil, derrors.New(derrors.CodeInvalidInput, "presentation_submission is for definition "+submission.DefinitionID)
+	}
+	_, vpClaims, err := decodeJwt(vpJwt)
+	if err != nil {
+		return nil, invalid("failed to parse vp", err)
+	}
+
+	// only the credentials of vp.verifiableCredential are verified with the presentation, so a path
+	// must not select a JWT from any other claim
+	presented := presentedCredentials(vpClaims)
+
+	evaluation := &Evaluation{Credentials: map[string]string{}}
+	var failures []string
+	for _, cd := range compiled.descriptors {
+		vcJwt, err := evaluateDescriptor(def, cd, submission, vpClaims, presented)
+		if err != nil {
+			failures = append(failures, cd.ID+": "+err.Error())
+			continue
+		}
+		evaluation.Credentials[cd.ID] = vcJwt
+	}
+	if len(failures) > 0 {
+		return nil, derrors.New(derrors.CodeInvalidInput, "presentation_submission does not satisfy the definition: "+strings.Join(failures, "; "))
+	}
+	return evaluation, nil
+}
+
+// Verify verifies the signatures of vpJwt (authentication key of the holder, assertionMethod keys of the
+// issuers) and evaluates its embedded presentation submission against def. It returns the holder DID.
+func Verify(def *PresentationDefinition, vpJwt string, getAuthKey, getAssertionKey func(string, string) string) (*Evaluation, string, error) {
+	ok, holderDid, err := core.VerifyVpWithKeys(vpJwt, getAuthKey, getAssertionKey)
+	if !ok {
+		return nil, holderDid, err
+	}
+	evaluation, err := Evaluate(def, vpJwt, nil)
+	return evaluation, holderDid, err
+}
+
+// evaluateDescriptor resolves the credential submitted for cd and checks it. Any of the descriptor map
+// entries with the descriptor id may satisfy it.
+func evaluateDescriptor(def *PresentationDefinition, cd compiledDescriptor, submission *PresentationSubmission, vpClaims interface{}, presented map[string]bool) (string, error) {
+	err := fmt.Errorf("not in descriptor_map")
+	for _, entry := range submission.DescriptorMap {
+		if entry.ID != cd.ID {
+			continue
+		}
+		var vcJwt, format string
+		var header map[string]interface{}
+		var claims interface{}
+		if vcJwt, format, header, claims, err = resolve(entry, vpClaims, 0); err != nil {
+			continue
+		}
+		if !presented[vcJwt] {
+			err = fmt.Errorf("credential is not in vp.verifiableCredential")
+			continue
+		}
+		if err = cd.match(def.Format, format, header, claims); err == nil {
+			return vcJwt, nil
+		}
+	}
+	return "", err
+}
+
+func presentedCredentials(vpClaims interface{}) map[string]bool {
+	presented := map[string]bool{}
+	payload, _ := vpClaims.(map[string]interface{})
+	vp, _ := payload["vp"].(map[string]interface{})
+	switch v := vp["verifiableCredential"].(type) {
+	case string:
+		presented[v] = true
+	case []interface{}:
+		for _, item := range v {
+			if s, ok := item.(string); ok {
+				presented[s] = true
+			}
+		}
+	}
+	return presented
+}
+
+// maxNesting bounds path_nested chains.
+const maxNesting = 4
+
+// resolve follows the descriptor and its nested descriptors from the presentation claims to a credential.
+func resolve(d Descriptor, current interface{}, depth int) (string, string, map[string]interface{}, interface{}, error) {
+	if depth > maxNesting {
+		return "", "", nil, nil, fmt.Errorf("path_nested too deep")
+	}
+	steps, err := compilePath(d.Path)
+	if err != nil {
+		return "", "", nil, nil, err
+	}
+	values := evaluatePath(steps, current)
+	if len(values) != 1 {
+		return "", "", nil, nil, fmt.Errorf("path %s selects %d values", d.Path, len(values))
+	}
+
+	var token string
+	var header map[string]interface{}
+	claims := values[0]
+	if s, ok := claims.(string); ok {
+		token = s
+		if header, claims, err = decodeJwt(s); err != nil {
+			return "", "", nil, nil, fmt.Errorf("path %s does not select a jwt", d.Path)
+		}
+	}
+	if d.PathNested != nil {
+		if !isVpFormat(d.Format) {
+			return "", "", nil, nil, fmt.Errorf("path_nested is only supported in %s", FormatJwtVp)
+		}
+		return resolve(*d.PathNested, claims, depth+1)
+	}
+	if token == "" {
+		return "", "", nil, nil, fmt.Errorf("path %s does not select a jwt credential", d.Path)
+	}
+	return token, d.Format, header, claims, nil
+}
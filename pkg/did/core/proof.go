@@ -0,0 +1,149 @@
+package core
+
+import (
+	"byd50-ssi/pkg/did/core/dataintegrity"
+	"byd50-ssi/pkg/did/core/vcdm"
+	derrors "byd50-ssi/pkg/did/errors"
+	"crypto"
+	"encoding/json"
+)
+
+// AddVcProof signs the credential with a Data Integrity proof (ecdsa-jcs-2019 or eddsa-jcs-2022,
+// depending on the key) and appends it to vc.Proof. ProofPurpose defaults to assertionMethod.
+func AddVcProof(vc *vcdm.VerifiableCredential, options dataintegrity.ProofOptions, signer crypto.Signer) error {
+	if vc == nil {
+		return derrors.New(derrors.CodeInvalidInput, "credential is nil")
+	}
+	if vc.Version() == vcdm.V1 && !Contains(vc.Context, dataintegrity.ContextDataIntegrityV1) {
+		vc.Context = append(vc.Context, dataintegrity.ContextDataIntegrityV1)
+	}
+	unsecured := *vc
+	unsecured.Proof = nil
+	document, err := toDocument(unsecured)
+	if err != nil {
+		return err
+	}
+	proof, err := dataintegrity.CreateProof(document, options, signer)
+	if err != nil {
+		return err
+	}
+	vc.Proof = append(vc.Proof, proof)
+	return nil
+}
+
+// VerifyVcProof verifies every Data Integrity proof of the credential, which must be an assertionMethod proof
+// made with a key of the credential issuer. Verification keys are resolved from each proof's verificationMethod
+// with getPbKey.
+func VerifyVcProof(vc *vcdm.VerifiableCredential, getPbKey func(string, string) string) (bool, error) {
+	if vc == nil {
+		return false, derrors.New(derrors.CodeInvalidInput, "credential is nil")
+	}
+	if len(vc.Proof) == 0 {
+		return false, derrors.New(derrors.CodeInvalidInput, "credential has no proof")
+	}
+	unsecured := *vc
+	unsecured.Proof = nil
+	document, err := toDocument(unsecured)
+	if err != nil {
+		return false, err
+	}
+	if err := verifyProofs(document, vc.Proof, vc.Issuer.ID, dataintegrity.ProofPurposeAssertionMethod, getPbKey); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// AddVpProof signs the presentation with a Data Integrity proof and appends it to vp.Proof.
+// ProofPurpose defaults to authentication; Challenge and Domain bind the proof to a verifier.
+func AddVpProof(vp *vcdm.VerifiablePresentation, options dataintegrity.ProofOptions, signer crypto.Signer) error {
+	if vp == nil {
+		return derrors.New(derrors.CodeInvalidInput, "presentation is nil")
+	}
+	if options.ProofPurpose == "" {
+		options.ProofPurpose = dataintegrity.ProofPurposeAuthentication
+	}
+	if vp.Version() == vcdm.V1 && !Contains(vp.Context, dataintegrity.ContextDataIntegrityV1) {
+		vp.Context = append(vp.Context, dataintegrity.ContextDataIntegrityV1)
+	}
+	unsecured := *vp
+	unsecured.Proof = nil
+	document, err := toDocument(unsecured)
+	if err != nil {
+		return err
+	}
+	proof, err := dataintegrity.CreateProof(document, options, signer)
+	if err != nil {
+		return err
+	}
+	vp.Proof = append(vp.Proof, proof)
+	return nil
+}
+
+// VerifyVpProof verifies the proofs of the presentation, which must be made with a key of its holder, with getAuthKey
+// (authentication keys of the holder) and of every embedded credential with getAssertionKey (assertionMethod keys of
+// the issuers).
+// When challenge or domain are not empty, the presentation proofs must carry the same values.
+func VerifyVpProof(vp *vcdm.VerifiablePresentation, challenge, domain string, getAuthKey, getAssertionKey func(string, string) string) (bool, error) {
+	if vp == nil {
+		return false, derrors.New(derrors.CodeInvalidInput, "presentation is nil")
+	}
+	if len(vp.Proof) == 0 {
+		return false, derrors.New(derrors.CodeInvalidInput, "presentation has no proof")
+	}
+	if vp.Holder == "" {
+		return false, derrors.New(derrors.CodeInvalidInput, "presentation has no holder")
+	}
+	for _, proof := range vp.Proof {
+		if challenge != "" && proof.Challenge != challenge {
+			return false, derrors.New(derrors.CodeInvalidInput, "vp proof challenge mismatch")
+		}
+		if domain != "" && proof.Domain != domain {
+			return false, derrors.New(derrors.CodeInvalidInput, "vp proof domain mismatch")
+		}
+	}
+
+	unsecured := *vp
+	unsecured.Proof = nil
+	document, err := toDocument(unsecured)
+	if err != nil {
+		return false, err
+	}
+	if err := verifyProofs(document, vp.Proof, vp.Holder, dataintegrity.ProofPurposeAuthentication, getAuthKey); err != nil {
+		return false, err
+	}
+
+	for _, presented := range vp.VerifiableCredential {
+		if presented.Credential == nil {
+			continue
+		}
+		if ok, err := VerifyVcProof(presented.Credential, getAssertionKey); !ok {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+func verifyProofs(document map[string]interface{}, proofs []dataintegrity.Proof, controller, proofPurpose string, getPbKey func(string, string) string) error {
+	for _, proof := range proofs {
+		pbKey, err := dataintegrity.ResolveVerificationMethod(proof.VerificationMethod, getPbKey)
+		if err != nil {
+			return err
+		}
+		if err := dataintegrity.VerifyProof(document, proof, controller, proofPurpose, pbKey); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func toDocument(v interface{}) (map[string]interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "failed to encode document", err)
+	}
+	var document map[string]interface{}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "failed to decode document", err)
+	}
+	return document, nil
+}
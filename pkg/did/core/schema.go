@@ -0,0 +1,120 @@
+package core
+
+import (
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	"byd50-ssi/pkg/did/core/credschema"
+	"byd50-ssi/pkg/did/core/vcdm"
+	derrors "byd50-ssi/pkg/did/errors"
+	"github.com/golang-jwt/jwt"
+)
+
+// VerifyOptions holds the optional checks of VerifyVcWithOptions that go beyond the signature.
+type VerifyOptions struct {
+	// ValidateSchema validates every credentialSubject against its credential schemas.
+	ValidateSchema bool
+	// Schemas is the registry used by ValidateSchema. credschema.Default is used when nil.
+	Schemas *credschema.Registry
+	// Trust, when set, rejects credentials whose signer is not trusted for their types.
+	Trust IssuerTrust
+}
+
+// RegisterCredentialSchema registers the JSON Schema that credentials of credType must satisfy
+// at issuance. Issued credentials reference it through credentialSchema.
+func RegisterCredentialSchema(credType, schemaID string, schemaJSON []byte) error {
+	return credschema.Default.Register(credType, schemaID, schemaJSON)
+}
+
+// ValidateCredentialSchema validates the credential subjects against the registered schemas.
+// Violations are returned as *credschema.ValidationError.
+func ValidateCredentialSchema(vc *vcdm.VerifiableCredential) error {
+	return credschema.Default.Validate(vc)
+}
+
+// VerifyVcWithOptions verifies the JWT-VC signature and then runs the checks enabled in options.
+func VerifyVcWithOptions(vcJwt string, getPbKey func(string, string) string, options VerifyOptions) (bool, error) {
+	if ok, err := VerifyVc(vcJwt, getPbKey); !ok {
+		return false, err
+	}
+	if err := options.check(vcJwt); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// check runs the optional checks on a VC whose signature has already been verified.
+func (options VerifyOptions) check(vcJwt string) error {
+	if !options.ValidateSchema && options.Trust == nil {
+		return nil
+	}
+	vc, err := credentialFromJwt(vcJwt)
+	if err != nil {
+		return err
+	}
+	if options.ValidateSchema {
+		schemas := options.Schemas
+		if schemas == nil {
+			schemas = credschema.Default
+		}
+		if err := schemas.Validate(vc); err != nil {
+			return err
+		}
+	}
+	if options.Trust != nil {
+		if err := checkIssuerTrust(options.Trust, vcJwt, vc); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// withCredentialSchema validates vc and returns a copy referencing the schemas registered for its types.
+func withCredentialSchema(vc *vcdm.VerifiableCredential) (*vcdm.VerifiableCredential, error) {
+	out := *vc
+	if len(out.CredentialSchema) == 0 {
+		out.CredentialSchema = credschema.Default.SchemasFor(out.Type)
+	}
+	if err := credschema.Default.Validate(&out); err != nil {
+		return nil, err
+	}
+	return &out, nil
+}
+
+// applyCredentialSchema is withCredentialSchema for raw VcClaims. The vc claim is copied before
+// credentialSchema is added so the caller's map is left untouched.
+func applyCredentialSchema(claims byd50_jwt.VcClaims) (byd50_jwt.VcClaims, error) {
+	vc, err := vcdm.CredentialFromClaims(claims)
+	if err != nil {
+		return claims, err
+	}
+	if len(vc.CredentialSchema) == 0 {
+		vc.CredentialSchema = credschema.Default.SchemasFor(vc.Type)
+		if len(vc.CredentialSchema) > 0 {
+			myVc := make(map[string]interface{}, len(claims.Vc)+1)
+			for k, v := range claims.Vc {
+				myVc[k] = v
+			}
+			if len(vc.CredentialSchema) == 1 {
+				myVc["credentialSchema"] = vc.CredentialSchema[0]
+			} else {
+				myVc["credentialSchema"] = vc.CredentialSchema
+			}
+			claims.Vc = myVc
+		}
+	}
+	if err := credschema.Default.Validate(vc); err != nil {
+		return claims, err
+	}
+	return claims, nil
+}
+
+func credentialFromJwt(vcJwt string) (*vcdm.VerifiableCredential, error) {
+	token, _, err := new(jwt.Parser).ParseUnverified(vcJwt, jwt.MapClaims{})
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "failed to parse vc", err)
+	}
+	mapClaims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, derrors.New(derrors.CodeInvalidInput, "invalid vc claims")
+	}
+	return vcdm.CredentialFromMapClaims(mapClaims)
+}
@@ -0,0 +1,60 @@
+package core
+
+import (
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	derrors "byd50-ssi/pkg/did/errors"
+	"crypto"
+	"github.com/golang-jwt/jwt"
+)
+
+// CreateSdJwtVc issues an SD-JWT VC whose disclosable credentialSubject claims (dotted paths)
+// can be revealed selectively by the holder.
+func CreateSdJwtVc(kid, vct string, credSub map[string]interface{}, disclosable []string, holderDid string,
+	standardClaims jwt.StandardClaims, pvKey crypto.PrivateKey) (string, error) {
+	if vct == "" {
+		return "", derrors.New(derrors.CodeInvalidInput, "vct is empty")
+	}
+	if pvKey == nil {
+		return "", derrors.New(derrors.CodeEmptyKey, "issuer private key is nil")
+	}
+	if standardClaims.Id == "" {
+		standardClaims.Id = NewCredentialID()
+	}
+	sdJwt, err := byd50_jwt.CreateSdJwtVc(kid, vct, credSub, disclosable, holderDid, standardClaims, pvKey)
+	if err != nil {
+		return "", derrors.Wrap(derrors.CodeInvalidInput, "failed to create sd-jwt vc", err)
+	}
+	return sdJwt, nil
+}
+
+// PresentSdJwt builds a holder presentation revealing only the claims in reveal,
+// bound to aud and nonce by a key binding JWT.
+func PresentSdJwt(sdJwt string, reveal []string, aud, nonce, holderDid string, pvKey crypto.PrivateKey) (string, error) {
+	if pvKey == nil {
+		return "", derrors.New(derrors.CodeEmptyKey, "holder private key is nil")
+	}
+	presentation, err := byd50_jwt.PresentSdJwt(sdJwt, reveal, aud, nonce, holderDid, pvKey)
+	if err != nil {
+		return "", derrors.Wrap(derrors.CodeInvalidInput, "failed to present sd-jwt", err)
+	}
+	return presentation, nil
+}
+
+// VerifySdJwt verifies an SD-JWT presentation and returns the disclosed claims.
+func VerifySdJwt(presentation, aud, nonce string, getPbKey func(string, string) string) (map[string]interface{}, error) {
+	claims, err := byd50_jwt.VerifySdJwt(presentation, aud, nonce, getPbKey)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "sd-jwt verification failed", err)
+	}
+	return claims, nil
+}
+
+// VerifySdJwtWithKeys verifies an SD-JWT presentation with the issuer signature checked by getIssuerKey
+// and the key binding JWT by getHolderKey.
+func VerifySdJwtWithKeys(presentation, aud, nonce string, getIssuerKey, getHolderKey func(string, string) string) (map[string]interface{}, error) {
+	claims, err := byd50_jwt.VerifySdJwtWithKeys(presentation, aud, nonce, getIssuerKey, getHolderKey)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "sd-jwt verification failed", err)
+	}
+	return claims, nil
+}
@@ -0,0 +1,140 @@
+package siop
+
+import (
+	derrors "byd50-ssi/pkg/did/errors"
+	"sync"
+	"time"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// DefaultSessionTTL is how long a sign-in session accepts an ID token when RelyingParty.SessionTTL is zero.
+const DefaultSessionTTL = 5 * time.Minute
+
+// Status is the state of a sign-in session.
+type Status string
+
+const (
+	// StatusCreated is a session waiting for the ID token.
+	StatusCreated Status = "created"
+	// StatusAuthenticated is a session whose ID token was verified.
+	StatusAuthenticated Status = "authenticated"
+	// StatusExpired is a session that timed out before an ID token arrived.
+	StatusExpired Status = "expired"
+)
+
+// Session tracks one sign-in request. Its id is the state of the request.
+type Session struct {
+	ID        string               `json:"id"`
+	Status    Status               `json:"status"`
+	Request   AuthorizationRequest `json:"request"`
+	CreatedAt time.Time            `json:"created_at"`
+	ExpiresAt time.Time            `json:"expires_at"`
+	// Subject is the DID that signed in, once authenticated.
+	Subject string `json:"subject,omitempty"`
+	// Error is why the last ID token was rejected.
+	Error string `json:"error,omitempty"`
+}
+
+// RelyingParty signs users in with self-issued ID tokens. Sessions are kept in memory.
+type RelyingParty struct {
+	// GetPbKey resolves the keys of the subject DID, normally authentication keys.
+	GetPbKey   func(string, string) string
+	SessionTTL time.Duration
+
+	mu       sync.Mutex
+	sessions map[string]*Session
+}
+
+// CreateSession starts a sign-in session whose ID token is posted to responseURI, the client_id of the request.
+func (rp *RelyingParty) CreateSession(responseURI string) (*Session, error) {
+	ttl := rp.SessionTTL
+	if ttl <= 0 {
+		ttl = DefaultSessionTTL
+	}
+	now := time.Now()
+	session := &Session{
+		ID:     uuid.NewV4().String(),
+		Status: StatusCreated,
+		Request: AuthorizationRequest{
+			ResponseType:   ResponseTypeIDToken,
+			Scope:          ScopeOpenID,
+			ClientID:       responseURI,
+			ClientIDScheme: ClientIDSchemeRedirectURI,
+			ResponseMode:   ResponseModeDirectPost,
+			ResponseURI:    responseURI,
+			Nonce:          uuid.NewV4().String(),
+			IDTokenType:    IDTokenTypeSubjectSigned,
+			ClientMetadata: &ClientMetadata{SubjectSyntaxTypesSupported: []string{SubjectSyntaxDid}},
+		},
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	}
+	session.Request.State = session.ID
+	if err := session.Request.Validate(); err != nil {
+		return nil, err
+	}
+
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	if rp.sessions == nil {
+		rp.sessions = map[string]*Session{}
+	}
+	for id, s := range rp.sessions {
+		// finished sessions stay pollable for a TTL after they expire
+		if now.After(s.ExpiresAt.Add(ttl)) {
+			delete(rp.sessions, id)
+		}
+	}
+	rp.sessions[session.ID] = session
+	copied := *session
+	return &copied, nil
+}
+
+// Session returns a snapshot of the session id.
+func (rp *RelyingParty) Session(id string) (*Session, error) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	session, err := rp.sessionLocked(id)
+	if err != nil {
+		return nil, err
+	}
+	copied := *session
+	return &copied, nil
+}
+
+// HandleResponse verifies the ID token posted for the session state. A session accepts a single verified ID token.
+// The state is public in the request, so a rejected ID token leaves the session waiting for the user's own ID token;
+// err explains the rejection.
+func (rp *RelyingParty) HandleResponse(state, idToken string) (*Session, error) {
+	rp.mu.Lock()
+	defer rp.mu.Unlock()
+	session, err := rp.sessionLocked(state)
+	if err != nil {
+		return nil, err
+	}
+	if session.Status != StatusCreated {
+		return nil, derrors.New(derrors.CodeInvalidInput, "session is "+string(session.Status))
+	}
+	subject, err := VerifyIDToken(idToken, session.Request.ClientID, session.Request.Nonce, rp.GetPbKey)
+	if err != nil {
+		session.Error = err.Error()
+	} else {
+		session.Status = StatusAuthenticated
+		session.Subject = subject
+	}
+	copied := *session
+	return &copied, err
+}
+
+// sessionLocked returns the session id, marking it expired when it timed out while waiting.
+func (rp *RelyingParty) sessionLocked(id string) (*Session, error) {
+	session, ok := rp.sessions[id]
+	if !ok {
+		return nil, derrors.New(derrors.CodeNotFound, "session not found")
+	}
+	if session.Status == StatusCreated && time.Now().After(session.ExpiresAt) {
+		session.Status = StatusExpired
+	}
+	return session, nil
+}
@@ -0,0 +1,183 @@
+// Package siop implements Self-Issued OpenID Provider v2 (draft 13) for signing in with a DID: the relying
+// party creates an authorization request for a self-issued ID token, the wallet answers with an ID token
+// whose iss and sub are the holder DID and that is signed by a key of that DID, and the relying party
+// validates it by resolving sub.
+//
+// Requests are passed by value with client_id_scheme redirect_uri and response_mode direct_post, so the
+// client_id is the URI the ID token is posted to.
+package siop
+
+import (
+	"byd50-ssi/pkg/did/core"
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	derrors "byd50-ssi/pkg/did/errors"
+	"crypto"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	// Scheme is the custom URL scheme self-issued OpenID providers register.
+	Scheme = "siopv2://"
+	// ResponseTypeIDToken requests a self-issued ID token.
+	ResponseTypeIDToken = "id_token"
+	// ScopeOpenID is the scope of OpenID authentication requests.
+	ScopeOpenID = "openid"
+	// ResponseModeDirectPost makes the wallet POST the ID token to the response URI.
+	ResponseModeDirectPost = "direct_post"
+	// ClientIDSchemeRedirectURI makes the response URI the client_id.
+	ClientIDSchemeRedirectURI = "redirect_uri"
+	// IDTokenTypeSubjectSigned asks for an ID token signed by the subject (DID) key.
+	IDTokenTypeSubjectSigned = "subject_signing_issued"
+	// SubjectSyntaxDid accepts DIDs of any method as subject.
+	SubjectSyntaxDid = "did"
+	// IDTokenMaxAge is how old the iat of an ID token may be.
+	IDTokenMaxAge = 5 * time.Minute
+)
+
+// AuthorizationRequest is a SIOPv2 request for a self-issued ID token.
+type AuthorizationRequest struct {
+	ResponseType   string          `json:"response_type"`
+	Scope          string          `json:"scope"`
+	ClientID       string          `json:"client_id"`
+	ClientIDScheme string          `json:"client_id_scheme"`
+	ResponseMode   string          `json:"response_mode"`
+	ResponseURI    string          `json:"response_uri"`
+	Nonce          string          `json:"nonce"`
+	State          string          `json:"state"`
+	IDTokenType    string          `json:"id_token_type"`
+	ClientMetadata *ClientMetadata `json:"client_metadata,omitempty"`
+}
+
+// ClientMetadata describes the relying party to the wallet.
+type ClientMetadata struct {
+	SubjectSyntaxTypesSupported []string `json:"subject_syntax_types_supported"`
+}
+
+// Validate checks the parameters this package relies on.
+func (r *AuthorizationRequest) Validate() error {
+	switch {
+	case r.ResponseType != ResponseTypeIDToken:
+		return derrors.New(derrors.CodeInvalidInput, "unsupported response_type: "+r.ResponseType)
+	case !core.Contains(strings.Fields(r.Scope), ScopeOpenID):
+		return derrors.New(derrors.CodeInvalidInput, "scope must contain openid")
+	case r.ResponseMode != ResponseModeDirectPost:
+		return derrors.New(derrors.CodeInvalidInput, "unsupported response_mode: "+r.ResponseMode)
+	case r.ClientIDScheme != ClientIDSchemeRedirectURI || r.ClientID == "" || r.ClientID != r.ResponseURI:
+		return derrors.New(derrors.CodeInvalidInput, "client_id must be the response_uri")
+	case r.Nonce == "" || r.State == "":
+		return derrors.New(derrors.CodeInvalidInput, "nonce and state are required")
+	}
+	return nil
+}
+
+// URI encodes the request by value as a Scheme URL.
+func (r *AuthorizationRequest) URI() (string, error) {
+	params := url.Values{}
+	params.Set("response_type", r.ResponseType)
+	params.Set("scope", r.Scope)
+	params.Set("client_id", r.ClientID)
+	params.Set("client_id_scheme", r.ClientIDScheme)
+	params.Set("response_mode", r.ResponseMode)
+	params.Set("response_uri", r.ResponseURI)
+	params.Set("nonce", r.Nonce)
+	params.Set("state", r.State)
+	params.Set("id_token_type", r.IDTokenType)
+	if r.ClientMetadata != nil {
+		metadata, err := json.Marshal(r.ClientMetadata)
+		if err != nil {
+			return "", derrors.Wrap(derrors.CodeInternal, "failed to encode client_metadata", err)
+		}
+		params.Set("client_metadata", string(metadata))
+	}
+	return Scheme + "?" + params.Encode(), nil
+}
+
+// ParseRequestURI decodes a request passed by value, as a wallet does.
+func ParseRequestURI(uri string) (*AuthorizationRequest, error) {
+	parsed, err := url.Parse(uri)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "invalid request uri", err)
+	}
+	params := parsed.Query()
+	r := &AuthorizationRequest{
+		ResponseType:   params.Get("response_type"),
+		Scope:          params.Get("scope"),
+		ClientID:       params.Get("client_id"),
+		ClientIDScheme: params.Get("client_id_scheme"),
+		ResponseMode:   params.Get("response_mode"),
+		ResponseURI:    params.Get("response_uri"),
+		Nonce:          params.Get("nonce"),
+		State:          params.Get("state"),
+		IDTokenType:    params.Get("id_token_type"),
+	}
+	if metadata := params.Get("client_metadata"); metadata != "" {
+		r.ClientMetadata = new(ClientMetadata)
+		if err := json.Unmarshal([]byte(metadata), r.ClientMetadata); err != nil {
+			return nil, derrors.Wrap(derrors.CodeInvalidInput, "invalid client_metadata", err)
+		}
+	}
+	if err := r.Validate(); err != nil {
+		return nil, err
+	}
+	return r, nil
+}
+
+// CreateIDToken answers r as a wallet with a self-issued ID token of holderDid, valid for validity.
+// pvKey should belong to an authentication key of holderDid.
+func CreateIDToken(r *AuthorizationRequest, holderDid string, validity time.Duration, pvKey crypto.PrivateKey) (string, error) {
+	if err := r.Validate(); err != nil {
+		return "", err
+	}
+	now := time.Now()
+	idToken, err := byd50_jwt.Sign(holderDid, "", jwt.MapClaims{
+		"iss":   holderDid,
+		"sub":   holderDid,
+		"aud":   r.ClientID,
+		"nonce": r.Nonce,
+		"iat":   now.Unix(),
+		"exp":   now.Add(validity).Unix(),
+	}, pvKey)
+	if err != nil {
+		return "", derrors.Wrap(derrors.CodeInternal, "failed to sign id token", err)
+	}
+	return idToken, nil
+}
+
+// VerifyIDToken validates a self-issued ID token for the relying party clientID and the request nonce and
+// returns the DID it authenticates. The signature is verified with the key getPbKey resolves for sub, which
+// must also be the issuer: a self-issued token cannot vouch for another subject.
+func VerifyIDToken(idToken, clientID, nonce string, getPbKey func(string, string) string) (string, error) {
+	claims, err := byd50_jwt.ParseSigned(idToken, getPbKey)
+	if err != nil {
+		return "", derrors.Wrap(derrors.CodeInvalidInput, "id token invalid", err)
+	}
+	mapClaims := byd50_jwt.MapClaims(claims)
+	sub, _ := mapClaims["sub"].(string)
+	iss, _ := mapClaims["iss"].(string)
+	if !strings.HasPrefix(sub, "did:") || iss != sub {
+		return "", derrors.New(derrors.CodeInvalidInput, "id token is not self-issued by a DID")
+	}
+	// the kid may be a DID URL selecting one of the keys of sub
+	if signer, err := core.GetSignerDid(idToken); err != nil || signer != sub {
+		return "", derrors.New(derrors.CodeInvalidInput, "id token is not signed by its subject")
+	}
+	if audiences, _ := mapClaims.GetAudience(); clientID == "" || !core.Contains(audiences, clientID) {
+		return "", derrors.New(derrors.CodeInvalidInput, "id token aud mismatch")
+	}
+	if got, _ := mapClaims["nonce"].(string); nonce == "" || got != nonce {
+		return "", derrors.New(derrors.CodeInvalidInput, "id token nonce mismatch")
+	}
+	if _, err := mapClaims.GetExpiresAt(); err != nil {
+		return "", derrors.New(derrors.CodeInvalidInput, "id token exp is missing")
+	}
+	iat, err := mapClaims.GetIssuedAt()
+	if err != nil || time.Since(time.Unix(iat, 0)) > IDTokenMaxAge {
+		return "", derrors.New(derrors.CodeInvalidInput, "id token iat is missing or too old")
+	}
+	return sub, nil
+}
@@ -0,0 +1,151 @@
+package siop
+
+import (
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	responseURI = "https://rp.example.com/siop/response"
+	holderDid   = "did:byd50:holder"
+	otherDid    = "did:byd50:other"
+)
+
+type testKeys map[string]*ecdsa.PrivateKey
+
+func (k testKeys) getPbKey(did, _ string) string {
+	pvKey, ok := k[did]
+	if !ok {
+		return ""
+	}
+	pbBytes, _ := x509.MarshalPKIXPublicKey(&pvKey.PublicKey)
+	return base58.Encode(pbBytes)
+}
+
+func newTestRelyingParty(t *testing.T) (*RelyingParty, testKeys) {
+	k := testKeys{}
+	for _, did := range []string{holderDid, otherDid} {
+		pvKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k[did] = pvKey
+	}
+	return &RelyingParty{GetPbKey: k.getPbKey}, k
+}
+
+func TestSignIn(t *testing.T) {
+	rp, k := newTestRelyingParty(t)
+	session, err := rp.CreateSession(responseURI)
+	if err != nil {
+		t.Fatal(err)
+	}
+	uri, err := session.Request.URI()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the wallet decodes the request and answers with an ID token of its DID
+	request, err := ParseRequestURI(uri)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if request.Nonce != session.Request.Nonce || request.ClientMetadata == nil {
+		t.Fatalf("unexpected request %+v", request)
+	}
+	idToken, err := CreateIDToken(request, holderDid, time.Minute, k[holderDid])
+	if err != nil {
+		t.Fatal(err)
+	}
+	authenticated, err := rp.HandleResponse(request.State, idToken)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if authenticated.Status != StatusAuthenticated || authenticated.Subject != holderDid {
+		t.Fatalf("unexpected session %+v", authenticated)
+	}
+	if _, err := rp.HandleResponse(request.State, idToken); err == nil {
+		t.Fatal("expected a second ID token for the same session to be rejected")
+	}
+	if got, _ := rp.Session(session.ID); got.Status != StatusAuthenticated {
+		t.Fatalf("expected authenticated, got %s", got.Status)
+	}
+}
+
+func TestVerifyIDToken(t *testing.T) {
+	rp, k := newTestRelyingParty(t)
+	session, _ := rp.CreateSession(responseURI)
+	request := session.Request
+
+	// replayed to another session: nonce mismatch
+	idToken, _ := CreateIDToken(&request, holderDid, time.Minute, k[holderDid])
+	if _, err := VerifyIDToken(idToken, responseURI, "other-nonce", k.getPbKey); err == nil || !strings.Contains(err.Error(), "nonce") {
+		t.Fatalf("expected nonce mismatch, got %v", err)
+	}
+	// made for another relying party: aud mismatch
+	if _, err := VerifyIDToken(idToken, "https://other.example.com", request.Nonce, k.getPbKey); err == nil || !strings.Contains(err.Error(), "aud") {
+		t.Fatalf("expected aud mismatch, got %v", err)
+	}
+	// signed with a key that does not belong to the claimed DID
+	forged, _ := CreateIDToken(&request, holderDid, time.Minute, k[otherDid])
+	if _, err := VerifyIDToken(forged, responseURI, request.Nonce, k.getPbKey); err == nil {
+		t.Fatal("expected ID token signed by another key to be rejected")
+	}
+	// a DID URL kid selects a key of the subject; a key of another DID cannot sign for it
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss":   holderDid,
+		"sub":   holderDid,
+		"aud":   responseURI,
+		"nonce": request.Nonce,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Minute).Unix(),
+	}
+	urlKid, _ := byd50_jwt.Sign(holderDid+"#keys-1", "", claims, k[holderDid])
+	if did, err := VerifyIDToken(urlKid, responseURI, request.Nonce, k.getPbKey); err != nil || did != holderDid {
+		t.Fatalf("expected ID token with a DID URL kid to verify, got %v %v", did, err)
+	}
+	otherKid, _ := byd50_jwt.Sign(otherDid+"#keys-1", "", claims, k[otherDid])
+	if _, err := VerifyIDToken(otherKid, responseURI, request.Nonce, k.getPbKey); err == nil {
+		t.Fatal("expected ID token signed with a key of another DID to be rejected")
+	}
+	expired, _ := CreateIDToken(&request, holderDid, -time.Minute, k[holderDid])
+	if _, err := VerifyIDToken(expired, responseURI, request.Nonce, k.getPbKey); err == nil {
+		t.Fatal("expected expired ID token to be rejected")
+	}
+
+	// a rejected ID token leaves the session open for the user's own ID token
+	rejected, err := rp.HandleResponse(session.ID, forged)
+	if err == nil || rejected.Status != StatusCreated || rejected.Error == "" {
+		t.Fatalf("expected rejected ID token to keep the session waiting, got %v %+v", err, rejected)
+	}
+	if authenticated, err := rp.HandleResponse(session.ID, idToken); err != nil || authenticated.Status != StatusAuthenticated {
+		t.Fatalf("expected the session to still authenticate, got %v", err)
+	}
+	if _, err := rp.HandleResponse("unknown", idToken); err == nil {
+		t.Fatal("expected unknown state to be rejected")
+	}
+}
+
+func TestSessionExpiry(t *testing.T) {
+	rp, k := newTestRelyingParty(t)
+	rp.SessionTTL = time.Millisecond
+	session, _ := rp.CreateSession(responseURI)
+	idToken, _ := CreateIDToken(&session.Request, holderDid, time.Minute, k[holderDid])
+	time.Sleep(5 * time.Millisecond)
+	if _, err := rp.HandleResponse(session.ID, idToken); err == nil {
+		t.Fatal("expected expired session to reject the ID token")
+	}
+	if got, _ := rp.Session(session.ID); got.Status != StatusExpired {
+		t.Fatalf("expected expired, got %s", got.Status)
+	}
+}
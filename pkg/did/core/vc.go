@@ -2,19 +2,56 @@ package core
 
 import (
 	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	"byd50-ssi/pkg/did/core/vcdm"
 	derrors "byd50-ssi/pkg/did/errors"
-	"crypto/ecdsa"
+	"byd50-ssi/pkg/keys"
+	"crypto"
 	"github.com/golang-jwt/jwt"
+	uuid "github.com/satori/go.uuid"
+	"log"
+	"strings"
 	"time"
 )
 
-func CreateVc(kid, typ string, credSub map[string]interface{}, standardClaims jwt.StandardClaims, pvKey *ecdsa.PrivateKey) string {
-	claims := buildVcClaims(typ, credSub, standardClaims)
+// NewCredentialID returns a unique urn:uuid identifier to be used as the jti of a VC or VP.
+func NewCredentialID() string {
+	return "urn:uuid:" + uuid.NewV4().String()
+}
+
+func CreateVc(kid, typ string, credSub map[string]interface{}, standardClaims jwt.StandardClaims, pvKey crypto.PrivateKey) string {
+	claims, err := buildVcClaims(typ, credSub, standardClaims)
+	if err != nil {
+		log.Printf("build vc claims failed: %v", err)
+		return ""
+	}
 	vcSampleJwt := byd50_jwt.CreateVc(kid, claims, pvKey)
 	return vcSampleJwt
 }
 
-func CreateVcWithClaims(kid string, claims byd50_jwt.VcClaims, pvKey *ecdsa.PrivateKey) string {
+// CreateVcWithCredential signs a typed credential as a JWT-VC.
+// The subjects are validated against the schemas registered for the credential types before signing;
+// violations are returned as *credschema.ValidationError.
+func CreateVcWithCredential(kid string, vc *vcdm.VerifiableCredential, standardClaims jwt.StandardClaims, pvKey crypto.PrivateKey) (string, error) {
+	if vc == nil {
+		return "", derrors.New(derrors.CodeInvalidInput, "credential is nil")
+	}
+	claims, err := buildVcClaimsFromCredential(vc, standardClaims)
+	if err != nil {
+		return "", err
+	}
+	vcJwt := byd50_jwt.CreateVc(kid, claims, pvKey)
+	if vcJwt == "" {
+		return "", derrors.New(derrors.CodeInternal, "failed to sign vc")
+	}
+	return vcJwt, nil
+}
+
+func CreateVcWithClaims(kid string, claims byd50_jwt.VcClaims, pvKey crypto.PrivateKey) string {
+	claims, err := applyCredentialSchema(claims)
+	if err != nil {
+		log.Printf("credential schema validation failed: %v", err)
+		return ""
+	}
 	vcSampleJwt := byd50_jwt.CreateVc(kid, claims, pvKey)
 	return vcSampleJwt
 }
@@ -30,31 +67,50 @@ func VerifyVc(vc string, getPbKey func(string, string) string) (bool, error) {
 	return true, nil
 }
 
-func buildVcClaims(typ string, credSub map[string]interface{}, standardClaims jwt.StandardClaims) byd50_jwt.VcClaims {
-	typArray := []string{"VerifiableCredential"}
-	if typ != "" {
-		typArray = append(typArray, typ)
+// GetVcMapClaims verifies the VC JWT and returns its claims.
+func GetVcMapClaims(vc string, getPbKey func(string, string) string) (bool, jwt.MapClaims, error) {
+	return byd50_jwt.ParseVc(vc, getPbKey)
+}
+
+// GetSigner returns the kid header of a JWT, i.e. the DID whose key verifies its signature.
+// The token is not verified; compare the result only after the signature has been checked.
+func GetSigner(tokenString string) (string, error) {
+	token, _, err := new(jwt.Parser).ParseUnverified(tokenString, jwt.MapClaims{})
+	if err != nil {
+		return "", derrors.Wrap(derrors.CodeInvalidInput, "failed to parse jwt", err)
 	}
+	kid, _ := token.Header["kid"].(string)
+	if kid == "" {
+		return "", derrors.New(derrors.CodeInvalidInput, "jwt has no kid")
+	}
+	return kid, nil
+}
 
-	myVc := map[string]interface{}{
-		"@context": []string{
-			"https://www.w3.org/2018/credentials/v1",
-			"https://www.w3.org/2018/credentials/examples/v1",
-		},
-		"type":              typArray,
-		"credentialSubject": credSub,
+// GetSignerDid returns the DID of the kid header of a JWT: the DID itself, or the DID of a DID URL kid
+// (did#fragment) that selects one of its verification methods. Like GetSigner, the token is not verified.
+func GetSignerDid(tokenString string) (string, error) {
+	kid, err := GetSigner(tokenString)
+	if err != nil {
+		return "", err
 	}
+	did, _, _ := strings.Cut(kid, "#")
+	return did, nil
+}
 
-	nonce := RandomString(12)
+func buildVcClaims(typ string, credSub map[string]interface{}, standardClaims jwt.StandardClaims) (byd50_jwt.VcClaims, error) {
+	vc := vcdm.NewCredentialV1(typ, vcdm.CredentialSubject{Claims: credSub})
+	return buildVcClaimsFromCredential(vc, standardClaims)
+}
 
-	// Create the Claims
-	claims := byd50_jwt.VcClaims{
-		nonce,
-		myVc,
-		standardClaims,
+func buildVcClaimsFromCredential(vc *vcdm.VerifiableCredential, standardClaims jwt.StandardClaims) (byd50_jwt.VcClaims, error) {
+	if standardClaims.Id == "" && vc.ID == "" {
+		standardClaims.Id = NewCredentialID()
 	}
-
-	return claims
+	vc, err := withCredentialSchema(vc)
+	if err != nil {
+		return byd50_jwt.VcClaims{}, err
+	}
+	return vc.ToVcClaims(standardClaims, RandomString(12))
 }
 
 // ValidateVcClaims ensures required standard claims are present and consistent.
@@ -79,3 +135,15 @@ func ValidateVcClaims(claims byd50_jwt.VcClaims) error {
 	}
 	return nil
 }
+
+// CheckVerificationMethodKey reports whether the base58 public key matches the declared verification method type.
+func CheckVerificationMethodKey(vmType, pbKeyBase58 string) error {
+	pbKey, err := keys.ParsePublicKeyBase58(pbKeyBase58)
+	if err != nil {
+		return derrors.Wrap(derrors.CodeInvalidKey, "invalid public key", err)
+	}
+	if err := byd50_jwt.CheckVerificationMethodKey(vmType, pbKey); err != nil {
+		return derrors.Wrap(derrors.CodeInvalidKey, "verification method type mismatch", err)
+	}
+	return nil
+}
@@ -3,10 +3,16 @@ package core_test
 import (
 	"byd50-ssi/pkg/did/core"
 	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	"byd50-ssi/pkg/did/core/credschema"
+	"byd50-ssi/pkg/did/core/dataintegrity"
+	"byd50-ssi/pkg/did/core/vcdm"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/x509"
+	"encoding/json"
+	"errors"
 	"testing"
 	"time"
 
@@ -101,3 +107,296 @@ func TestValidateClaims(t *testing.T) {
 		t.Fatal("expected vp claims issuer error")
 	}
 }
+
+func TestCreateVcWithCredential(t *testing.T) {
+	pvKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pbBytes, err := x509.MarshalPKIXPublicKey(&pvKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	getPbKey := func(_ string, _ string) string {
+		return base58.Encode(pbBytes)
+	}
+
+	vc := vcdm.NewCredential("TestCredential", vcdm.CredentialSubject{
+		ID:     "did:byd50:holder",
+		Claims: map[string]interface{}{"name": "tester"},
+	})
+	vc.Issuer = vcdm.Issuer{ID: "did:byd50:test"}
+	vc.ValidFrom = time.Now().Truncate(time.Second)
+	vc.ValidUntil = vc.ValidFrom.Add(time.Minute)
+
+	vcJwt, err := core.CreateVcWithCredential("did:byd50:test", vc, jwt.StandardClaims{IssuedAt: time.Now().Unix()}, pvKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := core.VerifyVc(vcJwt, getPbKey); !ok || err != nil {
+		t.Fatalf("verify vc failed: %v", err)
+	}
+
+	vpJwt, err := core.CreateVpWithPresentation("did:byd50:holder",
+		vcdm.NewPresentation("TestPresentation", vcdm.JwtCredentials([]string{vcJwt})...),
+		jwt.StandardClaims{ExpiresAt: time.Now().Add(time.Minute).Unix(), IssuedAt: time.Now().Unix()}, pvKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, claims, err := core.GetMapClaims(vpJwt, getPbKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vp, err := vcdm.PresentationFromMapClaims(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := vp.CredentialJwts(); len(got) != 1 || got[0] != vcJwt {
+		t.Fatalf("unexpected presented credentials: %v", got)
+	}
+	if vp.Version() != vcdm.V2 {
+		t.Fatalf("unexpected presentation version: %v", vp.Version())
+	}
+}
+
+func TestDataIntegrityProof(t *testing.T) {
+	issuerKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	holderPub, holderKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	malloryKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keys := map[string]interface{}{
+		"did:byd50:issuer":  &issuerKey.PublicKey,
+		"did:byd50:holder":  holderPub,
+		"did:byd50:mallory": &malloryKey.PublicKey,
+	}
+	getPbKey := func(did string, _ string) string {
+		pbBytes, err := x509.MarshalPKIXPublicKey(keys[did])
+		if err != nil {
+			return ""
+		}
+		return base58.Encode(pbBytes)
+	}
+
+	vc := vcdm.NewCredentialV1("TestCredential", vcdm.CredentialSubject{
+		ID:     "did:byd50:holder",
+		Claims: map[string]interface{}{"name": "tester"},
+	})
+	vc.Issuer = vcdm.Issuer{ID: "did:byd50:issuer"}
+	vc.ValidFrom = time.Now()
+	err = core.AddVcProof(vc, dataintegrity.ProofOptions{VerificationMethod: "did:byd50:issuer#keys-1"}, issuerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !core.Contains(vc.Context, dataintegrity.ContextDataIntegrityV1) {
+		t.Fatal("v1 credential must declare the data integrity context")
+	}
+
+	// Proofs survive a JSON round trip.
+	data, err := json.Marshal(vc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var decoded vcdm.VerifiableCredential
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if ok, err := core.VerifyVcProof(&decoded, getPbKey); !ok || err != nil {
+		t.Fatalf("verify vc proof failed: %v", err)
+	}
+
+	vp := vcdm.NewPresentation("TestPresentation", vcdm.PresentedCredential{Credential: &decoded})
+	vp.Holder = "did:byd50:holder"
+	err = core.AddVpProof(vp, dataintegrity.ProofOptions{
+		VerificationMethod: "did:byd50:holder#keys-1",
+		Challenge:          "n-123",
+		Domain:             "did:byd50:rp",
+	}, holderKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if vp.Proof[0].Cryptosuite != dataintegrity.CryptosuiteEddsaJcs2022 {
+		t.Fatalf("unexpected cryptosuite: %s", vp.Proof[0].Cryptosuite)
+	}
+	if ok, err := core.VerifyVpProof(vp, "n-123", "did:byd50:rp", getPbKey, getPbKey); !ok || err != nil {
+		t.Fatalf("verify vp proof failed: %v", err)
+	}
+	if ok, _ := core.VerifyVpProof(vp, "n-456", "", getPbKey, getPbKey); ok {
+		t.Fatal("expected challenge mismatch")
+	}
+
+	// The holder proof is checked against authentication keys, the credential proofs against assertionMethod keys.
+	onlyKeyOf := func(owner string) func(string, string) string {
+		return func(did, keyId string) string {
+			if did != owner {
+				return ""
+			}
+			return getPbKey(did, keyId)
+		}
+	}
+	if ok, err := core.VerifyVpProof(vp, "", "", onlyKeyOf("did:byd50:holder"), onlyKeyOf("did:byd50:issuer")); !ok || err != nil {
+		t.Fatalf("verify vp proof with separate keys failed: %v", err)
+	}
+	if ok, _ := core.VerifyVpProof(vp, "", "", onlyKeyOf("did:byd50:issuer"), onlyKeyOf("did:byd50:holder")); ok {
+		t.Fatal("expected keys of the wrong relationship to be rejected")
+	}
+
+	// Proofs made with keys of another DID than the issuer or the holder are rejected.
+	impersonated := vcdm.NewCredentialV1("TestCredential", vc.CredentialSubject...)
+	impersonated.Issuer = vcdm.Issuer{ID: "did:byd50:issuer"}
+	impersonated.ValidFrom = vc.ValidFrom
+	if err := core.AddVcProof(impersonated, dataintegrity.ProofOptions{VerificationMethod: "did:byd50:mallory#keys-1"}, malloryKey); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := core.VerifyVcProof(impersonated, getPbKey); ok {
+		t.Fatal("expected a credential signed by another DID than its issuer to be rejected")
+	}
+	hijacked := vcdm.NewPresentation("TestPresentation", vcdm.PresentedCredential{Credential: &decoded})
+	hijacked.Holder = "did:byd50:holder"
+	if err := core.AddVpProof(hijacked, dataintegrity.ProofOptions{VerificationMethod: "did:byd50:mallory#keys-1"}, malloryKey); err != nil {
+		t.Fatal(err)
+	}
+	if ok, _ := core.VerifyVpProof(hijacked, "", "", getPbKey, getPbKey); ok {
+		t.Fatal("expected a presentation signed by another DID than its holder to be rejected")
+	}
+
+	decoded.CredentialSubject[0].Claims["name"] = "mallory"
+	if ok, _ := core.VerifyVpProof(vp, "", "", getPbKey, getPbKey); ok {
+		t.Fatal("expected tampered embedded credential to fail")
+	}
+}
+
+func TestCredentialSchemaValidation(t *testing.T) {
+	pvKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pbBytes, err := x509.MarshalPKIXPublicKey(&pvKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	getPbKey := func(_ string, _ string) string {
+		return base58.Encode(pbBytes)
+	}
+
+	schemaID := "urn:byd50:schema:SchemaTestCredential:1"
+	schema := `{"type": "object", "required": ["name"], "properties": {"name": {"type": "string", "minLength": 1}}}`
+	if err := core.RegisterCredentialSchema("SchemaTestCredential", schemaID, []byte(schema)); err != nil {
+		t.Fatal(err)
+	}
+	standardClaims := jwt.StandardClaims{
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		IssuedAt:  time.Now().Unix(),
+		Issuer:    "did:byd50:test",
+	}
+
+	invalid := vcdm.NewCredentialV1("SchemaTestCredential", vcdm.CredentialSubject{Claims: map[string]interface{}{"name": ""}})
+	_, err = core.CreateVcWithCredential("did:byd50:test", invalid, standardClaims, pvKey)
+	var verr *credschema.ValidationError
+	if !errors.As(err, &verr) || len(verr.Violations) == 0 {
+		t.Fatalf("expected schema violation, got %v", err)
+	}
+	if vcJwt := core.CreateVc("did:byd50:test", "SchemaTestCredential", map[string]interface{}{}, standardClaims, pvKey); vcJwt != "" {
+		t.Fatal("expected CreateVc to refuse an invalid subject")
+	}
+
+	valid := vcdm.NewCredentialV1("SchemaTestCredential", vcdm.CredentialSubject{Claims: map[string]interface{}{"name": "tester"}})
+	vcJwt, err := core.CreateVcWithCredential("did:byd50:test", valid, standardClaims, pvKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(valid.CredentialSchema) != 0 {
+		t.Fatal("caller credential must not be modified")
+	}
+	ok, err := core.VerifyVcWithOptions(vcJwt, getPbKey, core.VerifyOptions{ValidateSchema: true})
+	if !ok || err != nil {
+		t.Fatalf("verify with schema failed: %v", err)
+	}
+	token, _, err := new(jwt.Parser).ParseUnverified(vcJwt, jwt.MapClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	issued, err := vcdm.CredentialFromMapClaims(token.Claims.(jwt.MapClaims))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(issued.CredentialSchema) != 1 || issued.CredentialSchema[0].ID != schemaID {
+		t.Fatalf("credentialSchema not attached: %+v", issued.CredentialSchema)
+	}
+
+	// A verifier with a stricter schema rejects the embedded subject.
+	strict := credschema.NewRegistry()
+	strict.MustRegister("SchemaTestCredential", schemaID,
+		[]byte(`{"type": "object", "required": ["name", "age"]}`))
+	ok, err = core.VerifyVcWithOptions(vcJwt, getPbKey, core.VerifyOptions{ValidateSchema: true, Schemas: strict})
+	if ok || !errors.As(err, &verr) {
+		t.Fatalf("expected schema violation on verify, got %v", err)
+	}
+}
+
+func TestVerifyOptionsIssuerTrust(t *testing.T) {
+	pvKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pbBytes, err := x509.MarshalPKIXPublicKey(&pvKey.PublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	getPbKey := func(_ string, _ string) string {
+		return base58.Encode(pbBytes)
+	}
+	standardClaims := jwt.StandardClaims{
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		IssuedAt:  time.Now().Unix(),
+		Issuer:    "http://www.gov.kr/residentregistration",
+	}
+	vcJwt := core.CreateVc("did:byd50:issuer", "LicenceCredential", map[string]interface{}{"name": "tester"}, standardClaims, pvKey)
+	vpJwt := core.CreateVp("did:byd50:holder", "TestPresentation", []string{vcJwt}, standardClaims, pvKey)
+
+	var asked []string
+	untrusted := errors.New("untrusted")
+	trust := core.IssuerTrustFunc(func(issuerDid string, credentialTypes []string) error {
+		// the signer (kid) is checked, not the free-form iss claim
+		asked = append([]string{issuerDid}, credentialTypes...)
+		if issuerDid != "did:byd50:issuer" {
+			return untrusted
+		}
+		return nil
+	})
+	ok, err := core.VerifyVcWithOptions(vcJwt, getPbKey, core.VerifyOptions{Trust: trust})
+	if !ok || err != nil {
+		t.Fatalf("expected trusted vc to verify: %v", err)
+	}
+	if len(asked) != 2 || asked[0] != "did:byd50:issuer" || asked[1] != "LicenceCredential" {
+		t.Fatalf("unexpected trust query: %v", asked)
+	}
+	if ok, _, err := core.VerifyVpWithOptions(vpJwt, getPbKey, getPbKey, core.VerifyOptions{Trust: trust}); !ok || err != nil {
+		t.Fatalf("expected vp with trusted vc to verify: %v", err)
+	}
+
+	// a kid that is a DID URL is checked as its DID
+	urlJwt := core.CreateVc("did:byd50:issuer#keys-1", "LicenceCredential", map[string]interface{}{"name": "tester"}, standardClaims, pvKey)
+	if ok, err := core.VerifyVcWithOptions(urlJwt, getPbKey, core.VerifyOptions{Trust: trust}); !ok || err != nil {
+		t.Fatalf("expected vc signed with a did url kid to verify: %v", err)
+	}
+	if asked[0] != "did:byd50:issuer" {
+		t.Fatalf("unexpected trust query: %v", asked)
+	}
+
+	otherJwt := core.CreateVc("did:byd50:other", "LicenceCredential", map[string]interface{}{"name": "tester"}, standardClaims, pvKey)
+	if ok, err := core.VerifyVcWithOptions(otherJwt, getPbKey, core.VerifyOptions{Trust: trust}); ok || !errors.Is(err, untrusted) {
+		t.Fatalf("expected untrusted vc to fail, got %v", err)
+	}
+	otherVp := core.CreateVp("did:byd50:holder", "TestPresentation", []string{otherJwt}, standardClaims, pvKey)
+	if ok, _, err := core.VerifyVpWithOptions(otherVp, getPbKey, getPbKey, core.VerifyOptions{Trust: trust}); ok || !errors.Is(err, untrusted) {
+		t.Fatalf("expected vp with untrusted vc to fail, got %v", err)
+	}
+}
@@ -0,0 +1,239 @@
+// Package vcdm implements the W3C Verifiable Credentials Data Model (VCDM) as typed Go structs.
+// Both VCDM 2.0 (validFrom/validUntil) and VCDM 1.1 (issuanceDate/expirationDate) documents can be read;
+// documents are written in the version declared by their first @context entry.
+package vcdm
+
+import (
+	"byd50-ssi/pkg/did/core/dataintegrity"
+	derrors "byd50-ssi/pkg/did/errors"
+	"encoding/json"
+	"time"
+)
+
+const (
+	ContextV1         = "https://www.w3.org/2018/credentials/v1"
+	ContextV2         = "https://www.w3.org/ns/credentials/v2"
+	ContextExamplesV1 = "https://www.w3.org/2018/credentials/examples/v1"
+	ContextExamplesV2 = "https://www.w3.org/ns/credentials/examples/v2"
+
+	TypeVerifiableCredential   = "VerifiableCredential"
+	TypeVerifiablePresentation = "VerifiablePresentation"
+
+	CredentialSchemaTypeJsonSchema = "JsonSchema"
+)
+
+// Version identifies the data model version of a credential or presentation.
+type Version int
+
+const (
+	V1 Version = 1
+	V2 Version = 2
+)
+
+// Issuer is the issuer property of a credential. It is serialized as a plain URL
+// when only the ID is set, and as an object otherwise.
+type Issuer struct {
+	ID   string `json:"id"`
+	Name string `json:"name,omitempty"`
+}
+
+// CredentialSubject is a single entry of credentialSubject. Claims holds every property except id.
+type CredentialSubject struct {
+	ID     string
+	Claims map[string]interface{}
+}
+
+// CredentialSchema is an entry of credentialSchema referencing the schema the subject conforms to.
+type CredentialSchema struct {
+	ID   string `json:"id"`
+	Type string `json:"type"`
+}
+
+// VerifiableCredential is a typed VCDM credential.
+// Properties that are not modeled explicitly are preserved in Extra.
+type VerifiableCredential struct {
+	Context           []string
+	ID                string
+	Type              []string
+	Issuer            Issuer
+	ValidFrom         time.Time
+	ValidUntil        time.Time
+	CredentialSubject []CredentialSubject
+	CredentialSchema  []CredentialSchema
+	Proof             []dataintegrity.Proof
+	Extra             map[string]interface{}
+}
+
+// NewCredential returns a VCDM 2.0 credential of the given type.
+func NewCredential(typ string, subjects ...CredentialSubject) *VerifiableCredential {
+	return &VerifiableCredential{
+		Context:           []string{ContextV2, ContextExamplesV2},
+		Type:              types(TypeVerifiableCredential, typ),
+		CredentialSubject: subjects,
+	}
+}
+
+// NewCredentialV1 returns a credential of the given type using the VCDM 1.1 contexts.
+func NewCredentialV1(typ string, subjects ...CredentialSubject) *VerifiableCredential {
+	return &VerifiableCredential{
+		Context:           []string{ContextV1, ContextExamplesV1},
+		Type:              types(TypeVerifiableCredential, typ),
+		CredentialSubject: subjects,
+	}
+}
+
+// Version reports the data model version declared by the base context.
+func (vc *VerifiableCredential) Version() Version {
+	return contextVersion(vc.Context)
+}
+
+func (vc VerifiableCredential) MarshalJSON() ([]byte, error) {
+	m := copyMap(vc.Extra)
+	m["@context"] = vc.Context
+	if vc.ID != "" {
+		m["id"] = vc.ID
+	}
+	m["type"] = vc.Type
+	if vc.Issuer.ID != "" {
+		m["issuer"] = vc.Issuer
+	}
+
+	fromKey, untilKey := "validFrom", "validUntil"
+	if vc.Version() == V1 {
+		fromKey, untilKey = "issuanceDate", "expirationDate"
+	}
+	if !vc.ValidFrom.IsZero() {
+		m[fromKey] = formatTime(vc.ValidFrom)
+	}
+	if !vc.ValidUntil.IsZero() {
+		m[untilKey] = formatTime(vc.ValidUntil)
+	}
+
+	switch len(vc.CredentialSubject) {
+	case 0:
+	case 1:
+		m["credentialSubject"] = vc.CredentialSubject[0]
+	default:
+		m["credentialSubject"] = vc.CredentialSubject
+	}
+	switch len(vc.CredentialSchema) {
+	case 0:
+	case 1:
+		m["credentialSchema"] = vc.CredentialSchema[0]
+	default:
+		m["credentialSchema"] = vc.CredentialSchema
+	}
+	if proof := proofValue(vc.Proof); proof != nil {
+		m["proof"] = proof
+	}
+	return json.Marshal(m)
+}
+
+func (vc *VerifiableCredential) UnmarshalJSON(data []byte) error {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return derrors.Wrap(derrors.CodeInvalidInput, "invalid credential json", err)
+	}
+
+	var out VerifiableCredential
+	var err error
+	if out.Context, err = stringOrArray(m["@context"]); err != nil {
+		return derrors.Wrap(derrors.CodeInvalidInput, "invalid credential @context", err)
+	}
+	if out.Type, err = stringOrArray(m["type"]); err != nil {
+		return derrors.Wrap(derrors.CodeInvalidInput, "invalid credential type", err)
+	}
+	out.ID, _ = m["id"].(string)
+	if raw, ok := m["issuer"]; ok {
+		if err := remarshal(raw, &out.Issuer); err != nil {
+			return err
+		}
+	}
+	if out.ValidFrom, err = firstTime(m, "validFrom", "issuanceDate"); err != nil {
+		return err
+	}
+	if out.ValidUntil, err = firstTime(m, "validUntil", "expirationDate"); err != nil {
+		return err
+	}
+	switch raw := m["credentialSubject"].(type) {
+	case nil:
+	case []interface{}:
+		if err := remarshal(raw, &out.CredentialSubject); err != nil {
+			return err
+		}
+	default:
+		var subject CredentialSubject
+		if err := remarshal(raw, &subject); err != nil {
+			return err
+		}
+		out.CredentialSubject = []CredentialSubject{subject}
+	}
+	switch raw := m["credentialSchema"].(type) {
+	case nil:
+	case []interface{}:
+		if err := remarshal(raw, &out.CredentialSchema); err != nil {
+			return err
+		}
+	default:
+		var schema CredentialSchema
+		if err := remarshal(raw, &schema); err != nil {
+			return err
+		}
+		out.CredentialSchema = []CredentialSchema{schema}
+	}
+	if out.Proof, err = decodeProof(m["proof"]); err != nil {
+		return err
+	}
+
+	for _, key := range []string{"@context", "id", "type", "issuer", "validFrom", "issuanceDate",
+		"validUntil", "expirationDate", "credentialSubject", "credentialSchema", "proof"} {
+		delete(m, key)
+	}
+	if len(m) > 0 {
+		out.Extra = m
+	}
+	*vc = out
+	return nil
+}
+
+func (i Issuer) MarshalJSON() ([]byte, error) {
+	if i.Name == "" {
+		return json.Marshal(i.ID)
+	}
+	type issuer Issuer
+	return json.Marshal(issuer(i))
+}
+
+func (i *Issuer) UnmarshalJSON(data []byte) error {
+	var id string
+	if err := json.Unmarshal(data, &id); err == nil {
+		*i = Issuer{ID: id}
+		return nil
+	}
+	type issuer Issuer
+	var out issuer
+	if err := json.Unmarshal(data, &out); err != nil {
+		return derrors.Wrap(derrors.CodeInvalidInput, "invalid issuer", err)
+	}
+	*i = Issuer(out)
+	return nil
+}
+
+func (s CredentialSubject) MarshalJSON() ([]byte, error) {
+	m := copyMap(s.Claims)
+	if s.ID != "" {
+		m["id"] = s.ID
+	}
+	return json.Marshal(m)
+}
+
+func (s *CredentialSubject) UnmarshalJSON(data []byte) error {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return derrors.Wrap(derrors.CodeInvalidInput, "invalid credentialSubject", err)
+	}
+	id, _ := m["id"].(string)
+	delete(m, "id")
+	*s = CredentialSubject{ID: id, Claims: m}
+	return nil
+}
@@ -0,0 +1,124 @@
+package vcdm
+
+import (
+	"byd50-ssi/pkg/did/core/dataintegrity"
+	derrors "byd50-ssi/pkg/did/errors"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+func contextVersion(context []string) Version {
+	if len(context) > 0 && context[0] == ContextV1 {
+		return V1
+	}
+	return V2
+}
+
+func types(base, typ string) []string {
+	typArray := []string{base}
+	if typ != "" {
+		typArray = append(typArray, typ)
+	}
+	return typArray
+}
+
+// stringOrArray decodes a JSON-LD value that may be a single string or an array of strings.
+func stringOrArray(v interface{}) ([]string, error) {
+	switch value := v.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return []string{value}, nil
+	case []interface{}:
+		out := make([]string, 0, len(value))
+		for _, item := range value {
+			s, ok := item.(string)
+			if !ok {
+				return nil, errors.New("expected string entries")
+			}
+			out = append(out, s)
+		}
+		return out, nil
+	default:
+		return nil, errors.New("expected string or array")
+	}
+}
+
+func firstTime(m map[string]interface{}, keys ...string) (time.Time, error) {
+	for _, key := range keys {
+		raw, ok := m[key]
+		if !ok {
+			continue
+		}
+		s, ok := raw.(string)
+		if !ok {
+			return time.Time{}, derrors.New(derrors.CodeInvalidInput, key+" must be a string")
+		}
+		t, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			return time.Time{}, derrors.Wrap(derrors.CodeInvalidInput, "invalid "+key, err)
+		}
+		return t, nil
+	}
+	return time.Time{}, nil
+}
+
+func formatTime(t time.Time) string {
+	return t.UTC().Format(time.RFC3339)
+}
+
+func copyMap(in map[string]interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(in))
+	for k, v := range in {
+		out[k] = v
+	}
+	return out
+}
+
+// remarshal converts between a generic JSON value (e.g. a claim map) and a typed value.
+func remarshal(in interface{}, out interface{}) error {
+	data, err := json.Marshal(in)
+	if err != nil {
+		return derrors.Wrap(derrors.CodeInvalidInput, "failed to encode json", err)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		var dErr *derrors.Error
+		if errors.As(err, &dErr) {
+			return err
+		}
+		return derrors.Wrap(derrors.CodeInvalidInput, "failed to decode json", err)
+	}
+	return nil
+}
+
+// proofValue returns the JSON value of a proof property: a single object or a proof set.
+func proofValue(proofs []dataintegrity.Proof) interface{} {
+	switch len(proofs) {
+	case 0:
+		return nil
+	case 1:
+		return proofs[0]
+	default:
+		return proofs
+	}
+}
+
+func decodeProof(raw interface{}) ([]dataintegrity.Proof, error) {
+	switch value := raw.(type) {
+	case nil:
+		return nil, nil
+	case []interface{}:
+		var proofs []dataintegrity.Proof
+		if err := remarshal(value, &proofs); err != nil {
+			return nil, err
+		}
+		return proofs, nil
+	default:
+		var proof dataintegrity.Proof
+		if err := remarshal(value, &proof); err != nil {
+			return nil, err
+		}
+		return []dataintegrity.Proof{proof}, nil
+	}
+}
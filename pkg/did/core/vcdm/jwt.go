@@ -0,0 +1,142 @@
+package vcdm
+
+import (
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// ToVcClaims encodes the credential as JWT-VC claims.
+// Standard claims that are left empty are derived from the credential (jti=id, iss=issuer,
+// sub=subject id, nbf=validFrom, exp=validUntil); the derived properties are then omitted from the vc claim.
+func (vc *VerifiableCredential) ToVcClaims(standardClaims jwt.StandardClaims, nonce string) (byd50_jwt.VcClaims, error) {
+	if standardClaims.Id == "" {
+		standardClaims.Id = vc.ID
+	}
+	if standardClaims.Issuer == "" {
+		standardClaims.Issuer = vc.Issuer.ID
+	}
+	if standardClaims.Subject == "" && len(vc.CredentialSubject) == 1 {
+		standardClaims.Subject = vc.CredentialSubject[0].ID
+	}
+	if standardClaims.NotBefore == 0 && !vc.ValidFrom.IsZero() {
+		standardClaims.NotBefore = vc.ValidFrom.Unix()
+	}
+	if standardClaims.ExpiresAt == 0 && !vc.ValidUntil.IsZero() {
+		standardClaims.ExpiresAt = vc.ValidUntil.Unix()
+	}
+
+	var myVc map[string]interface{}
+	if err := remarshal(vc, &myVc); err != nil {
+		return byd50_jwt.VcClaims{}, err
+	}
+	for _, key := range []string{"id", "validFrom", "issuanceDate", "validUntil", "expirationDate"} {
+		delete(myVc, key)
+	}
+	if vc.Issuer.Name == "" {
+		delete(myVc, "issuer")
+	}
+
+	return byd50_jwt.VcClaims{
+		Nonce:          nonce,
+		Vc:             myVc,
+		StandardClaims: standardClaims,
+	}, nil
+}
+
+// CredentialFromClaims decodes JWT-VC claims into a credential, filling properties from the standard claims.
+func CredentialFromClaims(claims byd50_jwt.VcClaims) (*VerifiableCredential, error) {
+	vc := new(VerifiableCredential)
+	if err := remarshal(claims.Vc, vc); err != nil {
+		return nil, err
+	}
+	if vc.ID == "" {
+		vc.ID = claims.Id
+	}
+	if vc.Issuer.ID == "" {
+		vc.Issuer.ID = claims.Issuer
+	}
+	if vc.ValidFrom.IsZero() {
+		vc.ValidFrom = unixTime(claims.NotBefore, claims.IssuedAt)
+	}
+	if vc.ValidUntil.IsZero() {
+		vc.ValidUntil = unixTime(claims.ExpiresAt)
+	}
+	if len(vc.CredentialSubject) == 1 && vc.CredentialSubject[0].ID == "" {
+		vc.CredentialSubject[0].ID = claims.Subject
+	}
+	return vc, nil
+}
+
+// CredentialFromMapClaims decodes the claims of a parsed JWT-VC into a credential.
+func CredentialFromMapClaims(mapClaims jwt.MapClaims) (*VerifiableCredential, error) {
+	var claims byd50_jwt.VcClaims
+	if err := remarshal(withoutAudience(mapClaims), &claims); err != nil {
+		return nil, err
+	}
+	return CredentialFromClaims(claims)
+}
+
+// ToVpClaims encodes the presentation as JWT-VP claims (jti=id, iss=holder).
+func (vp *VerifiablePresentation) ToVpClaims(standardClaims jwt.StandardClaims, nonce string) (byd50_jwt.VpClaims, error) {
+	if standardClaims.Id == "" {
+		standardClaims.Id = vp.ID
+	}
+	if standardClaims.Issuer == "" {
+		standardClaims.Issuer = vp.Holder
+	}
+
+	var myVp map[string]interface{}
+	if err := remarshal(vp, &myVp); err != nil {
+		return byd50_jwt.VpClaims{}, err
+	}
+	delete(myVp, "id")
+	delete(myVp, "holder")
+
+	return byd50_jwt.VpClaims{
+		Nonce:          nonce,
+		Vp:             myVp,
+		StandardClaims: standardClaims,
+	}, nil
+}
+
+// PresentationFromClaims decodes JWT-VP claims into a presentation, filling properties from the standard claims.
+func PresentationFromClaims(claims byd50_jwt.VpClaims) (*VerifiablePresentation, error) {
+	vp := new(VerifiablePresentation)
+	if err := remarshal(claims.Vp, vp); err != nil {
+		return nil, err
+	}
+	if vp.ID == "" {
+		vp.ID = claims.Id
+	}
+	if vp.Holder == "" {
+		vp.Holder = claims.Issuer
+	}
+	return vp, nil
+}
+
+// PresentationFromMapClaims decodes the claims of a parsed JWT-VP into a presentation.
+func PresentationFromMapClaims(mapClaims jwt.MapClaims) (*VerifiablePresentation, error) {
+	var claims byd50_jwt.VpClaims
+	if err := remarshal(withoutAudience(mapClaims), &claims); err != nil {
+		return nil, err
+	}
+	return PresentationFromClaims(claims)
+}
+
+// withoutAudience drops aud, which jwt.StandardClaims can not decode when it is an array.
+func withoutAudience(mapClaims jwt.MapClaims) map[string]interface{} {
+	out := copyMap(mapClaims)
+	delete(out, "aud")
+	return out
+}
+
+func unixTime(candidates ...int64) time.Time {
+	for _, sec := range candidates {
+		if sec != 0 {
+			return time.Unix(sec, 0).UTC()
+		}
+	}
+	return time.Time{}
+}
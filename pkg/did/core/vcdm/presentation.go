@@ -0,0 +1,152 @@
+package vcdm
+
+import (
+	"byd50-ssi/pkg/did/core/dataintegrity"
+	derrors "byd50-ssi/pkg/did/errors"
+	"encoding/json"
+)
+
+// PresentedCredential is an entry of verifiableCredential in a presentation.
+// It is either an enveloped JWT-VC or an embedded credential.
+type PresentedCredential struct {
+	Jwt        string
+	Credential *VerifiableCredential
+}
+
+// VerifiablePresentation is a typed VCDM presentation.
+// Properties that are not modeled explicitly are preserved in Extra.
+type VerifiablePresentation struct {
+	Context              []string
+	ID                   string
+	Type                 []string
+	Holder               string
+	VerifiableCredential []PresentedCredential
+	Proof                []dataintegrity.Proof
+	Extra                map[string]interface{}
+}
+
+// NewPresentation returns a VCDM 2.0 presentation of the given type.
+func NewPresentation(typ string, credentials ...PresentedCredential) *VerifiablePresentation {
+	return &VerifiablePresentation{
+		Context:              []string{ContextV2, ContextExamplesV2},
+		Type:                 types(TypeVerifiablePresentation, typ),
+		VerifiableCredential: credentials,
+	}
+}
+
+// NewPresentationV1 returns a presentation of the given type using the VCDM 1.1 contexts.
+func NewPresentationV1(typ string, credentials ...PresentedCredential) *VerifiablePresentation {
+	return &VerifiablePresentation{
+		Context:              []string{ContextV1, ContextExamplesV1},
+		Type:                 types(TypeVerifiablePresentation, typ),
+		VerifiableCredential: credentials,
+	}
+}
+
+// JwtCredentials wraps VC JWTs as presented credentials.
+func JwtCredentials(vcJwts []string) []PresentedCredential {
+	credentials := make([]PresentedCredential, 0, len(vcJwts))
+	for _, vcJwt := range vcJwts {
+		credentials = append(credentials, PresentedCredential{Jwt: vcJwt})
+	}
+	return credentials
+}
+
+// Version reports the data model version declared by the base context.
+func (vp *VerifiablePresentation) Version() Version {
+	return contextVersion(vp.Context)
+}
+
+// CredentialJwts returns the enveloped VC JWTs of the presentation.
+func (vp *VerifiablePresentation) CredentialJwts() []string {
+	var vcJwts []string
+	for _, credential := range vp.VerifiableCredential {
+		if credential.Jwt != "" {
+			vcJwts = append(vcJwts, credential.Jwt)
+		}
+	}
+	return vcJwts
+}
+
+func (vp VerifiablePresentation) MarshalJSON() ([]byte, error) {
+	m := copyMap(vp.Extra)
+	m["@context"] = vp.Context
+	if vp.ID != "" {
+		m["id"] = vp.ID
+	}
+	m["type"] = vp.Type
+	if vp.Holder != "" {
+		m["holder"] = vp.Holder
+	}
+	if vp.VerifiableCredential != nil {
+		m["verifiableCredential"] = vp.VerifiableCredential
+	}
+	if proof := proofValue(vp.Proof); proof != nil {
+		m["proof"] = proof
+	}
+	return json.Marshal(m)
+}
+
+func (vp *VerifiablePresentation) UnmarshalJSON(data []byte) error {
+	var m map[string]interface{}
+	if err := json.Unmarshal(data, &m); err != nil {
+		return derrors.Wrap(derrors.CodeInvalidInput, "invalid presentation json", err)
+	}
+
+	var out VerifiablePresentation
+	var err error
+	if out.Context, err = stringOrArray(m["@context"]); err != nil {
+		return derrors.Wrap(derrors.CodeInvalidInput, "invalid presentation @context", err)
+	}
+	if out.Type, err = stringOrArray(m["type"]); err != nil {
+		return derrors.Wrap(derrors.CodeInvalidInput, "invalid presentation type", err)
+	}
+	out.ID, _ = m["id"].(string)
+	out.Holder, _ = m["holder"].(string)
+	switch raw := m["verifiableCredential"].(type) {
+	case nil:
+	case []interface{}:
+		if err := remarshal(raw, &out.VerifiableCredential); err != nil {
+			return err
+		}
+	default:
+		var credential PresentedCredential
+		if err := remarshal(raw, &credential); err != nil {
+			return err
+		}
+		out.VerifiableCredential = []PresentedCredential{credential}
+	}
+	if out.Proof, err = decodeProof(m["proof"]); err != nil {
+		return err
+	}
+
+	for _, key := range []string{"@context", "id", "type", "holder", "verifiableCredential", "proof"} {
+		delete(m, key)
+	}
+	if len(m) > 0 {
+		out.Extra = m
+	}
+	*vp = out
+	return nil
+}
+
+func (c PresentedCredential) MarshalJSON() ([]byte, error) {
+	if c.Credential != nil {
+		return json.Marshal(c.Credential)
+	}
+	return json.Marshal(c.Jwt)
+}
+
+func (c *PresentedCredential) UnmarshalJSON(data []byte) error {
+	var vcJwt string
+	if err := json.Unmarshal(data, &vcJwt); err == nil {
+		*c = PresentedCredential{Jwt: vcJwt}
+		return nil
+	}
+	credential := new(VerifiableCredential)
+	if err := json.Unmarshal(data, credential); err != nil {
+		return err
+	}
+	*c = PresentedCredential{Credential: credential}
+	return nil
+}
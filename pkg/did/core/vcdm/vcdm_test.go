@@ -0,0 +1,173 @@
+package vcdm
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+func TestCredentialV2RoundTrip(t *testing.T) {
+	validFrom := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	vc := NewCredential("DriverLicenseCredential", CredentialSubject{
+		ID:     "did:byd50:holder",
+		Claims: map[string]interface{}{"licenseType": "Type-1"},
+	})
+	vc.ID = "urn:uuid:1234"
+	vc.Issuer = Issuer{ID: "did:byd50:issuer", Name: "License Office"}
+	vc.ValidFrom = validFrom
+	vc.ValidUntil = validFrom.Add(24 * time.Hour)
+	vc.Extra = map[string]interface{}{"evidence": "checked"}
+
+	data, err := json.Marshal(vc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(data, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw["validFrom"] != "2024-01-01T00:00:00Z" || raw["issuanceDate"] != nil {
+		t.Fatalf("unexpected v2 dates: %s", data)
+	}
+	if _, ok := raw["issuer"].(map[string]interface{}); !ok {
+		t.Fatalf("expected issuer object: %s", data)
+	}
+	if _, ok := raw["credentialSubject"].(map[string]interface{}); !ok {
+		t.Fatalf("expected single credentialSubject object: %s", data)
+	}
+
+	var decoded VerifiableCredential
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Version() != V2 || decoded.ID != vc.ID || decoded.Issuer != vc.Issuer {
+		t.Fatalf("unexpected credential: %+v", decoded)
+	}
+	if !decoded.ValidFrom.Equal(vc.ValidFrom) || !decoded.ValidUntil.Equal(vc.ValidUntil) {
+		t.Fatalf("unexpected validity: %v - %v", decoded.ValidFrom, decoded.ValidUntil)
+	}
+	if len(decoded.CredentialSubject) != 1 || decoded.CredentialSubject[0].ID != "did:byd50:holder" ||
+		decoded.CredentialSubject[0].Claims["licenseType"] != "Type-1" {
+		t.Fatalf("unexpected subject: %+v", decoded.CredentialSubject)
+	}
+	if decoded.Extra["evidence"] != "checked" {
+		t.Fatalf("extra properties lost: %+v", decoded.Extra)
+	}
+}
+
+func TestCredentialV1Compat(t *testing.T) {
+	data := []byte(`{
+		"@context": ["https://www.w3.org/2018/credentials/v1"],
+		"type": "VerifiableCredential",
+		"issuer": "did:byd50:issuer",
+		"issuanceDate": "2021-06-08T14:04:43Z",
+		"expirationDate": "2022-06-08T14:04:43Z",
+		"credentialSubject": [{"id": "did:byd50:a"}, {"id": "did:byd50:b"}]
+	}`)
+	var vc VerifiableCredential
+	if err := json.Unmarshal(data, &vc); err != nil {
+		t.Fatal(err)
+	}
+	if vc.Version() != V1 || vc.Issuer.ID != "did:byd50:issuer" || len(vc.CredentialSubject) != 2 {
+		t.Fatalf("unexpected credential: %+v", vc)
+	}
+	if vc.ValidFrom.IsZero() || vc.ValidUntil.IsZero() {
+		t.Fatal("v1 dates not mapped to validFrom/validUntil")
+	}
+
+	out, err := json.Marshal(vc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var raw map[string]interface{}
+	if err := json.Unmarshal(out, &raw); err != nil {
+		t.Fatal(err)
+	}
+	if raw["issuanceDate"] != "2021-06-08T14:04:43Z" || raw["validFrom"] != nil {
+		t.Fatalf("v1 credential must keep issuanceDate: %s", out)
+	}
+	if raw["issuer"] != "did:byd50:issuer" {
+		t.Fatalf("expected issuer string: %s", out)
+	}
+
+	if err := json.Unmarshal([]byte(`{"issuanceDate": "yesterday"}`), &vc); err == nil {
+		t.Fatal("expected error for invalid date")
+	}
+}
+
+func TestVcClaimsMapping(t *testing.T) {
+	vc := NewCredentialV1("AlumniCredential", CredentialSubject{
+		ID:     "did:byd50:holder",
+		Claims: map[string]interface{}{"degree": "BachelorDegree"},
+	})
+	vc.ID = "urn:uuid:5678"
+	vc.Issuer = Issuer{ID: "did:byd50:issuer"}
+	vc.ValidUntil = time.Now().Add(time.Hour).Truncate(time.Second)
+
+	claims, err := vc.ToVcClaims(jwt.StandardClaims{IssuedAt: time.Now().Unix()}, "nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Id != vc.ID || claims.Issuer != "did:byd50:issuer" || claims.Subject != "did:byd50:holder" {
+		t.Fatalf("standard claims not derived: %+v", claims.StandardClaims)
+	}
+	if claims.ExpiresAt != vc.ValidUntil.Unix() {
+		t.Fatalf("unexpected exp: %d", claims.ExpiresAt)
+	}
+	if _, ok := claims.Vc["issuer"]; ok {
+		t.Fatal("issuer must be carried by iss")
+	}
+
+	mapClaims := jwt.MapClaims{}
+	if err := remarshal(claims, &mapClaims); err != nil {
+		t.Fatal(err)
+	}
+	mapClaims["aud"] = []interface{}{"did:byd50:rp"}
+	decoded, err := CredentialFromMapClaims(mapClaims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.ID != vc.ID || decoded.Issuer.ID != vc.Issuer.ID || !decoded.ValidUntil.Equal(vc.ValidUntil) {
+		t.Fatalf("unexpected credential: %+v", decoded)
+	}
+	if decoded.ValidFrom.IsZero() {
+		t.Fatal("validFrom must fall back to iat")
+	}
+	if decoded.Type[1] != "AlumniCredential" || decoded.CredentialSubject[0].Claims["degree"] != "BachelorDegree" {
+		t.Fatalf("unexpected credential: %+v", decoded)
+	}
+}
+
+func TestPresentationMapping(t *testing.T) {
+	embedded := NewCredential("AlumniCredential", CredentialSubject{ID: "did:byd50:holder"})
+	vp := NewPresentation("CredentialManagerPresentation",
+		PresentedCredential{Jwt: "eyJhbGciOi.payload.sig"},
+		PresentedCredential{Credential: embedded},
+	)
+	vp.Holder = "did:byd50:holder"
+
+	claims, err := vp.ToVpClaims(jwt.StandardClaims{Id: "urn:uuid:vp"}, "nonce")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if claims.Issuer != "did:byd50:holder" {
+		t.Fatalf("holder must be carried by iss: %+v", claims.StandardClaims)
+	}
+
+	decoded, err := PresentationFromClaims(claims)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decoded.Holder != vp.Holder || decoded.ID != "urn:uuid:vp" {
+		t.Fatalf("unexpected presentation: %+v", decoded)
+	}
+	if got := decoded.CredentialJwts(); len(got) != 1 || got[0] != "eyJhbGciOi.payload.sig" {
+		t.Fatalf("unexpected jwt credentials: %v", got)
+	}
+	if decoded.VerifiableCredential[1].Credential == nil ||
+		decoded.VerifiableCredential[1].Credential.CredentialSubject[0].ID != "did:byd50:holder" {
+		t.Fatalf("embedded credential lost: %+v", decoded.VerifiableCredential)
+	}
+}
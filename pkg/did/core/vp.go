@@ -2,19 +2,41 @@ package core
 
 import (
 	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	"byd50-ssi/pkg/did/core/vcdm"
 	derrors "byd50-ssi/pkg/did/errors"
-	"crypto/ecdsa"
+	"crypto"
 	"github.com/golang-jwt/jwt"
+	"log"
 	"time"
 )
 
-func CreateVp(kid, typ string, vcJwtArray []string, standardClaims jwt.StandardClaims, pvKey *ecdsa.PrivateKey) string {
-	claims := buildVpClaims(typ, vcJwtArray, standardClaims)
+func CreateVp(kid, typ string, vcJwtArray []string, standardClaims jwt.StandardClaims, pvKey crypto.PrivateKey) string {
+	claims, err := buildVpClaims(typ, vcJwtArray, standardClaims)
+	if err != nil {
+		log.Printf("build vp claims failed: %v", err)
+		return ""
+	}
 	vpJwt := byd50_jwt.CreateVp(kid, claims, pvKey)
 	return vpJwt
 }
 
-func CreateVpWithClaims(kid string, claims byd50_jwt.VpClaims, pvKey *ecdsa.PrivateKey) string {
+// CreateVpWithPresentation signs a typed presentation as a JWT-VP.
+func CreateVpWithPresentation(kid string, vp *vcdm.VerifiablePresentation, standardClaims jwt.StandardClaims, pvKey crypto.PrivateKey) (string, error) {
+	if vp == nil {
+		return "", derrors.New(derrors.CodeInvalidInput, "presentation is nil")
+	}
+	claims, err := buildVpClaimsFromPresentation(vp, standardClaims)
+	if err != nil {
+		return "", err
+	}
+	vpJwt := byd50_jwt.CreateVp(kid, claims, pvKey)
+	if vpJwt == "" {
+		return "", derrors.New(derrors.CodeInternal, "failed to sign vp")
+	}
+	return vpJwt, nil
+}
+
+func CreateVpWithClaims(kid string, claims byd50_jwt.VpClaims, pvKey crypto.PrivateKey) string {
 	vpJwt := byd50_jwt.CreateVp(kid, claims, pvKey)
 	return vpJwt
 }
@@ -30,6 +52,40 @@ func VerifyVp(vp string, getPbKey func(string, string) string) (bool, string, er
 	return true, did, nil
 }
 
+// VerifyVpWithKeys verifies the VP signature with getAuthKey (authentication keys of the holder) and
+// the embedded VCs with getAssertionKey (assertionMethod keys of the issuers).
+func VerifyVpWithKeys(vp string, getAuthKey, getAssertionKey func(string, string) string) (bool, string, error) {
+	ok, did, err := byd50_jwt.VerifyVpWithKeys(vp, getAuthKey, getAssertionKey)
+	if err != nil {
+		return false, did, err
+	}
+	if !ok {
+		return false, did, derrors.New(derrors.CodeInvalidInput, "vp signature invalid")
+	}
+	return true, did, nil
+}
+
+// VerifyVpWithBinding is VerifyVpWithKeys for a presentation made for one verifier request: the VP must carry
+// the nonce of the request and name aud in its aud claim, so it cannot be replayed to another verifier or request.
+func VerifyVpWithBinding(vp, aud, nonce string, getAuthKey, getAssertionKey func(string, string) string) (bool, string, error) {
+	ok, did, err := VerifyVpWithKeys(vp, getAuthKey, getAssertionKey)
+	if !ok {
+		return false, did, err
+	}
+	_, claims, err := GetMapClaims(vp, getAuthKey)
+	if err != nil {
+		return false, did, derrors.Wrap(derrors.CodeInvalidInput, "vp claims invalid", err)
+	}
+	if got, _ := claims["nonce"].(string); nonce == "" || got != nonce {
+		return false, did, derrors.New(derrors.CodeInvalidInput, "vp nonce mismatch")
+	}
+	audiences, err := byd50_jwt.MapClaims(claims).GetAudience()
+	if err != nil || aud == "" || !Contains(audiences, aud) {
+		return false, did, derrors.New(derrors.CodeInvalidInput, "vp aud mismatch")
+	}
+	return true, did, nil
+}
+
 func GetMapClaims(vp string, getPbKey func(string, string) string) (bool, jwt.MapClaims, error) {
 	ok, mapClaims, err := byd50_jwt.ParseVp(vp, getPbKey)
 	return ok, mapClaims, err
@@ -45,31 +101,16 @@ func ClaimsGetIat(mapclaims jwt.MapClaims) (time.Time, error) {
 	return iatTime, err
 }
 
-func buildVpClaims(typ string, vcJwtArray []string, standardClaims jwt.StandardClaims) byd50_jwt.VpClaims {
-	typArray := []string{"VerifiablePresentation"}
-	if typ != "" {
-		typArray = append(typArray, typ)
-	}
-
-	myVp := map[string]interface{}{
-		"@context": []string{
-			"https://www.w3.org/2018/credentials/v1",
-			"https://www.w3.org/2018/credentials/examples/v1",
-		},
-		"type":                 typArray,
-		"verifiableCredential": vcJwtArray,
-	}
-
-	nonce := RandomString(12)
+func buildVpClaims(typ string, vcJwtArray []string, standardClaims jwt.StandardClaims) (byd50_jwt.VpClaims, error) {
+	vp := vcdm.NewPresentationV1(typ, vcdm.JwtCredentials(vcJwtArray)...)
+	return buildVpClaimsFromPresentation(vp, standardClaims)
+}
 
-	// Create the Claims
-	claims := byd50_jwt.VpClaims{
-		nonce,
-		myVp,
-		standardClaims,
+func buildVpClaimsFromPresentation(vp *vcdm.VerifiablePresentation, standardClaims jwt.StandardClaims) (byd50_jwt.VpClaims, error) {
+	if standardClaims.Id == "" && vp.ID == "" {
+		standardClaims.Id = NewCredentialID()
 	}
-
-	return claims
+	return vp.ToVpClaims(standardClaims, RandomString(12))
 }
 
 // ValidateVpClaims ensures required standard claims are present and consistent.
@@ -0,0 +1,410 @@
+package kms
+
+import (
+	derrors "byd50-ssi/pkg/did/errors"
+	"byd50-ssi/pkg/keys"
+	"crypto"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rand"
+	"crypto/rsa"
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"golang.org/x/crypto/scrypt"
+)
+
+const (
+	keystoreVersion = 1
+	keystoreKdf     = "scrypt"
+	// scrypt parameters of new keystores, as recommended for interactive logins (RFC 7914)
+	scryptN      = 1 << 15
+	scryptR      = 8
+	scryptP      = 1
+	scryptKeyLen = 32
+	saltLen      = 16
+)
+
+// ErrWrongPassphrase is returned by OpenKeystore when the passphrase does not decrypt the stored keys.
+var ErrWrongPassphrase = errors.New("keystore passphrase is wrong or the keystore is corrupted")
+
+// Keystore keeps key pairs on disk, encrypted with AES-256-GCM under a key derived from a passphrase with
+// scrypt. Keys are named per DID, so a DID can have several keys (e.g. "signing" and "auth"), and aliases
+// map stable names such as "issuer" to the DID an application uses, so it can find its identity again
+// after a restart. Opening the keystore decrypts all keys; they stay in memory until the process exits.
+type Keystore struct {
+	path string
+	key  []byte
+
+	mu      sync.Mutex
+	file    keystoreFile
+	pvKeys  map[string]crypto.PrivateKey
+	keyType map[string]string
+}
+
+type keystoreFile struct {
+	Version int               `json:"version"`
+	Kdf     kdfParams         `json:"kdf"`
+	Keys    []keystoreEntry   `json:"keys"`
+	Aliases map[string]string `json:"aliases,omitempty"`
+}
+
+type kdfParams struct {
+	Name string `json:"name"`
+	N    int    `json:"n"`
+	R    int    `json:"r"`
+	P    int    `json:"p"`
+	Salt []byte `json:"salt"`
+}
+
+type keystoreEntry struct {
+	Did  string `json:"did"`
+	Name string `json:"name"`
+	Type string `json:"type"`
+	// PublicKeyBase58 is stored in clear so the keys of a keystore can be listed.
+	PublicKeyBase58 string `json:"publicKeyBase58"`
+	Nonce           []byte `json:"nonce"`
+	Ciphertext      []byte `json:"ciphertext"`
+}
+
+// KeyInfo describes a stored key without its private part.
+type KeyInfo struct {
+	Did             string `json:"did"`
+	Name            string `json:"name"`
+	Type            string `json:"type"`
+	PublicKeyBase58 string `json:"publicKeyBase58"`
+}
+
+// OpenKeystore loads and unlocks the keystore at path, or starts an empty one that is created on the first
+// Put. The passphrase must not be empty.
+func OpenKeystore(path, passphrase string) (*Keystore, error) {
+	if passphrase == "" {
+		return nil, derrors.New(derrors.CodeInvalidInput, "keystore passphrase is empty")
+	}
+	ks := &Keystore{path: path, pvKeys: map[string]crypto.PrivateKey{}, keyType: map[string]string{}}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		salt := make([]byte, saltLen)
+		if _, err := rand.Read(salt); err != nil {
+			return nil, derrors.Wrap(derrors.CodeInternal, "failed to create keystore salt", err)
+		}
+		ks.file = keystoreFile{
+			Version: keystoreVersion,
+			Kdf:     kdfParams{Name: keystoreKdf, N: scryptN, R: scryptR, P: scryptP, Salt: salt},
+		}
+	} else if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInternal, "failed to read keystore", err)
+	} else if err := json.Unmarshal(data, &ks.file); err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "invalid keystore file", err)
+	}
+	if ks.file.Version != keystoreVersion || ks.file.Kdf.Name != keystoreKdf {
+		return nil, derrors.New(derrors.CodeInvalidInput, "unsupported keystore version or kdf")
+	}
+
+	ks.key, err = scrypt.Key([]byte(passphrase), ks.file.Kdf.Salt, ks.file.Kdf.N, ks.file.Kdf.R, ks.file.Kdf.P, scryptKeyLen)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "invalid keystore kdf parameters", err)
+	}
+	for _, entry := range ks.file.Keys {
+		pvKey, err := ks.decrypt(entry)
+		if err != nil {
+			return nil, err
+		}
+		ks.pvKeys[entryID(entry.Did, entry.Name)] = pvKey
+		ks.keyType[entryID(entry.Did, entry.Name)] = entry.Type
+	}
+	return ks, nil
+}
+
+// Put stores the private key pvKey as key name of did, replacing a key of the same name, and writes the
+// keystore to disk.
+func (ks *Keystore) Put(did, name string, pvKey crypto.PrivateKey) error {
+	if did == "" || name == "" {
+		return derrors.New(derrors.CodeInvalidInput, "did and key name are required")
+	}
+	keyType, pbKey, err := describeKey(pvKey)
+	if err != nil {
+		return err
+	}
+	plaintext := []byte(keys.ExportPrivateKeyAsBase58(pvKey))
+	if len(plaintext) == 0 {
+		return derrors.New(derrors.CodeInvalidKey, "failed to encode private key")
+	}
+	gcm, err := ks.aead()
+	if err != nil {
+		return err
+	}
+	nonce := make([]byte, gcm.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return derrors.Wrap(derrors.CodeInternal, "failed to create nonce", err)
+	}
+	entry := keystoreEntry{
+		Did:             did,
+		Name:            name,
+		Type:            keyType,
+		PublicKeyBase58: keys.ExportPublicKeyAsBase58(pbKey),
+		Nonce:           nonce,
+		// the entry id is authenticated, so an encrypted key cannot be moved to another DID or name
+		Ciphertext: gcm.Seal(nil, nonce, plaintext, []byte(entryID(did, name))),
+	}
+
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	id := entryID(did, name)
+	entries := make([]keystoreEntry, 0, len(ks.file.Keys)+1)
+	for _, existing := range ks.file.Keys {
+		if entryID(existing.Did, existing.Name) != id {
+			entries = append(entries, existing)
+		}
+	}
+	previous := ks.file.Keys
+	ks.file.Keys = append(entries, entry)
+	if err := ks.save(); err != nil {
+		ks.file.Keys = previous
+		return err
+	}
+	ks.pvKeys[id] = pvKey
+	ks.keyType[id] = keyType
+	return nil
+}
+
+// Get returns the private key name of did.
+func (ks *Keystore) Get(did, name string) (crypto.PrivateKey, error) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	pvKey, ok := ks.pvKeys[entryID(did, name)]
+	if !ok {
+		return nil, derrors.New(derrors.CodeNotFound, "key not found: "+entryID(did, name))
+	}
+	return pvKey, nil
+}
+
+// Delete removes the key name of did. Aliases of a DID without keys are removed with its last key.
+func (ks *Keystore) Delete(did, name string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	id := entryID(did, name)
+	if _, ok := ks.pvKeys[id]; !ok {
+		return derrors.New(derrors.CodeNotFound, "key not found: "+id)
+	}
+	previous, previousAliases := ks.file.Keys, ks.file.Aliases
+	entries := make([]keystoreEntry, 0, len(ks.file.Keys))
+	hasKeys := false
+	for _, entry := range ks.file.Keys {
+		if entryID(entry.Did, entry.Name) == id {
+			continue
+		}
+		entries = append(entries, entry)
+		hasKeys = hasKeys || entry.Did == did
+	}
+	ks.file.Keys = entries
+	if !hasKeys {
+		aliases := map[string]string{}
+		for alias, aliasDid := range ks.file.Aliases {
+			if aliasDid != did {
+				aliases[alias] = aliasDid
+			}
+		}
+		ks.file.Aliases = aliases
+	}
+	if err := ks.save(); err != nil {
+		ks.file.Keys, ks.file.Aliases = previous, previousAliases
+		return err
+	}
+	delete(ks.pvKeys, id)
+	delete(ks.keyType, id)
+	return nil
+}
+
+// List returns the stored keys of did, or of all DIDs when did is empty, ordered by DID and name.
+func (ks *Keystore) List(did string) []KeyInfo {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	var infos []KeyInfo
+	for _, entry := range ks.file.Keys {
+		if did == "" || entry.Did == did {
+			infos = append(infos, KeyInfo{Did: entry.Did, Name: entry.Name, Type: entry.Type, PublicKeyBase58: entry.PublicKeyBase58})
+		}
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if infos[i].Did != infos[j].Did {
+			return infos[i].Did < infos[j].Did
+		}
+		return infos[i].Name < infos[j].Name
+	})
+	return infos
+}
+
+// SetAlias makes alias refer to did and writes the keystore to disk.
+func (ks *Keystore) SetAlias(alias, did string) error {
+	if alias == "" || did == "" {
+		return derrors.New(derrors.CodeInvalidInput, "alias and did are required")
+	}
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	previous := ks.file.Aliases
+	aliases := map[string]string{alias: did}
+	for a, d := range previous {
+		if a != alias {
+			aliases[a] = d
+		}
+	}
+	ks.file.Aliases = aliases
+	if err := ks.save(); err != nil {
+		ks.file.Aliases = previous
+		return err
+	}
+	return nil
+}
+
+// Alias returns the DID alias refers to.
+func (ks *Keystore) Alias(alias string) (string, bool) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	did, ok := ks.file.Aliases[alias]
+	return did, ok
+}
+
+// LoadKMS returns a KMS holding the key name of did.
+func (ks *Keystore) LoadKMS(did, name string) (KMS, error) {
+	pvKey, err := ks.Get(did, name)
+	if err != nil {
+		return KMS{}, err
+	}
+	_, pbKey, err := describeKey(pvKey)
+	if err != nil {
+		return KMS{}, err
+	}
+	loaded := newKMS(pvKey, pbKey)
+	loaded.SetDid(did)
+	return loaded, nil
+}
+
+// Identity returns the KMS of the DID alias refers to, holding its key name. The first time, it generates a
+// key pair of keyType, registers a DID for its public key with createDid and stores the key and the alias, so
+// later calls, also after a restart, return the same identity.
+func (ks *Keystore) Identity(alias, name, keyType string, createDid func(pbKeyBase58 string) (string, error)) (KMS, error) {
+	if did, ok := ks.Alias(alias); ok {
+		return ks.LoadKMS(did, name)
+	}
+	switch keyType {
+	case KeyTypeRSA, KeyTypeECDSA, KeyTypeSecp256k1, KeyTypeEd25519:
+	default:
+		return KMS{}, derrors.New(derrors.CodeInvalidInput, "unknown keyType: "+keyType)
+	}
+	pvKey, pbKey := GenerateKeyPair(keyType)
+	created := newKMS(pvKey, pbKey)
+	did, err := createDid(created.PbKeyBase58())
+	if err != nil {
+		return KMS{}, err
+	}
+	if err := created.SetDid(did); err != nil {
+		return KMS{}, derrors.Wrap(derrors.CodeInternal, "created did is invalid", err)
+	}
+	if err := ks.Put(did, name, pvKey); err != nil {
+		return KMS{}, err
+	}
+	if err := ks.SetAlias(alias, did); err != nil {
+		return KMS{}, err
+	}
+	return created, nil
+}
+
+// StoreKMS stores the key pair of k as key name of its DID.
+func (ks *Keystore) StoreKMS(k KMS, name string) error {
+	return ks.Put(k.Did(), name, k.privateKey)
+}
+
+func (ks *Keystore) aead() (cipher.AEAD, error) {
+	block, err := aes.NewCipher(ks.key)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInternal, "failed to init cipher", err)
+	}
+	gcm, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInternal, "failed to init cipher", err)
+	}
+	return gcm, nil
+}
+
+func (ks *Keystore) decrypt(entry keystoreEntry) (crypto.PrivateKey, error) {
+	gcm, err := ks.aead()
+	if err != nil {
+		return nil, err
+	}
+	if len(entry.Nonce) != gcm.NonceSize() {
+		return nil, ErrWrongPassphrase
+	}
+	plaintext, err := gcm.Open(nil, entry.Nonce, entry.Ciphertext, []byte(entryID(entry.Did, entry.Name)))
+	if err != nil {
+		return nil, ErrWrongPassphrase
+	}
+	pvKey, err := keys.ParsePrivateKeyBase58(string(plaintext))
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidKey, "invalid stored key "+entryID(entry.Did, entry.Name), err)
+	}
+	return pvKey, nil
+}
+
+// save writes the keystore atomically, readable by the owner only.
+func (ks *Keystore) save() error {
+	data, err := json.MarshalIndent(ks.file, "", "  ")
+	if err != nil {
+		return derrors.Wrap(derrors.CodeInternal, "failed to encode keystore", err)
+	}
+	if dir := filepath.Dir(ks.path); dir != "" {
+		if err := os.MkdirAll(dir, 0o700); err != nil {
+			return derrors.Wrap(derrors.CodeInternal, "failed to create keystore directory", err)
+		}
+	}
+	tmp, err := os.CreateTemp(filepath.Dir(ks.path), filepath.Base(ks.path)+".*.tmp")
+	if err != nil {
+		return derrors.Wrap(derrors.CodeInternal, "failed to write keystore", err)
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return derrors.Wrap(derrors.CodeInternal, "failed to write keystore", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return derrors.Wrap(derrors.CodeInternal, "failed to write keystore", err)
+	}
+	if err := os.Rename(tmp.Name(), ks.path); err != nil {
+		return derrors.Wrap(derrors.CodeInternal, "failed to write keystore", err)
+	}
+	return nil
+}
+
+// describeKey returns the KeyType and public key of a supported private key.
+func describeKey(pvKey crypto.PrivateKey) (string, crypto.PublicKey, error) {
+	switch v := pvKey.(type) {
+	case *rsa.PrivateKey:
+		return KeyTypeRSA, &v.PublicKey, nil
+	case *ecdsa.PrivateKey:
+		if keys.IsSecp256k1(v.Curve) {
+			return KeyTypeSecp256k1, &v.PublicKey, nil
+		}
+		return KeyTypeECDSA, &v.PublicKey, nil
+	case ed25519.PrivateKey:
+		return KeyTypeEd25519, v.Public(), nil
+	case *ecdh.PrivateKey:
+		if v.Curve() == ecdh.X25519() {
+			return KeyTypeX25519, v.PublicKey(), nil
+		}
+		return "", nil, derrors.New(derrors.CodeInvalidKey, "unsupported private key type")
+	default:
+		return "", nil, derrors.New(derrors.CodeInvalidKey, "unsupported private key type")
+	}
+}
+
+func entryID(did, name string) string {
+	return did + "#" + name
+}
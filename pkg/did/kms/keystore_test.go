@@ -0,0 +1,187 @@
+package kms
+
+import (
+	"byd50-ssi/pkg/keys"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+const keystoreDid = "did:byd50:issuer"
+
+func TestKeystorePersistsKeys(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	ks, err := OpenKeystore(path, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Fatal("expected an empty keystore not to be written")
+	}
+	stored := map[string]interface{}{}
+	for _, keyType := range []string{KeyTypeECDSA, KeyTypeRSA, KeyTypeSecp256k1, KeyTypeEd25519, KeyTypeX25519} {
+		pvKey, _ := GenerateKeyPair(keyType)
+		if err := ks.Put(keystoreDid, keyType, pvKey); err != nil {
+			t.Fatalf("%s: %v", keyType, err)
+		}
+		stored[keyType] = pvKey
+	}
+	if err := ks.SetAlias("issuer", keystoreDid); err != nil {
+		t.Fatal(err)
+	}
+	if info, _ := os.Stat(path); info.Mode().Perm() != 0o600 {
+		t.Fatalf("expected keystore to be private, got %v", info.Mode().Perm())
+	}
+
+	reopened, err := OpenKeystore(path, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if did, ok := reopened.Alias("issuer"); !ok || did != keystoreDid {
+		t.Fatalf("unexpected alias %q", did)
+	}
+	if infos := reopened.List(""); len(infos) != len(stored) {
+		t.Fatalf("expected %d keys in total, got %d", len(stored), len(infos))
+	}
+	infos := reopened.List(keystoreDid)
+	if len(infos) != len(stored) {
+		t.Fatalf("expected %d keys, got %+v", len(stored), infos)
+	}
+	for _, info := range infos {
+		if info.Type != info.Name {
+			t.Fatalf("%s: stored as %s", info.Name, info.Type)
+		}
+		pvKey, err := reopened.Get(keystoreDid, info.Name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if keys.ExportPrivateKeyAsBase58(pvKey) != keys.ExportPrivateKeyAsBase58(stored[info.Name]) {
+			t.Fatalf("%s: key changed across reopen", info.Name)
+		}
+	}
+
+	loaded, err := reopened.LoadKMS(keystoreDid, KeyTypeECDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pvKey, err := loaded.PvKeyECDSA(); loaded.Did() != keystoreDid || err != nil || !pvKey.Equal(stored[KeyTypeECDSA]) {
+		t.Fatalf("unexpected KMS for did %q: %v", loaded.Did(), err)
+	}
+	loaded, err = reopened.LoadKMS(keystoreDid, KeyTypeRSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ok, signature := loaded.Sign("message"); !ok || !loaded.Verify("message", signature) {
+		t.Fatal("expected the loaded KMS to sign")
+	}
+}
+
+func TestKeystoreRejectsWrongPassphraseAndTampering(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	ks, err := OpenKeystore(path, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pvKey, _ := GenerateKeyPair(KeyTypeECDSA)
+	if err := ks.Put(keystoreDid, "signing", pvKey); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenKeystore(path, "battery staple"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("expected a wrong passphrase to be rejected, got %v", err)
+	}
+	if _, err := OpenKeystore(path, ""); err == nil {
+		t.Fatal("expected an empty passphrase to be rejected")
+	}
+
+	// an encrypted key moved to another DID does not decrypt
+	data, _ := os.ReadFile(path)
+	var file keystoreFile
+	if err := json.Unmarshal(data, &file); err != nil {
+		t.Fatal(err)
+	}
+	file.Keys[0].Did = "did:byd50:attacker"
+	data, _ = json.Marshal(file)
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := OpenKeystore(path, "correct horse"); !errors.Is(err, ErrWrongPassphrase) {
+		t.Fatalf("expected a moved key to be rejected, got %v", err)
+	}
+}
+
+func TestKeystoreDelete(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	ks, _ := OpenKeystore(path, "correct horse")
+	signing, _ := GenerateKeyPair(KeyTypeECDSA)
+	auth, _ := GenerateKeyPair(KeyTypeEd25519)
+	if err := ks.Put(keystoreDid, "signing", signing); err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Put(keystoreDid, "auth", auth); err != nil {
+		t.Fatal(err)
+	}
+	ks.SetAlias("issuer", keystoreDid)
+
+	if err := ks.Delete(keystoreDid, "signing"); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := ks.Get(keystoreDid, "signing"); err == nil {
+		t.Fatal("expected the deleted key to be gone")
+	}
+	if _, ok := ks.Alias("issuer"); !ok {
+		t.Fatal("expected the alias to stay while the DID has keys")
+	}
+	if err := ks.Delete(keystoreDid, "auth"); err != nil {
+		t.Fatal(err)
+	}
+	if err := ks.Delete(keystoreDid, "auth"); err == nil {
+		t.Fatal("expected deleting an unknown key to fail")
+	}
+
+	reopened, err := OpenKeystore(path, "correct horse")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if infos := reopened.List(""); len(infos) != 0 {
+		t.Fatalf("expected no keys, got %+v", infos)
+	}
+	if _, ok := reopened.Alias("issuer"); ok {
+		t.Fatal("expected the alias of a DID without keys to be removed")
+	}
+}
+
+func TestKeystoreIdentity(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "keystore.json")
+	ks, _ := OpenKeystore(path, "correct horse")
+	created := 0
+	createDid := func(pbKeyBase58 string) (string, error) {
+		created++
+		return "did:byd50:" + pbKeyBase58[:16], nil
+	}
+	first, err := ks.Identity("issuer", "signing", KeyTypeECDSA, createDid)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reopened, _ := OpenKeystore(path, "correct horse")
+	second, err := reopened.Identity("issuer", "signing", KeyTypeECDSA, createDid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if created != 1 || second.Did() != first.Did() || second.PbKeyBase58() != first.PbKeyBase58() {
+		t.Fatalf("expected the identity to be reused, created %d DIDs: %q and %q", created, first.Did(), second.Did())
+	}
+	if _, err := reopened.Identity("verifier", "signing", "dsa", createDid); err == nil {
+		t.Fatal("expected an unknown key type to be rejected")
+	}
+	if _, err := reopened.Identity("verifier", "signing", KeyTypeECDSA, func(string) (string, error) {
+		return "", errors.New("registrar down")
+	}); err == nil {
+		t.Fatal("expected a failed DID registration to be reported")
+	}
+	if _, ok := reopened.Alias("verifier"); ok {
+		t.Fatal("expected no alias for a failed registration")
+	}
+}
@@ -2,8 +2,11 @@ package kms
 
 import (
 	didcore "byd50-ssi/pkg/did/core"
+	derrors "byd50-ssi/pkg/did/errors"
 	"byd50-ssi/pkg/keys"
+	"crypto"
 	"crypto/ecdsa"
+	"crypto/ed25519"
 	"crypto/rsa"
 	"errors"
 	"log"
@@ -36,8 +39,12 @@ type Decryptor interface {
 }
 
 const (
-	KeyTypeRSA   = "rsa"
-	KeyTypeECDSA = "ecdsa"
+	KeyTypeRSA       = "rsa"
+	KeyTypeECDSA     = "ecdsa"
+	KeyTypeSecp256k1 = "secp256k1"
+	KeyTypeEd25519   = "ed25519"
+	// KeyTypeX25519 keys only agree keys, e.g. to decrypt JWEs sent to the keyAgreement keys of a DID.
+	KeyTypeX25519 = "x25519"
 )
 
 // Deprecated: use PvKeyRSA or PvKeyECDSA for type-safe access.
@@ -92,6 +99,15 @@ func (p *KMS) PbKeyECDSA() (*ecdsa.PublicKey, error) {
 	return pbKey, nil
 }
 
+// Signer returns the private key as a crypto.Signer, e.g. for Data Integrity proofs.
+func (p *KMS) Signer() (crypto.Signer, error) {
+	signer, ok := p.privateKey.(crypto.Signer)
+	if !ok || signer == nil {
+		return nil, errors.New("private key is not a signer")
+	}
+	return signer, nil
+}
+
 func (p *KMS) PvKeyBase58() string {
 	return p.privateKeyBase58
 }
@@ -158,6 +174,18 @@ func (p *KMS) Decrypt(ciphertextBase64 string) string {
 
 var kms KMS
 
+// newKMS returns a KMS holding the given key pair, independent of the package KMS.
+func newKMS(pvKey, pbKey interface{}) KMS {
+	var k KMS
+	k.SetPvKey(pvKey)
+	k.SetPbKey(pbKey)
+	k.SetPvKeyPEM(ExportPrivateKeyAsPEM(pvKey))
+	k.SetPbKeyPEM(ExportPublicKeyAsPEM(pbKey))
+	k.SetPvKeyBase58(ExportPrivateKeyAsBase58(pvKey))
+	k.SetPbKeyBase58(ExportPublicKeyAsBase58(pbKey))
+	return k
+}
+
 // GenerateKeyPair Generate key pair
 func GenerateKeyPair(keyType string) (interface{}, interface{}) {
 	var privateKey, publicKey interface{}
@@ -166,58 +194,54 @@ func GenerateKeyPair(keyType string) (interface{}, interface{}) {
 		privateKey, publicKey = keys.GenerateKeyPair(2048)
 	case KeyTypeECDSA:
 		privateKey, publicKey, _ = keys.GenerateECDSAKeyPair()
+	case KeyTypeSecp256k1:
+		privateKey, publicKey, _ = keys.GenerateSecp256k1KeyPair()
+	case KeyTypeEd25519:
+		privateKey, publicKey, _ = keys.GenerateEd25519KeyPair()
+	case KeyTypeX25519:
+		privateKey, publicKey, _ = keys.GenerateX25519KeyPair()
 	default:
 		log.Fatal("unknown keyType")
 	}
 	return privateKey, publicKey
 }
 
-// InitKMS Generate and assign a key pair.
+// InitKMS generates a key pair of keyType and returns a KMS holding it. The KMS is also kept as the
+// package KMS returned by GetKMS.
+//
+// Deprecated: use a KeyManager, which signs with the key without handing out the private key.
 func InitKMS(keyType string) (KMS, error) {
-	switch keyType {
-	case KeyTypeRSA:
-		fallthrough
-	case KeyTypeECDSA:
-		pvKey, pbKey := GenerateKeyPair(keyType)
-		kms.SetPvKey(pvKey)
-		kms.SetPbKey(pbKey)
-		kms.SetPvKeyPEM(ExportPrivateKeyAsPEM(pvKey))
-		kms.SetPbKeyPEM(ExportPublicKeyAsPEM(pbKey))
-		kms.SetPvKeyBase58(ExportPrivateKeyAsBase58(pvKey))
-		kms.SetPbKeyBase58(ExportPublicKeyAsBase58(pbKey))
-	default:
-		return kms, errors.New("unknown keyType: " + keyType)
+	pvKey, err := generateKey(keyType)
+	if err != nil {
+		return KMS{}, err
+	}
+	_, pbKey, err := describeKey(pvKey)
+	if err != nil {
+		return KMS{}, err
 	}
+	kms = newKMS(pvKey, pbKey)
 	return kms, nil
 }
 
+// InitKMSwithKeyPair makes the given key pair the package KMS. The private key must be an RSA, ECDSA
+// (P-256 or secp256k1) or Ed25519 key and the public key must be its public key.
+//
+// Deprecated: use KeyManager.Import.
 func InitKMSwithKeyPair(vPvKey interface{}, vPbKey interface{}) error {
-	switch keyType := vPvKey.(type) {
-	case *rsa.PrivateKey:
-		log.Println(keyType.Validate(), "init KMS")
-		pvKey := vPvKey.(*rsa.PrivateKey)
-		pbKey := vPbKey.(*rsa.PublicKey)
-		kms.SetPvKey(pvKey)
-		kms.SetPbKey(pbKey)
-		kms.SetPvKeyPEM(ExportPrivateKeyAsPEM(pvKey))
-		kms.SetPbKeyPEM(ExportPublicKeyAsPEM(pbKey))
-		kms.SetPvKeyBase58(ExportPrivateKeyAsBase58(pvKey))
-		kms.SetPbKeyBase58(ExportPublicKeyAsBase58(pbKey))
-	case *ecdsa.PrivateKey:
-		pvKey := vPvKey.(*ecdsa.PrivateKey)
-		pbKey := vPbKey.(*ecdsa.PublicKey)
-		kms.SetPvKey(pvKey)
-		kms.SetPbKey(pbKey)
-		kms.SetPvKeyPEM(ExportPrivateKeyAsPEM(pvKey))
-		kms.SetPbKeyPEM(ExportPublicKeyAsPEM(pbKey))
-		kms.SetPvKeyBase58(ExportPrivateKeyAsBase58(pvKey))
-		kms.SetPbKeyBase58(ExportPublicKeyAsBase58(pbKey))
-	default:
-		log.Println(keyType, "unknown keyType:%v", keyType)
+	_, pbKey, err := describeKey(vPvKey)
+	if err != nil {
+		return err
 	}
+	if equal, ok := pbKey.(interface{ Equal(crypto.PublicKey) bool }); !ok || !equal.Equal(vPbKey) {
+		return derrors.New(derrors.CodeInvalidKey, "public key does not belong to the private key")
+	}
+	kms = newKMS(vPvKey, vPbKey)
 	return nil
 }
 
+// GetKMS returns the package KMS set by InitKMS or InitKMSwithKeyPair.
+//
+// Deprecated: use a KeyManager.
 func GetKMS() KMS {
 	return kms
 }
@@ -234,6 +258,13 @@ func ExportPrivateKeyAsPEM(privateKey interface{}) string {
 			return ""
 		}
 		return pemStr
+	case ed25519.PrivateKey:
+		pemStr, err := keys.ExportEd25519PrivateKeyAsPEM(v)
+		if err != nil {
+			log.Printf("%v", err.Error())
+			return ""
+		}
+		return pemStr
 	default:
 		log.Printf("unknown key type")
 		return ""
@@ -257,6 +288,13 @@ func ExportPublicKeyAsPEM(publicKey interface{}) string {
 			return ""
 		}
 		return pemStr
+	case ed25519.PublicKey:
+		pemStr, err := keys.ExportEd25519PublicKeyAsPEM(v)
+		if err != nil {
+			log.Printf("%v", err.Error())
+			return ""
+		}
+		return pemStr
 	default:
 		log.Printf("unknown key type")
 		return ""
@@ -265,26 +303,10 @@ func ExportPublicKeyAsPEM(publicKey interface{}) string {
 
 // ExportPrivateKeyAsBase58 : Exports a PrivateKey as Base58.
 func ExportPrivateKeyAsBase58(privateKey interface{}) string {
-	switch v := privateKey.(type) {
-	case *rsa.PrivateKey:
-		return keys.ExportRSAPrivateKeyAsBase58(v)
-	case *ecdsa.PrivateKey:
-		return keys.ExportECDSAPrivateKeyAsBase58(v)
-	default:
-		log.Printf("unknown key type")
-		return ""
-	}
+	return keys.ExportPrivateKeyAsBase58(privateKey)
 }
 
 // ExportPublicKeyAsBase58 : Exports a PublicKey as Base58.
 func ExportPublicKeyAsBase58(publicKey interface{}) string {
-	switch v := publicKey.(type) {
-	case *rsa.PublicKey:
-		return keys.ExportRSAPublicKeyAsBase58(v)
-	case *ecdsa.PublicKey:
-		return keys.ExportECDSAPublicKeyAsBase58(v)
-	default:
-		log.Printf("unknown key type")
-		return ""
-	}
+	return keys.ExportPublicKeyAsBase58(publicKey)
 }
@@ -0,0 +1,274 @@
+package kms
+
+import (
+	"byd50-ssi/pkg/did/core/jwe"
+	derrors "byd50-ssi/pkg/did/errors"
+	"byd50-ssi/pkg/keys"
+	"crypto"
+	"crypto/rand"
+	"io"
+	"sort"
+	"sync"
+
+	uuid "github.com/satori/go.uuid"
+)
+
+// KeyManager holds private keys and refers to them by opaque key IDs, so callers sign, agree keys and decrypt
+// without ever seeing the private key. Implementations may keep the keys in memory, in a file or in
+// an HSM.
+type KeyManager interface {
+	// Create generates a key pair of keyType and returns its key ID.
+	Create(keyType string) (string, error)
+	// Import takes over an existing private key and returns its key ID.
+	Import(pvKey crypto.PrivateKey) (string, error)
+	// PublicKey returns the public key of keyID.
+	PublicKey(keyID string) (crypto.PublicKey, error)
+	// Sign signs digest with keyID as crypto.Signer does. Ed25519 keys sign the message itself and
+	// X25519 keys cannot sign.
+	Sign(keyID string, digest []byte, opts crypto.SignerOpts) ([]byte, error)
+	// Decrypt decrypts ciphertext with keyID as crypto.Decrypter does. Only RSA keys can decrypt;
+	// EC and X25519 keys decrypt JWEs with DecryptJWE.
+	Decrypt(keyID string, ciphertext []byte, opts crypto.DecrypterOpts) ([]byte, error)
+	// SharedSecret computes the ECDH shared secret of keyID and a peer public key as keys.SharedSecret does.
+	// Only EC and X25519 keys agree keys.
+	SharedSecret(keyID string, peer crypto.PublicKey) ([]byte, error)
+	// List describes all keys, ordered by key ID.
+	List() ([]KeyHandle, error)
+	// Delete removes keyID.
+	Delete(keyID string) error
+}
+
+// KeyHandle describes a key of a KeyManager without its private part.
+type KeyHandle struct {
+	ID              string `json:"id"`
+	Type            string `json:"type"`
+	PublicKeyBase58 string `json:"publicKeyBase58"`
+}
+
+// KeySigner returns a crypto.Signer that signs with keyID of m. It can be passed wherever a private
+// key is expected for signing, such as byd50_jwt.Sign or the core CreateVc and CreateVp functions.
+func KeySigner(m KeyManager, keyID string) (crypto.Signer, error) {
+	pbKey, err := m.PublicKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return &handleSigner{manager: m, keyID: keyID, pbKey: pbKey}, nil
+}
+
+// PublicKeyBase58 returns the public key of keyID of m as base58, as it is published in DID documents.
+func PublicKeyBase58(m KeyManager, keyID string) (string, error) {
+	pbKey, err := m.PublicKey(keyID)
+	if err != nil {
+		return "", err
+	}
+	pbKeyBase58 := keys.ExportPublicKeyAsBase58(pbKey)
+	if pbKeyBase58 == "" {
+		return "", derrors.New(derrors.CodeInvalidKey, "failed to encode public key")
+	}
+	return pbKeyBase58, nil
+}
+
+// KeyAgreer returns a jwe.KeyAgreer that agrees keys with keyID of m.
+func KeyAgreer(m KeyManager, keyID string) (jwe.KeyAgreer, error) {
+	pbKey, err := m.PublicKey(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return &handleAgreer{manager: m, keyID: keyID, pbKey: pbKey}, nil
+}
+
+// DecryptJWE decrypts a JWE (see jwe.Parse) encrypted to the key agreement key keyID of m.
+func DecryptJWE(m KeyManager, keyID string, data []byte) ([]byte, error) {
+	encrypted, err := jwe.Parse(data)
+	if err != nil {
+		return nil, err
+	}
+	agreer, err := KeyAgreer(m, keyID)
+	if err != nil {
+		return nil, err
+	}
+	return encrypted.Decrypt(agreer)
+}
+
+type handleSigner struct {
+	manager KeyManager
+	keyID   string
+	pbKey   crypto.PublicKey
+}
+
+func (s *handleSigner) Public() crypto.PublicKey {
+	return s.pbKey
+}
+
+func (s *handleSigner) Sign(_ io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	return s.manager.Sign(s.keyID, digest, opts)
+}
+
+type handleAgreer struct {
+	manager KeyManager
+	keyID   string
+	pbKey   crypto.PublicKey
+}
+
+func (a *handleAgreer) Public() crypto.PublicKey {
+	return a.pbKey
+}
+
+func (a *handleAgreer) SharedSecret(peer crypto.PublicKey) ([]byte, error) {
+	return a.manager.SharedSecret(a.keyID, peer)
+}
+
+// MemoryKeyManager is a KeyManager keeping the keys in memory. The keys are lost when the process exits.
+type MemoryKeyManager struct {
+	mu   sync.RWMutex
+	keys map[string]memoryKey
+}
+
+type memoryKey struct {
+	keyType string
+	pvKey   crypto.PrivateKey
+	pbKey   crypto.PublicKey
+}
+
+// NewMemoryKeyManager returns an empty MemoryKeyManager.
+func NewMemoryKeyManager() *MemoryKeyManager {
+	return &MemoryKeyManager{keys: map[string]memoryKey{}}
+}
+
+func (m *MemoryKeyManager) Create(keyType string) (string, error) {
+	pvKey, err := generateKey(keyType)
+	if err != nil {
+		return "", err
+	}
+	return m.Import(pvKey)
+}
+
+func (m *MemoryKeyManager) Import(pvKey crypto.PrivateKey) (string, error) {
+	keyType, pbKey, err := describeKey(pvKey)
+	if err != nil {
+		return "", err
+	}
+	keyID := uuid.NewV4().String()
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.keys[keyID] = memoryKey{keyType: keyType, pvKey: pvKey, pbKey: pbKey}
+	return keyID, nil
+}
+
+func (m *MemoryKeyManager) PublicKey(keyID string) (crypto.PublicKey, error) {
+	key, err := m.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	return key.pbKey, nil
+}
+
+func (m *MemoryKeyManager) Sign(keyID string, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	key, err := m.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	signer, ok := key.pvKey.(crypto.Signer)
+	if !ok {
+		return nil, derrors.New(derrors.CodeInvalidKey, key.keyType+" keys cannot sign")
+	}
+	signature, err := signer.Sign(rand.Reader, digest, opts)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "failed to sign", err)
+	}
+	return signature, nil
+}
+
+func (m *MemoryKeyManager) Decrypt(keyID string, ciphertext []byte, opts crypto.DecrypterOpts) ([]byte, error) {
+	key, err := m.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	decrypter, ok := key.pvKey.(crypto.Decrypter)
+	if !ok {
+		return nil, derrors.New(derrors.CodeInvalidKey, key.keyType+" keys cannot decrypt")
+	}
+	plaintext, err := decrypter.Decrypt(rand.Reader, ciphertext, opts)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "failed to decrypt", err)
+	}
+	return plaintext, nil
+}
+
+func (m *MemoryKeyManager) SharedSecret(keyID string, peer crypto.PublicKey) ([]byte, error) {
+	key, err := m.key(keyID)
+	if err != nil {
+		return nil, err
+	}
+	switch key.keyType {
+	case KeyTypeECDSA, KeyTypeSecp256k1, KeyTypeX25519:
+	default:
+		return nil, derrors.New(derrors.CodeInvalidKey, key.keyType+" keys cannot agree keys")
+	}
+	secret, err := keys.SharedSecret(key.pvKey, peer)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "key agreement failed", err)
+	}
+	return secret, nil
+}
+
+func (m *MemoryKeyManager) List() ([]KeyHandle, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	handles := make([]KeyHandle, 0, len(m.keys))
+	for keyID, key := range m.keys {
+		handles = append(handles, KeyHandle{
+			ID:              keyID,
+			Type:            key.keyType,
+			PublicKeyBase58: keys.ExportPublicKeyAsBase58(key.pbKey),
+		})
+	}
+	sort.Slice(handles, func(i, j int) bool { return handles[i].ID < handles[j].ID })
+	return handles, nil
+}
+
+func (m *MemoryKeyManager) Delete(keyID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if _, ok := m.keys[keyID]; !ok {
+		return derrors.New(derrors.CodeNotFound, "key not found: "+keyID)
+	}
+	delete(m.keys, keyID)
+	return nil
+}
+
+func (m *MemoryKeyManager) key(keyID string) (memoryKey, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	key, ok := m.keys[keyID]
+	if !ok {
+		return memoryKey{}, derrors.New(derrors.CodeNotFound, "key not found: "+keyID)
+	}
+	return key, nil
+}
+
+// generateKey generates a private key of keyType.
+func generateKey(keyType string) (crypto.PrivateKey, error) {
+	var (
+		pvKey crypto.PrivateKey
+		err   error
+	)
+	switch keyType {
+	case KeyTypeRSA:
+		pvKey, _ = keys.GenerateKeyPair(2048)
+	case KeyTypeECDSA:
+		pvKey, _, err = keys.GenerateECDSAKeyPair()
+	case KeyTypeSecp256k1:
+		pvKey, _, err = keys.GenerateSecp256k1KeyPair()
+	case KeyTypeEd25519:
+		pvKey, _, err = keys.GenerateEd25519KeyPair()
+	case KeyTypeX25519:
+		pvKey, _, err = keys.GenerateX25519KeyPair()
+	default:
+		return nil, derrors.New(derrors.CodeInvalidInput, "unknown keyType: "+keyType)
+	}
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInternal, "failed to generate "+keyType+" key", err)
+	}
+	return pvKey, nil
+}
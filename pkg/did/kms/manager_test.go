@@ -0,0 +1,176 @@
+package kms
+
+import (
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	"byd50-ssi/pkg/did/core/jwe"
+	derrors "byd50-ssi/pkg/did/errors"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha512"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+)
+
+func errorCode(err error) derrors.Code {
+	var derr *derrors.Error
+	if errors.As(err, &derr) {
+		return derr.Code()
+	}
+	return ""
+}
+
+func TestMemoryKeyManagerSignsByKeyID(t *testing.T) {
+	m := NewMemoryKeyManager()
+	for _, keyType := range []string{KeyTypeECDSA, KeyTypeRSA, KeyTypeSecp256k1, KeyTypeEd25519} {
+		keyID, err := m.Create(keyType)
+		if err != nil {
+			t.Fatalf("%s: %v", keyType, err)
+		}
+		signer, err := KeySigner(m, keyID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, ok := signer.(interface{ Equal(crypto.PrivateKey) bool }); ok {
+			t.Fatalf("%s: signer exposes the private key", keyType)
+		}
+		pbKeyBase58, err := PublicKeyBase58(m, keyID)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed, err := byd50_jwt.Sign("did:byd50:issuer", "", jwt.MapClaims{"iss": "did:byd50:issuer"}, signer)
+		if err != nil {
+			t.Fatalf("%s: %v", keyType, err)
+		}
+		if _, err := byd50_jwt.ParseSigned(signed, func(string, string) string { return pbKeyBase58 }); err != nil {
+			t.Fatalf("%s: signature does not verify: %v", keyType, err)
+		}
+	}
+
+	handles, err := m.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(handles) != 4 {
+		t.Fatalf("expected 4 keys, got %+v", handles)
+	}
+	for _, handle := range handles {
+		if handle.PublicKeyBase58 == "" {
+			t.Fatalf("%s: missing public key", handle.Type)
+		}
+	}
+	if _, err := m.Create("dsa"); errorCode(err) != derrors.CodeInvalidInput {
+		t.Fatalf("expected an unknown key type to be rejected, got %v", err)
+	}
+}
+
+func TestMemoryKeyManagerImportDecryptDelete(t *testing.T) {
+	m := NewMemoryKeyManager()
+	pvKey, pbKey := GenerateKeyPair(KeyTypeRSA)
+	keyID, err := m.Import(pvKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, _ := m.PublicKey(keyID); !pbKey.(*rsa.PublicKey).Equal(got) {
+		t.Fatal("expected the public key of the imported key")
+	}
+	ciphertext, err := rsa.EncryptOAEP(sha512.New(), rand.Reader, pbKey.(*rsa.PublicKey), []byte("challenge"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plaintext, err := m.Decrypt(keyID, ciphertext, &rsa.OAEPOptions{Hash: crypto.SHA512})
+	if err != nil || string(plaintext) != "challenge" {
+		t.Fatalf("unexpected plaintext %q: %v", plaintext, err)
+	}
+
+	edKeyID, _ := m.Create(KeyTypeEd25519)
+	if _, err := m.Decrypt(edKeyID, ciphertext, nil); errorCode(err) != derrors.CodeInvalidKey {
+		t.Fatalf("expected an Ed25519 key not to decrypt, got %v", err)
+	}
+	if _, err := m.Import("not a key"); errorCode(err) != derrors.CodeInvalidKey {
+		t.Fatalf("expected an unsupported key to be rejected, got %v", err)
+	}
+
+	if err := m.Delete(keyID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Sign(keyID, make([]byte, 32), crypto.SHA256); errorCode(err) != derrors.CodeNotFound {
+		t.Fatalf("expected a deleted key to be gone, got %v", err)
+	}
+	if err := m.Delete(keyID); errorCode(err) != derrors.CodeNotFound {
+		t.Fatalf("expected deleting an unknown key to fail, got %v", err)
+	}
+}
+
+func TestMemoryKeyManagerDecryptsJWE(t *testing.T) {
+	m := NewMemoryKeyManager()
+	var recipients []jwe.Recipient
+	var keyIDs []string
+	for _, keyType := range []string{KeyTypeX25519, KeyTypeECDSA, KeyTypeSecp256k1} {
+		keyID, err := m.Create(keyType)
+		if err != nil {
+			t.Fatalf("%s: %v", keyType, err)
+		}
+		pbKey, _ := m.PublicKey(keyID)
+		recipients = append(recipients, jwe.Recipient{KeyID: "did:byd50:holder#" + keyType, PublicKey: pbKey})
+		keyIDs = append(keyIDs, keyID)
+	}
+	encrypted, err := jwe.Encrypt([]byte("credential"), "JWT", recipients...)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(encrypted)
+	for _, keyID := range keyIDs {
+		plaintext, err := DecryptJWE(m, keyID, data)
+		if err != nil || string(plaintext) != "credential" {
+			t.Fatalf("unexpected plaintext %q: %v", plaintext, err)
+		}
+	}
+
+	if _, err := m.Sign(keyIDs[0], make([]byte, 32), crypto.SHA256); errorCode(err) != derrors.CodeInvalidKey {
+		t.Fatalf("expected an X25519 key not to sign, got %v", err)
+	}
+	edKeyID, _ := m.Create(KeyTypeEd25519)
+	if _, err := DecryptJWE(m, edKeyID, data); errorCode(err) != derrors.CodeInvalidKey {
+		t.Fatalf("expected an Ed25519 key not to decrypt, got %v", err)
+	}
+	if _, err := m.SharedSecret(edKeyID, recipients[0].PublicKey); errorCode(err) != derrors.CodeInvalidKey {
+		t.Fatalf("expected an Ed25519 key not to agree keys, got %v", err)
+	}
+	otherKeyID, _ := m.Create(KeyTypeX25519)
+	if _, err := DecryptJWE(m, otherKeyID, data); errorCode(err) != derrors.CodeInvalidKey {
+		t.Fatalf("expected a key that is not a recipient to be rejected, got %v", err)
+	}
+}
+
+func TestInitKMSwithKeyPair(t *testing.T) {
+	pvKey, pbKey := GenerateKeyPair(KeyTypeEd25519)
+	if err := InitKMSwithKeyPair(pvKey, pbKey); err != nil {
+		t.Fatal(err)
+	}
+	if got := GetKMS(); got.PbKeyBase58() != ExportPublicKeyAsBase58(pbKey) {
+		t.Fatal("expected the key pair to become the package KMS")
+	}
+	_, otherPbKey := GenerateKeyPair(KeyTypeEd25519)
+	if err := InitKMSwithKeyPair(pvKey, otherPbKey); err == nil {
+		t.Fatal("expected a mismatched public key to be rejected")
+	}
+	if err := InitKMSwithKeyPair("not a key", pbKey); err == nil {
+		t.Fatal("expected an unknown key type to be rejected")
+	}
+
+	first, err := InitKMS(KeyTypeECDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, _ := InitKMS(KeyTypeECDSA)
+	if first.PbKeyBase58() == second.PbKeyBase58() {
+		t.Fatal("expected independent KMS values")
+	}
+	if _, err := InitKMS("dsa"); err == nil {
+		t.Fatal("expected an unknown key type to be rejected")
+	}
+}
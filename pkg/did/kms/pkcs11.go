@@ -0,0 +1,318 @@
+//go:build pkcs11
+// +build pkcs11
+
+package kms
+
+import (
+	derrors "byd50-ssi/pkg/did/errors"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"encoding/asn1"
+	"math/big"
+	"sort"
+	"sync"
+
+	"github.com/miekg/pkcs11"
+	uuid "github.com/satori/go.uuid"
+)
+
+// oidNamedCurveP256 is the CKA_EC_PARAMS of P-256 keys.
+var oidNamedCurveP256 = asn1.ObjectIdentifier{1, 2, 840, 10045, 3, 1, 7}
+
+// PKCS11KeyManager is a KeyManager keeping ECDSA P-256 keys in a PKCS#11 token such as an HSM or SoftHSM2.
+// Private keys are generated in the token as sensitive, non-extractable objects and only their public keys
+// and the ECDH shared secrets of key agreements leave it. The key ID is stored as CKA_ID and CKA_LABEL of both
+// key objects.
+type PKCS11KeyManager struct {
+	mu      sync.Mutex
+	ctx     *pkcs11.Ctx
+	session pkcs11.SessionHandle
+}
+
+// NewPKCS11KeyManager loads the PKCS#11 module at modulePath and logs in as user with pin to the token
+// labelled tokenLabel. Close the manager to log out and unload the module. The module is initialized once per
+// process, so use a single manager per module.
+func NewPKCS11KeyManager(modulePath, tokenLabel, pin string) (*PKCS11KeyManager, error) {
+	ctx := pkcs11.New(modulePath)
+	if ctx == nil {
+		return nil, derrors.New(derrors.CodeInvalidInput, "failed to load pkcs11 module: "+modulePath)
+	}
+	if err := ctx.Initialize(); err != nil {
+		ctx.Destroy()
+		return nil, derrors.Wrap(derrors.CodeUpstream, "failed to initialize pkcs11 module", err)
+	}
+	session, err := openTokenSession(ctx, tokenLabel, pin)
+	if err != nil {
+		ctx.Finalize()
+		ctx.Destroy()
+		return nil, err
+	}
+	return &PKCS11KeyManager{ctx: ctx, session: session}, nil
+}
+
+func openTokenSession(ctx *pkcs11.Ctx, tokenLabel, pin string) (pkcs11.SessionHandle, error) {
+	slots, err := ctx.GetSlotList(true)
+	if err != nil {
+		return 0, derrors.Wrap(derrors.CodeUpstream, "failed to list pkcs11 slots", err)
+	}
+	for _, slot := range slots {
+		info, err := ctx.GetTokenInfo(slot)
+		if err != nil || info.Label != tokenLabel {
+			continue
+		}
+		session, err := ctx.OpenSession(slot, pkcs11.CKF_SERIAL_SESSION|pkcs11.CKF_RW_SESSION)
+		if err != nil {
+			return 0, derrors.Wrap(derrors.CodeUpstream, "failed to open pkcs11 session", err)
+		}
+		if err := ctx.Login(session, pkcs11.CKU_USER, pin); err != nil {
+			ctx.CloseSession(session)
+			return 0, derrors.Wrap(derrors.CodeInvalidKey, "failed to log in to pkcs11 token "+tokenLabel, err)
+		}
+		return session, nil
+	}
+	return 0, derrors.New(derrors.CodeNotFound, "pkcs11 token not found: "+tokenLabel)
+}
+
+// Close logs out of the token and unloads the module.
+func (m *PKCS11KeyManager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.ctx.Logout(m.session)
+	m.ctx.CloseSession(m.session)
+	err := m.ctx.Finalize()
+	m.ctx.Destroy()
+	return err
+}
+
+// Create generates an ECDSA P-256 key pair in the token, which signs and agrees keys. Other key types are
+// not supported.
+func (m *PKCS11KeyManager) Create(keyType string) (string, error) {
+	if keyType != KeyTypeECDSA {
+		return "", derrors.New(derrors.CodeInvalidInput, "unsupported pkcs11 keyType: "+keyType)
+	}
+	ecParams, err := asn1.Marshal(oidNamedCurveP256)
+	if err != nil {
+		return "", derrors.Wrap(derrors.CodeInternal, "failed to encode curve", err)
+	}
+	keyID := uuid.NewV4().String()
+	pbTemplate := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_VERIFY, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, ecParams),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, []byte(keyID)),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, keyID),
+	}
+	pvTemplate := []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_PRIVATE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_SIGN, true),
+		pkcs11.NewAttribute(pkcs11.CKA_DERIVE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, []byte(keyID)),
+		pkcs11.NewAttribute(pkcs11.CKA_LABEL, keyID),
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	mechanism := []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_EC_KEY_PAIR_GEN, nil)}
+	if _, _, err := m.ctx.GenerateKeyPair(m.session, mechanism, pbTemplate, pvTemplate); err != nil {
+		return "", derrors.Wrap(derrors.CodeUpstream, "failed to generate pkcs11 key", err)
+	}
+	return keyID, nil
+}
+
+// Import is not supported: keys of a PKCS11KeyManager are generated in the token.
+func (m *PKCS11KeyManager) Import(crypto.PrivateKey) (string, error) {
+	return "", derrors.New(derrors.CodeInvalidInput, "pkcs11 keys must be created in the token")
+}
+
+func (m *PKCS11KeyManager) PublicKey(keyID string) (crypto.PublicKey, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return m.publicKey(keyID)
+}
+
+// Sign signs digest with the mechanism CKM_ECDSA and returns the ASN.1 signature crypto.Signer returns.
+func (m *PKCS11KeyManager) Sign(keyID string, digest []byte, _ crypto.SignerOpts) ([]byte, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pvKey, err := m.object(pkcs11.CKO_PRIVATE_KEY, keyID)
+	if err != nil {
+		return nil, err
+	}
+	if err := m.ctx.SignInit(m.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)}, pvKey); err != nil {
+		return nil, derrors.Wrap(derrors.CodeUpstream, "failed to sign", err)
+	}
+	signature, err := m.ctx.Sign(m.session, digest)
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeUpstream, "failed to sign", err)
+	}
+	if len(signature)%2 != 0 {
+		return nil, derrors.New(derrors.CodeUpstream, "unexpected pkcs11 signature length")
+	}
+	half := len(signature) / 2
+	der, err := asn1.Marshal(struct{ R, S *big.Int }{
+		R: new(big.Int).SetBytes(signature[:half]),
+		S: new(big.Int).SetBytes(signature[half:]),
+	})
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInternal, "failed to encode signature", err)
+	}
+	return der, nil
+}
+
+// Decrypt is not supported: ECDSA keys cannot decrypt. Use DecryptJWE, which agrees keys with SharedSecret.
+func (m *PKCS11KeyManager) Decrypt(keyID string, _ []byte, _ crypto.DecrypterOpts) ([]byte, error) {
+	if _, err := m.PublicKey(keyID); err != nil {
+		return nil, err
+	}
+	return nil, derrors.New(derrors.CodeInvalidKey, KeyTypeECDSA+" keys cannot decrypt")
+}
+
+// SharedSecret derives the ECDH shared secret with the mechanism CKM_ECDH1_DERIVE into a session object,
+// reads it and destroys the object. Keys created before key agreement was supported lack CKA_DERIVE and fail.
+func (m *PKCS11KeyManager) SharedSecret(keyID string, peer crypto.PublicKey) ([]byte, error) {
+	pbKey, ok := peer.(*ecdsa.PublicKey)
+	if !ok || pbKey.Curve != elliptic.P256() {
+		return nil, derrors.New(derrors.CodeInvalidInput, "peer key is not a P-256 key")
+	}
+	point, err := pbKey.ECDH()
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "invalid peer key", err)
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	pvKey, err := m.object(pkcs11.CKO_PRIVATE_KEY, keyID)
+	if err != nil {
+		return nil, err
+	}
+	params := pkcs11.NewECDH1DeriveParams(pkcs11.CKD_NULL, nil, point.Bytes())
+	secret, err := m.ctx.DeriveKey(m.session, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDH1_DERIVE, params)}, pvKey, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_SECRET_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_GENERIC_SECRET),
+		pkcs11.NewAttribute(pkcs11.CKA_TOKEN, false),
+		pkcs11.NewAttribute(pkcs11.CKA_SENSITIVE, false),
+		pkcs11.NewAttribute(pkcs11.CKA_EXTRACTABLE, true),
+		pkcs11.NewAttribute(pkcs11.CKA_VALUE_LEN, 32),
+	})
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeUpstream, "failed to derive pkcs11 shared secret", err)
+	}
+	defer m.ctx.DestroyObject(m.session, secret)
+	attributes, err := m.ctx.GetAttributeValue(m.session, secret, []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_VALUE, nil)})
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeUpstream, "failed to read pkcs11 shared secret", err)
+	}
+	return attributes[0].Value, nil
+}
+
+func (m *PKCS11KeyManager) List() ([]KeyHandle, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	objects, err := m.findObjects([]*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, pkcs11.CKO_PUBLIC_KEY),
+		pkcs11.NewAttribute(pkcs11.CKA_KEY_TYPE, pkcs11.CKK_EC),
+	})
+	if err != nil {
+		return nil, err
+	}
+	handles := make([]KeyHandle, 0, len(objects))
+	for _, object := range objects {
+		attributes, err := m.ctx.GetAttributeValue(m.session, object, []*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_ID, nil)})
+		if err != nil {
+			return nil, derrors.Wrap(derrors.CodeUpstream, "failed to read pkcs11 key id", err)
+		}
+		keyID := string(attributes[0].Value)
+		pbKey, err := m.publicKey(keyID)
+		if err != nil {
+			// keys of other applications, e.g. on other curves
+			continue
+		}
+		handles = append(handles, KeyHandle{ID: keyID, Type: KeyTypeECDSA, PublicKeyBase58: ExportPublicKeyAsBase58(pbKey)})
+	}
+	sort.Slice(handles, func(i, j int) bool { return handles[i].ID < handles[j].ID })
+	return handles, nil
+}
+
+func (m *PKCS11KeyManager) Delete(keyID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	objects, err := m.findObjects([]*pkcs11.Attribute{pkcs11.NewAttribute(pkcs11.CKA_ID, []byte(keyID))})
+	if err != nil {
+		return err
+	}
+	if len(objects) == 0 {
+		return derrors.New(derrors.CodeNotFound, "key not found: "+keyID)
+	}
+	for _, object := range objects {
+		if err := m.ctx.DestroyObject(m.session, object); err != nil {
+			return derrors.Wrap(derrors.CodeUpstream, "failed to delete pkcs11 key", err)
+		}
+	}
+	return nil
+}
+
+// publicKey reads the P-256 public key keyID from the token. The caller holds m.mu.
+func (m *PKCS11KeyManager) publicKey(keyID string) (crypto.PublicKey, error) {
+	object, err := m.object(pkcs11.CKO_PUBLIC_KEY, keyID)
+	if err != nil {
+		return nil, err
+	}
+	attributes, err := m.ctx.GetAttributeValue(m.session, object, []*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_EC_PARAMS, nil),
+		pkcs11.NewAttribute(pkcs11.CKA_EC_POINT, nil),
+	})
+	if err != nil {
+		return nil, derrors.Wrap(derrors.CodeUpstream, "failed to read pkcs11 public key", err)
+	}
+	var curve asn1.ObjectIdentifier
+	if _, err := asn1.Unmarshal(attributes[0].Value, &curve); err != nil || !curve.Equal(oidNamedCurveP256) {
+		return nil, derrors.New(derrors.CodeInvalidKey, "pkcs11 key is not a P-256 key: "+keyID)
+	}
+	// CKA_EC_POINT is a DER octet string; some tokens return the bare point
+	point := attributes[1].Value
+	var octets []byte
+	if _, err := asn1.Unmarshal(point, &octets); err == nil {
+		point = octets
+	}
+	x, y := elliptic.Unmarshal(elliptic.P256(), point)
+	if x == nil {
+		return nil, derrors.New(derrors.CodeInvalidKey, "invalid pkcs11 public key: "+keyID)
+	}
+	return &ecdsa.PublicKey{Curve: elliptic.P256(), X: x, Y: y}, nil
+}
+
+// object finds the key object of class with CKA_ID keyID. The caller holds m.mu.
+func (m *PKCS11KeyManager) object(class uint, keyID string) (pkcs11.ObjectHandle, error) {
+	objects, err := m.findObjects([]*pkcs11.Attribute{
+		pkcs11.NewAttribute(pkcs11.CKA_CLASS, class),
+		pkcs11.NewAttribute(pkcs11.CKA_ID, []byte(keyID)),
+	})
+	if err != nil {
+		return 0, err
+	}
+	if len(objects) == 0 {
+		return 0, derrors.New(derrors.CodeNotFound, "key not found: "+keyID)
+	}
+	return objects[0], nil
+}
+
+// findObjects returns all objects matching template. The caller holds m.mu.
+func (m *PKCS11KeyManager) findObjects(template []*pkcs11.Attribute) ([]pkcs11.ObjectHandle, error) {
+	if err := m.ctx.FindObjectsInit(m.session, template); err != nil {
+		return nil, derrors.Wrap(derrors.CodeUpstream, "failed to search pkcs11 objects", err)
+	}
+	defer m.ctx.FindObjectsFinal(m.session)
+	var objects []pkcs11.ObjectHandle
+	for {
+		batch, _, err := m.ctx.FindObjects(m.session, 16)
+		if err != nil {
+			return nil, derrors.Wrap(derrors.CodeUpstream, "failed to search pkcs11 objects", err)
+		}
+		if len(batch) == 0 {
+			return objects, nil
+		}
+		objects = append(objects, batch...)
+	}
+}
@@ -0,0 +1,173 @@
+//go:build pkcs11
+// +build pkcs11
+
+package kms
+
+import (
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	"byd50-ssi/pkg/did/core/dids"
+	"byd50-ssi/pkg/did/core/jwe"
+	derrors "byd50-ssi/pkg/did/errors"
+	"crypto"
+	"encoding/json"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+
+	"github.com/golang-jwt/jwt"
+)
+
+const (
+	softHSMTokenLabel = "byd50-test"
+	softHSMPin        = "5678"
+)
+
+// softHSMModule returns the SoftHSM2 module, from SOFTHSM2_MODULE or its usual install locations.
+func softHSMModule() string {
+	if module := os.Getenv("SOFTHSM2_MODULE"); module != "" {
+		return module
+	}
+	for _, module := range []string{
+		"/usr/lib/softhsm/libsofthsm2.so",
+		"/usr/lib/x86_64-linux-gnu/softhsm/libsofthsm2.so",
+		"/usr/local/lib/softhsm/libsofthsm2.so",
+	} {
+		if _, err := os.Stat(module); err == nil {
+			return module
+		}
+	}
+	return ""
+}
+
+// initSoftHSMToken initializes a fresh SoftHSM2 token in a temporary directory and returns its module.
+func initSoftHSMToken(t *testing.T) string {
+	t.Helper()
+	module := softHSMModule()
+	util, err := exec.LookPath("softhsm2-util")
+	if module == "" || err != nil {
+		t.Skip("SoftHSM2 is not installed (apt install softhsm2)")
+	}
+	dir := t.TempDir()
+	tokenDir := filepath.Join(dir, "tokens")
+	if err := os.Mkdir(tokenDir, 0o700); err != nil {
+		t.Fatal(err)
+	}
+	conf := filepath.Join(dir, "softhsm2.conf")
+	if err := os.WriteFile(conf, []byte("directories.tokendir = "+tokenDir+"\nobjectstore.backend = file\nlog.level = ERROR\n"), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	t.Setenv("SOFTHSM2_CONF", conf)
+	out, err := exec.Command(util, "--init-token", "--free", "--label", softHSMTokenLabel, "--so-pin", "1234", "--pin", softHSMPin).CombinedOutput()
+	if err != nil {
+		t.Fatalf("softhsm2-util: %v: %s", err, out)
+	}
+	return module
+}
+
+func TestPKCS11KeyManagerSignsJWT(t *testing.T) {
+	module := initSoftHSMToken(t)
+	m, err := NewPKCS11KeyManager(module, softHSMTokenLabel, softHSMPin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer m.Close()
+
+	keyID, err := m.Create(KeyTypeECDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	signer, err := KeySigner(m, keyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pbKeyBase58, err := PublicKeyBase58(m, keyID)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vm, err := dids.NewVerificationMethod("did:byd50:issuer#key-1", "did:byd50:issuer", "", pbKeyBase58)
+	if err != nil || vm.PublicKeyJwk == nil {
+		t.Fatalf("expected the public key to be published as a JWK: %+v %v", vm, err)
+	}
+
+	signed, err := byd50_jwt.Sign("did:byd50:issuer#key-1", "", jwt.MapClaims{"iss": "did:byd50:issuer"}, signer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := byd50_jwt.ParseSigned(signed, func(string, string) string { return pbKeyBase58 }); err != nil {
+		t.Fatalf("signature does not verify: %v", err)
+	}
+	token, _, err := new(jwt.Parser).ParseUnverified(signed, jwt.MapClaims{})
+	if err != nil || token.Header["alg"] != "ES256" {
+		t.Fatalf("expected ES256, got %v", token.Header["alg"])
+	}
+
+	if _, err := m.Create(KeyTypeSecp256k1); errorCode(err) != derrors.CodeInvalidInput {
+		t.Fatalf("expected secp256k1 to be rejected, got %v", err)
+	}
+	if _, err := m.Import("key"); errorCode(err) != derrors.CodeInvalidInput {
+		t.Fatalf("expected import to be rejected, got %v", err)
+	}
+	if _, err := m.Decrypt(keyID, []byte("ciphertext"), nil); errorCode(err) != derrors.CodeInvalidKey {
+		t.Fatalf("expected an ECDSA key not to decrypt, got %v", err)
+	}
+
+	// The token key decrypts JWEs by ECDH key agreement.
+	pbKey, _ := m.PublicKey(keyID)
+	encrypted, err := jwe.Encrypt([]byte(signed), "JWT", jwe.Recipient{KeyID: "did:byd50:issuer#key-1", PublicKey: pbKey})
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, _ := json.Marshal(encrypted)
+	plaintext, err := DecryptJWE(m, keyID, data)
+	if err != nil || string(plaintext) != signed {
+		t.Fatalf("unexpected plaintext %q: %v", plaintext, err)
+	}
+}
+
+func TestPKCS11KeyManagerKeepsKeysInToken(t *testing.T) {
+	module := initSoftHSMToken(t)
+	m, err := NewPKCS11KeyManager(module, softHSMTokenLabel, softHSMPin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	keyID, err := m.Create(KeyTypeECDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pbKeyBase58, _ := PublicKeyBase58(m, keyID)
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	m, err = NewPKCS11KeyManager(module, softHSMTokenLabel, softHSMPin)
+	if err != nil {
+		t.Fatal(err)
+	}
+	handles, err := m.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(handles) != 1 || handles[0].ID != keyID || handles[0].PublicKeyBase58 != pbKeyBase58 {
+		t.Fatalf("expected the key to survive a new session, got %+v", handles)
+	}
+	if _, err := m.Sign(keyID, make([]byte, 32), crypto.SHA256); err != nil {
+		t.Fatal(err)
+	}
+	if err := m.Delete(keyID); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := m.Sign(keyID, make([]byte, 32), crypto.SHA256); errorCode(err) != derrors.CodeNotFound {
+		t.Fatalf("expected a deleted key to be gone, got %v", err)
+	}
+	if err := m.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := NewPKCS11KeyManager(module, "no-such-token", softHSMPin); errorCode(err) != derrors.CodeNotFound {
+		t.Fatalf("expected an unknown token to be rejected, got %v", err)
+	}
+	if _, err := NewPKCS11KeyManager(module, softHSMTokenLabel, "0000"); errorCode(err) != derrors.CodeInvalidKey {
+		t.Fatalf("expected a wrong pin to be rejected, got %v", err)
+	}
+}
@@ -0,0 +1,163 @@
+package ledger_test
+
+import (
+	"byd50-ssi/pkg/did/core"
+	derrors "byd50-ssi/pkg/did/errors"
+	"byd50-ssi/pkg/did/ledger"
+	"byd50-ssi/pkg/did/pkg/database"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+func issueVc(t *testing.T, pvKey *ecdsa.PrivateKey, subject, typ string) string {
+	t.Helper()
+	standardClaims := jwt.StandardClaims{
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		IssuedAt:  time.Now().Unix(),
+		Issuer:    "did:byd50:issuer",
+		Subject:   subject,
+	}
+	return core.CreateVc("did:byd50:issuer", typ, map[string]interface{}{"name": "tester"}, standardClaims, pvKey)
+}
+
+func TestNewCredentialIDUnique(t *testing.T) {
+	seen := map[string]bool{}
+	for i := 0; i < 100; i++ {
+		id := core.NewCredentialID()
+		if !strings.HasPrefix(id, "urn:uuid:") {
+			t.Fatalf("unexpected id format: %s", id)
+		}
+		if seen[id] {
+			t.Fatalf("duplicate id: %s", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestNewRecord(t *testing.T) {
+	pvKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	vcJwt := issueVc(t, pvKey, "did:byd50:holder", "DriverLicenseCredential")
+
+	record, err := ledger.NewRecord(vcJwt)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(record.Jti, "urn:uuid:") {
+		t.Fatalf("unexpected jti: %s", record.Jti)
+	}
+	if record.SubjectDid != "did:byd50:holder" {
+		t.Fatalf("unexpected subject: %s", record.SubjectDid)
+	}
+	if record.Type != "DriverLicenseCredential" {
+		t.Fatalf("unexpected type: %s", record.Type)
+	}
+	if record.Issuer != "did:byd50:issuer" || record.IssuedAt == 0 || record.ExpiresAt == 0 {
+		t.Fatalf("unexpected record: %+v", record)
+	}
+	if record.Hash != ledger.Hash(vcJwt) {
+		t.Fatalf("unexpected hash: %s", record.Hash)
+	}
+
+	if _, err := ledger.NewRecord("not-a-jwt"); err == nil {
+		t.Fatal("expected error for malformed jwt")
+	}
+}
+
+func testStore(t *testing.T, store ledger.Store) {
+	t.Helper()
+	ctx := context.Background()
+	pvKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	first := issueVc(t, pvKey, "did:byd50:alice", "DriverLicenseCredential")
+	second := issueVc(t, pvKey, "did:byd50:bob", "DriverLicenseCredential")
+	third := issueVc(t, pvKey, "did:byd50:alice", "RentalCarAgreementCredential")
+
+	var issued []ledger.Record
+	for _, vcJwt := range []string{first, second, third} {
+		record, err := ledger.Issue(ctx, store, vcJwt)
+		if err != nil {
+			t.Fatal(err)
+		}
+		issued = append(issued, record)
+	}
+	for i, record := range issued {
+		if record.StatusIndex != int64(i) {
+			t.Fatalf("unexpected status index %d for record %d", record.StatusIndex, i)
+		}
+	}
+
+	if _, err := ledger.Issue(ctx, store, first); err == nil {
+		t.Fatal("expected duplicate jti to be rejected")
+	}
+
+	got, err := store.Get(ctx, issued[1].Jti)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got != issued[1] {
+		t.Fatalf("unexpected record: %+v", got)
+	}
+
+	_, err = store.Get(ctx, "urn:uuid:missing")
+	var dErr *derrors.Error
+	if !errors.As(err, &dErr) || dErr.Code() != derrors.CodeNotFound {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+
+	bySubject, err := store.ListBySubject(ctx, "did:byd50:alice")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bySubject) != 2 || bySubject[0].Jti != issued[0].Jti || bySubject[1].Jti != issued[2].Jti {
+		t.Fatalf("unexpected records by subject: %+v", bySubject)
+	}
+
+	byType, err := store.ListByType(ctx, "DriverLicenseCredential")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(byType) != 2 {
+		t.Fatalf("unexpected records by type: %+v", byType)
+	}
+
+	all, err := store.List(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(all) != 3 {
+		t.Fatalf("unexpected record count: %d", len(all))
+	}
+}
+
+func TestMemoryStore(t *testing.T) {
+	testStore(t, ledger.NewMemoryStore())
+}
+
+func TestLevelDBStore(t *testing.T) {
+	db, err := database.InitializePath(filepath.Join(t.TempDir(), "ledger.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := ledger.NewLevelDBStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = store.Close() }()
+
+	testStore(t, store)
+}
@@ -0,0 +1,137 @@
+package ledger
+
+import (
+	derrors "byd50-ssi/pkg/did/errors"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+	"sort"
+	"sync"
+)
+
+const (
+	recordKeyPrefix = "ledger:jti:"
+	sequenceKey     = "ledger:seq"
+)
+
+// LevelDBStore implements Store using LevelDB.
+type LevelDBStore struct {
+	mu sync.Mutex
+	db *leveldb.DB
+}
+
+func NewLevelDBStore(db *leveldb.DB) (*LevelDBStore, error) {
+	if db == nil {
+		return nil, errors.New("leveldb db is nil")
+	}
+	return &LevelDBStore{db: db}, nil
+}
+
+func (s *LevelDBStore) Append(_ context.Context, record Record) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record.Jti == "" {
+		return record, derrors.New(derrors.CodeInvalidInput, "jti is empty")
+	}
+	key := []byte(recordKeyPrefix + record.Jti)
+	exists, err := s.db.Has(key, nil)
+	if err != nil {
+		return record, derrors.Wrap(derrors.CodeInternal, "ledger lookup failed", err)
+	}
+	if exists {
+		return record, derrors.New(derrors.CodeInvalidInput, "jti already recorded: "+record.Jti)
+	}
+
+	next, err := s.nextIndex()
+	if err != nil {
+		return record, err
+	}
+	record.StatusIndex = next
+
+	value, err := json.Marshal(record)
+	if err != nil {
+		return record, derrors.Wrap(derrors.CodeInternal, "failed to encode ledger record", err)
+	}
+	seq := make([]byte, 8)
+	binary.BigEndian.PutUint64(seq, uint64(next+1))
+
+	batch := new(leveldb.Batch)
+	batch.Put(key, value)
+	batch.Put([]byte(sequenceKey), seq)
+	if err := s.db.Write(batch, nil); err != nil {
+		return record, derrors.Wrap(derrors.CodeInternal, "failed to write ledger record", err)
+	}
+	return record, nil
+}
+
+func (s *LevelDBStore) Get(_ context.Context, jti string) (Record, error) {
+	var record Record
+	value, err := s.db.Get([]byte(recordKeyPrefix+jti), nil)
+	if err == leveldb.ErrNotFound {
+		return record, derrors.New(derrors.CodeNotFound, "credential not found: "+jti)
+	}
+	if err != nil {
+		return record, derrors.Wrap(derrors.CodeInternal, "ledger lookup failed", err)
+	}
+	if err := json.Unmarshal(value, &record); err != nil {
+		return record, derrors.Wrap(derrors.CodeInternal, "failed to decode ledger record", err)
+	}
+	return record, nil
+}
+
+func (s *LevelDBStore) ListBySubject(ctx context.Context, subjectDid string) ([]Record, error) {
+	records, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return filter(records, func(r Record) bool { return r.SubjectDid == subjectDid }), nil
+}
+
+func (s *LevelDBStore) ListByType(ctx context.Context, typ string) ([]Record, error) {
+	records, err := s.List(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return filter(records, func(r Record) bool { return r.Type == typ }), nil
+}
+
+// List returns all records ordered by status index (issuance order).
+func (s *LevelDBStore) List(_ context.Context) ([]Record, error) {
+	var records []Record
+	iter := s.db.NewIterator(util.BytesPrefix([]byte(recordKeyPrefix)), nil)
+	defer iter.Release()
+	for iter.Next() {
+		var record Record
+		if err := json.Unmarshal(iter.Value(), &record); err != nil {
+			return nil, derrors.Wrap(derrors.CodeInternal, "failed to decode ledger record", err)
+		}
+		records = append(records, record)
+	}
+	if err := iter.Error(); err != nil {
+		return nil, derrors.Wrap(derrors.CodeInternal, "ledger iteration failed", err)
+	}
+	sort.Slice(records, func(i, j int) bool { return records[i].StatusIndex < records[j].StatusIndex })
+	return records, nil
+}
+
+func (s *LevelDBStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *LevelDBStore) nextIndex() (int64, error) {
+	value, err := s.db.Get([]byte(sequenceKey), nil)
+	if err == leveldb.ErrNotFound {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, derrors.Wrap(derrors.CodeInternal, "failed to read ledger sequence", err)
+	}
+	if len(value) != 8 {
+		return 0, derrors.New(derrors.CodeInternal, "corrupted ledger sequence")
+	}
+	return int64(binary.BigEndian.Uint64(value)), nil
+}
@@ -0,0 +1,64 @@
+package ledger
+
+import (
+	derrors "byd50-ssi/pkg/did/errors"
+	"context"
+	"sync"
+)
+
+// MemoryStore implements Store in memory. Records are kept in issuance order.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	records []Record
+	byJti   map[string]int
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{byJti: map[string]int{}}
+}
+
+func (s *MemoryStore) Append(_ context.Context, record Record) (Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if record.Jti == "" {
+		return record, derrors.New(derrors.CodeInvalidInput, "jti is empty")
+	}
+	if _, ok := s.byJti[record.Jti]; ok {
+		return record, derrors.New(derrors.CodeInvalidInput, "jti already recorded: "+record.Jti)
+	}
+	record.StatusIndex = int64(len(s.records))
+	s.byJti[record.Jti] = len(s.records)
+	s.records = append(s.records, record)
+	return record, nil
+}
+
+func (s *MemoryStore) Get(_ context.Context, jti string) (Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	idx, ok := s.byJti[jti]
+	if !ok {
+		return Record{}, derrors.New(derrors.CodeNotFound, "credential not found: "+jti)
+	}
+	return s.records[idx], nil
+}
+
+func (s *MemoryStore) ListBySubject(ctx context.Context, subjectDid string) ([]Record, error) {
+	records, _ := s.List(ctx)
+	return filter(records, func(r Record) bool { return r.SubjectDid == subjectDid }), nil
+}
+
+func (s *MemoryStore) ListByType(ctx context.Context, typ string) ([]Record, error) {
+	records, _ := s.List(ctx)
+	return filter(records, func(r Record) bool { return r.Type == typ }), nil
+}
+
+func (s *MemoryStore) List(_ context.Context) ([]Record, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	records := make([]Record, len(s.records))
+	copy(records, s.records)
+	return records, nil
+}
@@ -0,0 +1,69 @@
+package ledger
+
+import (
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	derrors "byd50-ssi/pkg/did/errors"
+	"crypto/sha256"
+	"encoding/hex"
+	"github.com/golang-jwt/jwt"
+)
+
+// Record is a single entry of the issuer-side issuance ledger.
+// It keeps enough metadata to answer "what did we issue to whom" without storing the credential itself.
+type Record struct {
+	Jti         string `json:"jti"`
+	Issuer      string `json:"iss"`
+	SubjectDid  string `json:"sub"`
+	Type        string `json:"type"`
+	IssuedAt    int64  `json:"iat"`
+	ExpiresAt   int64  `json:"exp"`
+	StatusIndex int64  `json:"statusIndex"`
+	Hash        string `json:"hash"`
+}
+
+// NewRecord builds a ledger record from a signed VC JWT.
+// The status index is assigned by the Store when the record is appended.
+func NewRecord(vcJwt string) (Record, error) {
+	var record Record
+	token, _, err := new(jwt.Parser).ParseUnverified(vcJwt, jwt.MapClaims{})
+	if err != nil {
+		return record, derrors.Wrap(derrors.CodeInvalidInput, "failed to parse vc jwt", err)
+	}
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return record, derrors.New(derrors.CodeInvalidInput, "vc jwt has no claims")
+	}
+
+	mc := byd50_jwt.MapClaims(claims)
+	record.Jti, _ = claims["jti"].(string)
+	if record.Jti == "" {
+		return record, derrors.New(derrors.CodeInvalidInput, "vc jti is empty")
+	}
+	record.Issuer, _ = mc.GetIssuer()
+	record.SubjectDid, _ = claims["sub"].(string)
+	record.IssuedAt, _ = mc.GetIssuedAt()
+	record.ExpiresAt, _ = mc.GetExpiresAt()
+	record.Type = credentialType(mc)
+	record.Hash = Hash(vcJwt)
+	return record, nil
+}
+
+// Hash returns the hex encoded SHA-256 digest of a credential as stored in the ledger.
+func Hash(vcJwt string) string {
+	digest := sha256.Sum256([]byte(vcJwt))
+	return hex.EncodeToString(digest[:])
+}
+
+// credentialType returns the most specific type of the VC (the last one other than VerifiableCredential).
+func credentialType(claims byd50_jwt.MapClaims) string {
+	types, err := claims.GetVcType()
+	if err != nil {
+		return ""
+	}
+	for i := len(types) - 1; i >= 0; i-- {
+		if types[i] != "VerifiableCredential" {
+			return types[i]
+		}
+	}
+	return ""
+}
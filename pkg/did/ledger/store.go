@@ -0,0 +1,34 @@
+package ledger
+
+import (
+	"context"
+)
+
+// Store defines persistence and query operations for the issuance ledger.
+type Store interface {
+	// Append stores the record, assigning the next status index. The stored record is returned.
+	Append(ctx context.Context, record Record) (Record, error)
+	Get(ctx context.Context, jti string) (Record, error)
+	ListBySubject(ctx context.Context, subjectDid string) ([]Record, error)
+	ListByType(ctx context.Context, typ string) ([]Record, error)
+	List(ctx context.Context) ([]Record, error)
+}
+
+// Issue records a freshly signed VC JWT in the store.
+func Issue(ctx context.Context, store Store, vcJwt string) (Record, error) {
+	record, err := NewRecord(vcJwt)
+	if err != nil {
+		return record, err
+	}
+	return store.Append(ctx, record)
+}
+
+func filter(records []Record, match func(Record) bool) []Record {
+	var matched []Record
+	for _, r := range records {
+		if match(r) {
+			matched = append(matched, r)
+		}
+	}
+	return matched
+}
@@ -1,13 +1,21 @@
 package controller
 
 import (
+	"byd50-ssi/pkg/did/configs"
 	"byd50-ssi/pkg/did/core"
+	"byd50-ssi/pkg/did/core/didauth"
 	"byd50-ssi/pkg/did/core/dids"
+	"byd50-ssi/pkg/did/core/jwe"
 	"byd50-ssi/pkg/did/core/rc"
 	derrors "byd50-ssi/pkg/did/errors"
+	"byd50-ssi/pkg/keys"
 	pb "byd50-ssi/proto-files"
 	"context"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
 	"encoding/json"
+	"errors"
 	"log"
 	"strings"
 	"time"
@@ -121,8 +129,220 @@ func ResolveDIDWithErr(dID string) (string, error) {
 	return documents, nil
 }
 
+/**
+ * Replace the DID Document. The update is signed with a capabilityInvocation key of the DID.
+ *
+ * @param did      the id of DID document
+ * @param document the new DID document
+ * @param pvKey    the private key of a capabilityInvocation key of the DID
+ */
+func UpdateDIDWithErr(did, document string, pvKey crypto.PrivateKey) error {
+	currentDocument, err := ResolveDIDWithErr(did)
+	if err != nil {
+		return err
+	}
+	proof, err := core.SignDidUpdate(did, currentDocument, document, pvKey)
+	if err != nil {
+		return err
+	}
+
+	registrarClient := getRegistrarClient()
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	r, err := registrarClient.UpdateDid(ctx, &pb.UpdateDidRequest{Did: did, Document: document, Proof: proof})
+	if err != nil {
+		return derrors.Wrap(derrors.CodeUpstream, "registrar update did failed", err)
+	}
+	if r.GetResult() != "success" {
+		return derrors.New(derrors.CodeUpstream, "registrar update did failed: "+r.GetResult())
+	}
+	log.Printf("UpdateDID(%v)", did)
+	return nil
+}
+
+/**
+ * Add a service to the DID Document.
+ *
+ * @param did     the id of DID document
+ * @param service the service; a relative id ("#name") is expanded with the DID
+ * @param pvKey   the private key of a capabilityInvocation key of the DID
+ */
+func AddService(did string, service dids.ServiceProperty, pvKey crypto.PrivateKey) error {
+	return updateDocument(did, pvKey, func(doc *dids.DocumentInterface) error {
+		return doc.AddService(service)
+	})
+}
+
+/**
+ * Add a service to the DID Document, replacing the service that has the same id, if any.
+ *
+ * @param did     the id of DID document
+ * @param service the service; a relative id ("#name") is expanded with the DID
+ * @param pvKey   the private key of a capabilityInvocation key of the DID
+ */
+func ReplaceService(did string, service dids.ServiceProperty, pvKey crypto.PrivateKey) error {
+	return updateDocument(did, pvKey, func(doc *dids.DocumentInterface) error {
+		return doc.ReplaceService(service)
+	})
+}
+
+/**
+ * Remove a service from the DID Document.
+ *
+ * @param did       the id of DID document
+ * @param serviceId the id of the service
+ * @param pvKey     the private key of a capabilityInvocation key of the DID
+ */
+func RemoveService(did, serviceId string, pvKey crypto.PrivateKey) error {
+	return updateDocument(did, pvKey, func(doc *dids.DocumentInterface) error {
+		return doc.RemoveService(serviceId)
+	})
+}
+
+// GetServices returns the services of the DID document that have the given type (all services when empty).
+func GetServices(did, serviceType string) ([]dids.ServiceProperty, error) {
+	doc, err := resolveDocument(did)
+	if err != nil {
+		return nil, err
+	}
+	if serviceType == "" {
+		return doc.Service, nil
+	}
+	return doc.ServicesByType(serviceType), nil
+}
+
+/**
+ * Add a key agreement key to the DID Document, so that JWEs can be encrypted to the DID (see EncryptForDIDs).
+ *
+ * @param did         the id of DID document
+ * @param keyId       the id of the verification method; a relative id ("#name") is expanded with the DID
+ * @param pbKeyBase58 the X25519 or EC (P-256, P-384, secp256k1) public key, see keys.ExportPublicKeyAsBase58
+ * @param pvKey       the private key of a capabilityInvocation key of the DID
+ */
+func AddKeyAgreementKey(did, keyId, pbKeyBase58 string, pvKey crypto.PrivateKey) error {
+	pbKey, err := keys.ParsePublicKeyBase58(pbKeyBase58)
+	if err != nil {
+		return derrors.Wrap(derrors.CodeInvalidKey, "invalid public key", err)
+	}
+	if !isKeyAgreementKey(pbKey) {
+		return derrors.New(derrors.CodeInvalidKey, "key agreement keys must be X25519 or EC keys")
+	}
+	vm, err := dids.NewVerificationMethod(keyId, did, "", pbKeyBase58)
+	if err != nil {
+		return derrors.Wrap(derrors.CodeInvalidKey, "invalid verification method", err)
+	}
+	return updateDocument(did, pvKey, func(doc *dids.DocumentInterface) error {
+		return doc.AddVerificationMethod(vm, dids.KeyAgreement)
+	})
+}
+
+/**
+ * Remove a verification method from the DID Document and from all of its verification relationships.
+ *
+ * @param did   the id of DID document
+ * @param keyId the id of the verification method
+ * @param pvKey the private key of a capabilityInvocation key of the DID
+ */
+func RemoveVerificationMethod(did, keyId string, pvKey crypto.PrivateKey) error {
+	return updateDocument(did, pvKey, func(doc *dids.DocumentInterface) error {
+		return doc.RemoveVerificationMethod(keyId)
+	})
+}
+
+// GetKeyAgreementRecipients returns the keyAgreement keys of the DID document as JWE recipients, identified by
+// the DID URLs of their verification methods.
+func GetKeyAgreementRecipients(did string) ([]jwe.Recipient, error) {
+	doc, err := resolveDocument(did)
+	if err != nil {
+		return nil, err
+	}
+	var recipients []jwe.Recipient
+	for _, entry := range doc.KeyAgreement {
+		vm, err := doc.FindVerificationMethod(dids.KeyAgreement, entry.ID)
+		if err != nil {
+			return nil, derrors.Wrap(derrors.CodeNotFound, "unresolvable keyAgreement key in document", err)
+		}
+		pbKey, err := vm.PublicKey()
+		if err != nil {
+			return nil, derrors.Wrap(derrors.CodeInvalidKey, "invalid public key in document", err)
+		}
+		if !isKeyAgreementKey(pbKey) {
+			continue
+		}
+		keyId := vm.ID
+		if strings.HasPrefix(keyId, "#") {
+			keyId = doc.ID + keyId
+		}
+		recipients = append(recipients, jwe.Recipient{KeyID: keyId, PublicKey: pbKey})
+	}
+	if len(recipients) == 0 {
+		return nil, derrors.New(derrors.CodeNotFound, "no keyAgreement key in document of "+did)
+	}
+	return recipients, nil
+}
+
+// EncryptForDIDs encrypts plaintext, e.g. a credential, to every keyAgreement key of the given DIDs. Each holder
+// decrypts the JWE with its key, e.g. with kms.DecryptJWE. contentType is the cty of the JWE, such as "JWT".
+func EncryptForDIDs(plaintext []byte, contentType string, recipientDids ...string) (*jwe.JWE, error) {
+	if len(recipientDids) == 0 {
+		return nil, derrors.New(derrors.CodeInvalidInput, "at least one recipient did is required")
+	}
+	var recipients []jwe.Recipient
+	for _, did := range recipientDids {
+		didRecipients, err := GetKeyAgreementRecipients(did)
+		if err != nil {
+			return nil, err
+		}
+		recipients = append(recipients, didRecipients...)
+	}
+	return jwe.Encrypt(plaintext, contentType, recipients...)
+}
+
+func isKeyAgreementKey(pbKey crypto.PublicKey) bool {
+	switch pbKey.(type) {
+	case *ecdh.PublicKey, *ecdsa.PublicKey:
+		return true
+	}
+	return false
+}
+
+func updateDocument(did string, pvKey crypto.PrivateKey, update func(doc *dids.DocumentInterface) error) error {
+	doc, err := resolveDocument(did)
+	if err != nil {
+		return err
+	}
+	if err := update(&doc); err != nil {
+		switch {
+		case errors.Is(err, dids.ErrServiceNotFound):
+			return derrors.Wrap(derrors.CodeNotFound, "service not found", err)
+		case errors.Is(err, dids.ErrVerificationMethodNotFound):
+			return derrors.Wrap(derrors.CodeNotFound, "verification method not found", err)
+		default:
+			return derrors.Wrap(derrors.CodeInvalidInput, "invalid document update", err)
+		}
+	}
+	document, err := json.MarshalIndent(doc, "", " ")
+	if err != nil {
+		return derrors.Wrap(derrors.CodeInternal, "failed to encode did document", err)
+	}
+	return UpdateDIDWithErr(did, string(document), pvKey)
+}
+
+func resolveDocument(did string) (dids.DocumentInterface, error) {
+	var ifDoc dids.DocumentInterface
+	document, err := ResolveDIDWithErr(did)
+	if err != nil {
+		return ifDoc, err
+	}
+	if err := json.Unmarshal([]byte(document), &ifDoc); err != nil {
+		return ifDoc, derrors.Wrap(derrors.CodeInternal, "failed to parse did document", err)
+	}
+	return ifDoc, nil
+}
+
 /**
  * Get a publicKey that matches the id of DID document and the id of publicKey.
+ * The key must be listed in the authentication relationship of the document.
  *
  * @param did   the id of DID document
  * @param keyId the id of publicKey
@@ -138,23 +358,47 @@ func GetPublicKey(did, keyId string) string {
 }
 
 func GetPublicKeyWithErr(did, keyId string) (string, error) {
-	// Add PublicKey in to the Document
-	var ifDoc dids.DocumentInterface
-	document, err := ResolveDIDWithErr(did)
+	return GetVerificationKeyWithErr(did, dids.Authentication, keyId)
+}
+
+// GetAssertionMethodKey returns the key of the assertionMethod relationship, i.e. a key the DID subject
+// issues credentials with. It has the getPbKey signature used to verify VCs.
+func GetAssertionMethodKey(did, keyId string) string {
+	pbKeyBase58, err := GetVerificationKeyWithErr(did, dids.AssertionMethod, keyId)
 	if err != nil {
-		return "", err
+		log.Printf("GetAssertionMethodKey error: %v", err)
+		return ""
 	}
-	if err := json.Unmarshal([]byte(document), &ifDoc); err != nil {
-		return "", derrors.Wrap(derrors.CodeInternal, "failed to parse did document", err)
+	return pbKeyBase58
+}
+
+// GetVerificationKeyWithErr returns the base58 public key of the verification method keyId (the first one
+// when empty) listed in the given verification relationship of the DID document.
+func GetVerificationKeyWithErr(did, relationship, keyId string) (string, error) {
+	ifDoc, err := resolveDocument(did)
+	if err != nil {
+		return "", err
 	}
-	if len(ifDoc.Authentication) == 0 {
-		return "", derrors.New(derrors.CodeNotFound, "no authentication keys in document")
+	vm, err := ifDoc.FindVerificationMethod(relationship, keyId)
+	if err != nil {
+		return "", derrors.Wrap(derrors.CodeNotFound, "no "+relationship+" key in document", err)
 	}
-	pbKeyBase58 := ifDoc.Authentication[0].PublicKeyBase58
 
-	if len(pbKeyBase58) == 0 {
+	// publicKeyJwk and publicKeyMultibase are converted to base58; legacy publicKeyBase58 is used as is.
+	pbKeyBase58, err := vm.PublicKeyAsBase58()
+	if errors.Is(err, dids.ErrNoPublicKey) {
 		return "", derrors.New(derrors.CodeEmptyKey, "public key is empty")
 	}
+	if err != nil {
+		return "", derrors.Wrap(derrors.CodeInvalidKey, "invalid public key in document", err)
+	}
+	// The key must match the verification method type declared in the document,
+	// since the JWS alg accepted by verifiers is derived from the key.
+	if vm.Types != "" {
+		if err := core.CheckVerificationMethodKey(vm.Types, pbKeyBase58); err != nil {
+			return "", err
+		}
+	}
 
 	return pbKeyBase58, nil
 }
@@ -184,6 +428,9 @@ func GetAuthResponseString(challengeString, pvKeyBase58 string) string {
 	return authResponseString
 }
 
+// GetSimplePresent returns the legacy "did;time;signature" simple presentation of did, signed with the RSA key pvKeyBase58.
+//
+// Deprecated: it has no audience or nonce; use GetDidAuthToken.
 func GetSimplePresent(did, pvKeyBase58 string) string {
 	didAndTime := did + ";" + time.Now().UTC().Format(time.RFC3339)
 
@@ -195,31 +442,36 @@ func GetSimplePresent(did, pvKeyBase58 string) string {
 	return simplePresentString
 }
 
-func VerifySimplePresent(simplePresentString string) string {
-	var result = "fail"
+// legacySimplePresents verifies legacy simple presentations and remembers the accepted ones, so each is used once.
+var legacySimplePresents = &didauth.TokenVerifier{GetPbKey: GetPublicKey, ClockSkew: configs.UseConfig.DidAuthClockSkew}
 
-	slice := strings.Split(simplePresentString, ";")
-	aDid := slice[0]
-	aTime := slice[1]
-	aSign := slice[2]
-	presentTime, _ := time.Parse(time.RFC3339, aTime)
+// VerifySimplePresent verifies a legacy simple presentation and returns "success", "time out" when it is older
+// than didauth.DefaultLegacyWindow, or "fail".
+//
+// Deprecated: relying parties should verify DID auth tokens with a didauth.TokenVerifier, which also accepts
+// legacy presentations through VerifyLegacy.
+func VerifySimplePresent(simplePresentString string) string {
+	did, err := legacySimplePresents.VerifyLegacy(simplePresentString)
+	switch {
+	case err == nil:
+		log.Printf("VerifySimplePresent: %v", did)
+		return "success"
+	case didauth.TokenErrorCodeOf(err) == didauth.TokenExpired:
+		return "time out"
+	}
+	log.Printf("VerifySimplePresent error: %v", err)
+	return "fail"
+}
 
-	log.Printf("presentTime: %v", presentTime)
-	duration := time.Now().UTC().Sub(presentTime)
-	log.Printf("duration = %v", duration)
+// didAuthTokenValidity is how long the DID auth tokens of GetDidAuthToken are valid.
+const didAuthTokenValidity = time.Minute
 
-	if duration < time.Second*10 {
-		pbKeyBase58, err := GetPublicKeyWithErr(aDid, "")
-		if err != nil {
-			log.Printf("VerifySimplePresent error: %v", err)
-			return result
-		}
-		if core.PbKeyVerify(pbKeyBase58, aDid+";"+aTime, aSign) {
-			result = "success"
-		}
-	} else {
-		result = "time out"
+// GetDidAuthToken returns a DID auth token with which did authenticates to the relying party aud, signed with
+// pvKeyBase58, which should belong to an authentication key of did.
+func GetDidAuthToken(did, aud, pvKeyBase58 string) (string, error) {
+	pvKey, err := keys.ParsePrivateKeyBase58(pvKeyBase58)
+	if err != nil {
+		return "", derrors.Wrap(derrors.CodeInvalidKey, "invalid private key", err)
 	}
-
-	return result
+	return didauth.CreateToken(did, aud, didAuthTokenValidity, pvKey)
 }
@@ -1,7 +1,10 @@
 package controller
 
 import (
+	"byd50-ssi/pkg/did/core"
+	"byd50-ssi/pkg/did/core/didauth"
 	"byd50-ssi/pkg/did/core/dids"
+	derrors "byd50-ssi/pkg/did/errors"
 	"byd50-ssi/pkg/did/kms"
 	pb "byd50-ssi/proto-files"
 	"context"
@@ -37,8 +40,16 @@ func (f *fakeRegistrarClient) ResolveDid(_ context.Context, in *pb.ResolveDidReq
 	return &pb.ResolveDidResponse{DidDocument: doc}, nil
 }
 
-func (f *fakeRegistrarClient) UpdateDid(_ context.Context, _ *pb.UpdateDidRequest, _ ...grpc.CallOption) (*pb.UpdateDidResponse, error) {
-	return &pb.UpdateDidResponse{Result: "ok"}, nil
+func (f *fakeRegistrarClient) UpdateDid(_ context.Context, in *pb.UpdateDidRequest, _ ...grpc.CallOption) (*pb.UpdateDidResponse, error) {
+	current, ok := f.docs[in.GetDid()]
+	if !ok {
+		return &pb.UpdateDidResponse{Result: "not found"}, nil
+	}
+	if err := core.VerifyDidUpdate(in.GetProof(), in.GetDid(), current, in.GetDocument()); err != nil {
+		return nil, err
+	}
+	f.docs[in.GetDid()] = in.GetDocument()
+	return &pb.UpdateDidResponse{Result: "success"}, nil
 }
 
 func TestControllerFlow(t *testing.T) {
@@ -88,6 +99,21 @@ func TestControllerFlow(t *testing.T) {
 	if VerifySimplePresent(simplePresent) != "success" {
 		t.Fatal(errors.New("simple present failed"))
 	}
+	if VerifySimplePresent(simplePresent) != "fail" {
+		t.Fatal(errors.New("replayed simple present accepted"))
+	}
+	if VerifySimplePresent("malformed") != "fail" {
+		t.Fatal(errors.New("malformed simple present accepted"))
+	}
+
+	token, err := GetDidAuthToken(did, "byd50-ssi:test-rp", dkms.PvKeyBase58())
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifier := &didauth.TokenVerifier{Audience: "byd50-ssi:test-rp", GetPbKey: GetPublicKey}
+	if tokenDid, err := verifier.Verify(token); err != nil || tokenDid != did {
+		t.Fatalf("did auth token rejected: %v", err)
+	}
 }
 
 func TestGetPublicKeyEmpty(t *testing.T) {
@@ -139,3 +165,217 @@ func TestControllerErrorPaths(t *testing.T) {
 		t.Fatal("expected GetPublicKeyWithErr error")
 	}
 }
+
+func TestVerificationRelationshipKeys(t *testing.T) {
+	oldProvider := registrarClientProvider
+	defer func() { registrarClientProvider = oldProvider }()
+
+	authKMS, err := kms.InitKMS(kms.KeyTypeECDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	issueKMS, err := kms.InitKMS(kms.KeyTypeECDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The authentication key may not issue credentials and the assertion key may not authenticate.
+	did := "did:byd50:relationships"
+	authVm, err := dids.NewVerificationMethod(did+"#keys-1", did, "", authKMS.PbKeyBase58())
+	if err != nil {
+		t.Fatal(err)
+	}
+	issueVm, err := dids.NewVerificationMethod(did+"#keys-2", did, "", issueKMS.PbKeyBase58())
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := dids.DocumentInterface{
+		Context:            []string{"https://www.w3.org/ns/dids/v1"},
+		ID:                 did,
+		VerificationMethod: []dids.VerificationMethodProperty{authVm, issueVm},
+		Authentication:     []dids.AuthenticationProperty{dids.ReferenceTo(authVm.ID)},
+		AssertionMethod:    []dids.VerificationRelationship{dids.ReferenceTo(issueVm.ID)},
+	}
+	docBytes, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	legacyDid := "did:byd50:legacy"
+	legacyDoc := dids.DocumentInterface{
+		Context: []string{"https://www.w3.org/ns/dids/v1"},
+		ID:      legacyDid,
+		Authentication: []dids.AuthenticationProperty{
+			{ID: legacyDid + "#keys-1", Controller: legacyDid, PublicKeyBase58: authKMS.PbKeyBase58()},
+		},
+	}
+	legacyBytes, err := json.Marshal(legacyDoc)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	fake := &fakeRegistrarClient{docs: map[string]string{did: string(docBytes), legacyDid: string(legacyBytes)}}
+	registrarClientProvider = func() pb.RegistrarClient { return fake }
+
+	if pbKey := GetPublicKey(did, ""); pbKey != authKMS.PbKeyBase58() {
+		t.Fatal("authentication key mismatch")
+	}
+	if pbKey := GetAssertionMethodKey(did, ""); pbKey != issueKMS.PbKeyBase58() {
+		t.Fatal("assertionMethod key mismatch")
+	}
+	if _, err := GetPublicKeyWithErr(did, "keys-2"); err == nil {
+		t.Fatal("expected assertion key to be rejected for authentication")
+	}
+	if _, err := GetVerificationKeyWithErr(did, dids.AssertionMethod, "keys-1"); err == nil {
+		t.Fatal("expected authentication key to be rejected for assertionMethod")
+	}
+	if _, err := GetVerificationKeyWithErr(did, dids.KeyAgreement, ""); err == nil {
+		t.Fatal("expected missing keyAgreement key error")
+	}
+
+	// Documents with only an embedded authentication key keep working for credentials.
+	if pbKey := GetAssertionMethodKey(legacyDid, ""); pbKey != authKMS.PbKeyBase58() {
+		t.Fatal("legacy document key mismatch")
+	}
+}
+
+func TestServiceUpdates(t *testing.T) {
+	oldProvider := registrarClientProvider
+	defer func() { registrarClientProvider = oldProvider }()
+
+	fake := &fakeRegistrarClient{docs: map[string]string{}}
+	registrarClientProvider = func() pb.RegistrarClient { return fake }
+
+	dkms, err := kms.InitKMS(kms.KeyTypeEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	otherKMS, err := kms.InitKMS(kms.KeyTypeEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	did, err := CreateDIDWithErr(dkms.PbKeyBase58(), "byd50")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	didcomm := dids.ServiceProperty{
+		ID:    "#didcomm",
+		Types: dids.ServiceTypes{dids.ServiceTypeDIDCommMessaging},
+		ServiceEndpoint: dids.EndpointMap(map[string]interface{}{
+			"uri":    "https://holder.example/didcomm",
+			"accept": []interface{}{"didcomm/v2"},
+		}),
+	}
+	if err := AddService(did, didcomm, otherKMS.PvKey()); err == nil {
+		t.Fatal("expected update signed by another key to be rejected")
+	}
+	if err := AddService(did, didcomm, dkms.PvKey()); err != nil {
+		t.Fatal(err)
+	}
+	registry := dids.ServiceProperty{
+		ID:              did + "#registry",
+		Types:           dids.ServiceTypes{dids.ServiceTypeCredentialRegistry},
+		ServiceEndpoint: dids.EndpointURIs("https://a.example/registry", "https://b.example/registry"),
+	}
+	if err := AddService(did, registry, dkms.PvKey()); err != nil {
+		t.Fatal(err)
+	}
+
+	services, err := GetServices(did, dids.ServiceTypeDIDCommMessaging)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(services) != 1 || services[0].ID != did+"#didcomm" || services[0].ServiceEndpoint.URIs()[0] != "https://holder.example/didcomm" {
+		t.Fatalf("unexpected services: %+v", services)
+	}
+	// Keys are still resolvable from the updated document.
+	if pbKey, err := GetPublicKeyWithErr(did, ""); err != nil || pbKey != dkms.PbKeyBase58() {
+		t.Fatalf("public key lost after update: %v", err)
+	}
+
+	if err := RemoveService(did, "#didcomm", dkms.PvKey()); err != nil {
+		t.Fatal(err)
+	}
+	var dErr *derrors.Error
+	if err := RemoveService(did, "#didcomm", dkms.PvKey()); !errors.As(err, &dErr) || dErr.Code() != derrors.CodeNotFound {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if services, _ := GetServices(did, ""); len(services) != 1 || services[0].ID != did+"#registry" {
+		t.Fatalf("unexpected services after remove: %+v", services)
+	}
+}
+
+func TestKeyAgreementEncryption(t *testing.T) {
+	oldProvider := registrarClientProvider
+	defer func() { registrarClientProvider = oldProvider }()
+
+	fake := &fakeRegistrarClient{docs: map[string]string{}}
+	registrarClientProvider = func() pb.RegistrarClient { return fake }
+
+	holderKMS, err := kms.InitKMS(kms.KeyTypeEd25519)
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifierKMS, err := kms.InitKMS(kms.KeyTypeECDSA)
+	if err != nil {
+		t.Fatal(err)
+	}
+	holderDid, err := CreateDIDWithErr(holderKMS.PbKeyBase58(), "byd50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	verifierDid, err := CreateDIDWithErr(verifierKMS.PbKeyBase58(), "byd50")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dErr *derrors.Error
+	if _, err := EncryptForDIDs([]byte("vc"), "JWT", holderDid); !errors.As(err, &dErr) || dErr.Code() != derrors.CodeNotFound {
+		t.Fatalf("expected a DID without keyAgreement keys to be rejected, got %v", err)
+	}
+
+	// Key agreement keys are held by a key manager and published in the DID documents.
+	m := kms.NewMemoryKeyManager()
+	holderKeyID, _ := m.Create(kms.KeyTypeX25519)
+	verifierKeyID, _ := m.Create(kms.KeyTypeECDSA)
+	holderPb, _ := kms.PublicKeyBase58(m, holderKeyID)
+	verifierPb, _ := kms.PublicKeyBase58(m, verifierKeyID)
+	if err := AddKeyAgreementKey(holderDid, "#key-agreement-1", holderPb, holderKMS.PvKey()); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddKeyAgreementKey(verifierDid, verifierDid+"#key-agreement-1", verifierPb, verifierKMS.PvKey()); err != nil {
+		t.Fatal(err)
+	}
+	if err := AddKeyAgreementKey(holderDid, "#key-agreement-2", holderKMS.PbKeyBase58(), holderKMS.PvKey()); !errors.As(err, &dErr) || dErr.Code() != derrors.CodeInvalidKey {
+		t.Fatalf("expected an ed25519 key agreement key to be rejected, got %v", err)
+	}
+
+	encrypted, err := EncryptForDIDs([]byte("eyJhbGciOiJFZERTQSJ9.vc.sig"), "JWT", holderDid, verifierDid)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if kids := encrypted.KeyIDs(); len(kids) != 2 || kids[0] != holderDid+"#key-agreement-1" || kids[1] != verifierDid+"#key-agreement-1" {
+		t.Fatalf("unexpected recipients %v", kids)
+	}
+	data, _ := json.Marshal(encrypted)
+	for _, keyID := range []string{holderKeyID, verifierKeyID} {
+		plaintext, err := kms.DecryptJWE(m, keyID, data)
+		if err != nil || string(plaintext) != "eyJhbGciOiJFZERTQSJ9.vc.sig" {
+			t.Fatalf("unexpected plaintext %q: %v", plaintext, err)
+		}
+	}
+	// The signing key is unaffected by the update.
+	if pbKey, err := GetPublicKeyWithErr(holderDid, ""); err != nil || pbKey != holderKMS.PbKeyBase58() {
+		t.Fatalf("public key lost after update: %v", err)
+	}
+
+	if err := RemoveVerificationMethod(holderDid, "key-agreement-1", holderKMS.PvKey()); err != nil {
+		t.Fatal(err)
+	}
+	if err := RemoveVerificationMethod(holderDid, "key-agreement-1", holderKMS.PvKey()); !errors.As(err, &dErr) || dErr.Code() != derrors.CodeNotFound {
+		t.Fatalf("expected not found error, got %v", err)
+	}
+	if _, err := GetKeyAgreementRecipients(holderDid); !errors.As(err, &dErr) || dErr.Code() != derrors.CodeNotFound {
+		t.Fatalf("expected the removed key to be gone, got %v", err)
+	}
+}
@@ -12,6 +12,11 @@ func Initialize() (*leveldb.DB, error) {
 	if path == "" {
 		path = "/tmp/foo.db"
 	}
+	return InitializePath(path)
+}
+
+// InitializePath opens (or creates) the database at the given path.
+func InitializePath(path string) (*leveldb.DB, error) {
 	db, err := leveldb.OpenFile(path, nil)
 	if err != nil {
 		log.Printf(err.Error())
@@ -0,0 +1,159 @@
+package trust
+
+import (
+	"byd50-ssi/pkg/did/core"
+	"byd50-ssi/pkg/did/core/vcdm"
+	derrors "byd50-ssi/pkg/did/errors"
+	"context"
+	"crypto"
+	"time"
+
+	"github.com/golang-jwt/jwt"
+)
+
+// accreditedForClaim lists the credential types an accreditation VC allows its subject to issue.
+const accreditedForClaim = "accreditedFor"
+
+// IssueAccreditation signs an accreditation VC in which rootDid accredits issuerDid as an issuer of credentialTypes.
+// pvKey should be an assertionMethod key of rootDid.
+func IssueAccreditation(rootDid, issuerDid string, credentialTypes []string, validity time.Duration, pvKey crypto.PrivateKey) (string, error) {
+	if err := validateDid(rootDid); err != nil {
+		return "", err
+	}
+	if err := validateDid(issuerDid); err != nil {
+		return "", err
+	}
+	if len(credentialTypes) == 0 {
+		return "", derrors.New(derrors.CodeInvalidInput, "no credential types to accredit")
+	}
+	if validity <= 0 {
+		return "", derrors.New(derrors.CodeInvalidInput, "validity must be positive")
+	}
+	if pvKey == nil {
+		return "", derrors.New(derrors.CodeEmptyKey, "private key is nil")
+	}
+
+	now := time.Now()
+	vc := vcdm.NewCredentialV1(AccreditationCredentialType, vcdm.CredentialSubject{
+		ID:     issuerDid,
+		Claims: map[string]interface{}{accreditedForClaim: credentialTypes},
+	})
+	vc.Issuer = vcdm.Issuer{ID: rootDid}
+	vc.ValidFrom = now
+	vc.ValidUntil = now.Add(validity)
+
+	claims, err := vc.ToVcClaims(jwt.StandardClaims{Id: core.NewCredentialID(), IssuedAt: now.Unix()}, core.RandomString(12))
+	if err != nil {
+		return "", err
+	}
+	vcJwt := core.CreateVcWithClaims(rootDid, claims, pvKey)
+	if vcJwt == "" {
+		return "", derrors.New(derrors.CodeInternal, "failed to sign accreditation")
+	}
+	return vcJwt, nil
+}
+
+// Accredit verifies an accreditation VC and adds an entry for every accredited credential type.
+// The signer must be a root authority valid now and covering each of the types; the entries
+// expire with the accreditation.
+func (r *Registry) Accredit(ctx context.Context, accreditationJwt string) ([]Entry, error) {
+	if r.getPbKey == nil {
+		return nil, derrors.New(derrors.CodeInternal, "registry cannot verify accreditations")
+	}
+	ok, mapClaims, err := core.GetVcMapClaims(accreditationJwt, r.getPbKey)
+	if err != nil || !ok {
+		return nil, derrors.Wrap(derrors.CodeInvalidInput, "accreditation signature invalid", err)
+	}
+	rootDid, err := core.GetSignerDid(accreditationJwt)
+	if err != nil {
+		return nil, err
+	}
+	if iss, _ := mapClaims["iss"].(string); iss != rootDid {
+		return nil, derrors.New(derrors.CodeInvalidInput, "accreditation issuer must be its signer")
+	}
+	exp, ok := mapClaims["exp"].(float64)
+	if !ok {
+		return nil, derrors.New(derrors.CodeInvalidInput, "accreditation has no exp")
+	}
+	jti, _ := mapClaims["jti"].(string)
+
+	vc, err := vcdm.CredentialFromMapClaims(mapClaims)
+	if err != nil {
+		return nil, err
+	}
+	if !core.Contains(vc.Type, AccreditationCredentialType) {
+		return nil, derrors.New(derrors.CodeInvalidInput, "credential is not an "+AccreditationCredentialType)
+	}
+	if len(vc.CredentialSubject) != 1 {
+		return nil, derrors.New(derrors.CodeInvalidInput, "accreditation must have a single subject")
+	}
+	subject := vc.CredentialSubject[0]
+	if err := validateDid(subject.ID); err != nil {
+		return nil, err
+	}
+	credentialTypes, err := accreditedTypes(subject.Claims[accreditedForClaim])
+	if err != nil {
+		return nil, err
+	}
+
+	roots, err := r.store.ListRoots(ctx)
+	if err != nil {
+		return nil, err
+	}
+	var root *Root
+	for i := range roots {
+		if roots[i].Did == rootDid && roots[i].ValidAt(r.now()) {
+			root = &roots[i]
+		}
+	}
+	if root == nil {
+		return nil, derrors.New(derrors.CodeInvalidInput, rootDid+" is not a root authority")
+	}
+
+	entries := make([]Entry, 0, len(credentialTypes))
+	for _, credentialType := range credentialTypes {
+		if !root.Covers(credentialType) {
+			return nil, derrors.New(derrors.CodeInvalidInput, rootDid+" cannot accredit "+credentialType)
+		}
+		entry := Entry{
+			IssuerDid:      subject.ID,
+			CredentialType: credentialType,
+			ValidUntil:     int64(exp),
+			AccreditedBy:   rootDid,
+			Accreditation:  jti,
+		}
+		if !vc.ValidFrom.IsZero() {
+			entry.ValidFrom = vc.ValidFrom.Unix()
+		}
+		if root.ValidUntil != 0 && root.ValidUntil < entry.ValidUntil {
+			entry.ValidUntil = root.ValidUntil
+		}
+		entries = append(entries, entry)
+	}
+	for _, entry := range entries {
+		if err := r.store.PutIssuer(ctx, entry); err != nil {
+			return nil, err
+		}
+	}
+	return entries, nil
+}
+
+func accreditedTypes(claim interface{}) ([]string, error) {
+	var credentialTypes []string
+	switch v := claim.(type) {
+	case string:
+		credentialTypes = []string{v}
+	case []interface{}:
+		for _, item := range v {
+			typ, ok := item.(string)
+			if !ok {
+				return nil, derrors.New(derrors.CodeInvalidInput, "invalid "+accreditedForClaim)
+			}
+			credentialTypes = append(credentialTypes, typ)
+		}
+	}
+	if len(credentialTypes) == 0 {
+		return nil, derrors.New(derrors.CodeInvalidInput, "accreditation has no "+accreditedForClaim)
+	}
+	return credentialTypes, nil
+}
@@ -0,0 +1,59 @@
+package trust
+
+import (
+	"byd50-ssi/pkg/did/configs"
+	pb "byd50-ssi/proto-files"
+	"context"
+	"fmt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+	"log"
+	"strings"
+	"sync"
+	"time"
+)
+
+// clientTimeout bounds a trust check made through the core.IssuerTrust interface.
+const clientTimeout = 5 * time.Second
+
+// Client implements core.IssuerTrust against a remote TrustRegistry service. It fails closed:
+// an unreachable registry makes every issuer untrusted.
+type Client struct {
+	pb.TrustRegistryClient
+}
+
+func NewClient(conn grpc.ClientConnInterface) *Client {
+	return &Client{TrustRegistryClient: pb.NewTrustRegistryClient(conn)}
+}
+
+var (
+	onceClient    sync.Once
+	defaultClient *Client
+)
+
+// GetClient returns the client of the TrustRegistry service hosted by the did-registrar.
+// The connection is not blocking, so a registry that is down surfaces on the first call.
+func GetClient() *Client {
+	onceClient.Do(func() {
+		conn, err := grpc.Dial(configs.UseConfig.DidRegistrarAddress, grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			log.Fatalf("did not connect: %v", err)
+		}
+		defaultClient = NewClient(conn)
+	})
+	return defaultClient
+}
+
+func (c *Client) CheckIssuer(issuerDid string, credentialTypes []string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), clientTimeout)
+	defer cancel()
+	reply, err := c.TrustRegistryClient.CheckIssuer(ctx, &pb.CheckIssuerRequest{IssuerDid: issuerDid, CredentialTypes: credentialTypes})
+	if err != nil {
+		return fmt.Errorf("%w: trust registry unavailable: %v", ErrUntrustedIssuer, err)
+	}
+	if !reply.GetTrusted() {
+		// the reason is the error message of the registry, which already starts with ErrUntrustedIssuer
+		return fmt.Errorf("%w: %s", ErrUntrustedIssuer, strings.TrimPrefix(reply.GetReason(), ErrUntrustedIssuer.Error()+": "))
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package trust
+
+import (
+	derrors "byd50-ssi/pkg/did/errors"
+	"context"
+	"encoding/json"
+	"errors"
+	"github.com/syndtr/goleveldb/leveldb"
+	"github.com/syndtr/goleveldb/leveldb/util"
+)
+
+const (
+	issuerKeyPrefix = "trust:issuer:"
+	rootKeyPrefix   = "trust:root:"
+)
+
+// LevelDBStore implements Store using LevelDB. Keys are prefixed, so the database may be shared.
+type LevelDBStore struct {
+	db *leveldb.DB
+}
+
+func NewLevelDBStore(db *leveldb.DB) (*LevelDBStore, error) {
+	if db == nil {
+		return nil, errors.New("leveldb db is nil")
+	}
+	return &LevelDBStore{db: db}, nil
+}
+
+func (s *LevelDBStore) PutIssuer(_ context.Context, entry Entry) error {
+	return s.put(issuerKeyPrefix+issuerKey(entry.IssuerDid, entry.CredentialType), entry)
+}
+
+func (s *LevelDBStore) DeleteIssuer(_ context.Context, issuerDid, credentialType string) error {
+	return s.delete(issuerKeyPrefix+issuerKey(issuerDid, credentialType), "trusted issuer not found: "+issuerDid)
+}
+
+func (s *LevelDBStore) ListIssuers(_ context.Context) ([]Entry, error) {
+	entries := []Entry{}
+	err := s.list(issuerKeyPrefix, func(value []byte) error {
+		var entry Entry
+		if err := json.Unmarshal(value, &entry); err != nil {
+			return err
+		}
+		entries = append(entries, entry)
+		return nil
+	})
+	return entries, err
+}
+
+func (s *LevelDBStore) PutRoot(_ context.Context, root Root) error {
+	return s.put(rootKeyPrefix+root.Did, root)
+}
+
+func (s *LevelDBStore) DeleteRoot(_ context.Context, did string) error {
+	return s.delete(rootKeyPrefix+did, "root authority not found: "+did)
+}
+
+func (s *LevelDBStore) ListRoots(_ context.Context) ([]Root, error) {
+	roots := []Root{}
+	err := s.list(rootKeyPrefix, func(value []byte) error {
+		var root Root
+		if err := json.Unmarshal(value, &root); err != nil {
+			return err
+		}
+		roots = append(roots, root)
+		return nil
+	})
+	return roots, err
+}
+
+func (s *LevelDBStore) Close() error {
+	return s.db.Close()
+}
+
+func (s *LevelDBStore) put(key string, v interface{}) error {
+	value, err := json.Marshal(v)
+	if err != nil {
+		return derrors.Wrap(derrors.CodeInternal, "failed to encode trust registry entry", err)
+	}
+	if err := s.db.Put([]byte(key), value, nil); err != nil {
+		return derrors.Wrap(derrors.CodeInternal, "failed to write trust registry entry", err)
+	}
+	return nil
+}
+
+func (s *LevelDBStore) delete(key, notFound string) error {
+	exists, err := s.db.Has([]byte(key), nil)
+	if err != nil {
+		return derrors.Wrap(derrors.CodeInternal, "trust registry lookup failed", err)
+	}
+	if !exists {
+		return derrors.New(derrors.CodeNotFound, notFound)
+	}
+	if err := s.db.Delete([]byte(key), nil); err != nil {
+		return derrors.Wrap(derrors.CodeInternal, "failed to delete trust registry entry", err)
+	}
+	return nil
+}
+
+// list decodes the values under prefix in key order.
+func (s *LevelDBStore) list(prefix string, decode func([]byte) error) error {
+	iter := s.db.NewIterator(util.BytesPrefix([]byte(prefix)), nil)
+	defer iter.Release()
+	for iter.Next() {
+		if err := decode(iter.Value()); err != nil {
+			return derrors.Wrap(derrors.CodeInternal, "failed to decode trust registry entry", err)
+		}
+	}
+	if err := iter.Error(); err != nil {
+		return derrors.Wrap(derrors.CodeInternal, "trust registry iteration failed", err)
+	}
+	return nil
+}
@@ -0,0 +1,84 @@
+package trust
+
+import (
+	derrors "byd50-ssi/pkg/did/errors"
+	"context"
+	"sort"
+	"sync"
+)
+
+// MemoryStore implements Store in memory.
+type MemoryStore struct {
+	mu      sync.RWMutex
+	issuers map[string]Entry
+	roots   map[string]Root
+}
+
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{issuers: map[string]Entry{}, roots: map[string]Root{}}
+}
+
+func (s *MemoryStore) PutIssuer(_ context.Context, entry Entry) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.issuers[issuerKey(entry.IssuerDid, entry.CredentialType)] = entry
+	return nil
+}
+
+func (s *MemoryStore) DeleteIssuer(_ context.Context, issuerDid, credentialType string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	key := issuerKey(issuerDid, credentialType)
+	if _, ok := s.issuers[key]; !ok {
+		return derrors.New(derrors.CodeNotFound, "trusted issuer not found: "+issuerDid)
+	}
+	delete(s.issuers, key)
+	return nil
+}
+
+func (s *MemoryStore) ListIssuers(_ context.Context) ([]Entry, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	keys := make([]string, 0, len(s.issuers))
+	for key := range s.issuers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	entries := make([]Entry, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, s.issuers[key])
+	}
+	return entries, nil
+}
+
+func (s *MemoryStore) PutRoot(_ context.Context, root Root) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.roots[root.Did] = root
+	return nil
+}
+
+func (s *MemoryStore) DeleteRoot(_ context.Context, did string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.roots[did]; !ok {
+		return derrors.New(derrors.CodeNotFound, "root authority not found: "+did)
+	}
+	delete(s.roots, did)
+	return nil
+}
+
+func (s *MemoryStore) ListRoots(_ context.Context) ([]Root, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	dids := make([]string, 0, len(s.roots))
+	for did := range s.roots {
+		dids = append(dids, did)
+	}
+	sort.Strings(dids)
+	roots := make([]Root, 0, len(dids))
+	for _, did := range dids {
+		roots = append(roots, s.roots[did])
+	}
+	return roots, nil
+}
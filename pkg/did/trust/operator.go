@@ -0,0 +1,67 @@
+package trust
+
+import (
+	pb "byd50-ssi/proto-files"
+	"context"
+	"crypto/subtle"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// OperatorTokenEnv is the environment variable of the token that authorizes the registry operator.
+const OperatorTokenEnv = "TRUST_OPERATOR_TOKEN"
+
+// operatorMetadataKey carries the operator token of a call as "Bearer <token>", like an Authorization header.
+const operatorMetadataKey = "authorization"
+
+// operatorMethods are the registry writes reserved to the operator. Issuers otherwise only enter the registry
+// through Accredit, which is authorized by the signature of a root authority.
+var operatorMethods = map[string]bool{
+	pb.TrustRegistry_AddTrustedIssuer_FullMethodName:    true,
+	pb.TrustRegistry_RemoveTrustedIssuer_FullMethodName: true,
+	pb.TrustRegistry_AddRootAuthority_FullMethodName:    true,
+	pb.TrustRegistry_RemoveRootAuthority_FullMethodName: true,
+}
+
+// OperatorInterceptor rejects the operator writes of the TrustRegistry service unless the call carries token
+// (see OperatorContext). With an empty token these writes are disabled and the registry only changes through Accredit.
+func OperatorInterceptor(token string) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if !operatorMethods[info.FullMethod] {
+			return handler(ctx, req)
+		}
+		if token == "" {
+			return nil, status.Error(codes.PermissionDenied, "trust registry operator writes are disabled")
+		}
+		md, _ := metadata.FromIncomingContext(ctx)
+		var presented string
+		if values := md.Get(operatorMetadataKey); len(values) > 0 {
+			presented, _ = OperatorTokenFromHeader(values[0])
+		}
+		if presented == "" {
+			return nil, status.Error(codes.Unauthenticated, "operator token required")
+		}
+		if subtle.ConstantTimeCompare([]byte(presented), []byte(token)) != 1 {
+			return nil, status.Error(codes.PermissionDenied, "invalid operator token")
+		}
+		return handler(ctx, req)
+	}
+}
+
+// OperatorContext adds the operator token to the metadata of a registry write.
+func OperatorContext(ctx context.Context, token string) context.Context {
+	return metadata.AppendToOutgoingContext(ctx, operatorMetadataKey, "Bearer "+token)
+}
+
+// OperatorTokenFromHeader returns the token of an Authorization header of scheme Bearer.
+func OperatorTokenFromHeader(header string) (string, bool) {
+	scheme, token, found := strings.Cut(header, " ")
+	if !found || token == "" || !strings.EqualFold(scheme, "Bearer") {
+		return "", false
+	}
+	return token, true
+}
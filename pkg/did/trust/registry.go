@@ -0,0 +1,140 @@
+package trust
+
+import (
+	derrors "byd50-ssi/pkg/did/errors"
+	"context"
+	"fmt"
+	"strings"
+	"time"
+)
+
+// Registry answers whether an issuer DID is trusted for a credential type. It implements core.IssuerTrust.
+//
+// An issuer is trusted for a type at a given time when
+//   - it is a root authority valid at that time that covers the type, or
+//   - it has an entry for the type valid at that time, and, when the entry came from an accreditation,
+//     the accrediting root is still registered, valid and covering the type.
+//
+// Removing a root therefore withdraws every accreditation it signed.
+type Registry struct {
+	store    Store
+	getPbKey func(string, string) string
+	now      func() time.Time
+}
+
+// NewRegistry returns a registry backed by store. getPbKey resolves the assertionMethod keys that
+// verify accreditation VCs; it may be nil when accreditations are not used.
+func NewRegistry(store Store, getPbKey func(string, string) string) *Registry {
+	return &Registry{store: store, getPbKey: getPbKey, now: time.Now}
+}
+
+// AddIssuer accepts entry.IssuerDid as an issuer of entry.CredentialType.
+func (r *Registry) AddIssuer(ctx context.Context, entry Entry) error {
+	if err := validateDid(entry.IssuerDid); err != nil {
+		return err
+	}
+	if entry.CredentialType == "" {
+		return derrors.New(derrors.CodeInvalidInput, "credential type is empty")
+	}
+	if err := validateWindow(entry.ValidFrom, entry.ValidUntil); err != nil {
+		return err
+	}
+	return r.store.PutIssuer(ctx, entry)
+}
+
+func (r *Registry) RemoveIssuer(ctx context.Context, issuerDid, credentialType string) error {
+	return r.store.DeleteIssuer(ctx, issuerDid, credentialType)
+}
+
+// Issuers lists the trusted issuer entries, optionally only those of credentialType.
+func (r *Registry) Issuers(ctx context.Context, credentialType string) ([]Entry, error) {
+	entries, err := r.store.ListIssuers(ctx)
+	if err != nil || credentialType == "" {
+		return entries, err
+	}
+	matched := []Entry{}
+	for _, entry := range entries {
+		if entry.CredentialType == credentialType {
+			matched = append(matched, entry)
+		}
+	}
+	return matched, nil
+}
+
+// AddRoot registers a root authority.
+func (r *Registry) AddRoot(ctx context.Context, root Root) error {
+	if err := validateDid(root.Did); err != nil {
+		return err
+	}
+	if err := validateWindow(root.ValidFrom, root.ValidUntil); err != nil {
+		return err
+	}
+	return r.store.PutRoot(ctx, root)
+}
+
+func (r *Registry) RemoveRoot(ctx context.Context, did string) error {
+	return r.store.DeleteRoot(ctx, did)
+}
+
+func (r *Registry) Roots(ctx context.Context) ([]Root, error) {
+	return r.store.ListRoots(ctx)
+}
+
+// Check returns nil when issuerDid is trusted for credentialType now, and an error wrapping
+// ErrUntrustedIssuer when it is not.
+func (r *Registry) Check(ctx context.Context, issuerDid, credentialType string) error {
+	now := r.now()
+	roots, err := r.store.ListRoots(ctx)
+	if err != nil {
+		return err
+	}
+	rootFor := func(did string) bool {
+		for _, root := range roots {
+			if root.Did == did && root.ValidAt(now) && root.Covers(credentialType) {
+				return true
+			}
+		}
+		return false
+	}
+	if rootFor(issuerDid) {
+		return nil
+	}
+
+	entries, err := r.store.ListIssuers(ctx)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if entry.IssuerDid != issuerDid || entry.CredentialType != credentialType || !entry.ValidAt(now) {
+			continue
+		}
+		if entry.AccreditedBy == "" || rootFor(entry.AccreditedBy) {
+			return nil
+		}
+	}
+	return fmt.Errorf("%w: %s for %s", ErrUntrustedIssuer, issuerDid, credentialType)
+}
+
+// CheckIssuer implements core.IssuerTrust: issuerDid must be trusted for every credential type.
+func (r *Registry) CheckIssuer(issuerDid string, credentialTypes []string) error {
+	for _, credentialType := range credentialTypes {
+		if err := r.Check(context.Background(), issuerDid, credentialType); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func validateDid(did string) error {
+	if !strings.HasPrefix(did, "did:") {
+		return derrors.New(derrors.CodeInvalidInput, "invalid did: "+did)
+	}
+	return nil
+}
+
+func validateWindow(from, until int64) error {
+	if from != 0 && until != 0 && until <= from {
+		return derrors.New(derrors.CodeInvalidInput, "validUntil must be after validFrom")
+	}
+	return nil
+}
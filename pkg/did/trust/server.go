@@ -0,0 +1,161 @@
+package trust
+
+import (
+	derrors "byd50-ssi/pkg/did/errors"
+	pb "byd50-ssi/proto-files"
+	"context"
+	"errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// Server exposes a Registry as the TrustRegistry gRPC service.
+type Server struct {
+	pb.UnimplementedTrustRegistryServer
+	registry *Registry
+}
+
+func NewServer(registry *Registry) *Server {
+	return &Server{registry: registry}
+}
+
+func (s *Server) AddTrustedIssuer(ctx context.Context, in *pb.AddTrustedIssuerRequest) (*pb.TrustRegistryResult, error) {
+	if in.GetIssuer() == nil {
+		return nil, status.Error(codes.InvalidArgument, "issuer is required")
+	}
+	entry := EntryFromProto(in.GetIssuer())
+	// accreditations are only added through Accredit
+	entry.AccreditedBy, entry.Accreditation = "", ""
+	if err := s.registry.AddIssuer(ctx, entry); err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.TrustRegistryResult{Result: "success"}, nil
+}
+
+func (s *Server) RemoveTrustedIssuer(ctx context.Context, in *pb.RemoveTrustedIssuerRequest) (*pb.TrustRegistryResult, error) {
+	if err := s.registry.RemoveIssuer(ctx, in.GetIssuerDid(), in.GetCredentialType()); err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.TrustRegistryResult{Result: "success"}, nil
+}
+
+func (s *Server) ListTrustedIssuers(ctx context.Context, in *pb.ListTrustedIssuersRequest) (*pb.ListTrustedIssuersResponse, error) {
+	entries, err := s.registry.Issuers(ctx, in.GetCredentialType())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.ListTrustedIssuersResponse{Issuers: entriesToProto(entries)}, nil
+}
+
+func (s *Server) AddRootAuthority(ctx context.Context, in *pb.AddRootAuthorityRequest) (*pb.TrustRegistryResult, error) {
+	if in.GetRoot() == nil {
+		return nil, status.Error(codes.InvalidArgument, "root is required")
+	}
+	if err := s.registry.AddRoot(ctx, RootFromProto(in.GetRoot())); err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.TrustRegistryResult{Result: "success"}, nil
+}
+
+func (s *Server) RemoveRootAuthority(ctx context.Context, in *pb.RemoveRootAuthorityRequest) (*pb.TrustRegistryResult, error) {
+	if err := s.registry.RemoveRoot(ctx, in.GetDid()); err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.TrustRegistryResult{Result: "success"}, nil
+}
+
+func (s *Server) ListRootAuthorities(ctx context.Context, _ *pb.ListRootAuthoritiesRequest) (*pb.ListRootAuthoritiesResponse, error) {
+	roots, err := s.registry.Roots(ctx)
+	if err != nil {
+		return nil, statusError(err)
+	}
+	out := make([]*pb.RootAuthority, 0, len(roots))
+	for _, root := range roots {
+		out = append(out, RootToProto(root))
+	}
+	return &pb.ListRootAuthoritiesResponse{Roots: out}, nil
+}
+
+func (s *Server) Accredit(ctx context.Context, in *pb.AccreditRequest) (*pb.AccreditResponse, error) {
+	entries, err := s.registry.Accredit(ctx, in.GetAccreditationVcJwt())
+	if err != nil {
+		return nil, statusError(err)
+	}
+	return &pb.AccreditResponse{Issuers: entriesToProto(entries)}, nil
+}
+
+func (s *Server) CheckIssuer(ctx context.Context, in *pb.CheckIssuerRequest) (*pb.CheckIssuerResponse, error) {
+	if in.GetIssuerDid() == "" || len(in.GetCredentialTypes()) == 0 {
+		return nil, status.Error(codes.InvalidArgument, "issuer_did and credential_types are required")
+	}
+	for _, credentialType := range in.GetCredentialTypes() {
+		if err := s.registry.Check(ctx, in.GetIssuerDid(), credentialType); err != nil {
+			if errors.Is(err, ErrUntrustedIssuer) {
+				return &pb.CheckIssuerResponse{Trusted: false, Reason: err.Error()}, nil
+			}
+			return nil, statusError(err)
+		}
+	}
+	return &pb.CheckIssuerResponse{Trusted: true}, nil
+}
+
+func statusError(err error) error {
+	var dErr *derrors.Error
+	if errors.As(err, &dErr) {
+		switch dErr.Code() {
+		case derrors.CodeNotFound:
+			return status.Error(codes.NotFound, err.Error())
+		case derrors.CodeInvalidInput, derrors.CodeEmptyKey, derrors.CodeInvalidKey:
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+	}
+	return status.Error(codes.Internal, err.Error())
+}
+
+func EntryFromProto(in *pb.TrustedIssuer) Entry {
+	return Entry{
+		IssuerDid:      in.GetIssuerDid(),
+		CredentialType: in.GetCredentialType(),
+		ValidFrom:      in.GetValidFrom(),
+		ValidUntil:     in.GetValidUntil(),
+		AccreditedBy:   in.GetAccreditedBy(),
+		Accreditation:  in.GetAccreditation(),
+	}
+}
+
+func EntryToProto(entry Entry) *pb.TrustedIssuer {
+	return &pb.TrustedIssuer{
+		IssuerDid:      entry.IssuerDid,
+		CredentialType: entry.CredentialType,
+		ValidFrom:      entry.ValidFrom,
+		ValidUntil:     entry.ValidUntil,
+		AccreditedBy:   entry.AccreditedBy,
+		Accreditation:  entry.Accreditation,
+	}
+}
+
+func RootFromProto(in *pb.RootAuthority) Root {
+	return Root{
+		Did:             in.GetDid(),
+		CredentialTypes: in.GetCredentialTypes(),
+		ValidFrom:       in.GetValidFrom(),
+		ValidUntil:      in.GetValidUntil(),
+	}
+}
+
+func RootToProto(root Root) *pb.RootAuthority {
+	return &pb.RootAuthority{
+		Did:             root.Did,
+		CredentialTypes: root.CredentialTypes,
+		ValidFrom:       root.ValidFrom,
+		ValidUntil:      root.ValidUntil,
+	}
+}
+
+func entriesToProto(entries []Entry) []*pb.TrustedIssuer {
+	out := make([]*pb.TrustedIssuer, 0, len(entries))
+	for _, entry := range entries {
+		out = append(out, EntryToProto(entry))
+	}
+	return out
+}
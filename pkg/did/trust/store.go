@@ -0,0 +1,21 @@
+package trust
+
+import (
+	"context"
+)
+
+// Store defines persistence of the trust registry.
+type Store interface {
+	// PutIssuer stores the entry, replacing the one with the same issuer DID and credential type.
+	PutIssuer(ctx context.Context, entry Entry) error
+	DeleteIssuer(ctx context.Context, issuerDid, credentialType string) error
+	ListIssuers(ctx context.Context) ([]Entry, error)
+	// PutRoot stores the root authority, replacing the one with the same DID.
+	PutRoot(ctx context.Context, root Root) error
+	DeleteRoot(ctx context.Context, did string) error
+	ListRoots(ctx context.Context) ([]Root, error)
+}
+
+func issuerKey(issuerDid, credentialType string) string {
+	return issuerDid + "\x00" + credentialType
+}
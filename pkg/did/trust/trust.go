@@ -0,0 +1,63 @@
+// Package trust implements a trust registry for verifiers: it maps credential types to the issuer DIDs
+// accepted for them. Entries are added directly by the registry operator or through accreditation VCs
+// signed by a root authority, and may be limited to a validity window.
+package trust
+
+import (
+	"errors"
+	"time"
+)
+
+// AccreditationCredentialType is the type of the VC through which a root authority accredits an issuer.
+const AccreditationCredentialType = "AccreditationCredential"
+
+// ErrUntrustedIssuer is returned when an issuer is not trusted for a credential type.
+var ErrUntrustedIssuer = errors.New("issuer is not trusted")
+
+// Entry accepts IssuerDid as an issuer of CredentialType. ValidFrom and ValidUntil are unix seconds, 0 means open.
+type Entry struct {
+	IssuerDid      string `json:"issuerDid"`
+	CredentialType string `json:"credentialType"`
+	ValidFrom      int64  `json:"validFrom,omitempty"`
+	ValidUntil     int64  `json:"validUntil,omitempty"`
+	// AccreditedBy is the root authority whose accreditation VC added the entry; empty for direct entries.
+	AccreditedBy string `json:"accreditedBy,omitempty"`
+	// Accreditation is the jti of that accreditation VC.
+	Accreditation string `json:"accreditation,omitempty"`
+}
+
+// ValidAt reports whether t lies in the validity window of the entry.
+func (e Entry) ValidAt(t time.Time) bool {
+	return validAt(e.ValidFrom, e.ValidUntil, t)
+}
+
+// Root is a root authority. It is trusted for, and may accredit issuers of, CredentialTypes (any type when empty).
+type Root struct {
+	Did             string   `json:"did"`
+	CredentialTypes []string `json:"credentialTypes,omitempty"`
+	ValidFrom       int64    `json:"validFrom,omitempty"`
+	ValidUntil      int64    `json:"validUntil,omitempty"`
+}
+
+// ValidAt reports whether t lies in the validity window of the root.
+func (r Root) ValidAt(t time.Time) bool {
+	return validAt(r.ValidFrom, r.ValidUntil, t)
+}
+
+// Covers reports whether the root is an authority for credentialType.
+func (r Root) Covers(credentialType string) bool {
+	if len(r.CredentialTypes) == 0 {
+		return true
+	}
+	for _, typ := range r.CredentialTypes {
+		if typ == credentialType {
+			return true
+		}
+	}
+	return false
+}
+
+func validAt(from, until int64, t time.Time) bool {
+	now := t.Unix()
+	return (from == 0 || now >= from) && (until == 0 || now < until)
+}
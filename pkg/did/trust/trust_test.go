@@ -0,0 +1,357 @@
+package trust_test
+
+import (
+	"byd50-ssi/pkg/did/core"
+	byd50_jwt "byd50-ssi/pkg/did/core/byd50-jwt"
+	"byd50-ssi/pkg/did/pkg/database"
+	"byd50-ssi/pkg/did/trust"
+	pb "byd50-ssi/proto-files"
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"net"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/golang-jwt/jwt"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+)
+
+const (
+	rootDid     = "did:byd50:root"
+	issuerDid   = "did:byd50:issuer"
+	licenceType = "eDriver'sLicenceCardCredential"
+)
+
+type testKeys struct {
+	pvKeys map[string]*ecdsa.PrivateKey
+	pbKeys map[string]string
+}
+
+func newTestKeys(t *testing.T, dids ...string) *testKeys {
+	t.Helper()
+	k := &testKeys{pvKeys: map[string]*ecdsa.PrivateKey{}, pbKeys: map[string]string{}}
+	for _, did := range dids {
+		pvKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+		if err != nil {
+			t.Fatal(err)
+		}
+		pbBytes, err := x509.MarshalPKIXPublicKey(&pvKey.PublicKey)
+		if err != nil {
+			t.Fatal(err)
+		}
+		k.pvKeys[did] = pvKey
+		k.pbKeys[did] = base58.Encode(pbBytes)
+	}
+	return k
+}
+
+func (k *testKeys) getPbKey(did, _ string) string {
+	return k.pbKeys[did]
+}
+
+func TestDirectEntries(t *testing.T) {
+	ctx := context.Background()
+	registry := trust.NewRegistry(trust.NewMemoryStore(), nil)
+
+	if err := registry.Check(ctx, issuerDid, licenceType); !errors.Is(err, trust.ErrUntrustedIssuer) {
+		t.Fatalf("expected untrusted issuer, got %v", err)
+	}
+	if err := registry.AddIssuer(ctx, trust.Entry{IssuerDid: issuerDid, CredentialType: licenceType}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.CheckIssuer(issuerDid, []string{licenceType}); err != nil {
+		t.Fatalf("expected trusted issuer: %v", err)
+	}
+	if err := registry.CheckIssuer(issuerDid, []string{licenceType, "OtherCredential"}); err == nil {
+		t.Fatal("expected issuer to be untrusted for another type")
+	}
+
+	expired := trust.Entry{
+		IssuerDid:      "did:byd50:expired",
+		CredentialType: licenceType,
+		ValidUntil:     time.Now().Add(-time.Minute).Unix(),
+	}
+	if err := registry.AddIssuer(ctx, expired); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Check(ctx, expired.IssuerDid, licenceType); err == nil {
+		t.Fatal("expected expired entry to be untrusted")
+	}
+	notYet := trust.Entry{
+		IssuerDid:      "did:byd50:future",
+		CredentialType: licenceType,
+		ValidFrom:      time.Now().Add(time.Hour).Unix(),
+	}
+	if err := registry.AddIssuer(ctx, notYet); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Check(ctx, notYet.IssuerDid, licenceType); err == nil {
+		t.Fatal("expected entry not yet valid to be untrusted")
+	}
+
+	if err := registry.AddIssuer(ctx, trust.Entry{IssuerDid: "not-a-did", CredentialType: licenceType}); err == nil {
+		t.Fatal("expected invalid did to be rejected")
+	}
+	if err := registry.AddIssuer(ctx, trust.Entry{IssuerDid: issuerDid, CredentialType: licenceType, ValidFrom: 20, ValidUntil: 10}); err == nil {
+		t.Fatal("expected inverted window to be rejected")
+	}
+
+	if err := registry.RemoveIssuer(ctx, issuerDid, licenceType); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Check(ctx, issuerDid, licenceType); err == nil {
+		t.Fatal("expected removed issuer to be untrusted")
+	}
+	if err := registry.RemoveIssuer(ctx, issuerDid, licenceType); err == nil {
+		t.Fatal("expected removing a missing entry to fail")
+	}
+}
+
+func TestAccreditation(t *testing.T) {
+	ctx := context.Background()
+	keys := newTestKeys(t, rootDid, issuerDid, "did:byd50:other-root")
+	registry := trust.NewRegistry(trust.NewMemoryStore(), keys.getPbKey)
+
+	accreditation, err := trust.IssueAccreditation(rootDid, issuerDid, []string{licenceType}, time.Hour, keys.pvKeys[rootDid])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.Accredit(ctx, accreditation); err == nil {
+		t.Fatal("expected accreditation by an unregistered root to fail")
+	}
+
+	if err := registry.AddRoot(ctx, trust.Root{Did: rootDid, CredentialTypes: []string{licenceType}}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Check(ctx, rootDid, licenceType); err != nil {
+		t.Fatalf("expected root to be trusted for its types: %v", err)
+	}
+	if err := registry.Check(ctx, rootDid, "OtherCredential"); err == nil {
+		t.Fatal("expected root to be untrusted outside its types")
+	}
+
+	entries, err := registry.Accredit(ctx, accreditation)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(entries) != 1 || entries[0].AccreditedBy != rootDid || entries[0].ValidUntil == 0 || entries[0].Accreditation == "" {
+		t.Fatalf("unexpected entries: %+v", entries)
+	}
+	if err := registry.Check(ctx, issuerDid, licenceType); err != nil {
+		t.Fatalf("expected accredited issuer to be trusted: %v", err)
+	}
+
+	// a root signing with a DID URL kid accredits as its DID
+	urlAccreditation, err := trust.IssueAccreditation(rootDid, "did:byd50:url-issuer", []string{licenceType}, time.Hour, keys.pvKeys[rootDid])
+	if err != nil {
+		t.Fatal(err)
+	}
+	token, _, err := new(jwt.Parser).ParseUnverified(urlAccreditation, jwt.MapClaims{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	urlAccreditation, err = byd50_jwt.Sign(rootDid+"#keys-1", "", token.Claims, keys.pvKeys[rootDid])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if entries, err := registry.Accredit(ctx, urlAccreditation); err != nil || entries[0].AccreditedBy != rootDid {
+		t.Fatalf("expected accreditation with a did url kid, got %+v: %v", entries, err)
+	}
+
+	// a root cannot accredit types it is not an authority for
+	outOfScope, err := trust.IssueAccreditation(rootDid, issuerDid, []string{"OtherCredential"}, time.Hour, keys.pvKeys[rootDid])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.Accredit(ctx, outOfScope); err == nil {
+		t.Fatal("expected accreditation outside the root's types to fail")
+	}
+
+	// an accreditation signed by an issuer, not a root, is rejected
+	selfAccredited, err := trust.IssueAccreditation(issuerDid, "did:byd50:other", []string{licenceType}, time.Hour, keys.pvKeys[issuerDid])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := registry.Accredit(ctx, selfAccredited); err == nil {
+		t.Fatal("expected accreditation by a non-root to fail")
+	}
+
+	// an accreditation whose signature does not verify is rejected
+	forged, err := trust.IssueAccreditation("did:byd50:other-root", "did:byd50:other", []string{licenceType}, time.Hour, keys.pvKeys["did:byd50:other-root"])
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.AddRoot(ctx, trust.Root{Did: "did:byd50:other-root"}); err != nil {
+		t.Fatal(err)
+	}
+	keys.pbKeys["did:byd50:other-root"] = keys.pbKeys[rootDid]
+	if _, err := registry.Accredit(ctx, forged); err == nil {
+		t.Fatal("expected accreditation verified with another key to fail")
+	}
+
+	// removing the root withdraws its accreditations
+	if err := registry.RemoveRoot(ctx, rootDid); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Check(ctx, issuerDid, licenceType); !errors.Is(err, trust.ErrUntrustedIssuer) {
+		t.Fatalf("expected accreditation to be withdrawn, got %v", err)
+	}
+}
+
+func TestLevelDBStore(t *testing.T) {
+	ctx := context.Background()
+	db, err := database.InitializePath(filepath.Join(t.TempDir(), "trust.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := trust.NewLevelDBStore(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	registry := trust.NewRegistry(store, nil)
+	if err := registry.AddIssuer(ctx, trust.Entry{IssuerDid: issuerDid, CredentialType: licenceType}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.AddIssuer(ctx, trust.Entry{IssuerDid: issuerDid, CredentialType: "OtherCredential"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.AddRoot(ctx, trust.Root{Did: rootDid}); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := registry.Issuers(ctx, licenceType)
+	if err != nil || len(entries) != 1 || entries[0].IssuerDid != issuerDid {
+		t.Fatalf("unexpected entries: %+v, %v", entries, err)
+	}
+	roots, err := registry.Roots(ctx)
+	if err != nil || len(roots) != 1 || roots[0].Did != rootDid {
+		t.Fatalf("unexpected roots: %+v, %v", roots, err)
+	}
+	if err := registry.Check(ctx, issuerDid, licenceType); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.RemoveIssuer(ctx, issuerDid, licenceType); err != nil {
+		t.Fatal(err)
+	}
+	if err := registry.Check(ctx, issuerDid, licenceType); err == nil {
+		t.Fatal("expected removed issuer to be untrusted")
+	}
+	if err := registry.RemoveRoot(ctx, "did:byd50:missing"); err == nil {
+		t.Fatal("expected removing a missing root to fail")
+	}
+}
+
+func TestGrpcClientAndVerifyOptions(t *testing.T) {
+	keys := newTestKeys(t, issuerDid, "did:byd50:untrusted", "did:byd50:holder")
+	registry := trust.NewRegistry(trust.NewMemoryStore(), nil)
+	if err := registry.AddIssuer(context.Background(), trust.Entry{IssuerDid: issuerDid, CredentialType: licenceType}); err != nil {
+		t.Fatal(err)
+	}
+
+	lis := bufconn.Listen(1 << 20)
+	s := grpc.NewServer()
+	pb.RegisterTrustRegistryServer(s, trust.NewServer(registry))
+	go func() { _ = s.Serve(lis) }()
+	defer s.Stop()
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+		grpc.WithTransportCredentials(insecure.NewCredentials()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	client := trust.NewClient(conn)
+
+	issue := func(issuer string) string {
+		vcJwt := core.CreateVc(issuer, licenceType, map[string]interface{}{"id": "did:byd50:holder"},
+			newStandardClaims(issuer, "did:byd50:holder"), keys.pvKeys[issuer])
+		return core.CreateVp("did:byd50:holder", "VerifiablePresentation", []string{vcJwt},
+			newStandardClaims("did:byd50:holder", ""), keys.pvKeys["did:byd50:holder"])
+	}
+	options := core.VerifyOptions{Trust: client}
+
+	if ok, _, err := core.VerifyVpWithOptions(issue(issuerDid), keys.getPbKey, keys.getPbKey, options); !ok || err != nil {
+		t.Fatalf("expected licence of a trusted issuer to verify: %v", err)
+	}
+	ok, _, err := core.VerifyVpWithOptions(issue("did:byd50:untrusted"), keys.getPbKey, keys.getPbKey, options)
+	if ok || !errors.Is(err, trust.ErrUntrustedIssuer) {
+		t.Fatalf("expected untrusted licence to be rejected, got %v, %v", ok, err)
+	}
+
+	// the registry being unreachable makes every issuer untrusted
+	s.Stop()
+	if err := client.CheckIssuer(issuerDid, []string{licenceType}); !errors.Is(err, trust.ErrUntrustedIssuer) {
+		t.Fatalf("expected fail closed, got %v", err)
+	}
+}
+
+func TestOperatorInterceptor(t *testing.T) {
+	dial := func(t *testing.T, token string) *trust.Client {
+		lis := bufconn.Listen(1 << 20)
+		s := grpc.NewServer(grpc.UnaryInterceptor(trust.OperatorInterceptor(token)))
+		pb.RegisterTrustRegistryServer(s, trust.NewServer(trust.NewRegistry(trust.NewMemoryStore(), nil)))
+		go func() { _ = s.Serve(lis) }()
+		t.Cleanup(s.Stop)
+		conn, err := grpc.NewClient("passthrough:///bufnet",
+			grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) { return lis.DialContext(ctx) }),
+			grpc.WithTransportCredentials(insecure.NewCredentials()))
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = conn.Close() })
+		return trust.NewClient(conn)
+	}
+	add := func(ctx context.Context, client *trust.Client) error {
+		_, err := client.AddTrustedIssuer(ctx, &pb.AddTrustedIssuerRequest{
+			Issuer: &pb.TrustedIssuer{IssuerDid: issuerDid, CredentialType: licenceType},
+		})
+		return err
+	}
+	ctx := context.Background()
+
+	client := dial(t, "operator-secret")
+	if err := add(ctx, client); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected a write without operator token to be rejected, got %v", err)
+	}
+	if err := add(trust.OperatorContext(ctx, "guess"), client); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected a write with a wrong operator token to be rejected, got %v", err)
+	}
+	if _, err := client.AddRootAuthority(ctx, &pb.AddRootAuthorityRequest{Root: &pb.RootAuthority{Did: rootDid}}); status.Code(err) != codes.Unauthenticated {
+		t.Fatalf("expected a root authority write without operator token to be rejected, got %v", err)
+	}
+	if err := client.CheckIssuer(issuerDid, []string{licenceType}); !errors.Is(err, trust.ErrUntrustedIssuer) {
+		t.Fatalf("expected rejected writes to leave the registry unchanged, got %v", err)
+	}
+	if err := add(trust.OperatorContext(ctx, "operator-secret"), client); err != nil {
+		t.Fatal(err)
+	}
+	if err := client.CheckIssuer(issuerDid, []string{licenceType}); err != nil {
+		t.Fatalf("expected the operator entry to be trusted: %v", err)
+	}
+
+	// without a configured token the operator writes are disabled
+	if err := add(trust.OperatorContext(ctx, ""), dial(t, "")); status.Code(err) != codes.PermissionDenied {
+		t.Fatalf("expected operator writes to be disabled, got %v", err)
+	}
+}
+
+func newStandardClaims(issuer, subject string) jwt.StandardClaims {
+	return jwt.StandardClaims{
+		ExpiresAt: time.Now().Add(time.Minute).Unix(),
+		IssuedAt:  time.Now().Unix(),
+		Issuer:    issuer,
+		Subject:   subject,
+	}
+}
@@ -0,0 +1,110 @@
+package keys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/sha512"
+	_ "embed"
+	"errors"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"golang.org/x/crypto/pbkdf2"
+	"golang.org/x/text/unicode/norm"
+)
+
+// bip39English is the BIP-39 English wordlist, one word per line.
+//
+//go:embed bip39_english.txt
+var bip39English string
+
+var (
+	bip39Words   = strings.Fields(bip39English)
+	bip39Indexes = func() map[string]int {
+		indexes := make(map[string]int, len(bip39Words))
+		for i, word := range bip39Words {
+			indexes[word] = i
+		}
+		return indexes
+	}()
+)
+
+// NewMnemonic generates a BIP-39 mnemonic of bits entropy: 128 (12 words) to 256 (24 words) in steps of 32.
+func NewMnemonic(bits int) (string, error) {
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return "", fmt.Errorf("invalid mnemonic entropy size: %d bits", bits)
+	}
+	entropy := make([]byte, bits/8)
+	if _, err := rand.Read(entropy); err != nil {
+		return "", err
+	}
+	return MnemonicFromEntropy(entropy)
+}
+
+// MnemonicFromEntropy encodes 16 to 32 bytes of entropy as a BIP-39 mnemonic.
+func MnemonicFromEntropy(entropy []byte) (string, error) {
+	bits := len(entropy) * 8
+	if bits < 128 || bits > 256 || bits%32 != 0 {
+		return "", fmt.Errorf("invalid mnemonic entropy size: %d bits", bits)
+	}
+	checksumBits := bits / 32
+	hash := sha256.Sum256(entropy)
+	// entropy || first checksumBits of its SHA-256, split into groups of 11 bits
+	value := new(big.Int).SetBytes(entropy)
+	value.Lsh(value, uint(checksumBits))
+	value.Or(value, big.NewInt(int64(hash[0]>>(8-checksumBits))))
+
+	count := (bits + checksumBits) / 11
+	words := make([]string, count)
+	mask := big.NewInt(2047)
+	for i := count - 1; i >= 0; i-- {
+		words[i] = bip39Words[new(big.Int).And(value, mask).Int64()]
+		value.Rsh(value, 11)
+	}
+	return strings.Join(words, " "), nil
+}
+
+// MnemonicToEntropy decodes a BIP-39 mnemonic and verifies its checksum.
+func MnemonicToEntropy(mnemonic string) ([]byte, error) {
+	words := strings.Fields(norm.NFKD.String(mnemonic))
+	if len(words) < 12 || len(words) > 24 || len(words)%3 != 0 {
+		return nil, fmt.Errorf("invalid mnemonic length: %d words", len(words))
+	}
+	value := new(big.Int)
+	for _, word := range words {
+		index, ok := bip39Indexes[word]
+		if !ok {
+			return nil, fmt.Errorf("invalid mnemonic word: %q", word)
+		}
+		value.Lsh(value, 11)
+		value.Or(value, big.NewInt(int64(index)))
+	}
+	checksumBits := len(words) * 11 / 33
+	checksum := new(big.Int).And(value, big.NewInt(1<<checksumBits-1)).Int64()
+	value.Rsh(value, uint(checksumBits))
+
+	entropy := value.FillBytes(make([]byte, checksumBits*4))
+	hash := sha256.Sum256(entropy)
+	if int64(hash[0]>>(8-checksumBits)) != checksum {
+		return nil, errors.New("invalid mnemonic checksum")
+	}
+	return entropy, nil
+}
+
+// ValidateMnemonic reports whether mnemonic is a BIP-39 mnemonic with a valid checksum.
+func ValidateMnemonic(mnemonic string) error {
+	_, err := MnemonicToEntropy(mnemonic)
+	return err
+}
+
+// MnemonicToSeed validates mnemonic and derives its 64-byte BIP-39 seed, protected by an optional passphrase.
+// The seed is the input of NewMasterKey.
+func MnemonicToSeed(mnemonic, passphrase string) ([]byte, error) {
+	if err := ValidateMnemonic(mnemonic); err != nil {
+		return nil, err
+	}
+	normalized := strings.Join(strings.Fields(norm.NFKD.String(mnemonic)), " ")
+	salt := "mnemonic" + norm.NFKD.String(passphrase)
+	return pbkdf2.Key([]byte(normalized), []byte(salt), 2048, 64, sha512.New), nil
+}
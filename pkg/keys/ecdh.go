@@ -0,0 +1,80 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"errors"
+)
+
+// SharedSecret computes the ECDH shared secret Z of a private key and a peer public key on the same curve: the
+// x-coordinate of the shared point for P-256, P-384 and secp256k1 keys, and the X25519 function output for X25519
+// keys. EC keys may be given as *ecdsa or *ecdh keys.
+func SharedSecret(privateKey crypto.PrivateKey, peer crypto.PublicKey) ([]byte, error) {
+	if pvKey, ok := privateKey.(*ecdsa.PrivateKey); ok && IsSecp256k1(pvKey.Curve) {
+		pbKey, ok := peer.(*ecdsa.PublicKey)
+		if !ok || !IsSecp256k1(pbKey.Curve) || !pvKey.Curve.IsOnCurve(pbKey.X, pbKey.Y) {
+			return nil, errors.New("peer key is not a secp256k1 key")
+		}
+		x, _ := pvKey.Curve.ScalarMult(pbKey.X, pbKey.Y, pvKey.D.FillBytes(make([]byte, 32)))
+		if x.Sign() == 0 {
+			return nil, errors.New("invalid shared secret")
+		}
+		return x.FillBytes(make([]byte, 32)), nil
+	}
+
+	var pvKey *ecdh.PrivateKey
+	switch v := privateKey.(type) {
+	case *ecdh.PrivateKey:
+		pvKey = v
+	case *ecdsa.PrivateKey:
+		var err error
+		if pvKey, err = v.ECDH(); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, errors.New("unsupported key type for key agreement")
+	}
+	pbKey, err := ecdhPublicKey(peer)
+	if err != nil {
+		return nil, err
+	}
+	if pbKey.Curve() != pvKey.Curve() {
+		return nil, errors.New("peer key is on a different curve")
+	}
+	return pvKey.ECDH(pbKey)
+}
+
+// GenerateKeyAgreementKey generates an ephemeral key pair on the curve of publicKey, for key agreement with it.
+func GenerateKeyAgreementKey(publicKey crypto.PublicKey) (crypto.PrivateKey, crypto.PublicKey, error) {
+	switch v := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		switch {
+		case IsSecp256k1(v.Curve):
+			return GenerateSecp256k1KeyPair()
+		case v.Curve == elliptic.P256(), v.Curve == elliptic.P384():
+			privateKey, err := ecdsa.GenerateKey(v.Curve, rand.Reader)
+			if err != nil {
+				return nil, nil, err
+			}
+			return privateKey, &privateKey.PublicKey, nil
+		}
+	case *ecdh.PublicKey:
+		if v.Curve() == ecdh.X25519() {
+			return GenerateX25519KeyPair()
+		}
+	}
+	return nil, nil, errors.New("unsupported key type for key agreement")
+}
+
+func ecdhPublicKey(publicKey crypto.PublicKey) (*ecdh.PublicKey, error) {
+	switch v := publicKey.(type) {
+	case *ecdh.PublicKey:
+		return v, nil
+	case *ecdsa.PublicKey:
+		return v.ECDH()
+	}
+	return nil, errors.New("unsupported key type for key agreement")
+}
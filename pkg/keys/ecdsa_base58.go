@@ -6,10 +6,14 @@ import (
 	"log"
 
 	"github.com/btcsuite/btcutil/base58"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
 )
 
 // ExportECDSAPrivateKeyAsBase58 : Exports a ecdsa.PrivateKey as Base58.
 func ExportECDSAPrivateKeyAsBase58(privateKey *ecdsa.PrivateKey) string {
+	if privateKey != nil && IsSecp256k1(privateKey.Curve) {
+		return base58.Encode(ethcrypto.FromECDSA(privateKey))
+	}
 	privateKeyBytes, err := x509.MarshalECPrivateKey(privateKey)
 	if err != nil {
 		log.Printf("error occured: %v", err.Error())
@@ -20,6 +24,9 @@ func ExportECDSAPrivateKeyAsBase58(privateKey *ecdsa.PrivateKey) string {
 
 // ExportECDSAPublicKeyAsBase58 : Exports a ecdsa.PublicKey as Base58.
 func ExportECDSAPublicKeyAsBase58(publicKey *ecdsa.PublicKey) string {
+	if publicKey != nil && IsSecp256k1(publicKey.Curve) {
+		return base58.Encode(ethcrypto.CompressPubkey(publicKey))
+	}
 	publicKeyBytes, err := x509.MarshalPKIXPublicKey(publicKey)
 	if err != nil {
 		log.Printf("error occured: %v", err.Error())
@@ -0,0 +1,15 @@
+package keys
+
+import (
+	"crypto/ed25519"
+	"crypto/rand"
+)
+
+// GenerateEd25519KeyPair generates a new Ed25519 key pair.
+func GenerateEd25519KeyPair() (ed25519.PrivateKey, ed25519.PublicKey, error) {
+	publicKey, privateKey, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return privateKey, publicKey, nil
+}
@@ -0,0 +1,37 @@
+package keys
+
+import (
+	"crypto/ed25519"
+	"crypto/x509"
+	"encoding/pem"
+)
+
+// ExportEd25519PrivateKeyAsPEM : Exports a ed25519.PrivateKey as PKCS#8 PEM.
+func ExportEd25519PrivateKeyAsPEM(privateKey ed25519.PrivateKey) (string, error) {
+	privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(privateKey)
+	if err != nil {
+		return "", err
+	}
+	privateKeyPEM := pem.EncodeToMemory(
+		&pem.Block{
+			Type:  "PRIVATE KEY",
+			Bytes: privateKeyBytes,
+		},
+	)
+	return string(privateKeyPEM), nil
+}
+
+// ExportEd25519PublicKeyAsPEM : Exports a ed25519.PublicKey as PEM.
+func ExportEd25519PublicKeyAsPEM(publicKey ed25519.PublicKey) (string, error) {
+	publicKeyBytes, err := x509.MarshalPKIXPublicKey(publicKey)
+	if err != nil {
+		return "", err
+	}
+	publicKeyPEM := pem.EncodeToMemory(
+		&pem.Block{
+			Type:  "PUBLIC KEY",
+			Bytes: publicKeyBytes,
+		},
+	)
+	return string(publicKeyPEM), nil
+}
@@ -0,0 +1,246 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// Curves of HD keys, named as in JWK crv.
+const (
+	CurveSecp256k1 = "secp256k1"
+	CurveP256      = "P-256"
+	CurveEd25519   = "Ed25519"
+)
+
+// HardenedOffset is added to an index to derive a hardened child (written i' in paths).
+const HardenedOffset uint32 = 0x80000000
+
+// HDKey is an extended private key of a BIP-32 tree, derived as specified by SLIP-10 so that the same
+// scheme covers secp256k1 (identical to BIP-32), P-256 and Ed25519. Ed25519 keys only have hardened children.
+type HDKey struct {
+	curve     string
+	key       []byte
+	chainCode []byte
+}
+
+// NewMasterKey derives the master key of curve from a seed such as the one of MnemonicToSeed.
+func NewMasterKey(seed []byte, curve string) (*HDKey, error) {
+	if len(seed) < 16 || len(seed) > 64 {
+		return nil, fmt.Errorf("invalid seed size: %d bytes", len(seed))
+	}
+	var hmacKey string
+	switch curve {
+	case CurveSecp256k1:
+		hmacKey = "Bitcoin seed"
+	case CurveP256:
+		hmacKey = "Nist256p1 seed"
+	case CurveEd25519:
+		hmacKey = "ed25519 seed"
+	default:
+		return nil, errors.New("unsupported hd key curve: " + curve)
+	}
+	sum := hmacSHA512([]byte(hmacKey), seed)
+	for curve != CurveEd25519 && !validScalar(curve, sum[:32]) {
+		sum = hmacSHA512([]byte(hmacKey), sum)
+	}
+	return &HDKey{curve: curve, key: sum[:32], chainCode: sum[32:]}, nil
+}
+
+// Curve returns the curve of k.
+func (k *HDKey) Curve() string {
+	return k.curve
+}
+
+// Key returns the 32-byte private key of k.
+func (k *HDKey) Key() []byte {
+	return append([]byte(nil), k.key...)
+}
+
+// ChainCode returns the chain code of k.
+func (k *HDKey) ChainCode() []byte {
+	return append([]byte(nil), k.chainCode...)
+}
+
+// Child derives the child index of k. Indexes from HardenedOffset on derive hardened children.
+func (k *HDKey) Child(index uint32) (*HDKey, error) {
+	hardened := index >= HardenedOffset
+	if k.curve == CurveEd25519 && !hardened {
+		return nil, errors.New("ed25519 keys only have hardened children")
+	}
+	data := make([]byte, 0, 37)
+	if hardened {
+		data = append(append(data, 0), k.key...)
+	} else {
+		data = append(data, k.compressedPublicKey()...)
+	}
+	data = binary.BigEndian.AppendUint32(data, index)
+	sum := hmacSHA512(k.chainCode, data)
+	if k.curve == CurveEd25519 {
+		return &HDKey{curve: k.curve, key: sum[:32], chainCode: sum[32:]}, nil
+	}
+	n := k.params().N
+	for {
+		il := new(big.Int).SetBytes(sum[:32])
+		child := new(big.Int).Add(il, new(big.Int).SetBytes(k.key))
+		child.Mod(child, n)
+		if il.Cmp(n) < 0 && child.Sign() != 0 {
+			return &HDKey{curve: k.curve, key: child.FillBytes(make([]byte, 32)), chainCode: sum[32:]}, nil
+		}
+		// SLIP-10: retry with 0x01 || IR || index when the key is invalid
+		data = binary.BigEndian.AppendUint32(append([]byte{1}, sum[32:]...), index)
+		sum = hmacSHA512(k.chainCode, data)
+	}
+}
+
+// Derive derives the key at path, e.g. m/50'/0'/0'/0', from the master key k.
+func (k *HDKey) Derive(path string) (*HDKey, error) {
+	indexes, err := ParseDerivationPath(path)
+	if err != nil {
+		return nil, err
+	}
+	key := k
+	for _, index := range indexes {
+		if key, err = key.Child(index); err != nil {
+			return nil, err
+		}
+	}
+	return key, nil
+}
+
+// PrivateKey returns k as a signing key: *ecdsa.PrivateKey for secp256k1 and P-256, ed25519.PrivateKey for Ed25519.
+func (k *HDKey) PrivateKey() crypto.PrivateKey {
+	switch k.curve {
+	case CurveEd25519:
+		return ed25519.NewKeyFromSeed(k.key)
+	case CurveSecp256k1:
+		pvKey, _ := ethcrypto.ToECDSA(k.key)
+		return pvKey
+	}
+	curve := elliptic.P256()
+	x, y := curve.ScalarBaseMult(k.key)
+	return &ecdsa.PrivateKey{PublicKey: ecdsa.PublicKey{Curve: curve, X: x, Y: y}, D: new(big.Int).SetBytes(k.key)}
+}
+
+// PublicKey returns the public key of k.
+func (k *HDKey) PublicKey() crypto.PublicKey {
+	return k.PrivateKey().(crypto.Signer).Public()
+}
+
+func (k *HDKey) params() *elliptic.CurveParams {
+	if k.curve == CurveSecp256k1 {
+		return ethcrypto.S256().Params()
+	}
+	return elliptic.P256().Params()
+}
+
+// compressedPublicKey returns the SEC1 compressed public key of an ECDSA key.
+func (k *HDKey) compressedPublicKey() []byte {
+	pbKey := k.PublicKey().(*ecdsa.PublicKey)
+	compressed := make([]byte, 33)
+	compressed[0] = 2 + byte(pbKey.Y.Bit(0))
+	pbKey.X.FillBytes(compressed[1:])
+	return compressed
+}
+
+func validScalar(curve string, key []byte) bool {
+	n := (&HDKey{curve: curve}).params().N
+	d := new(big.Int).SetBytes(key)
+	return d.Sign() != 0 && d.Cmp(n) < 0
+}
+
+func hmacSHA512(key, data []byte) []byte {
+	mac := hmac.New(sha512.New, key)
+	mac.Write(data)
+	return mac.Sum(nil)
+}
+
+// ParseDerivationPath parses a path such as m/50'/1'/0' into child indexes. Hardened indexes are marked with '
+// or h.
+func ParseDerivationPath(path string) ([]uint32, error) {
+	segments := strings.Split(strings.TrimSpace(path), "/")
+	if segments[0] != "m" {
+		return nil, errors.New("derivation path must start with m: " + path)
+	}
+	indexes := make([]uint32, 0, len(segments)-1)
+	for _, segment := range segments[1:] {
+		hardened := strings.HasSuffix(segment, "'") || strings.HasSuffix(segment, "h")
+		if hardened {
+			segment = segment[:len(segment)-1]
+		}
+		index, err := strconv.ParseUint(segment, 10, 32)
+		if err != nil || uint32(index) >= HardenedOffset {
+			return nil, errors.New("invalid derivation path: " + path)
+		}
+		if hardened {
+			index += uint64(HardenedOffset)
+		}
+		indexes = append(indexes, uint32(index))
+	}
+	return indexes, nil
+}
+
+// Wallet key paths. All levels are hardened, so they apply to Ed25519 as well and a leaked child key does not
+// expose its siblings:
+//
+//	m / 50' / curve' / relyingParty' / rotation'
+//
+// 50 is the purpose of byd50 DID keys and curve is 0 for secp256k1, 1 for P-256 and 2 for Ed25519.
+// relyingParty is 0 for the identity key of the wallet's DID and RelyingPartyIndex of the relying party
+// for pairwise keys, so that relying parties cannot correlate the wallet. rotation counts key rotations from 0.
+const DerivationPurpose = 50
+
+// IdentityKeyPath returns the path of the identity key of curve after rotation rotations.
+func IdentityKeyPath(curve string, rotation uint32) (string, error) {
+	return keyPath(curve, 0, rotation)
+}
+
+// RelyingPartyKeyPath returns the path of the pairwise key of curve for relyingParty (its DID or origin)
+// after rotation rotations.
+func RelyingPartyKeyPath(curve, relyingParty string, rotation uint32) (string, error) {
+	if relyingParty == "" {
+		return "", errors.New("relying party is required")
+	}
+	return keyPath(curve, RelyingPartyIndex(relyingParty), rotation)
+}
+
+// RelyingPartyIndex maps a relying party to a non-zero path index: the first 31 bits of the SHA-256 of its
+// identifier.
+func RelyingPartyIndex(relyingParty string) uint32 {
+	digest := sha256.Sum256([]byte(relyingParty))
+	index := binary.BigEndian.Uint32(digest[:4]) &^ HardenedOffset
+	if index == 0 {
+		index = 1
+	}
+	return index
+}
+
+func keyPath(curve string, relyingParty, rotation uint32) (string, error) {
+	var curveIndex int
+	switch curve {
+	case CurveSecp256k1:
+		curveIndex = 0
+	case CurveP256:
+		curveIndex = 1
+	case CurveEd25519:
+		curveIndex = 2
+	default:
+		return "", errors.New("unsupported hd key curve: " + curve)
+	}
+	if rotation >= HardenedOffset {
+		return "", fmt.Errorf("invalid rotation: %d", rotation)
+	}
+	return fmt.Sprintf("m/%d'/%d'/%d'/%d'", DerivationPurpose, curveIndex, relyingParty, rotation), nil
+}
@@ -0,0 +1,140 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"encoding/hex"
+	"strings"
+	"testing"
+)
+
+func TestMnemonicVectors(t *testing.T) {
+	// BIP-39 reference vectors (passphrase TREZOR)
+	vectors := []struct{ entropy, mnemonic, seed string }{
+		{
+			"00000000000000000000000000000000",
+			"abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon about",
+			"c55257c360c07c72029aebc1b53c05ed0362ada38ead3e3e9efa3708e53495531f09a6987599d18264c1e1c92f2cf141630c7a3c4ab7c81b2f001698e7463b04",
+		},
+		{
+			"7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f7f",
+			"legal winner thank year wave sausage worth useful legal winner thank yellow",
+			"2e8905819b8723fe2c1d161860e5ee1830318dbf49a83bd451cfb8440c28bd6fa457fe1296106559a3c80937a1c1069be3a3a5bd381ee6260e8d9739fce1f607",
+		},
+		{
+			"ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+			"zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo zoo vote",
+			"dd48c104698c30cfe2b6142103248622fb7bb0ff692eebb00089b32d22484e1613912f0a5b694407be899ffd31ed3992c456cdf60f5d4564b8ba3f05a69890ad",
+		},
+	}
+	for _, v := range vectors {
+		entropy, _ := hex.DecodeString(v.entropy)
+		mnemonic, err := MnemonicFromEntropy(entropy)
+		if err != nil || mnemonic != v.mnemonic {
+			t.Fatalf("unexpected mnemonic %q: %v", mnemonic, err)
+		}
+		decoded, err := MnemonicToEntropy(mnemonic)
+		if err != nil || hex.EncodeToString(decoded) != v.entropy {
+			t.Fatalf("unexpected entropy %x: %v", decoded, err)
+		}
+		seed, err := MnemonicToSeed(mnemonic, "TREZOR")
+		if err != nil || hex.EncodeToString(seed) != v.seed {
+			t.Fatalf("unexpected seed %x: %v", seed, err)
+		}
+	}
+
+	mnemonic, err := NewMnemonic(256)
+	if err != nil || len(strings.Fields(mnemonic)) != 24 {
+		t.Fatalf("expected 24 words, got %q: %v", mnemonic, err)
+	}
+	if _, err := NewMnemonic(100); err == nil {
+		t.Fatal("expected an invalid entropy size to be rejected")
+	}
+	if _, err := MnemonicToSeed(strings.Replace(vectors[0].mnemonic, "about", "abandon", 1), ""); err == nil {
+		t.Fatal("expected a bad checksum to be rejected")
+	}
+	if err := ValidateMnemonic("abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon abandon byd50"); err == nil {
+		t.Fatal("expected an unknown word to be rejected")
+	}
+}
+
+func TestHDKeySLIP10Vectors(t *testing.T) {
+	// SLIP-10 test vector 1 (for secp256k1 identical to BIP-32 test vector 1)
+	seed, _ := hex.DecodeString("000102030405060708090a0b0c0d0e0f")
+	vectors := []struct{ curve, path, chainCode, key string }{
+		{CurveSecp256k1, "m", "873dff81c02f525623fd1fe5167eac3a55a049de3d314bb42ee227ffed37d508", "e8f32e723decf4051aefac8e2c93c9c5b214313817cdb01a1494b917c8436b35"},
+		{CurveSecp256k1, "m/0'/1", "2a7857631386ba23dacac34180dd1983734e444fdbf774041578e9b6adb37c19", "3c6cb8d0f6a264c91ea8b5030fadaa8e538b020f0a387421a12de9319dc93368"},
+		{CurveP256, "m", "beeb672fe4621673f722f38529c07392fecaa61015c80c34f29ce8b41b3cb6ea", "612091aaa12e22dd2abef664f8a01a82cae99ad7441b7ef8110424915c268bc2"},
+		{CurveP256, "m/0'/1", "4187afff1aafa8445010097fb99d23aee9f599450c7bd140b6826ac22ba21d0c", "284e9d38d07d21e4e281b645089a94f4cf5a5a81369acf151a1c3a57f18b2129"},
+		{CurveEd25519, "m", "90046a93de5380a72b5e45010748567d5ea02bbf6522f979e05c0d8d8ca9fffb", "2b4be7f19ee27bbf30c667b642d5f4aa69fd169872f8fc3059c08ebae2eb19e7"},
+		{CurveEd25519, "m/0h", "8b59aa11380b624e81507a27fedda59fea6d0b779a778918a2fd3590e16e9c69", "68e0fe46dfb67e368c75379acec591dad19df3cde26e63b93a8e704f1dade7a3"},
+	}
+	for _, v := range vectors {
+		master, err := NewMasterKey(seed, v.curve)
+		if err != nil {
+			t.Fatal(err)
+		}
+		key, err := master.Derive(v.path)
+		if err != nil {
+			t.Fatalf("%s %s: %v", v.curve, v.path, err)
+		}
+		if hex.EncodeToString(key.ChainCode()) != v.chainCode || hex.EncodeToString(key.Key()) != v.key {
+			t.Fatalf("%s %s: unexpected key %x / %x", v.curve, v.path, key.ChainCode(), key.Key())
+		}
+	}
+
+	master, _ := NewMasterKey(seed, CurveEd25519)
+	if _, err := master.Derive("m/0"); err == nil {
+		t.Fatal("expected a non-hardened ed25519 child to be rejected")
+	}
+	if _, err := master.Derive("0'/1'"); err == nil {
+		t.Fatal("expected a path without m to be rejected")
+	}
+	if _, err := NewMasterKey(seed, "P-384"); err == nil {
+		t.Fatal("expected an unsupported curve to be rejected")
+	}
+}
+
+func TestWalletKeyPaths(t *testing.T) {
+	seed, err := MnemonicToSeed("legal winner thank year wave sausage worth useful legal winner thank yellow", "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := IdentityKeyPath(CurveP256, 0)
+	if err != nil || path != "m/50'/1'/0'/0'" {
+		t.Fatalf("unexpected identity path %q: %v", path, err)
+	}
+	rpPath, _ := RelyingPartyKeyPath(CurveP256, "did:byd50:rental", 0)
+	rotatedPath, _ := RelyingPartyKeyPath(CurveP256, "did:byd50:rental", 1)
+	otherPath, _ := RelyingPartyKeyPath(CurveP256, "https://verifier.example", 0)
+	if rpPath == path || rpPath == rotatedPath || rpPath == otherPath {
+		t.Fatalf("expected distinct paths: %s %s %s %s", path, rpPath, rotatedPath, otherPath)
+	}
+	if _, err := RelyingPartyKeyPath(CurveP256, "", 0); err == nil {
+		t.Fatal("expected an empty relying party to be rejected")
+	}
+
+	for _, curve := range []string{CurveSecp256k1, CurveP256, CurveEd25519} {
+		master, _ := NewMasterKey(seed, curve)
+		path, _ := RelyingPartyKeyPath(curve, "did:byd50:rental", 0)
+		first, err := master.Derive(path)
+		if err != nil {
+			t.Fatalf("%s: %v", curve, err)
+		}
+		restored, _ := NewMasterKey(seed, curve)
+		second, _ := restored.Derive(path)
+		if ExportPublicKeyAsBase58(first.PublicKey()) != ExportPublicKeyAsBase58(second.PublicKey()) {
+			t.Fatalf("%s: expected the same key from the same seed", curve)
+		}
+		switch pvKey := first.PrivateKey().(type) {
+		case *ecdsa.PrivateKey:
+			if curve == CurveEd25519 || IsSecp256k1(pvKey.Curve) != (curve == CurveSecp256k1) {
+				t.Fatalf("%s: unexpected curve", curve)
+			}
+		case ed25519.PrivateKey:
+			if curve != CurveEd25519 {
+				t.Fatalf("%s: unexpected ed25519 key", curve)
+			}
+		}
+	}
+}
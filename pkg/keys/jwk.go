@@ -0,0 +1,166 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"math/big"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// JWK is the public part of a JSON Web Key (RFC 7517) for the key types used by DID documents:
+// EC (P-256, P-384, secp256k1), OKP (Ed25519, X25519) and RSA.
+type JWK struct {
+	Kty string `json:"kty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+}
+
+// ExportPublicKeyAsJWK : Exports a public key of any supported type as a JWK.
+func ExportPublicKeyAsJWK(publicKey crypto.PublicKey) (*JWK, error) {
+	switch v := publicKey.(type) {
+	case *ecdsa.PublicKey:
+		crv, err := jwkCurveName(v.Curve)
+		if err != nil {
+			return nil, err
+		}
+		size := (v.Curve.Params().BitSize + 7) / 8
+		return &JWK{
+			Kty: "EC",
+			Crv: crv,
+			X:   base64.RawURLEncoding.EncodeToString(v.X.FillBytes(make([]byte, size))),
+			Y:   base64.RawURLEncoding.EncodeToString(v.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case ed25519.PublicKey:
+		return &JWK{Kty: "OKP", Crv: "Ed25519", X: base64.RawURLEncoding.EncodeToString(v)}, nil
+	case *ecdh.PublicKey:
+		if v.Curve() != ecdh.X25519() {
+			return nil, errors.New("unsupported curve for jwk")
+		}
+		return &JWK{Kty: "OKP", Crv: "X25519", X: base64.RawURLEncoding.EncodeToString(v.Bytes())}, nil
+	case *rsa.PublicKey:
+		return &JWK{
+			Kty: "RSA",
+			N:   base64.RawURLEncoding.EncodeToString(v.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(v.E)).Bytes()),
+		}, nil
+	}
+	return nil, errors.New("unsupported key type for jwk")
+}
+
+// PublicKey returns the public key described by the JWK.
+func (jwk *JWK) PublicKey() (crypto.PublicKey, error) {
+	if jwk == nil {
+		return nil, errors.New("jwk is nil")
+	}
+	switch jwk.Kty {
+	case "EC":
+		curve, err := jwkCurve(jwk.Crv)
+		if err != nil {
+			return nil, err
+		}
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, errors.New("invalid jwk x")
+		}
+		y, err := base64.RawURLEncoding.DecodeString(jwk.Y)
+		if err != nil {
+			return nil, errors.New("invalid jwk y")
+		}
+		size := (curve.Params().BitSize + 7) / 8
+		if len(x) != size || len(y) != size {
+			return nil, errors.New("invalid jwk coordinate length")
+		}
+		publicKey := &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}
+		if !curve.IsOnCurve(publicKey.X, publicKey.Y) {
+			return nil, errors.New("jwk point is not on curve")
+		}
+		return publicKey, nil
+	case "OKP":
+		x, err := base64.RawURLEncoding.DecodeString(jwk.X)
+		if err != nil {
+			return nil, errors.New("invalid jwk x")
+		}
+		switch jwk.Crv {
+		case "Ed25519":
+			if len(x) != ed25519.PublicKeySize {
+				return nil, errors.New("invalid jwk x")
+			}
+			return ed25519.PublicKey(x), nil
+		case "X25519":
+			publicKey, err := ecdh.X25519().NewPublicKey(x)
+			if err != nil {
+				return nil, errors.New("invalid jwk x")
+			}
+			return publicKey, nil
+		}
+		return nil, errors.New("unsupported jwk curve: " + jwk.Crv)
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(jwk.N)
+		if err != nil || len(n) == 0 {
+			return nil, errors.New("invalid jwk n")
+		}
+		e, err := base64.RawURLEncoding.DecodeString(jwk.E)
+		if err != nil || len(e) == 0 || len(e) > 4 {
+			return nil, errors.New("invalid jwk e")
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	}
+	return nil, errors.New("unsupported jwk kty: " + jwk.Kty)
+}
+
+// Thumbprint returns the JWK SHA-256 thumbprint (RFC 7638), base64url encoded: the hash of the required
+// members of the key in lexicographic order.
+func (jwk *JWK) Thumbprint() (string, error) {
+	if jwk == nil {
+		return "", errors.New("jwk is nil")
+	}
+	var members string
+	switch jwk.Kty {
+	case "EC":
+		members = fmt.Sprintf(`{"crv":%q,"kty":"EC","x":%q,"y":%q}`, jwk.Crv, jwk.X, jwk.Y)
+	case "OKP":
+		members = fmt.Sprintf(`{"crv":%q,"kty":"OKP","x":%q}`, jwk.Crv, jwk.X)
+	case "RSA":
+		members = fmt.Sprintf(`{"e":%q,"kty":"RSA","n":%q}`, jwk.E, jwk.N)
+	default:
+		return "", errors.New("unsupported jwk kty: " + jwk.Kty)
+	}
+	digest := sha256.Sum256([]byte(members))
+	return base64.RawURLEncoding.EncodeToString(digest[:]), nil
+}
+
+func jwkCurveName(curve elliptic.Curve) (string, error) {
+	switch {
+	case curve == elliptic.P256():
+		return "P-256", nil
+	case curve == elliptic.P384():
+		return "P-384", nil
+	case IsSecp256k1(curve):
+		return "secp256k1", nil
+	}
+	return "", errors.New("unsupported curve for jwk")
+}
+
+func jwkCurve(crv string) (elliptic.Curve, error) {
+	switch crv {
+	case "P-256":
+		return elliptic.P256(), nil
+	case "P-384":
+		return elliptic.P384(), nil
+	case "secp256k1":
+		return ethcrypto.S256(), nil
+	}
+	return nil, errors.New("unsupported jwk curve: " + crv)
+}
@@ -0,0 +1,101 @@
+package keys
+
+import (
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"log"
+
+	"github.com/btcsuite/btcutil/base58"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// ExportPublicKeyAsBase58 : Exports a public key of any supported type as Base58.
+// ECDSA (P-256, P-384), Ed25519 and X25519 keys are PKIX encoded, RSA keys PKCS#1 encoded and
+// secp256k1 keys use the 33 byte compressed point.
+func ExportPublicKeyAsBase58(publicKey crypto.PublicKey) string {
+	switch v := publicKey.(type) {
+	case *rsa.PublicKey:
+		return ExportRSAPublicKeyAsBase58(v)
+	case *ecdsa.PublicKey:
+		return ExportECDSAPublicKeyAsBase58(v)
+	case ed25519.PublicKey, *ecdh.PublicKey:
+		publicKeyBytes, err := x509.MarshalPKIXPublicKey(v)
+		if err != nil {
+			log.Printf("error occured: %v", err.Error())
+			return ""
+		}
+		return base58.Encode(publicKeyBytes)
+	default:
+		log.Printf("unknown key type")
+		return ""
+	}
+}
+
+// ExportPrivateKeyAsBase58 : Exports a private key of any supported type as Base58.
+// ECDSA keys are SEC 1 encoded, RSA keys PKCS#1 encoded, Ed25519 and X25519 keys PKCS#8 encoded and
+// secp256k1 keys use the raw 32 byte scalar.
+func ExportPrivateKeyAsBase58(privateKey crypto.PrivateKey) string {
+	switch v := privateKey.(type) {
+	case *rsa.PrivateKey:
+		return ExportRSAPrivateKeyAsBase58(v)
+	case *ecdsa.PrivateKey:
+		return ExportECDSAPrivateKeyAsBase58(v)
+	case ed25519.PrivateKey, *ecdh.PrivateKey:
+		privateKeyBytes, err := x509.MarshalPKCS8PrivateKey(v)
+		if err != nil {
+			log.Printf("error occured: %v", err.Error())
+			return ""
+		}
+		return base58.Encode(privateKeyBytes)
+	default:
+		log.Printf("unknown key type")
+		return ""
+	}
+}
+
+// ParsePublicKeyBase58 parses a public key exported by ExportPublicKeyAsBase58.
+func ParsePublicKeyBase58(publicKeyBase58 string) (crypto.PublicKey, error) {
+	publicKeyBytes := base58.Decode(publicKeyBase58)
+	if len(publicKeyBytes) == 0 {
+		return nil, errors.New("invalid public key base58")
+	}
+	if publicKey, err := x509.ParsePKIXPublicKey(publicKeyBytes); err == nil {
+		return publicKey, nil
+	}
+	if publicKey, err := x509.ParsePKCS1PublicKey(publicKeyBytes); err == nil {
+		return publicKey, nil
+	}
+	switch len(publicKeyBytes) {
+	case 33:
+		return ethcrypto.DecompressPubkey(publicKeyBytes)
+	case 65:
+		return ethcrypto.UnmarshalPubkey(publicKeyBytes)
+	}
+	return nil, errors.New("unsupported public key encoding")
+}
+
+// ParsePrivateKeyBase58 parses a private key exported by ExportPrivateKeyAsBase58.
+func ParsePrivateKeyBase58(privateKeyBase58 string) (crypto.PrivateKey, error) {
+	privateKeyBytes := base58.Decode(privateKeyBase58)
+	if len(privateKeyBytes) == 0 {
+		return nil, errors.New("invalid private key base58")
+	}
+	if privateKey, err := x509.ParseECPrivateKey(privateKeyBytes); err == nil {
+		return privateKey, nil
+	}
+	if privateKey, err := x509.ParsePKCS1PrivateKey(privateKeyBytes); err == nil {
+		return privateKey, nil
+	}
+	if privateKey, err := x509.ParsePKCS8PrivateKey(privateKeyBytes); err == nil {
+		return privateKey, nil
+	}
+	if len(privateKeyBytes) == 32 {
+		return ethcrypto.ToECDSA(privateKeyBytes)
+	}
+	return nil, errors.New("unsupported private key encoding")
+}
@@ -0,0 +1,97 @@
+package keys
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdh"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"errors"
+	"strings"
+
+	"github.com/btcsuite/btcutil/base58"
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// multibaseBase58Btc is the multibase prefix of base58-btc encoded values.
+const multibaseBase58Btc = "z"
+
+// Multicodec prefixes (unsigned varint) of the public key types published as Multikey.
+// github.com/multiformats/multicodec
+var (
+	multicodecEd25519Pub   = []byte{0xed, 0x01}
+	multicodecX25519Pub    = []byte{0xec, 0x01}
+	multicodecSecp256k1Pub = []byte{0xe7, 0x01}
+	multicodecP256Pub      = []byte{0x80, 0x24}
+	multicodecP384Pub      = []byte{0x81, 0x24}
+	multicodecRsaPub       = []byte{0x85, 0x24}
+)
+
+// ExportPublicKeyAsMultibase : Exports a public key as a base58-btc multibase value with a multicodec
+// prefix, as used by publicKeyMultibase. EC keys use the compressed point and RSA keys PKCS#1.
+func ExportPublicKeyAsMultibase(publicKey crypto.PublicKey) (string, error) {
+	var prefix, keyBytes []byte
+	switch v := publicKey.(type) {
+	case ed25519.PublicKey:
+		prefix, keyBytes = multicodecEd25519Pub, v
+	case *ecdh.PublicKey:
+		if v.Curve() != ecdh.X25519() {
+			return "", errors.New("unsupported curve for multibase")
+		}
+		prefix, keyBytes = multicodecX25519Pub, v.Bytes()
+	case *ecdsa.PublicKey:
+		switch {
+		case IsSecp256k1(v.Curve):
+			prefix, keyBytes = multicodecSecp256k1Pub, ethcrypto.CompressPubkey(v)
+		case v.Curve == elliptic.P256():
+			prefix, keyBytes = multicodecP256Pub, elliptic.MarshalCompressed(v.Curve, v.X, v.Y)
+		case v.Curve == elliptic.P384():
+			prefix, keyBytes = multicodecP384Pub, elliptic.MarshalCompressed(v.Curve, v.X, v.Y)
+		default:
+			return "", errors.New("unsupported curve for multibase")
+		}
+	case *rsa.PublicKey:
+		prefix, keyBytes = multicodecRsaPub, x509.MarshalPKCS1PublicKey(v)
+	default:
+		return "", errors.New("unsupported key type for multibase")
+	}
+	return multibaseBase58Btc + base58.Encode(append(append([]byte{}, prefix...), keyBytes...)), nil
+}
+
+// ParsePublicKeyMultibase parses a public key exported by ExportPublicKeyAsMultibase.
+func ParsePublicKeyMultibase(publicKeyMultibase string) (crypto.PublicKey, error) {
+	if !strings.HasPrefix(publicKeyMultibase, multibaseBase58Btc) {
+		return nil, errors.New("publicKeyMultibase must be base58-btc multibase")
+	}
+	data := base58.Decode(publicKeyMultibase[len(multibaseBase58Btc):])
+	switch {
+	case bytes.HasPrefix(data, multicodecEd25519Pub):
+		keyBytes := data[len(multicodecEd25519Pub):]
+		if len(keyBytes) != ed25519.PublicKeySize {
+			return nil, errors.New("invalid ed25519 public key length")
+		}
+		return ed25519.PublicKey(keyBytes), nil
+	case bytes.HasPrefix(data, multicodecX25519Pub):
+		return ecdh.X25519().NewPublicKey(data[len(multicodecX25519Pub):])
+	case bytes.HasPrefix(data, multicodecSecp256k1Pub):
+		return ethcrypto.DecompressPubkey(data[len(multicodecSecp256k1Pub):])
+	case bytes.HasPrefix(data, multicodecP256Pub):
+		return unmarshalCompressed(elliptic.P256(), data[len(multicodecP256Pub):])
+	case bytes.HasPrefix(data, multicodecP384Pub):
+		return unmarshalCompressed(elliptic.P384(), data[len(multicodecP384Pub):])
+	case bytes.HasPrefix(data, multicodecRsaPub):
+		return x509.ParsePKCS1PublicKey(data[len(multicodecRsaPub):])
+	}
+	return nil, errors.New("unsupported multicodec key type")
+}
+
+func unmarshalCompressed(curve elliptic.Curve, keyBytes []byte) (*ecdsa.PublicKey, error) {
+	x, y := elliptic.UnmarshalCompressed(curve, keyBytes)
+	if x == nil {
+		return nil, errors.New("invalid compressed public key")
+	}
+	return &ecdsa.PublicKey{Curve: curve, X: x, Y: y}, nil
+}
@@ -2,9 +2,11 @@ package keys
 
 import (
 	"bytes"
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
+	"crypto/rsa"
 	"crypto/x509"
 	"encoding/base64"
 	"encoding/pem"
@@ -173,3 +175,94 @@ func TestPemHelpers(t *testing.T) {
 		t.Fatal("expected parse public key error")
 	}
 }
+
+func TestKeyBase58RoundTrip(t *testing.T) {
+	p256, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	k1, _, err := GenerateSecp256k1KeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ed, _, err := GenerateEd25519KeyPair()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	type signer interface {
+		Public() crypto.PublicKey
+		Equal(crypto.PrivateKey) bool
+	}
+	for name, pvKey := range map[string]signer{"p256": p256, "secp256k1": k1, "ed25519": ed, "rsa": rsaKey} {
+		pvKeyBase58 := ExportPrivateKeyAsBase58(pvKey)
+		parsedPv, err := ParsePrivateKeyBase58(pvKeyBase58)
+		if err != nil {
+			t.Fatalf("%s: parse private key: %v", name, err)
+		}
+		if !pvKey.Equal(parsedPv) {
+			t.Fatalf("%s: private key mismatch", name)
+		}
+
+		pbKeyBase58 := ExportPublicKeyAsBase58(pvKey.Public())
+		parsedPb, err := ParsePublicKeyBase58(pbKeyBase58)
+		if err != nil {
+			t.Fatalf("%s: parse public key: %v", name, err)
+		}
+		if !pvKey.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(parsedPb) {
+			t.Fatalf("%s: public key mismatch", name)
+		}
+
+		jwk, err := ExportPublicKeyAsJWK(pvKey.Public())
+		if err != nil {
+			t.Fatalf("%s: export jwk: %v", name, err)
+		}
+		if parsedPb, err = jwk.PublicKey(); err != nil {
+			t.Fatalf("%s: parse jwk: %v", name, err)
+		}
+		if !pvKey.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(parsedPb) {
+			t.Fatalf("%s: jwk public key mismatch", name)
+		}
+
+		multibase, err := ExportPublicKeyAsMultibase(pvKey.Public())
+		if err != nil {
+			t.Fatalf("%s: export multibase: %v", name, err)
+		}
+		if parsedPb, err = ParsePublicKeyMultibase(multibase); err != nil {
+			t.Fatalf("%s: parse multibase: %v", name, err)
+		}
+		if !pvKey.Public().(interface{ Equal(crypto.PublicKey) bool }).Equal(parsedPb) {
+			t.Fatalf("%s: multibase public key mismatch", name)
+		}
+	}
+
+	if _, err := ParsePublicKeyBase58("notakey"); err == nil {
+		t.Fatal("expected error for invalid public key")
+	}
+	if _, err := ParsePublicKeyMultibase("notakey"); err == nil {
+		t.Fatal("expected error for invalid multibase key")
+	}
+	if _, err := (&JWK{Kty: "EC", Crv: "P-256", X: "AA", Y: "AA"}).PublicKey(); err == nil {
+		t.Fatal("expected error for invalid jwk")
+	}
+}
+
+func TestJWKThumbprint(t *testing.T) {
+	// RFC 7638 section 3.1
+	jwk := &JWK{
+		Kty: "RSA",
+		N:   "0vx7agoebGcQSuuPiLJXZptN9nndrQmbXEps2aiAFbWhM78LhWx4cbbfAAtVT86zwu1RK7aPFFxuhDR1L6tSoc_BJECPebWKRXjBZCiFV4n3oknjhMstn64tZ_2W-5JsGY4Hc5n9yBXArwl93lqt7_RN5w6Cf0h4QyQ5v-65YGjQR0_FDW2QvzqY368QQMicAtaSqzs8KJZgnYb9c7d0zgdAZHzu6qMQvRL5hajrn1n91CbOpbISD08qNLyrdkt-bFTWhAI4vMQFh6WeZu0fM4lFd2NcRwr3XPksINHaQ-G_xBniIqbw0Ls1jF44-csFCur-kEgU8awapJzKnqDKgw",
+		E:   "AQAB",
+	}
+	thumbprint, err := jwk.Thumbprint()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if thumbprint != "NzbLsXh8uDCcd-6MNwXF4W_7noWXFZAfHkxZsRGC9Xs" {
+		t.Fatalf("unexpected thumbprint %s", thumbprint)
+	}
+}
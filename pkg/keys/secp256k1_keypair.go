@@ -0,0 +1,26 @@
+package keys
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+
+	ethcrypto "github.com/ethereum/go-ethereum/crypto"
+)
+
+// GenerateSecp256k1KeyPair generates a new secp256k1 key pair, the curve used by Ethereum accounts.
+func GenerateSecp256k1KeyPair() (*ecdsa.PrivateKey, *ecdsa.PublicKey, error) {
+	privateKey, err := ethcrypto.GenerateKey()
+	if err != nil {
+		return nil, nil, err
+	}
+	return privateKey, &privateKey.PublicKey, nil
+}
+
+// IsSecp256k1 reports whether curve is secp256k1.
+func IsSecp256k1(curve elliptic.Curve) bool {
+	if curve == nil {
+		return false
+	}
+	params, s256 := curve.Params(), ethcrypto.S256().Params()
+	return params.P.Cmp(s256.P) == 0 && params.N.Cmp(s256.N) == 0
+}
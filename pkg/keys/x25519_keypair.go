@@ -0,0 +1,16 @@
+package keys
+
+import (
+	"crypto/ecdh"
+	"crypto/rand"
+)
+
+// GenerateX25519KeyPair generates a new X25519 key pair. X25519 keys are only used for key agreement
+// (keyAgreement of DID documents) and cannot sign.
+func GenerateX25519KeyPair() (*ecdh.PrivateKey, *ecdh.PublicKey, error) {
+	privateKey, err := ecdh.X25519().GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, nil, err
+	}
+	return privateKey, privateKey.PublicKey(), nil
+}
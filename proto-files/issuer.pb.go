@@ -515,6 +515,246 @@ func (x *RentalCarControlReply) GetResult() string {
 	return ""
 }
 
+type IssuedCredential struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jti           string                 `protobuf:"bytes,1,opt,name=jti,proto3" json:"jti,omitempty"`
+	Issuer        string                 `protobuf:"bytes,2,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	SubjectDid    string                 `protobuf:"bytes,3,opt,name=subject_did,json=subjectDid,proto3" json:"subject_did,omitempty"`
+	Type          string                 `protobuf:"bytes,4,opt,name=type,proto3" json:"type,omitempty"`
+	Iat           int64                  `protobuf:"varint,5,opt,name=iat,proto3" json:"iat,omitempty"`
+	Exp           int64                  `protobuf:"varint,6,opt,name=exp,proto3" json:"exp,omitempty"`
+	StatusIndex   int64                  `protobuf:"varint,7,opt,name=status_index,json=statusIndex,proto3" json:"status_index,omitempty"`
+	Hash          string                 `protobuf:"bytes,8,opt,name=hash,proto3" json:"hash,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IssuedCredential) Reset() {
+	*x = IssuedCredential{}
+	mi := &file_proto_files_issuer_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IssuedCredential) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IssuedCredential) ProtoMessage() {}
+
+func (x *IssuedCredential) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_issuer_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IssuedCredential.ProtoReflect.Descriptor instead.
+func (*IssuedCredential) Descriptor() ([]byte, []int) {
+	return file_proto_files_issuer_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *IssuedCredential) GetJti() string {
+	if x != nil {
+		return x.Jti
+	}
+	return ""
+}
+
+func (x *IssuedCredential) GetIssuer() string {
+	if x != nil {
+		return x.Issuer
+	}
+	return ""
+}
+
+func (x *IssuedCredential) GetSubjectDid() string {
+	if x != nil {
+		return x.SubjectDid
+	}
+	return ""
+}
+
+func (x *IssuedCredential) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *IssuedCredential) GetIat() int64 {
+	if x != nil {
+		return x.Iat
+	}
+	return 0
+}
+
+func (x *IssuedCredential) GetExp() int64 {
+	if x != nil {
+		return x.Exp
+	}
+	return 0
+}
+
+func (x *IssuedCredential) GetStatusIndex() int64 {
+	if x != nil {
+		return x.StatusIndex
+	}
+	return 0
+}
+
+func (x *IssuedCredential) GetHash() string {
+	if x != nil {
+		return x.Hash
+	}
+	return ""
+}
+
+type GetIssuedCredentialRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Jti           string                 `protobuf:"bytes,1,opt,name=jti,proto3" json:"jti,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetIssuedCredentialRequest) Reset() {
+	*x = GetIssuedCredentialRequest{}
+	mi := &file_proto_files_issuer_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetIssuedCredentialRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetIssuedCredentialRequest) ProtoMessage() {}
+
+func (x *GetIssuedCredentialRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_issuer_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetIssuedCredentialRequest.ProtoReflect.Descriptor instead.
+func (*GetIssuedCredentialRequest) Descriptor() ([]byte, []int) {
+	return file_proto_files_issuer_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *GetIssuedCredentialRequest) GetJti() string {
+	if x != nil {
+		return x.Jti
+	}
+	return ""
+}
+
+type ListIssuedCredentialsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	SubjectDid    string                 `protobuf:"bytes,1,opt,name=subject_did,json=subjectDid,proto3" json:"subject_did,omitempty"`
+	Type          string                 `protobuf:"bytes,2,opt,name=type,proto3" json:"type,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListIssuedCredentialsRequest) Reset() {
+	*x = ListIssuedCredentialsRequest{}
+	mi := &file_proto_files_issuer_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListIssuedCredentialsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListIssuedCredentialsRequest) ProtoMessage() {}
+
+func (x *ListIssuedCredentialsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_issuer_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListIssuedCredentialsRequest.ProtoReflect.Descriptor instead.
+func (*ListIssuedCredentialsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_files_issuer_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *ListIssuedCredentialsRequest) GetSubjectDid() string {
+	if x != nil {
+		return x.SubjectDid
+	}
+	return ""
+}
+
+func (x *ListIssuedCredentialsRequest) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+type ListIssuedCredentialsReply struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Credentials   []*IssuedCredential    `protobuf:"bytes,1,rep,name=credentials,proto3" json:"credentials,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListIssuedCredentialsReply) Reset() {
+	*x = ListIssuedCredentialsReply{}
+	mi := &file_proto_files_issuer_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListIssuedCredentialsReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListIssuedCredentialsReply) ProtoMessage() {}
+
+func (x *ListIssuedCredentialsReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_issuer_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListIssuedCredentialsReply.ProtoReflect.Descriptor instead.
+func (*ListIssuedCredentialsReply) Descriptor() ([]byte, []int) {
+	return file_proto_files_issuer_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *ListIssuedCredentialsReply) GetCredentials() []*IssuedCredential {
+	if x != nil {
+		return x.Credentials
+	}
+	return nil
+}
+
 var File_proto_files_issuer_proto protoreflect.FileDescriptor
 
 const file_proto_files_issuer_proto_rawDesc = "" +
@@ -549,13 +789,33 @@ const file_proto_files_issuer_proto_rawDesc = "" +
 	"\x1brental_car_agreement_vc_jwt\x18\x01 \x01(\tR\x17rentalCarAgreementVcJwt\"E\n" +
 	"\x15RentalCarControlReply\x12\x14\n" +
 	"\x05valid\x18\x01 \x01(\bR\x05valid\x12\x16\n" +
-	"\x06result\x18\x02 \x01(\tR\x06result2\x8a\x03\n" +
+	"\x06result\x18\x02 \x01(\tR\x06result\"\xcc\x01\n" +
+	"\x10IssuedCredential\x12\x10\n" +
+	"\x03jti\x18\x01 \x01(\tR\x03jti\x12\x16\n" +
+	"\x06issuer\x18\x02 \x01(\tR\x06issuer\x12\x1f\n" +
+	"\vsubject_did\x18\x03 \x01(\tR\n" +
+	"subjectDid\x12\x12\n" +
+	"\x04type\x18\x04 \x01(\tR\x04type\x12\x10\n" +
+	"\x03iat\x18\x05 \x01(\x03R\x03iat\x12\x10\n" +
+	"\x03exp\x18\x06 \x01(\x03R\x03exp\x12!\n" +
+	"\fstatus_index\x18\a \x01(\x03R\vstatusIndex\x12\x12\n" +
+	"\x04hash\x18\b \x01(\tR\x04hash\".\n" +
+	"\x1aGetIssuedCredentialRequest\x12\x10\n" +
+	"\x03jti\x18\x01 \x01(\tR\x03jti\"S\n" +
+	"\x1cListIssuedCredentialsRequest\x12\x1f\n" +
+	"\vsubject_did\x18\x01 \x01(\tR\n" +
+	"subjectDid\x12\x12\n" +
+	"\x04type\x18\x02 \x01(\tR\x04type\"X\n" +
+	"\x1aListIssuedCredentialsReply\x12:\n" +
+	"\vcredentials\x18\x01 \x03(\v2\x18.issuer.IssuedCredentialR\vcredentials2\xc6\x04\n" +
 	"\x06Issuer\x12I\n" +
 	"\x11RequestCredential\x12\x19.issuer.CredentialRequest\x1a\x17.issuer.CredentialReply\"\x00\x12=\n" +
 	"\rReqCredIdCard\x12\x15.issuer.IdCardRequest\x1a\x13.issuer.IdCardReply\"\x00\x12=\n" +
 	"\rReqCredDlCard\x12\x15.issuer.DlCardRequest\x1a\x13.issuer.DlCardReply\"\x00\x12a\n" +
 	"\x19ReqCredRentalCarAgreement\x12!.issuer.RentalCarAgreementRequest\x1a\x1f.issuer.RentalCarAgreementReply\"\x00\x12T\n" +
-	"\x10RentalCarControl\x12\x1f.issuer.RentalCarControlRequest\x1a\x1d.issuer.RentalCarControlReply\"\x00BH\n" +
+	"\x10RentalCarControl\x12\x1f.issuer.RentalCarControlRequest\x1a\x1d.issuer.RentalCarControlReply\"\x00\x12U\n" +
+	"\x13GetIssuedCredential\x12\".issuer.GetIssuedCredentialRequest\x1a\x18.issuer.IssuedCredential\"\x00\x12c\n" +
+	"\x15ListIssuedCredentials\x12$.issuer.ListIssuedCredentialsRequest\x1a\".issuer.ListIssuedCredentialsReply\"\x00BH\n" +
 	"\x1cio.grpc.examples.proto-filesB\x0fHelloWorldProtoP\x01Z\x15byd50-ssi/proto-filesb\x06proto3"
 
 var (
@@ -570,35 +830,44 @@ func file_proto_files_issuer_proto_rawDescGZIP() []byte {
 	return file_proto_files_issuer_proto_rawDescData
 }
 
-var file_proto_files_issuer_proto_msgTypes = make([]protoimpl.MessageInfo, 10)
+var file_proto_files_issuer_proto_msgTypes = make([]protoimpl.MessageInfo, 14)
 var file_proto_files_issuer_proto_goTypes = []any{
-	(*CredentialRequest)(nil),         // 0: issuer.CredentialRequest
-	(*CredentialReply)(nil),           // 1: issuer.CredentialReply
-	(*IdCardRequest)(nil),             // 2: issuer.IdCardRequest
-	(*IdCardReply)(nil),               // 3: issuer.IdCardReply
-	(*DlCardRequest)(nil),             // 4: issuer.DlCardRequest
-	(*DlCardReply)(nil),               // 5: issuer.DlCardReply
-	(*RentalCarAgreementRequest)(nil), // 6: issuer.RentalCarAgreementRequest
-	(*RentalCarAgreementReply)(nil),   // 7: issuer.RentalCarAgreementReply
-	(*RentalCarControlRequest)(nil),   // 8: issuer.RentalCarControlRequest
-	(*RentalCarControlReply)(nil),     // 9: issuer.RentalCarControlReply
+	(*CredentialRequest)(nil),            // 0: issuer.CredentialRequest
+	(*CredentialReply)(nil),              // 1: issuer.CredentialReply
+	(*IdCardRequest)(nil),                // 2: issuer.IdCardRequest
+	(*IdCardReply)(nil),                  // 3: issuer.IdCardReply
+	(*DlCardRequest)(nil),                // 4: issuer.DlCardRequest
+	(*DlCardReply)(nil),                  // 5: issuer.DlCardReply
+	(*RentalCarAgreementRequest)(nil),    // 6: issuer.RentalCarAgreementRequest
+	(*RentalCarAgreementReply)(nil),      // 7: issuer.RentalCarAgreementReply
+	(*RentalCarControlRequest)(nil),      // 8: issuer.RentalCarControlRequest
+	(*RentalCarControlReply)(nil),        // 9: issuer.RentalCarControlReply
+	(*IssuedCredential)(nil),             // 10: issuer.IssuedCredential
+	(*GetIssuedCredentialRequest)(nil),   // 11: issuer.GetIssuedCredentialRequest
+	(*ListIssuedCredentialsRequest)(nil), // 12: issuer.ListIssuedCredentialsRequest
+	(*ListIssuedCredentialsReply)(nil),   // 13: issuer.ListIssuedCredentialsReply
 }
 var file_proto_files_issuer_proto_depIdxs = []int32{
-	0, // 0: issuer.Issuer.RequestCredential:input_type -> issuer.CredentialRequest
-	2, // 1: issuer.Issuer.ReqCredIdCard:input_type -> issuer.IdCardRequest
-	4, // 2: issuer.Issuer.ReqCredDlCard:input_type -> issuer.DlCardRequest
-	6, // 3: issuer.Issuer.ReqCredRentalCarAgreement:input_type -> issuer.RentalCarAgreementRequest
-	8, // 4: issuer.Issuer.RentalCarControl:input_type -> issuer.RentalCarControlRequest
-	1, // 5: issuer.Issuer.RequestCredential:output_type -> issuer.CredentialReply
-	3, // 6: issuer.Issuer.ReqCredIdCard:output_type -> issuer.IdCardReply
-	5, // 7: issuer.Issuer.ReqCredDlCard:output_type -> issuer.DlCardReply
-	7, // 8: issuer.Issuer.ReqCredRentalCarAgreement:output_type -> issuer.RentalCarAgreementReply
-	9, // 9: issuer.Issuer.RentalCarControl:output_type -> issuer.RentalCarControlReply
-	5, // [5:10] is the sub-list for method output_type
-	0, // [0:5] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	10, // 0: issuer.ListIssuedCredentialsReply.credentials:type_name -> issuer.IssuedCredential
+	0,  // 1: issuer.Issuer.RequestCredential:input_type -> issuer.CredentialRequest
+	2,  // 2: issuer.Issuer.ReqCredIdCard:input_type -> issuer.IdCardRequest
+	4,  // 3: issuer.Issuer.ReqCredDlCard:input_type -> issuer.DlCardRequest
+	6,  // 4: issuer.Issuer.ReqCredRentalCarAgreement:input_type -> issuer.RentalCarAgreementRequest
+	8,  // 5: issuer.Issuer.RentalCarControl:input_type -> issuer.RentalCarControlRequest
+	11, // 6: issuer.Issuer.GetIssuedCredential:input_type -> issuer.GetIssuedCredentialRequest
+	12, // 7: issuer.Issuer.ListIssuedCredentials:input_type -> issuer.ListIssuedCredentialsRequest
+	1,  // 8: issuer.Issuer.RequestCredential:output_type -> issuer.CredentialReply
+	3,  // 9: issuer.Issuer.ReqCredIdCard:output_type -> issuer.IdCardReply
+	5,  // 10: issuer.Issuer.ReqCredDlCard:output_type -> issuer.DlCardReply
+	7,  // 11: issuer.Issuer.ReqCredRentalCarAgreement:output_type -> issuer.RentalCarAgreementReply
+	9,  // 12: issuer.Issuer.RentalCarControl:output_type -> issuer.RentalCarControlReply
+	10, // 13: issuer.Issuer.GetIssuedCredential:output_type -> issuer.IssuedCredential
+	13, // 14: issuer.Issuer.ListIssuedCredentials:output_type -> issuer.ListIssuedCredentialsReply
+	8,  // [8:15] is the sub-list for method output_type
+	1,  // [1:8] is the sub-list for method input_type
+	1,  // [1:1] is the sub-list for extension type_name
+	1,  // [1:1] is the sub-list for extension extendee
+	0,  // [0:1] is the sub-list for field type_name
 }
 
 func init() { file_proto_files_issuer_proto_init() }
@@ -612,7 +881,7 @@ func file_proto_files_issuer_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_files_issuer_proto_rawDesc), len(file_proto_files_issuer_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   10,
+			NumMessages:   14,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
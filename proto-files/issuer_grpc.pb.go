@@ -38,6 +38,8 @@ const (
 	Issuer_ReqCredDlCard_FullMethodName             = "/issuer.Issuer/ReqCredDlCard"
 	Issuer_ReqCredRentalCarAgreement_FullMethodName = "/issuer.Issuer/ReqCredRentalCarAgreement"
 	Issuer_RentalCarControl_FullMethodName          = "/issuer.Issuer/RentalCarControl"
+	Issuer_GetIssuedCredential_FullMethodName       = "/issuer.Issuer/GetIssuedCredential"
+	Issuer_ListIssuedCredentials_FullMethodName     = "/issuer.Issuer/ListIssuedCredentials"
 )
 
 // IssuerClient is the client API for Issuer service.
@@ -49,6 +51,8 @@ type IssuerClient interface {
 	ReqCredDlCard(ctx context.Context, in *DlCardRequest, opts ...grpc.CallOption) (*DlCardReply, error)
 	ReqCredRentalCarAgreement(ctx context.Context, in *RentalCarAgreementRequest, opts ...grpc.CallOption) (*RentalCarAgreementReply, error)
 	RentalCarControl(ctx context.Context, in *RentalCarControlRequest, opts ...grpc.CallOption) (*RentalCarControlReply, error)
+	GetIssuedCredential(ctx context.Context, in *GetIssuedCredentialRequest, opts ...grpc.CallOption) (*IssuedCredential, error)
+	ListIssuedCredentials(ctx context.Context, in *ListIssuedCredentialsRequest, opts ...grpc.CallOption) (*ListIssuedCredentialsReply, error)
 }
 
 type issuerClient struct {
@@ -109,6 +113,26 @@ func (c *issuerClient) RentalCarControl(ctx context.Context, in *RentalCarContro
 	return out, nil
 }
 
+func (c *issuerClient) GetIssuedCredential(ctx context.Context, in *GetIssuedCredentialRequest, opts ...grpc.CallOption) (*IssuedCredential, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IssuedCredential)
+	err := c.cc.Invoke(ctx, Issuer_GetIssuedCredential_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *issuerClient) ListIssuedCredentials(ctx context.Context, in *ListIssuedCredentialsRequest, opts ...grpc.CallOption) (*ListIssuedCredentialsReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListIssuedCredentialsReply)
+	err := c.cc.Invoke(ctx, Issuer_ListIssuedCredentials_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // IssuerServer is the server API for Issuer service.
 // All implementations must embed UnimplementedIssuerServer
 // for forward compatibility.
@@ -118,6 +142,8 @@ type IssuerServer interface {
 	ReqCredDlCard(context.Context, *DlCardRequest) (*DlCardReply, error)
 	ReqCredRentalCarAgreement(context.Context, *RentalCarAgreementRequest) (*RentalCarAgreementReply, error)
 	RentalCarControl(context.Context, *RentalCarControlRequest) (*RentalCarControlReply, error)
+	GetIssuedCredential(context.Context, *GetIssuedCredentialRequest) (*IssuedCredential, error)
+	ListIssuedCredentials(context.Context, *ListIssuedCredentialsRequest) (*ListIssuedCredentialsReply, error)
 	mustEmbedUnimplementedIssuerServer()
 }
 
@@ -143,6 +169,12 @@ func (UnimplementedIssuerServer) ReqCredRentalCarAgreement(context.Context, *Ren
 func (UnimplementedIssuerServer) RentalCarControl(context.Context, *RentalCarControlRequest) (*RentalCarControlReply, error) {
 	return nil, status.Error(codes.Unimplemented, "method RentalCarControl not implemented")
 }
+func (UnimplementedIssuerServer) GetIssuedCredential(context.Context, *GetIssuedCredentialRequest) (*IssuedCredential, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetIssuedCredential not implemented")
+}
+func (UnimplementedIssuerServer) ListIssuedCredentials(context.Context, *ListIssuedCredentialsRequest) (*ListIssuedCredentialsReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListIssuedCredentials not implemented")
+}
 func (UnimplementedIssuerServer) mustEmbedUnimplementedIssuerServer() {}
 func (UnimplementedIssuerServer) testEmbeddedByValue()                {}
 
@@ -254,6 +286,42 @@ func _Issuer_RentalCarControl_Handler(srv interface{}, ctx context.Context, dec
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Issuer_GetIssuedCredential_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetIssuedCredentialRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IssuerServer).GetIssuedCredential(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Issuer_GetIssuedCredential_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IssuerServer).GetIssuedCredential(ctx, req.(*GetIssuedCredentialRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Issuer_ListIssuedCredentials_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListIssuedCredentialsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(IssuerServer).ListIssuedCredentials(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Issuer_ListIssuedCredentials_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(IssuerServer).ListIssuedCredentials(ctx, req.(*ListIssuedCredentialsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Issuer_ServiceDesc is the grpc.ServiceDesc for Issuer service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -281,6 +349,14 @@ var Issuer_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "RentalCarControl",
 			Handler:    _Issuer_RentalCarControl_Handler,
 		},
+		{
+			MethodName: "GetIssuedCredential",
+			Handler:    _Issuer_GetIssuedCredential_Handler,
+		},
+		{
+			MethodName: "ListIssuedCredentials",
+			Handler:    _Issuer_ListIssuedCredentials_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto-files/issuer.proto",
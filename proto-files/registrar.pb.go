@@ -332,9 +332,11 @@ func (x *ResolveDidResponse) GetDidDocumentMetadata() string {
 }
 
 type UpdateDidRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Did           string                 `protobuf:"bytes,1,opt,name=did,proto3" json:"did,omitempty"`
-	Document      string                 `protobuf:"bytes,2,opt,name=document,proto3" json:"document,omitempty"`
+	state    protoimpl.MessageState `protogen:"open.v1"`
+	Did      string                 `protobuf:"bytes,1,opt,name=did,proto3" json:"did,omitempty"`
+	Document string                 `protobuf:"bytes,2,opt,name=document,proto3" json:"document,omitempty"`
+	// Compact JWS by a capabilityInvocation key of the DID over the current and the new document.
+	Proof         string `protobuf:"bytes,3,opt,name=proof,proto3" json:"proof,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -383,6 +385,13 @@ func (x *UpdateDidRequest) GetDocument() string {
 	return ""
 }
 
+func (x *UpdateDidRequest) GetProof() string {
+	if x != nil {
+		return x.Proof
+	}
+	return ""
+}
+
 type UpdateDidResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Result        string                 `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
@@ -447,10 +456,11 @@ const file_proto_files_registrar_proto_rawDesc = "" +
 	"\x12ResolveDidResponse\x12)\n" +
 	"\x10resolution_error\x18\x01 \x01(\tR\x0fresolutionError\x12!\n" +
 	"\fdid_document\x18\x02 \x01(\tR\vdidDocument\x122\n" +
-	"\x15did_document_metadata\x18\x03 \x01(\tR\x13didDocumentMetadata\"@\n" +
+	"\x15did_document_metadata\x18\x03 \x01(\tR\x13didDocumentMetadata\"V\n" +
 	"\x10UpdateDidRequest\x12\x10\n" +
 	"\x03did\x18\x01 \x01(\tR\x03did\x12\x1a\n" +
-	"\bdocument\x18\x02 \x01(\tR\bdocument\"+\n" +
+	"\bdocument\x18\x02 \x01(\tR\bdocument\x12\x14\n" +
+	"\x05proof\x18\x03 \x01(\tR\x05proof\"+\n" +
 	"\x11UpdateDidResponse\x12\x16\n" +
 	"\x06result\x18\x01 \x01(\tR\x06result2\xbc\x02\n" +
 	"\tRegistrar\x12H\n" +
@@ -82,6 +82,10 @@ func (x *ChallengeRequest) GetDid() string {
 type ChallengeReply struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AuthChallenge string                 `protobuf:"bytes,1,opt,name=auth_challenge,json=authChallenge,proto3" json:"auth_challenge,omitempty"`
+	// identifies the authentication session; the challenge is bound to the requesting did and single-use
+	SessionId string `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	// unix time after which the challenge is no longer accepted
+	ExpiresAt     int64 `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -123,9 +127,25 @@ func (x *ChallengeReply) GetAuthChallenge() string {
 	return ""
 }
 
+func (x *ChallengeReply) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ChallengeReply) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
 type ResponseRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	AuthResponse  string                 `protobuf:"bytes,1,opt,name=auth_response,json=authResponse,proto3" json:"auth_response,omitempty"`
+	SessionId     string                 `protobuf:"bytes,2,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Did           string                 `protobuf:"bytes,3,opt,name=did,proto3" json:"did,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -167,9 +187,31 @@ func (x *ResponseRequest) GetAuthResponse() string {
 	return ""
 }
 
+func (x *ResponseRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *ResponseRequest) GetDid() string {
+	if x != nil {
+		return x.Did
+	}
+	return ""
+}
+
 type ResponseReply struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Message       string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Message string                 `protobuf:"bytes,1,opt,name=message,proto3" json:"message,omitempty"`
+	// access token (JWT, typ at+jwt) of the authenticated session, set on success; present it in the
+	// authorization metadata of protected calls as "<token_type> <session_token>"
+	SessionToken string `protobuf:"bytes,2,opt,name=session_token,json=sessionToken,proto3" json:"session_token,omitempty"`
+	// unix time the access token expires at
+	ExpiresAt    int64  `protobuf:"varint,3,opt,name=expires_at,json=expiresAt,proto3" json:"expires_at,omitempty"`
+	RefreshToken string `protobuf:"bytes,4,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+	// Bearer, or DPoP when the tokens are bound to the key of dpop_proof
+	TokenType     string `protobuf:"bytes,5,opt,name=token_type,json=tokenType,proto3" json:"token_type,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
@@ -211,16 +253,484 @@ func (x *ResponseReply) GetMessage() string {
 	return ""
 }
 
-type SimplePresentRequest struct {
+func (x *ResponseReply) GetSessionToken() string {
+	if x != nil {
+		return x.SessionToken
+	}
+	return ""
+}
+
+func (x *ResponseReply) GetExpiresAt() int64 {
+	if x != nil {
+		return x.ExpiresAt
+	}
+	return 0
+}
+
+func (x *ResponseReply) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+func (x *ResponseReply) GetTokenType() string {
+	if x != nil {
+		return x.TokenType
+	}
+	return ""
+}
+
+type DidAuthChallengeReply struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Nonce     string                 `protobuf:"bytes,2,opt,name=nonce,proto3" json:"nonce,omitempty"`
+	// identifier of the relying party
+	Aud           string `protobuf:"bytes,3,opt,name=aud,proto3" json:"aud,omitempty"`
+	Domain        string `protobuf:"bytes,4,opt,name=domain,proto3" json:"domain,omitempty"`
+	Iat           int64  `protobuf:"varint,5,opt,name=iat,proto3" json:"iat,omitempty"`
+	Exp           int64  `protobuf:"varint,6,opt,name=exp,proto3" json:"exp,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DidAuthChallengeReply) Reset() {
+	*x = DidAuthChallengeReply{}
+	mi := &file_proto_files_relyingparty_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DidAuthChallengeReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DidAuthChallengeReply) ProtoMessage() {}
+
+func (x *DidAuthChallengeReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_relyingparty_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DidAuthChallengeReply.ProtoReflect.Descriptor instead.
+func (*DidAuthChallengeReply) Descriptor() ([]byte, []int) {
+	return file_proto_files_relyingparty_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *DidAuthChallengeReply) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *DidAuthChallengeReply) GetNonce() string {
+	if x != nil {
+		return x.Nonce
+	}
+	return ""
+}
+
+func (x *DidAuthChallengeReply) GetAud() string {
+	if x != nil {
+		return x.Aud
+	}
+	return ""
+}
+
+func (x *DidAuthChallengeReply) GetDomain() string {
+	if x != nil {
+		return x.Domain
+	}
+	return ""
+}
+
+func (x *DidAuthChallengeReply) GetIat() int64 {
+	if x != nil {
+		return x.Iat
+	}
+	return 0
+}
+
+func (x *DidAuthChallengeReply) GetExp() int64 {
+	if x != nil {
+		return x.Exp
+	}
+	return 0
+}
+
+type DidAuthResponseRequest struct {
+	state     protoimpl.MessageState `protogen:"open.v1"`
+	SessionId string                 `protobuf:"bytes,1,opt,name=session_id,json=sessionId,proto3" json:"session_id,omitempty"`
+	Did       string                 `protobuf:"bytes,2,opt,name=did,proto3" json:"did,omitempty"`
+	// compact JWS (typ didauth+jwt) over the challenge, signed by an authentication key of the did
+	SignedChallenge string `protobuf:"bytes,3,opt,name=signed_challenge,json=signedChallenge,proto3" json:"signed_challenge,omitempty"`
+	// optional DPoP proof (htm POST, htu /relyingparty.RelyingParty/DidAuthResponse) binding the tokens to its key
+	DpopProof     string `protobuf:"bytes,4,opt,name=dpop_proof,json=dpopProof,proto3" json:"dpop_proof,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DidAuthResponseRequest) Reset() {
+	*x = DidAuthResponseRequest{}
+	mi := &file_proto_files_relyingparty_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DidAuthResponseRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DidAuthResponseRequest) ProtoMessage() {}
+
+func (x *DidAuthResponseRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_relyingparty_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DidAuthResponseRequest.ProtoReflect.Descriptor instead.
+func (*DidAuthResponseRequest) Descriptor() ([]byte, []int) {
+	return file_proto_files_relyingparty_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *DidAuthResponseRequest) GetSessionId() string {
+	if x != nil {
+		return x.SessionId
+	}
+	return ""
+}
+
+func (x *DidAuthResponseRequest) GetDid() string {
+	if x != nil {
+		return x.Did
+	}
+	return ""
+}
+
+func (x *DidAuthResponseRequest) GetSignedChallenge() string {
+	if x != nil {
+		return x.SignedChallenge
+	}
+	return ""
+}
+
+func (x *DidAuthResponseRequest) GetDpopProof() string {
+	if x != nil {
+		return x.DpopProof
+	}
+	return ""
+}
+
+type RefreshTokenRequest struct {
+	state        protoimpl.MessageState `protogen:"open.v1"`
+	RefreshToken string                 `protobuf:"bytes,1,opt,name=refresh_token,json=refreshToken,proto3" json:"refresh_token,omitempty"`
+	// DPoP proof (htm POST, htu /relyingparty.RelyingParty/RefreshToken), required for DPoP-bound tokens
+	DpopProof     string `protobuf:"bytes,2,opt,name=dpop_proof,json=dpopProof,proto3" json:"dpop_proof,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RefreshTokenRequest) Reset() {
+	*x = RefreshTokenRequest{}
+	mi := &file_proto_files_relyingparty_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RefreshTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RefreshTokenRequest) ProtoMessage() {}
+
+func (x *RefreshTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_relyingparty_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RefreshTokenRequest.ProtoReflect.Descriptor instead.
+func (*RefreshTokenRequest) Descriptor() ([]byte, []int) {
+	return file_proto_files_relyingparty_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *RefreshTokenRequest) GetRefreshToken() string {
+	if x != nil {
+		return x.RefreshToken
+	}
+	return ""
+}
+
+func (x *RefreshTokenRequest) GetDpopProof() string {
+	if x != nil {
+		return x.DpopProof
+	}
+	return ""
+}
+
+type RevokeTokenRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// refresh or access token
+	Token         string `protobuf:"bytes,1,opt,name=token,proto3" json:"token,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeTokenRequest) Reset() {
+	*x = RevokeTokenRequest{}
+	mi := &file_proto_files_relyingparty_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeTokenRequest) ProtoMessage() {}
+
+func (x *RevokeTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_relyingparty_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeTokenRequest.ProtoReflect.Descriptor instead.
+func (*RevokeTokenRequest) Descriptor() ([]byte, []int) {
+	return file_proto_files_relyingparty_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *RevokeTokenRequest) GetToken() string {
+	if x != nil {
+		return x.Token
+	}
+	return ""
+}
+
+type RevokeTokenReply struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	SimplePresent string                 `protobuf:"bytes,1,opt,name=simple_present,json=simplePresent,proto3" json:"simple_present,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RevokeTokenReply) Reset() {
+	*x = RevokeTokenReply{}
+	mi := &file_proto_files_relyingparty_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RevokeTokenReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RevokeTokenReply) ProtoMessage() {}
+
+func (x *RevokeTokenReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_relyingparty_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RevokeTokenReply.ProtoReflect.Descriptor instead.
+func (*RevokeTokenReply) Descriptor() ([]byte, []int) {
+	return file_proto_files_relyingparty_proto_rawDescGZIP(), []int{8}
+}
+
+type IntrospectTokenRequest struct {
+	state       protoimpl.MessageState `protogen:"open.v1"`
+	AccessToken string                 `protobuf:"bytes,1,opt,name=access_token,json=accessToken,proto3" json:"access_token,omitempty"`
+	// DPoP proof of the protected call, required for DPoP-bound tokens
+	DpopProof string `protobuf:"bytes,2,opt,name=dpop_proof,json=dpopProof,proto3" json:"dpop_proof,omitempty"`
+	// method and uri the DPoP proof must be made for
+	Htm           string `protobuf:"bytes,3,opt,name=htm,proto3" json:"htm,omitempty"`
+	Htu           string `protobuf:"bytes,4,opt,name=htu,proto3" json:"htu,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IntrospectTokenRequest) Reset() {
+	*x = IntrospectTokenRequest{}
+	mi := &file_proto_files_relyingparty_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IntrospectTokenRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IntrospectTokenRequest) ProtoMessage() {}
+
+func (x *IntrospectTokenRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_relyingparty_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IntrospectTokenRequest.ProtoReflect.Descriptor instead.
+func (*IntrospectTokenRequest) Descriptor() ([]byte, []int) {
+	return file_proto_files_relyingparty_proto_rawDescGZIP(), []int{9}
+}
+
+func (x *IntrospectTokenRequest) GetAccessToken() string {
+	if x != nil {
+		return x.AccessToken
+	}
+	return ""
+}
+
+func (x *IntrospectTokenRequest) GetDpopProof() string {
+	if x != nil {
+		return x.DpopProof
+	}
+	return ""
+}
+
+func (x *IntrospectTokenRequest) GetHtm() string {
+	if x != nil {
+		return x.Htm
+	}
+	return ""
+}
+
+func (x *IntrospectTokenRequest) GetHtu() string {
+	if x != nil {
+		return x.Htu
+	}
+	return ""
+}
+
+type IntrospectTokenReply struct {
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Active bool                   `protobuf:"varint,1,opt,name=active,proto3" json:"active,omitempty"`
+	// authenticated did, when active
+	Did string `protobuf:"bytes,2,opt,name=did,proto3" json:"did,omitempty"`
+	Exp int64  `protobuf:"varint,3,opt,name=exp,proto3" json:"exp,omitempty"`
+	// JWK thumbprint of the key DPoP-bound tokens are bound to
+	Jkt string `protobuf:"bytes,4,opt,name=jkt,proto3" json:"jkt,omitempty"`
+	// why the token was rejected: malformed, invalid_signature, expired, revoked, replayed or invalid_dpop_proof
+	Error         string `protobuf:"bytes,5,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *IntrospectTokenReply) Reset() {
+	*x = IntrospectTokenReply{}
+	mi := &file_proto_files_relyingparty_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *IntrospectTokenReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*IntrospectTokenReply) ProtoMessage() {}
+
+func (x *IntrospectTokenReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_relyingparty_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use IntrospectTokenReply.ProtoReflect.Descriptor instead.
+func (*IntrospectTokenReply) Descriptor() ([]byte, []int) {
+	return file_proto_files_relyingparty_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *IntrospectTokenReply) GetActive() bool {
+	if x != nil {
+		return x.Active
+	}
+	return false
+}
+
+func (x *IntrospectTokenReply) GetDid() string {
+	if x != nil {
+		return x.Did
+	}
+	return ""
+}
+
+func (x *IntrospectTokenReply) GetExp() int64 {
+	if x != nil {
+		return x.Exp
+	}
+	return 0
+}
+
+func (x *IntrospectTokenReply) GetJkt() string {
+	if x != nil {
+		return x.Jkt
+	}
+	return ""
+}
+
+func (x *IntrospectTokenReply) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type SimplePresentRequest struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// DID auth token (compact JWS, typ didauth-token+jwt) for this relying party; the legacy
+	// "did;time;signature" string is still accepted
+	SimplePresent string `protobuf:"bytes,1,opt,name=simple_present,json=simplePresent,proto3" json:"simple_present,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SimplePresentRequest) Reset() {
 	*x = SimplePresentRequest{}
-	mi := &file_proto_files_relyingparty_proto_msgTypes[4]
+	mi := &file_proto_files_relyingparty_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -232,7 +742,7 @@ func (x *SimplePresentRequest) String() string {
 func (*SimplePresentRequest) ProtoMessage() {}
 
 func (x *SimplePresentRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_files_relyingparty_proto_msgTypes[4]
+	mi := &file_proto_files_relyingparty_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -245,7 +755,7 @@ func (x *SimplePresentRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SimplePresentRequest.ProtoReflect.Descriptor instead.
 func (*SimplePresentRequest) Descriptor() ([]byte, []int) {
-	return file_proto_files_relyingparty_proto_rawDescGZIP(), []int{4}
+	return file_proto_files_relyingparty_proto_rawDescGZIP(), []int{11}
 }
 
 func (x *SimplePresentRequest) GetSimplePresent() string {
@@ -256,15 +766,20 @@ func (x *SimplePresentRequest) GetSimplePresent() string {
 }
 
 type SimplePresentReply struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Result        string                 `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	state  protoimpl.MessageState `protogen:"open.v1"`
+	Result string                 `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	// authenticated did, on success
+	Did string `protobuf:"bytes,2,opt,name=did,proto3" json:"did,omitempty"`
+	// why the presentation was rejected: malformed, invalid_signature, invalid_issuer, invalid_audience,
+	// invalid_lifetime, expired, not_yet_valid or replayed
+	Error         string `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *SimplePresentReply) Reset() {
 	*x = SimplePresentReply{}
-	mi := &file_proto_files_relyingparty_proto_msgTypes[5]
+	mi := &file_proto_files_relyingparty_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -276,7 +791,7 @@ func (x *SimplePresentReply) String() string {
 func (*SimplePresentReply) ProtoMessage() {}
 
 func (x *SimplePresentReply) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_files_relyingparty_proto_msgTypes[5]
+	mi := &file_proto_files_relyingparty_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -289,7 +804,7 @@ func (x *SimplePresentReply) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use SimplePresentReply.ProtoReflect.Descriptor instead.
 func (*SimplePresentReply) Descriptor() ([]byte, []int) {
-	return file_proto_files_relyingparty_proto_rawDescGZIP(), []int{5}
+	return file_proto_files_relyingparty_proto_rawDescGZIP(), []int{12}
 }
 
 func (x *SimplePresentReply) GetResult() string {
@@ -299,16 +814,32 @@ func (x *SimplePresentReply) GetResult() string {
 	return ""
 }
 
+func (x *SimplePresentReply) GetDid() string {
+	if x != nil {
+		return x.Did
+	}
+	return ""
+}
+
+func (x *SimplePresentReply) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
 type VerifyVpRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Vp            string                 `protobuf:"bytes,1,opt,name=vp,proto3" json:"vp,omitempty"`
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Vp    string                 `protobuf:"bytes,1,opt,name=vp,proto3" json:"vp,omitempty"`
+	// when set, the presentation_submission of the vp is evaluated against this presentation definition
+	DefinitionId  string `protobuf:"bytes,2,opt,name=definition_id,json=definitionId,proto3" json:"definition_id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *VerifyVpRequest) Reset() {
 	*x = VerifyVpRequest{}
-	mi := &file_proto_files_relyingparty_proto_msgTypes[6]
+	mi := &file_proto_files_relyingparty_proto_msgTypes[13]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -320,7 +851,7 @@ func (x *VerifyVpRequest) String() string {
 func (*VerifyVpRequest) ProtoMessage() {}
 
 func (x *VerifyVpRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_files_relyingparty_proto_msgTypes[6]
+	mi := &file_proto_files_relyingparty_proto_msgTypes[13]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -333,7 +864,7 @@ func (x *VerifyVpRequest) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VerifyVpRequest.ProtoReflect.Descriptor instead.
 func (*VerifyVpRequest) Descriptor() ([]byte, []int) {
-	return file_proto_files_relyingparty_proto_rawDescGZIP(), []int{6}
+	return file_proto_files_relyingparty_proto_rawDescGZIP(), []int{13}
 }
 
 func (x *VerifyVpRequest) GetVp() string {
@@ -343,16 +874,24 @@ func (x *VerifyVpRequest) GetVp() string {
 	return ""
 }
 
+func (x *VerifyVpRequest) GetDefinitionId() string {
+	if x != nil {
+		return x.DefinitionId
+	}
+	return ""
+}
+
 type VerifyVpReply struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Result        string                 `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	Error         string                 `protobuf:"bytes,2,opt,name=error,proto3" json:"error,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
 func (x *VerifyVpReply) Reset() {
 	*x = VerifyVpReply{}
-	mi := &file_proto_files_relyingparty_proto_msgTypes[7]
+	mi := &file_proto_files_relyingparty_proto_msgTypes[14]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
@@ -364,7 +903,7 @@ func (x *VerifyVpReply) String() string {
 func (*VerifyVpReply) ProtoMessage() {}
 
 func (x *VerifyVpReply) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_files_relyingparty_proto_msgTypes[7]
+	mi := &file_proto_files_relyingparty_proto_msgTypes[14]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -377,7 +916,7 @@ func (x *VerifyVpReply) ProtoReflect() protoreflect.Message {
 
 // Deprecated: Use VerifyVpReply.ProtoReflect.Descriptor instead.
 func (*VerifyVpReply) Descriptor() ([]byte, []int) {
-	return file_proto_files_relyingparty_proto_rawDescGZIP(), []int{7}
+	return file_proto_files_relyingparty_proto_rawDescGZIP(), []int{14}
 }
 
 func (x *VerifyVpReply) GetResult() string {
@@ -387,32 +926,189 @@ func (x *VerifyVpReply) GetResult() string {
 	return ""
 }
 
+func (x *VerifyVpReply) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+type PresentationDefinitionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	DefinitionId  string                 `protobuf:"bytes,1,opt,name=definition_id,json=definitionId,proto3" json:"definition_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *PresentationDefinitionRequest) Reset() {
+	*x = PresentationDefinitionRequest{}
+	mi := &file_proto_files_relyingparty_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PresentationDefinitionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PresentationDefinitionRequest) ProtoMessage() {}
+
+func (x *PresentationDefinitionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_relyingparty_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PresentationDefinitionRequest.ProtoReflect.Descriptor instead.
+func (*PresentationDefinitionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_files_relyingparty_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *PresentationDefinitionRequest) GetDefinitionId() string {
+	if x != nil {
+		return x.DefinitionId
+	}
+	return ""
+}
+
+type PresentationDefinitionReply struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// DIF Presentation Exchange v2 presentation_definition (JSON)
+	PresentationDefinition string `protobuf:"bytes,1,opt,name=presentation_definition,json=presentationDefinition,proto3" json:"presentation_definition,omitempty"`
+	unknownFields          protoimpl.UnknownFields
+	sizeCache              protoimpl.SizeCache
+}
+
+func (x *PresentationDefinitionReply) Reset() {
+	*x = PresentationDefinitionReply{}
+	mi := &file_proto_files_relyingparty_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *PresentationDefinitionReply) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*PresentationDefinitionReply) ProtoMessage() {}
+
+func (x *PresentationDefinitionReply) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_relyingparty_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use PresentationDefinitionReply.ProtoReflect.Descriptor instead.
+func (*PresentationDefinitionReply) Descriptor() ([]byte, []int) {
+	return file_proto_files_relyingparty_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *PresentationDefinitionReply) GetPresentationDefinition() string {
+	if x != nil {
+		return x.PresentationDefinition
+	}
+	return ""
+}
+
 var File_proto_files_relyingparty_proto protoreflect.FileDescriptor
 
 const file_proto_files_relyingparty_proto_rawDesc = "" +
 	"\n" +
 	"\x1eproto-files/relyingparty.proto\x12\frelyingparty\"$\n" +
 	"\x10ChallengeRequest\x12\x10\n" +
-	"\x03did\x18\x01 \x01(\tR\x03did\"7\n" +
+	"\x03did\x18\x01 \x01(\tR\x03did\"u\n" +
 	"\x0eChallengeReply\x12%\n" +
-	"\x0eauth_challenge\x18\x01 \x01(\tR\rauthChallenge\"6\n" +
+	"\x0eauth_challenge\x18\x01 \x01(\tR\rauthChallenge\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\"g\n" +
 	"\x0fResponseRequest\x12#\n" +
-	"\rauth_response\x18\x01 \x01(\tR\fauthResponse\")\n" +
+	"\rauth_response\x18\x01 \x01(\tR\fauthResponse\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x02 \x01(\tR\tsessionId\x12\x10\n" +
+	"\x03did\x18\x03 \x01(\tR\x03did\"\xb1\x01\n" +
 	"\rResponseReply\x12\x18\n" +
-	"\amessage\x18\x01 \x01(\tR\amessage\"=\n" +
+	"\amessage\x18\x01 \x01(\tR\amessage\x12#\n" +
+	"\rsession_token\x18\x02 \x01(\tR\fsessionToken\x12\x1d\n" +
+	"\n" +
+	"expires_at\x18\x03 \x01(\x03R\texpiresAt\x12#\n" +
+	"\rrefresh_token\x18\x04 \x01(\tR\frefreshToken\x12\x1d\n" +
+	"\n" +
+	"token_type\x18\x05 \x01(\tR\ttokenType\"\x9a\x01\n" +
+	"\x15DidAuthChallengeReply\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x14\n" +
+	"\x05nonce\x18\x02 \x01(\tR\x05nonce\x12\x10\n" +
+	"\x03aud\x18\x03 \x01(\tR\x03aud\x12\x16\n" +
+	"\x06domain\x18\x04 \x01(\tR\x06domain\x12\x10\n" +
+	"\x03iat\x18\x05 \x01(\x03R\x03iat\x12\x10\n" +
+	"\x03exp\x18\x06 \x01(\x03R\x03exp\"\x93\x01\n" +
+	"\x16DidAuthResponseRequest\x12\x1d\n" +
+	"\n" +
+	"session_id\x18\x01 \x01(\tR\tsessionId\x12\x10\n" +
+	"\x03did\x18\x02 \x01(\tR\x03did\x12)\n" +
+	"\x10signed_challenge\x18\x03 \x01(\tR\x0fsignedChallenge\x12\x1d\n" +
+	"\n" +
+	"dpop_proof\x18\x04 \x01(\tR\tdpopProof\"Y\n" +
+	"\x13RefreshTokenRequest\x12#\n" +
+	"\rrefresh_token\x18\x01 \x01(\tR\frefreshToken\x12\x1d\n" +
+	"\n" +
+	"dpop_proof\x18\x02 \x01(\tR\tdpopProof\"*\n" +
+	"\x12RevokeTokenRequest\x12\x14\n" +
+	"\x05token\x18\x01 \x01(\tR\x05token\"\x12\n" +
+	"\x10RevokeTokenReply\"~\n" +
+	"\x16IntrospectTokenRequest\x12!\n" +
+	"\faccess_token\x18\x01 \x01(\tR\vaccessToken\x12\x1d\n" +
+	"\n" +
+	"dpop_proof\x18\x02 \x01(\tR\tdpopProof\x12\x10\n" +
+	"\x03htm\x18\x03 \x01(\tR\x03htm\x12\x10\n" +
+	"\x03htu\x18\x04 \x01(\tR\x03htu\"z\n" +
+	"\x14IntrospectTokenReply\x12\x16\n" +
+	"\x06active\x18\x01 \x01(\bR\x06active\x12\x10\n" +
+	"\x03did\x18\x02 \x01(\tR\x03did\x12\x10\n" +
+	"\x03exp\x18\x03 \x01(\x03R\x03exp\x12\x10\n" +
+	"\x03jkt\x18\x04 \x01(\tR\x03jkt\x12\x14\n" +
+	"\x05error\x18\x05 \x01(\tR\x05error\"=\n" +
 	"\x14SimplePresentRequest\x12%\n" +
-	"\x0esimple_present\x18\x01 \x01(\tR\rsimplePresent\",\n" +
+	"\x0esimple_present\x18\x01 \x01(\tR\rsimplePresent\"T\n" +
 	"\x12SimplePresentReply\x12\x16\n" +
-	"\x06result\x18\x01 \x01(\tR\x06result\"!\n" +
+	"\x06result\x18\x01 \x01(\tR\x06result\x12\x10\n" +
+	"\x03did\x18\x02 \x01(\tR\x03did\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"F\n" +
 	"\x0fVerifyVpRequest\x12\x0e\n" +
-	"\x02vp\x18\x01 \x01(\tR\x02vp\"'\n" +
+	"\x02vp\x18\x01 \x01(\tR\x02vp\x12#\n" +
+	"\rdefinition_id\x18\x02 \x01(\tR\fdefinitionId\"=\n" +
 	"\rVerifyVpReply\x12\x16\n" +
-	"\x06result\x18\x01 \x01(\tR\x06result2\xd0\x02\n" +
-	"\fRelyingParty\x12O\n" +
-	"\rAuthChallenge\x12\x1e.relyingparty.ChallengeRequest\x1a\x1c.relyingparty.ChallengeReply\"\x00\x12L\n" +
-	"\fAuthResponse\x12\x1d.relyingparty.ResponseRequest\x1a\x1b.relyingparty.ResponseReply\"\x00\x12W\n" +
+	"\x06result\x18\x01 \x01(\tR\x06result\x12\x14\n" +
+	"\x05error\x18\x02 \x01(\tR\x05error\"D\n" +
+	"\x1dPresentationDefinitionRequest\x12#\n" +
+	"\rdefinition_id\x18\x01 \x01(\tR\fdefinitionId\"V\n" +
+	"\x1bPresentationDefinitionReply\x127\n" +
+	"\x17presentation_definition\x18\x01 \x01(\tR\x16presentationDefinition2\x84\a\n" +
+	"\fRelyingParty\x12R\n" +
+	"\rAuthChallenge\x12\x1e.relyingparty.ChallengeRequest\x1a\x1c.relyingparty.ChallengeReply\"\x03\x88\x02\x01\x12O\n" +
+	"\fAuthResponse\x12\x1d.relyingparty.ResponseRequest\x1a\x1b.relyingparty.ResponseReply\"\x03\x88\x02\x01\x12Y\n" +
+	"\x10DidAuthChallenge\x12\x1e.relyingparty.ChallengeRequest\x1a#.relyingparty.DidAuthChallengeReply\"\x00\x12V\n" +
+	"\x0fDidAuthResponse\x12$.relyingparty.DidAuthResponseRequest\x1a\x1b.relyingparty.ResponseReply\"\x00\x12P\n" +
+	"\fRefreshToken\x12!.relyingparty.RefreshTokenRequest\x1a\x1b.relyingparty.ResponseReply\"\x00\x12Q\n" +
+	"\vRevokeToken\x12 .relyingparty.RevokeTokenRequest\x1a\x1e.relyingparty.RevokeTokenReply\"\x00\x12]\n" +
+	"\x0fIntrospectToken\x12$.relyingparty.IntrospectTokenRequest\x1a\".relyingparty.IntrospectTokenReply\"\x00\x12W\n" +
 	"\rSimplePresent\x12\".relyingparty.SimplePresentRequest\x1a .relyingparty.SimplePresentReply\"\x00\x12H\n" +
-	"\bVerifyVp\x12\x1d.relyingparty.VerifyVpRequest\x1a\x1b.relyingparty.VerifyVpReply\"\x00BH\n" +
+	"\bVerifyVp\x12\x1d.relyingparty.VerifyVpRequest\x1a\x1b.relyingparty.VerifyVpReply\"\x00\x12u\n" +
+	"\x19GetPresentationDefinition\x12+.relyingparty.PresentationDefinitionRequest\x1a).relyingparty.PresentationDefinitionReply\"\x00BH\n" +
 	"\x1cio.grpc.examples.proto-filesB\x0fHelloWorldProtoP\x01Z\x15byd50-ssi/proto-filesb\x06proto3"
 
 var (
@@ -427,31 +1123,52 @@ func file_proto_files_relyingparty_proto_rawDescGZIP() []byte {
 	return file_proto_files_relyingparty_proto_rawDescData
 }
 
-var file_proto_files_relyingparty_proto_msgTypes = make([]protoimpl.MessageInfo, 8)
+var file_proto_files_relyingparty_proto_msgTypes = make([]protoimpl.MessageInfo, 17)
 var file_proto_files_relyingparty_proto_goTypes = []any{
-	(*ChallengeRequest)(nil),     // 0: relyingparty.ChallengeRequest
-	(*ChallengeReply)(nil),       // 1: relyingparty.ChallengeReply
-	(*ResponseRequest)(nil),      // 2: relyingparty.ResponseRequest
-	(*ResponseReply)(nil),        // 3: relyingparty.ResponseReply
-	(*SimplePresentRequest)(nil), // 4: relyingparty.SimplePresentRequest
-	(*SimplePresentReply)(nil),   // 5: relyingparty.SimplePresentReply
-	(*VerifyVpRequest)(nil),      // 6: relyingparty.VerifyVpRequest
-	(*VerifyVpReply)(nil),        // 7: relyingparty.VerifyVpReply
+	(*ChallengeRequest)(nil),              // 0: relyingparty.ChallengeRequest
+	(*ChallengeReply)(nil),                // 1: relyingparty.ChallengeReply
+	(*ResponseRequest)(nil),               // 2: relyingparty.ResponseRequest
+	(*ResponseReply)(nil),                 // 3: relyingparty.ResponseReply
+	(*DidAuthChallengeReply)(nil),         // 4: relyingparty.DidAuthChallengeReply
+	(*DidAuthResponseRequest)(nil),        // 5: relyingparty.DidAuthResponseRequest
+	(*RefreshTokenRequest)(nil),           // 6: relyingparty.RefreshTokenRequest
+	(*RevokeTokenRequest)(nil),            // 7: relyingparty.RevokeTokenRequest
+	(*RevokeTokenReply)(nil),              // 8: relyingparty.RevokeTokenReply
+	(*IntrospectTokenRequest)(nil),        // 9: relyingparty.IntrospectTokenRequest
+	(*IntrospectTokenReply)(nil),          // 10: relyingparty.IntrospectTokenReply
+	(*SimplePresentRequest)(nil),          // 11: relyingparty.SimplePresentRequest
+	(*SimplePresentReply)(nil),            // 12: relyingparty.SimplePresentReply
+	(*VerifyVpRequest)(nil),               // 13: relyingparty.VerifyVpRequest
+	(*VerifyVpReply)(nil),                 // 14: relyingparty.VerifyVpReply
+	(*PresentationDefinitionRequest)(nil), // 15: relyingparty.PresentationDefinitionRequest
+	(*PresentationDefinitionReply)(nil),   // 16: relyingparty.PresentationDefinitionReply
 }
 var file_proto_files_relyingparty_proto_depIdxs = []int32{
-	0, // 0: relyingparty.RelyingParty.AuthChallenge:input_type -> relyingparty.ChallengeRequest
-	2, // 1: relyingparty.RelyingParty.AuthResponse:input_type -> relyingparty.ResponseRequest
-	4, // 2: relyingparty.RelyingParty.SimplePresent:input_type -> relyingparty.SimplePresentRequest
-	6, // 3: relyingparty.RelyingParty.VerifyVp:input_type -> relyingparty.VerifyVpRequest
-	1, // 4: relyingparty.RelyingParty.AuthChallenge:output_type -> relyingparty.ChallengeReply
-	3, // 5: relyingparty.RelyingParty.AuthResponse:output_type -> relyingparty.ResponseReply
-	5, // 6: relyingparty.RelyingParty.SimplePresent:output_type -> relyingparty.SimplePresentReply
-	7, // 7: relyingparty.RelyingParty.VerifyVp:output_type -> relyingparty.VerifyVpReply
-	4, // [4:8] is the sub-list for method output_type
-	0, // [0:4] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: relyingparty.RelyingParty.AuthChallenge:input_type -> relyingparty.ChallengeRequest
+	2,  // 1: relyingparty.RelyingParty.AuthResponse:input_type -> relyingparty.ResponseRequest
+	0,  // 2: relyingparty.RelyingParty.DidAuthChallenge:input_type -> relyingparty.ChallengeRequest
+	5,  // 3: relyingparty.RelyingParty.DidAuthResponse:input_type -> relyingparty.DidAuthResponseRequest
+	6,  // 4: relyingparty.RelyingParty.RefreshToken:input_type -> relyingparty.RefreshTokenRequest
+	7,  // 5: relyingparty.RelyingParty.RevokeToken:input_type -> relyingparty.RevokeTokenRequest
+	9,  // 6: relyingparty.RelyingParty.IntrospectToken:input_type -> relyingparty.IntrospectTokenRequest
+	11, // 7: relyingparty.RelyingParty.SimplePresent:input_type -> relyingparty.SimplePresentRequest
+	13, // 8: relyingparty.RelyingParty.VerifyVp:input_type -> relyingparty.VerifyVpRequest
+	15, // 9: relyingparty.RelyingParty.GetPresentationDefinition:input_type -> relyingparty.PresentationDefinitionRequest
+	1,  // 10: relyingparty.RelyingParty.AuthChallenge:output_type -> relyingparty.ChallengeReply
+	3,  // 11: relyingparty.RelyingParty.AuthResponse:output_type -> relyingparty.ResponseReply
+	4,  // 12: relyingparty.RelyingParty.DidAuthChallenge:output_type -> relyingparty.DidAuthChallengeReply
+	3,  // 13: relyingparty.RelyingParty.DidAuthResponse:output_type -> relyingparty.ResponseReply
+	3,  // 14: relyingparty.RelyingParty.RefreshToken:output_type -> relyingparty.ResponseReply
+	8,  // 15: relyingparty.RelyingParty.RevokeToken:output_type -> relyingparty.RevokeTokenReply
+	10, // 16: relyingparty.RelyingParty.IntrospectToken:output_type -> relyingparty.IntrospectTokenReply
+	12, // 17: relyingparty.RelyingParty.SimplePresent:output_type -> relyingparty.SimplePresentReply
+	14, // 18: relyingparty.RelyingParty.VerifyVp:output_type -> relyingparty.VerifyVpReply
+	16, // 19: relyingparty.RelyingParty.GetPresentationDefinition:output_type -> relyingparty.PresentationDefinitionReply
+	10, // [10:20] is the sub-list for method output_type
+	0,  // [0:10] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
 }
 
 func init() { file_proto_files_relyingparty_proto_init() }
@@ -465,7 +1182,7 @@ func file_proto_files_relyingparty_proto_init() {
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_files_relyingparty_proto_rawDesc), len(file_proto_files_relyingparty_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   8,
+			NumMessages:   17,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
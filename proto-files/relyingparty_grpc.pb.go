@@ -33,20 +33,41 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	RelyingParty_AuthChallenge_FullMethodName = "/relyingparty.RelyingParty/AuthChallenge"
-	RelyingParty_AuthResponse_FullMethodName  = "/relyingparty.RelyingParty/AuthResponse"
-	RelyingParty_SimplePresent_FullMethodName = "/relyingparty.RelyingParty/SimplePresent"
-	RelyingParty_VerifyVp_FullMethodName      = "/relyingparty.RelyingParty/VerifyVp"
+	RelyingParty_AuthChallenge_FullMethodName             = "/relyingparty.RelyingParty/AuthChallenge"
+	RelyingParty_AuthResponse_FullMethodName              = "/relyingparty.RelyingParty/AuthResponse"
+	RelyingParty_DidAuthChallenge_FullMethodName          = "/relyingparty.RelyingParty/DidAuthChallenge"
+	RelyingParty_DidAuthResponse_FullMethodName           = "/relyingparty.RelyingParty/DidAuthResponse"
+	RelyingParty_RefreshToken_FullMethodName              = "/relyingparty.RelyingParty/RefreshToken"
+	RelyingParty_RevokeToken_FullMethodName               = "/relyingparty.RelyingParty/RevokeToken"
+	RelyingParty_IntrospectToken_FullMethodName           = "/relyingparty.RelyingParty/IntrospectToken"
+	RelyingParty_SimplePresent_FullMethodName             = "/relyingparty.RelyingParty/SimplePresent"
+	RelyingParty_VerifyVp_FullMethodName                  = "/relyingparty.RelyingParty/VerifyVp"
+	RelyingParty_GetPresentationDefinition_FullMethodName = "/relyingparty.RelyingParty/GetPresentationDefinition"
 )
 
 // RelyingPartyClient is the client API for RelyingParty service.
 //
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type RelyingPartyClient interface {
+	// Deprecated: Do not use.
+	// The RSA-OAEP encrypted challenge only works for RSA keys; use DidAuthChallenge instead.
 	AuthChallenge(ctx context.Context, in *ChallengeRequest, opts ...grpc.CallOption) (*ChallengeReply, error)
+	// Deprecated: Do not use.
+	// Use DidAuthResponse instead.
 	AuthResponse(ctx context.Context, in *ResponseRequest, opts ...grpc.CallOption) (*ResponseReply, error)
+	// DidAuthChallenge issues a structured challenge for the did to sign with any of its authentication keys.
+	DidAuthChallenge(ctx context.Context, in *ChallengeRequest, opts ...grpc.CallOption) (*DidAuthChallengeReply, error)
+	// DidAuthResponse verifies the signed challenge and returns an access and a refresh token on success.
+	DidAuthResponse(ctx context.Context, in *DidAuthResponseRequest, opts ...grpc.CallOption) (*ResponseReply, error)
+	// RefreshToken exchanges a refresh token for new tokens; the refresh token is single-use.
+	RefreshToken(ctx context.Context, in *RefreshTokenRequest, opts ...grpc.CallOption) (*ResponseReply, error)
+	// RevokeToken ends the session of a refresh token, or revokes an access token.
+	RevokeToken(ctx context.Context, in *RevokeTokenRequest, opts ...grpc.CallOption) (*RevokeTokenReply, error)
+	// IntrospectToken validates an access token for a protected service (RFC 7662 style).
+	IntrospectToken(ctx context.Context, in *IntrospectTokenRequest, opts ...grpc.CallOption) (*IntrospectTokenReply, error)
 	SimplePresent(ctx context.Context, in *SimplePresentRequest, opts ...grpc.CallOption) (*SimplePresentReply, error)
 	VerifyVp(ctx context.Context, in *VerifyVpRequest, opts ...grpc.CallOption) (*VerifyVpReply, error)
+	GetPresentationDefinition(ctx context.Context, in *PresentationDefinitionRequest, opts ...grpc.CallOption) (*PresentationDefinitionReply, error)
 }
 
 type relyingPartyClient struct {
@@ -57,6 +78,7 @@ func NewRelyingPartyClient(cc grpc.ClientConnInterface) RelyingPartyClient {
 	return &relyingPartyClient{cc}
 }
 
+// Deprecated: Do not use.
 func (c *relyingPartyClient) AuthChallenge(ctx context.Context, in *ChallengeRequest, opts ...grpc.CallOption) (*ChallengeReply, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ChallengeReply)
@@ -67,6 +89,7 @@ func (c *relyingPartyClient) AuthChallenge(ctx context.Context, in *ChallengeReq
 	return out, nil
 }
 
+// Deprecated: Do not use.
 func (c *relyingPartyClient) AuthResponse(ctx context.Context, in *ResponseRequest, opts ...grpc.CallOption) (*ResponseReply, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ResponseReply)
@@ -77,6 +100,56 @@ func (c *relyingPartyClient) AuthResponse(ctx context.Context, in *ResponseReque
 	return out, nil
 }
 
+func (c *relyingPartyClient) DidAuthChallenge(ctx context.Context, in *ChallengeRequest, opts ...grpc.CallOption) (*DidAuthChallengeReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DidAuthChallengeReply)
+	err := c.cc.Invoke(ctx, RelyingParty_DidAuthChallenge_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *relyingPartyClient) DidAuthResponse(ctx context.Context, in *DidAuthResponseRequest, opts ...grpc.CallOption) (*ResponseReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResponseReply)
+	err := c.cc.Invoke(ctx, RelyingParty_DidAuthResponse_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *relyingPartyClient) RefreshToken(ctx context.Context, in *RefreshTokenRequest, opts ...grpc.CallOption) (*ResponseReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ResponseReply)
+	err := c.cc.Invoke(ctx, RelyingParty_RefreshToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *relyingPartyClient) RevokeToken(ctx context.Context, in *RevokeTokenRequest, opts ...grpc.CallOption) (*RevokeTokenReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(RevokeTokenReply)
+	err := c.cc.Invoke(ctx, RelyingParty_RevokeToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *relyingPartyClient) IntrospectToken(ctx context.Context, in *IntrospectTokenRequest, opts ...grpc.CallOption) (*IntrospectTokenReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(IntrospectTokenReply)
+	err := c.cc.Invoke(ctx, RelyingParty_IntrospectToken_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *relyingPartyClient) SimplePresent(ctx context.Context, in *SimplePresentRequest, opts ...grpc.CallOption) (*SimplePresentReply, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(SimplePresentReply)
@@ -97,14 +170,39 @@ func (c *relyingPartyClient) VerifyVp(ctx context.Context, in *VerifyVpRequest,
 	return out, nil
 }
 
+func (c *relyingPartyClient) GetPresentationDefinition(ctx context.Context, in *PresentationDefinitionRequest, opts ...grpc.CallOption) (*PresentationDefinitionReply, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(PresentationDefinitionReply)
+	err := c.cc.Invoke(ctx, RelyingParty_GetPresentationDefinition_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // RelyingPartyServer is the server API for RelyingParty service.
 // All implementations must embed UnimplementedRelyingPartyServer
 // for forward compatibility.
 type RelyingPartyServer interface {
+	// Deprecated: Do not use.
+	// The RSA-OAEP encrypted challenge only works for RSA keys; use DidAuthChallenge instead.
 	AuthChallenge(context.Context, *ChallengeRequest) (*ChallengeReply, error)
+	// Deprecated: Do not use.
+	// Use DidAuthResponse instead.
 	AuthResponse(context.Context, *ResponseRequest) (*ResponseReply, error)
+	// DidAuthChallenge issues a structured challenge for the did to sign with any of its authentication keys.
+	DidAuthChallenge(context.Context, *ChallengeRequest) (*DidAuthChallengeReply, error)
+	// DidAuthResponse verifies the signed challenge and returns an access and a refresh token on success.
+	DidAuthResponse(context.Context, *DidAuthResponseRequest) (*ResponseReply, error)
+	// RefreshToken exchanges a refresh token for new tokens; the refresh token is single-use.
+	RefreshToken(context.Context, *RefreshTokenRequest) (*ResponseReply, error)
+	// RevokeToken ends the session of a refresh token, or revokes an access token.
+	RevokeToken(context.Context, *RevokeTokenRequest) (*RevokeTokenReply, error)
+	// IntrospectToken validates an access token for a protected service (RFC 7662 style).
+	IntrospectToken(context.Context, *IntrospectTokenRequest) (*IntrospectTokenReply, error)
 	SimplePresent(context.Context, *SimplePresentRequest) (*SimplePresentReply, error)
 	VerifyVp(context.Context, *VerifyVpRequest) (*VerifyVpReply, error)
+	GetPresentationDefinition(context.Context, *PresentationDefinitionRequest) (*PresentationDefinitionReply, error)
 	mustEmbedUnimplementedRelyingPartyServer()
 }
 
@@ -121,12 +219,30 @@ func (UnimplementedRelyingPartyServer) AuthChallenge(context.Context, *Challenge
 func (UnimplementedRelyingPartyServer) AuthResponse(context.Context, *ResponseRequest) (*ResponseReply, error) {
 	return nil, status.Error(codes.Unimplemented, "method AuthResponse not implemented")
 }
+func (UnimplementedRelyingPartyServer) DidAuthChallenge(context.Context, *ChallengeRequest) (*DidAuthChallengeReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method DidAuthChallenge not implemented")
+}
+func (UnimplementedRelyingPartyServer) DidAuthResponse(context.Context, *DidAuthResponseRequest) (*ResponseReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method DidAuthResponse not implemented")
+}
+func (UnimplementedRelyingPartyServer) RefreshToken(context.Context, *RefreshTokenRequest) (*ResponseReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method RefreshToken not implemented")
+}
+func (UnimplementedRelyingPartyServer) RevokeToken(context.Context, *RevokeTokenRequest) (*RevokeTokenReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method RevokeToken not implemented")
+}
+func (UnimplementedRelyingPartyServer) IntrospectToken(context.Context, *IntrospectTokenRequest) (*IntrospectTokenReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method IntrospectToken not implemented")
+}
 func (UnimplementedRelyingPartyServer) SimplePresent(context.Context, *SimplePresentRequest) (*SimplePresentReply, error) {
 	return nil, status.Error(codes.Unimplemented, "method SimplePresent not implemented")
 }
 func (UnimplementedRelyingPartyServer) VerifyVp(context.Context, *VerifyVpRequest) (*VerifyVpReply, error) {
 	return nil, status.Error(codes.Unimplemented, "method VerifyVp not implemented")
 }
+func (UnimplementedRelyingPartyServer) GetPresentationDefinition(context.Context, *PresentationDefinitionRequest) (*PresentationDefinitionReply, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetPresentationDefinition not implemented")
+}
 func (UnimplementedRelyingPartyServer) mustEmbedUnimplementedRelyingPartyServer() {}
 func (UnimplementedRelyingPartyServer) testEmbeddedByValue()                      {}
 
@@ -184,6 +300,96 @@ func _RelyingParty_AuthResponse_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RelyingParty_DidAuthChallenge_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChallengeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RelyingPartyServer).DidAuthChallenge(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RelyingParty_DidAuthChallenge_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RelyingPartyServer).DidAuthChallenge(ctx, req.(*ChallengeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RelyingParty_DidAuthResponse_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DidAuthResponseRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RelyingPartyServer).DidAuthResponse(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RelyingParty_DidAuthResponse_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RelyingPartyServer).DidAuthResponse(ctx, req.(*DidAuthResponseRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RelyingParty_RefreshToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RefreshTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RelyingPartyServer).RefreshToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RelyingParty_RefreshToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RelyingPartyServer).RefreshToken(ctx, req.(*RefreshTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RelyingParty_RevokeToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevokeTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RelyingPartyServer).RevokeToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RelyingParty_RevokeToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RelyingPartyServer).RevokeToken(ctx, req.(*RevokeTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _RelyingParty_IntrospectToken_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IntrospectTokenRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RelyingPartyServer).IntrospectToken(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RelyingParty_IntrospectToken_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RelyingPartyServer).IntrospectToken(ctx, req.(*IntrospectTokenRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _RelyingParty_SimplePresent_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(SimplePresentRequest)
 	if err := dec(in); err != nil {
@@ -220,6 +426,24 @@ func _RelyingParty_VerifyVp_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _RelyingParty_GetPresentationDefinition_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PresentationDefinitionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(RelyingPartyServer).GetPresentationDefinition(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: RelyingParty_GetPresentationDefinition_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(RelyingPartyServer).GetPresentationDefinition(ctx, req.(*PresentationDefinitionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // RelyingParty_ServiceDesc is the grpc.ServiceDesc for RelyingParty service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -235,6 +459,26 @@ var RelyingParty_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "AuthResponse",
 			Handler:    _RelyingParty_AuthResponse_Handler,
 		},
+		{
+			MethodName: "DidAuthChallenge",
+			Handler:    _RelyingParty_DidAuthChallenge_Handler,
+		},
+		{
+			MethodName: "DidAuthResponse",
+			Handler:    _RelyingParty_DidAuthResponse_Handler,
+		},
+		{
+			MethodName: "RefreshToken",
+			Handler:    _RelyingParty_RefreshToken_Handler,
+		},
+		{
+			MethodName: "RevokeToken",
+			Handler:    _RelyingParty_RevokeToken_Handler,
+		},
+		{
+			MethodName: "IntrospectToken",
+			Handler:    _RelyingParty_IntrospectToken_Handler,
+		},
 		{
 			MethodName: "SimplePresent",
 			Handler:    _RelyingParty_SimplePresent_Handler,
@@ -243,6 +487,10 @@ var RelyingParty_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "VerifyVp",
 			Handler:    _RelyingParty_VerifyVp_Handler,
 		},
+		{
+			MethodName: "GetPresentationDefinition",
+			Handler:    _RelyingParty_GetPresentationDefinition_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "proto-files/relyingparty.proto",
@@ -0,0 +1,922 @@
+// Copyright 2015 gRPC authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        v5.29.3
+// source: proto-files/trustregistry.proto
+
+package proto_files
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type TrustedIssuer struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	IssuerDid      string                 `protobuf:"bytes,1,opt,name=issuer_did,json=issuerDid,proto3" json:"issuer_did,omitempty"`
+	CredentialType string                 `protobuf:"bytes,2,opt,name=credential_type,json=credentialType,proto3" json:"credential_type,omitempty"`
+	ValidFrom      int64                  `protobuf:"varint,3,opt,name=valid_from,json=validFrom,proto3" json:"valid_from,omitempty"`
+	ValidUntil     int64                  `protobuf:"varint,4,opt,name=valid_until,json=validUntil,proto3" json:"valid_until,omitempty"`
+	AccreditedBy   string                 `protobuf:"bytes,5,opt,name=accredited_by,json=accreditedBy,proto3" json:"accredited_by,omitempty"`
+	Accreditation  string                 `protobuf:"bytes,6,opt,name=accreditation,proto3" json:"accreditation,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *TrustedIssuer) Reset() {
+	*x = TrustedIssuer{}
+	mi := &file_proto_files_trustregistry_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrustedIssuer) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrustedIssuer) ProtoMessage() {}
+
+func (x *TrustedIssuer) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_trustregistry_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrustedIssuer.ProtoReflect.Descriptor instead.
+func (*TrustedIssuer) Descriptor() ([]byte, []int) {
+	return file_proto_files_trustregistry_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *TrustedIssuer) GetIssuerDid() string {
+	if x != nil {
+		return x.IssuerDid
+	}
+	return ""
+}
+
+func (x *TrustedIssuer) GetCredentialType() string {
+	if x != nil {
+		return x.CredentialType
+	}
+	return ""
+}
+
+func (x *TrustedIssuer) GetValidFrom() int64 {
+	if x != nil {
+		return x.ValidFrom
+	}
+	return 0
+}
+
+func (x *TrustedIssuer) GetValidUntil() int64 {
+	if x != nil {
+		return x.ValidUntil
+	}
+	return 0
+}
+
+func (x *TrustedIssuer) GetAccreditedBy() string {
+	if x != nil {
+		return x.AccreditedBy
+	}
+	return ""
+}
+
+func (x *TrustedIssuer) GetAccreditation() string {
+	if x != nil {
+		return x.Accreditation
+	}
+	return ""
+}
+
+type RootAuthority struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	Did             string                 `protobuf:"bytes,1,opt,name=did,proto3" json:"did,omitempty"`
+	CredentialTypes []string               `protobuf:"bytes,2,rep,name=credential_types,json=credentialTypes,proto3" json:"credential_types,omitempty"`
+	ValidFrom       int64                  `protobuf:"varint,3,opt,name=valid_from,json=validFrom,proto3" json:"valid_from,omitempty"`
+	ValidUntil      int64                  `protobuf:"varint,4,opt,name=valid_until,json=validUntil,proto3" json:"valid_until,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *RootAuthority) Reset() {
+	*x = RootAuthority{}
+	mi := &file_proto_files_trustregistry_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RootAuthority) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RootAuthority) ProtoMessage() {}
+
+func (x *RootAuthority) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_trustregistry_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RootAuthority.ProtoReflect.Descriptor instead.
+func (*RootAuthority) Descriptor() ([]byte, []int) {
+	return file_proto_files_trustregistry_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *RootAuthority) GetDid() string {
+	if x != nil {
+		return x.Did
+	}
+	return ""
+}
+
+func (x *RootAuthority) GetCredentialTypes() []string {
+	if x != nil {
+		return x.CredentialTypes
+	}
+	return nil
+}
+
+func (x *RootAuthority) GetValidFrom() int64 {
+	if x != nil {
+		return x.ValidFrom
+	}
+	return 0
+}
+
+func (x *RootAuthority) GetValidUntil() int64 {
+	if x != nil {
+		return x.ValidUntil
+	}
+	return 0
+}
+
+type TrustRegistryResult struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Result        string                 `protobuf:"bytes,1,opt,name=result,proto3" json:"result,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TrustRegistryResult) Reset() {
+	*x = TrustRegistryResult{}
+	mi := &file_proto_files_trustregistry_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TrustRegistryResult) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TrustRegistryResult) ProtoMessage() {}
+
+func (x *TrustRegistryResult) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_trustregistry_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TrustRegistryResult.ProtoReflect.Descriptor instead.
+func (*TrustRegistryResult) Descriptor() ([]byte, []int) {
+	return file_proto_files_trustregistry_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *TrustRegistryResult) GetResult() string {
+	if x != nil {
+		return x.Result
+	}
+	return ""
+}
+
+type AddTrustedIssuerRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Issuer        *TrustedIssuer         `protobuf:"bytes,1,opt,name=issuer,proto3" json:"issuer,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddTrustedIssuerRequest) Reset() {
+	*x = AddTrustedIssuerRequest{}
+	mi := &file_proto_files_trustregistry_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddTrustedIssuerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddTrustedIssuerRequest) ProtoMessage() {}
+
+func (x *AddTrustedIssuerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_trustregistry_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddTrustedIssuerRequest.ProtoReflect.Descriptor instead.
+func (*AddTrustedIssuerRequest) Descriptor() ([]byte, []int) {
+	return file_proto_files_trustregistry_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *AddTrustedIssuerRequest) GetIssuer() *TrustedIssuer {
+	if x != nil {
+		return x.Issuer
+	}
+	return nil
+}
+
+type RemoveTrustedIssuerRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	IssuerDid      string                 `protobuf:"bytes,1,opt,name=issuer_did,json=issuerDid,proto3" json:"issuer_did,omitempty"`
+	CredentialType string                 `protobuf:"bytes,2,opt,name=credential_type,json=credentialType,proto3" json:"credential_type,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *RemoveTrustedIssuerRequest) Reset() {
+	*x = RemoveTrustedIssuerRequest{}
+	mi := &file_proto_files_trustregistry_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveTrustedIssuerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveTrustedIssuerRequest) ProtoMessage() {}
+
+func (x *RemoveTrustedIssuerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_trustregistry_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveTrustedIssuerRequest.ProtoReflect.Descriptor instead.
+func (*RemoveTrustedIssuerRequest) Descriptor() ([]byte, []int) {
+	return file_proto_files_trustregistry_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *RemoveTrustedIssuerRequest) GetIssuerDid() string {
+	if x != nil {
+		return x.IssuerDid
+	}
+	return ""
+}
+
+func (x *RemoveTrustedIssuerRequest) GetCredentialType() string {
+	if x != nil {
+		return x.CredentialType
+	}
+	return ""
+}
+
+type ListTrustedIssuersRequest struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	CredentialType string                 `protobuf:"bytes,1,opt,name=credential_type,json=credentialType,proto3" json:"credential_type,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ListTrustedIssuersRequest) Reset() {
+	*x = ListTrustedIssuersRequest{}
+	mi := &file_proto_files_trustregistry_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrustedIssuersRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrustedIssuersRequest) ProtoMessage() {}
+
+func (x *ListTrustedIssuersRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_trustregistry_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrustedIssuersRequest.ProtoReflect.Descriptor instead.
+func (*ListTrustedIssuersRequest) Descriptor() ([]byte, []int) {
+	return file_proto_files_trustregistry_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *ListTrustedIssuersRequest) GetCredentialType() string {
+	if x != nil {
+		return x.CredentialType
+	}
+	return ""
+}
+
+type ListTrustedIssuersResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Issuers       []*TrustedIssuer       `protobuf:"bytes,1,rep,name=issuers,proto3" json:"issuers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListTrustedIssuersResponse) Reset() {
+	*x = ListTrustedIssuersResponse{}
+	mi := &file_proto_files_trustregistry_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListTrustedIssuersResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListTrustedIssuersResponse) ProtoMessage() {}
+
+func (x *ListTrustedIssuersResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_trustregistry_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListTrustedIssuersResponse.ProtoReflect.Descriptor instead.
+func (*ListTrustedIssuersResponse) Descriptor() ([]byte, []int) {
+	return file_proto_files_trustregistry_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *ListTrustedIssuersResponse) GetIssuers() []*TrustedIssuer {
+	if x != nil {
+		return x.Issuers
+	}
+	return nil
+}
+
+type AddRootAuthorityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Root          *RootAuthority         `protobuf:"bytes,1,opt,name=root,proto3" json:"root,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AddRootAuthorityRequest) Reset() {
+	*x = AddRootAuthorityRequest{}
+	mi := &file_proto_files_trustregistry_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AddRootAuthorityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AddRootAuthorityRequest) ProtoMessage() {}
+
+func (x *AddRootAuthorityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_trustregistry_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AddRootAuthorityRequest.ProtoReflect.Descriptor instead.
+func (*AddRootAuthorityRequest) Descriptor() ([]byte, []int) {
+	return file_proto_files_trustregistry_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *AddRootAuthorityRequest) GetRoot() *RootAuthority {
+	if x != nil {
+		return x.Root
+	}
+	return nil
+}
+
+type RemoveRootAuthorityRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Did           string                 `protobuf:"bytes,1,opt,name=did,proto3" json:"did,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RemoveRootAuthorityRequest) Reset() {
+	*x = RemoveRootAuthorityRequest{}
+	mi := &file_proto_files_trustregistry_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RemoveRootAuthorityRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RemoveRootAuthorityRequest) ProtoMessage() {}
+
+func (x *RemoveRootAuthorityRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_trustregistry_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RemoveRootAuthorityRequest.ProtoReflect.Descriptor instead.
+func (*RemoveRootAuthorityRequest) Descriptor() ([]byte, []int) {
+	return file_proto_files_trustregistry_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *RemoveRootAuthorityRequest) GetDid() string {
+	if x != nil {
+		return x.Did
+	}
+	return ""
+}
+
+type ListRootAuthoritiesRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRootAuthoritiesRequest) Reset() {
+	*x = ListRootAuthoritiesRequest{}
+	mi := &file_proto_files_trustregistry_proto_msgTypes[9]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRootAuthoritiesRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRootAuthoritiesRequest) ProtoMessage() {}
+
+func (x *ListRootAuthoritiesRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_trustregistry_proto_msgTypes[9]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRootAuthoritiesRequest.ProtoReflect.Descriptor instead.
+func (*ListRootAuthoritiesRequest) Descriptor() ([]byte, []int) {
+	return file_proto_files_trustregistry_proto_rawDescGZIP(), []int{9}
+}
+
+type ListRootAuthoritiesResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Roots         []*RootAuthority       `protobuf:"bytes,1,rep,name=roots,proto3" json:"roots,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListRootAuthoritiesResponse) Reset() {
+	*x = ListRootAuthoritiesResponse{}
+	mi := &file_proto_files_trustregistry_proto_msgTypes[10]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListRootAuthoritiesResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListRootAuthoritiesResponse) ProtoMessage() {}
+
+func (x *ListRootAuthoritiesResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_trustregistry_proto_msgTypes[10]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListRootAuthoritiesResponse.ProtoReflect.Descriptor instead.
+func (*ListRootAuthoritiesResponse) Descriptor() ([]byte, []int) {
+	return file_proto_files_trustregistry_proto_rawDescGZIP(), []int{10}
+}
+
+func (x *ListRootAuthoritiesResponse) GetRoots() []*RootAuthority {
+	if x != nil {
+		return x.Roots
+	}
+	return nil
+}
+
+type AccreditRequest struct {
+	state              protoimpl.MessageState `protogen:"open.v1"`
+	AccreditationVcJwt string                 `protobuf:"bytes,1,opt,name=accreditation_vc_jwt,json=accreditationVcJwt,proto3" json:"accreditation_vc_jwt,omitempty"`
+	unknownFields      protoimpl.UnknownFields
+	sizeCache          protoimpl.SizeCache
+}
+
+func (x *AccreditRequest) Reset() {
+	*x = AccreditRequest{}
+	mi := &file_proto_files_trustregistry_proto_msgTypes[11]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccreditRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccreditRequest) ProtoMessage() {}
+
+func (x *AccreditRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_trustregistry_proto_msgTypes[11]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccreditRequest.ProtoReflect.Descriptor instead.
+func (*AccreditRequest) Descriptor() ([]byte, []int) {
+	return file_proto_files_trustregistry_proto_rawDescGZIP(), []int{11}
+}
+
+func (x *AccreditRequest) GetAccreditationVcJwt() string {
+	if x != nil {
+		return x.AccreditationVcJwt
+	}
+	return ""
+}
+
+type AccreditResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Issuers       []*TrustedIssuer       `protobuf:"bytes,1,rep,name=issuers,proto3" json:"issuers,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *AccreditResponse) Reset() {
+	*x = AccreditResponse{}
+	mi := &file_proto_files_trustregistry_proto_msgTypes[12]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *AccreditResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*AccreditResponse) ProtoMessage() {}
+
+func (x *AccreditResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_trustregistry_proto_msgTypes[12]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use AccreditResponse.ProtoReflect.Descriptor instead.
+func (*AccreditResponse) Descriptor() ([]byte, []int) {
+	return file_proto_files_trustregistry_proto_rawDescGZIP(), []int{12}
+}
+
+func (x *AccreditResponse) GetIssuers() []*TrustedIssuer {
+	if x != nil {
+		return x.Issuers
+	}
+	return nil
+}
+
+type CheckIssuerRequest struct {
+	state           protoimpl.MessageState `protogen:"open.v1"`
+	IssuerDid       string                 `protobuf:"bytes,1,opt,name=issuer_did,json=issuerDid,proto3" json:"issuer_did,omitempty"`
+	CredentialTypes []string               `protobuf:"bytes,2,rep,name=credential_types,json=credentialTypes,proto3" json:"credential_types,omitempty"`
+	unknownFields   protoimpl.UnknownFields
+	sizeCache       protoimpl.SizeCache
+}
+
+func (x *CheckIssuerRequest) Reset() {
+	*x = CheckIssuerRequest{}
+	mi := &file_proto_files_trustregistry_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckIssuerRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckIssuerRequest) ProtoMessage() {}
+
+func (x *CheckIssuerRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_trustregistry_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckIssuerRequest.ProtoReflect.Descriptor instead.
+func (*CheckIssuerRequest) Descriptor() ([]byte, []int) {
+	return file_proto_files_trustregistry_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *CheckIssuerRequest) GetIssuerDid() string {
+	if x != nil {
+		return x.IssuerDid
+	}
+	return ""
+}
+
+func (x *CheckIssuerRequest) GetCredentialTypes() []string {
+	if x != nil {
+		return x.CredentialTypes
+	}
+	return nil
+}
+
+type CheckIssuerResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Trusted       bool                   `protobuf:"varint,1,opt,name=trusted,proto3" json:"trusted,omitempty"`
+	Reason        string                 `protobuf:"bytes,2,opt,name=reason,proto3" json:"reason,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CheckIssuerResponse) Reset() {
+	*x = CheckIssuerResponse{}
+	mi := &file_proto_files_trustregistry_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CheckIssuerResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CheckIssuerResponse) ProtoMessage() {}
+
+func (x *CheckIssuerResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_files_trustregistry_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CheckIssuerResponse.ProtoReflect.Descriptor instead.
+func (*CheckIssuerResponse) Descriptor() ([]byte, []int) {
+	return file_proto_files_trustregistry_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *CheckIssuerResponse) GetTrusted() bool {
+	if x != nil {
+		return x.Trusted
+	}
+	return false
+}
+
+func (x *CheckIssuerResponse) GetReason() string {
+	if x != nil {
+		return x.Reason
+	}
+	return ""
+}
+
+var File_proto_files_trustregistry_proto protoreflect.FileDescriptor
+
+const file_proto_files_trustregistry_proto_rawDesc = "" +
+	"\n" +
+	"\x1fproto-files/trustregistry.proto\x12\rtrustregistry\"\xe2\x01\n" +
+	"\rTrustedIssuer\x12\x1d\n" +
+	"\n" +
+	"issuer_did\x18\x01 \x01(\tR\tissuerDid\x12'\n" +
+	"\x0fcredential_type\x18\x02 \x01(\tR\x0ecredentialType\x12\x1d\n" +
+	"\n" +
+	"valid_from\x18\x03 \x01(\x03R\tvalidFrom\x12\x1f\n" +
+	"\vvalid_until\x18\x04 \x01(\x03R\n" +
+	"validUntil\x12#\n" +
+	"\raccredited_by\x18\x05 \x01(\tR\faccreditedBy\x12$\n" +
+	"\raccreditation\x18\x06 \x01(\tR\raccreditation\"\x8c\x01\n" +
+	"\rRootAuthority\x12\x10\n" +
+	"\x03did\x18\x01 \x01(\tR\x03did\x12)\n" +
+	"\x10credential_types\x18\x02 \x03(\tR\x0fcredentialTypes\x12\x1d\n" +
+	"\n" +
+	"valid_from\x18\x03 \x01(\x03R\tvalidFrom\x12\x1f\n" +
+	"\vvalid_until\x18\x04 \x01(\x03R\n" +
+	"validUntil\"-\n" +
+	"\x13TrustRegistryResult\x12\x16\n" +
+	"\x06result\x18\x01 \x01(\tR\x06result\"O\n" +
+	"\x17AddTrustedIssuerRequest\x124\n" +
+	"\x06issuer\x18\x01 \x01(\v2\x1c.trustregistry.TrustedIssuerR\x06issuer\"d\n" +
+	"\x1aRemoveTrustedIssuerRequest\x12\x1d\n" +
+	"\n" +
+	"issuer_did\x18\x01 \x01(\tR\tissuerDid\x12'\n" +
+	"\x0fcredential_type\x18\x02 \x01(\tR\x0ecredentialType\"D\n" +
+	"\x19ListTrustedIssuersRequest\x12'\n" +
+	"\x0fcredential_type\x18\x01 \x01(\tR\x0ecredentialType\"T\n" +
+	"\x1aListTrustedIssuersResponse\x126\n" +
+	"\aissuers\x18\x01 \x03(\v2\x1c.trustregistry.TrustedIssuerR\aissuers\"K\n" +
+	"\x17AddRootAuthorityRequest\x120\n" +
+	"\x04root\x18\x01 \x01(\v2\x1c.trustregistry.RootAuthorityR\x04root\".\n" +
+	"\x1aRemoveRootAuthorityRequest\x12\x10\n" +
+	"\x03did\x18\x01 \x01(\tR\x03did\"\x1c\n" +
+	"\x1aListRootAuthoritiesRequest\"Q\n" +
+	"\x1bListRootAuthoritiesResponse\x122\n" +
+	"\x05roots\x18\x01 \x03(\v2\x1c.trustregistry.RootAuthorityR\x05roots\"C\n" +
+	"\x0fAccreditRequest\x120\n" +
+	"\x14accreditation_vc_jwt\x18\x01 \x01(\tR\x12accreditationVcJwt\"J\n" +
+	"\x10AccreditResponse\x126\n" +
+	"\aissuers\x18\x01 \x03(\v2\x1c.trustregistry.TrustedIssuerR\aissuers\"^\n" +
+	"\x12CheckIssuerRequest\x12\x1d\n" +
+	"\n" +
+	"issuer_did\x18\x01 \x01(\tR\tissuerDid\x12)\n" +
+	"\x10credential_types\x18\x02 \x03(\tR\x0fcredentialTypes\"G\n" +
+	"\x13CheckIssuerResponse\x12\x18\n" +
+	"\atrusted\x18\x01 \x01(\bR\atrusted\x12\x16\n" +
+	"\x06reason\x18\x02 \x01(\tR\x06reason2\xa7\x06\n" +
+	"\rTrustRegistry\x12`\n" +
+	"\x10AddTrustedIssuer\x12&.trustregistry.AddTrustedIssuerRequest\x1a\".trustregistry.TrustRegistryResult\"\x00\x12f\n" +
+	"\x13RemoveTrustedIssuer\x12).trustregistry.RemoveTrustedIssuerRequest\x1a\".trustregistry.TrustRegistryResult\"\x00\x12k\n" +
+	"\x12ListTrustedIssuers\x12(.trustregistry.ListTrustedIssuersRequest\x1a).trustregistry.ListTrustedIssuersResponse\"\x00\x12`\n" +
+	"\x10AddRootAuthority\x12&.trustregistry.AddRootAuthorityRequest\x1a\".trustregistry.TrustRegistryResult\"\x00\x12f\n" +
+	"\x13RemoveRootAuthority\x12).trustregistry.RemoveRootAuthorityRequest\x1a\".trustregistry.TrustRegistryResult\"\x00\x12n\n" +
+	"\x13ListRootAuthorities\x12).trustregistry.ListRootAuthoritiesRequest\x1a*.trustregistry.ListRootAuthoritiesResponse\"\x00\x12M\n" +
+	"\bAccredit\x12\x1e.trustregistry.AccreditRequest\x1a\x1f.trustregistry.AccreditResponse\"\x00\x12V\n" +
+	"\vCheckIssuer\x12!.trustregistry.CheckIssuerRequest\x1a\".trustregistry.CheckIssuerResponse\"\x00BK\n" +
+	"\x1cio.grpc.examples.proto-filesB\x12TrustRegistryProtoP\x01Z\x15byd50-ssi/proto-filesb\x06proto3"
+
+var (
+	file_proto_files_trustregistry_proto_rawDescOnce sync.Once
+	file_proto_files_trustregistry_proto_rawDescData []byte
+)
+
+func file_proto_files_trustregistry_proto_rawDescGZIP() []byte {
+	file_proto_files_trustregistry_proto_rawDescOnce.Do(func() {
+		file_proto_files_trustregistry_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_proto_files_trustregistry_proto_rawDesc), len(file_proto_files_trustregistry_proto_rawDesc)))
+	})
+	return file_proto_files_trustregistry_proto_rawDescData
+}
+
+var file_proto_files_trustregistry_proto_msgTypes = make([]protoimpl.MessageInfo, 15)
+var file_proto_files_trustregistry_proto_goTypes = []any{
+	(*TrustedIssuer)(nil),               // 0: trustregistry.TrustedIssuer
+	(*RootAuthority)(nil),               // 1: trustregistry.RootAuthority
+	(*TrustRegistryResult)(nil),         // 2: trustregistry.TrustRegistryResult
+	(*AddTrustedIssuerRequest)(nil),     // 3: trustregistry.AddTrustedIssuerRequest
+	(*RemoveTrustedIssuerRequest)(nil),  // 4: trustregistry.RemoveTrustedIssuerRequest
+	(*ListTrustedIssuersRequest)(nil),   // 5: trustregistry.ListTrustedIssuersRequest
+	(*ListTrustedIssuersResponse)(nil),  // 6: trustregistry.ListTrustedIssuersResponse
+	(*AddRootAuthorityRequest)(nil),     // 7: trustregistry.AddRootAuthorityRequest
+	(*RemoveRootAuthorityRequest)(nil),  // 8: trustregistry.RemoveRootAuthorityRequest
+	(*ListRootAuthoritiesRequest)(nil),  // 9: trustregistry.ListRootAuthoritiesRequest
+	(*ListRootAuthoritiesResponse)(nil), // 10: trustregistry.ListRootAuthoritiesResponse
+	(*AccreditRequest)(nil),             // 11: trustregistry.AccreditRequest
+	(*AccreditResponse)(nil),            // 12: trustregistry.AccreditResponse
+	(*CheckIssuerRequest)(nil),          // 13: trustregistry.CheckIssuerRequest
+	(*CheckIssuerResponse)(nil),         // 14: trustregistry.CheckIssuerResponse
+}
+var file_proto_files_trustregistry_proto_depIdxs = []int32{
+	0,  // 0: trustregistry.AddTrustedIssuerRequest.issuer:type_name -> trustregistry.TrustedIssuer
+	0,  // 1: trustregistry.ListTrustedIssuersResponse.issuers:type_name -> trustregistry.TrustedIssuer
+	1,  // 2: trustregistry.AddRootAuthorityRequest.root:type_name -> trustregistry.RootAuthority
+	1,  // 3: trustregistry.ListRootAuthoritiesResponse.roots:type_name -> trustregistry.RootAuthority
+	0,  // 4: trustregistry.AccreditResponse.issuers:type_name -> trustregistry.TrustedIssuer
+	3,  // 5: trustregistry.TrustRegistry.AddTrustedIssuer:input_type -> trustregistry.AddTrustedIssuerRequest
+	4,  // 6: trustregistry.TrustRegistry.RemoveTrustedIssuer:input_type -> trustregistry.RemoveTrustedIssuerRequest
+	5,  // 7: trustregistry.TrustRegistry.ListTrustedIssuers:input_type -> trustregistry.ListTrustedIssuersRequest
+	7,  // 8: trustregistry.TrustRegistry.AddRootAuthority:input_type -> trustregistry.AddRootAuthorityRequest
+	8,  // 9: trustregistry.TrustRegistry.RemoveRootAuthority:input_type -> trustregistry.RemoveRootAuthorityRequest
+	9,  // 10: trustregistry.TrustRegistry.ListRootAuthorities:input_type -> trustregistry.ListRootAuthoritiesRequest
+	11, // 11: trustregistry.TrustRegistry.Accredit:input_type -> trustregistry.AccreditRequest
+	13, // 12: trustregistry.TrustRegistry.CheckIssuer:input_type -> trustregistry.CheckIssuerRequest
+	2,  // 13: trustregistry.TrustRegistry.AddTrustedIssuer:output_type -> trustregistry.TrustRegistryResult
+	2,  // 14: trustregistry.TrustRegistry.RemoveTrustedIssuer:output_type -> trustregistry.TrustRegistryResult
+	6,  // 15: trustregistry.TrustRegistry.ListTrustedIssuers:output_type -> trustregistry.ListTrustedIssuersResponse
+	2,  // 16: trustregistry.TrustRegistry.AddRootAuthority:output_type -> trustregistry.TrustRegistryResult
+	2,  // 17: trustregistry.TrustRegistry.RemoveRootAuthority:output_type -> trustregistry.TrustRegistryResult
+	10, // 18: trustregistry.TrustRegistry.ListRootAuthorities:output_type -> trustregistry.ListRootAuthoritiesResponse
+	12, // 19: trustregistry.TrustRegistry.Accredit:output_type -> trustregistry.AccreditResponse
+	14, // 20: trustregistry.TrustRegistry.CheckIssuer:output_type -> trustregistry.CheckIssuerResponse
+	13, // [13:21] is the sub-list for method output_type
+	5,  // [5:13] is the sub-list for method input_type
+	5,  // [5:5] is the sub-list for extension type_name
+	5,  // [5:5] is the sub-list for extension extendee
+	0,  // [0:5] is the sub-list for field type_name
+}
+
+func init() { file_proto_files_trustregistry_proto_init() }
+func file_proto_files_trustregistry_proto_init() {
+	if File_proto_files_trustregistry_proto != nil {
+		return
+	}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_files_trustregistry_proto_rawDesc), len(file_proto_files_trustregistry_proto_rawDesc)),
+			NumEnums:      0,
+			NumMessages:   15,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_proto_files_trustregistry_proto_goTypes,
+		DependencyIndexes: file_proto_files_trustregistry_proto_depIdxs,
+		MessageInfos:      file_proto_files_trustregistry_proto_msgTypes,
+	}.Build()
+	File_proto_files_trustregistry_proto = out.File
+	file_proto_files_trustregistry_proto_goTypes = nil
+	file_proto_files_trustregistry_proto_depIdxs = nil
+}
@@ -0,0 +1,405 @@
+// Copyright 2015 gRPC authors.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.0
+// - protoc             v5.29.3
+// source: proto-files/trustregistry.proto
+
+package proto_files
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	TrustRegistry_AddTrustedIssuer_FullMethodName    = "/trustregistry.TrustRegistry/AddTrustedIssuer"
+	TrustRegistry_RemoveTrustedIssuer_FullMethodName = "/trustregistry.TrustRegistry/RemoveTrustedIssuer"
+	TrustRegistry_ListTrustedIssuers_FullMethodName  = "/trustregistry.TrustRegistry/ListTrustedIssuers"
+	TrustRegistry_AddRootAuthority_FullMethodName    = "/trustregistry.TrustRegistry/AddRootAuthority"
+	TrustRegistry_RemoveRootAuthority_FullMethodName = "/trustregistry.TrustRegistry/RemoveRootAuthority"
+	TrustRegistry_ListRootAuthorities_FullMethodName = "/trustregistry.TrustRegistry/ListRootAuthorities"
+	TrustRegistry_Accredit_FullMethodName            = "/trustregistry.TrustRegistry/Accredit"
+	TrustRegistry_CheckIssuer_FullMethodName         = "/trustregistry.TrustRegistry/CheckIssuer"
+)
+
+// TrustRegistryClient is the client API for TrustRegistry service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// TrustRegistry maps credential types to the issuer DIDs verifiers accept for them.
+type TrustRegistryClient interface {
+	AddTrustedIssuer(ctx context.Context, in *AddTrustedIssuerRequest, opts ...grpc.CallOption) (*TrustRegistryResult, error)
+	RemoveTrustedIssuer(ctx context.Context, in *RemoveTrustedIssuerRequest, opts ...grpc.CallOption) (*TrustRegistryResult, error)
+	ListTrustedIssuers(ctx context.Context, in *ListTrustedIssuersRequest, opts ...grpc.CallOption) (*ListTrustedIssuersResponse, error)
+	AddRootAuthority(ctx context.Context, in *AddRootAuthorityRequest, opts ...grpc.CallOption) (*TrustRegistryResult, error)
+	RemoveRootAuthority(ctx context.Context, in *RemoveRootAuthorityRequest, opts ...grpc.CallOption) (*TrustRegistryResult, error)
+	ListRootAuthorities(ctx context.Context, in *ListRootAuthoritiesRequest, opts ...grpc.CallOption) (*ListRootAuthoritiesResponse, error)
+	Accredit(ctx context.Context, in *AccreditRequest, opts ...grpc.CallOption) (*AccreditResponse, error)
+	CheckIssuer(ctx context.Context, in *CheckIssuerRequest, opts ...grpc.CallOption) (*CheckIssuerResponse, error)
+}
+
+type trustRegistryClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewTrustRegistryClient(cc grpc.ClientConnInterface) TrustRegistryClient {
+	return &trustRegistryClient{cc}
+}
+
+func (c *trustRegistryClient) AddTrustedIssuer(ctx context.Context, in *AddTrustedIssuerRequest, opts ...grpc.CallOption) (*TrustRegistryResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TrustRegistryResult)
+	err := c.cc.Invoke(ctx, TrustRegistry_AddTrustedIssuer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trustRegistryClient) RemoveTrustedIssuer(ctx context.Context, in *RemoveTrustedIssuerRequest, opts ...grpc.CallOption) (*TrustRegistryResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TrustRegistryResult)
+	err := c.cc.Invoke(ctx, TrustRegistry_RemoveTrustedIssuer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trustRegistryClient) ListTrustedIssuers(ctx context.Context, in *ListTrustedIssuersRequest, opts ...grpc.CallOption) (*ListTrustedIssuersResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListTrustedIssuersResponse)
+	err := c.cc.Invoke(ctx, TrustRegistry_ListTrustedIssuers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trustRegistryClient) AddRootAuthority(ctx context.Context, in *AddRootAuthorityRequest, opts ...grpc.CallOption) (*TrustRegistryResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TrustRegistryResult)
+	err := c.cc.Invoke(ctx, TrustRegistry_AddRootAuthority_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trustRegistryClient) RemoveRootAuthority(ctx context.Context, in *RemoveRootAuthorityRequest, opts ...grpc.CallOption) (*TrustRegistryResult, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TrustRegistryResult)
+	err := c.cc.Invoke(ctx, TrustRegistry_RemoveRootAuthority_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trustRegistryClient) ListRootAuthorities(ctx context.Context, in *ListRootAuthoritiesRequest, opts ...grpc.CallOption) (*ListRootAuthoritiesResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListRootAuthoritiesResponse)
+	err := c.cc.Invoke(ctx, TrustRegistry_ListRootAuthorities_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trustRegistryClient) Accredit(ctx context.Context, in *AccreditRequest, opts ...grpc.CallOption) (*AccreditResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AccreditResponse)
+	err := c.cc.Invoke(ctx, TrustRegistry_Accredit_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *trustRegistryClient) CheckIssuer(ctx context.Context, in *CheckIssuerRequest, opts ...grpc.CallOption) (*CheckIssuerResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CheckIssuerResponse)
+	err := c.cc.Invoke(ctx, TrustRegistry_CheckIssuer_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// TrustRegistryServer is the server API for TrustRegistry service.
+// All implementations must embed UnimplementedTrustRegistryServer
+// for forward compatibility.
+//
+// TrustRegistry maps credential types to the issuer DIDs verifiers accept for them.
+type TrustRegistryServer interface {
+	AddTrustedIssuer(context.Context, *AddTrustedIssuerRequest) (*TrustRegistryResult, error)
+	RemoveTrustedIssuer(context.Context, *RemoveTrustedIssuerRequest) (*TrustRegistryResult, error)
+	ListTrustedIssuers(context.Context, *ListTrustedIssuersRequest) (*ListTrustedIssuersResponse, error)
+	AddRootAuthority(context.Context, *AddRootAuthorityRequest) (*TrustRegistryResult, error)
+	RemoveRootAuthority(context.Context, *RemoveRootAuthorityRequest) (*TrustRegistryResult, error)
+	ListRootAuthorities(context.Context, *ListRootAuthoritiesRequest) (*ListRootAuthoritiesResponse, error)
+	Accredit(context.Context, *AccreditRequest) (*AccreditResponse, error)
+	CheckIssuer(context.Context, *CheckIssuerRequest) (*CheckIssuerResponse, error)
+	mustEmbedUnimplementedTrustRegistryServer()
+}
+
+// UnimplementedTrustRegistryServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedTrustRegistryServer struct{}
+
+func (UnimplementedTrustRegistryServer) AddTrustedIssuer(context.Context, *AddTrustedIssuerRequest) (*TrustRegistryResult, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddTrustedIssuer not implemented")
+}
+func (UnimplementedTrustRegistryServer) RemoveTrustedIssuer(context.Context, *RemoveTrustedIssuerRequest) (*TrustRegistryResult, error) {
+	return nil, status.Error(codes.Unimplemented, "method RemoveTrustedIssuer not implemented")
+}
+func (UnimplementedTrustRegistryServer) ListTrustedIssuers(context.Context, *ListTrustedIssuersRequest) (*ListTrustedIssuersResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListTrustedIssuers not implemented")
+}
+func (UnimplementedTrustRegistryServer) AddRootAuthority(context.Context, *AddRootAuthorityRequest) (*TrustRegistryResult, error) {
+	return nil, status.Error(codes.Unimplemented, "method AddRootAuthority not implemented")
+}
+func (UnimplementedTrustRegistryServer) RemoveRootAuthority(context.Context, *RemoveRootAuthorityRequest) (*TrustRegistryResult, error) {
+	return nil, status.Error(codes.Unimplemented, "method RemoveRootAuthority not implemented")
+}
+func (UnimplementedTrustRegistryServer) ListRootAuthorities(context.Context, *ListRootAuthoritiesRequest) (*ListRootAuthoritiesResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method ListRootAuthorities not implemented")
+}
+func (UnimplementedTrustRegistryServer) Accredit(context.Context, *AccreditRequest) (*AccreditResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Accredit not implemented")
+}
+func (UnimplementedTrustRegistryServer) CheckIssuer(context.Context, *CheckIssuerRequest) (*CheckIssuerResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method CheckIssuer not implemented")
+}
+func (UnimplementedTrustRegistryServer) mustEmbedUnimplementedTrustRegistryServer() {}
+func (UnimplementedTrustRegistryServer) testEmbeddedByValue()                       {}
+
+// UnsafeTrustRegistryServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to TrustRegistryServer will
+// result in compilation errors.
+type UnsafeTrustRegistryServer interface {
+	mustEmbedUnimplementedTrustRegistryServer()
+}
+
+func RegisterTrustRegistryServer(s grpc.ServiceRegistrar, srv TrustRegistryServer) {
+	// If the following call panics, it indicates UnimplementedTrustRegistryServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&TrustRegistry_ServiceDesc, srv)
+}
+
+func _TrustRegistry_AddTrustedIssuer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddTrustedIssuerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrustRegistryServer).AddTrustedIssuer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrustRegistry_AddTrustedIssuer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrustRegistryServer).AddTrustedIssuer(ctx, req.(*AddTrustedIssuerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrustRegistry_RemoveTrustedIssuer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveTrustedIssuerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrustRegistryServer).RemoveTrustedIssuer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrustRegistry_RemoveTrustedIssuer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrustRegistryServer).RemoveTrustedIssuer(ctx, req.(*RemoveTrustedIssuerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrustRegistry_ListTrustedIssuers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListTrustedIssuersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrustRegistryServer).ListTrustedIssuers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrustRegistry_ListTrustedIssuers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrustRegistryServer).ListTrustedIssuers(ctx, req.(*ListTrustedIssuersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrustRegistry_AddRootAuthority_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AddRootAuthorityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrustRegistryServer).AddRootAuthority(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrustRegistry_AddRootAuthority_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrustRegistryServer).AddRootAuthority(ctx, req.(*AddRootAuthorityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrustRegistry_RemoveRootAuthority_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RemoveRootAuthorityRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrustRegistryServer).RemoveRootAuthority(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrustRegistry_RemoveRootAuthority_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrustRegistryServer).RemoveRootAuthority(ctx, req.(*RemoveRootAuthorityRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrustRegistry_ListRootAuthorities_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListRootAuthoritiesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrustRegistryServer).ListRootAuthorities(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrustRegistry_ListRootAuthorities_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrustRegistryServer).ListRootAuthorities(ctx, req.(*ListRootAuthoritiesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrustRegistry_Accredit_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AccreditRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrustRegistryServer).Accredit(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrustRegistry_Accredit_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrustRegistryServer).Accredit(ctx, req.(*AccreditRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _TrustRegistry_CheckIssuer_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CheckIssuerRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(TrustRegistryServer).CheckIssuer(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: TrustRegistry_CheckIssuer_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(TrustRegistryServer).CheckIssuer(ctx, req.(*CheckIssuerRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// TrustRegistry_ServiceDesc is the grpc.ServiceDesc for TrustRegistry service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var TrustRegistry_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "trustregistry.TrustRegistry",
+	HandlerType: (*TrustRegistryServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "AddTrustedIssuer",
+			Handler:    _TrustRegistry_AddTrustedIssuer_Handler,
+		},
+		{
+			MethodName: "RemoveTrustedIssuer",
+			Handler:    _TrustRegistry_RemoveTrustedIssuer_Handler,
+		},
+		{
+			MethodName: "ListTrustedIssuers",
+			Handler:    _TrustRegistry_ListTrustedIssuers_Handler,
+		},
+		{
+			MethodName: "AddRootAuthority",
+			Handler:    _TrustRegistry_AddRootAuthority_Handler,
+		},
+		{
+			MethodName: "RemoveRootAuthority",
+			Handler:    _TrustRegistry_RemoveRootAuthority_Handler,
+		},
+		{
+			MethodName: "ListRootAuthorities",
+			Handler:    _TrustRegistry_ListRootAuthorities_Handler,
+		},
+		{
+			MethodName: "Accredit",
+			Handler:    _TrustRegistry_Accredit_Handler,
+		},
+		{
+			MethodName: "CheckIssuer",
+			Handler:    _TrustRegistry_CheckIssuer_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "proto-files/trustregistry.proto",
+}